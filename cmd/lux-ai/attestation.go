@@ -0,0 +1,233 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/luxfi/ai/pkg/attestation"
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+var (
+	errMissingEvidence = errors.New("attestation evidence is required")
+	errEvidenceDevice  = errors.New("attestation evidence device_id must be the miner id")
+	errRemoteLocal     = errors.New("local nvtrust evidence cannot be verified remotely; send a software attestation")
+	errNoCapability    = errors.New("miner registered no capability")
+)
+
+// nodeClock reads the node's clock at each call, so the verifier follows
+// a clock swapped in after the node is created
+type nodeClock struct{ n *AINode }
+
+func (c nodeClock) Now() time.Time { return c.n.clock.Now() }
+
+// loadDriverPolicy reads a driver policy JSON file over the default
+// policy, so fields and families it omits keep their defaults
+func loadDriverPolicy(path string) (*attestation.DriverPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy := attestation.DefaultDriverPolicy()
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// AttestationResult is the node's verdict on a miner's attestation
+// evidence: the tier and trust score it grants and until when
+type AttestationResult struct {
+	Tier          cc.CCTier `json:"tier"`
+	TrustScore    uint8     `json:"trust_score"`
+	AttestedUntil time.Time `json:"attested_until"`
+}
+
+// verifyAttestation checks ev with the node's verifier. The miner's
+// registered key is the only one authorized to sign its software
+// attestations; a key it rotated away from is revoked.
+func (n *AINode) verifyAttestation(minerID string, publicKey ed25519.PublicKey, ev *attestation.GPUAttestation) (*attestation.DeviceStatus, error) {
+	n.attestMu.Lock()
+	defer n.attestMu.Unlock()
+
+	if old, ok := n.attestKeys[minerID]; ok && !bytes.Equal(old, publicKey) {
+		n.verifier.RevokeKey(minerID, old)
+		delete(n.attestKeys, minerID)
+	}
+	if len(publicKey) == ed25519.PublicKeySize {
+		notAfter := n.clock.Now().Add(attestation.SoftwareAttestationMaxAge)
+		if err := n.verifier.AuthorizeKey(minerID, publicKey, notAfter); err != nil {
+			return nil, err
+		}
+		n.attestKeys[minerID] = publicKey
+	}
+	return n.verifier.VerifyGPUAttestation(ev)
+}
+
+// handleMinerAttestationChallenge issues a benchmark challenge for a
+// miner's next software attestation, replacing any it has outstanding. The
+// request must carry the bearer token issued at registration.
+func (n *AINode) handleMinerAttestationChallenge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	n.mu.RLock()
+	_, ok := n.miners[req.ID]
+	authorized := ok && validBearer(r, n.tokens[req.ID])
+	n.mu.RUnlock()
+	if !ok {
+		http.Error(w, "miner not registered", http.StatusNotFound)
+		return
+	}
+	if !authorized {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	n.attestMu.Lock()
+	ch, err := n.verifier.IssueBenchmarkChallenge(req.ID)
+	n.attestMu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ch)
+}
+
+// handleMinerAttestation verifies a miner's attestation evidence and
+// records the result, updating the trust score used for scheduling. The
+// node derives the tier and trust score from the verified evidence, bounded
+// by the registered capability and the tier's maximum score, and the
+// attestation counts for the tier's validity period from now. Evidence
+// must name the miner as its device, and software attestations must be
+// signed by the miner's registered key. Local nvtrust evidence is refused:
+// the node cannot check its SPDM and RIM signatures, so it would grant a
+// hardware tier on the miner's word. The miner must have registered a
+// capability, and the request must carry the bearer token issued at
+// registration.
+func (n *AINode) handleMinerAttestation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID       string                      `json:"id"`
+		Evidence *attestation.GPUAttestation `json:"evidence"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	n.mu.RLock()
+	miner, ok := n.miners[req.ID]
+	authorized := ok && validBearer(r, n.tokens[req.ID])
+	var publicKey ed25519.PublicKey
+	hasCapability := false
+	if ok {
+		publicKey = miner.PublicKey
+		hasCapability = miner.Capability != nil
+	}
+	n.mu.RUnlock()
+	if !ok {
+		http.Error(w, "miner not registered", http.StatusNotFound)
+		return
+	}
+	if !authorized {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !hasCapability {
+		http.Error(w, errNoCapability.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Evidence == nil {
+		http.Error(w, errMissingEvidence.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Evidence.DeviceID != req.ID {
+		http.Error(w, errEvidenceDevice.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Evidence.Mode != attestation.ModeSoftware {
+		http.Error(w, errRemoteLocal.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status, err := n.verifyAttestation(req.ID, publicKey, req.Evidence)
+	if err != nil {
+		n.logAttestation(req.Evidence, "rejected: "+err.Error())
+		http.Error(w, fmt.Sprintf("attestation rejected: %v", err), http.StatusBadRequest)
+		return
+	}
+	tier := attestation.GPUAttestationTier(req.Evidence)
+	now := n.clock.Now()
+	result := AttestationResult{
+		Tier:          tier,
+		TrustScore:    min(status.TrustScore, tier.MaxTrustScore()),
+		AttestedUntil: now.Add(tier.AttestationValidity()),
+	}
+
+	n.mu.Lock()
+	// The miner may have re-registered or left while the evidence was checked
+	if n.miners[req.ID] != miner || !bytes.Equal(miner.PublicKey, publicKey) {
+		n.mu.Unlock()
+		http.Error(w, "miner registration changed during attestation", http.StatusConflict)
+		return
+	}
+	if miner.Capability == nil {
+		n.mu.Unlock()
+		http.Error(w, errNoCapability.Error(), http.StatusBadRequest)
+		return
+	}
+	if !miner.canAchieveTier(tier) {
+		n.mu.Unlock()
+		http.Error(w, fmt.Sprintf("tier %d exceeds registered capability tier %d", tier, miner.Capability.MaxTier), http.StatusBadRequest)
+		return
+	}
+	miner.Tier = result.Tier
+	miner.TrustScore = result.TrustScore
+	miner.AttestedUntil = result.AttestedUntil
+	miner.LastSeen = now
+	n.mu.Unlock()
+	n.logAttestation(req.Evidence, fmt.Sprintf("accepted at tier %d", tier))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Status string `json:"status"`
+		AttestationResult
+	}{"ok", result})
+}
+
+// logAttestation prints evidence and its outcome when Config.LogAttestations
+// is set. The evidence is redacted under the configured policy; the miner
+// ID is the device ID, so it is only printed in its redacted form.
+func (n *AINode) logAttestation(evidence *attestation.GPUAttestation, outcome string) {
+	if !n.config.LogAttestations {
+		return
+	}
+	policy := attestation.RedactionPolicy{IDPrefix: n.config.RedactIDPrefix, HashBytes: n.config.RedactHashBytes}
+	fmt.Fprintf(n.attestLog, "Attestation %s: %s\n", outcome, evidence.RedactWith(policy))
+}
@@ -0,0 +1,255 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/attestation"
+	"github.com/luxfi/ai/pkg/cc"
+	"github.com/luxfi/ai/pkg/clock"
+)
+
+// localEvidence returns nvtrust evidence from a CC-enabled GPU of model,
+// which the node refuses since it cannot verify its signatures
+func localEvidence(deviceID, model string) *attestation.GPUAttestation {
+	return &attestation.GPUAttestation{
+		DeviceID:  deviceID,
+		Model:     model,
+		CCEnabled: true,
+		Mode:      attestation.ModeLocal,
+		Timestamp: time.Now(),
+		LocalEvidence: &attestation.LocalGPUEvidence{
+			SPDMReport:  make([]byte, 256),
+			CertChain:   make([]byte, 1024),
+			RIMVerified: true,
+		},
+	}
+}
+
+// softwareEvidence returns a software attestation for an RTX 4090 signed
+// with key, answering ch when it is non-nil. The node times the answer on
+// its own clock, so a test on a mock clock must advance it past the
+// kernel's minimum for the RTX 4090 before posting.
+func softwareEvidence(t *testing.T, deviceID string, key ed25519.PrivateKey, ch *attestation.BenchmarkChallenge) *attestation.GPUAttestation {
+	t.Helper()
+	b := attestation.NewAttestationBuilder(&cc.HardwareCapability{
+		GPUVendor:    cc.VendorNVIDIA,
+		GPUModel:     "NVIDIA GeForce RTX 4090",
+		GPUSerial:    "GPU-4090-0001",
+		GPUDriverVer: "570.00",
+		ComputeCap:   "8.9",
+	}, key).WithDeviceID(deviceID)
+	if ch != nil {
+		b.WithBenchmark(ch, time.Second)
+	}
+	att, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return att
+}
+
+func postAttestation(n *AINode, token string, body interface{}) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/api/miners/attestation", bytes.NewReader(data))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	n.handleMinerAttestation(rec, req)
+	return rec
+}
+
+func TestMinerAttestation(t *testing.T) {
+	n := NewAINode(Config{})
+	mock := clock.NewMock(time.Now())
+	n.clock = mock
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	_, strangerPriv, _ := ed25519.GenerateKey(nil)
+	n.miners["m1"] = &MinerInfo{ID: "m1", PublicKey: pub, Capability: &cc.HardwareCapability{MaxTier: cc.Tier4Standard}}
+	n.tokens["m1"] = "tok"
+
+	tests := []struct {
+		name   string
+		token  string
+		body   interface{}
+		status int
+	}{
+		{"no token", "", map[string]interface{}{"id": "m1", "evidence": softwareEvidence(t, "m1", priv, nil)}, http.StatusUnauthorized},
+		{"unknown miner", "tok", map[string]interface{}{"id": "m2", "evidence": softwareEvidence(t, "m2", priv, nil)}, http.StatusNotFound},
+		{"self-reported tier", "tok", map[string]interface{}{"id": "m1", "tier": 1, "trust_score": 100}, http.StatusBadRequest},
+		{"other device", "tok", map[string]interface{}{"id": "m1", "evidence": softwareEvidence(t, "gpu-0", priv, nil)}, http.StatusBadRequest},
+		{"signed by a stranger", "tok", map[string]interface{}{"id": "m1", "evidence": softwareEvidence(t, "m1", strangerPriv, nil)}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := postAttestation(n, tt.token, tt.body); rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body)
+		}
+	}
+	if m := n.miners["m1"]; m.Tier != 0 || m.TrustScore != 0 {
+		t.Fatalf("rejected attestations set tier %d, trust %d", m.Tier, m.TrustScore)
+	}
+
+	// The node grants what the verified evidence supports, whatever else
+	// the request claims, for the tier's validity period
+	rec := postAttestation(n, "tok", map[string]interface{}{
+		"id": "m1", "tier": 1, "trust_score": 100, "expires_at": mock.Now().Add(365 * 24 * time.Hour),
+		"evidence": softwareEvidence(t, "m1", priv, nil),
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var result AttestationResult
+	json.NewDecoder(rec.Body).Decode(&result)
+	m := n.miners["m1"]
+	wantUntil := mock.Now().Add(cc.Tier4Standard.AttestationValidity())
+	if m.Tier != cc.Tier4Standard || m.TrustScore == 0 || m.TrustScore > cc.Tier4Standard.MaxTrustScore() || !m.AttestedUntil.Equal(wantUntil) {
+		t.Errorf("miner = tier %d, trust %d, attested until %v; want tier 4, trust within its range, until %v",
+			m.Tier, m.TrustScore, m.AttestedUntil, wantUntil)
+	}
+	if result.Tier != m.Tier || result.TrustScore != m.TrustScore || !result.AttestedUntil.Equal(m.AttestedUntil) {
+		t.Errorf("response = %+v, want the recorded attestation", result)
+	}
+}
+
+func TestMinerAttestationChallenge(t *testing.T) {
+	n := NewAINode(Config{})
+	mock := clock.NewMock(time.Now())
+	n.clock = mock
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	n.miners["m1"] = &MinerInfo{ID: "m1", PublicKey: pub, Capability: &cc.HardwareCapability{MaxTier: cc.Tier4Standard}}
+	n.tokens["m1"] = "tok"
+	challenge := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/miners/attestation/challenge", bytes.NewReader([]byte(`{"id":"m1"}`)))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		n.handleMinerAttestationChallenge(rec, req)
+		return rec
+	}
+
+	if rec := postAttestation(n, "tok", map[string]interface{}{"id": "m1", "evidence": softwareEvidence(t, "m1", priv, nil)}); rec.Code != http.StatusOK {
+		t.Fatalf("unanswered attestation: status = %d: %s", rec.Code, rec.Body)
+	}
+	unanswered := n.miners["m1"].TrustScore
+
+	if rec := challenge("wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("challenge with wrong token: status = %d, want 401", rec.Code)
+	}
+	rec := challenge("tok")
+	var ch attestation.BenchmarkChallenge
+	if err := json.NewDecoder(rec.Body).Decode(&ch); rec.Code != http.StatusOK || err != nil || ch.DeviceID != "m1" {
+		t.Fatalf("challenge = %d %+v, want one for m1", rec.Code, ch)
+	}
+
+	// Answering the challenge earns more trust than not, and only once. The
+	// node times the answer itself, from issuing the challenge.
+	evidence := softwareEvidence(t, "m1", priv, &ch)
+	mock.Advance(time.Second)
+	if rec := postAttestation(n, "tok", map[string]interface{}{"id": "m1", "evidence": evidence}); rec.Code != http.StatusOK {
+		t.Fatalf("answered attestation: status = %d: %s", rec.Code, rec.Body)
+	}
+	if got := n.miners["m1"].TrustScore; got <= unanswered {
+		t.Errorf("answered attestation trust = %d, want more than %d", got, unanswered)
+	}
+	if rec := postAttestation(n, "tok", map[string]interface{}{"id": "m1", "evidence": evidence}); rec.Code != http.StatusBadRequest {
+		t.Errorf("replayed answer: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestMinerAttestationBoundedByCapability(t *testing.T) {
+	n := NewAINode(Config{})
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	n.tokens["m1"] = "tok"
+
+	tests := []struct {
+		name       string
+		capability *cc.HardwareCapability
+		evidence   *attestation.GPUAttestation
+		status     int
+	}{
+		{"no capability", nil, softwareEvidence(t, "m1", priv, nil), http.StatusBadRequest},
+		{"full CC local evidence", &cc.HardwareCapability{MaxTier: cc.Tier1GPUNativeCC}, localEvidence("m1", "H100"), http.StatusBadRequest},
+		{"limited CC local evidence", &cc.HardwareCapability{MaxTier: cc.Tier2ConfidentialVM}, localEvidence("m1", "A100"), http.StatusBadRequest},
+		{"software", &cc.HardwareCapability{MaxTier: cc.Tier2ConfidentialVM}, softwareEvidence(t, "m1", priv, nil), http.StatusOK},
+	}
+	for _, tt := range tests {
+		n.miners["m1"] = &MinerInfo{ID: "m1", PublicKey: pub, Capability: tt.capability}
+		rec := postAttestation(n, "tok", map[string]interface{}{"id": "m1", "evidence": tt.evidence})
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body)
+			continue
+		}
+		m := n.miners["m1"]
+		switch {
+		case tt.status != http.StatusOK && (m.Tier != 0 || m.TrustScore != 0):
+			t.Errorf("%s: rejected evidence set tier %d, trust %d", tt.name, m.Tier, m.TrustScore)
+		case tt.status == http.StatusOK && (m.Tier != cc.Tier4Standard || m.TrustScore > cc.Tier4Standard.MaxTrustScore()):
+			t.Errorf("%s: tier %d, trust %d; want tier 4 within its trust range", tt.name, m.Tier, m.TrustScore)
+		}
+	}
+}
+
+func TestMinerAttestationLogging(t *testing.T) {
+	const id = "miner-0123456789"
+	for _, enabled := range []bool{false, true} {
+		n := NewAINode(Config{LogAttestations: enabled, RedactIDPrefix: 2})
+		var log bytes.Buffer
+		n.attestLog = &log
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		_, strangerPriv, _ := ed25519.GenerateKey(nil)
+		n.miners[id] = &MinerInfo{ID: id, PublicKey: pub, Capability: &cc.HardwareCapability{MaxTier: cc.Tier4Standard}}
+		n.tokens[id] = "tok"
+
+		postAttestation(n, "tok", map[string]interface{}{"id": id, "evidence": softwareEvidence(t, id, strangerPriv, nil)})
+		postAttestation(n, "tok", map[string]interface{}{"id": id, "evidence": softwareEvidence(t, id, priv, nil)})
+
+		got := log.String()
+		if !enabled {
+			if got != "" {
+				t.Errorf("logged %q with logging disabled", got)
+			}
+			continue
+		}
+		if !strings.Contains(got, "Attestation rejected") || !strings.Contains(got, "Attestation accepted at tier 4") {
+			t.Errorf("log = %q, want a rejected and an accepted attestation", got)
+		}
+		if strings.Contains(got, id) || strings.Contains(got, "GPU-4090-0001") || !strings.Contains(got, `"mi..."`) {
+			t.Errorf("log = %q, want IDs and serials cut to 2 characters", got)
+		}
+	}
+}
+
+func TestLoadDriverPolicy(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(good, []byte(`{"vulnerable": [{"min": "550.0", "max": "550.99"}], "min_cuda": "12.4"}`), 0o600)
+	os.WriteFile(bad, []byte(`{"min_cuda": "latest"}`), 0o600)
+
+	policy, err := loadDriverPolicy(good)
+	if err != nil {
+		t.Fatalf("loadDriverPolicy() error = %v", err)
+	}
+	if len(policy.Vulnerable) != 1 || policy.MinCUDA.String() != "12.4" {
+		t.Errorf("loadDriverPolicy() = %+v", policy)
+	}
+	if got := policy.MinRecommended[attestation.FamilyBlackwell]; got.String() != "570.0" {
+		t.Errorf("MinRecommended[blackwell] = %s, want the default 570.0", got)
+	}
+	if _, err := loadDriverPolicy(bad); err == nil {
+		t.Error("loadDriverPolicy() with an unparseable version: want error")
+	}
+}
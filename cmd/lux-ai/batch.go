@@ -0,0 +1,199 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+const (
+	// defaultMaxBatchConcurrency caps the embeddings a batch request has in
+	// flight when Config.MaxBatchConcurrency is unset
+	defaultMaxBatchConcurrency = 8
+
+	// placeholderEmbeddingDims is the size of the zero embedding returned
+	// while no miner is connected
+	placeholderEmbeddingDims = 1536
+
+	// placeholderEmbeddingTokens is the usage reported for a placeholder
+	// embedding
+	placeholderEmbeddingTokens = 8
+
+	// batchReadSize is the request buffer size; longer lines are
+	// assembled from several reads up to the per-item limit
+	batchReadSize = 64 << 10
+)
+
+// BatchEmbeddingItem is one line of an /v1/embeddings/batch request
+type BatchEmbeddingItem struct {
+	Input string `json:"input"`
+	Model string `json:"model"`
+}
+
+// BatchEmbeddingResult is one line of an /v1/embeddings/batch response.
+// Index is the item's position among the request's non-blank lines; Error
+// is set instead of Embedding when that item failed.
+type BatchEmbeddingResult struct {
+	Index     int       `json:"index"`
+	Object    string    `json:"object"`
+	Embedding []float64 `json:"embedding,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// maxBatchConcurrency returns the configured cap on a batch request's
+// in-flight embeddings
+func (n *AINode) maxBatchConcurrency() int {
+	if n.config.MaxBatchConcurrency > 0 {
+		return n.config.MaxBatchConcurrency
+	}
+	return defaultMaxBatchConcurrency
+}
+
+// handleEmbeddingsBatch embeds an NDJSON stream of BatchEmbeddingItem
+// lines and streams back one BatchEmbeddingResult line per item as each
+// completes, so results arrive out of order. Items are dispatched as
+// separate tasks, spreading them across miners, with at most
+// maxBatchConcurrency in flight; the request is read only as fast as slots
+// free up, which bounds memory for arbitrarily long batches. A malformed,
+// empty or oversized item, or a failed task, yields an error line for that
+// index without affecting the rest of the batch.
+func (n *AINode) handleEmbeddingsBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Results are written while the request is still being read
+	rc := http.NewResponseController(w)
+	_ = rc.EnableFullDuplex()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	results := make(chan BatchEmbeddingResult)
+	go n.runEmbeddingsBatch(r, results)
+
+	enc := json.NewEncoder(w)
+	for res := range results {
+		if err := enc.Encode(res); err != nil {
+			continue // Client gone; drain so workers can exit
+		}
+		_ = rc.Flush()
+	}
+}
+
+// runEmbeddingsBatch reads items from r and sends each result to results,
+// closing it once every item has finished
+func (n *AINode) runEmbeddingsBatch(r *http.Request, results chan<- BatchEmbeddingResult) {
+	var wg sync.WaitGroup
+	defer func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	slots := make(chan struct{}, n.maxBatchConcurrency())
+	br := bufio.NewReaderSize(r.Body, batchReadSize)
+	for index := 0; ; {
+		line, tooLong, err := readBatchLine(br, n.maxPromptBytes())
+		if err != nil && !errors.Is(err, io.EOF) {
+			results <- batchError(index, fmt.Errorf("read request: %w", err))
+			return
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 || tooLong {
+			i := index
+			index++
+
+			var item BatchEmbeddingItem
+			switch {
+			case tooLong:
+				results <- batchError(i, fmt.Errorf("item exceeds the limit of %d bytes", n.maxPromptBytes()))
+			case json.Unmarshal(line, &item) != nil:
+				results <- batchError(i, errors.New("invalid JSON item"))
+			case item.Input == "":
+				results <- batchError(i, errors.New("input is required"))
+			default:
+				select {
+				case slots <- struct{}{}:
+				case <-r.Context().Done():
+					return
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-slots }()
+					results <- n.embedBatchItem(r, i, item)
+				}()
+			}
+		}
+		if err != nil {
+			return // io.EOF
+		}
+	}
+}
+
+// embedBatchItem embeds one item on a miner, answering with a placeholder
+// embedding while none is connected like /v1/embeddings. Each embedding
+// returned is metered as /v1/embeddings meters it.
+func (n *AINode) embedBatchItem(r *http.Request, index int, item BatchEmbeddingItem) BatchEmbeddingResult {
+	input, err := json.Marshal(map[string]string{"text": item.Input})
+	if err != nil {
+		return batchError(index, err)
+	}
+	outputs, err := n.generate(r, "embedding", item.Model, input, 1)
+	if errors.Is(err, errNoMiners) {
+		n.recordUsage(r, item.Model, placeholderEmbeddingTokens, 0)
+		return BatchEmbeddingResult{
+			Index:     index,
+			Object:    "embedding",
+			Embedding: make([]float64, placeholderEmbeddingDims),
+			Model:     item.Model,
+		}
+	}
+	if err != nil {
+		return batchError(index, err)
+	}
+
+	var out struct {
+		Embedding []float64 `json:"embedding"`
+		Model     string    `json:"model"`
+	}
+	if err := json.Unmarshal(outputs[0], &out); err != nil || len(out.Embedding) == 0 {
+		return batchError(index, errors.New("invalid miner output"))
+	}
+	if out.Model == "" {
+		out.Model = item.Model
+	}
+	n.recordUsage(r, item.Model, placeholderEmbeddingTokens, 0)
+	return BatchEmbeddingResult{Index: index, Object: "embedding", Embedding: out.Embedding, Model: out.Model}
+}
+
+func batchError(index int, err error) BatchEmbeddingResult {
+	return BatchEmbeddingResult{Index: index, Object: "error", Error: err.Error()}
+}
+
+// readBatchLine reads one line, including its newline. Lines longer than
+// limit are consumed but not returned, and tooLong is set. err is io.EOF
+// after the final line.
+func readBatchLine(br *bufio.Reader, limit int) (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if !tooLong && len(line)+len(chunk) <= limit+1 {
+			line = append(line, chunk...)
+		} else {
+			line, tooLong = nil, true
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return line, tooLong, err
+		}
+	}
+}
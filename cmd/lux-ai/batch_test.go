@@ -0,0 +1,176 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runEmbeddingMiner registers a miner that embeds each text as [len(text)]
+// and fails tasks whose text is "fail", until ctx is done
+func runEmbeddingMiner(ctx context.Context, n *AINode) {
+	n.mu.Lock()
+	n.miners["embedder"] = &MinerInfo{ID: "embedder"}
+	n.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			n.mu.Lock()
+			for _, t := range n.claimTasksLocked("embedder") {
+				var in struct {
+					Text string `json:"text"`
+				}
+				json.Unmarshal(t.Input, &in)
+				if in.Text == "fail" {
+					t.Status = TaskDead
+					t.Failures = append(t.Failures, "backend error")
+				} else {
+					t.Output, _ = json.Marshal(map[string]interface{}{"embedding": []float64{float64(len(in.Text))}})
+					t.Status = TaskCompleted
+				}
+				n.finishTaskLocked(t)
+			}
+			n.mu.Unlock()
+		}
+	}()
+}
+
+func batchRequest(t *testing.T, n *AINode, body string) map[int]BatchEmbeddingResult {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/v1/embeddings/batch", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	n.handleEmbeddingsBatch(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	results := make(map[int]BatchEmbeddingResult)
+	sc := bufio.NewScanner(rec.Body)
+	for sc.Scan() {
+		var res BatchEmbeddingResult
+		if err := json.Unmarshal(sc.Bytes(), &res); err != nil {
+			t.Fatalf("invalid result line %q: %v", sc.Text(), err)
+		}
+		if _, dup := results[res.Index]; dup {
+			t.Fatalf("duplicate result for index %d", res.Index)
+		}
+		results[res.Index] = res
+	}
+	return results
+}
+
+func TestEmbeddingsBatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := NewAINode(Config{MaxPromptBytes: 64})
+	runEmbeddingMiner(ctx, n)
+
+	body := strings.Join([]string{
+		`{"input":"hello","model":"m"}`,
+		``, // blank lines are skipped
+		`not json`,
+		`{"input":""}`,
+		`{"input":"fail"}`,
+		`{"input":"` + strings.Repeat("x", 100) + `"}`,
+		`{"input":"abc"}`, // no trailing newline
+	}, "\n")
+	results := batchRequest(t, n, body)
+
+	want := []struct {
+		ok        bool
+		embedding float64
+	}{
+		{true, 5},
+		{false, 0},
+		{false, 0},
+		{false, 0},
+		{false, 0},
+		{true, 3},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for i, w := range want {
+		res := results[i]
+		if got := res.Error == ""; got != w.ok {
+			t.Errorf("results[%d] ok = %v, want %v (%+v)", i, got, w.ok, res)
+			continue
+		}
+		if w.ok && (len(res.Embedding) != 1 || res.Embedding[0] != w.embedding) {
+			t.Errorf("results[%d].embedding = %v, want [%v]", i, res.Embedding, w.embedding)
+		}
+	}
+	if results[0].Model != "m" {
+		t.Errorf("results[0].model = %q, want m", results[0].Model)
+	}
+}
+
+func TestEmbeddingsBatchNoMiners(t *testing.T) {
+	n := NewAINode(Config{})
+	results := batchRequest(t, n, "{\"input\":\"a\"}\n{\"input\":\"b\"}\n")
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for i, res := range results {
+		if len(res.Embedding) != placeholderEmbeddingDims {
+			t.Errorf("results[%d] has %d dims, want placeholder %d", i, len(res.Embedding), placeholderEmbeddingDims)
+		}
+	}
+}
+
+func TestEmbeddingsBatchConcurrencyCap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := NewAINode(Config{MaxBatchConcurrency: 2})
+	runEmbeddingMiner(ctx, n)
+
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = `{"input":"doc","model":"m"}`
+	}
+	results := batchRequest(t, n, strings.Join(lines, "\n"))
+	if len(results) != len(lines) {
+		t.Fatalf("got %d results, want %d", len(results), len(lines))
+	}
+	if got := n.inflight.Stats("m"); got.Peak > 2 || got.Current != 0 {
+		t.Errorf("in-flight stats = %+v, want peak <= 2 and none left", got)
+	}
+}
+
+func TestReadBatchLine(t *testing.T) {
+	// A reader smaller than the lines forces multi-read assembly
+	br := bufio.NewReaderSize(strings.NewReader("short\n"+strings.Repeat("y", 40)+"\n"+strings.Repeat("z", 20)+"\nlast"), 16)
+
+	tests := []struct {
+		line    string
+		tooLong bool
+		eof     bool
+	}{
+		{"short\n", false, false},
+		{"", true, false},
+		{strings.Repeat("z", 20) + "\n", false, false},
+		{"last", false, true},
+	}
+	for i, tt := range tests {
+		line, tooLong, err := readBatchLine(br, 24)
+		if string(line) != tt.line || tooLong != tt.tooLong || (err != nil) != tt.eof {
+			t.Errorf("line %d: readBatchLine() = %q, %v, %v; want %q, %v, eof %v",
+				i, line, tooLong, err, tt.line, tt.tooLong, tt.eof)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+var errTaskCancelled = errors.New("task cancelled")
+
+// finished reports whether t has reached a final status
+func (t *Task) finished() bool {
+	return t.Status == TaskCompleted || t.Status == TaskDead || t.Status == TaskCancelled
+}
+
+// cancelTaskLocked moves an unfinished task to TaskCancelled. An assigned
+// task frees its miner's slot and drops any partial output. A miner that
+// sends heartbeats is told to stop a running task in its next heartbeat
+// reply; any miner learns of the cancellation when its next append or
+// submit is refused with 409. Clients waiting on the task get
+// errTaskCancelled. reason is recorded in its timeline. Caller holds n.mu.
+func (n *AINode) cancelTaskLocked(t *Task, reason string) {
+	n.recordLocked(t, EventCancelled, reason)
+	if t.AssignedTo != "" {
+		n.finishTaskLocked(t)
+		n.resetChunksLocked(t)
+	}
+	if miner, ok := n.miners[t.AssignedTo]; ok && t.Status == TaskRunning && len(miner.PublicKey) > 0 {
+		miner.cancelled = append(miner.cancelled, t.ID)
+	}
+	t.Status = TaskCancelled
+	t.FinishedAt = n.clock.Now()
+}
+
+// taskOwner identifies who submits a task with r: the key ID of its API
+// key, or "" for an anonymous request
+func taskOwner(r *http.Request) string {
+	if key := apiKey(r); key != "" {
+		return keyID(key)
+	}
+	return ""
+}
+
+// canCancel reports whether r may cancel t. The admin token may cancel any
+// task and an API key the tasks submitted with it; tasks submitted without
+// a key can only be cancelled by the admin.
+func (n *AINode) canCancel(r *http.Request, t *Task) bool {
+	if n.config.AdminToken != "" && validBearer(r, n.config.AdminToken) {
+		return true
+	}
+	return t.owner != "" && taskOwner(r) == t.owner
+}
+
+// handleTask serves DELETE /api/tasks/{id}, cancelling a pending or
+// in-flight task on behalf of its submitter or the admin; see canCancel.
+// Finished tasks answer 409.
+func (n *AINode) handleTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		w.Header().Set("Allow", "DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if apiKey(r) == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := r.PathValue("id")
+	n.mu.Lock()
+	t, ok := n.tasks[id]
+	if !ok {
+		n.mu.Unlock()
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	if !n.canCancel(r, t) {
+		n.mu.Unlock()
+		http.Error(w, "only the task's submitter or the admin may cancel it", http.StatusForbidden)
+		return
+	}
+	if t.finished() {
+		status := t.Status
+		n.mu.Unlock()
+		http.Error(w, "task already "+status, http.StatusConflict)
+		return
+	}
+	n.cancelTaskLocked(t, "cancelled by client")
+	n.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"id":     id,
+		"status": TaskCancelled,
+	})
+}
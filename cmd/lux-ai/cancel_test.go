@@ -0,0 +1,216 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/internal/minersig"
+)
+
+func TestCancelTask(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		method     string
+		id         string
+		wantCode   int
+		wantStatus string
+	}{
+		{"pending", TaskPending, "DELETE", "t1", http.StatusOK, TaskCancelled},
+		{"assigned", TaskAssigned, "DELETE", "t1", http.StatusOK, TaskCancelled},
+		{"running", TaskRunning, "DELETE", "t1", http.StatusOK, TaskCancelled},
+		{"completed", TaskCompleted, "DELETE", "t1", http.StatusConflict, TaskCompleted},
+		{"dead", TaskDead, "DELETE", "t1", http.StatusConflict, TaskDead},
+		{"already cancelled", TaskCancelled, "DELETE", "t1", http.StatusConflict, TaskCancelled},
+		{"unknown", TaskPending, "DELETE", "missing", http.StatusNotFound, TaskPending},
+		{"wrong method", TaskPending, "POST", "t1", http.StatusMethodNotAllowed, TaskPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{DataDir: t.TempDir()})
+			miner := &MinerInfo{ID: "m"}
+			n.miners["m"] = miner
+			task := &Task{ID: "t1", Model: "zen-mini-0.5b", owner: keyID("client-key")}
+			if tt.status == TaskPending {
+				task.Status = TaskPending
+			} else {
+				n.assignLocked(task, miner, TaskAssigned)
+				task.Status = tt.status
+				task.Partial, task.NextSeq = "partial", 1
+			}
+			n.tasks["t1"] = task
+
+			req := httptest.NewRequest(tt.method, "/api/tasks/"+tt.id, nil)
+			req.Header.Set("Authorization", "Bearer client-key")
+			rec := httptest.NewRecorder()
+			n.newMux().ServeHTTP(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status code = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if task.Status != tt.wantStatus {
+				t.Errorf("task status = %s, want %s", task.Status, tt.wantStatus)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			if miner.ActiveTasks != 0 {
+				t.Errorf("miner ActiveTasks = %d, want 0", miner.ActiveTasks)
+			}
+			if task.Partial != "" || task.FinishedAt.IsZero() {
+				t.Errorf("partial = %q, finished at %v; want output dropped and finish time set", task.Partial, task.FinishedAt)
+			}
+			if claimed := n.claimTasksLocked("m"); len(claimed) != 0 {
+				t.Errorf("claimed %d tasks after cancel, want 0", len(claimed))
+			}
+		})
+	}
+}
+
+func TestCancelledTaskRefusesResult(t *testing.T) {
+	n := NewAINode(Config{DataDir: t.TempDir()})
+	n.tokens["m"] = "tok"
+	n.miners["m"] = &MinerInfo{ID: "m"}
+	n.tasks["t1"] = &Task{ID: "t1", Status: TaskCancelled, AssignedTo: "m"}
+
+	for _, path := range []string{"/api/tasks/submit", "/api/tasks/append"} {
+		body := `{"id":"t1","status":"completed","output":{"content":"late"}}`
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer tok")
+		rec := httptest.NewRecorder()
+		n.newMux().ServeHTTP(rec, req)
+		if rec.Code != http.StatusConflict {
+			t.Errorf("%s: status = %d, want 409: %s", path, rec.Code, rec.Body)
+		}
+	}
+	if n.tasks["t1"].Status != TaskCancelled {
+		t.Errorf("task status = %s, want cancelled", n.tasks["t1"].Status)
+	}
+}
+
+func TestHeartbeatReportsCancelledTasks(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	n := NewAINode(Config{DataDir: t.TempDir()})
+	miner := &MinerInfo{ID: "m", PublicKey: pub}
+	n.miners["m"] = miner
+	for _, id := range []string{"running", "assigned"} {
+		task := &Task{ID: id, Model: "zen-mini-0.5b"}
+		n.assignLocked(task, miner, TaskRunning)
+		n.tasks[id] = task
+	}
+	n.tasks["assigned"].Status = TaskAssigned
+	for _, id := range []string{"running", "assigned"} {
+		n.cancelTaskLocked(n.tasks[id], "cancelled by client")
+	}
+
+	heartbeat := func(ts time.Time) []string {
+		nonce := bytes.Repeat([]byte{7}, minHeartbeatNonce)
+		digest := minersig.HeartbeatDigest("m", ts, nonce)
+		body, _ := json.Marshal(&Heartbeat{ID: "m", Timestamp: ts, Nonce: nonce, Signature: ed25519.Sign(priv, digest[:])})
+		rec := httptest.NewRecorder()
+		n.handleMinerHeartbeat(rec, httptest.NewRequest("POST", "/api/miners/heartbeat", bytes.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("heartbeat status = %d: %s", rec.Code, rec.Body)
+		}
+		var reply struct {
+			Cancelled []string `json:"cancelled"`
+		}
+		json.NewDecoder(rec.Body).Decode(&reply)
+		return reply.Cancelled
+	}
+
+	now := time.Now()
+	if got := heartbeat(now); len(got) != 1 || got[0] != "running" {
+		t.Errorf("first heartbeat cancelled = %v, want [running]", got)
+	}
+	if got := heartbeat(now.Add(time.Second)); len(got) != 0 {
+		t.Errorf("second heartbeat cancelled = %v, want none", got)
+	}
+}
+
+func TestCancelWaitingChat(t *testing.T) {
+	n := NewAINode(Config{DataDir: t.TempDir()})
+	n.miners["m"] = &MinerInfo{ID: "m"}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}]}`))
+		req.Header.Set("Authorization", "Bearer client-key")
+		rec := httptest.NewRecorder()
+		n.handleChatCompletions(rec, req)
+		done <- rec
+	}()
+
+	var id string
+	for id == "" {
+		time.Sleep(10 * time.Millisecond)
+		n.mu.RLock()
+		for tid := range n.tasks {
+			id = tid
+		}
+		n.mu.RUnlock()
+	}
+
+	req := httptest.NewRequest("DELETE", "/api/tasks/"+id, nil)
+	req.Header.Set("Authorization", "Bearer client-key")
+	rec := httptest.NewRecorder()
+	n.newMux().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("cancel status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	select {
+	case chat := <-done:
+		if chat.Code != http.StatusConflict {
+			t.Errorf("chat status = %d, want 409: %s", chat.Code, chat.Body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("chat still waiting after its task was cancelled")
+	}
+	if got := n.inflight.Stats("zen-mini-0.5b").Current; got != 0 {
+		t.Errorf("in-flight = %d, want 0", got)
+	}
+}
+
+func TestCancelRequiresOwner(t *testing.T) {
+	n := NewAINode(Config{DataDir: t.TempDir(), AdminToken: "admin"})
+	n.tasks["mine"] = &Task{ID: "mine", Status: TaskPending, owner: keyID("alice")}
+	n.tasks["anon"] = &Task{ID: "anon", Status: TaskPending}
+
+	tests := []struct {
+		name   string
+		id     string
+		bearer string
+		want   int
+	}{
+		{"no key", "mine", "", http.StatusUnauthorized},
+		{"foreign key", "mine", "mallory", http.StatusForbidden},
+		{"anonymous task", "anon", "mallory", http.StatusForbidden},
+		{"owner", "mine", "alice", http.StatusOK},
+		{"admin", "anon", "admin", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("DELETE", "/api/tasks/"+tt.id, nil)
+		if tt.bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.bearer)
+		}
+		rec := httptest.NewRecorder()
+		n.newMux().ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.want, rec.Body)
+		}
+		if tt.want != http.StatusOK && n.tasks[tt.id].Status != TaskPending {
+			t.Fatalf("%s: task %s, want it left pending", tt.name, n.tasks[tt.id].Status)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+// capabilityTTL is how long a capability detection is reused
+const capabilityTTL = time.Minute
+
+// A detected tier lower than the one in effect applies only once it has
+// been detected this many times in a row, over at least this long, so a
+// host whose CC mode flaps does not flap its reported tier and stake
+const (
+	tierDowngradeCycles = 3
+	tierDowngradeAfter  = 5 * time.Minute
+)
+
+// capabilityCache holds the host's latest capability detection so that
+// /api/capability, which needs no authentication, does not probe the
+// hardware on every request. Failed detections are cached too. Each
+// detection is a cycle of the tier hysteresis.
+type capabilityCache struct {
+	mu     sync.Mutex
+	detect func() (*cc.OnboardingReport, error) // nil means cc.DetectAndScore
+	tiers  *cc.TierHysteresis
+	state  capabilityState
+	err    error
+	at     time.Time
+}
+
+// capabilityState is a detection with the tier in effect after hysteresis
+type capabilityState struct {
+	report  *cc.OnboardingReport
+	tier    cc.CCTier // Tier in effect
+	pending cc.CCTier // Lower detected tier awaiting confirmation, TierUnknown if none
+}
+
+// get returns the cached detection, detecting again if there is none or it
+// is older than capabilityTTL. Concurrent callers share one detection.
+func (c *capabilityCache) get(now time.Time) (capabilityState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.at.IsZero() && now.Sub(c.at) < capabilityTTL {
+		return c.state, c.err
+	}
+	detect := c.detect
+	if detect == nil {
+		detect = cc.DetectAndScore
+	}
+	if c.tiers == nil {
+		c.tiers = cc.NewTierHysteresis(tierDowngradeCycles, tierDowngradeAfter)
+	}
+
+	report, err := detect()
+	detected := cc.TierUnknown // A failed detection counts as the lowest tier
+	if err == nil {
+		detected = report.MaxTier
+	}
+	c.state = capabilityState{report: report, tier: c.tiers.Observe(detected, now)}
+	c.state.pending, _, _, _ = c.tiers.PendingDowngrade()
+	c.err = err
+	c.at = now
+	return c.state, c.err
+}
+
+// CapabilityResponse describes the node host's confidential-compute posture
+type CapabilityResponse struct {
+	Capability     *cc.HardwareCapability `json:"capability"`
+	Tier           string                 `json:"tier"`
+	SupportedTiers []string               `json:"supported_tiers"`
+	TrustScore     uint8                  `json:"trust_score"`
+	MinStakeLUX    uint64                 `json:"min_stake_lux"` // Stake required for Tier
+	DetectedTier   string                 `json:"detected_tier"`
+	PendingTier    string                 `json:"pending_tier,omitempty"` // Lower tier not yet in effect
+	RequiresSetup  bool                   `json:"requires_setup"`
+	SetupHint      string                 `json:"setup_hint,omitempty"`
+}
+
+// handleCapability reports the host's detected CC capabilities. Detection
+// is repeated at most every capabilityTTL, so operators see changes such as
+// CC mode being enabled without restarting the node. Tier and its stake are
+// the tier in effect: an upgrade applies at once, a downgrade only once it
+// persists (see tierDowngradeCycles).
+func (n *AINode) handleCapability(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, err := n.capability.get(n.clock.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report := state.report
+	resp := CapabilityResponse{
+		Capability:     report.Capability,
+		Tier:           state.tier.String(),
+		SupportedTiers: make([]string, 0, len(report.SupportedTiers)),
+		TrustScore:     report.TrustScore,
+		MinStakeLUX:    state.tier.MinStakeLUX(),
+		DetectedTier:   report.MaxTier.String(),
+		RequiresSetup:  report.RequiresSetup,
+		SetupHint:      report.SetupHint,
+	}
+	if state.pending != cc.TierUnknown {
+		resp.PendingTier = state.pending.String()
+	}
+	for _, tier := range report.SupportedTiers {
+		resp.SupportedTiers = append(resp.SupportedTiers, tier.String())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
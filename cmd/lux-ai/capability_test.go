@@ -0,0 +1,131 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+	"github.com/luxfi/ai/pkg/clock"
+)
+
+func TestCapabilityCached(t *testing.T) {
+	n := NewAINode(Config{})
+	mock := clock.NewMock(time.Now())
+	n.clock = mock
+
+	var mu sync.Mutex
+	detections := 0
+	var detectErr error
+	n.capability.detect = func() (*cc.OnboardingReport, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		detections++
+		if detectErr != nil {
+			return nil, detectErr
+		}
+		return cc.NewOnboardingReport(&cc.HardwareCapability{GPUVendor: cc.VendorNVIDIA, GPUModel: "H100", MaxTier: cc.Tier1GPUNativeCC}), nil
+	}
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		n.newMux().ServeHTTP(rec, httptest.NewRequest("GET", "/api/capability", nil))
+		return rec
+	}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rec := get(); rec.Code != http.StatusOK {
+				t.Errorf("status = %d: %s", rec.Code, rec.Body)
+			}
+		}()
+	}
+	wg.Wait()
+	if detections != 1 {
+		t.Errorf("detected %d times for concurrent requests, want 1", detections)
+	}
+	var resp CapabilityResponse
+	if err := json.NewDecoder(get().Body).Decode(&resp); err != nil || resp.Capability == nil || resp.Capability.GPUModel != "H100" {
+		t.Errorf("response = %+v, %v; want the detected H100", resp, err)
+	}
+
+	// A stale report is detected again, and a failure is cached as well
+	mu.Lock()
+	detectErr = errors.New("nvidia-smi failed")
+	mu.Unlock()
+	mock.Advance(capabilityTTL - time.Second)
+	if rec := get(); rec.Code != http.StatusOK || detections != 1 {
+		t.Errorf("within TTL: status %d after %d detections, want the cached report", rec.Code, detections)
+	}
+	mock.Advance(time.Second)
+	for range 2 {
+		if rec := get(); rec.Code != http.StatusInternalServerError {
+			t.Errorf("failed detection: status = %d, want 500", rec.Code)
+		}
+	}
+	if detections != 2 {
+		t.Errorf("detected %d times, want 2", detections)
+	}
+}
+
+func TestCapabilityTierHysteresis(t *testing.T) {
+	n := NewAINode(Config{})
+	mock := clock.NewMock(time.Now())
+	n.clock = mock
+
+	ccEnabled := true
+	n.capability.detect = func() (*cc.OnboardingReport, error) {
+		tier := cc.Tier4Standard
+		if ccEnabled {
+			tier = cc.Tier1GPUNativeCC
+		}
+		return cc.NewOnboardingReport(&cc.HardwareCapability{GPUVendor: cc.VendorNVIDIA, GPUModel: "H100", MaxTier: tier}), nil
+	}
+	detect := func(enabled bool) CapabilityResponse {
+		t.Helper()
+		ccEnabled = enabled
+		rec := httptest.NewRecorder()
+		n.newMux().ServeHTTP(rec, httptest.NewRequest("GET", "/api/capability", nil))
+		var resp CapabilityResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); rec.Code != http.StatusOK || err != nil {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+		mock.Advance(3 * time.Minute) // Past capabilityTTL, so the next request detects again
+		return resp
+	}
+	tier1, tier4 := cc.Tier1GPUNativeCC.String(), cc.Tier4Standard.String()
+
+	// CC mode toggling every cycle keeps the higher tier
+	for i, enabled := range []bool{true, false, true, false, false, true} {
+		if resp := detect(enabled); resp.Tier != tier1 || resp.MinStakeLUX != cc.Tier1GPUNativeCC.MinStakeLUX() {
+			t.Errorf("cycle %d: tier %s, stake %d; want %s and its stake", i, resp.Tier, resp.MinStakeLUX, tier1)
+		}
+	}
+
+	// A sustained downgrade applies after tierDowngradeCycles detections
+	// spanning tierDowngradeAfter
+	var resp CapabilityResponse
+	for range tierDowngradeCycles {
+		resp = detect(false)
+		if resp.Tier == tier1 && (resp.PendingTier != tier4 || resp.DetectedTier != tier4) {
+			t.Errorf("held tier: pending %q, detected %q; want %s", resp.PendingTier, resp.DetectedTier, tier4)
+		}
+	}
+	if resp.Tier != tier4 || resp.PendingTier != "" {
+		t.Errorf("sustained downgrade: tier %s, pending %q; want %s", resp.Tier, resp.PendingTier, tier4)
+	}
+
+	// An upgrade applies at once
+	if resp := detect(true); resp.Tier != tier1 {
+		t.Errorf("upgrade: tier %s, want %s", resp.Tier, tier1)
+	}
+}
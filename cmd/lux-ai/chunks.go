@@ -0,0 +1,138 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// maxChunkWindow bounds how far ahead of the next expected sequence
+	// number a chunk may arrive, limiting buffered out-of-order chunks
+	maxChunkWindow = 1024
+
+	// maxChunkBytes caps the content of one chunk
+	maxChunkBytes = 64 << 10
+)
+
+// ChunkRequest is an incremental piece of task output. Seq numbers start at
+// 0 and are contiguous; chunks may arrive out of order or more than once.
+type ChunkRequest struct {
+	ID      string `json:"id"`
+	Miner   string `json:"miner,omitempty"` // If set, must match the task's current assignee
+	Seq     int    `json:"seq"`
+	Content string `json:"content"`
+}
+
+// handleAppendChunk accepts a partial result for a running task. The
+// request must carry the bearer token issued to the task's assigned miner
+// at registration.
+func (n *AINode) handleAppendChunk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ChunkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	n.mu.Lock()
+	t, ok := n.tasks[req.ID]
+	if !ok {
+		n.mu.Unlock()
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	switch {
+	case t.Status == TaskCancelled:
+		n.mu.Unlock()
+		http.Error(w, errTaskCancelled.Error(), http.StatusConflict)
+		return
+	case t.Status == TaskCompleted || t.Status == TaskDead:
+		n.mu.Unlock()
+		http.Error(w, "task already finished", http.StatusConflict)
+		return
+	case req.Miner != "" && req.Miner != t.AssignedTo:
+		n.mu.Unlock()
+		http.Error(w, "task is assigned to another miner", http.StatusConflict)
+		return
+	case t.AssignedTo == "" || n.tokens[t.AssignedTo] == "" || !validBearer(r, n.tokens[t.AssignedTo]):
+		n.mu.Unlock()
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	case len(req.Content) > maxChunkBytes:
+		n.mu.Unlock()
+		http.Error(w, fmt.Sprintf("chunk exceeds the limit of %d bytes", maxChunkBytes), http.StatusRequestEntityTooLarge)
+		return
+	case req.Seq < 0 || req.Seq >= t.NextSeq+maxChunkWindow:
+		n.mu.Unlock()
+		http.Error(w, fmt.Sprintf("seq must be between 0 and %d", t.NextSeq+maxChunkWindow-1), http.StatusBadRequest)
+		return
+	}
+	duplicate := !n.appendChunkLocked(t, req.Seq, req.Content)
+	nextSeq := t.NextSeq
+	n.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "ok",
+		"next_seq":  nextSeq,
+		"duplicate": duplicate,
+	})
+}
+
+// appendChunkLocked buffers a chunk and folds every contiguous chunk into
+// t.Partial. It reports false if seq was already received. Caller holds n.mu.
+func (n *AINode) appendChunkLocked(t *Task, seq int, content string) bool {
+	if seq < t.NextSeq {
+		return false
+	}
+	if _, ok := t.chunks[seq]; ok {
+		return false
+	}
+	if t.chunks == nil {
+		t.chunks = make(map[int]string)
+	}
+	t.chunks[seq] = content
+	t.progressAt = n.clock.Now()
+
+	first := t.NextSeq == 0
+	var assembled string
+	for {
+		next, ok := t.chunks[t.NextSeq]
+		if !ok {
+			break
+		}
+		delete(t.chunks, t.NextSeq)
+		assembled += next
+		t.NextSeq++
+	}
+	if first && t.NextSeq > 0 {
+		n.recordLocked(t, EventFirstChunk, "")
+	}
+	t.Partial += assembled
+	return true
+}
+
+// resetChunksLocked discards partial output when a task starts a new
+// attempt. Caller holds n.mu.
+func (n *AINode) resetChunksLocked(t *Task) {
+	t.Partial = ""
+	t.NextSeq = 0
+	t.chunks = nil
+	t.progressAt = time.Time{}
+}
+
+// chunkedOutput returns the completion output for a task whose miner
+// streamed its result and sent an empty final submit
+func chunkedOutput(t *Task) json.RawMessage {
+	out, _ := json.Marshal(map[string]string{"content": t.Partial})
+	return out
+}
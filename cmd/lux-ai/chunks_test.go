@@ -0,0 +1,129 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAppendChunkLocked(t *testing.T) {
+	type chunk struct {
+		seq     int
+		content string
+	}
+	tests := []struct {
+		name     string
+		chunks   []chunk
+		accepted []bool
+		partial  string
+		nextSeq  int
+		buffered int
+	}{
+		{"in order", []chunk{{0, "a"}, {1, "b"}, {2, "c"}}, []bool{true, true, true}, "abc", 3, 0},
+		{"out of order", []chunk{{2, "c"}, {0, "a"}, {1, "b"}}, []bool{true, true, true}, "abc", 3, 0},
+		{"gap held back", []chunk{{0, "a"}, {2, "c"}, {3, "d"}}, []bool{true, true, true}, "a", 1, 2},
+		{"duplicate assembled", []chunk{{0, "a"}, {0, "x"}, {1, "b"}}, []bool{true, false, true}, "ab", 2, 0},
+		{"duplicate buffered", []chunk{{1, "b"}, {1, "x"}, {0, "a"}}, []bool{true, false, true}, "ab", 2, 0},
+		{"late after assembly", []chunk{{0, "a"}, {1, "b"}, {0, "a"}, {1, "b"}}, []bool{true, true, false, false}, "ab", 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{DataDir: t.TempDir()})
+			task := &Task{ID: "t1", Status: TaskAssigned, AssignedTo: "m"}
+			n.tasks[task.ID] = task
+
+			var accepted []bool
+			n.mu.Lock()
+			for _, c := range tt.chunks {
+				accepted = append(accepted, n.appendChunkLocked(task, c.seq, c.content))
+			}
+			n.mu.Unlock()
+			if !reflect.DeepEqual(accepted, tt.accepted) {
+				t.Errorf("accepted = %v, want %v", accepted, tt.accepted)
+			}
+			if task.Partial != tt.partial || task.NextSeq != tt.nextSeq || len(task.chunks) != tt.buffered {
+				t.Errorf("partial %q, next seq %d, %d buffered; want %q, %d, %d",
+					task.Partial, task.NextSeq, len(task.chunks), tt.partial, tt.nextSeq, tt.buffered)
+			}
+		})
+	}
+}
+
+func TestAppendChunkRequiresAssignedMiner(t *testing.T) {
+	n := NewAINode(Config{DataDir: t.TempDir()})
+	n.miners["m1"] = &MinerInfo{ID: "m1"}
+	n.miners["m2"] = &MinerInfo{ID: "m2"}
+	n.tokens["m1"] = "tok-m1"
+	n.tokens["m2"] = "tok-m2"
+	n.tasks["t1"] = &Task{ID: "t1", Status: TaskAssigned, AssignedTo: "m1"}
+	n.tasks["pending"] = &Task{ID: "pending", Status: TaskPending}
+
+	tests := []struct {
+		name   string
+		token  string
+		body   string
+		status int
+	}{
+		{"no token", "", `{"id":"t1","seq":0,"content":"a"}`, http.StatusUnauthorized},
+		{"other miner's token", "tok-m2", `{"id":"t1","seq":0,"content":"a"}`, http.StatusUnauthorized},
+		{"other miner named", "tok-m2", `{"id":"t1","miner":"m2","seq":0,"content":"a"}`, http.StatusConflict},
+		{"unassigned task", "tok-m1", `{"id":"pending","seq":0,"content":"a"}`, http.StatusUnauthorized},
+		{"oversized chunk", "tok-m1", `{"id":"t1","seq":0,"content":"` + strings.Repeat("x", maxChunkBytes+1) + `"}`, http.StatusRequestEntityTooLarge},
+		{"assigned miner", "tok-m1", `{"id":"t1","seq":0,"content":"a"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/api/tasks/append", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		n.newMux().ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body)
+		}
+	}
+	if got := n.tasks["t1"].Partial; got != "a" {
+		t.Errorf("partial = %q, want only the assigned miner's chunk", got)
+	}
+}
+
+func TestStreamedSubmitRequiresAssignedMiner(t *testing.T) {
+	n := NewAINode(Config{})
+	n.miners["m1"] = &MinerInfo{ID: "m1"}
+	n.miners["m2"] = &MinerInfo{ID: "m2"}
+	n.tokens["m1"] = "tok-m1"
+	n.tokens["m2"] = "tok-m2"
+	task := &Task{ID: "t1", Status: TaskRunning, AssignedTo: "m1"}
+	n.tasks[task.ID] = task
+	n.mu.Lock()
+	n.appendChunkLocked(task, 0, "Hel")
+	n.appendChunkLocked(task, 1, "lo")
+	n.mu.Unlock()
+
+	for _, token := range []string{"", "tok-m2", "tok-m1"} {
+		req := httptest.NewRequest("POST", "/api/tasks/submit", strings.NewReader(`{"id":"t1","status":"completed","chunks":2}`))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		n.newMux().ServeHTTP(rec, req)
+
+		want := http.StatusUnauthorized
+		if token == "tok-m1" {
+			want = http.StatusOK
+		}
+		if rec.Code != want {
+			t.Errorf("token %q: status = %d, want %d: %s", token, rec.Code, want, rec.Body)
+		}
+	}
+	if task.Status != TaskCompleted || string(task.Output) != `{"content":"Hello"}` {
+		t.Errorf("task = %s with %s, want completed with the streamed output", task.Status, task.Output)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// defaultTaskRetention is how long finished tasks are kept when
+	// Config.TaskRetention is unset
+	defaultTaskRetention = time.Hour
+
+	// compactInterval is how often finished tasks are compacted
+	compactInterval = time.Minute
+
+	// archiveFilePrefix names the dated files compacted tasks are appended
+	// to when Config.ArchiveTasks is set
+	archiveFilePrefix = "tasks-"
+)
+
+// CompactionStats describes task store compaction since the node started
+type CompactionStats struct {
+	Runs      uint64    `json:"runs"`
+	Compacted uint64    `json:"compacted"` // Finished tasks removed from the store
+	Archived  uint64    `json:"archived"`  // Compacted tasks written to the archive
+	LastRun   time.Time `json:"last_run,omitempty"`
+	LastError string    `json:"last_error,omitempty"` // Archive failure of the last run, if any
+}
+
+// taskRetention returns how long finished tasks are kept. It is never less
+// than the longest request timeout, so no client still waiting on a task
+// can lose it.
+func (n *AINode) taskRetention() time.Duration {
+	retention := n.config.TaskRetention
+	if retention <= 0 {
+		retention = defaultTaskRetention
+	}
+	return max(retention, n.maxRequestTimeout())
+}
+
+// compactTasksLoop compacts finished tasks every compactInterval
+func (n *AINode) compactTasksLoop(ctx context.Context) {
+	ticker := time.NewTicker(compactInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.compactTasks(n.clock.Now())
+		}
+	}
+}
+
+// compactTasks removes completed and dead tasks that finished more than
+// the retention window before now, archiving them first when configured.
+// The tasks are encoded under the lock and written after it is released,
+// and exactly the tasks written are removed; those that fail to archive
+// are kept for the next run. It returns the number of tasks removed.
+func (n *AINode) compactTasks(now time.Time) int {
+	retention := n.taskRetention()
+
+	var (
+		expired    []*Task
+		lines      [][]byte
+		archiveErr error
+	)
+	n.mu.RLock()
+	for _, t := range n.tasks {
+		if finishedBefore(t, now, retention) {
+			expired = append(expired, t)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].CreatedAt.Before(expired[j].CreatedAt)
+	})
+	if n.config.ArchiveTasks {
+		for i, t := range expired {
+			line, err := json.Marshal(t)
+			if err != nil {
+				expired, archiveErr = expired[:i], err
+				break
+			}
+			lines = append(lines, line)
+		}
+	}
+	n.mu.RUnlock()
+
+	if n.config.ArchiveTasks && len(lines) > 0 {
+		written, err := n.archiveTasks(lines, now)
+		expired = expired[:written]
+		if err != nil {
+			archiveErr = err
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.compaction.Runs++
+	n.compaction.LastRun = now
+	n.compaction.LastError = ""
+	if archiveErr != nil {
+		n.compaction.LastError = archiveErr.Error()
+	}
+
+	removed := 0
+	for _, t := range expired {
+		if n.tasks[t.ID] == t {
+			delete(n.tasks, t.ID)
+			removed++
+		}
+	}
+	n.compaction.Compacted += uint64(removed)
+	if n.config.ArchiveTasks {
+		n.compaction.Archived += uint64(len(expired))
+	}
+	return removed
+}
+
+// finishedBefore reports whether t completed, died or was cancelled more
+// than retention before now. Caller holds n.mu.
+func finishedBefore(t *Task, now time.Time, retention time.Duration) bool {
+	if !t.finished() {
+		return false
+	}
+	finished := t.FinishedAt
+	if finished.IsZero() {
+		finished = t.CreatedAt
+	}
+	return stale(finished, now, retention)
+}
+
+// archiveTasks appends encoded tasks, one per line, to the archive file for
+// now's date, so archives roll over daily. It returns how many lines were
+// written before any error. It does not touch n.mu.
+func (n *AINode) archiveTasks(lines [][]byte, now time.Time) (int, error) {
+	if err := os.MkdirAll(n.config.DataDir, 0755); err != nil {
+		return 0, err
+	}
+	path := filepath.Join(n.config.DataDir, archiveFilePrefix+now.UTC().Format("2006-01-02")+".jsonl")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for _, line := range lines {
+		if _, err = f.Write(append(line, '\n')); err != nil {
+			break
+		}
+		written++
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return written, err
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompactTasks(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-2 * time.Hour)
+	recent := now.Add(-time.Minute)
+
+	for _, archive := range []bool{false, true} {
+		dir := t.TempDir()
+		n := NewAINode(Config{DataDir: dir, ArchiveTasks: archive})
+		n.tasks = map[string]*Task{
+			"old-done":      {ID: "old-done", Status: TaskCompleted, CreatedAt: old, FinishedAt: old},
+			"old-dead":      {ID: "old-dead", Status: TaskDead, CreatedAt: old.Add(-time.Minute), FinishedAt: old},
+			"old-unstamped": {ID: "old-unstamped", Status: TaskCompleted, CreatedAt: old},
+			"recent-done":   {ID: "recent-done", Status: TaskCompleted, CreatedAt: old, FinishedAt: recent},
+			"old-running":   {ID: "old-running", Status: TaskRunning, CreatedAt: old},
+			"old-pending":   {ID: "old-pending", Status: TaskPending, CreatedAt: old},
+		}
+
+		if got := n.compactTasks(now); got != 3 {
+			t.Errorf("archive=%v: compactTasks() = %d, want 3", archive, got)
+		}
+		for _, id := range []string{"recent-done", "old-running", "old-pending"} {
+			if _, ok := n.tasks[id]; !ok {
+				t.Errorf("archive=%v: %s compacted, want kept", archive, id)
+			}
+		}
+		if len(n.tasks) != 3 {
+			t.Errorf("archive=%v: %d tasks left, want 3", archive, len(n.tasks))
+		}
+
+		stats := n.compaction
+		wantArchived := uint64(0)
+		if archive {
+			wantArchived = 3
+		}
+		if stats.Runs != 1 || stats.Compacted != 3 || stats.Archived != wantArchived || !stats.LastRun.Equal(now) {
+			t.Errorf("archive=%v: stats = %+v", archive, stats)
+		}
+
+		path := filepath.Join(dir, "tasks-2025-06-01.jsonl")
+		f, err := os.Open(path)
+		if !archive {
+			if err == nil {
+				f.Close()
+				t.Errorf("archive file written with archiving disabled")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("open archive: %v", err)
+		}
+		var ids []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var task Task
+			if err := json.Unmarshal(scanner.Bytes(), &task); err != nil {
+				t.Fatalf("decode archived task: %v", err)
+			}
+			ids = append(ids, task.ID)
+		}
+		f.Close()
+		if len(ids) != 3 || ids[0] != "old-dead" {
+			t.Errorf("archived %v, want 3 tasks oldest first", ids)
+		}
+	}
+}
+
+func TestCompactTasksArchivesOnce(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	n := NewAINode(Config{DataDir: dir, ArchiveTasks: true})
+	n.tasks["a"] = &Task{ID: "a", Status: TaskCompleted, FinishedAt: now.Add(-2 * time.Hour)}
+	n.tasks["b"] = &Task{ID: "b", Status: TaskRunning}
+	n.compactTasks(now)
+
+	n.tasks["b"].Status, n.tasks["b"].FinishedAt = TaskCompleted, now.Add(-2*time.Hour)
+	n.compactTasks(now.Add(time.Minute))
+
+	data, err := os.ReadFile(filepath.Join(dir, "tasks-2025-06-01.jsonl"))
+	if err != nil {
+		t.Fatalf("read archive: %v", err)
+	}
+	seen := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var task Task
+		if err := json.Unmarshal([]byte(line), &task); err != nil {
+			t.Fatalf("decode archived task: %v", err)
+		}
+		seen[task.ID]++
+	}
+	if seen["a"] != 1 || seen["b"] != 1 || len(seen) != 2 {
+		t.Errorf("archived %v, want a and b once each", seen)
+	}
+	if len(n.tasks) != 0 || n.compaction.Archived != 2 {
+		t.Errorf("%d tasks left, %d archived; want 0 and 2", len(n.tasks), n.compaction.Archived)
+	}
+}
+
+func TestCompactTasksArchiveFailureKeepsTasks(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	os.WriteFile(blocker, nil, 0o600)
+
+	now := time.Now()
+	n := NewAINode(Config{DataDir: filepath.Join(blocker, "data"), ArchiveTasks: true})
+	n.tasks["done"] = &Task{ID: "done", Status: TaskCompleted, FinishedAt: now.Add(-2 * time.Hour)}
+
+	if got := n.compactTasks(now); got != 0 {
+		t.Errorf("compactTasks() = %d, want 0", got)
+	}
+	if _, ok := n.tasks["done"]; !ok {
+		t.Error("task removed although archiving failed")
+	}
+	if n.compaction.LastError == "" {
+		t.Error("LastError empty, want the archive failure")
+	}
+}
+
+func TestTaskRetention(t *testing.T) {
+	tests := []struct {
+		configured time.Duration
+		want       time.Duration
+	}{
+		{0, defaultTaskRetention},
+		{time.Second, defaultMaxRequestTimeout},
+		{3 * time.Hour, 3 * time.Hour},
+	}
+	for _, tt := range tests {
+		n := NewAINode(Config{TaskRetention: tt.configured})
+		if got := n.taskRetention(); got != tt.want {
+			t.Errorf("taskRetention(%v) = %v, want %v", tt.configured, got, tt.want)
+		}
+	}
+}
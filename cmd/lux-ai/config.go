@@ -0,0 +1,151 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/luxfi/ai/pkg/attestation"
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+// Validate checks the configuration before the node starts, returning one
+// error per problem joined together, so an operator can fix them all in
+// one pass instead of discovering them at runtime. Validate creates
+// DataDir if needed to check that it is writable.
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Port < 1 || c.Port > 65535 {
+		add("port", "%d is outside 1-65535", c.Port)
+	}
+	if err := checkWritableDir(c.DataDir); err != nil {
+		add("data_dir", "%v", err)
+	}
+	if c.NodeURL != "" {
+		if u, err := url.Parse(c.NodeURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("node_url", "%q is not an http(s) URL such as http://localhost:9650", c.NodeURL)
+		}
+	}
+	if c.EnableCORS {
+		for _, origin := range c.AllowedOrigins {
+			if origin == "" {
+				add("allowed_origins", "empty origin; use \"*\" to allow every origin")
+				break
+			}
+		}
+	}
+	if _, err := NewScheduler(c.Scheduler); err != nil {
+		add("scheduler", "%v; want %s, %s or %s", err, SchedulerRoundRobin, SchedulerLeastLoaded, SchedulerTrustWeighted)
+	}
+	if err := validateContextPolicy(c.ContextOverflowPolicy); err != nil {
+		add("context_overflow_policy", "%v; want %s, %s or %s", err, ContextPolicyReject, ContextPolicyTruncateHead, ContextPolicyTruncatePreserveSystem)
+	}
+
+	// Zero selects the default for every limit; negative values are mistakes
+	for _, limit := range []struct {
+		field string
+		value int
+	}{
+		{"max_choices", c.MaxChoices},
+		{"max_retries", c.MaxRetries},
+		{"max_stop_sequences", c.MaxStopSequences},
+		{"max_messages", c.MaxMessages},
+		{"max_prompt_bytes", c.MaxPromptBytes},
+		{"max_body_bytes", c.MaxBodyBytes},
+		{"max_batch_concurrency", c.MaxBatchConcurrency},
+		{"max_capacity_tps", c.MaxCapacityTPS},
+		{"rate_limit_rpm", c.RateLimitRPM},
+		{"redact_id_prefix", c.RedactIDPrefix},
+		{"redact_hash_bytes", c.RedactHashBytes},
+	} {
+		if limit.value < 0 {
+			add(limit.field, "%d is negative; use 0 for the default", limit.value)
+		}
+	}
+	for _, d := range []struct {
+		field string
+		value time.Duration
+	}{
+		{"request_timeout", c.RequestTimeout},
+		{"max_request_timeout", c.MaxRequestTimeout},
+		{"task_retention", c.TaskRetention},
+	} {
+		if d.value < 0 {
+			add(d.field, "%s is negative; use 0 for the default", d.value)
+		}
+	}
+	if c.RedactHashBytes > attestation.MaxRedactHashBytes {
+		add("redact_hash_bytes", "%d exceeds the %d-byte SHA-256", c.RedactHashBytes, attestation.MaxRedactHashBytes)
+	}
+	if c.RequestTimeout > 0 && c.MaxRequestTimeout > 0 && c.RequestTimeout > c.MaxRequestTimeout {
+		add("request_timeout", "%s exceeds max_request_timeout %s", c.RequestTimeout, c.MaxRequestTimeout)
+	}
+
+	models := defaultModels()
+	for _, id := range sortedKeys(c.ModelMinTrust) {
+		if _, ok := models[id]; !ok {
+			add("model_min_trust", "unknown model %s", id)
+		}
+		if score := c.ModelMinTrust[id]; score > 100 {
+			add("model_min_trust", "%s: score %d is outside 0-100", id, score)
+		}
+	}
+	for _, problem := range c.ModelRoutes.validate(models) {
+		add("model_routes", "%s", problem)
+	}
+	tiers := make([]cc.CCTier, 0, len(c.TierRPM))
+	for tier := range c.TierRPM {
+		tiers = append(tiers, tier)
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i] < tiers[j] })
+	for _, tier := range tiers {
+		if rpm := c.TierRPM[tier]; !validTier(tier) {
+			add("tier_rpm", "tier %d is outside 1-4", tier)
+		} else if rpm < 0 {
+			add("tier_rpm", "tier %d: %d requests per minute is negative", tier, rpm)
+		}
+	}
+	for _, key := range sortedKeys(c.KeyTiers) {
+		if tier := c.KeyTiers[key]; !validTier(tier) {
+			add("key_tiers", "key %.4s...: tier %d is outside 1-4", key, tier)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkWritableDir creates dir if needed and checks that files can be
+// created in it
+func checkWritableDir(dir string) error {
+	if dir == "" {
+		return errors.New("not set")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// sortedKeys returns m's keys in order, so errors are reported stably
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
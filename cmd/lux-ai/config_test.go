@@ -0,0 +1,81 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+func TestConfigValidate(t *testing.T) {
+	valid := func(t *testing.T) Config {
+		return Config{Port: 9090, DataDir: filepath.Join(t.TempDir(), "data"), NodeURL: "http://localhost:9650"}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, c *Config)
+		want   []string // Fields expected in the error, in order
+	}{
+		{"valid", func(t *testing.T, c *Config) {}, nil},
+		{"port", func(t *testing.T, c *Config) { c.Port = 70000 }, []string{"port"}},
+		{"data dir unset", func(t *testing.T, c *Config) { c.DataDir = "" }, []string{"data_dir"}},
+		{"data dir is a file", func(t *testing.T, c *Config) {
+			c.DataDir = filepath.Join(t.TempDir(), "file")
+			os.WriteFile(c.DataDir, nil, 0644)
+		}, []string{"data_dir"}},
+		{"node url", func(t *testing.T, c *Config) { c.NodeURL = "localhost:9650" }, []string{"node_url"}},
+		{"scheduler", func(t *testing.T, c *Config) { c.Scheduler = "fastest" }, []string{"scheduler"}},
+		{"redaction", func(t *testing.T, c *Config) {
+			c.RedactIDPrefix, c.RedactHashBytes = -1, 33
+		}, []string{"redact_id_prefix", "redact_hash_bytes"}},
+		{"timeout above ceiling", func(t *testing.T, c *Config) {
+			c.RequestTimeout, c.MaxRequestTimeout = time.Minute, time.Second
+		}, []string{"request_timeout"}},
+		{"every problem", func(t *testing.T, c *Config) {
+			c.Port = 0
+			c.ContextOverflowPolicy = "drop"
+			c.MaxRetries = -1
+			c.MaxBatchConcurrency = -2
+			c.TaskRetention = -time.Hour
+			c.ModelMinTrust = map[string]uint8{"zen-mini-0.5b": 101, "gpt-9": 10}
+			c.TierRPM = map[cc.CCTier]int{cc.Tier1GPUNativeCC: -5, 7: 100}
+			c.KeyTiers = map[string]cc.CCTier{"sk-test": 9}
+		}, []string{"port", "context_overflow_policy", "max_retries", "max_batch_concurrency", "task_retention",
+			"model_min_trust: unknown model gpt-9", "model_min_trust: zen-mini-0.5b", "tier_rpm: tier 1", "tier_rpm: tier 7", "key_tiers"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid(t)
+			tt.modify(t, &c)
+			err := c.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				if _, err := os.Stat(c.DataDir); err != nil {
+					t.Errorf("data dir not created: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() error = nil, want %v", tt.want)
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("Validate() reported %d problems, want %d:\n%v", len(lines), len(tt.want), err)
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(lines[i], want) {
+					t.Errorf("problem %d = %q, want prefix %q", i, lines[i], want)
+				}
+			}
+		})
+	}
+}
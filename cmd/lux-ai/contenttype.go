@@ -0,0 +1,67 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// jsonMiddleware wraps the routes that decode a JSON POST body. It rejects
+// POST requests whose body is not declared as JSON with 415, so a form or
+// text payload gets a precise error instead of a decode failure.
+// Parameters such as charset are allowed. Gzip-encoded bodies are exempt,
+// since clients compressing a request commonly label it by its encoding
+// rather than its content.
+//
+// Bodies are capped at maxBodyBytes. One declared larger is rejected with
+// 413 before it is read; one that grows past the cap fails the handler's
+// read, which reports 413 through bodyErrorStatus.
+func (n *AINode) jsonMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && !isJSONContent(r) {
+			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+		if limit := n.maxBodyBytes(); r.ContentLength > limit {
+			http.Error(w, fmt.Sprintf("request body exceeds the limit of %d bytes", limit), http.StatusRequestEntityTooLarge)
+			return
+		} else if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		next(w, r)
+	}
+}
+
+// ndjsonMiddleware wraps the routes that stream an NDJSON POST body,
+// rejecting other bodies with 415 like jsonMiddleware. application/json is
+// accepted as well, since a one-item batch is also a JSON document. The
+// body is not capped: these handlers read it one bounded line at a time.
+func (n *AINode) ndjsonMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && !hasContentType(r, "application/x-ndjson", "application/json") {
+			http.Error(w, "Content-Type must be application/x-ndjson", http.StatusUnsupportedMediaType)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// isJSONContent reports whether r declares a JSON body or is gzip-encoded
+func isJSONContent(r *http.Request) bool {
+	return hasContentType(r, "application/json")
+}
+
+// hasContentType reports whether r declares one of mediaTypes or is
+// gzip-encoded
+func hasContentType(r *http.Request, mediaTypes ...string) bool {
+	if strings.EqualFold(strings.TrimSpace(r.Header.Get("Content-Encoding")), "gzip") {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && slices.Contains(mediaTypes, mediaType)
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONContentTypeEnforced(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		encoding    string
+		want        bool
+	}{
+		{"json", "application/json", "", true},
+		{"charset", "application/json; charset=utf-8", "", true},
+		{"uppercase", "Application/JSON", "", true},
+		{"missing", "", "", false},
+		{"form", "application/x-www-form-urlencoded", "", false},
+		{"text", "text/plain", "", false},
+		{"json suffix", "application/problem+json", "", false},
+		{"malformed", "application/json; charset", "", false},
+		{"gzip", "application/octet-stream", "gzip", true},
+		{"gzip unlabelled", "", "gzip", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{})
+			req := httptest.NewRequest("POST", "/api/miners/register", strings.NewReader(`{"id":"m"}`))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			if got := isJSONContent(req); got != tt.want {
+				t.Errorf("isJSONContent() = %v, want %v", got, tt.want)
+			}
+			if tt.encoding != "" {
+				return // The handler cannot decode a compressed body
+			}
+
+			rec := httptest.NewRecorder()
+			n.newMux().ServeHTTP(rec, req)
+			if got := rec.Code != http.StatusUnsupportedMediaType; got != tt.want {
+				t.Errorf("status = %d, want accepted %v", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONContentTypeRoutes(t *testing.T) {
+	mux := NewAINode(Config{}).newMux()
+	for path, want := range map[string]int{
+		"/v1/chat/completions":     http.StatusUnsupportedMediaType,
+		"/v1/embeddings":           http.StatusUnsupportedMediaType,
+		"/api/tasks/submit":        http.StatusUnsupportedMediaType,
+		"/v1/audio/transcriptions": http.StatusNotImplemented, // Not a JSON route
+	} {
+		req := httptest.NewRequest("POST", path, strings.NewReader("prompt=hi"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("POST %s: status = %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	// Other methods carry no body and are not checked
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/tasks/submit", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /api/tasks/submit: status = %d, want 405", rec.Code)
+	}
+}
+
+func TestNDJSONBatchRoute(t *testing.T) {
+	// A batch may be larger than the JSON body limit; only items are capped
+	n := NewAINode(Config{MaxBodyBytes: 64})
+	batch := strings.Repeat(`{"input":"hello world","model":"zen-mini-0.5b"}`+"\n", 4)
+
+	tests := []struct {
+		name        string
+		contentType string
+		status      int
+		lines       int
+	}{
+		{"ndjson", "application/x-ndjson", http.StatusOK, 4},
+		{"ndjson charset", "application/x-ndjson; charset=utf-8", http.StatusOK, 4},
+		{"json", "application/json", http.StatusOK, 4},
+		{"form", "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType, 0},
+		{"missing", "", http.StatusUnsupportedMediaType, 0},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/v1/embeddings/batch", strings.NewReader(batch))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		rec := httptest.NewRecorder()
+		n.newMux().ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		if got := strings.Count(rec.Body.String(), `"object":"embedding"`); got != tt.lines {
+			t.Errorf("%s: %d embeddings, want %d: %s", tt.name, got, tt.lines, rec.Body)
+		}
+	}
+}
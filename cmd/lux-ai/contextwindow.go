@@ -0,0 +1,125 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Context overflow policies, applied when a chat prompt plus max_tokens
+// does not fit the model's context window
+const (
+	// ContextPolicyReject fails the request with 400
+	ContextPolicyReject = "reject"
+
+	// ContextPolicyTruncateHead drops the oldest messages until the prompt fits
+	ContextPolicyTruncateHead = "truncate-head"
+
+	// ContextPolicyTruncatePreserveSystem drops the oldest non-system
+	// messages until the prompt fits
+	ContextPolicyTruncatePreserveSystem = "truncate-preserve-system"
+)
+
+// Headers reporting what a truncating policy dropped from the prompt
+const (
+	DroppedMessagesHeader = "X-Lux-Context-Dropped-Messages"
+	DroppedTokensHeader   = "X-Lux-Context-Dropped-Tokens"
+)
+
+// contextDrop describes the messages removed to fit a context window
+type contextDrop struct {
+	Messages int
+	Tokens   int
+}
+
+// validateContextPolicy rejects unknown context overflow policies
+func validateContextPolicy(policy string) error {
+	switch policy {
+	case "", ContextPolicyReject, ContextPolicyTruncateHead, ContextPolicyTruncatePreserveSystem:
+		return nil
+	default:
+		return fmt.Errorf("unknown context overflow policy %q", policy)
+	}
+}
+
+// contextPolicy returns the configured context overflow policy
+func (n *AINode) contextPolicy() string {
+	if n.config.ContextOverflowPolicy != "" {
+		return n.config.ContextOverflowPolicy
+	}
+	return ContextPolicyReject
+}
+
+// fitContext makes req's prompt plus max_tokens fit model's context window
+// under policy, dropping messages from req when the policy truncates. The
+// most recent message is never dropped. Models without a context size are
+// not enforced.
+func fitContext(req *ChatRequest, model *ModelInfo, policy string) (contextDrop, error) {
+	var drop contextDrop
+	if model == nil || model.ContextSize <= 0 {
+		return drop, nil
+	}
+	budget := model.ContextSize - max(req.MaxTokens, 0)
+	if budget <= 0 {
+		return drop, fmt.Errorf("max_tokens %d exceeds %s context window of %d tokens",
+			req.MaxTokens, model.ID, model.ContextSize)
+	}
+
+	prompt := estimatePromptTokens(req)
+	if prompt <= budget {
+		return drop, nil
+	}
+	overflow := func() error {
+		if req.MaxTokens > 0 {
+			return fmt.Errorf("prompt of %d tokens plus max_tokens %d exceeds %s context window of %d tokens",
+				prompt, req.MaxTokens, model.ID, model.ContextSize)
+		}
+		return fmt.Errorf("prompt of %d tokens exceeds %s context window of %d tokens",
+			prompt, model.ID, model.ContextSize)
+	}
+	if policy != ContextPolicyTruncateHead && policy != ContextPolicyTruncatePreserveSystem {
+		return drop, overflow()
+	}
+
+	tokens := prompt
+	last := len(req.Messages) - 1
+	keep := make([]bool, len(req.Messages))
+	for i := range keep {
+		keep[i] = true
+	}
+	for i := 0; i < last && tokens > budget; i++ {
+		m := req.Messages[i]
+		if policy == ContextPolicyTruncatePreserveSystem && m.Role == "system" {
+			continue
+		}
+		keep[i] = false
+		size := estimateTokens(m.Content) + 4
+		tokens -= size
+		drop.Messages++
+		drop.Tokens += size
+	}
+	if tokens > budget {
+		return contextDrop{}, overflow()
+	}
+
+	kept := make([]ChatMessage, 0, len(req.Messages)-drop.Messages)
+	for i, m := range req.Messages {
+		if keep[i] {
+			kept = append(kept, m)
+		}
+	}
+	req.Messages = kept
+	return drop, nil
+}
+
+// setDropHeaders reports a truncation on the response
+func setDropHeaders(w http.ResponseWriter, drop contextDrop) {
+	if drop.Messages == 0 {
+		return
+	}
+	w.Header().Set(DroppedMessagesHeader, strconv.Itoa(drop.Messages))
+	w.Header().Set(DroppedTokensHeader, strconv.Itoa(drop.Tokens))
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFitContext(t *testing.T) {
+	model := &ModelInfo{ID: "small", ContextSize: 100}
+	// Each message is 10+4 = 14 estimated tokens
+	msg := func(role string, i int) ChatMessage {
+		return ChatMessage{Role: role, Content: strings.Repeat(string(rune('a'+i)), 40)}
+	}
+	conversation := func() []ChatMessage {
+		msgs := []ChatMessage{msg("system", 0)}
+		for i := 1; i < 10; i++ {
+			msgs = append(msgs, msg("user", i))
+		}
+		return msgs // 140 tokens
+	}
+
+	tests := []struct {
+		name      string
+		policy    string
+		maxTokens int
+		messages  []ChatMessage
+		wantErr   bool
+		wantDrop  contextDrop
+		wantFirst string // role+letter of the first kept message
+	}{
+		{"fits", ContextPolicyReject, 0, conversation()[:7], false, contextDrop{}, "system a"},
+		{"reject", ContextPolicyReject, 0, conversation(), true, contextDrop{}, ""},
+		{"default is reject", "", 0, conversation(), true, contextDrop{}, ""},
+		{"truncate head", ContextPolicyTruncateHead, 0, conversation(), false, contextDrop{Messages: 3, Tokens: 42}, "user d"},
+		{"preserve system", ContextPolicyTruncatePreserveSystem, 0, conversation(), false, contextDrop{Messages: 3, Tokens: 42}, "system a"},
+		{"max_tokens reserved", ContextPolicyTruncateHead, 30, conversation(), false, contextDrop{Messages: 5, Tokens: 70}, "user f"},
+		{"max_tokens over window", ContextPolicyTruncateHead, 100, conversation()[:1], true, contextDrop{}, ""},
+		{"last message too long", ContextPolicyTruncateHead, 0, []ChatMessage{msg("user", 1), {Role: "user", Content: strings.Repeat("z", 400)}}, true, contextDrop{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &ChatRequest{Messages: tt.messages, MaxTokens: tt.maxTokens}
+			before := len(req.Messages)
+			drop, err := fitContext(req, model, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fitContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(req.Messages) != before {
+					t.Errorf("failed fit dropped messages: %d of %d left", len(req.Messages), before)
+				}
+				return
+			}
+			if drop != tt.wantDrop {
+				t.Errorf("fitContext() drop = %+v, want %+v", drop, tt.wantDrop)
+			}
+			if len(req.Messages) != before-drop.Messages {
+				t.Errorf("%d messages left, want %d", len(req.Messages), before-drop.Messages)
+			}
+			first := req.Messages[0]
+			if got := first.Role + " " + first.Content[:1]; got != tt.wantFirst {
+				t.Errorf("first message = %q, want %q", got, tt.wantFirst)
+			}
+			if last := req.Messages[len(req.Messages)-1]; last != tt.messages[len(tt.messages)-1] {
+				t.Errorf("last message = %+v, want the most recent kept", last)
+			}
+			if tokens := estimatePromptTokens(req) + tt.maxTokens; tokens > model.ContextSize {
+				t.Errorf("%d tokens after fitting, want at most %d", tokens, model.ContextSize)
+			}
+		})
+	}
+}
+
+func TestChatContextOverflow(t *testing.T) {
+	// zen-mini-0.5b has an 8192 token context
+	msgs := []ChatMessage{{Role: "system", Content: "be brief"}}
+	for i := 0; i < 5; i++ {
+		msgs = append(msgs, ChatMessage{Role: "user", Content: strings.Repeat("x", 8000)})
+	}
+	body, _ := json.Marshal(ChatRequest{Model: "zen-mini-0.5b", Messages: msgs})
+
+	tests := []struct {
+		policy      string
+		wantStatus  int
+		wantDropped string
+	}{
+		{"", http.StatusBadRequest, ""},
+		{ContextPolicyTruncateHead, http.StatusOK, "2"},
+		{ContextPolicyTruncatePreserveSystem, http.StatusOK, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			n := NewAINode(Config{ContextOverflowPolicy: tt.policy})
+			rec := chatRequest(t, n, string(body))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got := rec.Header().Get(DroppedMessagesHeader); got != tt.wantDropped {
+				t.Errorf("%s = %q, want %q", DroppedMessagesHeader, got, tt.wantDropped)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		enableCORS bool
+		method     string
+		wantStatus int
+		wantCORS   bool
+	}{
+		{"preflight with CORS", true, "OPTIONS", http.StatusNoContent, true},
+		{"preflight without CORS", false, "OPTIONS", http.StatusNoContent, false},
+		{"GET with CORS", true, "GET", http.StatusMethodNotAllowed, true},
+		{"GET without CORS", false, "GET", http.StatusMethodNotAllowed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{EnableCORS: tt.enableCORS})
+			handler := n.corsMiddleware(n.handleChatCompletions)
+
+			req := httptest.NewRequest(tt.method, "/v1/chat/completions", nil)
+			req.Header.Set("Origin", "https://example.com")
+			req.Header.Set("Access-Control-Request-Method", "POST")
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin") != ""; got != tt.wantCORS {
+				t.Errorf("CORS headers present = %v, want %v", got, tt.wantCORS)
+			}
+			if tt.method == "OPTIONS" && rec.Header().Get("Allow") == "" {
+				t.Error("OPTIONS response missing Allow header")
+			}
+			if tt.method == "OPTIONS" && rec.Body.Len() != 0 {
+				t.Errorf("OPTIONS response body = %q, want empty", rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+// Task statuses
+const (
+	TaskPending   = "pending"  // Waiting for a miner
+	TaskAssigned  = "assigned" // Assigned, not yet fetched by the miner
+	TaskRunning   = "running"  // Fetched by the miner
+	TaskCompleted = "completed"
+	TaskFailed    = "failed" // Reported by a miner; the task is retried or dead-lettered
+	TaskDead      = "dead"   // Retries exhausted
+	TaskCancelled = "cancelled"
+)
+
+const (
+	// dispatchTimeout is how long a client request waits for its task by
+	// default; see timeout.go
+	dispatchTimeout = 30 * time.Second
+
+	// defaultMaxChoices caps n on chat requests when Config.MaxChoices is unset
+	defaultMaxChoices = 8
+)
+
+var (
+	errNoMiners   = errors.New("no miners available")
+	errTaskFailed = errors.New("task failed")
+	errTaskDead   = errors.New("task failed after retries")
+
+	errInvalidResultStatus = errors.New(`result status must be "completed" or "failed"`)
+
+	errInsufficientVRAM  = errors.New("insufficient GPU memory")
+	errInsufficientTrust = errors.New("insufficient trust score")
+	errModelNotServed    = errors.New("no miner serves the model")
+)
+
+// generate dispatches count identical tasks, possibly to different miners,
+// and waits for all of them. Outputs are returned in dispatch order. Each
+// task counts as in flight for model until it finishes, times out or the
+// request is cancelled. If one task cannot be dispatched, those already
+// dispatched are cancelled.
+func (n *AINode) generate(r *http.Request, taskType, model string, input json.RawMessage, count int) ([]json.RawMessage, error) {
+	rng := n.requestRNG(r)
+	region := requestRegion(r)
+	owner := taskOwner(r)
+	tasks := make([]*Task, count)
+	releases := make([]func(), count)
+	defer func() {
+		for _, release := range releases {
+			if release != nil {
+				release()
+			}
+		}
+	}()
+	for i := range tasks {
+		task, err := n.dispatch(rng, region, owner, taskType, model, input)
+		if err != nil {
+			// Nobody will await the completions already dispatched
+			n.cancelTasks(tasks[:i], "another completion of the request could not be dispatched")
+			return nil, err
+		}
+		tasks[i] = task
+		releases[i] = n.inflight.Acquire(model)
+	}
+
+	// The first failure stops the wait on the other completions
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	outputs := make([]json.RawMessage, count)
+	var (
+		wg      sync.WaitGroup
+		failed  sync.Once
+		failure error
+	)
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			defer releases[i]()
+			done, err := n.awaitTask(ctx, id)
+			if err != nil {
+				failed.Do(func() {
+					failure = err
+					cancel()
+				})
+				return
+			}
+			outputs[i] = done.Output
+		}(i, task.ID)
+	}
+	wg.Wait()
+
+	if failure != nil {
+		// Nobody will await the completions still running
+		n.cancelTasks(tasks, "another completion of the request failed")
+		return nil, failure
+	}
+	return outputs, nil
+}
+
+// cancelTasks cancels those of tasks that have not finished, freeing their
+// miners
+func (n *AINode) cancelTasks(tasks []*Task, reason string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, t := range tasks {
+		if !t.finished() {
+			n.cancelTaskLocked(t, reason)
+		}
+	}
+}
+
+// dispatch creates a task for owner (see taskOwner) and assigns it to the
+// miner chosen by the scheduler among those qualified to serve model,
+// preferring those in region when it is set. It returns errNoMiners when
+// no miner is registered and errModelNotServed, errInsufficientVRAM or
+// errInsufficientTrust when none qualifies.
+func (n *AINode) dispatch(rng *rand.Rand, region, owner, taskType, model string, input json.RawMessage) (*Task, error) {
+	id, err := newTaskID()
+	if err != nil {
+		return nil, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	miners := n.sortedMinersLocked()
+	if len(miners) == 0 {
+		return nil, errNoMiners
+	}
+	fit, err := n.qualifiedLocked(miners, model)
+	if err != nil {
+		return nil, err
+	}
+	if region != "" {
+		if local := inRegion(fit, region); len(local) > 0 {
+			fit = local
+		}
+	}
+	miner := n.scheduler.Select(fit, rng)
+	if miner == nil {
+		return nil, errNoMiners
+	}
+
+	task := &Task{
+		ID:        id,
+		Type:      taskType,
+		Model:     model,
+		Level:     n.modelingLevelLocked(model),
+		Input:     input,
+		CreatedAt: n.clock.Now(),
+		owner:     owner,
+	}
+	n.tasks[id] = task
+	n.recordLocked(task, EventCreated, "")
+	n.assignLocked(task, miner, TaskAssigned)
+	return task, nil
+}
+
+// assignLocked records a new attempt of t on miner. Caller holds n.mu.
+func (n *AINode) assignLocked(t *Task, miner *MinerInfo, status string) {
+	t.AssignedTo = miner.ID
+	t.AssignedAt = n.clock.Now()
+	t.Status = status
+	t.Attempts++
+	t.TriedMiners = append(t.TriedMiners, miner.ID)
+	t.assignedKey = miner.PublicKey
+	n.recordLocked(t, EventAssigned, fmt.Sprintf("attempt %d", t.Attempts))
+	miner.ActiveTasks++
+	trackLevel(miner, t.Level, 1)
+}
+
+// writeGenerateError answers a request whose generate call failed
+func writeGenerateError(w http.ResponseWriter, err error) {
+	switch {
+	case unqualified(err):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	case errors.Is(err, context.DeadlineExceeded):
+		http.Error(w, "timeout waiting for miner", http.StatusGatewayTimeout)
+	case errors.Is(err, errTaskCancelled):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
+
+// awaitTask waits for a dispatched task to finish and returns a copy. It
+// waits for the timeout carried by ctx and cancels the task if it runs out.
+func (n *AINode) awaitTask(ctx context.Context, id string) (*Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutFrom(ctx))
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				n.cancelTimedOut(id)
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+			n.mu.RLock()
+			t, ok := n.tasks[id]
+			var snapshot Task
+			if ok {
+				snapshot = *t
+			}
+			n.mu.RUnlock()
+
+			switch {
+			case !ok:
+				return nil, errTaskFailed
+			case snapshot.Status == TaskCompleted:
+				return &snapshot, nil
+			case snapshot.Status == TaskDead:
+				return nil, fmt.Errorf("%w: %s", errTaskDead, strings.Join(snapshot.Failures, "; "))
+			case snapshot.Status == TaskCancelled:
+				return nil, errTaskCancelled
+			}
+		}
+	}
+}
+
+// claimTasksLocked hands a miner the tasks assigned to it, plus any
+// unassigned pending tasks, and marks them running. Caller holds n.mu.
+func (n *AINode) claimTasksLocked(minerID string) []*Task {
+	miner, ok := n.miners[minerID]
+	if !ok {
+		return []*Task{}
+	}
+	miner.LastSeen = n.clock.Now()
+
+	claimed := make([]*Task, 0)
+	for _, t := range n.tasks {
+		switch {
+		case t.Status == TaskAssigned && t.AssignedTo == minerID:
+			t.Status = TaskRunning
+		case t.Status == TaskPending && t.AssignedTo == "" && n.qualifiesLocked(miner, t.Model):
+			n.assignLocked(t, miner, TaskRunning)
+		default:
+			continue
+		}
+		n.recordLocked(t, EventRunning, "")
+		claimed = append(claimed, t)
+	}
+	sort.Slice(claimed, func(i, j int) bool {
+		return claimed[i].CreatedAt.Before(claimed[j].CreatedAt)
+	})
+	return claimed
+}
+
+// finishTaskLocked releases the miner's slot once an attempt completes or
+// fails. Caller holds n.mu.
+func (n *AINode) finishTaskLocked(t *Task) {
+	miner, ok := n.miners[t.AssignedTo]
+	if !ok {
+		return
+	}
+	if miner.ActiveTasks > 0 {
+		miner.ActiveTasks--
+	}
+	trackLevel(miner, t.Level, -1)
+	if t.Status == TaskCompleted {
+		miner.TasksHandled++
+	}
+}
+
+// sortedMinersLocked returns registered miners ordered by ID so scheduling
+// is deterministic for a given RNG seed. Caller holds n.mu.
+func (n *AINode) sortedMinersLocked() []*MinerInfo {
+	miners := make([]*MinerInfo, 0, len(n.miners))
+	for _, m := range n.miners {
+		miners = append(miners, m)
+	}
+	sort.Slice(miners, func(i, j int) bool { return miners[i].ID < miners[j].ID })
+	return miners
+}
+
+// minVRAMGBLocked returns the GPU memory needed to serve model, 0 if the
+// model is unknown or has no requirement. Caller holds n.mu.
+func (n *AINode) minVRAMGBLocked(model string) uint64 {
+	if m, ok := n.models[model]; ok {
+		return m.MinVRAMGB
+	}
+	return 0
+}
+
+// minTrustLocked returns the trust score a miner needs to serve model, 0 if
+// the model is unknown or has no requirement. Caller holds n.mu.
+func (n *AINode) minTrustLocked(model string) uint8 {
+	if m, ok := n.models[model]; ok {
+		return m.MinTrustScore
+	}
+	return 0
+}
+
+// qualifiesLocked reports whether miner serves model, has the GPU memory
+// and trust score it requires and capacity at its modeling level. Caller
+// holds n.mu.
+func (n *AINode) qualifiesLocked(miner *MinerInfo, model string) bool {
+	return servesModel(miner, model) && hasVRAM(miner, n.minVRAMGBLocked(model)) &&
+		miner.TrustScore >= n.minTrustLocked(model) && hasLevelCapacity(miner, n.modelingLevelLocked(model))
+}
+
+// qualifiedLocked returns the miners that can serve model, or an error
+// naming the requirement none of them meets. Caller holds n.mu.
+func (n *AINode) qualifiedLocked(miners []*MinerInfo, model string) ([]*MinerInfo, error) {
+	if len(miners) == 0 {
+		return miners, nil
+	}
+	serving := make([]*MinerInfo, 0, len(miners))
+	for _, m := range miners {
+		if servesModel(m, model) {
+			serving = append(serving, m)
+		}
+	}
+	if len(serving) == 0 {
+		return nil, fmt.Errorf("%w: %s", errModelNotServed, model)
+	}
+
+	need := n.minVRAMGBLocked(model)
+	fit := withVRAM(serving, need)
+	if len(fit) == 0 {
+		return nil, fmt.Errorf("%w: no miner with >=%dGB VRAM for model %s", errInsufficientVRAM, need, model)
+	}
+
+	minTrust := n.minTrustLocked(model)
+	trusted := make([]*MinerInfo, 0, len(fit))
+	for _, m := range fit {
+		if m.TrustScore >= minTrust {
+			trusted = append(trusted, m)
+		}
+	}
+	if len(trusted) == 0 {
+		return nil, fmt.Errorf("%w: no miner with trust score >=%d for model %s", errInsufficientTrust, minTrust, model)
+	}
+
+	level := n.modelingLevelLocked(model)
+	free := make([]*MinerInfo, 0, len(trusted))
+	for _, m := range trusted {
+		if hasLevelCapacity(m, level) {
+			free = append(free, m)
+		}
+	}
+	if len(free) == 0 {
+		return nil, fmt.Errorf("%w: %s for model %s", errLevelUnavailable, level, model)
+	}
+	return free, nil
+}
+
+// unqualified reports whether err means miners are connected but none
+// qualifies to serve the model
+func unqualified(err error) bool {
+	return errors.Is(err, errModelNotServed) || errors.Is(err, errInsufficientVRAM) ||
+		errors.Is(err, errInsufficientTrust) || errors.Is(err, errLevelUnavailable)
+}
+
+// servesModel reports whether miner advertises model. A miner that has
+// never sent a model list is assumed to serve any model; one that sent an
+// empty list serves none.
+func servesModel(miner *MinerInfo, model string) bool {
+	return miner.Models == nil || slices.Contains(miner.Models, model)
+}
+
+// hasVRAM reports whether miner can hold a model needing gb decimal
+// gigabytes of GPU memory. Miners that don't report their memory are
+// assumed to fit.
+func hasVRAM(miner *MinerInfo, gb uint64) bool {
+	memory := &cc.HardwareCapability{GPUMemoryMB: miner.GPUMemoryMB}
+	return gb == 0 || miner.GPUMemoryMB == 0 || memory.MemoryBytes() >= cc.GBToBytes(gb)
+}
+
+// withVRAM returns the miners that can hold a model needing gb of GPU memory
+func withVRAM(miners []*MinerInfo, gb uint64) []*MinerInfo {
+	fit := make([]*MinerInfo, 0, len(miners))
+	for _, m := range miners {
+		if hasVRAM(m, gb) {
+			fit = append(fit, m)
+		}
+	}
+	return fit
+}
+
+// newTaskID returns a random task identifier
+func newTaskID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+	return "task-" + hex.EncodeToString(b), nil
+}
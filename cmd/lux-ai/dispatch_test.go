@@ -0,0 +1,417 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+	"github.com/luxfi/ai/pkg/clock"
+)
+
+func TestDispatchVRAMCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		miners    map[string]uint64 // miner ID -> GPU memory in MB
+		model     string
+		wantMiner string
+		wantErr   error
+	}{
+		{"fits", map[string]uint64{"big": 81559}, "qwen3-8b", "big", nil},
+		{"skips small GPU", map[string]uint64{"a-small": 8192, "b-big": 24564}, "qwen3-8b", "b-big", nil},
+		{"unreported memory fits", map[string]uint64{"unknown": 0}, "qwen3-8b", "unknown", nil},
+		{"overflowing report does not wrap", map[string]uint64{"huge": 1<<44 + 1}, "qwen3-8b", "huge", nil},
+		{"small model on small GPU", map[string]uint64{"small": 8192}, "zen-mini-0.5b", "small", nil},
+		{"unknown model", map[string]uint64{"tiny": 1024}, "custom", "tiny", nil},
+		{"none qualify", map[string]uint64{"small": 8192, "smaller": 4096}, "qwen3-8b", "", errInsufficientVRAM},
+		{"no miners", nil, "qwen3-8b", "", errNoMiners},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{})
+			for id, mb := range tt.miners {
+				n.miners[id] = &MinerInfo{ID: id, GPUMemoryMB: mb}
+			}
+			task, err := n.dispatch(rand.New(rand.NewSource(1)), "", "", "chat", tt.model, nil)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("dispatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && task.AssignedTo != tt.wantMiner {
+				t.Errorf("assigned to %q, want %q", task.AssignedTo, tt.wantMiner)
+			}
+		})
+	}
+}
+
+func TestChatInsufficientVRAM(t *testing.T) {
+	n := NewAINode(Config{})
+	n.miners["small"] = &MinerInfo{ID: "small", GPUMemoryMB: 8192}
+
+	rec := chatRequest(t, n, `{"model":"qwen3-8b","messages":[{"role":"user","content":"hi"}]}`)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, ">=24GB VRAM for model qwen3-8b") {
+		t.Errorf("body = %q, want the VRAM requirement", body)
+	}
+}
+
+func TestClaimSkipsTasksExceedingVRAM(t *testing.T) {
+	n := NewAINode(Config{})
+	mock := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	n.clock = mock
+	n.miners["small"] = &MinerInfo{ID: "small", GPUMemoryMB: 8192}
+	n.tasks["big"] = &Task{ID: "big", Model: "qwen3-8b", Status: TaskPending}
+	n.tasks["light"] = &Task{ID: "light", Model: "zen-mini-0.5b", Status: TaskPending}
+
+	claimed := n.claimTasksLocked("small")
+	if len(claimed) != 1 || claimed[0].ID != "light" {
+		t.Fatalf("claimed %v, want only the light task", claimed)
+	}
+	if n.tasks["big"].Status != TaskPending {
+		t.Errorf("big task status = %s, want pending", n.tasks["big"].Status)
+	}
+	if got := n.miners["small"].LastSeen; !got.Equal(mock.Now()) {
+		t.Errorf("last seen = %v, want the node clock's %v", got, mock.Now())
+	}
+}
+
+func TestDispatchRegion(t *testing.T) {
+	miners := map[string]*MinerInfo{
+		"a-eu":    {Region: "eu-west"},
+		"b-us":    {Region: "us-east"},
+		"c-us":    {Region: "us-east", GPUMemoryMB: 8192},
+		"d-local": {},
+	}
+
+	tests := []struct {
+		name   string
+		region string
+		model  string
+		want   []string // Acceptable miners
+	}{
+		{"same region", "eu-west", "zen-mini-0.5b", []string{"a-eu"}},
+		{"case-insensitive", "US-EAST", "zen-mini-0.5b", []string{"b-us", "c-us"}},
+		{"region without VRAM falls back to capable local", "us-east", "qwen3-8b", []string{"b-us"}},
+		{"unknown region falls back globally", "ap-south", "zen-mini-0.5b", []string{"a-eu", "b-us", "c-us", "d-local"}},
+		{"no region", "", "zen-mini-0.5b", []string{"a-eu", "b-us", "c-us", "d-local"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 8; seed++ {
+				n := NewAINode(Config{})
+				for id, m := range miners {
+					info := *m
+					info.ID = id
+					n.miners[id] = &info
+				}
+				task, err := n.dispatch(rand.New(rand.NewSource(seed)), tt.region, "", "chat", tt.model, nil)
+				if err != nil {
+					t.Fatalf("dispatch() error = %v", err)
+				}
+				found := false
+				for _, id := range tt.want {
+					found = found || task.AssignedTo == id
+				}
+				if !found {
+					t.Errorf("seed %d: assigned to %s, want one of %v", seed, task.AssignedTo, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatchMinTrust(t *testing.T) {
+	tests := []struct {
+		name      string
+		minTrust  uint8
+		miners    map[string]uint8 // miner ID -> trust score
+		wantMiner string
+		wantErr   error
+	}{
+		{"no requirement", 0, map[string]uint8{"low": 10}, "low", nil},
+		{"skips untrusted", 70, map[string]uint8{"a-low": 40, "b-high": 90}, "b-high", nil},
+		{"exact threshold", 70, map[string]uint8{"edge": 70}, "edge", nil},
+		{"none qualify", 70, map[string]uint8{"low": 69, "lower": 10}, "", errInsufficientTrust},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{ModelMinTrust: map[string]uint8{"zen-mini-0.5b": tt.minTrust}})
+			for id, score := range tt.miners {
+				n.miners[id] = &MinerInfo{ID: id, TrustScore: score}
+			}
+			task, err := n.dispatch(rand.New(rand.NewSource(1)), "", "", "chat", "zen-mini-0.5b", nil)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("dispatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && task.AssignedTo != tt.wantMiner {
+				t.Errorf("assigned to %q, want %q", task.AssignedTo, tt.wantMiner)
+			}
+		})
+	}
+}
+
+func TestChatInsufficientTrust(t *testing.T) {
+	n := NewAINode(Config{ModelMinTrust: map[string]uint8{"zen-mini-0.5b": 80}})
+	n.miners["low"] = &MinerInfo{ID: "low", TrustScore: 50}
+
+	rec := chatRequest(t, n, `{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}]}`)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "no miner with trust score >=80 for model zen-mini-0.5b") {
+		t.Errorf("body = %q, want the unmet trust score", body)
+	}
+
+	n.tasks["pending"] = &Task{ID: "pending", Model: "zen-mini-0.5b", Status: TaskPending}
+	if claimed := n.claimTasksLocked("low"); len(claimed) != 0 {
+		t.Errorf("untrusted miner claimed %d tasks, want 0", len(claimed))
+	}
+}
+
+func TestParseModelMinTrust(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]uint8
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"qwen3-8b=70, zen-coder-1.5b=0", map[string]uint8{"qwen3-8b": 70, "zen-coder-1.5b": 0}, false},
+		{"qwen3-8b=101", nil, true},
+		{"unknown=10", nil, true},
+		{"qwen3-8b", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseModelMinTrust(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseModelMinTrust(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("parseModelMinTrust(%q) = %v, want %v", tt.in, got, tt.want)
+			continue
+		}
+		for id, score := range tt.want {
+			if got[id] != score {
+				t.Errorf("parseModelMinTrust(%q)[%s] = %d, want %d", tt.in, id, got[id], score)
+			}
+		}
+	}
+}
+
+func TestGenerateMultiple(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := NewAINode(Config{})
+	runFakeMiner(ctx, n, "a", "b", "c")
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	outputs, err := n.generate(req, "chat", "zen-mini-0.5b", json.RawMessage(`{}`), 3)
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	if len(outputs) != 3 {
+		t.Fatalf("generate() = %d outputs, want 3", len(outputs))
+	}
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if len(n.tasks) != 3 {
+		t.Errorf("%d tasks, want 3", len(n.tasks))
+	}
+	for id, task := range n.tasks {
+		if task.Status != TaskCompleted {
+			t.Errorf("task %s = %s, want %s", id, task.Status, TaskCompleted)
+		}
+	}
+	if got := n.inflight.Current("zen-mini-0.5b"); got != 0 {
+		t.Errorf("in flight = %d after generate, want 0", got)
+	}
+}
+
+func TestGeneratePartialDispatchFailure(t *testing.T) {
+	n := NewAINode(Config{})
+	miner := &MinerInfo{
+		ID:             "light",
+		ModelingLevels: map[cc.ModelingLevel]int{cc.ModelingLevelInferenceLight: 2},
+	}
+	n.miners["light"] = miner
+
+	// Two completions fit the miner's Light slots; the third does not
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	_, err := n.generate(req, "chat", "zen-mini-0.5b", json.RawMessage(`{}`), 3)
+	if !errors.Is(err, errLevelUnavailable) {
+		t.Fatalf("generate() error = %v, want %v", err, errLevelUnavailable)
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if len(n.tasks) != 2 {
+		t.Fatalf("%d tasks, want the 2 dispatched", len(n.tasks))
+	}
+	for id, task := range n.tasks {
+		if task.Status != TaskCancelled {
+			t.Errorf("task %s = %s, want %s", id, task.Status, TaskCancelled)
+		}
+	}
+	if miner.ActiveTasks != 0 || miner.levelTasks[cc.ModelingLevelInferenceLight] != 0 {
+		t.Errorf("miner has %d active tasks, %d at Light; want its slots freed",
+			miner.ActiveTasks, miner.levelTasks[cc.ModelingLevelInferenceLight])
+	}
+	if got := n.inflight.Current("zen-mini-0.5b"); got != 0 {
+		t.Errorf("in flight = %d after failed generate, want 0", got)
+	}
+}
+
+func TestGenerateFailureCancelsSiblings(t *testing.T) {
+	n := NewAINode(Config{})
+	miner := &MinerInfo{
+		ID:             "light",
+		ModelingLevels: map[cc.ModelingLevel]int{cc.ModelingLevelInferenceLight: 2},
+	}
+	n.miners["light"] = miner
+
+	errc := make(chan error, 1)
+	go func() {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		_, err := n.generate(req, "chat", "zen-mini-0.5b", json.RawMessage(`{}`), 2)
+		errc <- err
+	}()
+
+	// Fail one completion once both are dispatched
+	var tasks []*Task
+	for len(tasks) < 2 {
+		time.Sleep(10 * time.Millisecond)
+		n.mu.RLock()
+		tasks = tasks[:0]
+		for _, task := range n.tasks {
+			tasks = append(tasks, task)
+		}
+		n.mu.RUnlock()
+	}
+	n.cancelTasks(tasks[:1], "test")
+
+	if err := <-errc; !errors.Is(err, errTaskCancelled) {
+		t.Fatalf("generate() error = %v, want %v", err, errTaskCancelled)
+	}
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if tasks[1].Status != TaskCancelled {
+		t.Errorf("sibling task = %s, want %s", tasks[1].Status, TaskCancelled)
+	}
+	if miner.ActiveTasks != 0 {
+		t.Errorf("miner has %d active tasks, want its slots freed", miner.ActiveTasks)
+	}
+}
+
+func TestDispatchAdvertisedModels(t *testing.T) {
+	n := NewAINode(Config{})
+	n.miners["a"] = &MinerInfo{ID: "a", Models: []string{"zen-mini-0.5b"}}
+	n.tokens["a"] = "tok"
+	rng := rand.New(rand.NewSource(1))
+	advertise := func(models string) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/api/miners/models", strings.NewReader(`{"id":"a","models":`+models+`}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer tok")
+		rec := httptest.NewRecorder()
+		n.newMux().ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("advertise %s: status = %d: %s", models, rec.Code, rec.Body)
+		}
+	}
+
+	if task, err := n.dispatch(rng, "", "", "chat", "zen-mini-0.5b", nil); err != nil || task.AssignedTo != "a" {
+		t.Fatalf("dispatch(advertised) = %v, %v, want miner a", task, err)
+	}
+	if _, err := n.dispatch(rng, "", "", "chat", "qwen3-8b", nil); !errors.Is(err, errModelNotServed) || !unqualified(err) {
+		t.Errorf("dispatch(not advertised) error = %v, want %v", err, errModelNotServed)
+	}
+
+	// Once the miner drops the model it gets no more of its tasks, whether
+	// dispatched or claimed from the queue
+	advertise(`["qwen3-8b"]`)
+	if _, err := n.dispatch(rng, "", "", "chat", "zen-mini-0.5b", nil); !errors.Is(err, errModelNotServed) {
+		t.Errorf("dispatch(removed model) error = %v, want %v", err, errModelNotServed)
+	}
+	n.tasks["queued"] = &Task{ID: "queued", Status: TaskPending, Model: "zen-mini-0.5b"}
+	n.mu.Lock()
+	for _, task := range n.claimTasksLocked("a") {
+		if task.ID == "queued" {
+			t.Error("miner claimed a task for a model it no longer advertises")
+		}
+	}
+	n.mu.Unlock()
+
+	// An empty list serves nothing; advertising the model again restores it
+	advertise(`[]`)
+	if _, err := n.dispatch(rng, "", "", "chat", "qwen3-8b", nil); !errors.Is(err, errModelNotServed) {
+		t.Errorf("dispatch() with no models advertised error = %v, want %v", err, errModelNotServed)
+	}
+	advertise(`["zen-mini-0.5b"]`)
+	n.mu.Lock()
+	claimed := n.claimTasksLocked("a")
+	n.mu.Unlock()
+	if len(claimed) != 1 || claimed[0].ID != "queued" {
+		t.Errorf("claimed %v, want the queued task", claimed)
+	}
+
+	// Miners that never sent a list take any model
+	n.miners["any"] = &MinerInfo{ID: "any"}
+	if task, err := n.dispatch(rng, "", "", "chat", "qwen3-8b", nil); err != nil || task.AssignedTo != "any" {
+		t.Errorf("dispatch() = %v, %v, want miner any", task, err)
+	}
+}
+
+func TestPendingTasksClaimRequiresToken(t *testing.T) {
+	n := NewAINode(Config{})
+	n.miners["m1"] = &MinerInfo{ID: "m1"}
+	n.miners["m2"] = &MinerInfo{ID: "m2"}
+	n.tokens["m1"] = "tok-m1"
+	n.tokens["m2"] = "tok-m2"
+	task := &Task{ID: "t1", Model: "zen-mini-0.5b", Status: TaskPending}
+	n.tasks[task.ID] = task
+
+	claim := func(miner, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/tasks/pending?miner="+miner, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		n.newMux().ServeHTTP(rec, req)
+		return rec
+	}
+	for _, tt := range []struct{ name, miner, token string }{
+		{"no token", "m1", ""},
+		{"other miner's token", "m1", "tok-m2"},
+		{"unregistered miner", "m3", "tok-m1"},
+	} {
+		if rec := claim(tt.miner, tt.token); rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", tt.name, rec.Code)
+		}
+	}
+	if task.Status != TaskPending || !n.miners["m1"].LastSeen.IsZero() {
+		t.Fatalf("rejected claims changed state: task %s, last seen %v", task.Status, n.miners["m1"].LastSeen)
+	}
+
+	rec := claim("m1", "tok-m1")
+	var claimed []Task
+	if err := json.NewDecoder(rec.Body).Decode(&claimed); rec.Code != http.StatusOK || err != nil || len(claimed) != 1 {
+		t.Fatalf("claim = %d %s, want the pending task", rec.Code, rec.Body)
+	}
+	if task.Status != TaskRunning || task.AssignedTo != "m1" {
+		t.Errorf("task = %s on %q, want running on m1", task.Status, task.AssignedTo)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Embedding encodings accepted in an embeddings request's encoding_format
+const (
+	EncodingFloat  = "float"
+	EncodingBase64 = "base64"
+)
+
+// encodeEmbedding renders vec for an embeddings response: a JSON array of
+// floats, or with EncodingBase64 the standard base64 of its little-endian
+// float32 bytes, as OpenAI returns it. An empty format means float.
+func encodeEmbedding(vec []float64, format string) (interface{}, error) {
+	switch format {
+	case "", EncodingFloat:
+		return vec, nil
+	case EncodingBase64:
+		buf := make([]byte, 4*len(vec))
+		for i, v := range vec {
+			binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(v)))
+		}
+		return base64.StdEncoding.EncodeToString(buf), nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding_format %q; want %s or %s", format, EncodingFloat, EncodingBase64)
+	}
+}
+
+// EmbeddingError reports an input of an embeddings request that could not
+// be embedded. Index is the input's position in the request.
+type EmbeddingError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// parseEmbeddingInput splits an embeddings request's input, a string or an
+// array of them, into one raw item per input. Items are checked separately
+// by embeddingText so that one bad item does not fail the others.
+func parseEmbeddingInput(raw json.RawMessage) ([]json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] != '[' {
+		return []json.RawMessage{raw}, nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, errors.New("input is required")
+	}
+	return items, nil
+}
+
+// embeddingText returns the text of one embeddings input, rejecting
+// anything but a non-empty string that fits the byte limit and the
+// model's context window. Models without a context size are not enforced.
+func (n *AINode) embeddingText(item json.RawMessage, model *ModelInfo) (string, error) {
+	var text string
+	if len(item) == 0 || string(item) == "null" {
+		return "", errors.New("input is required")
+	}
+	if err := json.Unmarshal(item, &text); err != nil {
+		return "", errors.New("input must be a string")
+	}
+	if text == "" {
+		return "", errors.New("input is required")
+	}
+	if len(text) > n.maxPromptBytes() {
+		return "", fmt.Errorf("input exceeds the limit of %d bytes", n.maxPromptBytes())
+	}
+	if model != nil && model.ContextSize > 0 {
+		if tokens := estimateTokens(text); tokens > model.ContextSize {
+			return "", fmt.Errorf("input of %d tokens exceeds %s context window of %d tokens",
+				tokens, model.ID, model.ContextSize)
+		}
+	}
+	return text, nil
+}
@@ -0,0 +1,192 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// decodeBase64Embedding decodes an embedding the way OpenAI SDKs do:
+// base64 of little-endian float32s
+func decodeBase64Embedding(t *testing.T, s string) []float32 {
+	t.Helper()
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(buf)%4 != 0 {
+		t.Fatalf("invalid base64 embedding %q: %v", s, err)
+	}
+	vec := make([]float32, len(buf)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vec
+}
+
+func TestEncodeEmbedding(t *testing.T) {
+	vec := []float64{0, 1, -0.5, 0.125, 3.25e-3, -1e6}
+
+	for _, format := range []string{"", EncodingFloat} {
+		got, err := encodeEmbedding(vec, format)
+		if err != nil {
+			t.Fatalf("encodeEmbedding(%q) = %v", format, err)
+		}
+		data, _ := json.Marshal(got)
+		var decoded []float64
+		if err := json.Unmarshal(data, &decoded); err != nil || len(decoded) != len(vec) {
+			t.Fatalf("encodeEmbedding(%q) = %s, want a float array", format, data)
+		}
+		for i := range vec {
+			if decoded[i] != vec[i] {
+				t.Errorf("encodeEmbedding(%q)[%d] = %v, want %v", format, i, decoded[i], vec[i])
+			}
+		}
+	}
+
+	got, err := encodeEmbedding(vec, EncodingBase64)
+	if err != nil {
+		t.Fatalf("encodeEmbedding(base64) = %v", err)
+	}
+	s, ok := got.(string)
+	if !ok {
+		t.Fatalf("encodeEmbedding(base64) = %T, want a string", got)
+	}
+	decoded := decodeBase64Embedding(t, s)
+	if len(decoded) != len(vec) {
+		t.Fatalf("decoded %d floats, want %d", len(decoded), len(vec))
+	}
+	for i := range vec {
+		if decoded[i] != float32(vec[i]) {
+			t.Errorf("base64 embedding[%d] = %v, want %v", i, decoded[i], float32(vec[i]))
+		}
+	}
+
+	if _, err := encodeEmbedding(vec, "int8"); err == nil {
+		t.Error("encodeEmbedding(int8) should fail")
+	}
+}
+
+func TestEmbeddingsEncodingFormat(t *testing.T) {
+	n := NewAINode(Config{})
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/embeddings", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		n.handleEmbeddings(rec, req)
+		return rec
+	}
+
+	var resp struct {
+		Data []struct {
+			Embedding json.RawMessage `json:"embedding"`
+		} `json:"data"`
+	}
+	rec := post(`{"input":"hi","model":"m","encoding_format":"base64"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("base64 status = %d, want 200", rec.Code)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Data) != 1 {
+		t.Fatalf("invalid response %s: %v", rec.Body, err)
+	}
+	var s string
+	if err := json.Unmarshal(resp.Data[0].Embedding, &s); err != nil {
+		t.Fatalf("base64 embedding = %s, want a string", resp.Data[0].Embedding)
+	}
+	if got := decodeBase64Embedding(t, s); len(got) != placeholderEmbeddingDims {
+		t.Errorf("base64 embedding has %d dims, want %d", len(got), placeholderEmbeddingDims)
+	}
+
+	rec = post(`{"input":"hi","model":"m"}`)
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var floats []float64
+	if err := json.Unmarshal(resp.Data[0].Embedding, &floats); err != nil || len(floats) != placeholderEmbeddingDims {
+		t.Errorf("default embedding = %.40s..., want %d floats", resp.Data[0].Embedding, placeholderEmbeddingDims)
+	}
+
+	if rec := post(`{"input":"hi","model":"m","encoding_format":"int8"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("unsupported encoding_format status = %d, want 400", rec.Code)
+	}
+}
+
+func TestEmbeddingsPartialFailure(t *testing.T) {
+	n := NewAINode(Config{MaxPromptBytes: 64})
+	n.models["zen-mini-0.5b"].ContextSize = 8 // About 32 bytes
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/embeddings", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		n.handleEmbeddings(rec, req)
+		return rec
+	}
+	type response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+		Errors []EmbeddingError `json:"errors"`
+		Usage  struct {
+			PromptTokens int `json:"prompt_tokens"`
+		} `json:"usage"`
+	}
+
+	long := strings.Repeat("x", 40)
+	rec := post(`{"model":"zen-mini-0.5b","input":["hello","",42,"` + long + `","world",null,"` + strings.Repeat("y", 65) + `"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("mixed batch status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 2 || resp.Data[0].Index != 0 || resp.Data[1].Index != 4 {
+		t.Errorf("data = %+v, want embeddings for indices 0 and 4", resp.Data)
+	}
+	for _, d := range resp.Data {
+		if len(d.Embedding) != placeholderEmbeddingDims {
+			t.Errorf("data[%d] has %d dims, want %d", d.Index, len(d.Embedding), placeholderEmbeddingDims)
+		}
+	}
+	wantErrors := map[int]string{
+		1: "input is required",
+		2: "must be a string",
+		3: "context window",
+		5: "input is required",
+		6: "limit of 64 bytes",
+	}
+	if len(resp.Errors) != len(wantErrors) {
+		t.Errorf("errors = %+v, want %d", resp.Errors, len(wantErrors))
+	}
+	for _, e := range resp.Errors {
+		if want, ok := wantErrors[e.Index]; !ok || !strings.Contains(e.Error, want) {
+			t.Errorf("errors[%d] = %q, want mention of %q", e.Index, e.Error, want)
+		}
+	}
+	if resp.Usage.PromptTokens != 2*placeholderEmbeddingTokens {
+		t.Errorf("usage = %d prompt tokens, want %d", resp.Usage.PromptTokens, 2*placeholderEmbeddingTokens)
+	}
+
+	// A single string input behaves as a batch of one
+	rec = post(`{"model":"zen-mini-0.5b","input":"hello"}`)
+	resp = response{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK || len(resp.Data) != 1 || resp.Errors != nil {
+		t.Errorf("single input = %d %s, want one embedding", rec.Code, rec.Body)
+	}
+
+	// Only when every input fails is the request rejected
+	rec = post(`{"model":"zen-mini-0.5b","input":["","` + long + `"]}`)
+	resp = response{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusBadRequest || len(resp.Data) != 0 || len(resp.Errors) != 2 {
+		t.Errorf("all-failed batch = %d %s, want 400 with both errors", rec.Code, rec.Body)
+	}
+	for _, body := range []string{`{"model":"zen-mini-0.5b","input":[]}`, `{"model":"zen-mini-0.5b","input":[1,}`} {
+		if rec := post(body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, want 400", body, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runLimitedMiner completes chat tasks with reply, cut to the request's
+// max_tokens at four bytes per token the way an engine would, reporting
+// finish_reason length when cut. Stop sequences are left to the node.
+func runLimitedMiner(ctx context.Context, n *AINode, reply string) {
+	n.mu.Lock()
+	n.miners["limited"] = &MinerInfo{ID: "limited"}
+	n.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			n.mu.Lock()
+			for _, t := range n.claimTasksLocked("limited") {
+				var in struct {
+					MaxTokens int `json:"max_tokens"`
+				}
+				json.Unmarshal(t.Input, &in)
+				content, reason := reply, "stop"
+				if in.MaxTokens > 0 && len(content) > 4*in.MaxTokens {
+					content, reason = content[:4*in.MaxTokens], "length"
+				}
+				t.Output, _ = json.Marshal(map[string]string{"content": content, "finish_reason": reason})
+				t.Status = TaskCompleted
+				n.finishTaskLocked(t)
+			}
+			n.mu.Unlock()
+		}
+	}()
+}
+
+func TestChatFinishReason(t *testing.T) {
+	const reply = "one two three END four five"
+
+	tests := []struct {
+		name        string
+		maxTokens   int
+		stop        string
+		wantContent string
+		wantReason  string
+	}{
+		{"natural stop", 0, "", reply, "stop"},
+		{"max_tokens", 2, "", "one two ", "length"},
+		{"stop sequence", 0, "END", "one two three ", "stop"},
+		{"stop sequence before limit", 5, "END", "one two three ", "stop"},
+		{"limit before stop sequence", 2, "END", "one two ", "length"},
+	}
+	for _, tt := range tests {
+		for _, stream := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s stream=%v", tt.name, stream), func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				n := NewAINode(Config{})
+				runLimitedMiner(ctx, n, reply)
+
+				body, _ := json.Marshal(map[string]any{
+					"model":      "zen-mini-0.5b",
+					"messages":   []map[string]string{{"role": "user", "content": "count"}},
+					"max_tokens": tt.maxTokens,
+					"stop":       tt.stop,
+					"stream":     stream,
+				})
+				rec := chatRequest(t, n, string(body))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+				}
+
+				var content, reason string
+				if stream {
+					var b strings.Builder
+					for _, c := range sseChunks(t, rec.Body.String()) {
+						b.WriteString(c.Choices[0].Delta.Content)
+						if fr := c.Choices[0].FinishReason; fr != nil {
+							reason = *fr
+						}
+					}
+					content = b.String()
+				} else {
+					var resp ChatResponse
+					if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+						t.Fatalf("decode response: %v", err)
+					}
+					content, reason = resp.Choices[0].Message.Content, resp.Choices[0].FinishReason
+				}
+				if content != tt.wantContent || reason != tt.wantReason {
+					t.Errorf("completion = (%q, %q), want (%q, %q)", content, reason, tt.wantContent, tt.wantReason)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/luxfi/ai/internal/minersig"
+)
+
+const (
+	// heartbeatSkew is how far a heartbeat timestamp may be from the node's
+	// clock
+	heartbeatSkew = 2 * time.Minute
+
+	// minHeartbeatNonce is the shortest nonce accepted, in bytes
+	minHeartbeatNonce = 16
+)
+
+var (
+	errKeylessMiner      = errors.New("miner registered without a public key cannot send heartbeats")
+	errUnsignedHeartbeat = errors.New("heartbeat signature required")
+	errInvalidHeartbeat  = errors.New("invalid heartbeat signature")
+	errStaleHeartbeat    = errors.New("heartbeat timestamp outside allowed clock skew")
+	errReplayedHeartbeat = errors.New("heartbeat not newer than the last accepted")
+	errShortNonce        = errors.New("heartbeat nonce must be at least 16 bytes")
+)
+
+// Heartbeat is a miner's signed liveness report
+type Heartbeat struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Nonce     []byte    `json:"nonce"`
+	Signature []byte    `json:"signature"`
+}
+
+// verifyHeartbeat checks hb against miner's registered key. A heartbeat
+// must be signed, fall within heartbeatSkew of now and be newer than the
+// last one accepted, so a captured heartbeat cannot be replayed.
+func verifyHeartbeat(miner *MinerInfo, hb *Heartbeat, now time.Time) error {
+	if len(miner.PublicKey) == 0 {
+		return errKeylessMiner
+	}
+	if len(hb.Signature) == 0 || hb.Timestamp.IsZero() {
+		return errUnsignedHeartbeat
+	}
+	if len(hb.Nonce) < minHeartbeatNonce {
+		return errShortNonce
+	}
+	digest := minersig.HeartbeatDigest(miner.ID, hb.Timestamp, hb.Nonce)
+	if !ed25519.Verify(miner.PublicKey, digest[:], hb.Signature) {
+		return errInvalidHeartbeat
+	}
+	if skew := now.Sub(hb.Timestamp); skew > heartbeatSkew || skew < -heartbeatSkew {
+		return errStaleHeartbeat
+	}
+	if !hb.Timestamp.After(miner.heartbeatAt) {
+		return errReplayedHeartbeat
+	}
+	return nil
+}
+
+// handleMinerHeartbeat marks a miner live after verifying its signed
+// heartbeat, replying with the IDs of its running tasks cancelled since the
+// last one. Unsigned, forged, stale and replayed heartbeats are rejected
+// without touching the miner's state.
+func (n *AINode) handleMinerHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var hb Heartbeat
+	if err := json.NewDecoder(r.Body).Decode(&hb); err != nil {
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	now := n.clock.Now()
+	n.mu.Lock()
+	miner, ok := n.miners[hb.ID]
+	if !ok {
+		n.mu.Unlock()
+		http.Error(w, "miner not registered", http.StatusNotFound)
+		return
+	}
+	if err := verifyHeartbeat(miner, &hb, now); err != nil {
+		n.mu.Unlock()
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	miner.heartbeatAt = hb.Timestamp
+	miner.LastSeen = now
+	cancelled := miner.cancelled
+	miner.cancelled = nil
+	n.mu.Unlock()
+
+	reply := map[string]interface{}{
+		"status":    "ok",
+		"last_seen": now,
+	}
+	if len(cancelled) > 0 {
+		reply["cancelled"] = cancelled
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reply)
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/internal/minersig"
+)
+
+func TestMinerHeartbeat(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	_, otherPriv, _ := ed25519.GenerateKey(nil)
+	nonce := bytes.Repeat([]byte{7}, minHeartbeatNonce)
+	now := time.Now()
+	sign := func(key ed25519.PrivateKey, id string, ts time.Time) []byte {
+		digest := minersig.HeartbeatDigest(id, ts, nonce)
+		return ed25519.Sign(key, digest[:])
+	}
+	lastSeen := now.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		key    ed25519.PublicKey
+		prior  time.Time // Last accepted heartbeat
+		hb     Heartbeat
+		status int
+	}{
+		{"valid", pub, time.Time{}, Heartbeat{ID: "m", Timestamp: now, Nonce: nonce, Signature: sign(priv, "m", now)}, http.StatusOK},
+		{"unknown miner", pub, time.Time{}, Heartbeat{ID: "x", Timestamp: now, Nonce: nonce, Signature: sign(priv, "x", now)}, http.StatusNotFound},
+		{"keyless miner", nil, time.Time{}, Heartbeat{ID: "m", Timestamp: now, Nonce: nonce}, http.StatusUnauthorized},
+		{"unsigned", pub, time.Time{}, Heartbeat{ID: "m", Timestamp: now, Nonce: nonce}, http.StatusUnauthorized},
+		{"short nonce", pub, time.Time{}, Heartbeat{ID: "m", Timestamp: now, Nonce: nonce[:8], Signature: sign(priv, "m", now)}, http.StatusUnauthorized},
+		{"wrong key", pub, time.Time{}, Heartbeat{ID: "m", Timestamp: now, Nonce: nonce, Signature: sign(otherPriv, "m", now)}, http.StatusUnauthorized},
+		{"signed for other miner", pub, time.Time{}, Heartbeat{ID: "m", Timestamp: now, Nonce: nonce, Signature: sign(priv, "other", now)}, http.StatusUnauthorized},
+		{"stale", pub, time.Time{}, Heartbeat{ID: "m", Timestamp: now.Add(-time.Hour), Nonce: nonce, Signature: sign(priv, "m", now.Add(-time.Hour))}, http.StatusUnauthorized},
+		{"replayed", pub, now, Heartbeat{ID: "m", Timestamp: now, Nonce: nonce, Signature: sign(priv, "m", now)}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{})
+			n.miners["m"] = &MinerInfo{ID: "m", PublicKey: tt.key, LastSeen: lastSeen, heartbeatAt: tt.prior}
+
+			body, _ := json.Marshal(&tt.hb)
+			rec := httptest.NewRecorder()
+			n.handleMinerHeartbeat(rec, httptest.NewRequest("POST", "/api/miners/heartbeat", bytes.NewReader(body)))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+
+			updated := !n.miners["m"].LastSeen.Equal(lastSeen)
+			if updated != (tt.status == http.StatusOK) {
+				t.Errorf("LastSeen updated = %v, want %v", updated, tt.status == http.StatusOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// InflightStats is a model's in-flight task count
+type InflightStats struct {
+	Current int64 `json:"current"` // Tasks dispatched and not yet finished
+	Peak    int64 `json:"peak"`    // Highest Current since the node started
+}
+
+// ModelCounters tracks in-flight tasks per model. The zero value is ready
+// to use and all methods are safe for concurrent use.
+type ModelCounters struct {
+	mu     sync.Mutex
+	models map[string]*modelCounter
+}
+
+type modelCounter struct {
+	current atomic.Int64
+	peak    atomic.Int64
+}
+
+// Acquire counts a task for model as in flight and returns the func that
+// releases it. Release is idempotent, so callers can both defer it and
+// call it early:
+//
+//	release := c.Acquire(model)
+//	defer release()
+func (c *ModelCounters) Acquire(model string) (release func()) {
+	mc := c.counter(model)
+	cur := mc.current.Add(1)
+	for {
+		peak := mc.peak.Load()
+		if cur <= peak || mc.peak.CompareAndSwap(peak, cur) {
+			break
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { mc.current.Add(-1) })
+	}
+}
+
+// Current returns the number of in-flight tasks for model
+func (c *ModelCounters) Current(model string) int64 {
+	return c.Stats(model).Current
+}
+
+// Stats returns the in-flight counts for model
+func (c *ModelCounters) Stats(model string) InflightStats {
+	c.mu.Lock()
+	mc, ok := c.models[model]
+	c.mu.Unlock()
+	if !ok {
+		return InflightStats{}
+	}
+	return InflightStats{Current: mc.current.Load(), Peak: mc.peak.Load()}
+}
+
+// Snapshot returns the in-flight counts of every model that has had a
+// task dispatched
+func (c *ModelCounters) Snapshot() map[string]InflightStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := make(map[string]InflightStats, len(c.models))
+	for model, mc := range c.models {
+		snapshot[model] = InflightStats{Current: mc.current.Load(), Peak: mc.peak.Load()}
+	}
+	return snapshot
+}
+
+// counter returns model's counter, creating it on first use
+func (c *ModelCounters) counter(model string) *modelCounter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.models == nil {
+		c.models = make(map[string]*modelCounter)
+	}
+	mc, ok := c.models[model]
+	if !ok {
+		mc = &modelCounter{}
+		c.models[model] = mc
+	}
+	return mc
+}
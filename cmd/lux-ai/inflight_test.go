@@ -0,0 +1,110 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestModelCountersConcurrent(t *testing.T) {
+	var c ModelCounters
+	const workers = 50
+
+	start := make(chan struct{})
+	hold := make(chan struct{})
+	var acquired, done sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		acquired.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			<-start
+			release := c.Acquire("m")
+			defer release()
+			acquired.Done()
+			<-hold
+		}()
+	}
+	close(start)
+	acquired.Wait()
+
+	if got := c.Stats("m"); got.Current != workers || got.Peak != workers {
+		t.Errorf("Stats() while held = %+v, want current and peak %d", got, workers)
+	}
+	close(hold)
+	done.Wait()
+
+	if got := c.Stats("m"); got.Current != 0 || got.Peak != workers {
+		t.Errorf("Stats() after release = %+v, want current 0, peak %d", got, workers)
+	}
+}
+
+func TestModelCountersRelease(t *testing.T) {
+	var c ModelCounters
+
+	release := c.Acquire("a")
+	c.Acquire("b")
+	release()
+	release()
+	if got := c.Current("a"); got != 0 {
+		t.Errorf("Current(a) after double release = %d, want 0", got)
+	}
+	if got := c.Current("b"); got != 1 {
+		t.Errorf("Current(b) = %d, want 1", got)
+	}
+	if got := c.Current("unknown"); got != 0 {
+		t.Errorf("Current(unknown) = %d, want 0", got)
+	}
+
+	// A panicking handler still releases through its defer
+	func() {
+		defer func() { recover() }()
+		defer c.Acquire("a")()
+		panic("handler panic")
+	}()
+	if got := c.Stats("a"); got.Current != 0 || got.Peak != 1 {
+		t.Errorf("Stats(a) after panic = %+v, want current 0, peak 1", got)
+	}
+
+	snapshot := c.Snapshot()
+	if len(snapshot) != 2 || snapshot["b"].Current != 1 {
+		t.Errorf("Snapshot() = %+v, want a and b with b in flight", snapshot)
+	}
+}
+
+func TestGenerateTracksInflight(t *testing.T) {
+	n := NewAINode(Config{})
+	n.mu.Lock()
+	n.miners["idle"] = &MinerInfo{ID: "idle"} // never completes its tasks
+	n.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil).WithContext(ctx)
+	errc := make(chan error, 1)
+	go func() {
+		_, err := n.generate(req, "chat", "m", json.RawMessage(`{}`), 2)
+		errc <- err
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for n.inflight.Current("m") != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Current() = %d, want 2 while waiting", n.inflight.Current("m"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-errc; err == nil {
+		t.Fatal("generate() error = nil after cancel")
+	}
+	if got := n.inflight.Stats("m"); got.Current != 0 || got.Peak != 2 {
+		t.Errorf("Stats() after cancel = %+v, want current 0, peak 2", got)
+	}
+}
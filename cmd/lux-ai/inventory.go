@@ -0,0 +1,215 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Inventory output formats accepted by -inventory-format
+const (
+	InventoryCSV  = "csv"
+	InventoryJSON = "json"
+)
+
+const (
+	// inventoryConcurrency bounds how many nodes are queried at once
+	inventoryConcurrency = 8
+
+	// inventoryTimeout bounds each node's capability request, which runs
+	// hardware detection on the node
+	inventoryTimeout = 30 * time.Second
+)
+
+// InventoryEntry is one node's row in a fleet inventory. Nodes that could
+// not be queried have only Node and Error set.
+type InventoryEntry struct {
+	Node           string `json:"node"`
+	GPUVendor      string `json:"gpu_vendor"`
+	GPUModel       string `json:"gpu_model"`
+	ComputeCap     string `json:"compute_capability"`
+	Tier           string `json:"tier"`
+	TrustScore     uint8  `json:"trust_score"`
+	GPUCCSupported bool   `json:"gpu_cc_supported"`
+	GPUCCEnabled   bool   `json:"gpu_cc_enabled"`
+	CPUTEEType     string `json:"cpu_tee_type"`
+	CPUTEEActive   bool   `json:"cpu_tee_active"`
+	RequiresSetup  bool   `json:"requires_setup"`
+	SetupHint      string `json:"setup_hint,omitempty"`
+	Error          string `json:"error,omitempty"`
+
+	Report *CapabilityResponse `json:"report,omitempty"` // The node's full /api/capability response
+}
+
+// inventoryColumns are the CSV header, in InventoryEntry field order
+var inventoryColumns = []string{
+	"node", "gpu_vendor", "gpu_model", "compute_capability", "tier", "trust_score",
+	"gpu_cc_supported", "gpu_cc_enabled", "cpu_tee_type", "cpu_tee_active",
+	"requires_setup", "setup_hint", "error",
+}
+
+// readNodeList reads node URLs, one per line, from path ("-" for stdin).
+// Blank lines and lines starting with # are skipped.
+func readNodeList(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var nodes []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		nodes = append(nodes, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%s: no node URLs", path)
+	}
+	return nodes, nil
+}
+
+// collectInventory queries /api/capability on every node, a few at a time,
+// and returns one entry per node in the order given. A node that fails is
+// reported in its entry's Error rather than aborting the inventory.
+func collectInventory(ctx context.Context, client *http.Client, nodes []string) []InventoryEntry {
+	entries := make([]InventoryEntry, len(nodes))
+	sem := make(chan struct{}, inventoryConcurrency)
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			entries[i] = InventoryEntry{Node: node}
+			report, err := fetchCapability(ctx, client, node)
+			if err != nil {
+				entries[i].Error = err.Error()
+				return
+			}
+			entries[i].fill(report)
+		}()
+	}
+	wg.Wait()
+	return entries
+}
+
+// fetchCapability requests a node's capability report. Node URLs without
+// a scheme are taken to be http.
+func fetchCapability(ctx context.Context, client *http.Client, node string) (*CapabilityResponse, error) {
+	base := strings.TrimRight(node, "/")
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+	ctx, cancel := context.WithTimeout(ctx, inventoryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", base+"/api/capability", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var report CapabilityResponse
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("invalid capability report: %w", err)
+	}
+	if report.Capability == nil {
+		return nil, fmt.Errorf("invalid capability report: no capability")
+	}
+	return &report, nil
+}
+
+// fill copies the inventory columns out of a capability report
+func (e *InventoryEntry) fill(report *CapabilityResponse) {
+	c := report.Capability
+	e.GPUVendor = string(c.GPUVendor)
+	e.GPUModel = c.GPUModel
+	e.ComputeCap = string(c.ComputeCap)
+	e.Tier = report.Tier
+	e.TrustScore = report.TrustScore
+	e.GPUCCSupported = c.GPUCCSupported
+	e.GPUCCEnabled = c.GPUCCEnabled
+	e.CPUTEEType = string(c.CPUTEEType)
+	e.CPUTEEActive = c.CPUTEEActive
+	e.RequiresSetup = report.RequiresSetup
+	e.SetupHint = report.SetupHint
+	e.Report = report
+}
+
+// writeInventory writes entries to out as CSV, or as a JSON array
+// including each node's full report
+func writeInventory(out io.Writer, format string, entries []InventoryEntry) error {
+	switch format {
+	case InventoryJSON:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case InventoryCSV:
+		w := csv.NewWriter(out)
+		w.Write(inventoryColumns)
+		for _, e := range entries {
+			w.Write([]string{
+				e.Node, e.GPUVendor, e.GPUModel, e.ComputeCap, e.Tier, strconv.Itoa(int(e.TrustScore)),
+				strconv.FormatBool(e.GPUCCSupported), strconv.FormatBool(e.GPUCCEnabled),
+				e.CPUTEEType, strconv.FormatBool(e.CPUTEEActive),
+				strconv.FormatBool(e.RequiresSetup), e.SetupHint, e.Error,
+			})
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		return fmt.Errorf("unknown inventory format %q; want %s or %s", format, InventoryCSV, InventoryJSON)
+	}
+}
+
+// runInventory collects the inventory of the nodes listed in path and
+// writes it to out. It returns how many nodes could not be queried.
+func runInventory(ctx context.Context, path, format string, out io.Writer) (failed int, err error) {
+	if format != InventoryCSV && format != InventoryJSON {
+		return 0, fmt.Errorf("unknown inventory format %q; want %s or %s", format, InventoryCSV, InventoryJSON)
+	}
+	nodes, err := readNodeList(path)
+	if err != nil {
+		return 0, err
+	}
+	entries := collectInventory(ctx, &http.Client{}, nodes)
+	for _, e := range entries {
+		if e.Error != "" {
+			failed++
+		}
+	}
+	return failed, writeInventory(out, format, entries)
+}
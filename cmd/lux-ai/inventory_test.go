@@ -0,0 +1,98 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+func TestFleetInventory(t *testing.T) {
+	h100 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/capability" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(CapabilityResponse{
+			Capability: &cc.HardwareCapability{
+				GPUVendor:      cc.VendorNVIDIA,
+				GPUModel:       "NVIDIA H100 80GB HBM3",
+				ComputeCap:     cc.ComputeCapHopper,
+				GPUCCSupported: true,
+				CPUTEEType:     cc.TEESEVSNP,
+			},
+			Tier:          cc.Tier2ConfidentialVM.String(),
+			TrustScore:    72,
+			RequiresSetup: true,
+			SetupHint:     "enable GPU CC mode",
+		})
+	}))
+	defer h100.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "detection failed", http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	list := filepath.Join(t.TempDir(), "nodes.txt")
+	content := "# fleet\n" + h100.URL + "/\n\n" + broken.URL + "\n" + strings.TrimPrefix(h100.URL, "http://") + "\n"
+	if err := os.WriteFile(list, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := readNodeList(list)
+	if err != nil || len(nodes) != 3 {
+		t.Fatalf("readNodeList() = %v, %v, want 3 nodes", nodes, err)
+	}
+
+	entries := collectInventory(context.Background(), http.DefaultClient, nodes)
+	for _, i := range []int{0, 2} {
+		e := entries[i]
+		if e.Error != "" || e.GPUModel != "NVIDIA H100 80GB HBM3" || e.ComputeCap != "9.0" || !e.GPUCCSupported || !e.RequiresSetup || e.TrustScore != 72 {
+			t.Errorf("entry %d = %+v, want the H100 report", i, e)
+		}
+	}
+	if e := entries[1]; e.Node != broken.URL || !strings.Contains(e.Error, "500") || e.GPUModel != "" {
+		t.Errorf("entry 1 = %+v, want a 500 error for %s", e, broken.URL)
+	}
+
+	var out bytes.Buffer
+	if err := writeInventory(&out, InventoryCSV, entries); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || strings.Join(rows[0], ",") != strings.Join(inventoryColumns, ",") {
+		t.Fatalf("CSV = %q, want a header and 3 rows", rows)
+	}
+	if row := rows[1]; row[4] != cc.Tier2ConfidentialVM.String() || row[6] != "true" || row[10] != "true" || row[12] != "" {
+		t.Errorf("CSV row = %q", row)
+	}
+
+	out.Reset()
+	if err := writeInventory(&out, InventoryJSON, entries); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []InventoryEntry
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 3 || decoded[0].Report == nil || decoded[0].Report.Capability.CPUTEEType != cc.TEESEVSNP {
+		t.Errorf("JSON inventory = %+v, want full reports", decoded)
+	}
+
+	if err := writeInventory(&out, "xml", entries); err == nil {
+		t.Error("writeInventory(xml) should fail")
+	}
+}
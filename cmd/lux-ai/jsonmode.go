@@ -0,0 +1,75 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Response format types accepted in ChatRequest.ResponseFormat
+const (
+	ResponseFormatText       = "text"
+	ResponseFormatJSONObject = "json_object"
+)
+
+var (
+	errResponseFormat    = errors.New("unsupported response_format type")
+	errInvalidJSONOutput = errors.New("model output is not a valid JSON object")
+)
+
+// ResponseFormat mirrors the OpenAI response_format parameter
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// validate rejects format types the node cannot honour. A nil format is
+// plain text.
+func (f *ResponseFormat) validate() error {
+	if f == nil {
+		return nil
+	}
+	switch f.Type {
+	case "", ResponseFormatText, ResponseFormatJSONObject:
+		return nil
+	default:
+		return fmt.Errorf("%w %q", errResponseFormat, f.Type)
+	}
+}
+
+// jsonObject reports whether the caller asked for JSON mode
+func (f *ResponseFormat) jsonObject() bool {
+	return f != nil && f.Type == ResponseFormatJSONObject
+}
+
+// isJSONObject reports whether content parses as a single JSON object
+func isJSONObject(content string) bool {
+	var obj map[string]json.RawMessage
+	return json.Unmarshal([]byte(strings.TrimSpace(content)), &obj) == nil && obj != nil
+}
+
+// ensureJSONContents enforces JSON mode on completed choices. The miner
+// passes response_format to its backend, but not every engine enforces it,
+// so each choice is checked here and one that isn't a JSON object is
+// regenerated once before giving up with errInvalidJSONOutput.
+func (n *AINode) ensureJSONContents(r *http.Request, model string, input json.RawMessage, stop StopSequences, completions []chatCompletion) error {
+	for i, c := range completions {
+		if isJSONObject(c.Content) {
+			continue
+		}
+		outputs, err := n.generate(r, "chat", model, input, 1)
+		if err != nil {
+			return err
+		}
+		retried, err := parseChatOutput(outputs[0], stop)
+		if err != nil || !isJSONObject(retried.Content) {
+			return errInvalidJSONOutput
+		}
+		completions[i] = retried
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runFakeMiner registers a miner and completes each claimed task with the
+// next reply, repeating the last one, until ctx is done
+func runFakeMiner(ctx context.Context, n *AINode, replies ...string) {
+	n.mu.Lock()
+	n.miners["fake"] = &MinerInfo{ID: "fake"}
+	n.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			n.mu.Lock()
+			for _, t := range n.claimTasksLocked("fake") {
+				reply := replies[0]
+				if len(replies) > 1 {
+					replies = replies[1:]
+				}
+				t.Output, _ = json.Marshal(map[string]string{"content": reply})
+				t.Status = TaskCompleted
+				n.finishTaskLocked(t)
+			}
+			n.mu.Unlock()
+		}
+	}()
+}
+
+func chatRequest(t *testing.T, n *AINode, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	n.handleChatCompletions(rec, req)
+	return rec
+}
+
+func TestChatJSONMode(t *testing.T) {
+	const jsonBody = `{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}],"response_format":{"type":"json_object"}}`
+
+	tests := []struct {
+		name        string
+		body        string
+		replies     []string
+		wantStatus  int
+		wantContent string
+	}{
+		{"valid JSON", jsonBody, []string{`{"ok":true}`}, http.StatusOK, `{"ok":true}`},
+		{"retried once", jsonBody, []string{"not json", `{"ok":1}`}, http.StatusOK, `{"ok":1}`},
+		{"invalid after retry", jsonBody, []string{"not json", "[1,2]"}, http.StatusBadGateway, ""},
+		{"text mode not validated", `{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}]}`, []string{"plain"}, http.StatusOK, "plain"},
+		{"unknown type", `{"messages":[],"response_format":{"type":"xml"}}`, []string{"{}"}, http.StatusBadRequest, ""},
+		{"stream", `{"messages":[],"stream":true,"response_format":{"type":"json_object"}}`, []string{"{}"}, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			n := NewAINode(Config{})
+			runFakeMiner(ctx, n, tt.replies...)
+
+			rec := chatRequest(t, n, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp ChatResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if got := resp.Choices[0].Message.Content; got != tt.wantContent {
+				t.Errorf("content = %q, want %q", got, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestIsJSONObject(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{`{"a":1}`, true},
+		{"  {}\n", true},
+		{"[1]", false},
+		{"null", false},
+		{`"str"`, false},
+		{`{"a":1} trailing`, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isJSONObject(tt.content); got != tt.want {
+			t.Errorf("isJSONObject(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
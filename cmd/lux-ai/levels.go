@@ -0,0 +1,69 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+var errLevelUnavailable = errors.New("no miner with capacity at modeling level")
+
+// modelingLevelLocked returns the modeling level of model, 0 if the model
+// is unknown or has none. Caller holds n.mu.
+func (n *AINode) modelingLevelLocked(model string) cc.ModelingLevel {
+	if m, ok := n.models[model]; ok {
+		return m.ModelingLevel
+	}
+	return 0
+}
+
+// hasLevelCapacity reports whether miner takes a task at level. Miners that
+// opted into no levels take any level; others only the levels they listed,
+// while fewer than that level's concurrency limit are active (0 = no limit).
+func hasLevelCapacity(miner *MinerInfo, level cc.ModelingLevel) bool {
+	if level == 0 || len(miner.ModelingLevels) == 0 {
+		return true
+	}
+	limit, ok := miner.ModelingLevels[level]
+	if !ok {
+		return false
+	}
+	return limit == 0 || miner.levelTasks[level] < limit
+}
+
+// trackLevel adjusts miner's active task count at level by delta
+func trackLevel(miner *MinerInfo, level cc.ModelingLevel, delta int) {
+	if level == 0 {
+		return
+	}
+	if miner.levelTasks == nil {
+		miner.levelTasks = make(map[cc.ModelingLevel]int)
+	}
+	miner.levelTasks[level] = max(0, miner.levelTasks[level]+delta)
+}
+
+// validateModelingLevels checks that every level a miner opts into is
+// defined, has a non-negative concurrency limit and fits in the miner's
+// reported GPU memory
+func validateModelingLevels(miner *MinerInfo) error {
+	var vramGB uint64
+	if miner.GPUMemoryMB > 0 {
+		vramGB = (&cc.HardwareCapability{GPUMemoryMB: miner.GPUMemoryMB}).MemoryGB()
+	}
+	for _, level := range slices.Sorted(maps.Keys(miner.ModelingLevels)) {
+		limit := miner.ModelingLevels[level]
+		if err := cc.CheckLevelVRAM(level, vramGB); err != nil {
+			return err
+		}
+		if limit < 0 {
+			return fmt.Errorf("%w: negative concurrency %d for %s", cc.ErrInvalidModelingLevel, limit, level)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+func TestDispatchModelingLevels(t *testing.T) {
+	n := NewAINode(Config{})
+	n.miners["light"] = &MinerInfo{
+		ID:             "light",
+		GPUMemoryMB:    81559,
+		ModelingLevels: map[cc.ModelingLevel]int{cc.ModelingLevelInferenceLight: 1},
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	// Opted into Light only, so a Standard model finds no miner
+	_, err := n.dispatch(rng, "", "", "chat", "qwen3-8b", nil)
+	if !errors.Is(err, errLevelUnavailable) || !unqualified(err) {
+		t.Fatalf("dispatch(standard) error = %v, want %v", err, errLevelUnavailable)
+	}
+
+	first, err := n.dispatch(rng, "", "", "chat", "zen-mini-0.5b", nil)
+	if err != nil {
+		t.Fatalf("dispatch(light) error = %v", err)
+	}
+	if first.AssignedTo != "light" || first.Level != cc.ModelingLevelInferenceLight {
+		t.Errorf("task = %s at %s, want light at %s", first.AssignedTo, first.Level, cc.ModelingLevelInferenceLight)
+	}
+
+	// The single Light slot is taken until the first task finishes
+	if _, err := n.dispatch(rng, "", "", "chat", "zen-mini-0.5b", nil); !errors.Is(err, errLevelUnavailable) {
+		t.Fatalf("dispatch() at capacity error = %v, want %v", err, errLevelUnavailable)
+	}
+	n.mu.Lock()
+	first.Status = TaskCompleted
+	n.finishTaskLocked(first)
+	n.mu.Unlock()
+	if _, err := n.dispatch(rng, "", "", "chat", "zen-mini-0.5b", nil); err != nil {
+		t.Errorf("dispatch() after finish error = %v", err)
+	}
+
+	// Miners that opted into no levels still take any level
+	n.miners["any"] = &MinerInfo{ID: "any", GPUMemoryMB: 81559}
+	if task, err := n.dispatch(rng, "", "", "chat", "qwen3-8b", nil); err != nil || task.AssignedTo != "any" {
+		t.Errorf("dispatch(standard) = %v, %v, want miner any", task, err)
+	}
+}
+
+func TestRegisterModelingLevels(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"light on small GPU", `{"id":"m","gpu_memory_mb":8192,"modeling_levels":{"1":2}}`, http.StatusOK},
+		{"unreported memory", `{"id":"m","modeling_levels":{"3":0}}`, http.StatusOK},
+		{"exceeds VRAM", `{"id":"m","gpu_memory_mb":8192,"modeling_levels":{"1":1,"2":1}}`, http.StatusBadRequest},
+		{"undefined level", `{"id":"m","modeling_levels":{"9":1}}`, http.StatusBadRequest},
+		{"negative concurrency", `{"id":"m","modeling_levels":{"1":-1}}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{})
+			rec := httptest.NewRecorder()
+			n.handleMinerRegister(rec, httptest.NewRequest("POST", "/api/miners/register", strings.NewReader(tt.body)))
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// defaultMaxMessages caps a chat request's messages when
+	// Config.MaxMessages is unset
+	defaultMaxMessages = 256
+
+	// defaultMaxPromptBytes caps the total content of a chat request's
+	// messages when Config.MaxPromptBytes is unset
+	defaultMaxPromptBytes = 1 << 20
+
+	// defaultMaxBodyBytes caps a JSON request body when
+	// Config.MaxBodyBytes is unset
+	defaultMaxBodyBytes = 8 << 20
+)
+
+// maxMessages returns the configured cap on a chat request's message count
+func (n *AINode) maxMessages() int {
+	if n.config.MaxMessages > 0 {
+		return n.config.MaxMessages
+	}
+	return defaultMaxMessages
+}
+
+// maxPromptBytes returns the configured cap on a chat request's total
+// message content
+func (n *AINode) maxPromptBytes() int {
+	if n.config.MaxPromptBytes > 0 {
+		return n.config.MaxPromptBytes
+	}
+	return defaultMaxPromptBytes
+}
+
+// maxBodyBytes returns the configured cap on a JSON request body
+func (n *AINode) maxBodyBytes() int64 {
+	if n.config.MaxBodyBytes > 0 {
+		return int64(n.config.MaxBodyBytes)
+	}
+	return defaultMaxBodyBytes
+}
+
+// bodyErrorStatus returns the status for a request body that could not be
+// read or decoded: 413 if it exceeded the body limit, otherwise 400
+func bodyErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// validateMessages rejects chat requests with too many messages or too much
+// prompt text, before any token counting or dispatch
+func (n *AINode) validateMessages(messages []ChatMessage) error {
+	if len(messages) > n.maxMessages() {
+		return fmt.Errorf("too many messages: %d exceeds the limit of %d", len(messages), n.maxMessages())
+	}
+	total := 0
+	for _, m := range messages {
+		total += len(m.Content)
+	}
+	if total > n.maxPromptBytes() {
+		return fmt.Errorf("prompt too long: %d bytes exceeds the limit of %d", total, n.maxPromptBytes())
+	}
+	return nil
+}
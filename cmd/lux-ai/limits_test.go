@@ -0,0 +1,96 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChatMessageLimits(t *testing.T) {
+	body := func(count, size int) string {
+		msgs := make([]ChatMessage, count)
+		for i := range msgs {
+			msgs[i] = ChatMessage{Role: "user", Content: strings.Repeat("x", size)}
+		}
+		b, _ := json.Marshal(ChatRequest{Messages: msgs})
+		return string(b)
+	}
+
+	tests := []struct {
+		name       string
+		config     Config
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{"within defaults", Config{}, body(3, 10), http.StatusOK, ""},
+		{"too many messages", Config{}, body(defaultMaxMessages+1, 1), http.StatusBadRequest, "too many messages"},
+		{"configured message cap", Config{MaxMessages: 2}, body(3, 1), http.StatusBadRequest, "too many messages"},
+		{"prompt too long", Config{MaxPromptBytes: 100}, body(2, 51), http.StatusBadRequest, "prompt too long"},
+		{"prompt at limit", Config{MaxPromptBytes: 100}, body(2, 50), http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(tt.config)
+			rec := chatRequest(t, n, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantError) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	chat := func(size int) string {
+		b, _ := json.Marshal(ChatRequest{Model: "zen-mini-0.5b", Messages: []ChatMessage{{Role: "user", Content: strings.Repeat("x", size)}}})
+		return string(b)
+	}
+	small, large := chat(100), chat(2000)
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		unsized    bool // Sent without a Content-Length
+		record     bool
+		wantStatus int
+	}{
+		{"within limit", "/v1/chat/completions", small, false, false, http.StatusOK},
+		{"declared too large", "/v1/chat/completions", large, false, false, http.StatusRequestEntityTooLarge},
+		{"grows too large", "/v1/chat/completions", large, true, false, http.StatusRequestEntityTooLarge},
+		{"recorded", "/v1/chat/completions", large, true, true, http.StatusRequestEntityTooLarge},
+		{"miner route", "/api/miners/register", `{"id":"` + strings.Repeat("m", 2000) + `"}`, true, false, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{MaxBodyBytes: 1024})
+			if tt.record {
+				rec, err := NewRecorder(t.TempDir())
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer rec.Close()
+				n.recorder = rec
+			}
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.unsized {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			n.newMux().ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
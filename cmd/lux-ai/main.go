@@ -5,15 +5,29 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+
+	"github.com/luxfi/ai/pkg/attestation"
+	"github.com/luxfi/ai/pkg/cc"
+	"github.com/luxfi/ai/pkg/clock"
+	"github.com/luxfi/ai/pkg/miner/backend"
 )
 
 var (
@@ -25,10 +39,35 @@ type AINode struct {
 	config  Config
 	mu      sync.RWMutex
 	miners  map[string]*MinerInfo
+	tokens  map[string]string // miner ID -> bearer token issued at registration
 	tasks   map[string]*Task
 	models  map[string]*ModelInfo
 	server  *http.Server
 	running bool
+
+	scheduler Scheduler
+	schedSeq  atomic.Uint64 // Scheduling decisions drawn from Config.SchedulerSeed
+	inflight  ModelCounters // In-flight tasks per model
+
+	recorder *Recorder // nil unless Config.RecordRequests
+
+	compaction CompactionStats // Guarded by mu
+
+	limiter rateLimiter // Per-caller request buckets for the /v1 API
+	usage   usageMeter  // Per-API-key usage; see usage.go
+
+	capability capabilityCache // Host CC detection for /api/capability
+
+	maintenance maintenanceState // Guarded by mu; see maintenance.go
+
+	clock clock.Clock // Time for task, miner and maintenance bookkeeping
+
+	// Verifies miner attestation evidence, with the registered key each
+	// miner's software attestations are checked against; see attestation.go
+	attestMu   sync.Mutex
+	verifier   *attestation.Verifier
+	attestKeys map[string]ed25519.PublicKey
+	attestLog  io.Writer // Where Config.LogAttestations prints
 }
 
 // Config holds node configuration
@@ -38,6 +77,49 @@ type Config struct {
 	NodeURL        string   `json:"node_url"`
 	EnableCORS     bool     `json:"enable_cors"`
 	AllowedOrigins []string `json:"allowed_origins"`
+	RecordRequests bool     `json:"record_requests"` // Append chat exchanges to DataDir/recordings.jsonl
+	Scheduler      string   `json:"scheduler"`       // round-robin, least-loaded, or trust-weighted
+	SchedulerSeed  int64    `json:"scheduler_seed"`  // Seeds scheduling of requests without a session ID (0 = clock)
+	AutoDowngrade  bool     `json:"auto_downgrade"`  // Route prompts to the smallest fitting model in the family
+	MaxChoices     int      `json:"max_choices"`     // Upper bound on a chat request's n (0 = default)
+	MaxRetries     int      `json:"max_retries"`     // Reassignments of a failed task before it is dead-lettered
+
+	ContextOverflowPolicy string `json:"context_overflow_policy"` // reject, truncate-head, or truncate-preserve-system ("" = reject)
+
+	ModelRoutes ModelRoutes `json:"model_routes"` // Virtual chat models split across concrete ones by weight
+
+	MaxStopSequences int `json:"max_stop_sequences"` // Upper bound on a chat request's stop (0 = default)
+	MaxMessages      int `json:"max_messages"`       // Upper bound on a chat request's messages (0 = default)
+	MaxPromptBytes   int `json:"max_prompt_bytes"`   // Upper bound on a chat request's total message content (0 = default)
+	MaxBodyBytes     int `json:"max_body_bytes"`     // Upper bound on a JSON request body (0 = default)
+
+	MaxBatchConcurrency int `json:"max_batch_concurrency"` // Embeddings in flight per batch request (0 = default)
+	MaxCapacityTPS      int `json:"max_capacity_tps"`      // Ceiling on the tokens/sec a miner may report (0 = default)
+
+	// How long a request waits for each task; see timeout.go
+	RequestTimeout    time.Duration `json:"request_timeout"`     // Without TimeoutHeader (0 = 30s)
+	MaxRequestTimeout time.Duration `json:"max_request_timeout"` // Ceiling on TimeoutHeader (0 = 5m)
+
+	ModelMinTrust map[string]uint8 `json:"model_min_trust"` // Model ID -> trust score a miner needs to be assigned its tasks
+
+	TaskRetention time.Duration `json:"task_retention"` // How long finished tasks stay pollable before compaction (0 = default)
+	ArchiveTasks  bool          `json:"archive_tasks"`  // Append compacted tasks to DataDir/tasks-<date>.jsonl
+
+	// Rate limiting of the /v1 API; see ratelimit.go for precedence
+	RateLimitRPM int                  `json:"rate_limit_rpm"` // Requests per minute per caller without a tier rate (0 = unlimited)
+	TierRPM      map[cc.CCTier]int    `json:"tier_rpm"`       // Requests per minute per API key of each CC tier (0 = unlimited)
+	KeyTiers     map[string]cc.CCTier `json:"-"`              // API key -> CC tier
+
+	DriverPolicy *attestation.DriverPolicy `json:"driver_policy,omitempty"` // Driver, CUDA and VBIOS versions scored in software attestations (nil = default)
+
+	// Logging of miner attestations, always redacted; see pkg/attestation/redact.go
+	LogAttestations bool `json:"log_attestations"`  // Print each attestation a miner submits and its outcome
+	RedactIDPrefix  int  `json:"redact_id_prefix"`  // Serial and device ID characters kept in logs (0 = default)
+	RedactHashBytes int  `json:"redact_hash_bytes"` // SHA-256 bytes kept for raw evidence in logs, up to 32 (0 = default)
+
+	AdminToken string `json:"-"` // Bearer token allowed to query every key's usage and toggle maintenance; also marks replayed requests
+
+	Maintenance bool `json:"maintenance"` // Start with the /v1 API in maintenance mode
 }
 
 // MinerInfo tracks connected miners
@@ -48,6 +130,31 @@ type MinerInfo struct {
 	GPUEnabled   bool      `json:"gpu_enabled"`
 	LastSeen     time.Time `json:"last_seen"`
 	TasksHandled uint64    `json:"tasks_handled"`
+	TrustScore   uint8     `json:"trust_score"`             // 0-100, weights trust-weighted scheduling
+	ActiveTasks  int       `json:"active_tasks"`            // Tasks assigned and not yet finished
+	Models       []string  `json:"models,omitempty"`        // Local models the miner advertises
+	CapacityTPS  float64   `json:"capacity_tps,omitempty"`  // Benchmarked tokens/sec, 0 if unknown
+	GPUMemoryMB  uint64    `json:"gpu_memory_mb,omitempty"` // Reported GPU memory in MiB, 0 if unknown
+	PublicKey    []byte    `json:"public_key,omitempty"`    // Ed25519 key that signs results; required on submit if set
+	Region       string    `json:"region,omitempty"`        // Locality, matched against RegionHeader
+
+	// Modeling levels the miner opts into, each with its concurrency limit
+	// (0 = no limit). Empty means the miner takes tasks at any level.
+	ModelingLevels map[cc.ModelingLevel]int `json:"modeling_levels,omitempty"`
+	levelTasks     map[cc.ModelingLevel]int // Active tasks per modeling level
+
+	// Hardware capability detected by the miner, with MaxTier validated
+	// against the hardware at registration; nil if not reported, in which
+	// case the miner is held to Tier4
+	Capability *cc.HardwareCapability `json:"capability,omitempty"`
+
+	// Latest attestation verified on /api/miners/attestation
+	Tier          cc.CCTier `json:"tier,omitempty"`
+	AttestedUntil time.Time `json:"attested_until,omitempty"`
+
+	heartbeatAt  time.Time // Timestamp of the last accepted signed heartbeat
+	registeredAt time.Time // Timestamp of the last accepted signed re-registration
+	cancelled    []string  // Running tasks cancelled since the last heartbeat
 }
 
 // Task represents an AI task
@@ -58,8 +165,37 @@ type Task struct {
 	Input      json.RawMessage `json:"input"`
 	Output     json.RawMessage `json:"output,omitempty"`
 	Status     string          `json:"status"`
+	Error      string          `json:"error,omitempty"` // Failure reason reported by the miner
 	AssignedTo string          `json:"assigned_to,omitempty"`
 	CreatedAt  time.Time       `json:"created_at"`
+	owner      string          // Key ID of the API key that submitted the task; see taskOwner
+	FinishedAt time.Time       `json:"finished_at,omitempty"` // When the task completed or died
+
+	Level cc.ModelingLevel `json:"modeling_level,omitempty"` // The model's modeling level when dispatched
+
+	// Retry bookkeeping
+	AssignedAt  time.Time `json:"assigned_at,omitempty"`
+	Attempts    int       `json:"attempts"`
+	TriedMiners []string  `json:"tried_miners,omitempty"`
+	Failures    []string  `json:"failures,omitempty"` // One entry per failed attempt
+
+	// Chunked output (see /api/tasks/append)
+	Partial string         `json:"partial,omitempty"`  // Contiguous chunks assembled so far
+	NextSeq int            `json:"next_seq,omitempty"` // Next chunk sequence number expected
+	Chunks  int            `json:"chunks,omitempty"`   // On final submit: total chunks sent
+	chunks  map[int]string // Out-of-order chunks awaiting earlier ones
+
+	progressAt time.Time // When the current attempt's latest chunk was accepted
+
+	// Result signature over minersig.ResultDigest, recorded when the
+	// completing miner registered a public key
+	Signature []byte    `json:"signature,omitempty"`
+	SignedAt  time.Time `json:"signed_at,omitempty"`
+	SignedBy  string    `json:"signed_by,omitempty"`
+
+	assignedKey ed25519.PublicKey // Assignee's key when the attempt was assigned; its result must be signed with it
+
+	Timeline []TaskEvent `json:"timeline,omitempty"` // Lifecycle events; see timeline.go
 }
 
 // ModelInfo describes available models
@@ -69,35 +205,72 @@ type ModelInfo struct {
 	Type         string   `json:"type"`
 	Capabilities []string `json:"capabilities"`
 	ContextSize  int      `json:"context_size"`
+	Family       string   `json:"family"`                // Models in a family may substitute for each other
+	ParamsB      float64  `json:"params_b"`              // Parameter count in billions
+	MinVRAMGB    uint64   `json:"min_vram_gb,omitempty"` // GPU memory a miner needs to serve the model, 0 if any
+
+	MinTrustScore uint8 `json:"min_trust_score,omitempty"` // Trust score a miner needs to serve the model, 0 if any
+
+	ModelingLevel cc.ModelingLevel `json:"modeling_level,omitempty"` // Workload level; miners opting into levels must list it
+
+	// Streaming is set when miners relay the model's output incrementally.
+	// Streamed requests for other models get the whole completion as one
+	// chunk once it is done.
+	Streaming bool `json:"streaming"`
+
+	// DefaultParams are the sampling parameters used when a chat request
+	// omits them; see applyDefaultParams
+	DefaultParams *SamplingParams `json:"default_params,omitempty"`
+}
+
+// ChatMessage is a single message in a chat conversation
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
 }
 
 // ChatRequest represents a chat API request
 type ChatRequest struct {
-	Model    string `json:"model"`
-	Messages []struct {
-		Role    string `json:"role"`
-		Content string `json:"content"`
-	} `json:"messages"`
-	MaxTokens   int     `json:"max_tokens,omitempty"`
-	Temperature float64 `json:"temperature,omitempty"`
-	Stream      bool    `json:"stream,omitempty"`
+	Model       string        `json:"model"`
+	Messages    []ChatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+	N           int           `json:"n,omitempty"` // Number of completions, default 1
+
+	// Optional sampling parameters, passed through to the miner; see
+	// validateSampling for the accepted ranges. Seed is echoed in the
+	// response.
+	TopP             *float64 `json:"top_p,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	Seed             *int64   `json:"seed,omitempty"`
+
+	// Stop ends generation at the first of these sequences, which is not
+	// included in the output; finish_reason is then stop
+	Stop StopSequences `json:"stop,omitempty"`
+
+	// ResponseFormat {"type":"json_object"} guarantees each choice is a
+	// JSON object; see ensureJSONContents
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ChatChoice is one generated completion in a ChatResponse
+type ChatChoice struct {
+	Index        int         `json:"index"`
+	Message      ChatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
 }
 
 // ChatResponse represents a chat API response
 type ChatResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Index   int `json:"index"`
-		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		} `json:"message"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
-	Usage struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Seed    *int64       `json:"seed,omitempty"` // The request's seed, if set
+	Choices []ChatChoice `json:"choices"`
+	Usage   struct {
 		PromptTokens     int `json:"prompt_tokens"`
 		CompletionTokens int `json:"completion_tokens"`
 		TotalTokens      int `json:"total_tokens"`
@@ -110,6 +283,38 @@ func main() {
 		dataDir     = flag.String("data", "./data", "Data directory")
 		nodeURL     = flag.String("node", "http://localhost:9650", "Lux node URL")
 		enableCORS  = flag.Bool("cors", true, "Enable CORS")
+		downgrade   = flag.Bool("auto-downgrade", false, "Route chat requests to the smallest model in the family that fits the prompt")
+		maxChoices  = flag.Int("max-n", defaultMaxChoices, "Maximum completions (n) per chat request")
+		maxRetries  = flag.Int("max-retries", defaultMaxRetries, "Retries for a failed task before it is dead-lettered")
+		maxStop     = flag.Int("max-stop", defaultMaxStopSequences, "Maximum stop sequences per chat request")
+		maxMessages = flag.Int("max-messages", defaultMaxMessages, "Maximum messages per chat request")
+		maxPrompt   = flag.Int("max-prompt-bytes", defaultMaxPromptBytes, "Maximum total message content per chat request, in bytes")
+		maxBody     = flag.Int("max-body-bytes", defaultMaxBodyBytes, "Maximum JSON request body, in bytes")
+		maxBatch    = flag.Int("max-batch-concurrency", defaultMaxBatchConcurrency, "Maximum embeddings in flight per batch request")
+		maxCapacity = flag.Int("max-capacity-tps", defaultMaxCapacityTPS, "Ceiling on the benchmarked tokens/sec a miner may report at registration")
+		reqTimeout  = flag.Duration("request-timeout", dispatchTimeout, "How long a request waits for a miner without an X-Lux-Timeout header")
+		maxTimeout  = flag.Duration("max-request-timeout", defaultMaxRequestTimeout, "Ceiling on the X-Lux-Timeout a client may ask for")
+		minTrust    = flag.String("min-trust", "", "Minimum miner trust score per model, e.g. qwen3-8b=70,zen-coder-1.5b=50")
+		retention   = flag.Duration("task-retention", defaultTaskRetention, "How long finished tasks are kept before compaction")
+		archive     = flag.Bool("archive-tasks", false, "Append compacted tasks to dated archive files in the data directory")
+		rateLimit   = flag.Int("rate-limit", 0, "Requests per minute per API key or client IP without a tier rate (0 = unlimited)")
+		tierRPM     = flag.String("tier-rpm", "", "Requests per minute per API key by CC tier, e.g. 1=600,2=300,3=120")
+		keyTiers    = flag.String("key-tiers", "", "JSON file mapping API keys to CC tiers (1-4)")
+		logAttest   = flag.Bool("log-attestations", false, "Log each attestation a miner submits, with serials truncated and raw evidence hashed")
+		redactID    = flag.Int("redact-id-prefix", attestation.DefaultRedactIDPrefix, "Characters of GPU serials and device IDs kept in attestation logs")
+		redactHash  = flag.Int("redact-hash-bytes", attestation.DefaultRedactHashBytes, "Bytes of the SHA-256 kept for raw evidence in attestation logs (up to 32)")
+		driverPol   = flag.String("driver-policy", "", "JSON file with the GPU driver, CUDA and VBIOS version policy, including known-vulnerable ranges")
+		routes      = flag.String("model-routes", "", "JSON file mapping virtual model names to weighted models, e.g. {\"zen-chat\": {\"zen-mini-0.5b\": 90, \"qwen3-8b\": 10}}")
+		adminToken  = flag.String("admin-token", "", "Bearer token that may query every API key's usage and toggle maintenance mode; -replay sends it to mark replayed requests")
+		maintenance = flag.Bool("maintenance", false, "Start in maintenance mode, refusing new /v1 requests with 503")
+		scheduler   = flag.String("scheduler", SchedulerRoundRobin, "Miner scheduler: round-robin, least-loaded, trust-weighted")
+		schedSeed   = flag.Int64("scheduler-seed", 0, "Seed for scheduling requests without a session ID, making miner selection replayable (0 = clock)")
+		overflow    = flag.String("context-overflow", ContextPolicyReject, "Prompts over the model context: reject, truncate-head, truncate-preserve-system")
+		record      = flag.Bool("record", false, "Record chat requests/responses to the data directory")
+		replay      = flag.String("replay", "", "Replay a recordings file against a running node and exit")
+		replayURL   = flag.String("replay-url", "", "Node API URL for -replay (default http://localhost:<port>)")
+		inventory   = flag.String("inventory", "", "Collect /api/capability from the node URLs listed in a file (- for stdin), print a fleet inventory and exit")
+		invFormat   = flag.String("inventory-format", InventoryCSV, "Output format for -inventory: csv or json")
 		showVersion = flag.Bool("version", false, "Show version")
 	)
 
@@ -120,12 +325,114 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *replay != "" {
+		target := *replayURL
+		if target == "" {
+			target = fmt.Sprintf("http://localhost:%d", *port)
+		}
+		result, err := replayRecordings(context.Background(), *replay, target, *adminToken, os.Stdout)
+		if result != nil {
+			fmt.Printf("Replayed %d requests: %d matched, %d mismatched, %d errors\n",
+				result.Total, result.Matched, result.Mismatched, result.Errors)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Replay failed: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if *inventory != "" {
+		failed, err := runInventory(context.Background(), *inventory, *invFormat, os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Inventory failed: %v\n", err)
+			os.Exit(1)
+		}
+		if failed > 0 {
+			fmt.Fprintf(os.Stderr, "%d nodes could not be queried; see the error column\n", failed)
+		}
+		os.Exit(0)
+	}
+
 	config := Config{
 		Port:           *port,
 		DataDir:        *dataDir,
 		NodeURL:        *nodeURL,
 		EnableCORS:     *enableCORS,
 		AllowedOrigins: []string{"*"},
+		RecordRequests: *record,
+		Scheduler:      *scheduler,
+		SchedulerSeed:  *schedSeed,
+		AutoDowngrade:  *downgrade,
+		MaxChoices:     *maxChoices,
+		MaxRetries:     *maxRetries,
+
+		ContextOverflowPolicy: *overflow,
+
+		MaxStopSequences: *maxStop,
+		MaxMessages:      *maxMessages,
+		MaxPromptBytes:   *maxPrompt,
+		MaxBodyBytes:     *maxBody,
+
+		MaxBatchConcurrency: *maxBatch,
+		MaxCapacityTPS:      *maxCapacity,
+
+		RequestTimeout:    *reqTimeout,
+		MaxRequestTimeout: *maxTimeout,
+
+		TaskRetention: *retention,
+		ArchiveTasks:  *archive,
+
+		RateLimitRPM: *rateLimit,
+
+		LogAttestations: *logAttest,
+		RedactIDPrefix:  *redactID,
+		RedactHashBytes: *redactHash,
+
+		AdminToken: *adminToken,
+
+		Maintenance: *maintenance,
+	}
+
+	trust, err := parseModelMinTrust(*minTrust)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	config.ModelMinTrust = trust
+	rates, err := parseTierRPM(*tierRPM)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	config.TierRPM = rates
+	if *keyTiers != "" {
+		tiers, err := loadKeyTiers(*keyTiers)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		config.KeyTiers = tiers
+	}
+	if *driverPol != "" {
+		policy, err := loadDriverPolicy(*driverPol)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		config.DriverPolicy = policy
+	}
+	if *routes != "" {
+		r, err := loadModelRoutes(*routes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		config.ModelRoutes = r
+	}
+	if err := config.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(1)
 	}
 
 	node := NewAINode(config)
@@ -160,39 +467,80 @@ func main() {
 	fmt.Println("AI Node stopped.")
 }
 
-// NewAINode creates a new AI node
+// NewAINode creates a new AI node. An unknown Config.Scheduler falls back
+// to round-robin.
 func NewAINode(config Config) *AINode {
-	return &AINode{
-		config: config,
-		miners: make(map[string]*MinerInfo),
-		tasks:  make(map[string]*Task),
-		models: defaultModels(),
+	scheduler, err := NewScheduler(config.Scheduler)
+	if err != nil {
+		scheduler = &RoundRobinScheduler{}
+	}
+	models := defaultModels()
+	for id, score := range config.ModelMinTrust {
+		if m, ok := models[id]; ok {
+			m.MinTrustScore = score
+		}
+	}
+	n := &AINode{
+		config:     config,
+		miners:     make(map[string]*MinerInfo),
+		tokens:     make(map[string]string),
+		tasks:      make(map[string]*Task),
+		models:     models,
+		scheduler:  scheduler,
+		clock:      clock.Real{},
+		verifier:   attestation.NewVerifier(),
+		attestKeys: make(map[string]ed25519.PublicKey),
+		attestLog:  os.Stdout,
+	}
+	n.verifier.SetClock(nodeClock{n})
+	n.verifier.SetDriverPolicy(config.DriverPolicy)
+	if config.Maintenance {
+		n.maintenance = maintenanceState{Enabled: true, Since: n.clock.Now(), RetryAfter: defaultMaintenanceRetryAfter}
 	}
+	return n
 }
 
 // defaultModels returns the default available models
 func defaultModels() map[string]*ModelInfo {
 	return map[string]*ModelInfo{
 		"zen-coder-1.5b": {
-			ID:           "zen-coder-1.5b",
-			Name:         "Zen Coder 1.5B",
-			Type:         "chat",
-			Capabilities: []string{"code", "chat", "completion"},
-			ContextSize:  32768,
+			ID:            "zen-coder-1.5b",
+			Name:          "Zen Coder 1.5B",
+			Type:          "chat",
+			Capabilities:  []string{"code", "chat", "completion"},
+			ContextSize:   32768,
+			Family:        "zen",
+			ParamsB:       1.5,
+			MinVRAMGB:     cc.ModelingLevelInferenceLight.MinVRAMGB(),
+			ModelingLevel: cc.ModelingLevelInferenceLight,
+			Streaming:     true,
+			DefaultParams: &SamplingParams{Temperature: float64Ptr(0.2), TopP: float64Ptr(0.95)},
 		},
 		"zen-mini-0.5b": {
-			ID:           "zen-mini-0.5b",
-			Name:         "Zen Mini 0.5B",
-			Type:         "chat",
-			Capabilities: []string{"chat", "completion"},
-			ContextSize:  8192,
+			ID:            "zen-mini-0.5b",
+			Name:          "Zen Mini 0.5B",
+			Type:          "chat",
+			Capabilities:  []string{"chat", "completion"},
+			ContextSize:   8192,
+			Family:        "zen",
+			ParamsB:       0.5,
+			MinVRAMGB:     cc.ModelingLevelInferenceLight.MinVRAMGB(),
+			ModelingLevel: cc.ModelingLevelInferenceLight,
+			Streaming:     true,
+			DefaultParams: &SamplingParams{Temperature: float64Ptr(0.7), TopP: float64Ptr(0.8)},
 		},
 		"qwen3-8b": {
-			ID:           "qwen3-8b",
-			Name:         "Qwen3 8B",
-			Type:         "chat",
-			Capabilities: []string{"chat", "code", "reasoning"},
-			ContextSize:  131072,
+			ID:            "qwen3-8b",
+			Name:          "Qwen3 8B",
+			Type:          "chat",
+			Capabilities:  []string{"chat", "code", "reasoning"},
+			ContextSize:   131072,
+			Family:        "qwen3",
+			ParamsB:       8,
+			MinVRAMGB:     cc.ModelingLevelInferenceStandard.MinVRAMGB(),
+			ModelingLevel: cc.ModelingLevelInferenceStandard,
+			Streaming:     true,
+			DefaultParams: &SamplingParams{Temperature: float64Ptr(0.6), TopP: float64Ptr(0.95)},
 		},
 	}
 }
@@ -212,32 +560,68 @@ func (n *AINode) Start(ctx context.Context) error {
 		return err
 	}
 
+	if n.config.RecordRequests {
+		rec, err := NewRecorder(n.config.DataDir)
+		if err != nil {
+			return err
+		}
+		n.recorder = rec
+	}
+
+	if err := n.usage.load(n.usagePath()); err != nil {
+		return fmt.Errorf("load usage: %w", err)
+	}
+
+	go n.sweepTasks(ctx)
+	go n.compactTasksLoop(ctx)
+	go n.persistUsageLoop(ctx)
+
+	n.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", n.config.Port),
+		Handler: n.newMux(),
+	}
+
+	go n.server.ListenAndServe()
+
+	return nil
+}
+
+// newMux returns the node's HTTP routes
+func (n *AINode) newMux() *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// OpenAI-compatible API
-	mux.HandleFunc("/v1/chat/completions", n.corsMiddleware(n.handleChatCompletions))
-	mux.HandleFunc("/v1/models", n.corsMiddleware(n.handleModels))
-	mux.HandleFunc("/v1/embeddings", n.corsMiddleware(n.handleEmbeddings))
+	mux.HandleFunc("/v1/chat/completions", n.corsMiddleware(n.maintenanceMiddleware(n.timeoutMiddleware(n.jsonMiddleware(n.rateLimitMiddleware(n.recordMiddleware(n.handleChatCompletions)))))))
+	mux.HandleFunc("/v1/models", n.corsMiddleware(n.maintenanceMiddleware(n.rateLimitMiddleware(n.handleModels))))
+	mux.HandleFunc("/v1/embeddings", n.corsMiddleware(n.maintenanceMiddleware(n.timeoutMiddleware(n.jsonMiddleware(n.rateLimitMiddleware(n.handleEmbeddings))))))
+	mux.HandleFunc("/v1/embeddings/batch", n.corsMiddleware(n.maintenanceMiddleware(n.timeoutMiddleware(n.ndjsonMiddleware(n.rateLimitMiddleware(n.handleEmbeddingsBatch))))))
+	mux.HandleFunc("/v1/moderations", n.corsMiddleware(n.maintenanceMiddleware(n.timeoutMiddleware(n.jsonMiddleware(n.rateLimitMiddleware(n.handleModerations))))))
+	mux.HandleFunc("/v1/", n.corsMiddleware(n.maintenanceMiddleware(n.handleNotImplemented)))
 
 	// Lux AI API
 	mux.HandleFunc("/api/miners", n.corsMiddleware(n.handleMiners))
-	mux.HandleFunc("/api/miners/register", n.corsMiddleware(n.handleMinerRegister))
+	mux.HandleFunc("/api/miners/register", n.corsMiddleware(n.jsonMiddleware(n.handleMinerRegister)))
+	mux.HandleFunc("/api/miners/deregister", n.corsMiddleware(n.jsonMiddleware(n.handleMinerDeregister)))
+	mux.HandleFunc("/api/miners/models", n.corsMiddleware(n.jsonMiddleware(n.handleMinerModels)))
+	mux.HandleFunc("/api/miners/attestation", n.corsMiddleware(n.jsonMiddleware(n.handleMinerAttestation)))
+	mux.HandleFunc("/api/miners/attestation/challenge", n.corsMiddleware(n.jsonMiddleware(n.handleMinerAttestationChallenge)))
+	mux.HandleFunc("/api/miners/heartbeat", n.corsMiddleware(n.jsonMiddleware(n.handleMinerHeartbeat)))
 	mux.HandleFunc("/api/tasks", n.corsMiddleware(n.handleTasks))
 	mux.HandleFunc("/api/tasks/pending", n.corsMiddleware(n.handlePendingTasks))
-	mux.HandleFunc("/api/tasks/submit", n.corsMiddleware(n.handleSubmitResult))
+	mux.HandleFunc("/api/tasks/submit", n.corsMiddleware(n.jsonMiddleware(n.handleSubmitResult)))
+	mux.HandleFunc("/api/tasks/append", n.corsMiddleware(n.jsonMiddleware(n.handleAppendChunk)))
+	mux.HandleFunc("/api/tasks/dead", n.corsMiddleware(n.handleDeadTasks))
+	mux.HandleFunc("/api/tasks/{id}", n.corsMiddleware(n.handleTask))
+	mux.HandleFunc("/api/tasks/{id}/timeline", n.corsMiddleware(n.handleTaskTimeline))
 	mux.HandleFunc("/api/stats", n.corsMiddleware(n.handleStats))
+	mux.HandleFunc("/api/usage", n.corsMiddleware(n.handleUsage))
+	mux.HandleFunc("/api/capability", n.corsMiddleware(n.handleCapability))
+	mux.HandleFunc("/api/admin/maintenance", n.corsMiddleware(n.jsonMiddleware(n.handleMaintenance)))
 
 	// Health check
 	mux.HandleFunc("/health", n.handleHealth)
 
-	n.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", n.config.Port),
-		Handler: mux,
-	}
-
-	go n.server.ListenAndServe()
-
-	return nil
+	return mux
 }
 
 // Stop halts the AI node server
@@ -250,40 +634,74 @@ func (n *AINode) Stop() error {
 	n.running = false
 	n.mu.Unlock()
 
+	var err error
 	if n.server != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		return n.server.Shutdown(ctx)
+		err = n.server.Shutdown(ctx)
 	}
-	return nil
+	if n.recorder != nil {
+		n.recorder.Close()
+	}
+	if saveErr := n.usage.save(n.usagePath()); err == nil {
+		err = saveErr
+	}
+	return err
 }
 
-// corsMiddleware adds CORS headers
+// allowedMethods is advertised in Allow and Access-Control-Allow-Methods
+const allowedMethods = "GET, POST, PUT, DELETE, OPTIONS"
+
+// corsMiddleware adds CORS headers when enabled and answers OPTIONS with
+// 204 either way, so preflights never reach handlers that would reject the
+// method. With CORS disabled no Access-Control headers are sent, so
+// browsers still refuse the cross-origin request.
 func (n *AINode) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if n.config.EnableCORS {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
 			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
+		}
+		if r.Method == "OPTIONS" {
+			w.Header().Set("Allow", allowedMethods)
+			w.WriteHeader(http.StatusNoContent)
+			return
 		}
 		next(w, r)
 	}
 }
 
-// handleChatCompletions handles OpenAI-compatible chat API
+// handleChatCompletions handles OpenAI-compatible chat API.
+//
+// With response_format {"type":"json_object"} the format is forwarded to the
+// miner's backend, and every returned choice is also checked to be a JSON
+// object: an invalid choice is regenerated once, then the request fails with
+// 502. JSON mode cannot be combined with stream, and unknown format types
+// are rejected with 400.
 func (n *AINode) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	validateOnly, err := dryRun(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var req ChatRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+	requested := req.Model
+	if routed, ok := n.config.ModelRoutes.pick(req.Model, n.requestRNG(r)); ok {
+		w.Header().Set(RoutedFromHeader, req.Model)
+		req.Model = routed
+	}
+	if err := n.validateMessages(req.Messages); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -299,34 +717,149 @@ func (n *AINode) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 		model = n.models[req.Model]
 	}
 
-	// Generate response (placeholder - would route to miner)
+	if n.config.AutoDowngrade {
+		if smaller := n.downgradeModel(model, &req); smaller != nil {
+			w.Header().Set(DowngradedFromHeader, req.Model)
+			req.Model = smaller.ID
+			model = smaller
+		}
+	}
+
+	drop, err := fitContext(&req, model, n.contextPolicy())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	setDropHeaders(w, drop)
+
+	choices := req.N
+	if choices == 0 {
+		choices = 1
+	}
+	if choices < 0 || choices > n.maxChoices() {
+		http.Error(w, fmt.Sprintf("n must be between 1 and %d", n.maxChoices()), http.StatusBadRequest)
+		return
+	}
+	if err := req.ResponseFormat.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	jsonMode := req.ResponseFormat.jsonObject()
+	if err := n.validateStop(req.Stop); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := req.validateSampling(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.applyDefaultParams(model)
+	if req.Stream {
+		if choices != 1 {
+			http.Error(w, "stream does not support n > 1", http.StatusBadRequest)
+			return
+		}
+		if jsonMode {
+			// Streamed chunks can't be validated before they are sent
+			http.Error(w, "response_format json_object is not supported with stream", http.StatusBadRequest)
+			return
+		}
+	}
+	if validateOnly {
+		writeChatValidation(w, requested, &req, model, choices)
+		return
+	}
+
+	// Each task generates a single completion
+	single := req
+	single.N = 0
+	input, err := json.Marshal(single)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	placeholder := fmt.Sprintf("Hello! I'm %s running on the Lux AI network. How can I help you today?", model.Name)
+	if jsonMode {
+		b, _ := json.Marshal(map[string]string{"message": placeholder})
+		placeholder = string(b)
+	}
+	if req.Stream && model.Streaming {
+		n.streamChat(w, r, &req, input, placeholder)
+		return
+	}
+	if req.Stream {
+		n.synthesizeStream(w, r, &req, input, placeholder)
+		return
+	}
+
+	outputs, err := n.generate(r, "chat", req.Model, input, choices)
+	if errors.Is(err, errNoMiners) {
+		// No miners connected yet: answer with a placeholder
+		completions := make([]chatCompletion, choices)
+		for i := range completions {
+			completions[i] = chatCompletion{Content: placeholder, FinishReason: backend.FinishReasonStop}
+		}
+		n.writeChatResponse(w, r, &req, completions)
+		return
+	}
+	if err != nil {
+		writeGenerateError(w, err)
+		return
+	}
+
+	completions := make([]chatCompletion, len(outputs))
+	for i, out := range outputs {
+		c, err := parseChatOutput(out, req.Stop)
+		if err != nil {
+			http.Error(w, "invalid miner output", http.StatusBadGateway)
+			return
+		}
+		completions[i] = c
+	}
+	if jsonMode {
+		if err := n.ensureJSONContents(r, req.Model, input, req.Stop, completions); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+	n.writeChatResponse(w, r, &req, completions)
+}
+
+// maxChoices returns the configured cap on a chat request's n
+func (n *AINode) maxChoices() int {
+	if n.config.MaxChoices > 0 {
+		return n.config.MaxChoices
+	}
+	return defaultMaxChoices
+}
+
+// writeChatResponse writes an OpenAI-compatible chat completion with one
+// choice per completion and meters its usage. The prompt is counted once;
+// completion usage is summed across choices.
+func (n *AINode) writeChatResponse(w http.ResponseWriter, r *http.Request, req *ChatRequest, completions []chatCompletion) {
 	response := ChatResponse{
 		ID:      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
 		Object:  "chat.completion",
 		Created: time.Now().Unix(),
 		Model:   req.Model,
+		Seed:    req.Seed,
+		Choices: make([]ChatChoice, 0, len(completions)),
 	}
-	response.Choices = append(response.Choices, struct {
-		Index   int `json:"index"`
-		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		} `json:"message"`
-		FinishReason string `json:"finish_reason"`
-	}{
-		Index: 0,
-		Message: struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		}{
-			Role:    "assistant",
-			Content: fmt.Sprintf("Hello! I'm %s running on the Lux AI network. How can I help you today?", model.Name),
-		},
-		FinishReason: "stop",
-	})
-	response.Usage.PromptTokens = 10
-	response.Usage.CompletionTokens = 20
-	response.Usage.TotalTokens = 30
+	promptTokens := estimatePromptTokens(req)
+	completionTokens := 0
+	for i, c := range completions {
+		response.Choices = append(response.Choices, ChatChoice{
+			Index:        i,
+			Message:      ChatMessage{Role: "assistant", Content: c.Content},
+			FinishReason: c.FinishReason,
+		})
+		completionTokens += estimateTokens(c.Content)
+	}
+	response.Usage.PromptTokens = promptTokens
+	response.Usage.CompletionTokens = completionTokens
+	response.Usage.TotalTokens = promptTokens + completionTokens
+	n.recordUsage(r, req.Model, promptTokens, completionTokens)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -339,11 +872,25 @@ func (n *AINode) handleModels(w http.ResponseWriter, r *http.Request) {
 
 	models := make([]map[string]interface{}, 0, len(n.models))
 	for _, m := range n.models {
+		entry := map[string]interface{}{
+			"id":        m.ID,
+			"object":    "model",
+			"created":   time.Now().Unix(),
+			"owned_by":  "lux-ai",
+			"streaming": m.Streaming,
+		}
+		if m.DefaultParams != nil {
+			entry["default_params"] = m.DefaultParams
+		}
+		models = append(models, entry)
+	}
+	for _, name := range sortedKeys(n.config.ModelRoutes) {
 		models = append(models, map[string]interface{}{
-			"id":       m.ID,
+			"id":       name,
 			"object":   "model",
 			"created":  time.Now().Unix(),
 			"owned_by": "lux-ai",
+			"routes":   n.config.ModelRoutes[name],
 		})
 	}
 
@@ -354,7 +901,10 @@ func (n *AINode) handleModels(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleEmbeddings handles embedding requests
+// handleEmbeddings handles embedding requests. Input may be a string or an
+// array of strings; inputs that are empty, not strings or too long for the
+// model are reported in "errors" by index while the rest are embedded. The
+// request fails with 400 only if every input does.
 func (n *AINode) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -362,42 +912,102 @@ func (n *AINode) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Input string `json:"input"`
-		Model string `json:"model"`
+		Input          json.RawMessage `json:"input"`
+		Model          string          `json:"model"`
+		EncodingFormat string          `json:"encoding_format"` // EncodingFloat (default) or EncodingBase64
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+	items, err := parseEmbeddingInput(req.Input)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// Placeholder embedding
-	embedding := make([]float64, 1536)
+	embedding, err := encodeEmbedding(make([]float64, placeholderEmbeddingDims), req.EncodingFormat)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	n.mu.RLock()
+	model := n.models[req.Model]
+	n.mu.RUnlock()
+
+	data := make([]map[string]interface{}, 0, len(items))
+	var failed []EmbeddingError
+	for i, item := range items {
+		if _, err := n.embeddingText(item, model); err != nil {
+			failed = append(failed, EmbeddingError{Index: i, Error: err.Error()})
+			continue
+		}
+		data = append(data, map[string]interface{}{
+			"object":    "embedding",
+			"embedding": embedding,
+			"index":     i,
+		})
+	}
+
+	tokens := placeholderEmbeddingTokens * len(data)
+	resp := map[string]interface{}{
 		"object": "list",
-		"data": []map[string]interface{}{
-			{
-				"object":    "embedding",
-				"embedding": embedding,
-				"index":     0,
-			},
-		},
-		"model": req.Model,
+		"data":   data,
+		"model":  req.Model,
 		"usage": map[string]int{
-			"prompt_tokens": 8,
-			"total_tokens":  8,
+			"prompt_tokens": tokens,
+			"total_tokens":  tokens,
 		},
-	})
+	}
+	if len(failed) > 0 {
+		resp["errors"] = failed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(data) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	} else {
+		n.recordUsage(r, req.Model, tokens, 0)
+	}
+	json.NewEncoder(w).Encode(resp)
 }
 
-// handleMiners returns connected miners
+// handleMiners returns registered miners. With ?online=true, or a
+// ?max_age=<duration>, it only returns miners seen within max_age
+// (default defaultMinerMaxAge).
 func (n *AINode) handleMiners(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	online := false
+	if v := q.Get("online"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "invalid online: "+v, http.StatusBadRequest)
+			return
+		}
+		online = b
+	}
+	maxAge := defaultMinerMaxAge
+	if v := q.Get("max_age"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid max_age: "+v, http.StatusBadRequest)
+			return
+		}
+		maxAge = d
+		online = true
+	}
+
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 
+	now := n.clock.Now()
 	miners := make([]*MinerInfo, 0, len(n.miners))
 	for _, m := range n.miners {
+		if online && stale(m.LastSeen, now, maxAge) {
+			continue
+		}
 		miners = append(miners, m)
 	}
 
@@ -405,32 +1015,196 @@ func (n *AINode) handleMiners(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(miners)
 }
 
-// handleMinerRegister registers a new miner
+// handleMinerRegister registers a new miner or updates a known one; see
+// upsertMinerLocked. Updating a known miner requires its bearer token or a
+// signature from its registered key. A reported hardware capability must
+// support the max tier it claims.
 func (n *AINode) handleMinerRegister(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var miner MinerInfo
-	if err := json.NewDecoder(r.Body).Decode(&miner); err != nil {
+	var reg minerRegistration
+	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+	miner := reg.MinerInfo
+	if strings.TrimSpace(miner.ID) == "" {
+		http.Error(w, errMissingMinerID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(miner.PublicKey) != 0 && len(miner.PublicKey) != ed25519.PublicKeySize {
+		http.Error(w, fmt.Sprintf("public_key must be %d bytes", ed25519.PublicKeySize), http.StatusBadRequest)
+		return
+	}
+	if miner.Capability != nil {
+		if err := miner.Capability.ValidateClaim(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := validateModelingLevels(&miner); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	miner.LastSeen = time.Now()
+	now := n.clock.Now()
+	miner.LastSeen = now
+	miner.ActiveTasks = 0
+	miner.Region = strings.TrimSpace(miner.Region)
+	miner.CapacityTPS = min(max(miner.CapacityTPS, 0), float64(n.maxCapacityTPS()))
+
+	token, err := newMinerToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	n.mu.Lock()
-	n.miners[miner.ID] = &miner
+	if old, ok := n.miners[miner.ID]; ok {
+		if err := n.authorizeRegistrationLocked(r, old, &reg, now); err != nil {
+			n.mu.Unlock()
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+	created, changed := n.upsertMinerLocked(&miner)
+	n.tokens[miner.ID] = token
 	n.mu.Unlock()
 
+	status := "registered"
+	if !created {
+		status = "updated"
+	}
+	if changed == nil {
+		changed = []string{}
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "registered",
-		"id":     miner.ID,
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  status,
+		"id":      miner.ID,
+		"token":   token,
+		"created": created,
+		"changed": changed,
 	})
 }
 
+// handleMinerModels replaces the model list a registered miner advertises,
+// sent when models are added to or removed from the miner's model directory.
+// From then on the miner is only assigned tasks for the models it lists.
+func (n *AINode) handleMinerModels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID     string   `json:"id"`
+		Models []string `json:"models"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	n.mu.Lock()
+	miner, ok := n.miners[req.ID]
+	if !ok {
+		n.mu.Unlock()
+		http.Error(w, "miner not registered", http.StatusNotFound)
+		return
+	}
+	if !validBearer(r, n.tokens[req.ID]) {
+		n.mu.Unlock()
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	// An explicit list, even an empty one, replaces "serves any model"
+	miner.Models = req.Models
+	if miner.Models == nil {
+		miner.Models = []string{}
+	}
+	miner.LastSeen = n.clock.Now()
+	n.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "ok",
+		"models": len(req.Models),
+	})
+}
+
+// handleMinerDeregister removes a miner that is shutting down and returns
+// its unfinished tasks to the pending queue. The request must carry the
+// bearer token issued at registration.
+func (n *AINode) handleMinerDeregister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	n.mu.Lock()
+	token, ok := n.tokens[req.ID]
+	if !ok {
+		n.mu.Unlock()
+		http.Error(w, "miner not registered", http.StatusNotFound)
+		return
+	}
+	if !validBearer(r, token) {
+		n.mu.Unlock()
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	delete(n.miners, req.ID)
+	delete(n.tokens, req.ID)
+
+	reassigned := 0
+	for _, t := range n.tasks {
+		if t.AssignedTo == req.ID && !t.finished() {
+			t.AssignedTo = ""
+			t.Status = TaskPending
+			n.resetChunksLocked(t)
+			n.recordLocked(t, EventQueued, "miner deregistered")
+			reassigned++
+		}
+	}
+	n.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":           "deregistered",
+		"id":               req.ID,
+		"tasks_reassigned": reassigned,
+	})
+}
+
+// newMinerToken returns a random bearer token for a registered miner
+func newMinerToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validBearer reports whether the request's bearer token matches want
+func validBearer(r *http.Request, want string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
 // handleTasks returns all tasks
 func (n *AINode) handleTasks(w http.ResponseWriter, r *http.Request) {
 	n.mu.RLock()
@@ -445,14 +1219,31 @@ func (n *AINode) handleTasks(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(tasks)
 }
 
-// handlePendingTasks returns pending tasks for miners
+// handlePendingTasks returns pending tasks for miners. With ?miner=<id> it
+// hands that miner the tasks assigned to it and marks them running; the
+// request must carry the bearer token issued at the miner's registration.
 func (n *AINode) handlePendingTasks(w http.ResponseWriter, r *http.Request) {
+	if minerID := r.URL.Query().Get("miner"); minerID != "" {
+		n.mu.Lock()
+		if token := n.tokens[minerID]; token == "" || !validBearer(r, token) {
+			n.mu.Unlock()
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		claimed := n.claimTasksLocked(minerID)
+		n.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claimed)
+		return
+	}
+
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 
 	pending := make([]*Task, 0)
 	for _, t := range n.tasks {
-		if t.Status == "pending" {
+		if t.Status == TaskPending {
 			pending = append(pending, t)
 		}
 	}
@@ -461,7 +1252,9 @@ func (n *AINode) handlePendingTasks(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(pending)
 }
 
-// handleSubmitResult handles task result submission
+// handleSubmitResult handles task result submission. Only the miner the
+// task is assigned to may submit, with the bearer token issued at its
+// registration, and it reports either TaskCompleted or TaskFailed.
 func (n *AINode) handleSubmitResult(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -470,58 +1263,85 @@ func (n *AINode) handleSubmitResult(w http.ResponseWriter, r *http.Request) {
 
 	var task Task
 	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+	if task.Status != TaskCompleted && task.Status != TaskFailed {
+		http.Error(w, errInvalidResultStatus.Error(), http.StatusBadRequest)
 		return
 	}
 
 	n.mu.Lock()
-	if existing, ok := n.tasks[task.ID]; ok {
-		existing.Output = task.Output
-		existing.Status = task.Status
+	existing, ok := n.tasks[task.ID]
+	if !ok {
+		n.mu.Unlock()
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
 	}
-	n.mu.Unlock()
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
-}
-
-// handleStats returns node statistics
-func (n *AINode) handleStats(w http.ResponseWriter, r *http.Request) {
-	n.mu.RLock()
-	defer n.mu.RUnlock()
-
-	var pending, completed, failed int
-	for _, t := range n.tasks {
-		switch t.Status {
-		case "pending":
-			pending++
-		case "completed":
-			completed++
-		case "failed":
-			failed++
+	if token := n.tokens[existing.AssignedTo]; existing.AssignedTo == "" || token == "" || !validBearer(r, token) {
+		n.mu.Unlock()
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case existing.Status == TaskCancelled:
+		// Tell the miner to stop working on it
+		n.mu.Unlock()
+		http.Error(w, errTaskCancelled.Error(), http.StatusConflict)
+		return
+	case existing.finished():
+		// Late result for a finished task; keep the recorded outcome
+	case task.Status == TaskCompleted && existing.NextSeq < task.Chunks:
+		have := existing.NextSeq
+		n.mu.Unlock()
+		http.Error(w, fmt.Sprintf("missing chunks: have %d of %d", have, task.Chunks), http.StatusConflict)
+		return
+	case task.Status == TaskCompleted:
+		output := task.Output
+		if len(output) == 0 && existing.NextSeq > 0 {
+			output = chunkedOutput(existing)
+		}
+		signed, err := n.verifyResultLocked(existing, output, &task)
+		if err != nil {
+			n.mu.Unlock()
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if signed {
+			existing.Signature = task.Signature
+			existing.SignedAt = task.SignedAt
+			existing.SignedBy = existing.AssignedTo
+		}
+		existing.Output = output
+		existing.Status = TaskCompleted
+		existing.FinishedAt = n.clock.Now()
+		n.recordLocked(existing, EventCompleted, "")
+		n.finishTaskLocked(existing)
+	case task.Status == TaskFailed:
+		reason := task.Error
+		if reason == "" {
+			reason = "miner reported failure"
 		}
+		n.failTaskLocked(existing, reason)
 	}
+	n.mu.Unlock()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"miners_connected": len(n.miners),
-		"models_available": len(n.models),
-		"tasks_pending":    pending,
-		"tasks_completed":  completed,
-		"tasks_failed":     failed,
-	})
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
 // handleHealth returns health status
 func (n *AINode) handleHealth(w http.ResponseWriter, r *http.Request) {
 	n.mu.RLock()
 	running := n.running
+	maintenance := n.maintenance.Enabled
 	n.mu.RUnlock()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status":  "healthy",
-		"running": running,
-		"version": version,
+		"status":      "healthy",
+		"running":     running,
+		"maintenance": maintenance,
+		"version":     version,
 	})
 }
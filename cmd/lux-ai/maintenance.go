@@ -0,0 +1,131 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultMaintenanceRetryAfter is the Retry-After sent during maintenance
+// when the operator gives none
+const defaultMaintenanceRetryAfter = 60 * time.Second
+
+// maintenanceState is the node's maintenance mode, guarded by AINode.mu.
+// While enabled the /v1 API refuses new work; miners, stats, health and
+// persistence keep running so in-flight tasks finish.
+type maintenanceState struct {
+	Enabled    bool
+	Since      time.Time
+	RetryAfter time.Duration
+}
+
+// maintenanceMiddleware answers /v1 requests with 503 and Retry-After
+// while the node is in maintenance
+func (n *AINode) maintenanceMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n.mu.RLock()
+		m := n.maintenance
+		n.mu.RUnlock()
+		if m.Enabled {
+			w.Header().Set("Retry-After", strconv.Itoa(int(m.RetryAfter.Seconds())))
+			http.Error(w, "node is in maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// setMaintenance turns maintenance mode on or off. A zero retryAfter
+// means defaultMaintenanceRetryAfter.
+func (n *AINode) setMaintenance(enabled bool, retryAfter time.Duration) maintenanceState {
+	if retryAfter <= 0 {
+		retryAfter = defaultMaintenanceRetryAfter
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	switch {
+	case !enabled:
+		n.maintenance = maintenanceState{}
+	case !n.maintenance.Enabled:
+		n.maintenance = maintenanceState{Enabled: true, Since: n.clock.Now()}
+	}
+	if enabled {
+		n.maintenance.RetryAfter = retryAfter
+	}
+	return n.maintenance
+}
+
+// maintenanceResponse is the body of /api/admin/maintenance
+type maintenanceResponse struct {
+	Enabled     bool       `json:"enabled"`
+	Since       *time.Time `json:"since,omitempty"`
+	RetryAfter  int        `json:"retry_after,omitempty"` // Seconds
+	ActiveTasks int        `json:"active_tasks"`          // Tasks still assigned to miners
+}
+
+// handleMaintenance reports maintenance mode on GET and changes it on POST
+// with {"enabled": bool, "retry_after": seconds}. Both require the admin
+// token.
+func (n *AINode) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if n.config.AdminToken == "" || !validBearer(r, n.config.AdminToken) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var state maintenanceState
+	if r.Method == "POST" {
+		var req struct {
+			Enabled    *bool `json:"enabled"`
+			RetryAfter int   `json:"retry_after"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", bodyErrorStatus(err))
+			return
+		}
+		if req.Enabled == nil {
+			http.Error(w, "enabled is required", http.StatusBadRequest)
+			return
+		}
+		if req.RetryAfter < 0 {
+			http.Error(w, "retry_after must be non-negative", http.StatusBadRequest)
+			return
+		}
+		state = n.setMaintenance(*req.Enabled, time.Duration(req.RetryAfter)*time.Second)
+	} else {
+		n.mu.RLock()
+		state = n.maintenance
+		n.mu.RUnlock()
+	}
+
+	resp := maintenanceResponse{
+		Enabled:     state.Enabled,
+		RetryAfter:  int(state.RetryAfter.Seconds()),
+		ActiveTasks: n.activeTasks(),
+	}
+	if state.Enabled {
+		resp.Since = &state.Since
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// activeTasks returns the number of tasks assigned to miners and not yet
+// finished
+func (n *AINode) activeTasks() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	active := 0
+	for _, m := range n.miners {
+		active += m.ActiveTasks
+	}
+	return active
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	n := NewAINode(Config{AdminToken: "admin"})
+	n.miners["m"] = &MinerInfo{ID: "m", ActiveTasks: 1}
+	mux := n.newMux()
+
+	serve := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, tt := range []struct {
+		name, token, body string
+		want              int
+	}{
+		{"no token", "", `{"enabled":true}`, http.StatusUnauthorized},
+		{"wrong token", "nope", `{"enabled":true}`, http.StatusUnauthorized},
+		{"missing enabled", "admin", `{}`, http.StatusBadRequest},
+		{"negative retry", "admin", `{"enabled":true,"retry_after":-1}`, http.StatusBadRequest},
+	} {
+		if rec := serve("POST", "/api/admin/maintenance", tt.token, tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+	if rec := serve("GET", "/v1/models", "", ""); rec.Code != http.StatusOK {
+		t.Fatalf("/v1/models before maintenance = %d, want 200", rec.Code)
+	}
+
+	rec := serve("POST", "/api/admin/maintenance", "admin", `{"enabled":true,"retry_after":120}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enable status = %d, want 200", rec.Code)
+	}
+	var resp maintenanceResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if !resp.Enabled || resp.Since == nil || resp.RetryAfter != 120 || resp.ActiveTasks != 1 {
+		t.Errorf("response = %+v, want enabled with retry 120 and 1 active task", resp)
+	}
+
+	for _, path := range []string{"/v1/models", "/v1/chat/completions", "/v1/embeddings", "/v1/completions"} {
+		rec := serve("POST", path, "", `{}`)
+		if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "120" {
+			t.Errorf("%s = %d, Retry-After %q, want 503 with 120", path, rec.Code, rec.Header().Get("Retry-After"))
+		}
+	}
+	// Miners, stats and health keep working so in-flight tasks can finish
+	for _, path := range []string{"/health", "/api/stats", "/api/tasks/pending?miner_id=m"} {
+		if rec := serve("GET", path, "", ""); rec.Code == http.StatusServiceUnavailable {
+			t.Errorf("%s = 503 during maintenance", path)
+		}
+	}
+	var health map[string]interface{}
+	json.NewDecoder(serve("GET", "/health", "", "").Body).Decode(&health)
+	if health["maintenance"] != true {
+		t.Errorf("health maintenance = %v, want true", health["maintenance"])
+	}
+
+	if rec := serve("POST", "/api/admin/maintenance", "admin", `{"enabled":false}`); rec.Code != http.StatusOK {
+		t.Fatalf("disable status = %d, want 200", rec.Code)
+	}
+	if rec := serve("GET", "/v1/models", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("/v1/models after maintenance = %d, want 200", rec.Code)
+	}
+}
+
+func TestMaintenanceAtStartup(t *testing.T) {
+	n := NewAINode(Config{Maintenance: true})
+	rec := httptest.NewRecorder()
+	n.newMux().ServeHTTP(rec, httptest.NewRequest("GET", "/v1/models", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("status = %d, Retry-After %q, want 503 with 60", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/internal/minersig"
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+func TestMinersOnlineFilter(t *testing.T) {
+	n := NewAINode(Config{})
+	now := time.Now()
+	n.miners["live"] = &MinerInfo{ID: "live", LastSeen: now.Add(-time.Minute)}
+	n.miners["idle"] = &MinerInfo{ID: "idle", LastSeen: now.Add(-10 * time.Minute)}
+	n.miners["gone"] = &MinerInfo{ID: "gone", LastSeen: now.Add(-2 * time.Hour)}
+
+	tests := []struct {
+		query  string
+		status int
+		want   []string
+	}{
+		{"", http.StatusOK, []string{"gone", "idle", "live"}},
+		{"?online=false", http.StatusOK, []string{"gone", "idle", "live"}},
+		{"?online=true", http.StatusOK, []string{"live"}},
+		{"?online=true&max_age=30m", http.StatusOK, []string{"idle", "live"}},
+		{"?max_age=3h", http.StatusOK, []string{"gone", "idle", "live"}},
+		{"?max_age=30s", http.StatusOK, []string{}},
+		{"?online=yes", http.StatusBadRequest, nil},
+		{"?max_age=soon", http.StatusBadRequest, nil},
+		{"?max_age=-5m", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			n.handleMiners(rec, httptest.NewRequest("GET", "/api/miners"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var miners []MinerInfo
+			if err := json.NewDecoder(rec.Body).Decode(&miners); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			got := make([]string, 0, len(miners))
+			for _, m := range miners {
+				got = append(got, m.ID)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("miners = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("miners = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterValidatesCapability(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"no capability", `{"id":"m"}`, http.StatusOK},
+		{"consistent", `{"id":"m","capability":{"gpu_vendor":"NVIDIA","gpu_model":"H100","gpu_cc_supported":true,"gpu_cc_enabled":true,"gpu_cc_mode":"On","nvtrust_available":true,"max_tier":1}}`, http.StatusOK},
+		{"over-claimed", `{"id":"m","capability":{"gpu_vendor":"NVIDIA","gpu_model":"RTX 4090","gpu_cc_supported":true,"gpu_cc_enabled":true,"nvtrust_available":true,"max_tier":1}}`, http.StatusBadRequest},
+		{"missing tier", `{"id":"m","capability":{"gpu_model":"RTX 4090"}}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{})
+			rec := httptest.NewRecorder()
+			n.handleMinerRegister(rec, httptest.NewRequest("POST", "/api/miners/register", strings.NewReader(tt.body)))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if _, registered := n.miners["m"]; registered != (tt.status == http.StatusOK) {
+				t.Errorf("registered = %v, want %v", registered, tt.status == http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestReRegisterPreservesStats(t *testing.T) {
+	const h100 = `"capability":{"gpu_vendor":"NVIDIA","gpu_model":"H100","gpu_cc_supported":true,"gpu_cc_enabled":true,"gpu_cc_mode":"On","nvtrust_available":true,"max_tier":1}`
+	n := NewAINode(Config{})
+	var token string
+	register := func(body string) map[string]interface{} {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/api/miners/register", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		n.handleMinerRegister(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+		}
+		var resp map[string]interface{}
+		json.NewDecoder(rec.Body).Decode(&resp)
+		token, _ = resp["token"].(string)
+		return resp
+	}
+
+	if resp := register(`{"id":"m","endpoint":"http://a:8888","gpu_enabled":true,` + h100 + `}`); resp["status"] != "registered" || resp["created"] != true {
+		t.Fatalf("first registration = %v, want created", resp)
+	}
+	attested := time.Now().Add(time.Hour)
+	m := n.miners["m"]
+	m.TasksHandled = 7
+	m.ActiveTasks = 2
+	m.Tier = cc.Tier1GPUNativeCC
+	m.TrustScore = 90
+	m.AttestedUntil = attested
+
+	resp := register(`{"id":"m","endpoint":"http://b:8888","gpu_enabled":true,"tasks_handled":0,"trust_score":0,` + h100 + `}`)
+	if resp["status"] != "updated" || resp["created"] != false {
+		t.Errorf("re-registration = %v, want updated", resp)
+	}
+	if changed := fmt.Sprint(resp["changed"]); changed != "[endpoint]" {
+		t.Errorf("changed = %s, want [endpoint]", changed)
+	}
+
+	m = n.miners["m"]
+	if m.TasksHandled != 7 || m.ActiveTasks != 2 || m.TrustScore != 90 || m.Tier != cc.Tier1GPUNativeCC || !m.AttestedUntil.Equal(attested) {
+		t.Errorf("miner = %+v, want accumulated stats kept", m)
+	}
+	if m.Endpoint != "http://b:8888" {
+		t.Errorf("Endpoint = %q, want the re-registered endpoint", m.Endpoint)
+	}
+
+	if resp := register(`{"id":"m","endpoint":"http://b:8888","gpu_enabled":true,` + h100 + `}`); fmt.Sprint(resp["changed"]) != "[]" {
+		t.Errorf("identical re-registration changed = %v, want none", resp["changed"])
+	}
+
+	// Hardware that can no longer back the recorded tier drops the
+	// attestation, and so does omitting the capability, which holds the
+	// miner to Tier4
+	for name, capability := range map[string]string{
+		"downgraded capability": `,"capability":{"gpu_model":"RTX 4090","max_tier":4}`,
+		"omitted capability":    "",
+	} {
+		register(`{"id":"m","endpoint":"http://b:8888",` + h100 + `}`)
+		m.Tier, m.TrustScore, m.AttestedUntil = cc.Tier1GPUNativeCC, 90, attested
+		register(`{"id":"m","endpoint":"http://b:8888"` + capability + `}`)
+		if m := n.miners["m"]; m.Tier != 0 || m.TrustScore != 0 || m.TasksHandled != 7 {
+			t.Errorf("%s: tier = %d, trust = %d, tasks = %d; want 0, 0, 7", name, m.Tier, m.TrustScore, m.TasksHandled)
+		}
+	}
+}
+
+func TestRegisterIgnoresNodeOwnedFields(t *testing.T) {
+	n := NewAINode(Config{})
+	n.models["gated"] = &ModelInfo{ID: "gated", MinTrustScore: 50}
+	body := `{"id":"m","gpu_enabled":true,"trust_score":100,"tier":1,"attested_until":"2099-01-01T00:00:00Z","tasks_handled":500,"active_tasks":-3}`
+	rec := httptest.NewRecorder()
+	n.handleMinerRegister(rec, httptest.NewRequest("POST", "/api/miners/register", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	m := n.miners["m"]
+	if m.TrustScore != 0 || m.Tier != 0 || !m.AttestedUntil.IsZero() || m.TasksHandled != 0 || m.ActiveTasks != 0 {
+		t.Errorf("miner = trust %d, tier %d, attested until %v, tasks %d/%d; want all zero",
+			m.TrustScore, m.Tier, m.AttestedUntil, m.TasksHandled, m.ActiveTasks)
+	}
+	if _, err := n.qualifiedLocked([]*MinerInfo{m}, "gated"); !errors.Is(err, errInsufficientTrust) {
+		t.Errorf("qualifiedLocked() = %v, want %v", err, errInsufficientTrust)
+	}
+}
+
+func TestRegisterCapacityBounded(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		reported float64
+		want     float64
+	}{
+		{"within default", Config{}, 150, 150},
+		{"over default", Config{}, 1e9, defaultMaxCapacityTPS},
+		{"negative", Config{}, -5, 0},
+		{"over configured", Config{MaxCapacityTPS: 100}, 150, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(tt.config)
+			body := fmt.Sprintf(`{"id":"m","capacity_tps":%g}`, tt.reported)
+			rec := httptest.NewRecorder()
+			n.handleMinerRegister(rec, httptest.NewRequest("POST", "/api/miners/register", strings.NewReader(body)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+			if got := n.miners["m"].CapacityTPS; got != tt.want {
+				t.Errorf("CapacityTPS = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReRegisterRequiresAuth(t *testing.T) {
+	n := NewAINode(Config{})
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	register := func(reg map[string]interface{}, bearer string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(reg)
+		req := httptest.NewRequest("POST", "/api/miners/register", bytes.NewReader(body))
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		rec := httptest.NewRecorder()
+		n.handleMinerRegister(rec, req)
+		return rec
+	}
+	signed := func(endpoint string, at time.Time) map[string]interface{} {
+		digest := minersig.RegistrationDigest("m", at, pub)
+		return map[string]interface{}{
+			"id": "m", "endpoint": endpoint, "public_key": pub,
+			"timestamp": at, "signature": ed25519.Sign(priv, digest[:]),
+		}
+	}
+
+	for _, id := range []string{"", "  "} {
+		if rec := register(map[string]interface{}{"id": id}, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("id %q: status = %d, want 400", id, rec.Code)
+		}
+	}
+
+	rec := register(map[string]interface{}{"id": "m", "endpoint": "http://a:8888", "public_key": pub}, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first registration status = %d: %s", rec.Code, rec.Body)
+	}
+	token := n.tokens["m"]
+
+	hijack := map[string]interface{}{"id": "m", "endpoint": "http://evil:8888"}
+	for _, bearer := range []string{"", "wrong"} {
+		if rec := register(hijack, bearer); rec.Code != http.StatusUnauthorized {
+			t.Errorf("bearer %q: status = %d, want 401", bearer, rec.Code)
+		}
+	}
+	if n.miners["m"].Endpoint != "http://a:8888" || n.tokens["m"] != token {
+		t.Fatal("unauthenticated re-registration changed the miner")
+	}
+
+	if rec := register(map[string]interface{}{"id": "m", "endpoint": "http://b:8888", "public_key": pub}, token); rec.Code != http.StatusOK {
+		t.Errorf("re-registration with token: status = %d, want 200", rec.Code)
+	}
+	if n.tokens["m"] == token {
+		t.Error("re-registration did not issue a new token")
+	}
+
+	// A miner that lost its token re-registers with a signature instead
+	now := n.clock.Now()
+	reg := signed("http://c:8888", now)
+	if rec := register(reg, ""); rec.Code != http.StatusOK {
+		t.Errorf("signed re-registration: status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if rec := register(reg, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("replayed registration: status = %d, want 401", rec.Code)
+	}
+	if rec := register(signed("http://d:8888", now.Add(-time.Hour)), ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("stale registration: status = %d, want 401", rec.Code)
+	}
+	if n.miners["m"].Endpoint != "http://c:8888" {
+		t.Errorf("Endpoint = %q, want the signed registration's", n.miners["m"].Endpoint)
+	}
+}
+
+func TestReRegisterBindsPublicKey(t *testing.T) {
+	n := NewAINode(Config{})
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	strangerPub, strangerPriv, _ := ed25519.GenerateKey(nil)
+	newPub, newPriv, _ := ed25519.GenerateKey(nil)
+	register := func(key ed25519.PublicKey, signer ed25519.PrivateKey, at time.Time, bearer string) int {
+		reg := map[string]interface{}{"id": "m", "public_key": key}
+		if signer != nil {
+			digest := minersig.RegistrationDigest("m", at, key)
+			reg["timestamp"] = at
+			reg["signature"] = ed25519.Sign(signer, digest[:])
+		}
+		body, _ := json.Marshal(reg)
+		req := httptest.NewRequest("POST", "/api/miners/register", bytes.NewReader(body))
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		rec := httptest.NewRecorder()
+		n.handleMinerRegister(rec, req)
+		return rec.Code
+	}
+
+	now := n.clock.Now()
+	if code := register(pub, nil, now, ""); code != http.StatusOK {
+		t.Fatalf("first registration status = %d", code)
+	}
+	token := n.tokens["m"]
+
+	tests := []struct {
+		name   string
+		key    ed25519.PublicKey
+		signer ed25519.PrivateKey
+		bearer string
+	}{
+		{"stranger signs with own key", strangerPub, strangerPriv, ""},
+		{"token without signature", newPub, nil, token},
+		{"token with new key's signature", newPub, newPriv, token},
+		{"token dropping the key", nil, nil, token},
+	}
+	for i, tt := range tests {
+		if code := register(tt.key, tt.signer, now.Add(time.Duration(i+1)*time.Second), tt.bearer); code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", tt.name, code)
+		}
+		if !bytes.Equal(n.miners["m"].PublicKey, pub) {
+			t.Fatalf("%s: registered key replaced", tt.name)
+		}
+	}
+
+	// Rotation signed by the current key is accepted, after which the old
+	// key no longer speaks for the miner
+	if code := register(newPub, priv, now.Add(10*time.Second), ""); code != http.StatusOK {
+		t.Fatalf("rotation signed by the old key: status = %d, want 200", code)
+	}
+	if !bytes.Equal(n.miners["m"].PublicKey, newPub) {
+		t.Error("rotation did not replace the registered key")
+	}
+	if code := register(pub, priv, now.Add(20*time.Second), ""); code != http.StatusUnauthorized {
+		t.Errorf("registration signed by the retired key: status = %d, want 401", code)
+	}
+}
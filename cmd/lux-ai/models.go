@@ -0,0 +1,111 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DowngradedFromHeader names the originally requested model when
+// Config.AutoDowngrade routed the request to a smaller one
+const DowngradedFromHeader = "X-Lux-Downgraded-From"
+
+const (
+	// downgradeHeadroom is the fraction of a model's context window a
+	// request may fill and still be considered a comfortable fit
+	downgradeHeadroom = 0.75
+
+	// defaultCompletionTokens is assumed when a request omits max_tokens
+	defaultCompletionTokens = 512
+)
+
+// estimateTokens approximates the token count of text at about four
+// characters per token
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// estimatePromptTokens approximates a chat request's prompt size, adding a
+// few tokens of framing per message
+func estimatePromptTokens(req *ChatRequest) int {
+	tokens := 0
+	for _, m := range req.Messages {
+		tokens += estimateTokens(m.Content) + 4
+	}
+	return tokens
+}
+
+// downgradeModel returns the smallest model in requested's family that
+// advertises every capability requested does and comfortably holds the
+// request's prompt plus completion. It returns nil when nothing smaller
+// fits.
+func (n *AINode) downgradeModel(requested *ModelInfo, req *ChatRequest) *ModelInfo {
+	completion := req.MaxTokens
+	if completion <= 0 {
+		completion = defaultCompletionTokens
+	}
+	need := float64(estimatePromptTokens(req) + completion)
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	var best *ModelInfo
+	for _, m := range n.models {
+		if m.Family == "" || m.Family != requested.Family || m.ParamsB >= requested.ParamsB {
+			continue
+		}
+		if need > downgradeHeadroom*float64(m.ContextSize) || !hasCapabilities(m, requested.Capabilities) {
+			continue
+		}
+		if best == nil || m.ParamsB < best.ParamsB || (m.ParamsB == best.ParamsB && m.ID < best.ID) {
+			best = m
+		}
+	}
+	return best
+}
+
+// hasCapabilities reports whether m advertises every capability in caps
+func hasCapabilities(m *ModelInfo, caps []string) bool {
+	for _, c := range caps {
+		found := false
+		for _, mc := range m.Capabilities {
+			if mc == c {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// parseModelMinTrust parses per-model minimum trust scores of the form
+// "qwen3-8b=70,zen-coder-1.5b=50" for Config.ModelMinTrust
+func parseModelMinTrust(s string) (map[string]uint8, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	models := defaultModels()
+	scores := make(map[string]uint8)
+	for _, entry := range strings.Split(s, ",") {
+		id, scoreStr, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("min trust %q: want <model>=<score>", entry)
+		}
+		id = strings.TrimSpace(id)
+		if _, ok := models[id]; !ok {
+			return nil, fmt.Errorf("min trust %q: unknown model %s", entry, id)
+		}
+		score, err := strconv.ParseUint(strings.TrimSpace(scoreStr), 10, 8)
+		if err != nil || score > 100 {
+			return nil, fmt.Errorf("min trust %q: score must be 0-100", entry)
+		}
+		scores[id] = uint8(score)
+	}
+	return scores, nil
+}
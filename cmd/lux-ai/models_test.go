@@ -0,0 +1,107 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// downgradeNode returns a node serving one family of chat models, from a
+// large one with tools down to small ones with fewer capabilities
+func downgradeNode() *AINode {
+	n := NewAINode(Config{AutoDowngrade: true})
+	n.models = map[string]*ModelInfo{
+		"big":   {ID: "big", Type: "chat", Family: "f", ParamsB: 70, ContextSize: 131072, Capabilities: []string{"chat", "tools"}},
+		"mid":   {ID: "mid", Type: "chat", Family: "f", ParamsB: 8, ContextSize: 32768, Capabilities: []string{"chat", "tools", "code"}},
+		"small": {ID: "small", Type: "chat", Family: "f", ParamsB: 1, ContextSize: 4096, Capabilities: []string{"chat", "tools"}},
+		"tiny":  {ID: "tiny", Type: "chat", Family: "f", ParamsB: 0.5, ContextSize: 4096, Capabilities: []string{"chat"}},
+		"other": {ID: "other", Type: "chat", Family: "g", ParamsB: 0.1, ContextSize: 131072, Capabilities: []string{"chat", "tools"}},
+		"loner": {ID: "loner", Type: "chat", ParamsB: 70, ContextSize: 131072, Capabilities: []string{"chat"}},
+		"plain": {ID: "plain", Type: "chat", ParamsB: 0.1, ContextSize: 131072, Capabilities: []string{"chat"}},
+	}
+	return n
+}
+
+func TestDowngradeModel(t *testing.T) {
+	n := downgradeNode()
+	prompt := func(chars, maxTokens int) *ChatRequest {
+		return &ChatRequest{Messages: []ChatMessage{{Role: "user", Content: strings.Repeat("x", chars)}}, MaxTokens: maxTokens}
+	}
+
+	tests := []struct {
+		name      string
+		requested string
+		req       *ChatRequest
+		want      string // "" for no downgrade
+	}{
+		{"smallest superset", "big", prompt(100, 0), "small"},
+		{"missing capability skipped", "mid", prompt(100, 0), ""},
+		{"prompt too big for small", "big", prompt(16000, 0), "mid"},
+		{"completion counts", "big", prompt(100, 3100), "mid"},
+		{"nothing fits", "big", prompt(400000, 0), ""},
+		{"smaller lacks a capability", "small", prompt(100, 0), ""},
+		{"already smallest", "tiny", prompt(100, 0), ""},
+		{"no family", "loner", prompt(100, 0), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := n.downgradeModel(n.models[tt.requested], tt.req)
+			if gotID := modelID(got); gotID != tt.want {
+				t.Errorf("downgradeModel(%s) = %q, want %q", tt.requested, gotID, tt.want)
+			}
+			if got != nil && !hasCapabilities(got, n.models[tt.requested].Capabilities) {
+				t.Errorf("downgradeModel(%s) = %s, missing capabilities of %v", tt.requested, got.ID, n.models[tt.requested].Capabilities)
+			}
+		})
+	}
+}
+
+func TestAutoDowngrade(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		enabled   bool
+		want      string
+	}{
+		{"downgraded", "big", true, "small"},
+		{"disabled", "big", false, "big"},
+		{"no smaller superset", "mid", true, "mid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := downgradeNode()
+			n.config.AutoDowngrade = tt.enabled
+			rec := chatRequest(t, n, fmt.Sprintf(`{"model":%q,"messages":[{"role":"user","content":"hi"}]}`, tt.requested))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+			var resp ChatResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Model != tt.want {
+				t.Errorf("model = %s, want %s", resp.Model, tt.want)
+			}
+			wantHeader := ""
+			if tt.want != tt.requested {
+				wantHeader = tt.requested
+			}
+			if got := rec.Header().Get(DowngradedFromHeader); got != wantHeader {
+				t.Errorf("%s = %q, want %q", DowngradedFromHeader, got, wantHeader)
+			}
+		})
+	}
+}
+
+// modelID returns m's ID, or "" for nil
+func modelID(m *ModelInfo) string {
+	if m == nil {
+		return ""
+	}
+	return m.ID
+}
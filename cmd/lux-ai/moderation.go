@@ -0,0 +1,220 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ModerationHeader is set to "passthrough" when no moderation model could
+// classify the input and every result is reported as not flagged
+const ModerationHeader = "X-Lux-Moderation"
+
+// moderationCapability marks models that can classify content
+const moderationCapability = "moderation"
+
+// moderationThreshold is the category score at or above which input is
+// flagged
+const moderationThreshold = 0.5
+
+// moderationCategories are the OpenAI moderation categories, in the order
+// the classifier is asked to score them
+var moderationCategories = []string{
+	"harassment",
+	"harassment/threatening",
+	"hate",
+	"hate/threatening",
+	"self-harm",
+	"self-harm/instructions",
+	"self-harm/intent",
+	"sexual",
+	"sexual/minors",
+	"violence",
+	"violence/graphic",
+}
+
+// moderationPrompt asks a chat model to score input against each category
+var moderationPrompt = "Classify the user's message for content moderation. " +
+	"Reply with only a JSON object mapping each of these categories to a score from 0 to 1: " +
+	strings.Join(moderationCategories, ", ") + "."
+
+// ModerationInput is the OpenAI moderation input. It accepts a single
+// string or an array of strings.
+type ModerationInput []string
+
+// UnmarshalJSON accepts "input": "x" and "input": ["x", "y"]
+func (in *ModerationInput) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*in = ModerationInput{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("input must be a string or an array of strings")
+	}
+	*in = list
+	return nil
+}
+
+// ModerationRequest represents a moderation API request
+type ModerationRequest struct {
+	Input ModerationInput `json:"input"`
+	Model string          `json:"model,omitempty"`
+}
+
+// ModerationResult is the classification of one input
+type ModerationResult struct {
+	Flagged        bool               `json:"flagged"`
+	Categories     map[string]bool    `json:"categories"`
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
+
+// ModerationResponse represents a moderation API response
+type ModerationResponse struct {
+	ID      string             `json:"id"`
+	Model   string             `json:"model"`
+	Results []ModerationResult `json:"results"`
+}
+
+// handleModerations classifies input with a moderation-capable model. When
+// no such model is registered or no miner is connected, every input is
+// reported as not flagged and ModerationHeader marks the passthrough, so
+// clients that moderate before chatting keep working.
+func (n *AINode) handleModerations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+	if len(req.Input) == 0 {
+		http.Error(w, "input is required", http.StatusBadRequest)
+		return
+	}
+
+	resp := ModerationResponse{
+		ID:      fmt.Sprintf("modr-%d", time.Now().UnixNano()),
+		Model:   req.Model,
+		Results: make([]ModerationResult, len(req.Input)),
+	}
+
+	model := n.moderationModel(req.Model)
+	if model == nil {
+		w.Header().Set(ModerationHeader, "passthrough")
+		writeModerationResponse(w, resp, nil)
+		return
+	}
+	resp.Model = model.ID
+
+	scores := make([]map[string]float64, len(req.Input))
+	for i, text := range req.Input {
+		s, err := n.classify(r, model.ID, text)
+		switch {
+		case errors.Is(err, errNoMiners):
+			w.Header().Set(ModerationHeader, "passthrough")
+			writeModerationResponse(w, resp, nil)
+			return
+		case unqualified(err):
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		case errors.Is(err, context.DeadlineExceeded):
+			http.Error(w, "timeout waiting for miner", http.StatusGatewayTimeout)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		scores[i] = s
+	}
+	writeModerationResponse(w, resp, scores)
+}
+
+// moderationModel returns the requested model when it is moderation
+// capable, otherwise the first moderation-capable model by ID, or nil
+func (n *AINode) moderationModel(requested string) *ModelInfo {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	caps := []string{moderationCapability}
+	if m, ok := n.models[requested]; ok && hasCapabilities(m, caps) {
+		return m
+	}
+	var best *ModelInfo
+	for _, m := range n.models {
+		if hasCapabilities(m, caps) && (best == nil || m.ID < best.ID) {
+			best = m
+		}
+	}
+	return best
+}
+
+// classify asks model to score text and returns the score of each known
+// category, clamped to [0, 1]. Categories the model omits score 0.
+func (n *AINode) classify(r *http.Request, model, text string) (map[string]float64, error) {
+	input, err := json.Marshal(ChatRequest{
+		Model: model,
+		Messages: []ChatMessage{
+			{Role: "system", Content: moderationPrompt},
+			{Role: "user", Content: text},
+		},
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONObject},
+	})
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := n.generate(r, "chat", model, input, 1)
+	if err != nil {
+		return nil, err
+	}
+	c, err := parseChatOutput(outputs[0], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]float64
+	if err := json.Unmarshal([]byte(strings.TrimSpace(c.Content)), &raw); err != nil {
+		return nil, errInvalidJSONOutput
+	}
+	scores := make(map[string]float64, len(moderationCategories))
+	for _, category := range moderationCategories {
+		scores[category] = min(1, max(0, raw[category]))
+	}
+	return scores, nil
+}
+
+// writeModerationResponse fills in each result from its category scores;
+// a nil scores slice reports every input as not flagged
+func writeModerationResponse(w http.ResponseWriter, resp ModerationResponse, scores []map[string]float64) {
+	for i := range resp.Results {
+		result := ModerationResult{
+			Categories:     make(map[string]bool, len(moderationCategories)),
+			CategoryScores: make(map[string]float64, len(moderationCategories)),
+		}
+		for _, category := range moderationCategories {
+			var score float64
+			if scores != nil {
+				score = scores[i][category]
+			}
+			flagged := score >= moderationThreshold
+			result.Categories[category] = flagged
+			result.CategoryScores[category] = score
+			result.Flagged = result.Flagged || flagged
+		}
+		resp.Results[i] = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
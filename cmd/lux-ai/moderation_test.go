@@ -0,0 +1,107 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestModerations(t *testing.T) {
+	guard := &ModelInfo{ID: "guard-1b", Name: "Guard", Type: "chat", Capabilities: []string{"chat", moderationCapability}}
+
+	tests := []struct {
+		name            string
+		body            string
+		guard           bool
+		replies         []string // nil runs no miner
+		wantStatus      int
+		wantPassthrough bool
+		wantFlagged     []bool
+	}{
+		{"no moderation model", `{"input":"hello"}`, false, []string{"{}"}, http.StatusOK, true, []bool{false}},
+		{"no miners", `{"input":"hello"}`, true, nil, http.StatusOK, true, []bool{false}},
+		{"not flagged", `{"input":"hello"}`, true, []string{`{"hate":0.1}`}, http.StatusOK, false, []bool{false}},
+		{"flagged", `{"input":"hello"}`, true, []string{`{"violence":0.9}`}, http.StatusOK, false, []bool{true}},
+		{"array input", `{"input":["a","b"]}`, true, []string{`{"hate":0.7}`, `{}`}, http.StatusOK, false, []bool{true, false}},
+		{"invalid output", `{"input":"hello"}`, true, []string{"not json"}, http.StatusBadGateway, false, nil},
+		{"missing input", `{}`, true, nil, http.StatusBadRequest, false, nil},
+		{"bad input type", `{"input":42}`, true, nil, http.StatusBadRequest, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			n := NewAINode(Config{})
+			if tt.guard {
+				n.models[guard.ID] = guard
+			}
+			if tt.replies != nil {
+				runFakeMiner(ctx, n, tt.replies...)
+			}
+
+			req := httptest.NewRequest("POST", "/v1/moderations", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			n.handleModerations(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got := rec.Header().Get(ModerationHeader) == "passthrough"; got != tt.wantPassthrough {
+				t.Errorf("passthrough = %v, want %v", got, tt.wantPassthrough)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp ModerationResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Results) != len(tt.wantFlagged) {
+				t.Fatalf("results = %d, want %d", len(resp.Results), len(tt.wantFlagged))
+			}
+			for i, want := range tt.wantFlagged {
+				result := resp.Results[i]
+				if result.Flagged != want {
+					t.Errorf("results[%d].flagged = %v, want %v", i, result.Flagged, want)
+				}
+				if len(result.Categories) != len(moderationCategories) || len(result.CategoryScores) != len(moderationCategories) {
+					t.Errorf("results[%d] has %d categories, %d scores, want %d each",
+						i, len(result.Categories), len(result.CategoryScores), len(moderationCategories))
+				}
+			}
+		})
+	}
+}
+
+func TestModerationModel(t *testing.T) {
+	n := NewAINode(Config{})
+	if m := n.moderationModel(""); m != nil {
+		t.Errorf("moderationModel() = %s with no moderation models, want nil", m.ID)
+	}
+
+	n.models["guard-b"] = &ModelInfo{ID: "guard-b", Capabilities: []string{moderationCapability}}
+	n.models["guard-a"] = &ModelInfo{ID: "guard-a", Capabilities: []string{moderationCapability}}
+
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{"", "guard-a"},
+		{"guard-b", "guard-b"},
+		{"zen-mini-0.5b", "guard-a"}, // not moderation capable
+		{"unknown", "guard-a"},
+	}
+	for _, tt := range tests {
+		if got := n.moderationModel(tt.requested); got == nil || got.ID != tt.want {
+			t.Errorf("moderationModel(%q) = %v, want %s", tt.requested, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+// maxRateBuckets bounds the limiter's caller table. Past it, the least
+// recently used bucket is evicted; with this many callers it has almost
+// always refilled, so dropping it changes nothing.
+const maxRateBuckets = 10000
+
+// Rate limiting
+//
+// Each caller of the /v1 API gets a token bucket holding up to one minute
+// of requests and refilling continuously at its requests-per-minute rate.
+// The rate is resolved per request, in order of precedence:
+//
+//  1. An API key mapped to a CC tier in Config.KeyTiers uses that tier's
+//     entry in Config.TierRPM, or Config.RateLimitRPM for a tier without
+//     one, in a bucket of its own
+//  2. Any other request, with an unknown API key or none, uses
+//     Config.RateLimitRPM, bucketed per client IP
+//
+// Only keys in Config.KeyTiers get their own bucket, so a client cannot
+// escape its IP's limit by sending a fresh made-up key with each request.
+// A rate of 0 is unlimited, so with no configuration nothing is limited.
+
+// rateLimiter holds a token bucket per caller. The zero value is ready to
+// use and all methods are safe for concurrent use.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*list.Element // Caller -> element of lru
+	lru     list.List                // *rateBucket, most recently used first
+}
+
+type rateBucket struct {
+	caller string
+	rpm    int
+	tokens float64
+	last   time.Time
+}
+
+// allow takes a request from caller's bucket at rpm as of now and, if the
+// bucket is empty, returns false with the wait until the next request is
+// allowed
+func (l *rateLimiter) allow(caller string, rpm int, now time.Time) (bool, time.Duration) {
+	if rpm <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buckets == nil {
+		l.buckets = make(map[string]*list.Element)
+	}
+
+	var b *rateBucket
+	if e, ok := l.buckets[caller]; ok {
+		l.lru.MoveToFront(e)
+		b = e.Value.(*rateBucket)
+	}
+	if b == nil || b.rpm != rpm {
+		if b == nil {
+			for len(l.buckets) >= maxRateBuckets {
+				l.evictLocked()
+			}
+			b = &rateBucket{caller: caller}
+			l.buckets[caller] = l.lru.PushFront(b)
+		}
+		b.rpm, b.tokens, b.last = rpm, float64(rpm), now
+	}
+	b.refill(now)
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) * float64(time.Minute) / float64(rpm))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// refill adds the requests earned since the bucket was last used
+func (b *rateBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(float64(b.rpm), b.tokens+elapsed.Minutes()*float64(b.rpm))
+		b.last = now
+	}
+}
+
+// evictLocked drops the least recently used bucket. Caller holds l.mu.
+func (l *rateLimiter) evictLocked() {
+	if e := l.lru.Back(); e != nil {
+		l.lru.Remove(e)
+		delete(l.buckets, e.Value.(*rateBucket).caller)
+	}
+}
+
+// apiKey returns the bearer API key r was sent with, or "" if none
+func apiKey(r *http.Request) string {
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return key
+}
+
+// callerLimit returns the rate limit bucket and requests per minute for r
+func (n *AINode) callerLimit(r *http.Request) (string, int) {
+	key := apiKey(r)
+	if tier, ok := n.config.KeyTiers[key]; ok && key != "" {
+		if rpm, ok := n.config.TierRPM[tier]; ok {
+			return "key:" + key, rpm
+		}
+		return "key:" + key, n.config.RateLimitRPM
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host, n.config.RateLimitRPM
+}
+
+// rateLimitMiddleware answers 429 with Retry-After once the caller's
+// bucket is empty
+func (n *AINode) rateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, rpm := n.callerLimit(r)
+		if ok, wait := n.limiter.allow(caller, rpm, n.clock.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, fmt.Sprintf("rate limit of %d requests per minute exceeded", rpm), http.StatusTooManyRequests)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// validTier reports whether tier is a defined CC tier
+func validTier(tier cc.CCTier) bool {
+	return tier >= cc.Tier1GPUNativeCC && tier <= cc.Tier4Standard
+}
+
+// parseTierRPM parses per-tier rates of the form "1=600,2=300"
+func parseTierRPM(s string) (map[cc.CCTier]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	rates := make(map[cc.CCTier]int)
+	for _, entry := range strings.Split(s, ",") {
+		tierStr, rpmStr, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("tier rate %q: want <tier>=<rpm>", entry)
+		}
+		tier, err := strconv.ParseUint(strings.TrimSpace(tierStr), 10, 8)
+		if err != nil || !validTier(cc.CCTier(tier)) {
+			return nil, fmt.Errorf("tier rate %q: tier must be 1-4", entry)
+		}
+		rpm, err := strconv.Atoi(strings.TrimSpace(rpmStr))
+		if err != nil || rpm < 0 {
+			return nil, fmt.Errorf("tier rate %q: rpm must be a non-negative integer", entry)
+		}
+		rates[cc.CCTier(tier)] = rpm
+	}
+	return rates, nil
+}
+
+// loadKeyTiers reads a JSON object mapping API keys to CC tiers
+func loadKeyTiers(path string) (map[string]cc.CCTier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tiers map[string]cc.CCTier
+	if err := json.Unmarshal(data, &tiers); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for key, tier := range tiers {
+		if !validTier(tier) {
+			return nil, fmt.Errorf("%s: key %.4s...: tier must be 1-4, got %d", path, key, tier)
+		}
+	}
+	return tiers, nil
+}
@@ -0,0 +1,192 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+func TestCallerLimit(t *testing.T) {
+	n := NewAINode(Config{
+		RateLimitRPM: 10,
+		TierRPM:      map[cc.CCTier]int{cc.Tier1GPUNativeCC: 600, cc.Tier2ConfidentialVM: 0},
+		KeyTiers: map[string]cc.CCTier{
+			"gold":   cc.Tier1GPUNativeCC,
+			"silver": cc.Tier2ConfidentialVM,
+			"bronze": cc.Tier4Standard,
+		},
+	})
+
+	tests := []struct {
+		name       string
+		auth       string
+		wantCaller string
+		wantRPM    int
+	}{
+		{"tier rate", "Bearer gold", "key:gold", 600},
+		{"unlimited tier", "Bearer silver", "key:silver", 0},
+		{"tier without rate", "Bearer bronze", "key:bronze", 10},
+		{"unknown key", "Bearer other", "ip:192.0.2.1", 10},
+		{"anonymous", "", "ip:192.0.2.1", 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+			r.RemoteAddr = "192.0.2.1:4321"
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			caller, rpm := n.callerLimit(r)
+			if caller != tt.wantCaller || rpm != tt.wantRPM {
+				t.Errorf("callerLimit() = %s, %d, want %s, %d", caller, rpm, tt.wantCaller, tt.wantRPM)
+			}
+		})
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	now := time.Unix(0, 0)
+	var l rateLimiter
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("a", 2, now); !ok {
+			t.Fatalf("request %d rejected, want allowed", i)
+		}
+	}
+	ok, wait := l.allow("a", 2, now)
+	if ok || wait != 30*time.Second {
+		t.Fatalf("allow() = %v, %v, want false, 30s", ok, wait)
+	}
+	if ok, _ := l.allow("b", 2, now); !ok {
+		t.Error("other caller rejected, want its own bucket")
+	}
+
+	now = now.Add(30 * time.Second)
+	if ok, _ := l.allow("a", 2, now); !ok {
+		t.Error("request after refill rejected")
+	}
+	if ok, _ := l.allow("a", 0, now); !ok {
+		t.Error("unlimited request rejected")
+	}
+}
+
+func TestRateLimiterEvictsLeastRecentlyUsed(t *testing.T) {
+	now := time.Unix(0, 0)
+	var l rateLimiter
+
+	// "hot" drains its bucket and stays in use while the table fills
+	l.allow("hot", 1, now)
+	for i := range maxRateBuckets + 100 {
+		l.allow(fmt.Sprintf("c%d", i), 1, now)
+		if i%1000 == 0 {
+			l.allow("hot", 1, now)
+		}
+	}
+	if len(l.buckets) != maxRateBuckets || l.lru.Len() != maxRateBuckets {
+		t.Fatalf("%d buckets (%d in LRU), want %d", len(l.buckets), l.lru.Len(), maxRateBuckets)
+	}
+	if _, ok := l.buckets["c0"]; ok {
+		t.Error("least recently used bucket kept")
+	}
+	if ok, _ := l.allow("hot", 1, now); ok {
+		t.Error("drained bucket was evicted, resetting its limit")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	n := NewAINode(Config{
+		RateLimitRPM: 1,
+		TierRPM:      map[cc.CCTier]int{cc.Tier1GPUNativeCC: 3},
+		KeyTiers:     map[string]cc.CCTier{"gold": cc.Tier1GPUNativeCC},
+	})
+	handler := n.rateLimitMiddleware(n.handleModels)
+
+	do := func(key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		r.Header.Set("Authorization", "Bearer "+key)
+		rec := httptest.NewRecorder()
+		handler(rec, r)
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		if rec := do("gold"); rec.Code != http.StatusOK {
+			t.Fatalf("tier 1 request %d: status = %d, want 200", i, rec.Code)
+		}
+	}
+	rec := do("gold")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("tier 1 request 3: status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "20" {
+		t.Errorf("Retry-After = %q, want 20", got)
+	}
+
+	if rec := do("plain"); rec.Code != http.StatusOK {
+		t.Fatalf("default request: status = %d, want 200", rec.Code)
+	}
+	// Unknown keys share their IP's bucket, so minting new ones is no escape
+	if rec := do("made-up"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("request with another unknown key: status = %d, want 429", rec.Code)
+	}
+}
+
+func TestParseTierRPM(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[cc.CCTier]int
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"1=600, 2=300,4=0", map[cc.CCTier]int{1: 600, 2: 300, 4: 0}, false},
+		{"5=10", nil, true},
+		{"1=-1", nil, true},
+		{"1", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTierRPM(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTierRPM(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("parseTierRPM(%q) = %v, want %v", tt.in, got, tt.want)
+			continue
+		}
+		for tier, rpm := range tt.want {
+			if got[tier] != rpm {
+				t.Errorf("parseTierRPM(%q)[%d] = %d, want %d", tt.in, tier, got[tier], rpm)
+			}
+		}
+	}
+}
+
+func TestLoadKeyTiers(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(good, []byte(`{"key-a": 1, "key-b": 4}`), 0o600)
+	os.WriteFile(bad, []byte(`{"key-a": 7}`), 0o600)
+
+	tiers, err := loadKeyTiers(good)
+	if err != nil {
+		t.Fatalf("loadKeyTiers() error = %v", err)
+	}
+	if tiers["key-a"] != cc.Tier1GPUNativeCC || tiers["key-b"] != cc.Tier4Standard {
+		t.Errorf("loadKeyTiers() = %v", tiers)
+	}
+	if _, err := loadKeyTiers(bad); err == nil {
+		t.Error("loadKeyTiers() with tier 7: want error")
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecordingFile is the JSONL file in DataDir that recorded requests are appended to
+const RecordingFile = "recordings.jsonl"
+
+// ReplayHeader marks replayed requests so a recording node does not record
+// them again. Its value must be the node's admin token, so clients cannot
+// use it to avoid being recorded.
+const ReplayHeader = "X-Lux-Replay"
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are replaced with a placeholder before recording
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "Api-Key"}
+
+// sensitiveFields are top-level JSON body fields replaced before recording
+var sensitiveFields = []string{"api_key", "apiKey", "key"}
+
+// Recording is a single recorded request/response exchange
+type Recording struct {
+	Timestamp  time.Time         `json:"timestamp"`
+	Method     string            `json:"method"`
+	Path       string            `json:"path"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Request    json.RawMessage   `json:"request"`
+	Status     int               `json:"status"`
+	Response   json.RawMessage   `json:"response"`
+	DurationMS int64             `json:"duration_ms"`
+}
+
+// Recorder appends request/response pairs to a JSONL file
+type Recorder struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewRecorder opens (or creates) the recording file in dir for appending
+func NewRecorder(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, RecordingFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Record writes one exchange as a single JSON line
+func (rec *Recorder) Record(r Recording) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.enc.Encode(r)
+}
+
+// Close closes the recording file
+func (rec *Recorder) Close() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.file.Close()
+}
+
+// captureWriter tees the response body and status for recording
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *captureWriter) WriteHeader(status int) {
+	c.status = status
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *captureWriter) Write(p []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	c.body.Write(p)
+	return c.ResponseWriter.Write(p)
+}
+
+// Flush passes through so streamed responses are delivered while recording
+func (c *captureWriter) Flush() {
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// recordMiddleware records the request and response when recording is enabled
+func (n *AINode) recordMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if n.recorder == nil || r.Method != http.MethodPost || n.isReplay(r) {
+			next(w, r)
+			return
+		}
+
+		reqBody, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), bodyErrorStatus(err))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(reqBody))
+
+		start := time.Now()
+		cw := &captureWriter{ResponseWriter: w}
+		next(cw, r)
+
+		err = n.recorder.Record(Recording{
+			Timestamp:  start,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Headers:    redactHeaders(r.Header),
+			Request:    redactBody(reqBody),
+			Status:     cw.status,
+			Response:   rawJSON(cw.body.Bytes()),
+			DurationMS: time.Since(start).Milliseconds(),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to record request: %v\n", err)
+		}
+	}
+}
+
+// redactHeaders flattens request headers, masking credentials
+func redactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k := range h {
+		out[k] = h.Get(k)
+	}
+	for _, k := range sensitiveHeaders {
+		if _, ok := out[http.CanonicalHeaderKey(k)]; ok {
+			out[http.CanonicalHeaderKey(k)] = redacted
+		}
+	}
+	return out
+}
+
+// redactBody masks credential fields in a JSON object body
+func redactBody(body []byte) json.RawMessage {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return rawJSON(body)
+	}
+	changed := false
+	for _, k := range sensitiveFields {
+		if _, ok := obj[k]; ok {
+			obj[k], _ = json.Marshal(redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return rawJSON(body)
+	}
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return rawJSON(body)
+	}
+	return out
+}
+
+// rawJSON returns b unchanged if it is valid JSON, otherwise as a JSON string
+func rawJSON(b []byte) json.RawMessage {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && json.Valid(b) {
+		return json.RawMessage(append([]byte(nil), b...))
+	}
+	s, _ := json.Marshal(string(b))
+	return s
+}
+
+// isReplay reports whether r carries the admin token in ReplayHeader
+func (n *AINode) isReplay(r *http.Request) bool {
+	got := r.Header.Get(ReplayHeader)
+	return n.config.AdminToken != "" && subtle.ConstantTimeCompare([]byte(got), []byte(n.config.AdminToken)) == 1
+}
+
+// ReplayResult summarizes a replay run
+type ReplayResult struct {
+	Total      int
+	Matched    int // Status code matched the recording
+	Mismatched int
+	Errors     int
+}
+
+// replayRecordings re-issues each recorded request against target and
+// compares the status code with the recorded one. When adminToken is set
+// it is sent in ReplayHeader so a recording target skips the replays.
+func replayRecordings(ctx context.Context, path, target, adminToken string, out io.Writer) (*ReplayResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	target = strings.TrimRight(target, "/")
+	result := &ReplayResult{}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var rec Recording
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return result, fmt.Errorf("line %d: %w", result.Total+1, err)
+		}
+		result.Total++
+
+		req, err := http.NewRequestWithContext(ctx, rec.Method, target+rec.Path, bytes.NewReader(rec.Request))
+		if err != nil {
+			return result, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if adminToken != "" {
+			req.Header.Set(ReplayHeader, adminToken)
+		}
+		if sid, ok := rec.Headers["X-Session-Id"]; ok {
+			req.Header.Set("X-Session-ID", sid)
+		}
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			result.Errors++
+			fmt.Fprintf(out, "%s %s: %v\n", rec.Method, rec.Path, err)
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		mark := "ok"
+		if resp.StatusCode == rec.Status {
+			result.Matched++
+		} else {
+			result.Mismatched++
+			mark = fmt.Sprintf("MISMATCH (recorded %d)", rec.Status)
+		}
+		fmt.Fprintf(out, "%s %s: %d in %s, recorded %dms %s\n", rec.Method, rec.Path,
+			resp.StatusCode, time.Since(start).Round(time.Millisecond), rec.DurationMS, mark)
+	}
+	return result, scanner.Err()
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRecordReplayRedacts(t *testing.T) {
+	const secret = "s3cret"
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := NewAINode(Config{})
+	n.recorder = recorder
+
+	body := `{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}],"api_key":"` + secret + `"}`
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Session-ID", "sess-1")
+	for _, h := range []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "Api-Key"} {
+		req.Header.Set(h, "Bearer "+secret)
+	}
+	rec := httptest.NewRecorder()
+	n.newMux().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("chat status = %d: %s", rec.Code, rec.Body)
+	}
+	recorder.Close()
+
+	path := filepath.Join(dir, RecordingFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte(secret)) {
+		t.Errorf("recording contains a credential: %s", data)
+	}
+	var recorded Recording
+	if err := json.Unmarshal(bytes.TrimSpace(data), &recorded); err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range sensitiveHeaders {
+		if got := recorded.Headers[http.CanonicalHeaderKey(h)]; got != redacted {
+			t.Errorf("recorded %s = %q, want %q", h, got, redacted)
+		}
+	}
+	if got := recorded.Headers["X-Session-Id"]; got != "sess-1" {
+		t.Errorf("recorded X-Session-Id = %q, want it kept", got)
+	}
+
+	// Replaying sends the redacted request, with no credentials, and
+	// reproduces the recorded status
+	var mu sync.Mutex
+	var replayed []*http.Request
+	var replayedBody []byte
+	target := NewAINode(Config{})
+	mux := target.newMux()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		replayed = append(replayed, r)
+		replayedBody = b
+		mu.Unlock()
+		r.Body = io.NopCloser(bytes.NewReader(b))
+		mux.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	result, err := replayRecordings(context.Background(), path, srv.URL, "admin", &out)
+	if err != nil {
+		t.Fatalf("replayRecordings() error = %v", err)
+	}
+	if result.Total != 1 || result.Matched != 1 {
+		t.Errorf("replay = %+v, want 1 matched: %s", result, out.String())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(replayed) != 1 {
+		t.Fatalf("target got %d requests, want 1", len(replayed))
+	}
+	r := replayed[0]
+	for _, h := range sensitiveHeaders {
+		if got := r.Header.Get(h); got != "" {
+			t.Errorf("replayed %s = %q, want none", h, got)
+		}
+	}
+	if r.Header.Get(ReplayHeader) != "admin" || r.Header.Get("X-Session-ID") != "sess-1" {
+		t.Errorf("replayed headers = %v, want the replay marker and session", r.Header)
+	}
+	if bytes.Contains(replayedBody, []byte(secret)) || !bytes.Contains(replayedBody, []byte(redacted)) {
+		t.Errorf("replayed body = %s, want api_key redacted", replayedBody)
+	}
+}
+
+func TestReplayHeaderRequiresAdminToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminToken string
+		marker     string
+		wantRecord bool
+	}{
+		{"no marker", "admin", "", true},
+		{"wrong marker", "admin", "1", true},
+		{"admin token", "admin", "admin", false},
+		{"no admin token configured", "", "1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			recorder, err := NewRecorder(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := NewAINode(Config{AdminToken: tt.adminToken})
+			n.recorder = recorder
+
+			req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}]}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.marker != "" {
+				req.Header.Set(ReplayHeader, tt.marker)
+			}
+			n.newMux().ServeHTTP(httptest.NewRecorder(), req)
+			recorder.Close()
+
+			data, err := os.ReadFile(filepath.Join(dir, RecordingFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recorded := len(data) > 0; recorded != tt.wantRecord {
+				t.Errorf("recorded = %v, want %v", recorded, tt.wantRecord)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/luxfi/ai/internal/minersig"
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+var (
+	errMissingMinerID           = errors.New("id is required")
+	errUnauthorizedRegistration = errors.New("miner already registered; re-registering requires its token or a signature from its registered key")
+	errUnsignedKeyRotation      = errors.New("changing a registered public key requires a signature from the current key")
+	errInvalidRegistration      = errors.New("invalid registration signature")
+	errStaleRegistration        = errors.New("registration timestamp outside allowed clock skew")
+	errReplayedRegistration     = errors.New("registration not newer than the last accepted")
+)
+
+// minerRegistration is the body of /api/miners/register: what the miner
+// reports about itself and, for a miner re-registering without its node
+// token, a signature by its registered key over minersig.RegistrationDigest
+type minerRegistration struct {
+	MinerInfo
+	Timestamp time.Time `json:"timestamp,omitempty"`
+	Signature []byte    `json:"signature,omitempty"`
+}
+
+// verifyRegistration checks that reg is signed by the key old is
+// registered with, within heartbeatSkew of now and newer than the last
+// signed registration accepted
+func verifyRegistration(old *MinerInfo, reg *minerRegistration, now time.Time) error {
+	if len(old.PublicKey) == 0 || len(reg.Signature) == 0 || reg.Timestamp.IsZero() {
+		return errUnauthorizedRegistration
+	}
+	digest := minersig.RegistrationDigest(old.ID, reg.Timestamp, reg.PublicKey)
+	if !ed25519.Verify(old.PublicKey, digest[:], reg.Signature) {
+		return errInvalidRegistration
+	}
+	if skew := now.Sub(reg.Timestamp); skew > heartbeatSkew || skew < -heartbeatSkew {
+		return errStaleRegistration
+	}
+	if !reg.Timestamp.After(old.registeredAt) {
+		return errReplayedRegistration
+	}
+	return nil
+}
+
+// authorizeRegistrationLocked checks that r may update the known miner old:
+// it must carry the miner's current bearer token or be signed by its
+// registered key. The public key is bound at first registration, so
+// replacing or dropping it always needs a signature from the current key;
+// the token alone is not enough. Caller holds n.mu.
+func (n *AINode) authorizeRegistrationLocked(r *http.Request, old *MinerInfo, reg *minerRegistration, now time.Time) error {
+	rotating := len(old.PublicKey) > 0 && !bytes.Equal(old.PublicKey, reg.PublicKey)
+	if token := n.tokens[old.ID]; !rotating && token != "" && validBearer(r, token) {
+		return nil
+	}
+	if rotating && len(reg.Signature) == 0 {
+		return errUnsignedKeyRotation
+	}
+	if err := verifyRegistration(old, reg, now); err != nil {
+		return err
+	}
+	old.registeredAt = reg.Timestamp
+	return nil
+}
+
+// upsertMinerLocked stores a registration. A new miner starts with none
+// of the state the node owns: trust, tier and attestation come only from a
+// verified attestation, and task counts from the node's own bookkeeping,
+// whatever the registration claimed. A known miner keeps the stats the
+// node accumulated for it (tasks handled, active tasks, attestation,
+// heartbeat replay state) and takes only the fields the miner reports
+// about itself. It returns whether the miner was created and, for an
+// update, the JSON names of the fields that changed. Caller holds n.mu.
+func (n *AINode) upsertMinerLocked(reg *MinerInfo) (created bool, changed []string) {
+	old, ok := n.miners[reg.ID]
+	if !ok {
+		reg.TasksHandled = 0
+		reg.ActiveTasks = 0
+		reg.TrustScore = 0
+		reg.Tier = 0
+		reg.AttestedUntil = time.Time{}
+		n.miners[reg.ID] = reg
+		return true, nil
+	}
+
+	if old.WalletAddr != reg.WalletAddr {
+		changed = append(changed, "wallet_address")
+	}
+	if old.Endpoint != reg.Endpoint {
+		changed = append(changed, "endpoint")
+	}
+	if old.GPUEnabled != reg.GPUEnabled {
+		changed = append(changed, "gpu_enabled")
+	}
+	if !slices.Equal(old.Models, reg.Models) {
+		changed = append(changed, "models")
+	}
+	if old.CapacityTPS != reg.CapacityTPS {
+		changed = append(changed, "capacity_tps")
+	}
+	if old.GPUMemoryMB != reg.GPUMemoryMB {
+		changed = append(changed, "gpu_memory_mb")
+	}
+	if !bytes.Equal(old.PublicKey, reg.PublicKey) {
+		changed = append(changed, "public_key")
+	}
+	if old.Region != reg.Region {
+		changed = append(changed, "region")
+	}
+	if !maps.Equal(old.ModelingLevels, reg.ModelingLevels) {
+		changed = append(changed, "modeling_levels")
+	}
+	if (old.Capability == nil) != (reg.Capability == nil) || len(cc.Diff(old.Capability, reg.Capability)) > 0 {
+		changed = append(changed, "capability")
+	}
+
+	old.WalletAddr = reg.WalletAddr
+	old.Endpoint = reg.Endpoint
+	old.GPUEnabled = reg.GPUEnabled
+	old.Models = reg.Models
+	old.CapacityTPS = reg.CapacityTPS
+	old.GPUMemoryMB = reg.GPUMemoryMB
+	old.PublicKey = reg.PublicKey
+	old.Region = reg.Region
+	old.ModelingLevels = reg.ModelingLevels
+	old.Capability = reg.Capability
+	old.LastSeen = reg.LastSeen
+
+	// An attestation the new hardware cannot back no longer counts
+	if old.Tier != 0 && !old.canAchieveTier(old.Tier) {
+		old.Tier = 0
+		old.TrustScore = 0
+		old.AttestedUntil = time.Time{}
+	}
+	return false, changed
+}
+
+// canAchieveTier reports whether m's registered capability supports tier.
+// A miner that reported no capability is treated as Tier4 hardware.
+func (m *MinerInfo) canAchieveTier(tier cc.CCTier) bool {
+	if m.Capability == nil {
+		return tier == cc.Tier4Standard
+	}
+	return m.Capability.CanAchieveTier(tier)
+}
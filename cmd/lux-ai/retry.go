@@ -0,0 +1,144 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	// defaultMaxRetries is how many times a failed task is reassigned before
+	// it moves to the dead-letter state
+	defaultMaxRetries = 2
+
+	// attemptTimeout bounds how long a miner may hold an attempt without
+	// progress, since it was assigned or its last accepted chunk, before it
+	// is counted as failed and retried elsewhere
+	attemptTimeout = 20 * time.Second
+
+	// sweepInterval is how often stalled attempts are checked
+	sweepInterval = time.Second
+
+	// defaultMinerMaxAge is how recently a miner must have been seen to be
+	// listed by /api/miners?online=true
+	defaultMinerMaxAge = 5 * time.Minute
+)
+
+// failTaskLocked records a failed attempt and either reassigns the task,
+// preferring miners that have not yet tried it, or, once retries are
+// exhausted, moves it to TaskDead. Caller holds n.mu.
+func (n *AINode) failTaskLocked(t *Task, reason string) {
+	miner := t.AssignedTo
+	if miner == "" {
+		miner = "unassigned"
+	}
+	t.Failures = append(t.Failures, fmt.Sprintf("attempt %d (%s): %s", t.Attempts, miner, reason))
+	n.recordLocked(t, EventFailed, reason)
+	n.finishTaskLocked(t)
+	n.resetChunksLocked(t)
+
+	if t.Attempts > n.config.MaxRetries {
+		t.Status = TaskDead
+		t.AssignedTo = ""
+		t.FinishedAt = n.clock.Now()
+		n.recordLocked(t, EventDead, "")
+		return
+	}
+
+	miners, _ := n.qualifiedLocked(n.sortedMinersLocked(), t.Model)
+	var candidates []*MinerInfo
+	for _, m := range miners {
+		if !t.triedBy(m.ID) {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		// Every connected miner has tried it; retry anywhere rather than stall
+		candidates = miners
+	}
+	if len(candidates) == 0 {
+		t.Status = TaskPending
+		t.AssignedTo = ""
+		n.recordLocked(t, EventQueued, "no qualified miner")
+		return
+	}
+	next := n.scheduler.Select(candidates, n.schedulerRNG())
+	n.assignLocked(t, next, TaskAssigned)
+}
+
+// triedBy reports whether minerID has already attempted t
+func (t *Task) triedBy(minerID string) bool {
+	for _, id := range t.TriedMiners {
+		if id == minerID {
+			return true
+		}
+	}
+	return false
+}
+
+// lastProgress returns when t's current attempt last made progress: when
+// it was assigned or, for a streaming miner, its latest accepted chunk
+func (t *Task) lastProgress() time.Time {
+	if t.progressAt.After(t.AssignedAt) {
+		return t.progressAt
+	}
+	return t.AssignedAt
+}
+
+// sweepTasks fails attempts that have gone longer than attemptTimeout
+// without progress until ctx is cancelled
+func (n *AINode) sweepTasks(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.sweepStale(n.clock.Now())
+		}
+	}
+}
+
+// sweepStale fails attempts that have gone longer than attemptTimeout
+// without progress as of now, so a miner streaming a long result keeps it
+func (n *AINode) sweepStale(now time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, t := range n.tasks {
+		if (t.Status == TaskAssigned || t.Status == TaskRunning) && stale(t.lastProgress(), now, attemptTimeout) {
+			n.failTaskLocked(t, "attempt timed out")
+		}
+	}
+}
+
+// stale reports whether more than maxAge has passed between since and now
+func stale(since, now time.Time, maxAge time.Duration) bool {
+	return now.Sub(since) > maxAge
+}
+
+// handleDeadTasks lists tasks that exhausted their retries, oldest first
+func (n *AINode) handleDeadTasks(w http.ResponseWriter, r *http.Request) {
+	n.mu.RLock()
+	dead := make([]Task, 0)
+	for _, t := range n.tasks {
+		if t.Status == TaskDead {
+			dead = append(dead, *t)
+		}
+	}
+	n.mu.RUnlock()
+
+	sort.Slice(dead, func(i, j int) bool {
+		return dead[i].CreatedAt.Before(dead[j].CreatedAt)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dead)
+}
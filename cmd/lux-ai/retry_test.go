@@ -0,0 +1,243 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/clock"
+)
+
+func TestSweepStale(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		wantStatus string
+		wantMiner  string
+	}{
+		{"retried elsewhere", 1, TaskAssigned, "m2"},
+		{"retries exhausted", 0, TaskDead, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			n := NewAINode(Config{MaxRetries: tt.maxRetries})
+			n.clock = mock
+			n.miners["m1"] = &MinerInfo{ID: "m1", Models: []string{"zen-mini-0.5b"}}
+			n.miners["m2"] = &MinerInfo{ID: "m2", Models: []string{"zen-mini-0.5b"}}
+			task := &Task{ID: "t1", Model: "zen-mini-0.5b", CreatedAt: mock.Now()}
+			n.tasks["t1"] = task
+			n.assignLocked(task, n.miners["m1"], TaskRunning)
+
+			n.sweepStale(mock.Advance(attemptTimeout))
+			if task.Status != TaskRunning || task.AssignedTo != "m1" {
+				t.Fatalf("at timeout: status = %s on %q, want running on m1", task.Status, task.AssignedTo)
+			}
+
+			now := mock.Advance(time.Millisecond)
+			n.sweepStale(now)
+			if task.Status != tt.wantStatus || task.AssignedTo != tt.wantMiner {
+				t.Errorf("after timeout: status = %s on %q, want %s on %q", task.Status, task.AssignedTo, tt.wantStatus, tt.wantMiner)
+			}
+			if tt.wantStatus == TaskDead && !task.FinishedAt.Equal(now) {
+				t.Errorf("FinishedAt = %v, want %v", task.FinishedAt, now)
+			}
+			if tt.wantStatus == TaskAssigned && !task.AssignedAt.Equal(now) {
+				t.Errorf("AssignedAt = %v, want %v", task.AssignedAt, now)
+			}
+		})
+	}
+}
+
+func TestSweepStaleKeepsStreamingAttempt(t *testing.T) {
+	mock := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	n := NewAINode(Config{MaxRetries: 1, DataDir: t.TempDir()})
+	n.clock = mock
+	n.miners["m1"] = &MinerInfo{ID: "m1", Models: []string{"zen-mini-0.5b"}}
+	n.miners["m2"] = &MinerInfo{ID: "m2", Models: []string{"zen-mini-0.5b"}}
+	task := &Task{ID: "t1", Model: "zen-mini-0.5b", CreatedAt: mock.Now()}
+	n.tasks["t1"] = task
+	n.assignLocked(task, n.miners["m1"], TaskRunning)
+
+	// A result streamed for several attempt timeouts keeps its miner
+	for seq := range 8 {
+		n.sweepStale(mock.Advance(attemptTimeout / 2))
+		n.appendChunkLocked(task, seq, "x")
+	}
+	if task.Status != TaskRunning || task.AssignedTo != "m1" || task.Partial != "xxxxxxxx" {
+		t.Fatalf("while streaming: status = %s on %q with %q, want running on m1", task.Status, task.AssignedTo, task.Partial)
+	}
+
+	// Duplicates are not progress
+	n.sweepStale(mock.Advance(attemptTimeout / 2))
+	n.appendChunkLocked(task, 7, "x")
+	n.sweepStale(mock.Advance(attemptTimeout/2 + time.Millisecond))
+	if task.AssignedTo != "m2" || task.Partial != "" {
+		t.Errorf("after stalling: assigned to %q with %q, want retried on m2 from scratch", task.AssignedTo, task.Partial)
+	}
+}
+
+func TestFailTaskReassignment(t *testing.T) {
+	tests := []struct {
+		name      string
+		miners    map[string][]string // Miner ID -> advertised models
+		tried     []string            // Miners that tried before the failing one
+		wantMiner string              // "" to stay pending
+	}{
+		{"untried miner preferred", map[string][]string{"m1": nil, "m2": nil, "m3": nil}, []string{"m2"}, "m3"},
+		{"all tried retries anywhere", map[string][]string{"m1": nil, "m2": nil}, []string{"m2"}, "m1"},
+		{"unqualified miner skipped", map[string][]string{"m1": nil, "m2": {"other"}}, nil, "m1"},
+		{"failed miner gone", map[string][]string{"m2": nil}, nil, "m2"},
+		{"no miner left", nil, nil, ""},
+		{"no qualified miner left", map[string][]string{"m2": {"other"}}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{MaxRetries: 5})
+			failed := &MinerInfo{ID: "m1"}
+			n.miners["m1"] = failed
+			task := &Task{ID: "t1", Model: "zen-mini-0.5b", TriedMiners: tt.tried, Attempts: len(tt.tried)}
+			n.tasks[task.ID] = task
+			n.assignLocked(task, failed, TaskRunning)
+			if _, ok := tt.miners["m1"]; !ok {
+				delete(n.miners, "m1")
+			}
+			for id, models := range tt.miners {
+				if id != "m1" {
+					n.miners[id] = &MinerInfo{ID: id, Models: models}
+				}
+			}
+
+			n.failTaskLocked(task, "boom")
+			if _, ok := n.miners["m1"]; ok && tt.wantMiner != "m1" && failed.ActiveTasks != 0 {
+				t.Errorf("failed miner has %d active tasks, want its slot released", failed.ActiveTasks)
+			}
+			if len(task.Failures) != 1 || !strings.Contains(task.Failures[0], "(m1): boom") {
+				t.Errorf("failures = %q, want the attempt on m1", task.Failures)
+			}
+			last := task.Timeline[len(task.Timeline)-1]
+			if tt.wantMiner == "" {
+				if task.Status != TaskPending || task.AssignedTo != "" || last.Event != EventQueued {
+					t.Errorf("status = %s on %q, last event %s; want pending, unassigned and queued", task.Status, task.AssignedTo, last.Event)
+				}
+				return
+			}
+			if task.Status != TaskAssigned || task.AssignedTo != tt.wantMiner || n.miners[tt.wantMiner].ActiveTasks != 1 {
+				t.Errorf("status = %s on %q, want assigned to %s", task.Status, task.AssignedTo, tt.wantMiner)
+			}
+			if want := len(tt.tried) + 2; task.Attempts != want || task.TriedMiners[want-1] != tt.wantMiner {
+				t.Errorf("attempts %d, tried %v; want %d ending with %s", task.Attempts, task.TriedMiners, want, tt.wantMiner)
+			}
+		})
+	}
+}
+
+func TestPendingTaskClaimedByNewMiner(t *testing.T) {
+	n := NewAINode(Config{MaxRetries: 1})
+	n.miners["m1"] = &MinerInfo{ID: "m1"}
+	task := &Task{ID: "t1", Model: "zen-mini-0.5b"}
+	n.tasks[task.ID] = task
+	n.assignLocked(task, n.miners["m1"], TaskRunning)
+	delete(n.miners, "m1")
+
+	n.failTaskLocked(task, "boom")
+	if task.Status != TaskPending {
+		t.Fatalf("status = %s, want pending with no miner left", task.Status)
+	}
+	n.miners["m2"] = &MinerInfo{ID: "m2"}
+	if claimed := n.claimTasksLocked("m2"); len(claimed) != 1 || claimed[0] != task {
+		t.Fatalf("claimed %v, want the pending task", claimed)
+	}
+
+	// The claim counted as the last allowed retry
+	n.failTaskLocked(task, "boom again")
+	if task.Status != TaskDead || task.Attempts != 2 || len(task.Failures) != 2 {
+		t.Errorf("status = %s after %d attempts and %d failures, want dead after 2", task.Status, task.Attempts, len(task.Failures))
+	}
+}
+
+func TestHandleDeadTasks(t *testing.T) {
+	n := NewAINode(Config{})
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"c", "a", "d", "b"} {
+		n.tasks[id] = &Task{ID: id, Status: TaskDead, CreatedAt: base.Add(time.Duration(3-i) * time.Minute)}
+	}
+	n.tasks["pending"] = &Task{ID: "pending", Status: TaskPending}
+	n.tasks["done"] = &Task{ID: "done", Status: TaskCompleted}
+
+	rec := httptest.NewRecorder()
+	n.newMux().ServeHTTP(rec, httptest.NewRequest("GET", "/api/tasks/dead", nil))
+	var dead []Task
+	if err := json.NewDecoder(rec.Body).Decode(&dead); err != nil {
+		t.Fatalf("status %d: %v", rec.Code, err)
+	}
+	var ids []string
+	for _, task := range dead {
+		ids = append(ids, task.ID)
+	}
+	if want := []string{"b", "d", "a", "c"}; !slices.Equal(ids, want) {
+		t.Errorf("dead tasks = %v, want oldest first %v", ids, want)
+	}
+
+	// No dead tasks is an empty list, not null
+	n.tasks = map[string]*Task{}
+	rec = httptest.NewRecorder()
+	n.newMux().ServeHTTP(rec, httptest.NewRequest("GET", "/api/tasks/dead", nil))
+	if got := strings.TrimSpace(rec.Body.String()); got != "[]" {
+		t.Errorf("no dead tasks = %s, want []", got)
+	}
+}
+
+func TestSubmitResultRequiresAssignedMiner(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		body       string
+		status     int
+		wantStatus string
+	}{
+		{"no token", "", `{"id":"t1","status":"failed"}`, http.StatusUnauthorized, TaskRunning},
+		{"other miner's token", "tok-m2", `{"id":"t1","status":"completed","output":{}}`, http.StatusUnauthorized, TaskRunning},
+		{"unknown status", "tok-m1", `{"id":"t1","status":"running","output":{}}`, http.StatusBadRequest, TaskRunning},
+		{"no status", "tok-m1", `{"id":"t1","output":{}}`, http.StatusBadRequest, TaskRunning},
+		{"unknown task", "tok-m1", `{"id":"t2","status":"failed"}`, http.StatusNotFound, TaskRunning},
+		{"failed by assignee", "tok-m1", `{"id":"t1","status":"failed"}`, http.StatusOK, TaskDead},
+		{"completed by assignee", "tok-m1", `{"id":"t1","status":"completed","output":{}}`, http.StatusOK, TaskCompleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{})
+			for _, id := range []string{"m1", "m2"} {
+				n.miners[id] = &MinerInfo{ID: id}
+				n.tokens[id] = "tok-" + id
+			}
+			task := &Task{ID: "t1", Model: "zen-mini-0.5b"}
+			n.tasks[task.ID] = task
+			n.assignLocked(task, n.miners["m1"], TaskRunning)
+
+			req := httptest.NewRequest("POST", "/api/tasks/submit", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			n.newMux().ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if task.Status != tt.wantStatus {
+				t.Errorf("task status = %s, want %s", task.Status, tt.wantStatus)
+			}
+			if tt.wantStatus != TaskRunning && (n.miners["m1"].ActiveTasks != 0 || task.FinishedAt.IsZero()) {
+				t.Errorf("m1 has %d active tasks, finished at %v; want the slot released and a finish time", n.miners["m1"].ActiveTasks, task.FinishedAt)
+			}
+		})
+	}
+}
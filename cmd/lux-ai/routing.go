@@ -0,0 +1,97 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+// RoutedFromHeader names the virtual model a chat request asked for when
+// Config.ModelRoutes sent it to a concrete one
+const RoutedFromHeader = "X-Lux-Routed-From"
+
+// ModelRoutes maps a virtual model name to the concrete models that serve
+// it and their relative weights, e.g.
+//
+//	{"zen-chat": {"zen-mini-0.5b": 90, "qwen3-8b": 10}}
+//
+// sends about 10% of zen-chat requests to qwen3-8b. A weight of 0 keeps a
+// model listed without sending it traffic.
+type ModelRoutes map[string]map[string]int
+
+// loadModelRoutes reads ModelRoutes from a JSON file. The routes are
+// checked against the model map by Config.Validate.
+func loadModelRoutes(path string) (ModelRoutes, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var routes ModelRoutes
+	if err := json.Unmarshal(data, &routes); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return routes, nil
+}
+
+// validate checks that every route names concrete models with
+// non-negative weights that send it some traffic, and does not shadow a
+// model of the same name
+func (routes ModelRoutes) validate(models map[string]*ModelInfo) []string {
+	var problems []string
+	for _, name := range sortedKeys(routes) {
+		if _, ok := models[name]; ok {
+			problems = append(problems, fmt.Sprintf("%s: shadows the model of the same name", name))
+		}
+		total := 0
+		for _, id := range sortedKeys(routes[name]) {
+			weight := routes[name][id]
+			if _, ok := models[id]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown model %s", name, id))
+			}
+			if weight < 0 {
+				problems = append(problems, fmt.Sprintf("%s: %s weight %d is negative", name, id, weight))
+			} else {
+				total += weight
+			}
+		}
+		if total == 0 {
+			problems = append(problems, fmt.Sprintf("%s: no model has a positive weight", name))
+		}
+	}
+	return problems
+}
+
+// pick chooses one of name's models with probability proportional to its
+// weight. Models are considered in ID order, so a seeded rng picks
+// reproducibly. ok is false if name is not a virtual model.
+func (routes ModelRoutes) pick(name string, rng *rand.Rand) (model string, ok bool) {
+	backends, ok := routes[name]
+	if !ok {
+		return "", false
+	}
+	ids := make([]string, 0, len(backends))
+	total := 0
+	for id, weight := range backends {
+		if weight > 0 {
+			ids = append(ids, id)
+			total += weight
+		}
+	}
+	if total == 0 {
+		return "", false
+	}
+	sort.Strings(ids)
+	r := rng.Intn(total)
+	for _, id := range ids {
+		if r < backends[id] {
+			return id, true
+		}
+		r -= backends[id]
+	}
+	return ids[len(ids)-1], true
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestModelRoutesPick(t *testing.T) {
+	routes := ModelRoutes{"zen-chat": {"zen-mini-0.5b": 90, "qwen3-8b": 10, "zen-coder-1.5b": 0}}
+	rng := rand.New(rand.NewSource(1))
+
+	counts := make(map[string]int)
+	for range 10000 {
+		model, ok := routes.pick("zen-chat", rng)
+		if !ok {
+			t.Fatal("pick(zen-chat) reported no route")
+		}
+		counts[model]++
+	}
+	if counts["zen-coder-1.5b"] != 0 {
+		t.Errorf("zero-weight model picked %d times", counts["zen-coder-1.5b"])
+	}
+	if got := counts["qwen3-8b"]; got < 800 || got > 1200 {
+		t.Errorf("qwen3-8b picked %d of 10000 times, want about 1000", got)
+	}
+	if _, ok := routes.pick("zen-mini-0.5b", rng); ok {
+		t.Error("pick() routed a concrete model")
+	}
+}
+
+func TestModelRoutesValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes ModelRoutes
+		want   []string
+	}{
+		{"valid", ModelRoutes{"zen-chat": {"zen-mini-0.5b": 9, "qwen3-8b": 1}}, nil},
+		{"shadows model", ModelRoutes{"qwen3-8b": {"zen-mini-0.5b": 1}}, []string{"qwen3-8b: shadows the model of the same name"}},
+		{"unknown model", ModelRoutes{"zen-chat": {"gpt-9": 1}}, []string{"zen-chat: unknown model gpt-9"}},
+		{"negative weight", ModelRoutes{"zen-chat": {"qwen3-8b": -1, "zen-mini-0.5b": 1}}, []string{"zen-chat: qwen3-8b weight -1 is negative"}},
+		{"no traffic", ModelRoutes{"zen-chat": {"qwen3-8b": 0}}, []string{"zen-chat: no model has a positive weight"}},
+	}
+	for _, tt := range tests {
+		if got := tt.routes.validate(defaultModels()); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: validate() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChatModelRouting(t *testing.T) {
+	n := NewAINode(Config{
+		DataDir:     t.TempDir(),
+		ModelRoutes: ModelRoutes{"zen-chat": {"qwen3-8b": 1}},
+	})
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions",
+		strings.NewReader(`{"model":"zen-chat","messages":[{"role":"user","content":"hi"}]}`))
+	req.Header.Set("Authorization", "Bearer sk-test")
+	rec := httptest.NewRecorder()
+	n.handleChatCompletions(rec, req)
+
+	var resp ChatResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Model != "qwen3-8b" {
+		t.Errorf("response model = %s, want qwen3-8b", resp.Model)
+	}
+	if got := rec.Header().Get(RoutedFromHeader); got != "zen-chat" {
+		t.Errorf("%s = %q, want zen-chat", RoutedFromHeader, got)
+	}
+
+	usage := n.usage.report(keyID("sk-test"), time.Time{}, time.Time{})
+	if len(usage) != 1 || !reflect.DeepEqual(usage[0].Models, map[string]uint64{"qwen3-8b": 1}) {
+		t.Errorf("usage = %+v, want one qwen3-8b request", usage)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import "fmt"
+
+// SamplingParams are a model's default sampling parameters. A nil field
+// falls back to globalDefaultParams.
+type SamplingParams struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+}
+
+// globalDefaultParams apply when neither a chat request nor its model sets
+// a parameter, so miners don't fall back to their backends' defaults
+var globalDefaultParams = SamplingParams{Temperature: float64Ptr(0.7), TopP: float64Ptr(1)}
+
+// applyDefaultParams fills in the sampling parameters req omits. A value
+// in the request wins over the model's DefaultParams, which win over
+// globalDefaultParams.
+func (req *ChatRequest) applyDefaultParams(model *ModelInfo) {
+	var defaults SamplingParams
+	if model != nil && model.DefaultParams != nil {
+		defaults = *model.DefaultParams
+	}
+	req.Temperature = firstParam(req.Temperature, defaults.Temperature, globalDefaultParams.Temperature)
+	req.TopP = firstParam(req.TopP, defaults.TopP, globalDefaultParams.TopP)
+}
+
+// firstParam returns a copy of the first set value, or nil if none is
+func firstParam(values ...*float64) *float64 {
+	for _, v := range values {
+		if v != nil {
+			return float64Ptr(*v)
+		}
+	}
+	return nil
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+// validateSampling rejects sampling parameters outside the ranges the
+// OpenAI API accepts: temperature 0-2, top_p 0-1 and penalties -2 to 2
+func (req *ChatRequest) validateSampling() error {
+	if t := req.Temperature; t != nil && (*t < 0 || *t > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %g", *t)
+	}
+	if p := req.TopP; p != nil && (*p < 0 || *p > 1) {
+		return fmt.Errorf("top_p must be between 0 and 1, got %g", *p)
+	}
+	if p := req.FrequencyPenalty; p != nil && (*p < -2 || *p > 2) {
+		return fmt.Errorf("frequency_penalty must be between -2 and 2, got %g", *p)
+	}
+	if p := req.PresencePenalty; p != nil && (*p < -2 || *p > 2) {
+		return fmt.Errorf("presence_penalty must be between -2 and 2, got %g", *p)
+	}
+	return nil
+}
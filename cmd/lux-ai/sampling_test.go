@@ -0,0 +1,141 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/ai/pkg/miner/backend"
+)
+
+func TestChatSamplingValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  string
+		wantErr string
+	}{
+		{"in range", `"temperature":2,"top_p":0,"frequency_penalty":-2,"presence_penalty":2,"seed":-1`, ""},
+		{"temperature", `"temperature":2.5`, "temperature must be between 0 and 2"},
+		{"top_p", `"top_p":1.1`, "top_p must be between 0 and 1"},
+		{"frequency_penalty", `"frequency_penalty":-3`, "frequency_penalty must be between -2 and 2"},
+		{"presence_penalty", `"presence_penalty":2.01`, "presence_penalty must be between -2 and 2"},
+		{"extra fields tolerated", `"user":"u-1","logit_bias":{"50256":-100},"logprobs":false`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{})
+			rec := chatRequest(t, n, `{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}],`+tt.params+`}`)
+			if tt.wantErr == "" {
+				if rec.Code != http.StatusOK {
+					t.Errorf("status = %d, want 200: %s", rec.Code, rec.Body)
+				}
+				return
+			}
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tt.wantErr) {
+				t.Errorf("got %d %q, want 400 %q", rec.Code, rec.Body, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChatSamplingPassthrough(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := NewAINode(Config{})
+	runFakeMiner(ctx, n, "hello")
+
+	rec := chatRequest(t, n, `{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}],"top_p":0.8,"presence_penalty":0.5,"seed":1234}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	var resp ChatResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Seed == nil || *resp.Seed != 1234 {
+		t.Errorf("response seed = %v, want 1234", resp.Seed)
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if len(n.tasks) != 1 {
+		t.Fatalf("got %d tasks, want 1", len(n.tasks))
+	}
+	for _, task := range n.tasks {
+		var input backend.ChatRequest
+		if err := json.Unmarshal(task.Input, &input); err != nil {
+			t.Fatal(err)
+		}
+		s := input.Sampling
+		if s.TopP == nil || *s.TopP != 0.8 || s.PresencePenalty == nil || *s.PresencePenalty != 0.5 || s.Seed == nil || *s.Seed != 1234 {
+			t.Errorf("task sampling = %+v, want the request's parameters", s)
+		}
+		if s.FrequencyPenalty != nil {
+			t.Errorf("FrequencyPenalty = %v, want unset", *s.FrequencyPenalty)
+		}
+	}
+}
+
+func TestChatDefaultParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		params   string
+		wantTemp float64
+		wantTopP float64
+	}{
+		{"client overrides model", "tuned", `,"temperature":1.2,"top_p":0.5`, 1.2, 0.5},
+		{"explicit zero temperature kept", "tuned", `,"temperature":0`, 0, 0.9},
+		{"model default", "tuned", "", 0.3, 0.9},
+		{"global default under partial model defaults", "half-tuned", "", 0.3, 1},
+		{"global default", "untuned", "", 0.7, 1},
+		{"built-in model default", "zen-coder-1.5b", "", 0.2, 0.95},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			n := NewAINode(Config{})
+			n.models["tuned"] = &ModelInfo{ID: "tuned", Type: "chat", DefaultParams: &SamplingParams{Temperature: float64Ptr(0.3), TopP: float64Ptr(0.9)}}
+			n.models["half-tuned"] = &ModelInfo{ID: "half-tuned", Type: "chat", DefaultParams: &SamplingParams{Temperature: float64Ptr(0.3)}}
+			n.models["untuned"] = &ModelInfo{ID: "untuned", Type: "chat"}
+			runFakeMiner(ctx, n, "hello")
+
+			rec := chatRequest(t, n, `{"model":"`+tt.model+`","messages":[{"role":"user","content":"hi"}]`+tt.params+`}`)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+
+			n.mu.RLock()
+			defer n.mu.RUnlock()
+			if len(n.tasks) != 1 {
+				t.Fatalf("got %d tasks, want 1", len(n.tasks))
+			}
+			for _, task := range n.tasks {
+				var input backend.ChatRequest
+				if err := json.Unmarshal(task.Input, &input); err != nil {
+					t.Fatal(err)
+				}
+				s := input.Sampling
+				if s.Temperature == nil || *s.Temperature != tt.wantTemp || s.TopP == nil || *s.TopP != tt.wantTopP {
+					t.Errorf("task temperature, top_p = %v, %v, want %g, %g", ptrString(s.Temperature), ptrString(s.TopP), tt.wantTemp, tt.wantTopP)
+				}
+			}
+		})
+	}
+}
+
+func ptrString(p *float64) string {
+	if p == nil {
+		return "unset"
+	}
+	return strconv.FormatFloat(*p, 'g', -1, 64)
+}
@@ -0,0 +1,207 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Scheduler names accepted in Config.Scheduler
+const (
+	SchedulerRoundRobin    = "round-robin"
+	SchedulerLeastLoaded   = "least-loaded"
+	SchedulerTrustWeighted = "trust-weighted"
+)
+
+// SessionHeader carries an optional client session ID. When present, the
+// scheduler RNG is seeded from it so miner selection is reproducible.
+const SessionHeader = "X-Session-ID"
+
+// RegionHeader carries an optional preferred miner region. Miners that
+// registered the same region (case-insensitively) are preferred, falling
+// back to all miners when none can take the task.
+const RegionHeader = "X-Lux-Region"
+
+// Scheduler picks the miner that receives the next task
+type Scheduler interface {
+	// Name returns the scheduler's config name
+	Name() string
+
+	// Select returns one of miners, or nil if there are none. miners is
+	// sorted by ID; rng is seeded per request.
+	Select(miners []*MinerInfo, rng *rand.Rand) *MinerInfo
+}
+
+// NewScheduler returns the scheduler registered under name.
+// An empty name selects round-robin.
+func NewScheduler(name string) (Scheduler, error) {
+	switch name {
+	case "", SchedulerRoundRobin:
+		return &RoundRobinScheduler{}, nil
+	case SchedulerLeastLoaded:
+		return LeastLoadedScheduler{}, nil
+	case SchedulerTrustWeighted:
+		return TrustWeightedScheduler{}, nil
+	default:
+		return nil, fmt.Errorf("unknown scheduler %q", name)
+	}
+}
+
+// RoundRobinScheduler cycles through miners in ID order
+type RoundRobinScheduler struct {
+	next atomic.Uint64
+}
+
+func (s *RoundRobinScheduler) Name() string { return SchedulerRoundRobin }
+
+func (s *RoundRobinScheduler) Select(miners []*MinerInfo, _ *rand.Rand) *MinerInfo {
+	if len(miners) == 0 {
+		return nil
+	}
+	i := s.next.Add(1) - 1
+	return miners[i%uint64(len(miners))]
+}
+
+// LeastLoadedScheduler picks the miner expected to finish a new task
+// soonest, preferring higher trust scores on ties. Miners are compared by
+// estimated completion time when both have a benchmarked capacity and by
+// active task count otherwise.
+type LeastLoadedScheduler struct{}
+
+func (LeastLoadedScheduler) Name() string { return SchedulerLeastLoaded }
+
+func (LeastLoadedScheduler) Select(miners []*MinerInfo, _ *rand.Rand) *MinerInfo {
+	var best *MinerInfo
+	for _, m := range miners {
+		if best == nil || lessLoaded(m, best) {
+			best = m
+		}
+	}
+	return best
+}
+
+// lessLoaded reports whether a should be preferred over b by the
+// least-loaded scheduler
+func lessLoaded(a, b *MinerInfo) bool {
+	ea, okA := estimateCompletion(a, typicalTaskTokens)
+	eb, okB := estimateCompletion(b, typicalTaskTokens)
+	switch {
+	case okA && okB && ea != eb:
+		return ea < eb
+	case !(okA && okB) && a.ActiveTasks != b.ActiveTasks:
+		return a.ActiveTasks < b.ActiveTasks
+	}
+	return a.TrustScore > b.TrustScore
+}
+
+// defaultMaxCapacityTPS caps the throughput a miner may report when
+// Config.MaxCapacityTPS is unset. Capacity is self-reported, so without a
+// ceiling a miner could claim any figure to win least-loaded and
+// trust-weighted scheduling.
+const defaultMaxCapacityTPS = 2000
+
+// maxCapacityTPS returns the configured ceiling on reported capacity
+func (n *AINode) maxCapacityTPS() int {
+	if n.config.MaxCapacityTPS > 0 {
+		return n.config.MaxCapacityTPS
+	}
+	return defaultMaxCapacityTPS
+}
+
+// typicalTaskTokens is the completion length assumed when estimating how
+// long queued tasks take
+const typicalTaskTokens = 256
+
+// estimateCompletion estimates how long a miner takes to generate tokens
+// for a new task behind its active ones. It returns false when the miner
+// reported no benchmarked capacity.
+func estimateCompletion(m *MinerInfo, tokens int) (time.Duration, bool) {
+	if m.CapacityTPS <= 0 {
+		return 0, false
+	}
+	queued := float64(m.ActiveTasks*typicalTaskTokens + tokens)
+	return time.Duration(queued / m.CapacityTPS * float64(time.Second)), true
+}
+
+// TrustWeightedScheduler picks miners at random with probability
+// proportional to trust score and inversely proportional to load. Every
+// miner keeps a non-zero weight so low-trust miners are not starved.
+type TrustWeightedScheduler struct{}
+
+func (TrustWeightedScheduler) Name() string { return SchedulerTrustWeighted }
+
+func (TrustWeightedScheduler) Select(miners []*MinerInfo, rng *rand.Rand) *MinerInfo {
+	if len(miners) == 0 {
+		return nil
+	}
+
+	weights := make([]float64, len(miners))
+	var total float64
+	for i, m := range miners {
+		weights[i] = minerWeight(m)
+		total += weights[i]
+	}
+
+	r := rng.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return miners[i]
+		}
+		r -= w
+	}
+	return miners[len(miners)-1]
+}
+
+// minerWeight is the trust-weighted selection weight for a miner
+func minerWeight(m *MinerInfo) float64 {
+	return float64(uint(m.TrustScore)+1) / float64(m.ActiveTasks+1)
+}
+
+// inRegion returns the miners in region
+func inRegion(miners []*MinerInfo, region string) []*MinerInfo {
+	local := make([]*MinerInfo, 0, len(miners))
+	for _, m := range miners {
+		if strings.EqualFold(m.Region, region) {
+			local = append(local, m)
+		}
+	}
+	return local
+}
+
+// requestRegion returns the request's preferred miner region, "" if none
+func requestRegion(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(RegionHeader))
+}
+
+// requestRNG returns the RNG used to schedule a request, seeded from the
+// session ID when the client supplied one
+func (n *AINode) requestRNG(r *http.Request) *rand.Rand {
+	if sid := r.Header.Get(SessionHeader); sid != "" {
+		h := fnv.New64a()
+		h.Write([]byte(sid))
+		return rand.New(rand.NewSource(int64(h.Sum64())))
+	}
+	return n.schedulerRNG()
+}
+
+// schedulerRNG returns the RNG for a scheduling decision not tied to a
+// session. With Config.SchedulerSeed set, the nth decision is seeded from
+// the seed and n, so replaying the same requests against a node with the
+// same seed selects the same miners.
+func (n *AINode) schedulerRNG() *rand.Rand {
+	if n.config.SchedulerSeed == 0 {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	h := fnv.New64a()
+	binary.Write(h, binary.BigEndian, n.config.SchedulerSeed)
+	binary.Write(h, binary.BigEndian, n.schedSeq.Add(1))
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
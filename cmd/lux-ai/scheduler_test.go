@@ -0,0 +1,220 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewScheduler(t *testing.T) {
+	tests := []struct {
+		name string
+		want string // "" for an error
+	}{
+		{"", SchedulerRoundRobin},
+		{SchedulerRoundRobin, SchedulerRoundRobin},
+		{SchedulerLeastLoaded, SchedulerLeastLoaded},
+		{SchedulerTrustWeighted, SchedulerTrustWeighted},
+		{"random", ""},
+	}
+	for _, tt := range tests {
+		s, err := NewScheduler(tt.name)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("NewScheduler(%q) = %s, want an error", tt.name, s.Name())
+			}
+			continue
+		}
+		if err != nil || s.Name() != tt.want {
+			t.Errorf("NewScheduler(%q) = %v, %v; want %s", tt.name, s, err, tt.want)
+		}
+	}
+}
+
+func TestSchedulersNoMiners(t *testing.T) {
+	for _, name := range []string{SchedulerRoundRobin, SchedulerLeastLoaded, SchedulerTrustWeighted} {
+		s, _ := NewScheduler(name)
+		for _, miners := range [][]*MinerInfo{nil, {}} {
+			if got := s.Select(miners, rand.New(rand.NewSource(1))); got != nil {
+				t.Errorf("%s: Select(%v) = %s, want nil", name, miners, got.ID)
+			}
+		}
+	}
+}
+
+func TestRoundRobinCycles(t *testing.T) {
+	miners := []*MinerInfo{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	s := &RoundRobinScheduler{}
+	var got []string
+	for range 7 {
+		got = append(got, s.Select(miners, nil).ID)
+	}
+	if want := []string{"a", "b", "c", "a", "b", "c", "a"}; !slices.Equal(got, want) {
+		t.Errorf("picks = %v, want %v", got, want)
+	}
+
+	// The cursor keeps counting when the miner list shrinks: eighth pick
+	if got := s.Select(miners[:2], nil).ID; got != "b" {
+		t.Errorf("pick from two miners = %s, want b", got)
+	}
+}
+
+func TestMinerWeight(t *testing.T) {
+	tests := []struct {
+		name  string
+		miner MinerInfo
+		want  float64
+	}{
+		{"untrusted idle", MinerInfo{}, 1},
+		{"trusted idle", MinerInfo{TrustScore: 99}, 100},
+		{"trusted busy", MinerInfo{TrustScore: 99, ActiveTasks: 4}, 20},
+		{"max trust", MinerInfo{TrustScore: 255}, 256},
+	}
+	for _, tt := range tests {
+		if got := minerWeight(&tt.miner); got != tt.want {
+			t.Errorf("%s: minerWeight() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTrustWeightedDistribution(t *testing.T) {
+	miners := []*MinerInfo{
+		{ID: "trusted", TrustScore: 99},              // Weight 100
+		{ID: "busy", TrustScore: 99, ActiveTasks: 3}, // Weight 25
+		{ID: "untrusted"},                            // Weight 1
+	}
+	const picks = 12600
+	rng := rand.New(rand.NewSource(1))
+	counts := map[string]int{}
+	for range picks {
+		counts[(TrustWeightedScheduler{}).Select(miners, rng).ID]++
+	}
+
+	// Within 20% of the expected share, and nobody starved
+	for _, m := range miners {
+		want := picks * minerWeight(m) / 126
+		if got := float64(counts[m.ID]); got < 0.8*want || got > 1.2*want {
+			t.Errorf("%s picked %v times, want about %v", m.ID, got, want)
+		}
+	}
+}
+
+func TestRequestRNGSession(t *testing.T) {
+	n := NewAINode(Config{})
+	miners := make([]*MinerInfo, 10)
+	for i := range miners {
+		miners[i] = &MinerInfo{ID: fmt.Sprintf("m%d", i), TrustScore: 50}
+	}
+	picks := func(session string) []string {
+		ids := make([]string, 10)
+		for i := range ids {
+			req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+			req.Header.Set(SessionHeader, session)
+			rng := n.requestRNG(req)
+			for range i {
+				rng.Float64() // A different decision within the session each time
+			}
+			ids[i] = (TrustWeightedScheduler{}).Select(miners, rng).ID
+		}
+		return ids
+	}
+
+	want := picks("session-a")
+	if got := picks("session-a"); !slices.Equal(got, want) {
+		t.Errorf("picks for session-a = %v, want %v", got, want)
+	}
+	if got := picks("session-b"); slices.Equal(got, want) {
+		t.Errorf("picks for sessions a and b are both %v, want them to diverge", got)
+	}
+}
+
+func TestInRegion(t *testing.T) {
+	miners := []*MinerInfo{{ID: "a", Region: "us-east"}, {ID: "b", Region: "EU-West"}, {ID: "c"}, {ID: "d", Region: "eu-west"}}
+	tests := []struct {
+		region string
+		want   []string
+	}{
+		{"eu-west", []string{"b", "d"}},
+		{"US-EAST", []string{"a"}},
+		{"ap-south", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, m := range inRegion(miners, tt.region) {
+			got = append(got, m.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("inRegion(%q) = %v, want %v", tt.region, got, tt.want)
+		}
+	}
+}
+
+func TestLeastLoadedUsesCapacity(t *testing.T) {
+	tests := []struct {
+		name   string
+		miners []*MinerInfo
+		want   string
+	}{
+		{"fewest active without capacity", []*MinerInfo{
+			{ID: "a", ActiveTasks: 2},
+			{ID: "b", ActiveTasks: 1},
+		}, "b"},
+		{"faster miner despite queue", []*MinerInfo{
+			{ID: "a", ActiveTasks: 1, CapacityTPS: 20},
+			{ID: "b", ActiveTasks: 2, CapacityTPS: 100},
+		}, "b"},
+		{"mixed capacity falls back to active tasks", []*MinerInfo{
+			{ID: "a", ActiveTasks: 1, CapacityTPS: 20},
+			{ID: "b", ActiveTasks: 2},
+		}, "a"},
+		{"trust breaks ties", []*MinerInfo{
+			{ID: "a", ActiveTasks: 1, CapacityTPS: 50, TrustScore: 10},
+			{ID: "b", ActiveTasks: 1, CapacityTPS: 50, TrustScore: 90},
+		}, "b"},
+	}
+	for _, tt := range tests {
+		if got := (LeastLoadedScheduler{}).Select(tt.miners, nil); got.ID != tt.want {
+			t.Errorf("%s: Select() = %s, want %s", tt.name, got.ID, tt.want)
+		}
+	}
+}
+
+func TestEstimateCompletion(t *testing.T) {
+	if _, ok := estimateCompletion(&MinerInfo{}, 100); ok {
+		t.Error("estimateCompletion() without capacity should report false")
+	}
+	m := &MinerInfo{ActiveTasks: 1, CapacityTPS: 100}
+	got, ok := estimateCompletion(m, 44)
+	if want := 3 * time.Second; !ok || got != want {
+		t.Errorf("estimateCompletion() = %v, %v, want %v, true", got, ok, want)
+	}
+}
+
+func TestSchedulerSeed(t *testing.T) {
+	miners := make([]*MinerInfo, 10)
+	for i := range miners {
+		miners[i] = &MinerInfo{ID: fmt.Sprintf("m%d", i), TrustScore: 50}
+	}
+	picks := func(seed int64) []string {
+		n := NewAINode(Config{SchedulerSeed: seed})
+		ids := make([]string, 20)
+		for i := range ids {
+			ids[i] = (TrustWeightedScheduler{}).Select(miners, n.schedulerRNG()).ID
+		}
+		return ids
+	}
+
+	want := picks(42)
+	if got := picks(42); !slices.Equal(got, want) {
+		t.Errorf("picks with seed 42 = %v, want %v", got, want)
+	}
+	if got := picks(43); slices.Equal(got, want) {
+		t.Errorf("picks with seeds 42 and 43 are both %v, want them to diverge", got)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/luxfi/ai/internal/minersig"
+)
+
+// resultSkew is how far a result's signed_at may be from the node's clock
+const resultSkew = 2 * time.Minute
+
+var (
+	errUnsignedResult   = errors.New("result signature required")
+	errInvalidSignature = errors.New("invalid result signature")
+	errStaleResult      = errors.New("result signed_at is too far from the node's clock")
+)
+
+// verifyResultLocked checks a completed submission's signature and reports
+// whether the result is signed. An attempt assigned to a miner that had
+// registered a public key must be signed with that key within resultSkew of
+// now, even if the miner has since left or changed its key; other attempts
+// may submit unsigned results. Caller holds n.mu.
+func (n *AINode) verifyResultLocked(t *Task, output json.RawMessage, sub *Task) (bool, error) {
+	if len(t.assignedKey) == 0 {
+		return false, nil
+	}
+	if len(sub.Signature) == 0 || sub.SignedAt.IsZero() {
+		return false, errUnsignedResult
+	}
+	if skew := n.clock.Now().Sub(sub.SignedAt); skew > resultSkew || skew < -resultSkew {
+		return false, errStaleResult
+	}
+	digest := minersig.ResultDigest(t.ID, output, sub.SignedAt)
+	if !ed25519.Verify(t.assignedKey, digest[:], sub.Signature) {
+		return false, errInvalidSignature
+	}
+	return true, nil
+}
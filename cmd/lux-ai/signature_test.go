@@ -0,0 +1,117 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/internal/minersig"
+	"github.com/luxfi/ai/pkg/clock"
+)
+
+func TestRegisterRejectsMalformedPublicKey(t *testing.T) {
+	n := NewAINode(Config{})
+	body := `{"id":"m","public_key":"AAEC"}`
+	rec := httptest.NewRecorder()
+	n.handleMinerRegister(rec, httptest.NewRequest("POST", "/api/miners/register", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestSubmitSignedResult(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	_, otherPriv, _ := ed25519.GenerateKey(nil)
+	output := json.RawMessage(`{"content": "hello"}`)
+	signedAt := time.Now()
+	stale := signedAt.Add(-resultSkew - time.Second)
+	staleSig := minersig.ResultDigest("t1", output, stale)
+	sign := func(key ed25519.PrivateKey, taskID string) []byte {
+		digest := minersig.ResultDigest(taskID, output, signedAt)
+		return ed25519.Sign(key, digest[:])
+	}
+
+	tests := []struct {
+		name      string
+		key       ed25519.PublicKey
+		signature []byte
+		signedAt  time.Time
+		status    int
+	}{
+		{"valid", pub, sign(priv, "t1"), signedAt, http.StatusOK},
+		{"keyless miner unsigned", nil, nil, time.Time{}, http.StatusOK},
+		{"unsigned", pub, nil, time.Time{}, http.StatusUnauthorized},
+		{"missing timestamp", pub, sign(priv, "t1"), time.Time{}, http.StatusUnauthorized},
+		{"wrong key", pub, sign(otherPriv, "t1"), signedAt, http.StatusUnauthorized},
+		{"other task", pub, sign(priv, "t2"), signedAt, http.StatusUnauthorized},
+		{"other timestamp", pub, sign(priv, "t1"), signedAt.Add(time.Second), http.StatusUnauthorized},
+		{"stale", pub, ed25519.Sign(priv, staleSig[:]), stale, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{})
+			n.clock = clock.NewMock(signedAt)
+			n.miners["m"] = &MinerInfo{ID: "m", PublicKey: tt.key, ActiveTasks: 1}
+			n.tokens["m"] = "tok"
+			n.tasks["t1"] = &Task{ID: "t1", Status: TaskRunning, AssignedTo: "m", assignedKey: tt.key}
+
+			body, _ := json.Marshal(&Task{
+				ID:        "t1",
+				Status:    TaskCompleted,
+				Output:    output,
+				Signature: tt.signature,
+				SignedAt:  tt.signedAt,
+			})
+			req := httptest.NewRequest("POST", "/api/tasks/submit", bytes.NewReader(body))
+			req.Header.Set("Authorization", "Bearer tok")
+			rec := httptest.NewRecorder()
+			n.handleSubmitResult(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+
+			task := n.tasks["t1"]
+			if tt.status != http.StatusOK {
+				if task.Status != TaskRunning || task.Output != nil {
+					t.Errorf("rejected result was applied: %+v", task)
+				}
+				return
+			}
+			if task.Status != TaskCompleted {
+				t.Fatalf("status = %s, want completed", task.Status)
+			}
+			if signed := tt.key != nil; signed != (task.SignedBy == "m") || !bytes.Equal(task.Signature, tt.signature) {
+				t.Errorf("signature = %x by %q, want %x by m", task.Signature, task.SignedBy, tt.signature)
+			}
+		})
+	}
+}
+
+func TestSubmitRequiresSignatureOfAssignedKey(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	n := NewAINode(Config{})
+	n.miners["m"] = &MinerInfo{ID: "m", PublicKey: pub}
+	n.tokens["m"] = "tok"
+	task := &Task{ID: "t1", Model: "zen-mini-0.5b"}
+	n.tasks[task.ID] = task
+	n.assignLocked(task, n.miners["m"], TaskRunning)
+
+	// Dropping the key after assignment does not waive the signature
+	n.miners["m"].PublicKey = nil
+	req := httptest.NewRequest("POST", "/api/tasks/submit", strings.NewReader(`{"id":"t1","status":"completed","output":{}}`))
+	req.Header.Set("Authorization", "Bearer tok")
+	rec := httptest.NewRecorder()
+	n.handleSubmitResult(rec, req)
+	if rec.Code != http.StatusUnauthorized || task.Status != TaskRunning {
+		t.Errorf("unsigned result: status %d, task %s; want 401 and still running", rec.Code, task.Status)
+	}
+}
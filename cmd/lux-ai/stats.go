@@ -0,0 +1,65 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Stats is a point-in-time view of the node's counters, served by
+// /api/stats
+type Stats struct {
+	MinersConnected int `json:"miners_connected"`
+	ModelsAvailable int `json:"models_available"`
+
+	TasksTotal     int `json:"tasks_total"` // Tasks held, in any status
+	TasksPending   int `json:"tasks_pending"`
+	TasksRunning   int `json:"tasks_running"` // Assigned to a miner and not yet finished
+	TasksCompleted int `json:"tasks_completed"`
+	TasksFailed    int `json:"tasks_failed"` // Failed attempts, including ones later retried
+	TasksDead      int `json:"tasks_dead"`
+	TasksCancelled int `json:"tasks_cancelled"`
+
+	ModelsInflight map[string]InflightStats `json:"models_inflight"`
+	TaskCompaction CompactionStats          `json:"task_compaction"`
+}
+
+// StatsSnapshot assembles every counter under a single acquisition of
+// n.mu, so the totals agree with each other even while tasks change.
+// Add new counters here rather than reading them separately in handlers.
+func (n *AINode) StatsSnapshot() Stats {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	s := Stats{
+		MinersConnected: len(n.miners),
+		ModelsAvailable: len(n.models),
+		ModelsInflight:  n.inflight.Snapshot(),
+		TasksTotal:      len(n.tasks),
+		TaskCompaction:  n.compaction,
+	}
+	for _, t := range n.tasks {
+		s.TasksFailed += len(t.Failures)
+		switch t.Status {
+		case TaskPending:
+			s.TasksPending++
+		case TaskAssigned, TaskRunning:
+			s.TasksRunning++
+		case TaskCompleted:
+			s.TasksCompleted++
+		case TaskDead:
+			s.TasksDead++
+		case TaskCancelled:
+			s.TasksCancelled++
+		}
+	}
+	return s
+}
+
+// handleStats returns node statistics
+func (n *AINode) handleStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(n.StatsSnapshot())
+}
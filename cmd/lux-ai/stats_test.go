@@ -0,0 +1,85 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestStatsSnapshotUnderLoad reads stats while tasks are dispatched,
+// claimed, completed, cancelled and compacted. Run with -race.
+func TestStatsSnapshotUnderLoad(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := NewAINode(Config{})
+	runFakeMiner(ctx, n, "done")
+	mux := n.newMux()
+
+	const tasks = 200
+	var writers sync.WaitGroup
+	writers.Add(2)
+	go func() {
+		defer writers.Done()
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < tasks; i++ {
+			task, err := n.dispatch(rng, "", "", "chat", "zen-mini-0.5b", json.RawMessage(`{}`))
+			if err != nil {
+				t.Errorf("dispatch: %v", err)
+				return
+			}
+			if i%3 == 0 {
+				n.cancelTimedOut(task.ID)
+			}
+		}
+	}()
+	go func() {
+		defer writers.Done()
+		for i := 0; i < tasks; i++ {
+			n.sweepStale(n.clock.Now())
+			n.compactTasks(n.clock.Now())
+		}
+	}()
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				s := n.StatsSnapshot()
+				if sum := s.TasksPending + s.TasksRunning + s.TasksCompleted + s.TasksDead + s.TasksCancelled; sum != s.TasksTotal {
+					t.Errorf("task statuses sum to %d, want tasks_total %d", sum, s.TasksTotal)
+					return
+				}
+
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/stats", nil))
+				var served Stats
+				if err := json.NewDecoder(rec.Body).Decode(&served); err != nil {
+					t.Errorf("decode /api/stats: %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	writers.Wait()
+	close(done)
+	readers.Wait()
+
+	if s := n.StatsSnapshot(); s.TasksTotal != tasks || s.MinersConnected != 1 {
+		t.Errorf("final stats = %+v, want %d tasks from 1 miner", s, tasks)
+	}
+}
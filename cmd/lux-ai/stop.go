@@ -0,0 +1,98 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/luxfi/ai/pkg/miner/backend"
+)
+
+// defaultMaxStopSequences caps stop on chat requests when
+// Config.MaxStopSequences is unset
+const defaultMaxStopSequences = 4
+
+// StopSequences is the OpenAI stop parameter. It accepts a single string or
+// an array of strings and always marshals as an array.
+type StopSequences []string
+
+// UnmarshalJSON accepts "stop": "x", "stop": ["x", "y"] and "stop": null
+func (s *StopSequences) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		if single == "" {
+			*s = nil
+		} else {
+			*s = StopSequences{single}
+		}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("stop must be a string or an array of strings")
+	}
+	*s = list
+	return nil
+}
+
+// maxStopSequences returns the configured cap on a chat request's stop
+func (n *AINode) maxStopSequences() int {
+	if n.config.MaxStopSequences > 0 {
+		return n.config.MaxStopSequences
+	}
+	return defaultMaxStopSequences
+}
+
+// validateStop rejects more stop sequences than the node allows, and empty
+// ones which would match everywhere
+func (n *AINode) validateStop(stop StopSequences) error {
+	if len(stop) > n.maxStopSequences() {
+		return fmt.Errorf("stop may contain at most %d sequences", n.maxStopSequences())
+	}
+	for _, seq := range stop {
+		if seq == "" {
+			return fmt.Errorf("stop sequences must not be empty")
+		}
+	}
+	return nil
+}
+
+// chatCompletion is one completed choice from a chat task output
+type chatCompletion struct {
+	Content      string `json:"content"`
+	FinishReason string `json:"finish_reason"`
+}
+
+// parseChatOutput extracts a completion from a chat task output. Stop
+// sequences are re-applied in case the miner's backend ignored them, and
+// the finish_reason is resolved to stop or length (see
+// backend.ResolveFinishReason).
+func parseChatOutput(out json.RawMessage, stop StopSequences) (chatCompletion, error) {
+	var c chatCompletion
+	if err := json.Unmarshal(out, &c); err != nil {
+		return chatCompletion{}, err
+	}
+	content, stopped := backend.TruncateAtStop(c.Content, stop)
+	c.Content = content
+	c.FinishReason = backend.ResolveFinishReason(c.FinishReason, stopped)
+	return c, nil
+}
+
+// stopPrefixLen returns the length of the longest suffix of content that
+// begins a stop sequence. Streaming holds those bytes back until the next
+// chunk shows whether the sequence completes.
+func stopPrefixLen(content string, stop StopSequences) int {
+	longest := 0
+	for _, seq := range stop {
+		for l := len(seq) - 1; l > longest; l-- {
+			if strings.HasSuffix(content, seq[:l]) {
+				longest = l
+				break
+			}
+		}
+	}
+	return longest
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestStopSequencesUnmarshal(t *testing.T) {
+	tests := []struct {
+		body    string
+		want    StopSequences
+		wantErr bool
+	}{
+		{`{"stop":"END"}`, StopSequences{"END"}, false},
+		{`{"stop":["a","b"]}`, StopSequences{"a", "b"}, false},
+		{`{"stop":""}`, nil, false},
+		{`{"stop":null}`, nil, false},
+		{`{}`, nil, false},
+		{`{"stop":5}`, nil, true},
+	}
+	for _, tt := range tests {
+		var req ChatRequest
+		err := json.Unmarshal([]byte(tt.body), &req)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.body, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(req.Stop, tt.want) {
+			t.Errorf("Unmarshal(%s) stop = %q, want %q", tt.body, req.Stop, tt.want)
+		}
+	}
+}
+
+func TestParseChatOutput(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		stop       StopSequences
+		wantText   string
+		wantReason string
+	}{
+		{"default stop", `{"content":"hi"}`, nil, "hi", "stop"},
+		{"length kept", `{"content":"hi","finish_reason":"length"}`, nil, "hi", "length"},
+		{"truncated at stop", `{"content":"one\ntwo END three"}`, StopSequences{"END", "\n"}, "one", "stop"},
+		{"stop overrides length", `{"content":"a.b","finish_reason":"length"}`, StopSequences{"."}, "a", "stop"},
+	}
+	for _, tt := range tests {
+		c, err := parseChatOutput(json.RawMessage(tt.output), tt.stop)
+		if err != nil {
+			t.Fatalf("%s: parseChatOutput: %v", tt.name, err)
+		}
+		if c.Content != tt.wantText || c.FinishReason != tt.wantReason {
+			t.Errorf("%s: parseChatOutput() = (%q, %q), want (%q, %q)", tt.name, c.Content, c.FinishReason, tt.wantText, tt.wantReason)
+		}
+	}
+}
+
+func TestStopPrefixLen(t *testing.T) {
+	tests := []struct {
+		content string
+		stop    StopSequences
+		want    int
+	}{
+		{"hello", StopSequences{"END"}, 0},
+		{"hello E", StopSequences{"END"}, 1},
+		{"hello EN", StopSequences{"END", "NX"}, 2},
+		{"hello", nil, 0},
+	}
+	for _, tt := range tests {
+		if got := stopPrefixLen(tt.content, tt.stop); got != tt.want {
+			t.Errorf("stopPrefixLen(%q, %q) = %d, want %d", tt.content, tt.stop, got, tt.want)
+		}
+	}
+}
+
+func TestChatStop(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		body        string
+		wantStatus  int
+		wantContent string
+	}{
+		{"string", Config{}, `{"messages":[],"stop":"END"}`, http.StatusOK, "answer "},
+		{"array", Config{}, `{"messages":[],"stop":["x","ans"]}`, http.StatusOK, ""},
+		{"too many", Config{}, `{"messages":[],"stop":["a","b","c","d","e"]}`, http.StatusBadRequest, ""},
+		{"configured cap", Config{MaxStopSequences: 1}, `{"messages":[],"stop":["a","b"]}`, http.StatusBadRequest, ""},
+		{"empty sequence", Config{}, `{"messages":[],"stop":["a",""]}`, http.StatusBadRequest, ""},
+		{"bad type", Config{}, `{"messages":[],"stop":{}}`, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			n := NewAINode(tt.config)
+			runFakeMiner(ctx, n, "answer END ignored")
+
+			rec := chatRequest(t, n, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp ChatResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if got := resp.Choices[0].Message.Content; got != tt.wantContent {
+				t.Errorf("content = %q, want %q", got, tt.wantContent)
+			}
+			if got := resp.Choices[0].FinishReason; got != "stop" {
+				t.Errorf("finish_reason = %q, want stop", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luxfi/ai/pkg/miner/backend"
+)
+
+// ChatDelta is the incremental message content in a ChatChunk
+type ChatDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// ChatChunkChoice is one choice in a streamed ChatChunk
+type ChatChunkChoice struct {
+	Index        int       `json:"index"`
+	Delta        ChatDelta `json:"delta"`
+	FinishReason *string   `json:"finish_reason"`
+}
+
+// ChatChunk is an OpenAI-compatible chat.completion.chunk server-sent event
+type ChatChunk struct {
+	ID      string            `json:"id"`
+	Object  string            `json:"object"`
+	Created int64             `json:"created"`
+	Model   string            `json:"model"`
+	Choices []ChatChunkChoice `json:"choices"`
+}
+
+// sseWriter emits chat.completion.chunk events for a single choice
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	id      string
+	model   string
+	created int64
+}
+
+func (s *sseWriter) event(v interface{}) {
+	data, _ := json.Marshal(v)
+	fmt.Fprintf(s.w, "data: %s\n\n", data)
+	s.flusher.Flush()
+}
+
+func (s *sseWriter) chunk(delta ChatDelta, finishReason *string) {
+	s.event(ChatChunk{
+		ID:      s.id,
+		Object:  "chat.completion.chunk",
+		Created: s.created,
+		Model:   s.model,
+		Choices: []ChatChunkChoice{{Delta: delta, FinishReason: finishReason}},
+	})
+}
+
+func (s *sseWriter) fail(msg string) {
+	s.event(map[string]interface{}{
+		"error": map[string]string{"message": msg},
+	})
+}
+
+func (s *sseWriter) done(finishReason string) {
+	s.chunk(ChatDelta{}, &finishReason)
+	fmt.Fprint(s.w, "data: [DONE]\n\n")
+	s.flusher.Flush()
+}
+
+// startSSE begins a chat.completion.chunk stream with the assistant role
+func startSSE(w http.ResponseWriter, flusher http.Flusher, model string) *sseWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	s := &sseWriter{
+		w:       w,
+		flusher: flusher,
+		id:      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
+		model:   model,
+		created: time.Now().Unix(),
+	}
+	s.chunk(ChatDelta{Role: "assistant"}, nil)
+	return s
+}
+
+// synthesizeStream answers a streamed request for a model whose miners
+// can't stream. The completion is generated as for a non-streamed request,
+// so failures still get an HTTP status, and is then sent as a single chunk.
+func (n *AINode) synthesizeStream(w http.ResponseWriter, r *http.Request, req *ChatRequest, input json.RawMessage, placeholder string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	c := chatCompletion{Content: placeholder, FinishReason: backend.FinishReasonStop}
+	outputs, err := n.generate(r, "chat", req.Model, input, 1)
+	switch {
+	case errors.Is(err, errNoMiners):
+	case err != nil:
+		writeGenerateError(w, err)
+		return
+	default:
+		if c, err = parseChatOutput(outputs[0], req.Stop); err != nil {
+			http.Error(w, "invalid miner output", http.StatusBadGateway)
+			return
+		}
+	}
+
+	s := startSSE(w, flusher, req.Model)
+	if c.Content != "" {
+		s.chunk(ChatDelta{Content: c.Content}, nil)
+	}
+	s.done(c.FinishReason)
+	n.recordUsage(r, req.Model, estimatePromptTokens(req), estimateTokens(c.Content))
+}
+
+// streamChat dispatches a single chat task and relays the miner's chunks
+// (see /api/tasks/append) to the client as server-sent events. placeholder
+// is streamed when no miner is connected. Partial output is held back while
+// it could still be the start of a stop sequence. Usage is metered once the
+// final chunk is sent, like a non-streamed completion.
+func (n *AINode) streamChat(w http.ResponseWriter, r *http.Request, req *ChatRequest, input json.RawMessage, placeholder string) {
+	model, stop := req.Model, req.Stop
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	task, err := n.dispatch(n.requestRNG(r), requestRegion(r), taskOwner(r), "chat", model, input)
+	if unqualified(err) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil && !errors.Is(err, errNoMiners) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s := startSSE(w, flusher, model)
+
+	if err != nil {
+		s.chunk(ChatDelta{Content: placeholder}, nil)
+		s.done(backend.FinishReasonStop)
+		n.recordUsage(r, model, estimatePromptTokens(req), estimateTokens(placeholder))
+		return
+	}
+	defer n.inflight.Acquire(model)()
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeoutFrom(r.Context()))
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	sent := 0
+	var streamed strings.Builder
+	attempt := task.Attempts
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				n.cancelTimedOut(task.ID)
+			}
+			s.fail("timeout waiting for miner")
+			return
+		case <-ticker.C:
+		}
+
+		n.mu.RLock()
+		t, ok := n.tasks[task.ID]
+		var snapshot Task
+		if ok {
+			snapshot = *t
+		}
+		n.mu.RUnlock()
+
+		switch {
+		case !ok:
+			s.fail(errTaskFailed.Error())
+			return
+		case snapshot.Status == TaskDead:
+			s.fail(fmt.Sprintf("%s: %s", errTaskDead, strings.Join(snapshot.Failures, "; ")))
+			return
+		case snapshot.Status == TaskCancelled:
+			s.fail(errTaskCancelled.Error())
+			return
+		case snapshot.Attempts != attempt:
+			// Output already sent can't be retracted, so a retry is only
+			// transparent before the first chunk
+			if sent > 0 {
+				s.fail("miner failed mid-stream")
+				return
+			}
+			attempt = snapshot.Attempts
+		}
+
+		content, stopped := backend.TruncateAtStop(snapshot.Partial, stop)
+		finishReason := backend.FinishReasonStop
+		if snapshot.Status == TaskCompleted {
+			// The final chunk carries the miner's finish reason even if its
+			// output can't extend what was already streamed
+			if c, err := parseChatOutput(snapshot.Output, stop); err == nil {
+				finishReason = c.FinishReason
+				if len(c.Content) >= sent {
+					content = c.Content
+				}
+			}
+		} else if !stopped {
+			content = content[:len(content)-stopPrefixLen(content, stop)]
+		}
+		if len(content) > sent {
+			s.chunk(ChatDelta{Content: content[sent:]}, nil)
+			streamed.WriteString(content[sent:])
+			sent = len(content)
+		}
+		if snapshot.Status == TaskCompleted {
+			s.done(finishReason)
+			n.recordUsage(r, model, estimatePromptTokens(req), estimateTokens(streamed.String()))
+			return
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// sseChunks decodes the chat.completion.chunk events in an SSE body
+func sseChunks(t *testing.T, body string) []ChatChunk {
+	t.Helper()
+	var chunks []ChatChunk
+	for _, line := range strings.Split(body, "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var c ChatChunk
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			t.Fatalf("decode %q: %v", data, err)
+		}
+		chunks = append(chunks, c)
+	}
+	return chunks
+}
+
+func TestStreamNonStreamingModel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := NewAINode(Config{})
+	n.models["batch-only"] = &ModelInfo{ID: "batch-only", Name: "Batch Only", Type: "chat", ContextSize: 8192}
+	runFakeMiner(ctx, n, "whole answer")
+
+	rec := chatRequest(t, n, `{"model":"batch-only","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+	if !strings.HasSuffix(rec.Body.String(), "data: [DONE]\n\n") {
+		t.Errorf("stream not terminated with [DONE]: %q", rec.Body)
+	}
+
+	chunks := sseChunks(t, rec.Body.String())
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want role, content and finish", len(chunks))
+	}
+	if got := chunks[1].Choices[0].Delta.Content; got != "whole answer" {
+		t.Errorf("content chunk = %q, want the whole completion", got)
+	}
+	if fr := chunks[2].Choices[0].FinishReason; fr == nil || *fr != "stop" {
+		t.Errorf("finish_reason = %v, want stop", fr)
+	}
+}
+
+func TestStreamNonStreamingModelNoMiner(t *testing.T) {
+	n := NewAINode(Config{})
+	n.models["batch-only"] = &ModelInfo{ID: "batch-only", Name: "Batch Only", Type: "chat", ContextSize: 8192}
+	n.miners["small"] = &MinerInfo{ID: "small", GPUMemoryMB: 1024}
+	n.models["batch-only"].MinVRAMGB = 24
+
+	// Failures before any output still get an HTTP status
+	rec := chatRequest(t, n, `{"model":"batch-only","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestDefaultModelsStream(t *testing.T) {
+	for id, m := range defaultModels() {
+		if !m.Streaming {
+			t.Errorf("default model %s does not declare streaming", id)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Task timeline events, in the order a task normally passes through them
+const (
+	EventCreated    = "created"
+	EventQueued     = "queued"   // Waiting for a qualified miner
+	EventAssigned   = "assigned" // Detail: the attempt number
+	EventRunning    = "running"  // Claimed by its miner
+	EventFirstChunk = "first_chunk"
+	EventCompleted  = "completed"
+	EventFailed     = "failed" // One attempt failed; detail: the reason
+	EventDead       = "dead"
+	EventCancelled  = "cancelled" // Detail: who or what cancelled it
+)
+
+// TaskEvent is one lifecycle transition of a task
+type TaskEvent struct {
+	Event  string    `json:"event"`
+	Time   time.Time `json:"time"`
+	Miner  string    `json:"miner,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// recordLocked appends an event to t's timeline, attributed to the miner
+// holding the task. Caller holds n.mu.
+func (n *AINode) recordLocked(t *Task, event, detail string) {
+	t.Timeline = append(t.Timeline, TaskEvent{
+		Event:  event,
+		Time:   n.clock.Now(),
+		Miner:  t.AssignedTo,
+		Detail: detail,
+	})
+}
+
+// TaskTimeline is the response of /api/tasks/{id}/timeline
+type TaskTimeline struct {
+	ID     string      `json:"id"`
+	Model  string      `json:"model"`
+	Status string      `json:"status"`
+	Events []TaskEvent `json:"events"`
+}
+
+// handleTaskTimeline serves GET /api/tasks/{id}/timeline, listing every
+// lifecycle event of a task with its timestamp and miner
+func (n *AINode) handleTaskTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n.mu.RLock()
+	t, ok := n.tasks[r.PathValue("id")]
+	var timeline TaskTimeline
+	if ok {
+		timeline = TaskTimeline{
+			ID:     t.ID,
+			Model:  t.Model,
+			Status: t.Status,
+			Events: append([]TaskEvent{}, t.Timeline...),
+		}
+	}
+	n.mu.RUnlock()
+	if !ok {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(timeline)
+}
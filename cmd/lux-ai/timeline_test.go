@@ -0,0 +1,105 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/clock"
+)
+
+func TestTaskTimeline(t *testing.T) {
+	mock := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	n := NewAINode(Config{DataDir: t.TempDir(), MaxRetries: 1})
+	n.clock = mock
+	for _, id := range []string{"m1", "m2"} {
+		n.miners[id] = &MinerInfo{ID: id, Models: []string{"zen-mini-0.5b"}}
+		n.tokens[id] = "tok-" + id
+	}
+	post := func(path, token, body string) {
+		t.Helper()
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		n.newMux().ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("POST %s = %d: %s", path, rec.Code, rec.Body)
+		}
+	}
+
+	task, err := n.dispatch(nil, "", "", "chat", "zen-mini-0.5b", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+	first := task.AssignedTo
+	mock.Advance(time.Second)
+	n.mu.Lock()
+	n.claimTasksLocked(first)
+	n.mu.Unlock()
+	mock.Advance(time.Second)
+	post("/api/tasks/submit", "tok-"+first, `{"id":"`+task.ID+`","status":"failed","error":"out of memory"}`)
+	second := task.AssignedTo
+	mock.Advance(time.Second)
+	n.mu.Lock()
+	n.claimTasksLocked(second)
+	n.mu.Unlock()
+	mock.Advance(time.Second)
+	post("/api/tasks/append", "tok-"+second, `{"id":"`+task.ID+`","seq":0,"content":"Hel"}`)
+	post("/api/tasks/append", "tok-"+second, `{"id":"`+task.ID+`","seq":1,"content":"lo"}`)
+	mock.Advance(time.Second)
+	post("/api/tasks/submit", "tok-"+second, `{"id":"`+task.ID+`","status":"completed","chunks":2}`)
+
+	rec := httptest.NewRecorder()
+	n.newMux().ServeHTTP(rec, httptest.NewRequest("GET", "/api/tasks/"+task.ID+"/timeline", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("timeline status = %d: %s", rec.Code, rec.Body)
+	}
+	var got TaskTimeline
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != task.ID || got.Status != TaskCompleted {
+		t.Errorf("timeline = %s %s, want %s completed", got.ID, got.Status, task.ID)
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := []TaskEvent{
+		{Event: EventCreated, Time: start},
+		{Event: EventAssigned, Time: start, Miner: first, Detail: "attempt 1"},
+		{Event: EventRunning, Time: start.Add(time.Second), Miner: first},
+		{Event: EventFailed, Time: start.Add(2 * time.Second), Miner: first, Detail: "out of memory"},
+		{Event: EventAssigned, Time: start.Add(2 * time.Second), Miner: second, Detail: "attempt 2"},
+		{Event: EventRunning, Time: start.Add(3 * time.Second), Miner: second},
+		{Event: EventFirstChunk, Time: start.Add(4 * time.Second), Miner: second},
+		{Event: EventCompleted, Time: start.Add(5 * time.Second), Miner: second},
+	}
+	if len(got.Events) != len(want) {
+		t.Fatalf("events = %+v, want %+v", got.Events, want)
+	}
+	for i := range want {
+		if e := got.Events[i]; e.Event != want[i].Event || !e.Time.Equal(want[i].Time) || e.Miner != want[i].Miner || e.Detail != want[i].Detail {
+			t.Errorf("event %d = %+v, want %+v", i, e, want[i])
+		}
+	}
+
+	for _, tt := range []struct {
+		method, id string
+		want       int
+	}{
+		{"GET", "missing", http.StatusNotFound},
+		{"POST", task.ID, http.StatusMethodNotAllowed},
+	} {
+		rec := httptest.NewRecorder()
+		n.newMux().ServeHTTP(rec, httptest.NewRequest(tt.method, "/api/tasks/"+tt.id+"/timeline", nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s timeline = %d, want %d", tt.method, tt.id, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeoutHeader carries an optional per-request wait budget: how long the
+// client will wait for each of its tasks, in seconds ("90", "2.5") or as a
+// Go duration ("90s"). Values above the server maximum are clamped to it.
+// The effective timeout is echoed in the response's TimeoutHeader.
+const TimeoutHeader = "X-Lux-Timeout"
+
+// defaultMaxRequestTimeout caps TimeoutHeader when Config.MaxRequestTimeout
+// is unset
+const defaultMaxRequestTimeout = 5 * time.Minute
+
+var errInvalidTimeout = errors.New("invalid timeout")
+
+type timeoutKey struct{}
+
+// maxRequestTimeout returns the ceiling on per-request timeouts
+func (n *AINode) maxRequestTimeout() time.Duration {
+	if n.config.MaxRequestTimeout > 0 {
+		return n.config.MaxRequestTimeout
+	}
+	return defaultMaxRequestTimeout
+}
+
+// defaultRequestTimeout returns the timeout of requests without
+// TimeoutHeader, never more than the ceiling
+func (n *AINode) defaultRequestTimeout() time.Duration {
+	timeout := n.config.RequestTimeout
+	if timeout <= 0 {
+		timeout = dispatchTimeout
+	}
+	return min(timeout, n.maxRequestTimeout())
+}
+
+// requestTimeout returns the timeout r asked for, clamped to the ceiling,
+// or the default if it asked for none
+func (n *AINode) requestTimeout(r *http.Request) (time.Duration, error) {
+	v := strings.TrimSpace(r.Header.Get(TimeoutHeader))
+	if v == "" {
+		return n.defaultRequestTimeout(), nil
+	}
+	timeout, err := parseTimeout(v)
+	if err != nil {
+		return 0, err
+	}
+	return min(timeout, n.maxRequestTimeout()), nil
+}
+
+// parseTimeout parses seconds or a Go duration, which must be positive
+func parseTimeout(s string) (time.Duration, error) {
+	var timeout time.Duration
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		if secs > 0 && secs < time.Duration(1<<63-1).Seconds() {
+			timeout = time.Duration(secs * float64(time.Second))
+		}
+	} else if d, err := time.ParseDuration(s); err == nil {
+		timeout = d
+	} else {
+		return 0, fmt.Errorf("%w %q: want seconds or a duration such as 90s", errInvalidTimeout, s)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("%w %q: must be positive", errInvalidTimeout, s)
+	}
+	return timeout, nil
+}
+
+// timeoutMiddleware resolves the request's timeout, answering 400 if
+// TimeoutHeader is malformed, and passes it to the dispatch code through
+// the request context
+func (n *AINode) timeoutMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		timeout, err := n.requestTimeout(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set(TimeoutHeader, strconv.FormatFloat(timeout.Seconds(), 'f', -1, 64))
+		next(w, r.WithContext(context.WithValue(r.Context(), timeoutKey{}, timeout)))
+	}
+}
+
+// timeoutFrom returns the task timeout carried by ctx, dispatchTimeout if
+// none
+func timeoutFrom(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	return dispatchTimeout
+}
+
+// cancelTimedOut cancels task id once its client stopped waiting for it,
+// so no miner keeps working on a result nobody will read
+func (n *AINode) cancelTimedOut(id string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if t, ok := n.tasks[id]; ok && !t.finished() {
+		n.cancelTaskLocked(t, "request timed out")
+	}
+}
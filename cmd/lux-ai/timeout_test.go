@@ -0,0 +1,97 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		header  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"server default", Config{}, "", dispatchTimeout, false},
+		{"configured default", Config{RequestTimeout: time.Minute}, "", time.Minute, false},
+		{"default above ceiling", Config{RequestTimeout: time.Hour, MaxRequestTimeout: time.Minute}, "", time.Minute, false},
+		{"seconds", Config{}, "90", 90 * time.Second, false},
+		{"fractional seconds", Config{}, "2.5", 2500 * time.Millisecond, false},
+		{"duration", Config{}, "1m30s", 90 * time.Second, false},
+		{"clamped", Config{}, "3600", defaultMaxRequestTimeout, false},
+		{"clamped to configured ceiling", Config{MaxRequestTimeout: 10 * time.Second}, "20s", 10 * time.Second, false},
+		{"zero", Config{}, "0", 0, true},
+		{"negative", Config{}, "-5", 0, true},
+		{"garbage", Config{}, "soon", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(tt.config)
+			req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+			if tt.header != "" {
+				req.Header.Set(TimeoutHeader, tt.header)
+			}
+			got, err := n.requestTimeout(req)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, errInvalidTimeout)) {
+				t.Fatalf("requestTimeout() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("requestTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestTimeoutCancelsTask(t *testing.T) {
+	n := NewAINode(Config{})
+	n.miners["m"] = &MinerInfo{ID: "m"}
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions",
+		strings.NewReader(`{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimeoutHeader, "0.3")
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	n.newMux().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504", rec.Code)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request took %v, want about the 0.3s timeout", elapsed)
+	}
+	if got := rec.Header().Get(TimeoutHeader); got != "0.3" {
+		t.Errorf("%s = %q, want 0.3", TimeoutHeader, got)
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if len(n.tasks) != 1 {
+		t.Fatalf("tasks = %d, want 1", len(n.tasks))
+	}
+	for _, task := range n.tasks {
+		if task.Status != TaskCancelled {
+			t.Errorf("task status = %s, want %s", task.Status, TaskCancelled)
+		}
+	}
+	if n.miners["m"].ActiveTasks != 0 {
+		t.Errorf("ActiveTasks = %d, want 0 after cancellation", n.miners["m"].ActiveTasks)
+	}
+
+	bad := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{}`))
+	bad.Header.Set("Content-Type", "application/json")
+	bad.Header.Set(TimeoutHeader, "forever")
+	rec = httptest.NewRecorder()
+	n.newMux().ServeHTTP(rec, bad)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid timeout status = %d, want 400", rec.Code)
+	}
+}
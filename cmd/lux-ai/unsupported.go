@@ -0,0 +1,42 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// supportedRoutes lists the OpenAI-compatible routes this node serves,
+// reported to clients that call any other /v1 route
+var supportedRoutes = []string{
+	"/v1/chat/completions",
+	"/v1/models",
+	"/v1/embeddings",
+	"/v1/embeddings/batch",
+	"/v1/moderations",
+}
+
+// APIError is an OpenAI-style error body
+type APIError struct {
+	Error struct {
+		Message   string   `json:"message"`
+		Type      string   `json:"type"`
+		Supported []string `json:"supported_routes,omitempty"`
+	} `json:"error"`
+}
+
+// handleNotImplemented answers /v1 routes the node does not serve, such as
+// /v1/images/generations, with a parseable 501 instead of the mux's 404
+func (n *AINode) handleNotImplemented(w http.ResponseWriter, r *http.Request) {
+	var body APIError
+	body.Error.Message = fmt.Sprintf("%s %s is not implemented by this node", r.Method, r.URL.Path)
+	body.Error.Type = "not_implemented"
+	body.Error.Supported = supportedRoutes
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotImplemented)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,54 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNotImplementedRoutes(t *testing.T) {
+	mux := NewAINode(Config{}).newMux()
+
+	for _, path := range []string{"/v1/images/generations", "/v1/audio/transcriptions", "/v1/", "/v1/chat"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader("{}")))
+
+		if rec.Code != http.StatusNotImplemented {
+			t.Errorf("%s: status = %d, want 501", path, rec.Code)
+			continue
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s: Content-Type = %q, want application/json", path, ct)
+		}
+		var body APIError
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: decode: %v", path, err)
+		}
+		if body.Error.Type != "not_implemented" || !strings.Contains(body.Error.Message, path) {
+			t.Errorf("%s: error = %+v", path, body.Error)
+		}
+	}
+}
+
+func TestSupportedRoutesRegistered(t *testing.T) {
+	mux := NewAINode(Config{}).newMux()
+
+	for _, path := range supportedRoutes {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code == http.StatusNotImplemented {
+			t.Errorf("%s: status 501, want the route's own handler", path)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("/api/unknown: status = %d, want 404", rec.Code)
+	}
+}
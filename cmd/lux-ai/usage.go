@@ -0,0 +1,329 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// UsageFile is the file in DataDir that per-key usage is persisted to
+const UsageFile = "usage.json"
+
+const (
+	// usageBucket is the granularity usage is kept and queried at
+	usageBucket = time.Hour
+
+	// usageRetention is how long usage buckets are kept before rolling off
+	usageRetention = 90 * 24 * time.Hour
+
+	// usagePersistInterval is how often changed usage is written to UsageFile
+	usagePersistInterval = time.Minute
+
+	// maxUsageKeys caps how many keys are metered. Past it, requests from
+	// keys not yet metered go unrecorded until old ones roll off.
+	maxUsageKeys = 10000
+)
+
+// Usage accounting
+//
+// Completed chat completions, streamed or not, and embeddings, including
+// each embedding of a batch, are metered per API key in hourly buckets.
+// Keys are identified by keyID, a truncated SHA-256, so neither the usage
+// file nor /api/usage reveals them. Requests without an API key are not
+// metered, and when Config.KeyTiers lists the node's keys, neither are
+// requests with a key it does not list. Otherwise any key is metered, up
+// to maxUsageKeys of them, so made-up keys cannot grow the table without
+// bound.
+
+// UsageTotals are the requests and tokens metered for a key
+type UsageTotals struct {
+	Requests         uint64 `json:"requests"`
+	PromptTokens     uint64 `json:"prompt_tokens"`
+	CompletionTokens uint64 `json:"completion_tokens"`
+	TotalTokens      uint64 `json:"total_tokens"`
+
+	Models map[string]uint64 `json:"models,omitempty"` // Requests per model that served them
+}
+
+func (u *UsageTotals) add(o UsageTotals) {
+	u.Requests += o.Requests
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.TotalTokens += o.TotalTokens
+	for model, requests := range o.Models {
+		if u.Models == nil {
+			u.Models = make(map[string]uint64)
+		}
+		u.Models[model] += requests
+	}
+}
+
+// KeyUsage is one key's totals in a /api/usage response
+type KeyUsage struct {
+	Key string `json:"key"` // keyID of the API key
+	UsageTotals
+}
+
+// UsageReport is the /api/usage response
+type UsageReport struct {
+	Object string     `json:"object"`
+	From   time.Time  `json:"from,omitempty"`
+	To     time.Time  `json:"to,omitempty"`
+	Keys   []KeyUsage `json:"keys"`
+}
+
+// usageMeter holds usage per key ID and bucket start (Unix seconds). The
+// zero value is ready to use and all methods are safe for concurrent use.
+type usageMeter struct {
+	mu      sync.Mutex
+	buckets map[string]map[int64]*UsageTotals
+	dirty   bool // Changed since last saved
+}
+
+// keyID identifies an API key in usage records without revealing it
+func keyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "key-" + hex.EncodeToString(sum[:8])
+}
+
+// record adds one completed request for id at time at, served by model.
+// A new id is dropped once maxUsageKeys keys are metered.
+func (m *usageMeter) record(id string, at time.Time, model string, promptTokens, completionTokens int) {
+	prompt, completion := uint64(max(promptTokens, 0)), uint64(max(completionTokens, 0))
+	start := at.Truncate(usageBucket).Unix()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.buckets == nil {
+		m.buckets = make(map[string]map[int64]*UsageTotals)
+	}
+	key, ok := m.buckets[id]
+	if !ok {
+		if len(m.buckets) >= maxUsageKeys {
+			return
+		}
+		key = make(map[int64]*UsageTotals)
+		m.buckets[id] = key
+	}
+	b, ok := key[start]
+	if !ok {
+		b = &UsageTotals{}
+		key[start] = b
+	}
+	totals := UsageTotals{
+		Requests:         1,
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+	}
+	if model != "" {
+		totals.Models = map[string]uint64{model: 1}
+	}
+	b.add(totals)
+	m.dirty = true
+}
+
+// report sums the buckets overlapping [from, to) for id, or for every key
+// if id is empty. A zero from or to leaves that end open.
+func (m *usageMeter) report(id string, from, to time.Time) []KeyUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]KeyUsage, 0)
+	for kid, buckets := range m.buckets {
+		if id != "" && kid != id {
+			continue
+		}
+		usage := KeyUsage{Key: kid}
+		for start, b := range buckets {
+			end := time.Unix(start, 0).Add(usageBucket)
+			if !from.IsZero() && !end.After(from) {
+				continue
+			}
+			if !to.IsZero() && !time.Unix(start, 0).Before(to) {
+				continue
+			}
+			usage.add(*b)
+		}
+		if usage.Requests > 0 {
+			keys = append(keys, usage)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	return keys
+}
+
+// prune drops buckets that ended before cutoff
+func (m *usageMeter) prune(cutoff time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, buckets := range m.buckets {
+		for start := range buckets {
+			if !time.Unix(start, 0).Add(usageBucket).After(cutoff) {
+				delete(buckets, start)
+				m.dirty = true
+			}
+		}
+		if len(buckets) == 0 {
+			delete(m.buckets, id)
+		}
+	}
+}
+
+// load replaces the meter's contents with those saved at path. A missing
+// file leaves the meter empty.
+func (m *usageMeter) load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var buckets map[string]map[int64]*UsageTotals
+	if err := json.Unmarshal(data, &buckets); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.buckets = buckets
+	m.dirty = false
+	return nil
+}
+
+// save writes the meter to path if it changed since it was last saved or
+// loaded. The file is replaced atomically.
+func (m *usageMeter) save(path string) error {
+	m.mu.Lock()
+	if !m.dirty {
+		m.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(m.buckets)
+	m.dirty = false
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		m.markDirty()
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		m.markDirty()
+		return err
+	}
+	return nil
+}
+
+func (m *usageMeter) markDirty() {
+	m.mu.Lock()
+	m.dirty = true
+	m.mu.Unlock()
+}
+
+// usagePath returns where usage is persisted
+func (n *AINode) usagePath() string {
+	return filepath.Join(n.config.DataDir, UsageFile)
+}
+
+// recordUsage meters a completed request served by model against the
+// caller's API key, if it is one the node meters
+func (n *AINode) recordUsage(r *http.Request, model string, promptTokens, completionTokens int) {
+	key := apiKey(r)
+	if key == "" {
+		return
+	}
+	if _, known := n.config.KeyTiers[key]; len(n.config.KeyTiers) > 0 && !known {
+		return
+	}
+	n.usage.record(keyID(key), n.clock.Now(), model, promptTokens, completionTokens)
+}
+
+// persistUsageLoop rolls off expired usage and saves it every
+// usagePersistInterval, and once more when ctx is done
+func (n *AINode) persistUsageLoop(ctx context.Context) {
+	ticker := time.NewTicker(usagePersistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			n.usage.save(n.usagePath())
+			return
+		case now := <-ticker.C:
+			n.usage.prune(now.Add(-usageRetention))
+			n.usage.save(n.usagePath())
+		}
+	}
+}
+
+// handleUsage reports metered usage. The admin token may query any key, or
+// all keys, with ?key=<key ID>; an API key may only query its own usage.
+// ?from= and ?to= (RFC 3339) restrict the report to the hourly buckets
+// overlapping that range.
+func (n *AINode) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	id := q.Get("key")
+	switch {
+	case n.config.AdminToken != "" && validBearer(r, n.config.AdminToken):
+	case apiKey(r) != "":
+		own := keyID(apiKey(r))
+		if id != "" && id != own {
+			http.Error(w, "API keys may only query their own usage", http.StatusForbidden)
+			return
+		}
+		id = own
+	default:
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var from, to time.Time
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid "+p.name+": "+v, http.StatusBadRequest)
+			return
+		}
+		*p.t = t
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		http.Error(w, "to must be after from", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UsageReport{
+		Object: "usage",
+		From:   from,
+		To:     to,
+		Keys:   n.usage.report(id, from, to),
+	})
+}
@@ -0,0 +1,246 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+func TestUsageMeter(t *testing.T) {
+	var m usageMeter
+	base := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	m.record("a", base.Add(5*time.Minute), "", 10, 20)
+	m.record("a", base.Add(50*time.Minute), "", 1, 2)
+	m.record("a", base.Add(2*time.Hour), "", 100, 0)
+	m.record("b", base, "", 3, 4)
+
+	tests := []struct {
+		name     string
+		id       string
+		from, to time.Time
+		want     []KeyUsage
+	}{
+		{"all time", "", time.Time{}, time.Time{}, []KeyUsage{
+			{"a", UsageTotals{3, 111, 22, 133, nil}},
+			{"b", UsageTotals{1, 3, 4, 7, nil}},
+		}},
+		{"one key", "b", time.Time{}, time.Time{}, []KeyUsage{{"b", UsageTotals{1, 3, 4, 7, nil}}}},
+		{"first hour", "", base, base.Add(time.Hour), []KeyUsage{
+			{"a", UsageTotals{2, 11, 22, 33, nil}},
+			{"b", UsageTotals{1, 3, 4, 7, nil}},
+		}},
+		{"partial bucket overlaps", "a", base.Add(30 * time.Minute), base.Add(time.Hour), []KeyUsage{{"a", UsageTotals{2, 11, 22, 33, nil}}}},
+		{"later", "", base.Add(time.Hour), time.Time{}, []KeyUsage{{"a", UsageTotals{1, 100, 0, 100, nil}}}},
+		{"none", "", base.Add(5 * time.Hour), time.Time{}, []KeyUsage{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.report(tt.id, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("report() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Buckets that ended before the cutoff roll off
+	m.prune(base.Add(time.Hour))
+	want := []KeyUsage{{"a", UsageTotals{1, 100, 0, 100, nil}}}
+	if got := m.report("", time.Time{}, time.Time{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("report() after prune = %v, want %v", got, want)
+	}
+}
+
+func TestUsageMeterConcurrent(t *testing.T) {
+	var m usageMeter
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				m.record("k", time.Now(), "", 1, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := m.report("k", time.Time{}, time.Time{}); len(got) != 1 || got[0].Requests != 1000 || got[0].TotalTokens != 2000 {
+		t.Errorf("report() = %v, want 1000 requests and 2000 tokens", got)
+	}
+}
+
+func TestUsageMeterCap(t *testing.T) {
+	var m usageMeter
+	now := time.Now()
+	for i := range maxUsageKeys {
+		m.record(strconv.Itoa(i), now, "", 1, 1)
+	}
+	m.record("new", now, "", 1, 1)
+	m.record("0", now, "", 1, 1)
+	if got := m.report("new", time.Time{}, time.Time{}); len(got) != 0 {
+		t.Errorf("key past the cap was metered: %v", got)
+	}
+	if got := m.report("0", time.Time{}, time.Time{}); len(got) != 1 || got[0].Requests != 2 {
+		t.Errorf("metered key = %v, want 2 requests", got)
+	}
+
+	// Keys that roll off make room again
+	m.prune(now.Add(usageBucket))
+	m.record("new", now.Add(usageBucket), "", 1, 1)
+	if got := m.report("new", time.Time{}, time.Time{}); len(got) != 1 {
+		t.Errorf("key after prune = %v, want it metered", got)
+	}
+}
+
+func TestUsagePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), UsageFile)
+
+	var m usageMeter
+	if err := m.load(path); err != nil {
+		t.Fatalf("load() of missing file = %v", err)
+	}
+	m.record("k", time.Now(), "", 5, 6)
+	if err := m.save(path); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	var loaded usageMeter
+	if err := loaded.load(path); err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if got, want := loaded.report("", time.Time{}, time.Time{}), m.report("", time.Time{}, time.Time{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded report = %v, want %v", got, want)
+	}
+}
+
+func TestUsageEndpoint(t *testing.T) {
+	n := NewAINode(Config{AdminToken: "admin", KeyTiers: map[string]cc.CCTier{
+		"alice": cc.Tier4Standard,
+		"bob":   cc.Tier4Standard,
+	}})
+	body := `{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}]}`
+	// Keys the node does not list are served but not metered
+	for _, key := range []string{"alice", "alice", "bob", "", "made-up"} {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		n.handleChatCompletions(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("chat status = %d", rec.Code)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		query    string
+		status   int
+		wantKeys map[string]uint64 // key ID -> requests
+	}{
+		{"admin sees all keys", "admin", "", http.StatusOK, map[string]uint64{keyID("alice"): 2, keyID("bob"): 1}},
+		{"admin filters by key", "admin", "?key=" + keyID("bob"), http.StatusOK, map[string]uint64{keyID("bob"): 1}},
+		{"key sees its own", "alice", "", http.StatusOK, map[string]uint64{keyID("alice"): 2}},
+		{"key cannot see others", "alice", "?key=" + keyID("bob"), http.StatusForbidden, nil},
+		{"anonymous", "", "", http.StatusUnauthorized, nil},
+		{"future range", "admin", "?from=2999-01-01T00:00:00Z", http.StatusOK, map[string]uint64{}},
+		{"bad range", "admin", "?from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z", http.StatusBadRequest, nil},
+		{"bad time", "admin", "?from=yesterday", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/usage"+tt.query, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			n.handleUsage(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var report UsageReport
+			if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			got := make(map[string]uint64)
+			for _, k := range report.Keys {
+				got[k.Key] = k.Requests
+				if k.TotalTokens != k.PromptTokens+k.CompletionTokens || k.PromptTokens == 0 {
+					t.Errorf("%s tokens = %+v", k.Key, k.UsageTotals)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("requests per key = %v, want %v", got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestUsageMeteredStreamsAndBatches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := NewAINode(Config{})
+	n.models["batch-only"] = &ModelInfo{ID: "batch-only", Name: "Batch Only", Type: "chat", ContextSize: 8192}
+
+	send := func(path, body string, handler http.HandlerFunc) {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer alice")
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s status = %d: %s", path, rec.Code, rec.Body)
+		}
+	}
+	requests := func() uint64 {
+		keys := n.usage.report(keyID("alice"), time.Time{}, time.Time{})
+		if len(keys) == 0 {
+			return 0
+		}
+		return keys[0].Requests
+	}
+
+	// Streamed placeholder, with no miner connected
+	send("/v1/chat/completions", `{"model":"zen-mini-0.5b","stream":true,"messages":[{"role":"user","content":"hi"}]}`, n.handleChatCompletions)
+	if got := requests(); got != 1 {
+		t.Fatalf("requests after streamed chat = %d, want 1", got)
+	}
+
+	// Each successful batch item; the failed one is not metered
+	embedCtx, stopEmbedding := context.WithCancel(ctx)
+	runEmbeddingMiner(embedCtx, n)
+	send("/v1/embeddings/batch", "{\"input\":\"a\"}\n{\"input\":\"fail\"}\n{\"input\":\"b\"}\n", n.handleEmbeddingsBatch)
+	if got := requests(); got != 3 {
+		t.Fatalf("requests after batch = %d, want 3", got)
+	}
+	stopEmbedding()
+	n.mu.Lock()
+	delete(n.miners, "embedder")
+	n.mu.Unlock()
+
+	// A stream synthesized from a model whose miners cannot stream
+	runFakeMiner(ctx, n, "whole answer")
+	send("/v1/chat/completions", `{"model":"batch-only","stream":true,"messages":[{"role":"user","content":"hi"}]}`, n.handleChatCompletions)
+	keys := n.usage.report(keyID("alice"), time.Time{}, time.Time{})
+	if keys[0].Requests != 4 || keys[0].Models["batch-only"] != 1 {
+		t.Errorf("usage = %+v, want 4 requests, one of batch-only", keys[0].UsageTotals)
+	}
+	if keys[0].CompletionTokens == 0 {
+		t.Error("streamed completions metered no completion tokens")
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// ChatValidation is the response to a dry-run chat request
+// (POST /v1/chat/completions?validate=true): the request passed every check
+// a real request would run, but nothing was dispatched
+type ChatValidation struct {
+	Object         string `json:"object"`
+	Valid          bool   `json:"valid"`
+	Model          string `json:"model"`           // Model the request would run on
+	RequestedModel string `json:"requested_model"` // Model named in the request
+	PromptTokens   int    `json:"prompt_tokens"`   // Estimated, after any context truncation
+	MaxTokens      int    `json:"max_tokens,omitempty"`
+	ContextSize    int    `json:"context_size,omitempty"`
+	N              int    `json:"n"`
+}
+
+// dryRun reports whether r asks for validation only
+func dryRun(r *http.Request) (bool, error) {
+	v := r.URL.Query().Get("validate")
+	if v == "" {
+		return false, nil
+	}
+	ok, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid validate %q: want true or false", v)
+	}
+	return ok, nil
+}
+
+// writeChatValidation answers a dry-run chat request that passed validation
+func writeChatValidation(w http.ResponseWriter, requested string, req *ChatRequest, model *ModelInfo, choices int) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ChatValidation{
+		Object:         "chat.completion.validation",
+		Valid:          true,
+		Model:          req.Model,
+		RequestedModel: requested,
+		PromptTokens:   estimatePromptTokens(req),
+		MaxTokens:      req.MaxTokens,
+		ContextSize:    model.ContextSize,
+		N:              choices,
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChatValidate(t *testing.T) {
+	long := strings.Repeat("word ", 40000)
+
+	tests := []struct {
+		name      string
+		query     string
+		body      string
+		wantCode  int
+		wantModel string
+		wantErr   string
+	}{
+		{
+			name:      "valid",
+			query:     "validate=true",
+			body:      `{"model":"zen-coder-1.5b","messages":[{"role":"user","content":"hello there"}],"max_tokens":64}`,
+			wantCode:  http.StatusOK,
+			wantModel: "zen-coder-1.5b",
+		},
+		{
+			name:      "unknown model resolves to default",
+			query:     "validate=1",
+			body:      `{"model":"nope","messages":[{"role":"user","content":"hi"}]}`,
+			wantCode:  http.StatusOK,
+			wantModel: "zen-mini-0.5b",
+		},
+		{
+			name:     "context overflow",
+			query:    "validate=true",
+			body:     `{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"` + long + `"}]}`,
+			wantCode: http.StatusBadRequest,
+			wantErr:  "exceeds zen-mini-0.5b context window",
+		},
+		{
+			name:     "invalid n",
+			query:    "validate=true",
+			body:     `{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}],"n":1000}`,
+			wantCode: http.StatusBadRequest,
+			wantErr:  "n must be between",
+		},
+		{
+			name:     "stream with n",
+			query:    "validate=true",
+			body:     `{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}],"n":2,"stream":true}`,
+			wantCode: http.StatusBadRequest,
+			wantErr:  "stream does not support n > 1",
+		},
+		{
+			name:     "bad validate value",
+			query:    "validate=maybe",
+			body:     `{"model":"zen-mini-0.5b","messages":[{"role":"user","content":"hi"}]}`,
+			wantCode: http.StatusBadRequest,
+			wantErr:  "invalid validate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewAINode(Config{})
+			n.miners["m1"] = &MinerInfo{ID: "m1"}
+
+			req := httptest.NewRequest("POST", "/v1/chat/completions?"+tt.query, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			n.handleChatCompletions(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if len(n.tasks) != 0 {
+				t.Errorf("dispatched %d tasks, want none", len(n.tasks))
+			}
+			if tt.wantErr != "" {
+				if !strings.Contains(rec.Body.String(), tt.wantErr) {
+					t.Errorf("body = %q, want %q", rec.Body, tt.wantErr)
+				}
+				return
+			}
+
+			var got ChatValidation
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !got.Valid || got.Model != tt.wantModel || got.PromptTokens <= 0 || got.N != 1 {
+				t.Errorf("validation = %+v, want valid on %s", got, tt.wantModel)
+			}
+		})
+	}
+}
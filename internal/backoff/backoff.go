@@ -0,0 +1,109 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package backoff retries operations with exponentially growing, optionally
+// jittered delays.
+package backoff
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// DefaultFactor is the delay multiplier used when Backoff.Factor is unset.
+const DefaultFactor = 2
+
+// Backoff describes a retry schedule. The delay before retry n (counting
+// from 0) is Base * Factor^n, capped at Max, then spread by Jitter.
+type Backoff struct {
+	// Base is the delay before the first retry.
+	Base time.Duration
+
+	// Factor multiplies the delay after each retry. Values of 1 or less use
+	// DefaultFactor.
+	Factor float64
+
+	// Max caps the delay before jitter is applied. Zero means no cap.
+	Max time.Duration
+
+	// Jitter randomizes each delay by up to this fraction in either
+	// direction, so 0.2 yields 80%-120% of the computed delay. It is
+	// clamped to [0, 1].
+	Jitter float64
+
+	// Retries is how many times a failed operation is retried after the
+	// first attempt. Zero or negative means no retries.
+	Retries int
+}
+
+// Delay returns the wait before retry attempt, counting from 0.
+func (b Backoff) Delay(attempt int) time.Duration {
+	factor := b.Factor
+	if factor <= 1 {
+		factor = DefaultFactor
+	}
+	d := float64(b.Base) * math.Pow(factor, float64(max(attempt, 0)))
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	if d > math.MaxInt64 {
+		d = math.MaxInt64
+	}
+
+	if jitter := min(max(b.Jitter, 0), 1); jitter > 0 {
+		d *= 1 + jitter*(2*rand.Float64()-1)
+	}
+	if d >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+// Retry calls fn until it succeeds, returns an error wrapped by Permanent,
+// runs out of retries, or ctx is done, waiting Delay between attempts. It
+// returns nil on success and otherwise the last error from fn, unwrapped
+// from Permanent.
+func (b Backoff) Retry(ctx context.Context, fn func(context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if attempt >= b.Retries || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(b.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// permanentError marks an error that Retry must not retry.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that Retry returns it immediately instead of
+// retrying. Permanent(nil) is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package backoff
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       Backoff
+		attempt int
+		want    time.Duration
+	}{
+		{"first retry", Backoff{Base: 100 * time.Millisecond}, 0, 100 * time.Millisecond},
+		{"default factor", Backoff{Base: 100 * time.Millisecond}, 3, 800 * time.Millisecond},
+		{"custom factor", Backoff{Base: time.Second, Factor: 3}, 2, 9 * time.Second},
+		{"capped", Backoff{Base: time.Second, Max: 5 * time.Second}, 10, 5 * time.Second},
+		{"negative attempt", Backoff{Base: time.Second}, -1, time.Second},
+		{"overflow saturates", Backoff{Base: time.Hour}, 1000, time.Duration(math.MaxInt64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.Delay(tt.attempt); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelayJitter(t *testing.T) {
+	b := Backoff{Base: time.Second, Jitter: 0.25}
+	lo, hi := time.Duration(math.MaxInt64), time.Duration(0)
+	for i := 0; i < 1000; i++ {
+		d := b.Delay(0)
+		if d < 750*time.Millisecond || d > 1250*time.Millisecond {
+			t.Fatalf("Delay(0) = %v, want within 25%% of 1s", d)
+		}
+		lo, hi = min(lo, d), max(hi, d)
+	}
+	if hi-lo < 100*time.Millisecond {
+		t.Errorf("delays spread over %v, want jitter to vary them", hi-lo)
+	}
+
+	// Jitter is clamped to 100%, so delays never go negative
+	b.Jitter = 5
+	for i := 0; i < 100; i++ {
+		if d := b.Delay(0); d < 0 || d > 2*time.Second {
+			t.Fatalf("Delay(0) with clamped jitter = %v, want within [0, 2s]", d)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+
+	tests := []struct {
+		name      string
+		retries   int
+		errs      []error // returned by successive calls, then nil
+		wantErr   error
+		wantCalls int
+	}{
+		{"succeeds first time", 3, nil, nil, 1},
+		{"succeeds after retries", 3, []error{errTransient, errTransient}, nil, 3},
+		{"runs out of retries", 2, []error{errTransient, errTransient, errTransient, errTransient}, errTransient, 3},
+		{"no retries", 0, []error{errTransient}, errTransient, 1},
+		{"permanent stops", 3, []error{errTransient, Permanent(errFatal)}, errFatal, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Backoff{Base: time.Millisecond, Retries: tt.retries}
+			calls := 0
+			err := b.Retry(context.Background(), func(context.Context) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if err != tt.wantErr {
+				t.Errorf("Retry() = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fn called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errTransient := errors.New("transient")
+	b := Backoff{Base: time.Hour, Retries: 5}
+
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Retry(ctx, func(context.Context) error {
+			calls++
+			return errTransient
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != errTransient {
+			t.Errorf("Retry() = %v, want last error %v", err, errTransient)
+		}
+		if calls != 1 {
+			t.Errorf("fn called %d times, want 1 before cancellation", calls)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Retry() did not return after ctx was cancelled")
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+	base := errors.New("base")
+	if err := Permanent(base); !errors.Is(err, base) || err.Error() != "base" {
+		t.Errorf("Permanent(base) = %v, want it to wrap base", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package minersig defines the digests a miner signs with its Ed25519 key
+// in its calls to the node. Miner and node both import it, so the two
+// sides cannot drift apart.
+package minersig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+)
+
+// ResultDomain separates result signatures from other uses of a miner key
+const ResultDomain = "lux-ai/task-result/v1"
+
+// ResultDigest is what a miner signs when submitting a result:
+//
+//	sha256(ResultDomain || 0x00 || taskID || 0x00 || sha256(output) || signedAt)
+//
+// output is hashed in compact JSON form and signedAt is its Unix time in
+// nanoseconds, big-endian.
+func ResultDigest(taskID string, output json.RawMessage, signedAt time.Time) [32]byte {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, output); err != nil {
+		compact.Reset()
+		compact.Write(output)
+	}
+	outputHash := sha256.Sum256(compact.Bytes())
+
+	h := sha256.New()
+	h.Write([]byte(ResultDomain))
+	h.Write([]byte{0})
+	h.Write([]byte(taskID))
+	h.Write([]byte{0})
+	h.Write(outputHash[:])
+	binary.Write(h, binary.BigEndian, signedAt.UnixNano())
+
+	var digest [32]byte
+	h.Sum(digest[:0])
+	return digest
+}
+
+// RegistrationDomain separates registration signatures from other uses of
+// a miner key
+const RegistrationDomain = "lux-ai/register/v1"
+
+// RegistrationDigest is what a miner signs when registering, so that it
+// can re-register without its node token:
+//
+//	sha256(RegistrationDomain || 0x00 || minerID || 0x00 || timestamp || publicKey)
+//
+// timestamp is the Unix time in nanoseconds, big-endian, and publicKey is
+// the key being registered.
+func RegistrationDigest(minerID string, timestamp time.Time, publicKey []byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte(RegistrationDomain))
+	h.Write([]byte{0})
+	h.Write([]byte(minerID))
+	h.Write([]byte{0})
+	binary.Write(h, binary.BigEndian, timestamp.UnixNano())
+	h.Write(publicKey)
+
+	var digest [32]byte
+	h.Sum(digest[:0])
+	return digest
+}
+
+// HeartbeatDomain separates heartbeat signatures from other uses of a
+// miner key
+const HeartbeatDomain = "lux-ai/heartbeat/v1"
+
+// HeartbeatDigest is what a miner signs when sending a heartbeat:
+//
+//	sha256(HeartbeatDomain || 0x00 || minerID || 0x00 || timestamp || nonce)
+//
+// timestamp is the Unix time in nanoseconds, big-endian.
+func HeartbeatDigest(minerID string, timestamp time.Time, nonce []byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte(HeartbeatDomain))
+	h.Write([]byte{0})
+	h.Write([]byte(minerID))
+	h.Write([]byte{0})
+	binary.Write(h, binary.BigEndian, timestamp.UnixNano())
+	h.Write(nonce)
+
+	var digest [32]byte
+	h.Sum(digest[:0])
+	return digest
+}
@@ -0,0 +1,62 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package minersig
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRegistrationDigest(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	key := []byte("public-key")
+	base := RegistrationDigest("m1", at, key)
+	for name, got := range map[string][32]byte{
+		"miner": RegistrationDigest("m2", at, key),
+		"time":  RegistrationDigest("m1", at.Add(time.Nanosecond), key),
+		"key":   RegistrationDigest("m1", at, []byte("other-key")),
+	} {
+		if got == base {
+			t.Errorf("digest ignores the %s", name)
+		}
+	}
+	if base == HeartbeatDigest("m1", at, key) {
+		t.Error("registration and heartbeat digests share a domain")
+	}
+}
+
+func TestHeartbeatDigest(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	nonce := []byte("0123456789abcdef")
+	base := HeartbeatDigest("m1", at, nonce)
+	for name, got := range map[string][32]byte{
+		"miner": HeartbeatDigest("m2", at, nonce),
+		"time":  HeartbeatDigest("m1", at.Add(time.Nanosecond), nonce),
+		"nonce": HeartbeatDigest("m1", at, []byte("fedcba9876543210")),
+	} {
+		if got == base {
+			t.Errorf("digest ignores the %s", name)
+		}
+	}
+}
+
+func TestResultDigest(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	base := ResultDigest("t1", json.RawMessage(`{"content":"hi"}`), at)
+
+	// JSON formatting does not change the digest
+	if got := ResultDigest("t1", json.RawMessage("{ \"content\" : \"hi\" }"), at); got != base {
+		t.Error("digest depends on JSON whitespace")
+	}
+	for name, got := range map[string][32]byte{
+		"task":   ResultDigest("t2", json.RawMessage(`{"content":"hi"}`), at),
+		"output": ResultDigest("t1", json.RawMessage(`{"content":"ho"}`), at),
+		"time":   ResultDigest("t1", json.RawMessage(`{"content":"hi"}`), at.Add(time.Nanosecond)),
+	} {
+		if got == base {
+			t.Errorf("digest ignores the %s", name)
+		}
+	}
+}
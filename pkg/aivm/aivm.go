@@ -206,6 +206,12 @@ func (vm *VM) Stop() error {
 	return nil
 }
 
+// AttestationNonce issues a single-use nonce for a provider to put in the
+// CPU quote it registers with
+func (vm *VM) AttestationNonce() ([]byte, error) {
+	return vm.verifier.NewNonce()
+}
+
 // RegisterProvider registers a new compute provider
 func (vm *VM) RegisterProvider(provider *Provider) error {
 	vm.mu.Lock()
@@ -213,7 +219,7 @@ func (vm *VM) RegisterProvider(provider *Provider) error {
 
 	// Verify CPU attestation if provided
 	if provider.CPUAttestation != nil {
-		if err := vm.verifier.VerifyCPUAttestation(provider.CPUAttestation, nil); err != nil {
+		if _, err := vm.verifier.VerifyCPUDevice(provider.ID, provider.CPUAttestation); err != nil {
 			return err
 		}
 	}
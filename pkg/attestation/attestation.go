@@ -4,20 +4,27 @@
 package attestation
 
 import (
+	"crypto/ecdsa"
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+	"github.com/luxfi/ai/pkg/clock"
 )
 
 var (
-	ErrInvalidQuote       = errors.New("invalid attestation quote")
-	ErrInvalidMeasurement = errors.New("measurement mismatch")
-	ErrQuoteExpired       = errors.New("quote expired")
-	ErrUnsupportedTEE     = errors.New("unsupported TEE type")
-	ErrInvalidSignature   = errors.New("invalid signature")
+	ErrInvalidQuote         = errors.New("invalid attestation quote")
+	ErrInvalidMeasurement   = errors.New("measurement mismatch")
+	ErrQuoteExpired         = errors.New("quote expired")
+	ErrUnsupportedTEE       = errors.New("unsupported TEE type")
+	ErrInvalidSignature     = errors.New("invalid signature")
+	ErrNoTrustedMeasurement = errors.New("no trusted measurement registered")
 )
 
 // AttestationMode indicates the type of attestation
@@ -130,11 +137,19 @@ type SoftwareGPUAttestation struct {
 	CUDAVersion   string `json:"cuda_version"`
 	VBIOSVersion  string `json:"vbios_version"`
 
-	// Performance attestation - prove the GPU ran computation
+	// Liveness check - prove the provider is online and did fresh work.
+	// The hash is derived from BenchmarkSeed, the seed of the challenge it
+	// answers, so it cannot be computed before the verifier issued the
+	// challenge. It does not prove the work ran on the GPU.
+	// BenchmarkTime is the kernel time the provider measured. It is
+	// informational; the verifier times the answer on its own clock.
+	BenchmarkSeed [32]byte `json:"benchmark_seed"`
 	BenchmarkHash [32]byte `json:"benchmark_hash"` // Hash of benchmark result
 	BenchmarkTime uint64   `json:"benchmark_time_ms"`
 
-	// Provider signature (signed with provider's key)
+	// Provider signature (signed with provider's key). ProviderID selects
+	// the verifier's authorized key set; empty means the device ID.
+	ProviderID     string `json:"provider_id,omitempty"`
 	ProviderPubKey []byte `json:"provider_pubkey"`
 	Signature      []byte `json:"signature"`
 
@@ -153,12 +168,60 @@ type DeviceStatus struct {
 	JobHistory []string        `json:"job_history"`
 	Mode       AttestationMode `json:"mode"`
 	HardwareCC bool            `json:"hardware_cc"` // True if hardware CC verified
+
+	// Method is the cc attestation method of the verified evidence, set by
+	// the verifier; see TierAttestation
+	Method string `json:"method,omitempty"`
+
+	// Name of the trusted measurement the device's CPU quote matched
+	Measurement string `json:"measurement,omitempty"`
 }
 
-// Verifier verifies TEE attestations
+// Verifier verifies TEE attestations. It is safe for concurrent use.
 type Verifier struct {
+	// mu guards every field below. Exported methods take it; unexported
+	// helpers expect the caller to hold it.
+	mu sync.Mutex
+
 	trustedMeasurements map[string][]byte
 	attestedDevices     map[string]*DeviceStatus
+
+	// Keys trusted to sign CPU TEE quotes; see RegisterQuoteKey
+	quoteKeys []*ecdsa.PublicKey
+
+	// Outstanding software benchmark challenges, keyed by device ID
+	challenges map[string]*outstandingChallenge
+
+	// Quote nonces from NewNonce not yet used, with their issue time
+	nonces map[string]time.Time
+
+	// GPU SPDM signing keys, keyed by device ID; see RegisterGPUKey
+	gpuKeys map[string]*ecdsa.PublicKey
+
+	// Nonce size and randomness source; see SetChallengeConfig
+	challenge ChallengeConfig
+
+	// Evidence size limits; see SetEvidenceLimits
+	limits EvidenceLimits
+
+	// Keys authorized to sign software attestations, keyed by provider ID
+	authorizedKeys map[string][]authorizedKey
+
+	// Driver version policy for software attestation scoring
+	driverPolicy *DriverPolicy
+
+	// Successful GPU verifications keyed by device ID; see SetCacheTTL
+	cache       map[string]*cacheEntry
+	cacheTTL    time.Duration
+	cacheHits   uint64
+	cacheMisses uint64
+
+	// Verification outcomes; see Stats
+	verified map[verifiedKey]uint64
+	rejected map[RejectReason]uint64
+
+	// Source of the current time; see SetClock
+	clock clock.Clock
 }
 
 // NewVerifier creates a new attestation verifier
@@ -166,40 +229,171 @@ func NewVerifier() *Verifier {
 	return &Verifier{
 		trustedMeasurements: make(map[string][]byte),
 		attestedDevices:     make(map[string]*DeviceStatus),
+		challenges:          make(map[string]*outstandingChallenge),
+		nonces:              make(map[string]time.Time),
+		gpuKeys:             make(map[string]*ecdsa.PublicKey),
+		challenge:           DefaultChallengeConfig(),
+		limits:              DefaultEvidenceLimits(),
+		authorizedKeys:      make(map[string][]authorizedKey),
+		driverPolicy:        DefaultDriverPolicy(),
+		cache:               make(map[string]*cacheEntry),
+		verified:            make(map[verifiedKey]uint64),
+		rejected:            make(map[RejectReason]uint64),
+		clock:               clock.Real{},
 	}
 }
 
-// RegisterTrustedMeasurement registers a trusted measurement
+// SetClock replaces the clock the verifier checks freshness, expiry and
+// key validity against. A nil clock restores the system clock.
+func (v *Verifier) SetClock(c clock.Clock) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.clock = clock.Or(c)
+}
+
+// now returns the verifier's current time
+func (v *Verifier) now() time.Time {
+	return v.clock.Now()
+}
+
+// SetDriverPolicy replaces the driver version policy used when scoring
+// software attestations. A nil policy restores the default.
+func (v *Verifier) SetDriverPolicy(policy *DriverPolicy) {
+	if policy == nil {
+		policy = DefaultDriverPolicy()
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.driverPolicy = policy
+	v.resetCache()
+}
+
+// RegisterTrustedMeasurement adds a named measurement to the CPU TEE
+// allow-list. Registering an existing name replaces its measurement.
 func (v *Verifier) RegisterTrustedMeasurement(name string, measurement []byte) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
 	v.trustedMeasurements[name] = measurement
 }
 
-// VerifyCPUAttestation verifies CPU TEE attestation
+// VerifyCPUAttestation verifies CPU TEE attestation. The quote must be
+// signed by a registered quote key, carry a nonce from NewNonce that has
+// not been used before, and its measurement must match
+// expectedMeasurement or a registered trusted measurement; with neither,
+// the quote is rejected.
 func (v *Verifier) VerifyCPUAttestation(quote *AttestationQuote, expectedMeasurement []byte) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_, err := v.matchMeasurement(quote, expectedMeasurement)
+	if err != nil {
+		v.recordRejected(nil, err)
+		return err
+	}
+	v.recordVerified(ModeLocal, cc.Tier2ConfidentialVM)
+	return nil
+}
+
+// VerifyCPUDevice verifies a device's CPU TEE quote against the registered
+// trusted measurements and records the matched name in the device's
+// status, creating the status if the device has none yet
+func (v *Verifier) VerifyCPUDevice(deviceID string, quote *AttestationQuote) (*DeviceStatus, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	name, err := v.matchMeasurement(quote, nil)
+	if err != nil {
+		v.recordRejected(nil, err)
+		return nil, err
+	}
+	v.recordVerified(ModeLocal, cc.Tier2ConfidentialVM)
+
+	status, ok := v.attestedDevices[deviceID]
+	if !ok {
+		status = &DeviceStatus{
+			Attested:   true,
+			TrustScore: cc.Tier2ConfidentialVM.BaseTrustScore(),
+			Operator:   deviceID,
+			Vendor:     quote.Type,
+			JobHistory: []string{},
+			Mode:       ModeLocal,
+			HardwareCC: true, // Signed by a trusted key and allow-listed
+			Method:     quoteMethod(quote.Type),
+		}
+		v.attestedDevices[deviceID] = status
+	}
+	status.LastSeen = v.now()
+	status.Measurement = name
+	return status, nil
+}
+
+// matchMeasurement checks a CPU quote's measurement against expected and
+// the registered trusted measurements. It returns the name of the first
+// registered measurement matched, in name order, or "" if the quote matched
+// expected. The quote must be signed and carry an unused nonce from
+// NewNonce; the nonce is consumed once the signature checks out.
+func (v *Verifier) matchMeasurement(quote *AttestationQuote, expected []byte) (string, error) {
 	if quote == nil || len(quote.Quote) == 0 {
-		return ErrInvalidQuote
+		return "", ErrInvalidQuote
 	}
-	if time.Since(quote.Timestamp) > time.Hour {
-		return ErrQuoteExpired
+	if err := v.checkQuoteSize(quote); err != nil {
+		return "", err
 	}
-	switch quote.Type {
-	case TEETypeSGX:
-		return v.verifySGXQuote(quote, expectedMeasurement)
-	case TEETypeSEVSNP:
-		return v.verifySEVSNPQuote(quote, expectedMeasurement)
-	case TEETypeTDX:
-		return v.verifyTDXQuote(quote, expectedMeasurement)
-	default:
-		return ErrUnsupportedTEE
+	if v.now().Sub(quote.Timestamp) > time.Hour {
+		return "", ErrQuoteExpired
+	}
+	measurement, err := quoteMeasurement(quote)
+	if err != nil {
+		return "", err
+	}
+	if err := v.verifyQuoteSignature(quote); err != nil {
+		return "", err
 	}
+	if err := v.checkNonce(quote.Nonce); err != nil {
+		return "", err
+	}
+
+	if len(expected) > 0 && bytesEqual(measurement, expected) {
+		return "", nil
+	}
+	names := make([]string, 0, len(v.trustedMeasurements))
+	for name := range v.trustedMeasurements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if bytesEqual(measurement, v.trustedMeasurements[name]) {
+			return name, nil
+		}
+	}
+	if len(expected) == 0 && len(names) == 0 {
+		return "", ErrNoTrustedMeasurement
+	}
+	return "", ErrInvalidMeasurement
 }
 
 // VerifyGPUAttestation verifies GPU attestation based on mode
 // All attestation is LOCAL - no cloud dependencies (blockchain requirement)
 func (v *Verifier) VerifyGPUAttestation(att *GPUAttestation) (*DeviceStatus, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.verifyGPUAttestation(att)
+}
+
+// verifyGPUAttestation verifies att and records the device's status
+func (v *Verifier) verifyGPUAttestation(att *GPUAttestation) (*DeviceStatus, error) {
 	if att == nil {
+		v.recordRejected(nil, ErrInvalidQuote)
 		return nil, ErrInvalidQuote
 	}
+	if err := v.checkGPUSize(att); err != nil {
+		v.recordRejected(att, err)
+		return nil, err
+	}
+
+	now := v.now()
+	hash := gpuAttestationHash(att)
+	if status, ok := v.cachedStatus(att, hash, now); ok {
+		return status, nil
+	}
 
 	var status *DeviceStatus
 	var err error
@@ -218,15 +412,20 @@ func (v *Verifier) VerifyGPUAttestation(att *GPUAttestation) (*DeviceStatus, err
 		} else if att.SoftwareAttestation != nil {
 			status, err = v.verifySoftwareGPUAttestation(att)
 		} else {
+			v.recordRejected(att, ErrInvalidQuote)
 			return nil, ErrInvalidQuote
 		}
 	}
 
 	if err != nil {
+		delete(v.cache, att.DeviceID)
+		v.recordRejected(att, err)
 		return nil, err
 	}
 
+	v.recordVerified(status.Mode, GPUAttestationTier(att))
 	v.attestedDevices[att.DeviceID] = status
+	v.cacheStatus(att, hash, status, now)
 	return status, nil
 }
 
@@ -238,21 +437,16 @@ func (v *Verifier) verifyLocalGPUAttestation(att *GPUAttestation) (*DeviceStatus
 		return nil, ErrInvalidQuote
 	}
 
-	// Check if GPU model supports CC
-	if !IsHardwareCCCapable(att.Model) {
-		return nil, errors.New("GPU model does not support confidential computing: " + att.Model)
+	// Check if GPU model supports CC (full or limited)
+	if GPUCCCapability(att.Model) == cc.GPUCCNone {
+		return nil, fmt.Errorf("%w: %s", ErrGPUNotCCCapable, att.Model)
 	}
 
 	ev := att.LocalEvidence
 
-	// Verify SPDM report exists (minimum size for valid report)
-	if len(ev.SPDMReport) < 256 {
-		return nil, ErrInvalidQuote
-	}
-
-	// Verify certificate chain exists
-	if len(ev.CertChain) < 256 {
-		return nil, ErrInvalidQuote
+	// Verify the SPDM report and certificate chain are plausibly sized
+	if err := ev.Validate(); err != nil {
+		return nil, err
 	}
 
 	// In production: verify SPDM signature against NVIDIA root cert
@@ -261,15 +455,23 @@ func (v *Verifier) verifyLocalGPUAttestation(att *GPUAttestation) (*DeviceStatus
 
 	trustScore := calculateLocalTrustScore(att, ev)
 
+	// Evidence whose measurements did not match the RIM cannot back a
+	// hardware tier
+	method := cc.MethodSoftware
+	if ev.RIMVerified {
+		method = cc.MethodNVTrust
+	}
+
 	return &DeviceStatus{
 		Attested:   true,
 		TrustScore: trustScore,
-		LastSeen:   time.Now(),
+		LastSeen:   v.now(),
 		Operator:   att.DeviceID,
 		Vendor:     TEETypeNVIDIA,
 		JobHistory: []string{},
 		Mode:       ModeLocal,
 		HardwareCC: ev.RIMVerified, // True if RIM verification passed
+		Method:     method,
 	}, nil
 }
 
@@ -281,84 +483,117 @@ func (v *Verifier) verifySoftwareGPUAttestation(att *GPUAttestation) (*DeviceSta
 
 	sw := att.SoftwareAttestation
 
-	// Verify basic fields
-	if sw.GPUSerial == "" || sw.DriverVersion == "" {
-		return nil, ErrInvalidQuote
-	}
-
-	// Verify provider signature exists
-	if len(sw.Signature) < 64 || len(sw.ProviderPubKey) < 32 {
-		return nil, ErrInvalidSignature
+	// Verify identity fields and signature sizes
+	if err := sw.Validate(); err != nil {
+		return nil, err
 	}
 
 	// Verify timestamp freshness
-	if time.Since(sw.Timestamp) > time.Hour {
+	if v.now().Sub(sw.Timestamp) > SoftwareAttestationMaxAge {
 		return nil, ErrQuoteExpired
 	}
 
-	// In production: verify signature against provider's public key
-	// signedData := hashSoftwareAttestation(sw)
-	// if !verifySignature(sw.ProviderPubKey, signedData, sw.Signature) {
-	//     return nil, ErrInvalidSignature
-	// }
+	// Verify the signature against the provider's authorized keys
+	if err := v.verifyProviderSignature(att, sw); err != nil {
+		return nil, err
+	}
+
+	// Verify the benchmark against the liveness challenge issued for this
+	// device. Without a challenge the benchmark claim cannot be checked, so
+	// it is accepted but earns no trust bonus.
+	benchmarkVerified := false
+	switch err := v.verifyBenchmark(att, sw); err {
+	case nil:
+		benchmarkVerified = true
+	case ErrNoChallenge:
+	default:
+		return nil, err
+	}
 
-	trustScore := calculateSoftwareTrustScore(att, sw)
+	trustScore := calculateSoftwareTrustScore(att, sw, benchmarkVerified, v.driverPolicy)
 
 	return &DeviceStatus{
 		Attested:   true,
 		TrustScore: trustScore,
-		LastSeen:   time.Now(),
+		LastSeen:   v.now(),
 		Operator:   att.DeviceID,
 		Vendor:     TEETypeNVIDIA,
 		JobHistory: []string{},
 		Mode:       ModeSoftware,
 		HardwareCC: false, // Software attestation cannot claim hardware CC
+		Method:     cc.MethodSoftware,
 	}, nil
 }
 
-func (v *Verifier) verifySGXQuote(quote *AttestationQuote, expectedMeasurement []byte) error {
-	if len(quote.Quote) < 432 {
-		return ErrInvalidQuote
-	}
-	mrenclave := quote.Quote[112:144]
-	if len(expectedMeasurement) > 0 && !bytesEqual(mrenclave, expectedMeasurement) {
-		return ErrInvalidMeasurement
+// quoteMethod returns the cc attestation method of a CPU quote type. SGX
+// enclaves are not confidential VMs, so SGX quotes have no method and
+// cannot back Tier 2.
+func quoteMethod(t TEEType) string {
+	switch t {
+	case TEETypeSEVSNP:
+		return cc.MethodSEVSNP
+	case TEETypeTDX:
+		return cc.MethodTDX
+	case TEETypeARM:
+		return cc.MethodCCA
+	default:
+		return ""
 	}
-	return nil
 }
 
-func (v *Verifier) verifySEVSNPQuote(quote *AttestationQuote, expectedMeasurement []byte) error {
-	if len(quote.Quote) < 1184 {
-		return ErrInvalidQuote
+// TierAttestation returns a tier attestation for providerID backed by this
+// verified status, issued at now for the tier's validity period. Its
+// Method is the one the verifier derived from the evidence, so a provider
+// cannot claim a stronger method than it proved.
+func (s *DeviceStatus) TierAttestation(providerID string, tier cc.CCTier, now time.Time) *cc.TierAttestation {
+	return &cc.TierAttestation{
+		Tier:       tier,
+		ProviderID: providerID,
+		HardwareID: s.Operator,
+		Method:     s.Method,
+		TrustScore: s.TrustScore,
+		IssuedAt:   now,
+		ExpiresAt:  now.Add(tier.AttestationValidity()),
 	}
-	report, err := ParseSEVSNPReport(quote.Quote)
-	if err != nil {
-		return err
-	}
-	if len(expectedMeasurement) > 0 && !bytesEqual(report.Measurement[:], expectedMeasurement) {
-		return ErrInvalidMeasurement
-	}
-	return nil
 }
 
-func (v *Verifier) verifyTDXQuote(quote *AttestationQuote, expectedMeasurement []byte) error {
-	if len(quote.Quote) < 584 {
-		return ErrInvalidQuote
-	}
-	tdxQuote, err := ParseTDXQuote(quote.Quote)
-	if err != nil {
-		return err
-	}
-	if len(expectedMeasurement) > 0 && !bytesEqual(tdxQuote.ReportData[:], expectedMeasurement) {
-		return ErrInvalidMeasurement
+// quoteMeasurement parses a CPU TEE quote and returns its measurement:
+// MRENCLAVE for SGX, the launch measurement for SEV-SNP and MRTD for TDX
+func quoteMeasurement(quote *AttestationQuote) ([]byte, error) {
+	switch quote.Type {
+	case TEETypeSGX:
+		if len(quote.Quote) < 432 {
+			return nil, ErrInvalidQuote
+		}
+		return quote.Quote[112:144], nil
+	case TEETypeSEVSNP:
+		if len(quote.Quote) < 1184 {
+			return nil, ErrInvalidQuote
+		}
+		report, err := ParseSEVSNPReport(quote.Quote)
+		if err != nil {
+			return nil, err
+		}
+		return report.Measurement[:], nil
+	case TEETypeTDX:
+		tdxQuote, err := ParseTDXQuote(quote.Quote)
+		if err != nil {
+			return nil, err
+		}
+		return tdxQuote.MRTD[:], nil
+	default:
+		return nil, ErrUnsupportedTEE
 	}
-	return nil
 }
 
 // calculateLocalTrustScore for local nvtrust verification
 // This is the PRIMARY trust score calculation for CC-capable GPUs
 // Max score: 100 for datacenter GPUs with full CC features
 func calculateLocalTrustScore(att *GPUAttestation, ev *LocalGPUEvidence) uint8 {
+	if GPUCCCapability(att.Model) == cc.GPUCCLimited {
+		return limitedCCTrustScore(att.CCEnabled, ev != nil && ev.RIMVerified)
+	}
+
 	score := uint8(70) // Base for local nvtrust verification
 
 	// CC features bonus
@@ -444,7 +679,8 @@ func ParseSEVSNPReport(data []byte) (*SEVSNPReport, error) {
 	return report, nil
 }
 
-// TDXQuote represents Intel TDX attestation quote
+// TDXQuote represents Intel TDX attestation quote: the 48-byte header and
+// the fields of the TD report body that follows it
 type TDXQuote struct {
 	Version            uint16
 	AttestationKeyType uint16
@@ -452,12 +688,14 @@ type TDXQuote struct {
 	Reserved           [4]byte
 	VendorID           [16]byte
 	UserData           [20]byte
+	MRSEAM             [48]byte
+	MRTD               [48]byte // Measurement of the initial TD image
 	ReportData         [64]byte
 }
 
 // ParseTDXQuote parses Intel TDX quote
 func ParseTDXQuote(data []byte) (*TDXQuote, error) {
-	if len(data) < 584 {
+	if len(data) < tdxSignedSize {
 		return nil, ErrInvalidQuote
 	}
 	quote := &TDXQuote{
@@ -468,13 +706,15 @@ func ParseTDXQuote(data []byte) (*TDXQuote, error) {
 	copy(quote.Reserved[:], data[8:12])
 	copy(quote.VendorID[:], data[12:28])
 	copy(quote.UserData[:], data[28:48])
-	copy(quote.ReportData[:], data[48:112])
+	copy(quote.MRSEAM[:], data[64:112])
+	copy(quote.MRTD[:], data[184:232])
+	copy(quote.ReportData[:], data[568:632])
 	return quote, nil
 }
 
 // calculateSoftwareTrustScore for consumer GPU software attestation
 // Max score: 60 (significantly lower - no hardware CC)
-func calculateSoftwareTrustScore(att *GPUAttestation, sw *SoftwareGPUAttestation) uint8 {
+func calculateSoftwareTrustScore(att *GPUAttestation, sw *SoftwareGPUAttestation, benchmarkVerified bool, policy *DriverPolicy) uint8 {
 	score := uint8(20) // Base for software attestation
 
 	// GPU model bonuses (consumer GPUs)
@@ -491,9 +731,9 @@ func calculateSoftwareTrustScore(att *GPUAttestation, sw *SoftwareGPUAttestation
 		score += 5
 	}
 
-	// Benchmark verification bonus
-	if sw.BenchmarkHash != [32]byte{} && sw.BenchmarkTime > 0 {
-		score += 10 // Proves GPU actually ran computation
+	// Benchmark verification bonus (only for answered challenges)
+	if benchmarkVerified {
+		score += 10 // Provider answered a fresh liveness challenge
 	}
 
 	// Signature verification bonus
@@ -501,10 +741,9 @@ func calculateSoftwareTrustScore(att *GPUAttestation, sw *SoftwareGPUAttestation
 		score += 10 // Provider accountability
 	}
 
-	// Driver version bonus (newer = better)
-	if sw.DriverVersion != "" {
-		score += 5
-	}
+	// Driver, CUDA and VBIOS versions: bonus for recommended, penalty for
+	// known-vulnerable
+	score = uint8(int(score) + policy.versionScoreAdjustment(att.Model, sw))
 
 	if score > 60 {
 		score = 60 // Cap at 60 for software attestation
@@ -512,40 +751,90 @@ func calculateSoftwareTrustScore(att *GPUAttestation, sw *SoftwareGPUAttestation
 	return score
 }
 
-// IsHardwareCCCapable returns true if the GPU model supports hardware CC
-func IsHardwareCCCapable(model string) bool {
+// GPUCCCapability returns the confidential computing level for a GPU model
+func GPUCCCapability(model string) cc.GPUCCLevel {
 	switch model {
 	case "H100", "H200", "B100", "B200", "GB200", "RTX PRO 6000":
-		return true
+		return cc.GPUCCFull
+	case "A100": // Ampere CC lacks Hopper's full memory/bus protection
+		return cc.GPUCCLimited
 	default:
-		return false
+		return cc.GPUCCNone
 	}
 }
 
+// IsHardwareCCCapable returns true if the GPU model supports full hardware CC
+func IsHardwareCCCapable(model string) bool {
+	return GPUCCCapability(model) == cc.GPUCCFull
+}
+
+// limitedCCTrustScore scores locally verified GPUs with limited CC. The
+// result always sits above the software attestation cap (60) and below the
+// full CC base (70).
+func limitedCCTrustScore(ccEnabled, rimVerified bool) uint8 {
+	score := uint8(61)
+	if ccEnabled {
+		score += 5
+	}
+	if rimVerified {
+		score += 3
+	}
+	return score
+}
+
 // GetDeviceStatus returns the status of an attested device
 func (v *Verifier) GetDeviceStatus(deviceID string) (*DeviceStatus, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
 	status, ok := v.attestedDevices[deviceID]
 	return status, ok
 }
 
 // RecordJobCompletion records job completion for a device
 func (v *Verifier) RecordJobCompletion(deviceID, jobID string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
 	if status, ok := v.attestedDevices[deviceID]; ok {
 		status.JobHistory = append(status.JobHistory, jobID)
-		status.LastSeen = time.Now()
+		status.LastSeen = v.now()
 	}
 }
 
-// ComputeAttestationHash computes hash for on-chain anchoring
+// QuoteEncodingVersion identifies the canonical quote encoding hashed by
+// ComputeAttestationHash. Bump it whenever the encoding changes so anchored
+// hashes from different encodings can never collide.
+const QuoteEncodingVersion uint8 = 1
+
+// quoteHashDomain separates quote digests from other SHA-256 uses
+const quoteHashDomain = "lux-ai/attestation-quote"
+
+// CanonicalQuoteEncoding returns the versioned canonical encoding of quote:
+// domain tag, encoding version, TEE type, quote version and timestamp (Unix
+// nanoseconds, 0 when unset), followed by the length-prefixed quote,
+// measurement, report data and nonce. Integers are big-endian.
+func CanonicalQuoteEncoding(quote *AttestationQuote) []byte {
+	var ts int64
+	if !quote.Timestamp.IsZero() {
+		ts = quote.Timestamp.UnixNano()
+	}
+
+	buf := make([]byte, 0, len(quoteHashDomain)+18+4*4+
+		len(quote.Quote)+len(quote.Measurement)+len(quote.ReportData)+len(quote.Nonce))
+	buf = append(buf, quoteHashDomain...)
+	buf = append(buf, QuoteEncodingVersion, byte(quote.Type))
+	buf = binary.BigEndian.AppendUint32(buf, quote.Version)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(ts))
+	for _, field := range [][]byte{quote.Quote, quote.Measurement, quote.ReportData, quote.Nonce} {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(field)))
+		buf = append(buf, field...)
+	}
+	return buf
+}
+
+// ComputeAttestationHash computes hash for on-chain anchoring over the
+// canonical encoding of every security-relevant quote field
 func ComputeAttestationHash(quote *AttestationQuote) [32]byte {
-	h := sha256.New()
-	h.Write([]byte{byte(quote.Type)})
-	h.Write(quote.Quote)
-	h.Write(quote.Measurement)
-	h.Write(quote.Nonce)
-	var hash [32]byte
-	copy(hash[:], h.Sum(nil))
-	return hash
+	return sha256.Sum256(CanonicalQuoteEncoding(quote))
 }
 
 // FormatDeviceID formats device ID from attestation
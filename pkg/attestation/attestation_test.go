@@ -4,8 +4,15 @@
 package attestation
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+	"github.com/luxfi/ai/pkg/clock"
 )
 
 func TestTEETypeString(t *testing.T) {
@@ -81,6 +88,41 @@ func TestVerifyCPUAttestation_ExpiredQuote(t *testing.T) {
 	}
 }
 
+func TestVerifierClock(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	mock := clock.NewMock(start)
+	v := NewVerifier()
+	v.SetClock(mock)
+	trustQuoteKeys(v)
+	v.RegisterTrustedMeasurement("image", make([]byte, 32))
+
+	quote := signedQuote(t, v, TEETypeSGX, nil)
+	quote.Timestamp = start
+	mock.Advance(time.Hour)
+	if err := v.VerifyCPUAttestation(quote, nil); err != nil {
+		t.Fatalf("VerifyCPUAttestation() at exactly 1h = %v, want nil", err)
+	}
+	mock.Advance(time.Nanosecond)
+	if err := v.VerifyCPUAttestation(quote, nil); err != ErrQuoteExpired {
+		t.Errorf("VerifyCPUAttestation() past 1h = %v, want %v", err, ErrQuoteExpired)
+	}
+
+	fresh := signedQuote(t, v, TEETypeSGX, nil)
+	fresh.Timestamp = mock.Now()
+	status, err := v.VerifyCPUDevice("vm-1", fresh)
+	if err != nil {
+		t.Fatalf("VerifyCPUDevice() error = %v", err)
+	}
+	if !status.LastSeen.Equal(mock.Now()) {
+		t.Errorf("LastSeen = %v, want the mock time %v", status.LastSeen, mock.Now())
+	}
+
+	v.SetClock(nil)
+	if err := v.VerifyCPUAttestation(quote, nil); err != ErrQuoteExpired {
+		t.Errorf("VerifyCPUAttestation() on the system clock = %v, want %v", err, ErrQuoteExpired)
+	}
+}
+
 func TestVerifyCPUAttestation_UnsupportedTEE(t *testing.T) {
 	v := NewVerifier()
 	quote := &AttestationQuote{
@@ -96,13 +138,11 @@ func TestVerifyCPUAttestation_UnsupportedTEE(t *testing.T) {
 
 func TestVerifySGXQuote(t *testing.T) {
 	v := NewVerifier()
+	trustQuoteKeys(v)
+	v.RegisterTrustedMeasurement("image", make([]byte, 32))
 
-	// Create valid SGX quote (432+ bytes)
-	quote := &AttestationQuote{
-		Type:      TEETypeSGX,
-		Quote:     make([]byte, 500),
-		Timestamp: time.Now(),
-	}
+	// Signed SGX quote: header, report body and ECDSA signature data
+	quote := signedQuote(t, v, TEETypeSGX, nil)
 
 	err := v.VerifyCPUAttestation(quote, nil)
 	if err != nil {
@@ -110,14 +150,27 @@ func TestVerifySGXQuote(t *testing.T) {
 	}
 }
 
-func TestVerifySGXQuote_MeasurementMismatch(t *testing.T) {
+func TestVerifyCPUAttestation_NoTrustedMeasurement(t *testing.T) {
 	v := NewVerifier()
+	trustQuoteKeys(v)
 
-	quote := &AttestationQuote{
-		Type:      TEETypeSGX,
-		Quote:     make([]byte, 500),
-		Timestamp: time.Now(),
+	err := v.VerifyCPUAttestation(signedQuote(t, v, TEETypeSGX, nil), nil)
+	if err != ErrNoTrustedMeasurement {
+		t.Errorf("expected ErrNoTrustedMeasurement, got %v", err)
 	}
+	if _, err := v.VerifyCPUDevice("vm-1", signedQuote(t, v, TEETypeSGX, nil)); err != ErrNoTrustedMeasurement {
+		t.Errorf("VerifyCPUDevice() = %v, want %v", err, ErrNoTrustedMeasurement)
+	}
+	if _, ok := v.GetDeviceStatus("vm-1"); ok {
+		t.Error("device attested without a trusted measurement")
+	}
+}
+
+func TestVerifySGXQuote_MeasurementMismatch(t *testing.T) {
+	v := NewVerifier()
+	trustQuoteKeys(v)
+
+	quote := signedQuote(t, v, TEETypeSGX, nil)
 
 	expectedMeasurement := make([]byte, 32)
 	expectedMeasurement[0] = 0xFF
@@ -130,13 +183,11 @@ func TestVerifySGXQuote_MeasurementMismatch(t *testing.T) {
 
 func TestVerifySEVSNPQuote(t *testing.T) {
 	v := NewVerifier()
+	trustQuoteKeys(v)
+	v.RegisterTrustedMeasurement("image", make([]byte, 48))
 
-	// Create valid SEV-SNP report (1184 bytes)
-	quote := &AttestationQuote{
-		Type:      TEETypeSEVSNP,
-		Quote:     make([]byte, 1200),
-		Timestamp: time.Now(),
-	}
+	// Signed SEV-SNP report (1184 bytes)
+	quote := signedQuote(t, v, TEETypeSEVSNP, nil)
 
 	err := v.VerifyCPUAttestation(quote, nil)
 	if err != nil {
@@ -146,13 +197,11 @@ func TestVerifySEVSNPQuote(t *testing.T) {
 
 func TestVerifyTDXQuote(t *testing.T) {
 	v := NewVerifier()
+	trustQuoteKeys(v)
+	v.RegisterTrustedMeasurement("image", make([]byte, 48))
 
-	// Create valid TDX quote (584+ bytes)
-	quote := &AttestationQuote{
-		Type:      TEETypeTDX,
-		Quote:     make([]byte, 600),
-		Timestamp: time.Now(),
-	}
+	// Signed TDX quote: header, TD report body and ECDSA signature data
+	quote := signedQuote(t, v, TEETypeTDX, nil)
 
 	err := v.VerifyCPUAttestation(quote, nil)
 	if err != nil {
@@ -317,8 +366,10 @@ func TestParseSEVSNPReport_TooShort(t *testing.T) {
 }
 
 func TestParseTDXQuote(t *testing.T) {
-	data := make([]byte, 600)
-	data[0] = 4 // Version
+	data := make([]byte, 632)
+	data[0] = 4      // Version
+	data[184] = 0xAA // MRTD
+	data[568] = 0xBB // Report data
 
 	quote, err := ParseTDXQuote(data)
 	if err != nil {
@@ -328,6 +379,9 @@ func TestParseTDXQuote(t *testing.T) {
 	if quote.Version != 4 {
 		t.Errorf("Version = %d, want 4", quote.Version)
 	}
+	if quote.MRTD[0] != 0xAA || quote.ReportData[0] != 0xBB {
+		t.Errorf("MRTD[0] = %#x, ReportData[0] = %#x, want 0xaa and 0xbb", quote.MRTD[0], quote.ReportData[0])
+	}
 }
 
 func TestParseTDXQuote_TooShort(t *testing.T) {
@@ -406,6 +460,46 @@ func TestComputeAttestationHash(t *testing.T) {
 	if hash == [32]byte{} {
 		t.Error("hash should not be empty")
 	}
+	if ComputeAttestationHash(quote) != hash {
+		t.Error("hash should be stable")
+	}
+}
+
+func TestComputeAttestationHashCoversAllFields(t *testing.T) {
+	base := AttestationQuote{
+		Type:        TEETypeSEVSNP,
+		Version:     2,
+		Quote:       []byte("quote"),
+		Measurement: []byte("measurement"),
+		ReportData:  []byte("report-data-a"),
+		Timestamp:   time.Unix(1700000000, 0),
+		Nonce:       []byte("nonce"),
+	}
+	baseHash := ComputeAttestationHash(&base)
+
+	tests := []struct {
+		name   string
+		mutate func(q *AttestationQuote)
+	}{
+		{"report data", func(q *AttestationQuote) { q.ReportData = []byte("report-data-b") }},
+		{"version", func(q *AttestationQuote) { q.Version = 3 }},
+		{"timestamp", func(q *AttestationQuote) { q.Timestamp = q.Timestamp.Add(time.Second) }},
+		{"type", func(q *AttestationQuote) { q.Type = TEETypeTDX }},
+		{"field boundary", func(q *AttestationQuote) {
+			q.Quote = []byte("quotem")
+			q.Measurement = []byte("easurement")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := base
+			tt.mutate(&q)
+			if ComputeAttestationHash(&q) == baseHash {
+				t.Errorf("changing %s should change the hash", tt.name)
+			}
+		})
+	}
 }
 
 func TestFormatDeviceID(t *testing.T) {
@@ -477,6 +571,9 @@ func TestLocalAttestation(t *testing.T) {
 	if !status.HardwareCC {
 		t.Error("should have HardwareCC true when RIMVerified")
 	}
+	if status.Method != cc.MethodNVTrust {
+		t.Errorf("Method = %q, want %q", status.Method, cc.MethodNVTrust)
+	}
 }
 
 func TestLocalAttestation_InvalidEvidence(t *testing.T) {
@@ -501,8 +598,11 @@ func TestLocalAttestation_InvalidEvidence(t *testing.T) {
 	}
 
 	_, err = v.VerifyGPUAttestation(att)
-	if err != ErrInvalidQuote {
-		t.Errorf("expected ErrInvalidQuote for short SPDM, got %v", err)
+	if err != ErrSPDMTooShort {
+		t.Errorf("expected ErrSPDMTooShort for short SPDM, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidQuote) {
+		t.Errorf("ErrSPDMTooShort should match ErrInvalidQuote")
 	}
 }
 
@@ -514,18 +614,17 @@ func TestSoftwareGPUAttestation(t *testing.T) {
 		Model:    "RTX 5090",
 		Mode:     ModeSoftware,
 		SoftwareAttestation: &SoftwareGPUAttestation{
-			GPUSerial:      "GPU-SERIAL-12345",
-			PCIID:          "0000:01:00.0",
-			ComputeCaps:    "10.0",
-			DriverVersion:  "570.00",
-			CUDAVersion:    "13.0",
-			BenchmarkHash:  [32]byte{1, 2, 3, 4, 5},
-			BenchmarkTime:  1500,
-			ProviderPubKey: make([]byte, 64),
-			Signature:      make([]byte, 128),
-			Timestamp:      time.Now(),
+			GPUSerial:     "GPU-SERIAL-12345",
+			PCIID:         "0000:01:00.0",
+			ComputeCaps:   "10.0",
+			DriverVersion: "570.00",
+			CUDAVersion:   "13.0",
+			BenchmarkHash: [32]byte{1, 2, 3, 4, 5},
+			BenchmarkTime: 1500,
+			Timestamp:     time.Now(),
 		},
 	}
+	signSoftwareAttestation(t, v, att)
 
 	status, err := v.VerifyGPUAttestation(att)
 	if err != nil {
@@ -544,27 +643,65 @@ func TestSoftwareGPUAttestation(t *testing.T) {
 	if status.HardwareCC {
 		t.Error("software attestation should not claim HardwareCC")
 	}
+	if status.Method != cc.MethodSoftware {
+		t.Errorf("Method = %q, want %q", status.Method, cc.MethodSoftware)
+	}
+}
+
+func TestDeviceStatusTierAttestation(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		status  *DeviceStatus
+		tier    cc.CCTier
+		wantErr error
+	}{
+		{"nvtrust backs tier 1", &DeviceStatus{Method: cc.MethodNVTrust}, cc.Tier1GPUNativeCC, nil},
+		{"software cannot back tier 1", &DeviceStatus{Method: cc.MethodSoftware}, cc.Tier1GPUNativeCC, cc.ErrAttestationMethod},
+		{"SEV-SNP backs tier 2", &DeviceStatus{Method: quoteMethod(TEETypeSEVSNP)}, cc.Tier2ConfidentialVM, nil},
+		{"SGX cannot back tier 2", &DeviceStatus{Method: quoteMethod(TEETypeSGX)}, cc.Tier2ConfidentialVM, cc.ErrAttestationMethod},
+		{"software backs tier 4", &DeviceStatus{Method: cc.MethodSoftware}, cc.Tier4Standard, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.status.TierAttestation("p", tt.tier, now)
+			if !a.ExpiresAt.Equal(now.Add(tt.tier.AttestationValidity())) {
+				t.Errorf("ExpiresAt = %v, want the tier's validity after %v", a.ExpiresAt, now)
+			}
+			pool := cc.NewAIRewardPool(time.Hour)
+			err := pool.ValidateProvider(&cc.AIProvider{ProviderID: "p", StakeLUX: 1_000_000, Attestation: a})
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("ValidateProvider() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
 }
 
 func TestSoftwareGPUAttestation_DGXSpark(t *testing.T) {
-	v := NewVerifier()
+	v, mock := mockVerifier()
+
+	ch, err := v.IssueBenchmarkChallenge("DGX-SPARK-001")
+	if err != nil {
+		t.Fatalf("IssueBenchmarkChallenge: %v", err)
+	}
+	mock.Advance(time.Second) // The answer arrives a second after the challenge
 
 	att := &GPUAttestation{
 		DeviceID: "DGX-SPARK-001",
 		Model:    "GB10",
 		Mode:     ModeSoftware,
 		SoftwareAttestation: &SoftwareGPUAttestation{
-			GPUSerial:      "DGX-SERIAL-12345",
-			PCIID:          "0000:01:00.0",
-			ComputeCaps:    "10.0",
-			DriverVersion:  "575.00",
-			BenchmarkHash:  [32]byte{1, 2, 3},
-			BenchmarkTime:  1000,
-			ProviderPubKey: make([]byte, 64),
-			Signature:      make([]byte, 128),
-			Timestamp:      time.Now(),
+			GPUSerial:     "DGX-SERIAL-12345",
+			PCIID:         "0000:01:00.0",
+			ComputeCaps:   "10.0",
+			DriverVersion: "575.00",
+			BenchmarkSeed: ch.Seed,
+			BenchmarkHash: RunBenchmarkKernel(ch),
+			BenchmarkTime: 1000,
+			Timestamp:     time.Now(),
 		},
 	}
+	signSoftwareAttestation(t, v, att)
 
 	status, err := v.VerifyGPUAttestation(att)
 	if err != nil {
@@ -597,8 +734,11 @@ func TestSoftwareGPUAttestation_InvalidSignature(t *testing.T) {
 	}
 
 	_, err := v.VerifyGPUAttestation(att)
-	if err != ErrInvalidSignature {
-		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	if err != ErrSignatureTooShort {
+		t.Errorf("expected ErrSignatureTooShort, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("ErrSignatureTooShort should match ErrInvalidSignature")
 	}
 }
 
@@ -638,7 +778,7 @@ func TestIsHardwareCCCapable(t *testing.T) {
 		{"RTX 5090", false},
 		{"RTX 4090", false},
 		{"GB10", false},
-		{"A100", false}, // A100 has limited CC, not full
+		{"A100", false}, // Limited CC, see TestGPUCCCapability
 	}
 
 	for _, tt := range tests {
@@ -650,6 +790,55 @@ func TestIsHardwareCCCapable(t *testing.T) {
 	}
 }
 
+func TestGPUCCCapability(t *testing.T) {
+	tests := []struct {
+		model string
+		want  cc.GPUCCLevel
+	}{
+		{"H100", cc.GPUCCFull},
+		{"GB200", cc.GPUCCFull},
+		{"A100", cc.GPUCCLimited},
+		{"RTX 4090", cc.GPUCCNone},
+		{"GB10", cc.GPUCCNone},
+	}
+
+	for _, tt := range tests {
+		if got := GPUCCCapability(tt.model); got != tt.want {
+			t.Errorf("GPUCCCapability(%s) = %v, want %v", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestLimitedCCLocalAttestation(t *testing.T) {
+	v := NewVerifier()
+	att := &GPUAttestation{
+		DeviceID:  "a100-0",
+		Model:     "A100",
+		Mode:      ModeLocal,
+		CCEnabled: true,
+		LocalEvidence: &LocalGPUEvidence{
+			SPDMReport:  make([]byte, 256),
+			CertChain:   make([]byte, 256),
+			RIMVerified: true,
+		},
+	}
+
+	status, err := v.VerifyGPUAttestation(att)
+	if err != nil {
+		t.Fatalf("VerifyGPUAttestation() error = %v", err)
+	}
+
+	// Limited CC ranks above software-only (max 60) and below full CC (min 70)
+	if status.TrustScore <= 60 || status.TrustScore >= 70 {
+		t.Errorf("A100 trust score = %d, want between 61 and 69", status.TrustScore)
+	}
+
+	nv := NewNvtrustVerifier(nil)
+	if got := nv.calculateLocalTrustScore(&GPUHardwareInfo{Model: "A100", CCEnabled: true}, true); got != status.TrustScore {
+		t.Errorf("nvtrust A100 score = %d, want %d", got, status.TrustScore)
+	}
+}
+
 func TestAttestationModes(t *testing.T) {
 	// Verify mode constants - ModeLocal is PRIMARY, ModeSoftware for non-CC GPUs
 	// ModeHardwareCC and ModeLocalVerifier are legacy aliases for ModeLocal
@@ -684,3 +873,122 @@ func TestLocalAttestationNonCCGPU(t *testing.T) {
 		t.Error("expected error for non-CC GPU with local attestation")
 	}
 }
+
+func TestVerifyCPUAttestation_AllowList(t *testing.T) {
+	imageA := bytes.Repeat([]byte{0xAA}, 32)
+	imageB := bytes.Repeat([]byte{0xBB}, 32)
+	sgxQuote := func(v *Verifier, mrenclave []byte) *AttestationQuote {
+		return signedQuote(t, v, TEETypeSGX, func(q []byte) { copy(q[112:144], mrenclave) })
+	}
+
+	tests := []struct {
+		name     string
+		trusted  map[string][]byte
+		quote    []byte
+		expected []byte
+		wantName string
+		wantErr  error
+	}{
+		{"matches first image", map[string][]byte{"image-a": imageA, "image-b": imageB}, imageA, nil, "image-a", nil},
+		{"matches second image", map[string][]byte{"image-a": imageA, "image-b": imageB}, imageB, nil, "image-b", nil},
+		{"matches none", map[string][]byte{"image-a": imageA, "image-b": imageB}, make([]byte, 32), nil, "", ErrInvalidMeasurement},
+		{"duplicate measurement picks first name", map[string][]byte{"z-old": imageA, "a-new": imageA}, imageA, nil, "a-new", nil},
+		{"expected alongside allow-list", map[string][]byte{"image-a": imageA}, imageB, imageB, "", nil},
+		{"no policy rejects", nil, imageB, nil, "", ErrNoTrustedMeasurement},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier()
+			trustQuoteKeys(v)
+			for name, m := range tt.trusted {
+				v.RegisterTrustedMeasurement(name, m)
+			}
+			quote := sgxQuote(v, tt.quote)
+
+			if err := v.VerifyCPUAttestation(quote, tt.expected); err != tt.wantErr {
+				t.Fatalf("VerifyCPUAttestation() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.expected != nil {
+				return
+			}
+
+			status, err := v.VerifyCPUDevice("vm-1", sgxQuote(v, tt.quote))
+			if err != tt.wantErr {
+				t.Fatalf("VerifyCPUDevice() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if _, ok := v.GetDeviceStatus("vm-1"); ok {
+					t.Error("failed verification recorded a device status")
+				}
+				return
+			}
+			if status.Measurement != tt.wantName {
+				t.Errorf("Measurement = %q, want %q", status.Measurement, tt.wantName)
+			}
+			if !status.HardwareCC {
+				t.Error("HardwareCC = false for an allow-listed measurement")
+			}
+			if stored, _ := v.GetDeviceStatus("vm-1"); stored != status {
+				t.Error("status not recorded for device")
+			}
+		})
+	}
+}
+
+func TestVerifyCPUDevice_UpdatesExistingStatus(t *testing.T) {
+	v := NewVerifier()
+	trustQuoteKeys(v)
+	v.RegisterTrustedMeasurement("image-a", make([]byte, 32))
+	v.attestedDevices["gpu-0"] = &DeviceStatus{Attested: true, TrustScore: 95, Mode: ModeLocal}
+
+	quote := signedQuote(t, v, TEETypeSGX, nil)
+	status, err := v.VerifyCPUDevice("gpu-0", quote)
+	if err != nil {
+		t.Fatalf("VerifyCPUDevice() error = %v", err)
+	}
+	if status.Measurement != "image-a" || status.TrustScore != 95 {
+		t.Errorf("status = %+v, want existing score with measurement image-a", status)
+	}
+}
+
+func TestVerifierConcurrentUse(t *testing.T) {
+	v := NewVerifier()
+	trustQuoteKeys(v)
+	v.RegisterTrustedMeasurement("image", make([]byte, 32))
+	v.SetCacheTTL(time.Minute)
+
+	// Verifying, caching, recording and reading devices from several
+	// goroutines must not race
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			device := fmt.Sprintf("GPU-%d", i%4)
+			for range 10 {
+				if _, err := v.VerifyGPUAttestation(newLocalAttestation(device, "H100")); err != nil {
+					t.Errorf("VerifyGPUAttestation() error = %v", err)
+					return
+				}
+				if _, err := v.VerifyCPUDevice(fmt.Sprintf("vm-%d", i), signedQuote(t, v, TEETypeSGX, nil)); err != nil {
+					t.Errorf("VerifyCPUDevice() error = %v", err)
+					return
+				}
+				v.RecordJobCompletion(device, "job")
+				v.GetDeviceStatus(device)
+				v.AttestedDevices()
+				v.CacheStats()
+				v.Stats()
+				if i == 0 {
+					v.InvalidateCache()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(v.AttestedDevices()); got != 12 {
+		t.Errorf("%d attested devices, want 12", got)
+	}
+}
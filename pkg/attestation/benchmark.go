@@ -0,0 +1,179 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrNoChallenge          = errors.New("no benchmark challenge issued")
+	ErrChallengeExpired     = errors.New("benchmark challenge expired")
+	ErrBenchmarkMismatch    = errors.New("benchmark result does not match challenge")
+	ErrBenchmarkImplausible = errors.New("benchmark time implausible for GPU model")
+	ErrStaleBenchmark       = errors.New("benchmark does not answer an outstanding challenge")
+)
+
+const (
+	// BenchmarkKernelMemoryHard is the reference benchmark kernel, a
+	// sequential SHA-256 chain in the style of scrypt's ROMix. It fills
+	// MemoryBlocks 32-byte blocks from the challenge seed, then makes
+	// Iterations data-dependent reads and writes across them. The result is
+	// deterministic so the verifier can compute it too.
+	//
+	// The kernel is a liveness check, not a GPU benchmark: any CPU answers
+	// it in about the same time. A correct, timely answer shows the
+	// provider is online and did fresh work for this challenge; it does not
+	// show that the work ran on the claimed GPU.
+	BenchmarkKernelMemoryHard = "sha256-romix-v1"
+
+	// DefaultBenchmarkMemoryBlocks is the table size issued in challenges,
+	// 4 MiB of 32-byte blocks
+	DefaultBenchmarkMemoryBlocks = 1 << 17
+
+	// DefaultBenchmarkIterations is the number of mixing rounds issued in
+	// challenges
+	DefaultBenchmarkIterations = 1 << 17
+
+	// BenchmarkChallengeTTL is how long an issued challenge may be answered
+	BenchmarkChallengeTTL = 10 * time.Minute
+)
+
+// BenchmarkChallenge is a liveness challenge issued by the verifier to a
+// software-attested GPU. The provider runs Kernel over Seed with
+// MemoryBlocks of memory for Iterations rounds and reports the result in
+// SoftwareGPUAttestation.BenchmarkHash, with Seed as its BenchmarkSeed. The
+// verifier times the answer from IssuedAt on its own clock.
+type BenchmarkChallenge struct {
+	DeviceID     string    `json:"device_id"`
+	Seed         [32]byte  `json:"seed"`
+	Kernel       string    `json:"kernel"`
+	MemoryBlocks uint32    `json:"memory_blocks"`
+	Iterations   uint32    `json:"iterations"`
+	IssuedAt     time.Time `json:"issued_at"`
+}
+
+// RunBenchmarkKernel computes the deterministic result for a challenge.
+// Miners call this to answer a challenge; the verifier calls it to check.
+func RunBenchmarkKernel(ch *BenchmarkChallenge) [32]byte {
+	blocks := max(ch.MemoryBlocks, 1)
+	table := make([][32]byte, blocks)
+	var buf [36]byte
+
+	// Fill the table with a hash chain seeded by the challenge
+	table[0] = sha256.Sum256(ch.Seed[:])
+	for i := uint32(1); i < blocks; i++ {
+		copy(buf[:32], table[i-1][:])
+		binary.LittleEndian.PutUint32(buf[32:], i)
+		table[i] = sha256.Sum256(buf[:])
+	}
+
+	// Mix: each round reads and rewrites the block its state points at
+	x := table[blocks-1]
+	for i := uint32(0); i < ch.Iterations; i++ {
+		j := binary.LittleEndian.Uint32(x[:4]) % blocks
+		for k := range x {
+			buf[k] = x[k] ^ table[j][k]
+		}
+		binary.LittleEndian.PutUint32(buf[32:], i)
+		x = sha256.Sum256(buf[:])
+		table[j] = x
+	}
+	return x
+}
+
+// BenchmarkTimeRange returns the plausible range in milliseconds from
+// issuing the default benchmark challenge to receiving its answer, for the
+// given GPU model. The range allows for the round trip to the provider.
+// Answers outside it are rejected as implausible. Since the kernel runs as
+// fast on a CPU, the range bounds liveness, not which device did the work.
+func BenchmarkTimeRange(model string) (min, max uint64) {
+	switch model {
+	case "RTX 5090", "RTX 5080": // Blackwell consumer
+		return 50, 2500
+	case "GB10": // DGX Spark
+		return 75, 3500
+	case "RTX 4090", "RTX 4080": // Ada consumer
+		return 75, 3500
+	case "RTX 3090", "RTX 3080":
+		return 100, 5500
+	default:
+		return 25, 10000
+	}
+}
+
+// outstandingChallenge is an issued benchmark challenge and its answer,
+// computed once at issue time
+type outstandingChallenge struct {
+	challenge BenchmarkChallenge
+	expected  [32]byte
+}
+
+// IssueBenchmarkChallenge creates a fresh benchmark challenge for a device,
+// replacing any outstanding challenge for the same device. The expected
+// answer is computed here, outside the verifier lock, so checking the
+// answer later is a comparison.
+func (v *Verifier) IssueBenchmarkChallenge(deviceID string) (*BenchmarkChallenge, error) {
+	v.mu.Lock()
+	ch := &BenchmarkChallenge{
+		DeviceID:     deviceID,
+		Kernel:       BenchmarkKernelMemoryHard,
+		MemoryBlocks: v.challenge.BenchmarkMemoryBlocks,
+		Iterations:   v.challenge.BenchmarkIterations,
+	}
+	_, err := io.ReadFull(v.challenge.Rand, ch.Seed[:])
+	v.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	expected := RunBenchmarkKernel(ch)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	ch.IssuedAt = v.now()
+	v.challenges[deviceID] = &outstandingChallenge{challenge: *ch, expected: expected}
+	return ch, nil
+}
+
+// verifyBenchmark checks a software attestation's benchmark against the
+// outstanding challenge for the device. Challenges are single-use, so a
+// benchmark replayed after its challenge was answered, or one answering an
+// earlier challenge, returns ErrStaleBenchmark. An attestation without a
+// seed and with no challenge outstanding returns ErrNoChallenge.
+//
+// The answer is timed from the challenge's issue to now on the verifier's
+// clock; the BenchmarkTime the provider reports is not trusted.
+func (v *Verifier) verifyBenchmark(att *GPUAttestation, sw *SoftwareGPUAttestation) error {
+	answered := v.now()
+
+	out, ok := v.challenges[att.DeviceID]
+	delete(v.challenges, att.DeviceID)
+	if !ok {
+		if sw.BenchmarkSeed != ([32]byte{}) {
+			return ErrStaleBenchmark
+		}
+		return ErrNoChallenge
+	}
+
+	elapsed := answered.Sub(out.challenge.IssuedAt)
+	if elapsed > BenchmarkChallengeTTL {
+		return ErrChallengeExpired
+	}
+	if sw.BenchmarkSeed != out.challenge.Seed {
+		return ErrStaleBenchmark
+	}
+	if out.expected != sw.BenchmarkHash {
+		return ErrBenchmarkMismatch
+	}
+	min, max := BenchmarkTimeRange(CanonicalGPUModel(att.Model))
+	if ms := elapsed.Milliseconds(); ms < int64(min) || ms > int64(max) {
+		return ErrBenchmarkImplausible
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/clock"
+)
+
+func newBenchmarkAttestation(t *testing.T, v *Verifier, deviceID, model string, seed, hash [32]byte, ms uint64) *GPUAttestation {
+	att := &GPUAttestation{
+		DeviceID: deviceID,
+		Model:    model,
+		Mode:     ModeSoftware,
+		SoftwareAttestation: &SoftwareGPUAttestation{
+			GPUSerial:     "GPU-SERIAL-12345",
+			DriverVersion: "570.00",
+			BenchmarkSeed: seed,
+			BenchmarkHash: hash,
+			BenchmarkTime: ms,
+			Timestamp:     time.Now(),
+		},
+	}
+	signSoftwareAttestation(t, v, att)
+	return att
+}
+
+// mockVerifier returns a verifier on a mock clock, so tests can control
+// how long a challenge takes to answer
+func mockVerifier() (*Verifier, *clock.Mock) {
+	v := NewVerifier()
+	mock := clock.NewMock(time.Now())
+	v.SetClock(mock)
+	return v, mock
+}
+
+func TestRunBenchmarkKernelDeterministic(t *testing.T) {
+	ch := &BenchmarkChallenge{Seed: [32]byte{7}, Kernel: BenchmarkKernelMemoryHard, MemoryBlocks: 1024, Iterations: 1000}
+	if RunBenchmarkKernel(ch) != RunBenchmarkKernel(ch) {
+		t.Error("kernel result should be deterministic")
+	}
+
+	other := *ch
+	other.Seed[0] = 8
+	if RunBenchmarkKernel(ch) == RunBenchmarkKernel(&other) {
+		t.Error("different seeds should produce different results")
+	}
+
+	fewer := *ch
+	fewer.Iterations = 999
+	if RunBenchmarkKernel(ch) == RunBenchmarkKernel(&fewer) {
+		t.Error("different iteration counts should produce different results")
+	}
+
+	smaller := *ch
+	smaller.MemoryBlocks = 1023
+	if RunBenchmarkKernel(ch) == RunBenchmarkKernel(&smaller) {
+		t.Error("different memory sizes should produce different results")
+	}
+}
+
+func TestIssueBenchmarkChallenge(t *testing.T) {
+	v := NewVerifier()
+	a, err := v.IssueBenchmarkChallenge("dev-1")
+	if err != nil {
+		t.Fatalf("IssueBenchmarkChallenge: %v", err)
+	}
+	b, _ := v.IssueBenchmarkChallenge("dev-1")
+	if a.Seed == b.Seed {
+		t.Error("challenges should have fresh seeds")
+	}
+	if b.Kernel != BenchmarkKernelMemoryHard || b.MemoryBlocks != DefaultBenchmarkMemoryBlocks || b.Iterations != DefaultBenchmarkIterations {
+		t.Errorf("unexpected challenge parameters: %+v", b)
+	}
+}
+
+func TestSoftwareBenchmarkChallenge(t *testing.T) {
+	tests := []struct {
+		name     string
+		issue    bool
+		correct  bool
+		seed     [32]byte // Overrides the challenge seed when set
+		model    string
+		elapsed  time.Duration // Issue to answer, on the verifier's clock
+		reported uint64        // BenchmarkTime the provider claims
+		wantErr  error
+	}{
+		{"correct answer", true, true, [32]byte{}, "RTX 5090", time.Second, 1000, nil},
+		{"detected model name", true, true, [32]byte{}, "NVIDIA GeForce RTX 5090", time.Second, 1000, nil},
+		{"fabricated hash", true, false, [32]byte{}, "RTX 5090", time.Second, 1000, ErrBenchmarkMismatch},
+		{"too fast", true, true, [32]byte{}, "RTX 5090", 10 * time.Millisecond, 1000, ErrBenchmarkImplausible},
+		{"too fast for detected model", true, true, [32]byte{}, "NVIDIA GeForce RTX 5090", 30 * time.Millisecond, 1000, ErrBenchmarkImplausible},
+		{"too slow", true, true, [32]byte{}, "RTX 5090", time.Minute, 1000, ErrBenchmarkImplausible},
+		{"too slow despite reported time", true, true, [32]byte{}, "RTX 5090", time.Minute, 500, ErrBenchmarkImplausible},
+		{"reported time ignored", true, true, [32]byte{}, "RTX 5090", time.Second, 60000, nil},
+		{"stale challenge", true, true, [32]byte{}, "RTX 5090", BenchmarkChallengeTTL + time.Minute, 1000, ErrChallengeExpired},
+		{"earlier challenge's seed", true, true, [32]byte{9}, "RTX 5090", time.Second, 1000, ErrStaleBenchmark},
+		{"seed without a challenge", false, false, [32]byte{9}, "RTX 5090", time.Second, 1000, ErrStaleBenchmark},
+		{"no challenge issued", false, false, [32]byte{}, "RTX 5090", time.Second, 1000, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, mock := mockVerifier()
+			seed, hash := tt.seed, [32]byte{1, 2, 3}
+			if tt.issue {
+				ch, err := v.IssueBenchmarkChallenge("GPU-001")
+				if err != nil {
+					t.Fatalf("IssueBenchmarkChallenge: %v", err)
+				}
+				if seed == ([32]byte{}) {
+					seed = ch.Seed
+				}
+				if tt.correct {
+					hash = RunBenchmarkKernel(ch)
+				}
+			}
+			mock.Advance(tt.elapsed)
+
+			att := newBenchmarkAttestation(t, v, "GPU-001", tt.model, seed, hash, tt.reported)
+			att.SoftwareAttestation.Timestamp = mock.Now()
+			signSoftwareAttestation(t, v, att)
+			_, err := v.VerifyGPUAttestation(att)
+			if err != tt.wantErr {
+				t.Errorf("VerifyGPUAttestation() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSoftwareBenchmarkBonusRequiresChallenge(t *testing.T) {
+	v, mock := mockVerifier()
+	unverified, err := v.VerifyGPUAttestation(newBenchmarkAttestation(t, v, "GPU-A", "RTX 5090", [32]byte{}, [32]byte{1}, 1500))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ch, _ := v.IssueBenchmarkChallenge("GPU-B")
+	mock.Advance(time.Second)
+	verified, err := v.VerifyGPUAttestation(newBenchmarkAttestation(t, v, "GPU-B", "RTX 5090", ch.Seed, RunBenchmarkKernel(ch), 1500))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if verified.TrustScore != unverified.TrustScore+10 {
+		t.Errorf("verified score = %d, unverified = %d; want +10 benchmark bonus",
+			verified.TrustScore, unverified.TrustScore)
+	}
+}
+
+func TestBenchmarkChallengeSingleUse(t *testing.T) {
+	v, mock := mockVerifier()
+	ch, _ := v.IssueBenchmarkChallenge("GPU-001")
+	att := newBenchmarkAttestation(t, v, "GPU-001", "RTX 5090", ch.Seed, RunBenchmarkKernel(ch), 1500)
+	mock.Advance(time.Second)
+
+	if _, err := v.VerifyGPUAttestation(att); err != nil {
+		t.Fatalf("first verification: %v", err)
+	}
+	// Replaying the same answer no longer matches an outstanding challenge
+	if _, err := v.VerifyGPUAttestation(att); err != ErrStaleBenchmark {
+		t.Errorf("replay verification error = %v, want %v", err, ErrStaleBenchmark)
+	}
+
+	// Nor does answering a superseded challenge
+	old, _ := v.IssueBenchmarkChallenge("GPU-001")
+	v.IssueBenchmarkChallenge("GPU-001")
+	mock.Advance(time.Second)
+	att = newBenchmarkAttestation(t, v, "GPU-001", "RTX 5090", old.Seed, RunBenchmarkKernel(old), 1500)
+	if _, err := v.VerifyGPUAttestation(att); err != ErrStaleBenchmark {
+		t.Errorf("superseded challenge error = %v, want %v", err, ErrStaleBenchmark)
+	}
+}
+
+func TestBenchmarkChallengesConcurrent(t *testing.T) {
+	v, mock := mockVerifier()
+	v.SetChallengeConfig(ChallengeConfig{BenchmarkMemoryBlocks: 16, BenchmarkIterations: 16}) // Cheap for the test
+	att := &GPUAttestation{Model: "RTX 5090"}
+
+	// Issuing and answering for the same and different devices at once
+	// must neither race nor lose a challenge
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 20 {
+				device := fmt.Sprintf("GPU-%d", (i+j)%4)
+				if _, err := v.IssueBenchmarkChallenge(device); err != nil {
+					t.Errorf("IssueBenchmarkChallenge: %v", err)
+					return
+				}
+				mock.Advance(time.Millisecond)
+				a := *att
+				a.DeviceID = device
+				v.mu.Lock()
+				v.verifyBenchmark(&a, &SoftwareGPUAttestation{})
+				v.mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Each device is left with at most the challenge issued last
+	v.mu.Lock()
+	outstanding := len(v.challenges)
+	v.mu.Unlock()
+	if outstanding > 4 {
+		t.Errorf("%d challenges outstanding, want at most 4", outstanding)
+	}
+
+	ch, _ := v.IssueBenchmarkChallenge("GPU-0")
+	mock.Advance(time.Second)
+	a := *att
+	a.DeviceID = "GPU-0"
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err := v.verifyBenchmark(&a, &SoftwareGPUAttestation{BenchmarkSeed: ch.Seed, BenchmarkHash: RunBenchmarkKernel(ch)}); err != nil {
+		t.Errorf("answer after concurrent use = %v, want nil", err)
+	}
+}
+
+func TestBenchmarkAnswerPrecomputed(t *testing.T) {
+	v, mock := mockVerifier()
+	ch, err := v.IssueBenchmarkChallenge("GPU-001")
+	if err != nil {
+		t.Fatalf("IssueBenchmarkChallenge: %v", err)
+	}
+	want := RunBenchmarkKernel(ch)
+
+	// Changing the returned challenge does not change the answer the
+	// verifier expects
+	ch.Iterations = 1
+	v.mu.Lock()
+	out := v.challenges["GPU-001"]
+	v.mu.Unlock()
+	if out.expected != want {
+		t.Error("verifier did not store the answer to the issued challenge")
+	}
+
+	mock.Advance(time.Second)
+	att := newBenchmarkAttestation(t, v, "GPU-001", "RTX 5090", ch.Seed, RunBenchmarkKernel(ch), 1000)
+	if _, err := v.VerifyGPUAttestation(att); err != ErrBenchmarkMismatch {
+		t.Errorf("answer to a modified challenge = %v, want %v", err, ErrBenchmarkMismatch)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+)
+
+const (
+	// bindingDomain separates binding digests from other hashes over the
+	// same nonce
+	bindingDomain = "lux-cc-binding-v1"
+
+	// spdmSigSize is the raw ECDSA P-384 signature (r || s) that ends an
+	// SPDM MEASUREMENTS response
+	spdmSigSize = 96
+)
+
+var (
+	ErrBindingMismatch    = errors.New("CPU quote not bound to GPU attestation")
+	ErrNoHardwareEvidence = errors.New("combined attestation requires hardware GPU evidence")
+	ErrUntrustedGPUKey    = errors.New("no trusted SPDM key for GPU")
+)
+
+// BindingReportData returns the value a confidential VM places at the start
+// of its CPU quote's report data to bind the quote to a GPU attestation:
+// SHA-256 over a domain tag, the GPU evidence nonce and the GPU device ID.
+// Since the report data is signed by the CPU and the nonce by the GPU, a
+// quote carrying it proves both came from the same machine answering the
+// same challenge.
+func BindingReportData(gpuNonce [32]byte, deviceID string) [32]byte {
+	h := sha256.New()
+	h.Write([]byte(bindingDomain))
+	h.Write(gpuNonce[:])
+	h.Write([]byte(deviceID))
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// RegisterGPUKey trusts key, a P-384 SPDM responder key, to sign the
+// measurements of the GPU deviceID. The verifier does not walk the device
+// certificate chain; callers validate the key against the NVIDIA root
+// before registering it.
+func (v *Verifier) RegisterGPUKey(deviceID string, key *ecdsa.PublicKey) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.gpuKeys[deviceID] = key
+}
+
+// VerifyCombined verifies Tier2 evidence: a confidential VM's CPU quote
+// and the local nvtrust attestation of a GPU attached to it. The quote
+// must be signed by a registered quote key and the GPU's SPDM report by
+// the device's registered key. Both must answer one joint challenge: the
+// quote nonce and the GPU evidence nonce are the same nonce, issued by
+// NewNonce and not used before, and the quote's report data starts with
+// BindingReportData of it, so evidence taken from different hosts or
+// replayed is rejected. The GPU's status is returned with the matched CPU
+// measurement name.
+func (v *Verifier) VerifyCombined(cpuQuote *AttestationQuote, gpuAtt *GPUAttestation) (*DeviceStatus, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	name, err := v.matchMeasurement(cpuQuote, nil)
+	if err != nil {
+		v.recordRejected(nil, err)
+		return nil, err
+	}
+	if gpuAtt == nil {
+		v.recordRejected(nil, ErrInvalidQuote)
+		return nil, ErrInvalidQuote
+	}
+	if gpuAtt.Mode != ModeLocal || gpuAtt.LocalEvidence == nil {
+		v.recordRejected(gpuAtt, ErrNoHardwareEvidence)
+		return nil, ErrNoHardwareEvidence
+	}
+	if err := checkBinding(cpuQuote, gpuAtt); err != nil {
+		v.recordRejected(gpuAtt, err)
+		return nil, err
+	}
+	if err := v.verifySPDMSignature(gpuAtt); err != nil {
+		v.recordRejected(gpuAtt, err)
+		return nil, err
+	}
+
+	status, err := v.verifyGPUAttestation(gpuAtt)
+	if err != nil {
+		return nil, err
+	}
+	status.Measurement = name
+	return status, nil
+}
+
+// checkBinding reports whether quote is bound to the nonce of att's local
+// evidence
+func checkBinding(quote *AttestationQuote, att *GPUAttestation) error {
+	nonce := att.LocalEvidence.Nonce
+	if nonce == ([32]byte{}) {
+		return ErrBindingMismatch // No GPU nonce to bind to
+	}
+	if !bytes.Equal(quote.Nonce, nonce[:]) {
+		return ErrBindingMismatch
+	}
+
+	reportData, err := quoteReportData(quote)
+	if err != nil {
+		return err
+	}
+	want := BindingReportData(nonce, att.DeviceID)
+	if !bytes.Equal(reportData[:len(want)], want[:]) {
+		return ErrBindingMismatch
+	}
+	return nil
+}
+
+// verifySPDMSignature checks the signature ending att's SPDM report
+// against the device's registered key. The signature covers the GPU
+// evidence nonce followed by the rest of the report, so a report cannot
+// be replayed against another challenge.
+func (v *Verifier) verifySPDMSignature(att *GPUAttestation) error {
+	key, ok := v.gpuKeys[att.DeviceID]
+	if !ok || key.Curve != elliptic.P384() {
+		return ErrUntrustedGPUKey
+	}
+	ev := att.LocalEvidence
+	if len(ev.SPDMReport) < MinSPDMReportSize {
+		return ErrSPDMTooShort
+	}
+	body := ev.SPDMReport[:len(ev.SPDMReport)-spdmSigSize]
+	sig := ev.SPDMReport[len(ev.SPDMReport)-spdmSigSize:]
+	h := sha512.New384()
+	h.Write(ev.Nonce[:])
+	h.Write(body)
+	r := new(big.Int).SetBytes(sig[:spdmSigSize/2])
+	s := new(big.Int).SetBytes(sig[spdmSigSize/2:])
+	if !ecdsa.Verify(key, h.Sum(nil), r, s) {
+		return ErrSPDMVerifyFailed
+	}
+	return nil
+}
+
+// quoteReportData returns the 64 bytes of report data signed into a CPU TEE
+// quote. It reads the quote body rather than AttestationQuote.ReportData,
+// which the hardware does not sign.
+func quoteReportData(quote *AttestationQuote) ([]byte, error) {
+	switch quote.Type {
+	case TEETypeSGX:
+		if len(quote.Quote) < 432 {
+			return nil, ErrInvalidQuote
+		}
+		return quote.Quote[368:432], nil
+	case TEETypeSEVSNP:
+		report, err := ParseSEVSNPReport(quote.Quote)
+		if err != nil {
+			return nil, err
+		}
+		return report.ReportData[:], nil
+	case TEETypeTDX:
+		tdxQuote, err := ParseTDXQuote(quote.Quote)
+		if err != nil {
+			return nil, err
+		}
+		return tdxQuote.ReportData[:], nil
+	default:
+		return nil, ErrUnsupportedTEE
+	}
+}
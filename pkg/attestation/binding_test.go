@@ -0,0 +1,246 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"testing"
+)
+
+// combinedFixture issues joint challenges and builds evidence answering
+// them: a signed SEV-SNP quote and a local GPU attestation whose SPDM
+// report is signed by a key registered for the device
+type combinedFixture struct {
+	t      *testing.T
+	v      *Verifier
+	gpuKey *ecdsa.PrivateKey
+}
+
+func newCombinedFixture(t *testing.T) *combinedFixture {
+	t.Helper()
+	gpuKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifier()
+	trustQuoteKeys(v)
+	v.RegisterTrustedMeasurement("cvm-image", make([]byte, 48))
+	v.RegisterGPUKey("GPU-CVM-001", &gpuKey.PublicKey)
+	return &combinedFixture{t: t, v: v, gpuKey: gpuKey}
+}
+
+// nonce issues a joint challenge nonce
+func (f *combinedFixture) nonce() [32]byte {
+	f.t.Helper()
+	issued, err := f.v.NewNonce()
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return [32]byte(issued)
+}
+
+// gpuAtt returns a local attestation of GPU-CVM-001 answering nonce
+func (f *combinedFixture) gpuAtt(nonce [32]byte) *GPUAttestation {
+	f.t.Helper()
+	report := make([]byte, MinSPDMReportSize)
+	copy(report, "spdm measurements")
+	h := sha512.New384()
+	h.Write(nonce[:])
+	h.Write(report[:len(report)-spdmSigSize])
+	r, s, err := ecdsa.Sign(rand.Reader, f.gpuKey, h.Sum(nil))
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	r.FillBytes(report[len(report)-spdmSigSize : len(report)-spdmSigSize/2])
+	s.FillBytes(report[len(report)-spdmSigSize/2:])
+
+	return &GPUAttestation{
+		DeviceID:     "GPU-CVM-001",
+		Model:        "H100",
+		CCEnabled:    true,
+		TEEIOEnabled: true,
+		Mode:         ModeLocal,
+		LocalEvidence: &LocalGPUEvidence{
+			SPDMReport:  report,
+			CertChain:   make([]byte, 1024),
+			RIMVerified: true,
+			Nonce:       nonce,
+		},
+	}
+}
+
+// snpQuote returns a signed SEV-SNP quote carrying reportData and nonce
+func (f *combinedFixture) snpQuote(reportData [32]byte, nonce []byte) *AttestationQuote {
+	quote := signedQuote(f.t, nil, TEETypeSEVSNP, func(q []byte) { copy(q[76:108], reportData[:]) })
+	quote.Nonce = nonce
+	return quote
+}
+
+// bound returns a quote bound to the GPU attestation answering nonce
+func (f *combinedFixture) bound(nonce [32]byte) *AttestationQuote {
+	return f.snpQuote(BindingReportData(nonce, "GPU-CVM-001"), nonce[:])
+}
+
+func TestVerifyCombined(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(f *combinedFixture) (*AttestationQuote, *GPUAttestation)
+		wantErr error
+	}{
+		{"bound", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			n := f.nonce()
+			return f.bound(n), f.gpuAtt(n)
+		}, nil},
+		{"nonce not issued", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			n := [32]byte{7, 7, 7}
+			return f.bound(n), f.gpuAtt(n)
+		}, ErrUnknownNonce},
+		{"quote without nonce", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			n := f.nonce()
+			return f.snpQuote(BindingReportData(n, "GPU-CVM-001"), nil), f.gpuAtt(n)
+		}, ErrInvalidNonce},
+		{"quote from another host", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			n := f.nonce()
+			return f.snpQuote([32]byte{9}, n[:]), f.gpuAtt(n)
+		}, ErrBindingMismatch},
+		{"different GPU nonce", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			return f.bound(f.nonce()), f.gpuAtt(f.nonce())
+		}, ErrBindingMismatch},
+		{"different device", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			n := f.nonce()
+			return f.snpQuote(BindingReportData(n, "GPU-OTHER"), n[:]), f.gpuAtt(n)
+		}, ErrBindingMismatch},
+		{"zero nonce", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			return f.bound([32]byte{}), f.gpuAtt([32]byte{})
+		}, ErrUnknownNonce},
+		{"tampered SPDM report", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			n := f.nonce()
+			att := f.gpuAtt(n)
+			att.LocalEvidence.SPDMReport[0] ^= 1
+			return f.bound(n), att
+		}, ErrSPDMVerifyFailed},
+		{"GPU key not registered", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			delete(f.v.gpuKeys, "GPU-CVM-001")
+			n := f.nonce()
+			return f.bound(n), f.gpuAtt(n)
+		}, ErrUntrustedGPUKey},
+		{"software GPU evidence", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			n := f.nonce()
+			att := f.gpuAtt(n)
+			att.Mode = ModeSoftware
+			att.LocalEvidence = nil
+			att.SoftwareAttestation = &SoftwareGPUAttestation{Nonce: n}
+			return f.bound(n), att
+		}, ErrNoHardwareEvidence},
+		{"nil GPU attestation", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			return f.bound(f.nonce()), nil
+		}, ErrInvalidQuote},
+		{"invalid quote", func(f *combinedFixture) (*AttestationQuote, *GPUAttestation) {
+			return &AttestationQuote{Type: TEETypeSEVSNP}, f.gpuAtt(f.nonce())
+		}, ErrInvalidQuote},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCombinedFixture(t)
+			quote, att := tt.build(f)
+			status, err := f.v.VerifyCombined(quote, att)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("VerifyCombined() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if _, ok := f.v.GetDeviceStatus("GPU-CVM-001"); ok {
+					t.Error("failed verification recorded a device status")
+				}
+				return
+			}
+			if !status.Attested || !status.HardwareCC {
+				t.Errorf("status = %+v, want attested with hardware CC", status)
+			}
+		})
+	}
+}
+
+func TestVerifyCombinedNonceSingleUse(t *testing.T) {
+	f := newCombinedFixture(t)
+	n := f.nonce()
+	quote, att := f.bound(n), f.gpuAtt(n)
+	if _, err := f.v.VerifyCombined(quote, att); err != nil {
+		t.Fatalf("VerifyCombined() error = %v", err)
+	}
+	if _, err := f.v.VerifyCombined(quote, att); err != ErrUnknownNonce {
+		t.Errorf("VerifyCombined() replayed = %v, want %v", err, ErrUnknownNonce)
+	}
+}
+
+func TestVerifyCombinedMeasurement(t *testing.T) {
+	f := newCombinedFixture(t)
+	n := f.nonce()
+	reportData := BindingReportData(n, "GPU-CVM-001")
+
+	// SGX: MRENCLAVE at 112, report data at 368
+	mrenclave := []byte("trusted-enclave-measurement-32b!")
+	quote := signedQuote(t, nil, TEETypeSGX, func(q []byte) {
+		copy(q[112:144], mrenclave)
+		copy(q[368:400], reportData[:])
+	})
+	quote.Nonce = n[:]
+	f.v.RegisterTrustedMeasurement("sgx-image", mrenclave)
+
+	status, err := f.v.VerifyCombined(quote, f.gpuAtt(n))
+	if err != nil {
+		t.Fatalf("VerifyCombined() error = %v", err)
+	}
+	if status.Measurement != "sgx-image" {
+		t.Errorf("Measurement = %q, want sgx-image", status.Measurement)
+	}
+
+	n = f.nonce()
+	unbound := signedQuote(t, nil, TEETypeSGX, func(q []byte) { copy(q[112:144], mrenclave) })
+	unbound.Nonce = n[:]
+	if _, err := f.v.VerifyCombined(unbound, f.gpuAtt(n)); err != ErrBindingMismatch {
+		t.Fatalf("VerifyCombined() unbound error = %v, want %v", err, ErrBindingMismatch)
+	}
+	if got := f.v.Stats().Rejected[RejectBinding]; got != 1 {
+		t.Errorf("Rejected[%s] = %d, want 1", RejectBinding, got)
+	}
+}
+
+func TestVerifyCombinedTDX(t *testing.T) {
+	tests := []struct {
+		name     string
+		bindMRTD bool // Binding written into MRTD instead of the report data
+		wantErr  error
+	}{
+		{"report data", false, nil},
+		{"MRTD", true, ErrBindingMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCombinedFixture(t)
+			n := f.nonce()
+			binding := BindingReportData(n, "GPU-CVM-001")
+			mrtd := make([]byte, 48)
+			if tt.bindMRTD {
+				copy(mrtd, binding[:])
+			}
+			f.v.RegisterTrustedMeasurement("td-image", mrtd)
+			quote := signedQuote(t, nil, TEETypeTDX, func(q []byte) {
+				copy(q[184:232], mrtd)
+				if !tt.bindMRTD {
+					copy(q[568:600], binding[:])
+				}
+			})
+			quote.Nonce = n[:]
+
+			if _, err := f.v.VerifyCombined(quote, f.gpuAtt(n)); err != tt.wantErr {
+				t.Errorf("VerifyCombined() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+var (
+	ErrNoGPUDetected   = errors.New("no GPU detected")
+	ErrNoSigningKey    = errors.New("signing key required for software attestation")
+	ErrMissingEvidence = errors.New("local GPU evidence required for CC-enabled GPU")
+)
+
+// canonicalGPUModels maps substrings of detected GPU names to the short
+// model names used by the verifier. More specific names come first so
+// "GB200" is not mistaken for "B200".
+var canonicalGPUModels = []string{
+	"GB200", "B200", "B100",
+	"H200", "H100",
+	"RTX PRO 6000",
+	"RTX 5090", "RTX 5080",
+	"RTX 4090", "RTX 4080",
+	"RTX 3090", "RTX 3080",
+	"A100",
+	"GB10",
+}
+
+// CanonicalGPUModel reduces a detected GPU name such as
+// "NVIDIA H100 80GB HBM3" to the short model name used for scoring ("H100").
+// Unknown names are returned unchanged.
+func CanonicalGPUModel(name string) string {
+	for _, model := range canonicalGPUModels {
+		if strings.Contains(name, model) {
+			return model
+		}
+	}
+	return name
+}
+
+// SoftwareAttestationDigest returns the digest a provider signs for a
+// software attestation: the device ID, model and CC flag of att, which the
+// verifier scores on, and every field of att.SoftwareAttestation except
+// the signature itself
+func SoftwareAttestationDigest(att *GPUAttestation) [32]byte {
+	sw := att.SoftwareAttestation
+	h := sha256.New()
+	for _, field := range []string{
+		att.DeviceID, att.Model,
+		sw.GPUSerial, sw.PCIID, sw.BoardID, sw.GPUPartNum, sw.ComputeCaps,
+		sw.DriverVersion, sw.CUDAVersion, sw.VBIOSVersion,
+	} {
+		// Length-prefix each field so adjacent fields cannot be re-split
+		var l [4]byte
+		binary.BigEndian.PutUint32(l[:], uint32(len(field)))
+		h.Write(l[:])
+		h.Write([]byte(field))
+	}
+	if att.CCEnabled {
+		h.Write([]byte{1})
+	} else {
+		h.Write([]byte{0})
+	}
+	h.Write(sw.BenchmarkSeed[:])
+	h.Write(sw.BenchmarkHash[:])
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], sw.BenchmarkTime)
+	h.Write(buf[:])
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(sw.ProviderID)))
+	h.Write(buf[:4])
+	h.Write([]byte(sw.ProviderID))
+	h.Write(sw.ProviderPubKey)
+	binary.BigEndian.PutUint64(buf[:], uint64(sw.Timestamp.UnixNano()))
+	h.Write(buf[:])
+	h.Write(sw.Nonce[:])
+	var digest [32]byte
+	copy(digest[:], h.Sum(nil))
+	return digest
+}
+
+// AttestationBuilder assembles a GPUAttestation from detected hardware.
+// CC-enabled GPUs produce ModeLocal attestations carrying nvtrust evidence;
+// all other GPUs produce a ModeSoftware attestation signed with the
+// provider's key. It is the producer-side counterpart of Verifier.
+type AttestationBuilder struct {
+	capability *cc.HardwareCapability
+	signingKey ed25519.PrivateKey
+	deviceID   string
+
+	evidence    *SPDMEvidence
+	rimVerified bool
+
+	benchmarkSeed [32]byte
+	benchmarkHash [32]byte
+	benchmarkTime uint64
+}
+
+// NewAttestationBuilder creates a builder for the given hardware capability.
+// The signing key is required for software attestation and may be nil for
+// CC-enabled GPUs.
+func NewAttestationBuilder(capability *cc.HardwareCapability, signingKey ed25519.PrivateKey) *AttestationBuilder {
+	b := &AttestationBuilder{
+		capability: capability,
+		signingKey: signingKey,
+	}
+	if capability != nil {
+		b.deviceID = capability.GPUSerial
+	}
+	return b
+}
+
+// WithDeviceID overrides the device ID (defaults to the GPU serial)
+func (b *AttestationBuilder) WithDeviceID(deviceID string) *AttestationBuilder {
+	b.deviceID = deviceID
+	return b
+}
+
+// WithLocalEvidence supplies nvtrust SPDM evidence for a CC-enabled GPU.
+// When omitted, Build collects evidence from the local GPU.
+func (b *AttestationBuilder) WithLocalEvidence(evidence *SPDMEvidence, rimVerified bool) *AttestationBuilder {
+	b.evidence = evidence
+	b.rimVerified = rimVerified
+	return b
+}
+
+// WithBenchmark answers a verifier-issued benchmark challenge for software
+// attestation, recording the result and how long the kernel took. The
+// verifier judges the answer by its own timing, not elapsed.
+func (b *AttestationBuilder) WithBenchmark(challenge *BenchmarkChallenge, elapsed time.Duration) *AttestationBuilder {
+	b.benchmarkSeed = challenge.Seed
+	b.benchmarkHash = RunBenchmarkKernel(challenge)
+	b.benchmarkTime = uint64(elapsed.Milliseconds())
+	return b
+}
+
+// Build produces the attestation appropriate for the hardware
+func (b *AttestationBuilder) Build() (*GPUAttestation, error) {
+	c := b.capability
+	if c == nil || c.GPUVendor == "" || c.GPUVendor == cc.VendorUnknown {
+		return nil, ErrNoGPUDetected
+	}
+
+	att := &GPUAttestation{
+		DeviceID:      b.deviceID,
+		Model:         CanonicalGPUModel(c.GPUModel),
+		CCEnabled:     c.GPUCCEnabled,
+		TEEIOEnabled:  c.TEEIOSupported && c.GPUCCEnabled,
+		DriverVersion: c.GPUDriverVer,
+		Timestamp:     time.Now(),
+	}
+
+	if c.GPUCCSupported && c.GPUCCEnabled {
+		return b.buildLocal(att)
+	}
+	return b.buildSoftware(att)
+}
+
+// buildLocal attaches nvtrust evidence for a CC-enabled GPU
+func (b *AttestationBuilder) buildLocal(att *GPUAttestation) (*GPUAttestation, error) {
+	evidence := b.evidence
+	if evidence == nil {
+		var err error
+		evidence, _, err = CollectGPUEvidence(0)
+		if err != nil {
+			return nil, err
+		}
+		if evidence == nil {
+			return nil, ErrMissingEvidence
+		}
+	}
+
+	att.Mode = ModeLocal
+	att.LocalEvidence = &LocalGPUEvidence{
+		SPDMReport:  evidence.RawReport,
+		CertChain:   evidence.CertificateChain,
+		RIMVerified: b.rimVerified,
+		Nonce:       evidence.Nonce,
+	}
+	if err := att.LocalEvidence.Validate(); err != nil {
+		return nil, err
+	}
+	return att, nil
+}
+
+// buildSoftware assembles and signs a software attestation
+func (b *AttestationBuilder) buildSoftware(att *GPUAttestation) (*GPUAttestation, error) {
+	if len(b.signingKey) != ed25519.PrivateKeySize {
+		return nil, ErrNoSigningKey
+	}
+
+	sw := &SoftwareGPUAttestation{
+		GPUSerial:      b.capability.GPUSerial,
+		ComputeCaps:    string(b.capability.ComputeCap),
+		DriverVersion:  b.capability.GPUDriverVer,
+		BenchmarkSeed:  b.benchmarkSeed,
+		BenchmarkHash:  b.benchmarkHash,
+		BenchmarkTime:  b.benchmarkTime,
+		ProviderPubKey: b.signingKey.Public().(ed25519.PublicKey),
+		Timestamp:      att.Timestamp,
+		Nonce:          GenerateAttestationNonce(),
+	}
+	att.Mode = ModeSoftware
+	att.SoftwareAttestation = sw
+	digest := SoftwareAttestationDigest(att)
+	sw.Signature = ed25519.Sign(b.signingKey, digest[:])
+
+	if err := sw.Validate(); err != nil {
+		return nil, err
+	}
+	return att, nil
+}
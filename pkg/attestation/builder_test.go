@@ -0,0 +1,134 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+func TestCanonicalGPUModel(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"NVIDIA H100 80GB HBM3", "H100"},
+		{"NVIDIA GB200", "GB200"},
+		{"NVIDIA B200", "B200"},
+		{"NVIDIA GeForce RTX 4090", "RTX 4090"},
+		{"NVIDIA RTX PRO 6000 Blackwell", "RTX PRO 6000"},
+		{"NVIDIA GB10", "GB10"},
+		{"Apple M3 Max", "Apple M3 Max"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalGPUModel(tt.name); got != tt.want {
+			t.Errorf("CanonicalGPUModel(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAttestationBuilderSoftware(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	capability := &cc.HardwareCapability{
+		GPUVendor:    cc.VendorNVIDIA,
+		GPUModel:     "NVIDIA GeForce RTX 4090",
+		GPUSerial:    "GPU-4090-0001",
+		GPUDriverVer: "570.00",
+		ComputeCap:   "8.9",
+	}
+
+	v, mock := mockVerifier()
+	if err := v.AuthorizeKey(capability.GPUSerial, pub, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("AuthorizeKey() error = %v", err)
+	}
+	ch, err := v.IssueBenchmarkChallenge(capability.GPUSerial)
+	if err != nil {
+		t.Fatalf("IssueBenchmarkChallenge() error = %v", err)
+	}
+	mock.Advance(1500 * time.Millisecond)
+
+	att, err := NewAttestationBuilder(capability, priv).
+		WithBenchmark(ch, 1500*time.Millisecond).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if att.Mode != ModeSoftware {
+		t.Errorf("Mode = %v, want %v", att.Mode, ModeSoftware)
+	}
+	if att.Model != "RTX 4090" {
+		t.Errorf("Model = %q, want %q", att.Model, "RTX 4090")
+	}
+
+	sw := att.SoftwareAttestation
+	digest := SoftwareAttestationDigest(att)
+	if !ed25519.Verify(pub, digest[:], sw.Signature) {
+		t.Error("software attestation signature does not verify")
+	}
+
+	status, err := v.VerifyGPUAttestation(att)
+	if err != nil {
+		t.Fatalf("VerifyGPUAttestation() error = %v", err)
+	}
+	if !status.Attested {
+		t.Error("built attestation should verify")
+	}
+}
+
+func TestAttestationBuilderSoftwareRequiresKey(t *testing.T) {
+	capability := &cc.HardwareCapability{GPUVendor: cc.VendorNVIDIA, GPUModel: "NVIDIA GeForce RTX 4090"}
+	if _, err := NewAttestationBuilder(capability, nil).Build(); err != ErrNoSigningKey {
+		t.Errorf("Build() error = %v, want %v", err, ErrNoSigningKey)
+	}
+}
+
+func TestAttestationBuilderNoGPU(t *testing.T) {
+	for _, capability := range []*cc.HardwareCapability{nil, {GPUVendor: cc.VendorUnknown}} {
+		if _, err := NewAttestationBuilder(capability, nil).Build(); err != ErrNoGPUDetected {
+			t.Errorf("Build() error = %v, want %v", err, ErrNoGPUDetected)
+		}
+	}
+}
+
+func TestAttestationBuilderLocal(t *testing.T) {
+	capability := &cc.HardwareCapability{
+		GPUVendor:      cc.VendorNVIDIA,
+		GPUModel:       "NVIDIA H100 80GB HBM3",
+		GPUSerial:      "GPU-H100-0001",
+		GPUCCSupported: true,
+		GPUCCEnabled:   true,
+		TEEIOSupported: true,
+	}
+	evidence := &SPDMEvidence{
+		RawReport:        make([]byte, 256),
+		CertificateChain: make([]byte, 1024),
+		Nonce:            GenerateAttestationNonce(),
+	}
+
+	att, err := NewAttestationBuilder(capability, nil).
+		WithLocalEvidence(evidence, true).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if att.Mode != ModeLocal {
+		t.Errorf("Mode = %v, want %v", att.Mode, ModeLocal)
+	}
+	if att.Model != "H100" || !att.CCEnabled || !att.TEEIOEnabled {
+		t.Errorf("unexpected attestation: model=%q cc=%v teeio=%v", att.Model, att.CCEnabled, att.TEEIOEnabled)
+	}
+	if att.LocalEvidence == nil || !att.LocalEvidence.RIMVerified {
+		t.Error("local evidence should carry RIM verification")
+	}
+	if att.SoftwareAttestation != nil {
+		t.Error("CC GPU should not produce a software attestation")
+	}
+}
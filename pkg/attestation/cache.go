@@ -0,0 +1,121 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+// SoftwareAttestationMaxAge is how old a software attestation's timestamp
+// may be when it is verified
+const SoftwareAttestationMaxAge = time.Hour
+
+// cacheEntry is a successful verification remembered for a device
+type cacheEntry struct {
+	hash    [32]byte
+	status  *DeviceStatus
+	expires time.Time
+}
+
+// CacheStats reports attestation cache effectiveness
+type CacheStats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+}
+
+// SetCacheTTL enables caching of successful GPU verifications. An identical
+// attestation re-submitted for the same device returns the cached status
+// without re-running verification until the entry expires. Entries live
+// for at most ttl, the attestation tier's validity window and, for software
+// attestations, SoftwareAttestationMaxAge from the attestation timestamp.
+// A ttl of zero disables the cache and drops existing entries.
+func (v *Verifier) SetCacheTTL(ttl time.Duration) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.cacheTTL = ttl
+	if ttl <= 0 {
+		v.resetCache()
+	}
+}
+
+// InvalidateCache drops all cached verifications. It is called whenever
+// keys or policy change, since a cached result may no longer hold.
+func (v *Verifier) InvalidateCache() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.resetCache()
+}
+
+// resetCache drops all cached verifications
+func (v *Verifier) resetCache() {
+	v.cache = make(map[string]*cacheEntry)
+}
+
+// CacheStats returns hit and miss counts since the verifier was created
+func (v *Verifier) CacheStats() CacheStats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return CacheStats{
+		Hits:    v.cacheHits,
+		Misses:  v.cacheMisses,
+		Entries: len(v.cache),
+	}
+}
+
+// cachedStatus returns the cached status for an identical attestation
+func (v *Verifier) cachedStatus(att *GPUAttestation, hash [32]byte, now time.Time) (*DeviceStatus, bool) {
+	if v.cacheTTL <= 0 {
+		return nil, false
+	}
+	entry, ok := v.cache[att.DeviceID]
+	if !ok || entry.hash != hash || !now.Before(entry.expires) {
+		v.cacheMisses++
+		return nil, false
+	}
+	v.cacheHits++
+	entry.status.LastSeen = now
+	return entry.status, true
+}
+
+// cacheStatus remembers a successful verification
+func (v *Verifier) cacheStatus(att *GPUAttestation, hash [32]byte, status *DeviceStatus, now time.Time) {
+	if v.cacheTTL <= 0 {
+		return
+	}
+	ttl := v.cacheTTL
+	if validity := GPUAttestationTier(att).AttestationValidity(); validity < ttl {
+		ttl = validity
+	}
+	expires := now.Add(ttl)
+	if sw := att.SoftwareAttestation; status.Mode == ModeSoftware && sw != nil {
+		if stale := sw.Timestamp.Add(SoftwareAttestationMaxAge); stale.Before(expires) {
+			expires = stale
+		}
+	}
+	v.cache[att.DeviceID] = &cacheEntry{hash: hash, status: status, expires: expires}
+}
+
+// GPUAttestationTier is the CC tier a verified GPU attestation supports:
+// tier 1 for full hardware CC, tier 2 for limited CC and tier 4 for
+// software attestation
+func GPUAttestationTier(att *GPUAttestation) cc.CCTier {
+	if att.Mode == ModeSoftware || att.LocalEvidence == nil {
+		return cc.Tier4Standard
+	}
+	if GPUCCCapability(att.Model) == cc.GPUCCLimited {
+		return cc.Tier2ConfidentialVM
+	}
+	return cc.Tier1GPUNativeCC
+}
+
+// gpuAttestationHash identifies an attestation's full contents
+func gpuAttestationHash(att *GPUAttestation) [32]byte {
+	data, _ := json.Marshal(att)
+	return sha256.Sum256(data)
+}
@@ -0,0 +1,153 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+	"github.com/luxfi/ai/pkg/clock"
+)
+
+func newLocalAttestation(deviceID, model string) *GPUAttestation {
+	return &GPUAttestation{
+		DeviceID:  deviceID,
+		Model:     model,
+		CCEnabled: true,
+		Mode:      ModeLocal,
+		LocalEvidence: &LocalGPUEvidence{
+			SPDMReport:  make([]byte, 512),
+			CertChain:   make([]byte, 1024),
+			RIMVerified: true,
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+func TestVerifierCacheDisabledByDefault(t *testing.T) {
+	v := NewVerifier()
+	att := newLocalAttestation("GPU-001", "H100")
+	for i := 0; i < 2; i++ {
+		if _, err := v.VerifyGPUAttestation(att); err != nil {
+			t.Fatalf("VerifyGPUAttestation() error = %v", err)
+		}
+	}
+	if stats := v.CacheStats(); stats != (CacheStats{}) {
+		t.Errorf("CacheStats() = %+v, want zero with cache disabled", stats)
+	}
+}
+
+func TestVerifierCacheHits(t *testing.T) {
+	v := NewVerifier()
+	v.SetCacheTTL(time.Hour)
+	att := newLocalAttestation("GPU-001", "H100")
+
+	first, err := v.VerifyGPUAttestation(att)
+	if err != nil {
+		t.Fatalf("VerifyGPUAttestation() error = %v", err)
+	}
+	second, err := v.VerifyGPUAttestation(att)
+	if err != nil {
+		t.Fatalf("VerifyGPUAttestation() error = %v", err)
+	}
+	if first != second {
+		t.Error("identical re-submission should return the cached status")
+	}
+
+	// A changed attestation is verified again
+	changed := newLocalAttestation("GPU-001", "H100")
+	changed.LocalEvidence.RIMVerified = false
+	third, err := v.VerifyGPUAttestation(changed)
+	if err != nil {
+		t.Fatalf("VerifyGPUAttestation() error = %v", err)
+	}
+	if third == second || third.HardwareCC {
+		t.Error("changed attestation should be re-verified")
+	}
+
+	want := CacheStats{Hits: 1, Misses: 2, Entries: 1}
+	if got := v.CacheStats(); got != want {
+		t.Errorf("CacheStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestVerifierCacheExpiry(t *testing.T) {
+	v := NewVerifier()
+	mock := clock.NewMock(time.Now())
+	v.SetClock(mock)
+	v.SetCacheTTL(time.Hour)
+	att := newLocalAttestation("GPU-001", "H100")
+	if _, err := v.VerifyGPUAttestation(att); err != nil {
+		t.Fatalf("VerifyGPUAttestation() error = %v", err)
+	}
+
+	mock.Advance(time.Hour - time.Nanosecond)
+	if _, err := v.VerifyGPUAttestation(att); err != nil {
+		t.Fatalf("VerifyGPUAttestation() error = %v", err)
+	}
+	mock.Advance(time.Nanosecond)
+	if _, err := v.VerifyGPUAttestation(att); err != nil {
+		t.Fatalf("VerifyGPUAttestation() error = %v", err)
+	}
+	if stats := v.CacheStats(); stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("CacheStats() = %+v, want a hit until the TTL, then a miss", stats)
+	}
+}
+
+func TestVerifierCacheBoundedByTierValidity(t *testing.T) {
+	tests := []struct {
+		name string
+		att  *GPUAttestation
+		max  time.Duration
+	}{
+		{"full CC", newLocalAttestation("GPU-H100", "H100"), cc.Tier1GPUNativeCC.AttestationValidity()},
+		{"limited CC", newLocalAttestation("GPU-A100", "A100"), cc.Tier2ConfidentialVM.AttestationValidity()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier()
+			mock := clock.NewMock(time.Now())
+			v.SetClock(mock)
+			v.SetCacheTTL(365 * 24 * time.Hour)
+			if _, err := v.VerifyGPUAttestation(tt.att); err != nil {
+				t.Fatalf("VerifyGPUAttestation() error = %v", err)
+			}
+			if ttl := v.cache[tt.att.DeviceID].expires.Sub(mock.Now()); ttl != tt.max {
+				t.Errorf("entry lives %v, want %v", ttl, tt.max)
+			}
+		})
+	}
+}
+
+func TestVerifierCacheSoftwareFreshness(t *testing.T) {
+	v := NewVerifier()
+	mock := clock.NewMock(time.Now())
+	v.SetClock(mock)
+	v.SetCacheTTL(24 * time.Hour)
+	att := newBenchmarkAttestation(t, v, "GPU-001", "RTX 5090", [32]byte{}, [32]byte{1}, 1500)
+	att.SoftwareAttestation.Timestamp = mock.Now().Add(-50 * time.Minute)
+	signSoftwareAttestation(t, v, att)
+
+	if _, err := v.VerifyGPUAttestation(att); err != nil {
+		t.Fatalf("VerifyGPUAttestation() error = %v", err)
+	}
+	if want := att.SoftwareAttestation.Timestamp.Add(SoftwareAttestationMaxAge); !v.cache["GPU-001"].expires.Equal(want) {
+		t.Errorf("entry expires %v, want %v with the attestation timestamp", v.cache["GPU-001"].expires, want)
+	}
+}
+
+func TestVerifierCacheInvalidatedOnRevoke(t *testing.T) {
+	v := NewVerifier()
+	v.SetCacheTTL(time.Hour)
+	att := newBenchmarkAttestation(t, v, "GPU-001", "RTX 5090", [32]byte{}, [32]byte{1}, 1500)
+	if _, err := v.VerifyGPUAttestation(att); err != nil {
+		t.Fatalf("VerifyGPUAttestation() error = %v", err)
+	}
+
+	v.RevokeKey("GPU-001", att.SoftwareAttestation.ProviderPubKey)
+	if _, err := v.VerifyGPUAttestation(att); err != ErrInvalidSignature {
+		t.Errorf("VerifyGPUAttestation() after revocation = %v, want %v", err, ErrInvalidSignature)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// DefaultNonceSize is the nonce length, in bytes, expected in CPU TEE
+	// quotes. It matches the fixed-size nonces in GPU evidence.
+	DefaultNonceSize = 32
+
+	// MinNonceSize is the shortest nonce a verifier can be configured to
+	// issue and accept
+	MinNonceSize = 16
+
+	// nonceTTL is how long an issued nonce stays usable, the same as the
+	// maximum quote age
+	nonceTTL = time.Hour
+)
+
+var (
+	ErrInvalidNonce = errors.New("invalid nonce length")
+	ErrUnknownNonce = errors.New("nonce not issued by this verifier or already used")
+)
+
+// ChallengeConfig controls the challenges a verifier issues to devices:
+// quote nonces and benchmark challenges
+type ChallengeConfig struct {
+	// NonceSize is the length of issued nonces. Quotes carrying a nonce of
+	// any other length are rejected. Zero means DefaultNonceSize.
+	NonceSize int
+
+	// Rand is the source of nonces and benchmark seeds. Nil means
+	// crypto/rand; tests may substitute a deterministic reader.
+	Rand io.Reader
+
+	// BenchmarkMemoryBlocks and BenchmarkIterations size issued benchmark
+	// challenges. Zero means DefaultBenchmarkMemoryBlocks and
+	// DefaultBenchmarkIterations; BenchmarkTimeRange assumes the defaults.
+	BenchmarkMemoryBlocks uint32
+	BenchmarkIterations   uint32
+}
+
+// DefaultChallengeConfig returns the production challenge configuration,
+// reading from crypto/rand
+func DefaultChallengeConfig() ChallengeConfig {
+	return ChallengeConfig{
+		NonceSize:             DefaultNonceSize,
+		Rand:                  rand.Reader,
+		BenchmarkMemoryBlocks: DefaultBenchmarkMemoryBlocks,
+		BenchmarkIterations:   DefaultBenchmarkIterations,
+	}
+}
+
+// SetChallengeConfig replaces the verifier's challenge configuration. Zero
+// fields take their defaults.
+func (v *Verifier) SetChallengeConfig(cfg ChallengeConfig) error {
+	if cfg.NonceSize == 0 {
+		cfg.NonceSize = DefaultNonceSize
+	}
+	if cfg.NonceSize < MinNonceSize {
+		return fmt.Errorf("%w: %d bytes, minimum is %d", ErrInvalidNonce, cfg.NonceSize, MinNonceSize)
+	}
+	if cfg.Rand == nil {
+		cfg.Rand = rand.Reader
+	}
+	if cfg.BenchmarkMemoryBlocks == 0 {
+		cfg.BenchmarkMemoryBlocks = DefaultBenchmarkMemoryBlocks
+	}
+	if cfg.BenchmarkIterations == 0 {
+		cfg.BenchmarkIterations = DefaultBenchmarkIterations
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.challenge = cfg
+	return nil
+}
+
+// ChallengeConfig returns the verifier's challenge configuration
+func (v *Verifier) ChallengeConfig() ChallengeConfig {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.challenge
+}
+
+// NewNonce reads a fresh nonce of the configured size for a device to
+// bind into its next quote. The verifier remembers it until it is used or
+// an hour has passed.
+func (v *Verifier) NewNonce() ([]byte, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	nonce := make([]byte, v.challenge.NonceSize)
+	if _, err := io.ReadFull(v.challenge.Rand, nonce); err != nil {
+		return nil, err
+	}
+
+	now := v.now()
+	for n, issued := range v.nonces {
+		if now.Sub(issued) > nonceTTL {
+			delete(v.nonces, n)
+		}
+	}
+	v.nonces[string(nonce)] = now
+	return nonce, nil
+}
+
+// useNonce consumes a nonce issued by NewNonce, so each is accepted once
+func (v *Verifier) useNonce(nonce []byte) error {
+	issued, ok := v.nonces[string(nonce)]
+	if !ok || v.now().Sub(issued) > nonceTTL {
+		return ErrUnknownNonce
+	}
+	delete(v.nonces, string(nonce))
+	return nil
+}
+
+// checkNonce requires a quote nonce this verifier issued and has not seen
+// used, and consumes it. A quote without a nonce is rejected.
+func (v *Verifier) checkNonce(nonce []byte) error {
+	if len(nonce) != v.challenge.NonceSize {
+		return fmt.Errorf("%w: got %d bytes, want %d", ErrInvalidNonce, len(nonce), v.challenge.NonceSize)
+	}
+	return v.useNonce(nonce)
+}
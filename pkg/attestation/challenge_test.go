@@ -0,0 +1,153 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/clock"
+)
+
+func TestDefaultChallengeConfigUsesCryptoRand(t *testing.T) {
+	cfg := NewVerifier().ChallengeConfig()
+	if cfg.NonceSize != DefaultNonceSize {
+		t.Errorf("NonceSize = %d, want %d", cfg.NonceSize, DefaultNonceSize)
+	}
+	if cfg.Rand != rand.Reader {
+		t.Error("default Rand is not crypto/rand.Reader")
+	}
+}
+
+func TestSetChallengeConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      ChallengeConfig
+		wantSize int
+		wantErr  bool
+	}{
+		{"zero value", ChallengeConfig{}, DefaultNonceSize, false},
+		{"minimum", ChallengeConfig{NonceSize: MinNonceSize}, MinNonceSize, false},
+		{"long", ChallengeConfig{NonceSize: 64}, 64, false},
+		{"too short", ChallengeConfig{NonceSize: MinNonceSize - 1}, DefaultNonceSize, true},
+		{"negative", ChallengeConfig{NonceSize: -1}, DefaultNonceSize, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier()
+			err := v.SetChallengeConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetChallengeConfig() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidNonce) {
+				t.Errorf("SetChallengeConfig() error = %v, want ErrInvalidNonce", err)
+			}
+			cfg := v.ChallengeConfig()
+			if cfg.NonceSize != tt.wantSize {
+				t.Errorf("NonceSize = %d, want %d", cfg.NonceSize, tt.wantSize)
+			}
+			if cfg.Rand == nil {
+				t.Error("Rand = nil, want a default source")
+			}
+		})
+	}
+}
+
+func TestDeterministicChallenges(t *testing.T) {
+	newVerifier := func() *Verifier {
+		v := NewVerifier()
+		src := bytes.NewReader(bytes.Repeat([]byte{0xab}, 128))
+		if err := v.SetChallengeConfig(ChallengeConfig{NonceSize: 24, Rand: src}); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	a, b := newVerifier(), newVerifier()
+	nonceA, err := a.NewNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonceB, _ := b.NewNonce()
+	if len(nonceA) != 24 || !bytes.Equal(nonceA, nonceB) {
+		t.Errorf("NewNonce() = %x and %x, want equal 24-byte nonces", nonceA, nonceB)
+	}
+
+	chA, err := a.IssueBenchmarkChallenge("gpu-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chB, _ := b.IssueBenchmarkChallenge("gpu-0")
+	if chA.Seed != chB.Seed || chA.Seed[0] != 0xab {
+		t.Errorf("Seed = %x and %x, want equal seeds from the injected reader", chA.Seed, chB.Seed)
+	}
+}
+
+func TestNewNonceShortRead(t *testing.T) {
+	v := NewVerifier()
+	v.SetChallengeConfig(ChallengeConfig{Rand: bytes.NewReader(make([]byte, 8))})
+	if _, err := v.NewNonce(); err == nil {
+		t.Error("NewNonce() from an exhausted reader should fail")
+	}
+	if _, err := v.IssueBenchmarkChallenge("gpu-0"); err == nil {
+		t.Error("IssueBenchmarkChallenge() from an exhausted reader should fail")
+	}
+}
+
+func TestVerifyCPUAttestationNonce(t *testing.T) {
+	tests := []struct {
+		name    string
+		nonce   func(v *Verifier) []byte
+		wantErr error
+	}{
+		{"issued", func(v *Verifier) []byte { n, _ := v.NewNonce(); return n }, nil},
+		{"no nonce", func(*Verifier) []byte { return nil }, ErrInvalidNonce},
+		{"too short", func(*Verifier) []byte { return make([]byte, DefaultNonceSize-1) }, ErrInvalidNonce},
+		{"too long", func(*Verifier) []byte { return make([]byte, DefaultNonceSize+1) }, ErrInvalidNonce},
+		{"not issued", func(*Verifier) []byte { return make([]byte, DefaultNonceSize) }, ErrUnknownNonce},
+		{"issued by another verifier", func(*Verifier) []byte { n, _ := NewVerifier().NewNonce(); return n }, ErrUnknownNonce},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier()
+			trustQuoteKeys(v)
+			quote := signedQuote(t, nil, TEETypeSGX, nil)
+			quote.Nonce = tt.nonce(v)
+			err := v.VerifyCPUAttestation(quote, make([]byte, 32))
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("VerifyCPUAttestation() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	v := NewVerifier()
+	v.SetChallengeConfig(ChallengeConfig{NonceSize: MinNonceSize})
+	trustQuoteKeys(v)
+	quote := signedQuote(t, v, TEETypeSGX, nil)
+	if err := v.VerifyCPUAttestation(quote, make([]byte, 32)); err != nil {
+		t.Errorf("VerifyCPUAttestation() with an issued %d-byte nonce = %v", MinNonceSize, err)
+	}
+	if err := v.VerifyCPUAttestation(quote, make([]byte, 32)); err != ErrUnknownNonce {
+		t.Errorf("VerifyCPUAttestation() reusing a nonce = %v, want %v", err, ErrUnknownNonce)
+	}
+}
+
+func TestNonceExpires(t *testing.T) {
+	mock := clock.NewMock(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	v := NewVerifier()
+	v.SetClock(mock)
+	stale, _ := v.NewNonce()
+	mock.Advance(nonceTTL + time.Second)
+	if err := v.useNonce(stale); err != ErrUnknownNonce {
+		t.Errorf("useNonce() after %v = %v, want %v", nonceTTL, err, ErrUnknownNonce)
+	}
+
+	v.NewNonce()
+	if _, ok := v.nonces[string(stale)]; ok {
+		t.Error("NewNonce() kept an expired nonce")
+	}
+}
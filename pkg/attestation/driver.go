@@ -0,0 +1,254 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidVersion = errors.New("invalid version string")
+
+// Version is a dotted numeric version such as a driver "570.86.15", a
+// CUDA "12.8" or a VBIOS "96.02.26.00.01". Missing trailing components
+// compare as zero.
+type Version []uint64
+
+// ParseVersion parses a dotted numeric version string
+func ParseVersion(s string) (Version, error) {
+	return parseVersion(s, 10)
+}
+
+// ParseVBIOSVersion parses a VBIOS version as nvidia-smi prints it, such
+// as "96.00.5C.00.01", whose components are hexadecimal
+func ParseVBIOSVersion(s string) (Version, error) {
+	return parseVersion(s, 16)
+}
+
+func parseVersion(s string, base int) (Version, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, ErrInvalidVersion
+	}
+	parts := strings.Split(s, ".")
+	v := make(Version, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, base, 32)
+		if err != nil {
+			return nil, ErrInvalidVersion
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// MustParseVersion is ParseVersion for constant inputs; it panics on error
+func MustParseVersion(s string) Version {
+	v, err := ParseVersion(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Compare returns -1, 0 or 1 as v is older than, equal to or newer than o
+func (v Version) Compare(o Version) int {
+	n := len(v)
+	if len(o) > n {
+		n = len(o)
+	}
+	for i := 0; i < n; i++ {
+		var a, b uint64
+		if i < len(v) {
+			a = v[i]
+		}
+		if i < len(o) {
+			b = o[i]
+		}
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	}
+	return 0
+}
+
+// String formats the version in dotted form
+func (v Version) String() string {
+	parts := make([]string, len(v))
+	for i, n := range v {
+		parts[i] = strconv.FormatUint(n, 10)
+	}
+	return strings.Join(parts, ".")
+}
+
+// MarshalJSON encodes the version as a dotted string
+func (v Version) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+// UnmarshalJSON decodes a dotted version string such as "550.54"
+func (v *Version) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseVersion(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// VersionRange is an inclusive range of versions
+type VersionRange struct {
+	Min Version `json:"min"`
+	Max Version `json:"max"`
+}
+
+// Contains reports whether v falls within the range
+func (r VersionRange) Contains(v Version) bool {
+	return v.Compare(r.Min) >= 0 && v.Compare(r.Max) <= 0
+}
+
+// VBIOSRange is an inclusive range of VBIOS versions, written as nvidia-smi
+// prints them
+type VBIOSRange struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
+// Contains reports whether v falls within the range. A range whose bounds
+// do not parse contains nothing.
+func (r VBIOSRange) Contains(v Version) bool {
+	min, err := ParseVBIOSVersion(r.Min)
+	if err != nil {
+		return false
+	}
+	max, err := ParseVBIOSVersion(r.Max)
+	if err != nil {
+		return false
+	}
+	return VersionRange{Min: min, Max: max}.Contains(v)
+}
+
+// GPU architecture families used for driver policy
+const (
+	FamilyBlackwell = "blackwell"
+	FamilyHopper    = "hopper"
+	FamilyAda       = "ada"
+	FamilyAmpere    = "ampere"
+)
+
+// GPUFamily returns the architecture family for a canonical GPU model name,
+// or "" if unknown
+func GPUFamily(model string) string {
+	switch model {
+	case "GB200", "B200", "B100", "RTX PRO 6000", "RTX 5090", "RTX 5080", "GB10":
+		return FamilyBlackwell
+	case "H200", "H100":
+		return FamilyHopper
+	case "RTX 4090", "RTX 4080":
+		return FamilyAda
+	case "A100", "RTX 3090", "RTX 3080":
+		return FamilyAmpere
+	default:
+		return ""
+	}
+}
+
+// DriverPolicy decides whether the driver, CUDA and VBIOS versions in a
+// software attestation earn the version bonus or a vulnerability penalty
+type DriverPolicy struct {
+	// MinRecommended is the minimum recommended driver per GPU family
+	MinRecommended map[string]Version `json:"min_recommended"`
+
+	// DefaultMinRecommended applies to families not in MinRecommended
+	DefaultMinRecommended Version `json:"default_min_recommended"`
+
+	// Vulnerable lists driver ranges with known security issues. Populate
+	// from NVIDIA security bulletins; drivers in these ranges are penalized.
+	Vulnerable []VersionRange `json:"vulnerable,omitempty"`
+
+	// VulnerableVBIOS lists VBIOS ranges with known security issues,
+	// penalized like vulnerable drivers
+	VulnerableVBIOS []VBIOSRange `json:"vulnerable_vbios,omitempty"`
+
+	// MinCUDA is the oldest CUDA runtime that keeps the driver bonus. A
+	// missing or unparseable CUDA version forfeits the bonus when set.
+	MinCUDA Version `json:"min_cuda,omitempty"`
+}
+
+// DefaultDriverPolicy returns the minimum driver branches that support
+// each architecture family. It lists no vulnerable driver or VBIOS ranges:
+// those follow NVIDIA security bulletins, which change faster than this
+// package, so operators supply them (lux-ai -driver-policy) and until they
+// do no version is penalized.
+func DefaultDriverPolicy() *DriverPolicy {
+	return &DriverPolicy{
+		MinRecommended: map[string]Version{
+			FamilyBlackwell: MustParseVersion("570.0"),
+			FamilyHopper:    MustParseVersion("535.0"),
+			FamilyAda:       MustParseVersion("535.0"),
+			FamilyAmpere:    MustParseVersion("535.0"),
+		},
+		DefaultMinRecommended: MustParseVersion("535.0"),
+	}
+}
+
+// Driver score adjustments
+const (
+	driverRecentBonus       = 5
+	driverVulnerablePenalty = 5
+)
+
+// versionScoreAdjustment returns the trust score change for the versions a
+// software attestation reports: -5 if the driver or VBIOS is known to be
+// vulnerable, otherwise the driver adjustment, withheld when the CUDA
+// runtime is older than MinCUDA
+func (p *DriverPolicy) versionScoreAdjustment(model string, sw *SoftwareGPUAttestation) int {
+	if vbios, err := ParseVBIOSVersion(sw.VBIOSVersion); err == nil {
+		for _, r := range p.VulnerableVBIOS {
+			if r.Contains(vbios) {
+				return -driverVulnerablePenalty
+			}
+		}
+	}
+	adj := p.driverScoreAdjustment(model, sw.DriverVersion)
+	if adj > 0 && len(p.MinCUDA) > 0 {
+		cuda, err := ParseVersion(sw.CUDAVersion)
+		if err != nil || cuda.Compare(p.MinCUDA) < 0 {
+			return 0
+		}
+	}
+	return adj
+}
+
+// driverScoreAdjustment returns the trust score change for a driver:
+// +5 at or above the family's recommended minimum, 0 for older or
+// unparseable versions, and -5 for known-vulnerable versions.
+func (p *DriverPolicy) driverScoreAdjustment(model, driver string) int {
+	v, err := ParseVersion(driver)
+	if err != nil {
+		return 0
+	}
+	for _, r := range p.Vulnerable {
+		if r.Contains(v) {
+			return -driverVulnerablePenalty
+		}
+	}
+	min, ok := p.MinRecommended[GPUFamily(model)]
+	if !ok {
+		min = p.DefaultMinRecommended
+	}
+	if v.Compare(min) >= 0 {
+		return driverRecentBonus
+	}
+	return 0
+}
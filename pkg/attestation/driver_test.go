@@ -0,0 +1,171 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"570.86.15", "570.86.15", false},
+		{"550.54", "550.54", false},
+		{" 535 ", "535", false},
+		{"", "", true},
+		{"570.x", "", true},
+		{"570..1", "", true},
+		{"-1.0", "", true},
+	}
+
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseVersion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && v.String() != tt.want {
+			t.Errorf("ParseVersion(%q) = %s, want %s", tt.in, v, tt.want)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"570.86.15", "570.86.15", 0},
+		{"570", "570.0.0", 0},
+		{"570.9", "570.86", -1}, // Numeric, not lexical
+		{"1000.0", "999.99", 1},
+		{"535.183.01", "535.183", 1},
+	}
+
+	for _, tt := range tests {
+		if got := MustParseVersion(tt.a).Compare(MustParseVersion(tt.b)); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDriverScoreAdjustment(t *testing.T) {
+	policy := DefaultDriverPolicy()
+	policy.Vulnerable = []VersionRange{
+		{Min: MustParseVersion("550.0"), Max: MustParseVersion("550.99")},
+	}
+
+	tests := []struct {
+		name   string
+		model  string
+		driver string
+		want   int
+	}{
+		{"blackwell recent", "RTX 5090", "572.16", driverRecentBonus},
+		{"blackwell at minimum", "RTX 5090", "570.0", driverRecentBonus},
+		{"blackwell too old", "RTX 5090", "565.90", 0},
+		{"ada recent", "RTX 4090", "560.35.03", driverRecentBonus},
+		{"ada too old", "RTX 4090", "525.60", 0},
+		{"vulnerable", "RTX 4090", "550.54.14", -driverVulnerablePenalty},
+		{"unknown family uses default", "Quadro P4000", "535.0", driverRecentBonus},
+		{"unparseable", "RTX 4090", "latest", 0},
+		{"empty", "RTX 4090", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.driverScoreAdjustment(tt.model, tt.driver); got != tt.want {
+				t.Errorf("driverScoreAdjustment(%s, %s) = %d, want %d", tt.model, tt.driver, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionScoreAdjustment(t *testing.T) {
+	policy := DefaultDriverPolicy()
+	policy.MinCUDA = MustParseVersion("12.4")
+	policy.VulnerableVBIOS = []VBIOSRange{{Min: "95.02", Max: "95.02.FF"}}
+
+	tests := []struct {
+		name               string
+		driver, cuda, bios string
+		want               int
+	}{
+		{"all current", "572.16", "12.8", "96.02.26.00.01", driverRecentBonus},
+		{"CUDA at minimum", "572.16", "12.4", "", driverRecentBonus},
+		{"CUDA too old", "572.16", "12.2", "", 0},
+		{"CUDA missing", "572.16", "", "", 0},
+		{"CUDA unparseable", "572.16", "twelve", "", 0},
+		{"vulnerable VBIOS", "572.16", "12.8", "95.02.5C.00.01", -driverVulnerablePenalty},
+		{"VBIOS past range", "572.16", "12.8", "95.03.00.00.01", driverRecentBonus},
+		{"vulnerable VBIOS old driver", "525.60", "12.8", "95.02.18", -driverVulnerablePenalty},
+		{"unparseable VBIOS", "572.16", "12.8", "unknown", driverRecentBonus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sw := &SoftwareGPUAttestation{DriverVersion: tt.driver, CUDAVersion: tt.cuda, VBIOSVersion: tt.bios}
+			if got := policy.versionScoreAdjustment("RTX 5090", sw); got != tt.want {
+				t.Errorf("versionScoreAdjustment(%s, %s, %s) = %d, want %d", tt.driver, tt.cuda, tt.bios, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDriverPolicyJSON(t *testing.T) {
+	var policy DriverPolicy
+	data := `{"default_min_recommended": "535.0", "vulnerable": [{"min": "550.0", "max": "550.99"}], "min_cuda": "12.4"}`
+	if err := json.Unmarshal([]byte(data), &policy); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !policy.Vulnerable[0].Contains(MustParseVersion("550.54.14")) {
+		t.Errorf("Vulnerable = %v, want 550.0-550.99", policy.Vulnerable)
+	}
+	if policy.MinCUDA.String() != "12.4" {
+		t.Errorf("MinCUDA = %s, want 12.4", policy.MinCUDA)
+	}
+	if err := json.Unmarshal([]byte(`{"min_cuda": "12.x"}`), &policy); err != ErrInvalidVersion {
+		t.Errorf("Unmarshal() bad version error = %v, want %v", err, ErrInvalidVersion)
+	}
+}
+
+func TestSoftwareTrustScoreDriverPolicy(t *testing.T) {
+	score := func(v *Verifier, driver string) uint8 {
+		att := &GPUAttestation{
+			DeviceID: "GPU-001",
+			Model:    "RTX 4090",
+			Mode:     ModeSoftware,
+			SoftwareAttestation: &SoftwareGPUAttestation{
+				GPUSerial:     "GPU-SERIAL-1",
+				DriverVersion: driver,
+				Timestamp:     time.Now(),
+			},
+		}
+		signSoftwareAttestation(t, v, att)
+		status, err := v.VerifyGPUAttestation(att)
+		if err != nil {
+			t.Fatalf("VerifyGPUAttestation() error = %v", err)
+		}
+		return status.TrustScore
+	}
+
+	v := NewVerifier()
+	recent, old := score(v, "570.86.15"), score(v, "470.42")
+	if recent != old+driverRecentBonus {
+		t.Errorf("recent driver score = %d, old = %d; want +%d", recent, old, driverRecentBonus)
+	}
+
+	v.SetDriverPolicy(&DriverPolicy{
+		DefaultMinRecommended: MustParseVersion("535.0"),
+		Vulnerable:            []VersionRange{{Min: MustParseVersion("570.0"), Max: MustParseVersion("570.99")}},
+	})
+	if got := score(v, "570.86.15"); got != old-driverVulnerablePenalty {
+		t.Errorf("vulnerable driver score = %d, want %d", got, old-driverVulnerablePenalty)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+// Minimum evidence sizes. Anything shorter cannot be a well-formed report,
+// so it is rejected before any cryptographic checks run.
+const (
+	// MinSPDMReportSize is the smallest SPDM MEASUREMENTS response
+	// accepted: header, a measurement block carrying a SHA-384 digest, the
+	// requester nonce and an ECDSA P-384 signature
+	MinSPDMReportSize = 256
+
+	// MinCertChainSize is the smallest GPU certificate chain accepted; a
+	// single DER-encoded X.509 certificate is larger than this
+	MinCertChainSize = 256
+
+	// MinSPDMSignatureSize is the smallest SPDM measurement signature
+	// accepted, a raw ECDSA P-256 (r, s) pair
+	MinSPDMSignatureSize = 64
+
+	// MinProviderSignatureSize is the size of the Ed25519 signature over a
+	// software attestation
+	MinProviderSignatureSize = 64
+
+	// MinProviderPubKeySize is the size of an Ed25519 provider public key
+	MinProviderPubKeySize = 32
+)
+
+// Evidence shape errors. Each also matches, via errors.Is, the generic
+// error verification returned before it existed, so existing checks for
+// ErrInvalidQuote, ErrInvalidSignature, ErrCertChainInvalid or
+// ErrSPDMVerifyFailed keep working.
+var (
+	ErrSPDMTooShort          = newEvidenceError("SPDM report too short", ErrInvalidQuote, ErrSPDMVerifyFailed)
+	ErrCertChainTooShort     = newEvidenceError("certificate chain too short", ErrInvalidQuote, ErrCertChainInvalid)
+	ErrSPDMSignatureTooShort = newEvidenceError("SPDM signature too short", ErrInvalidQuote, ErrSPDMVerifyFailed)
+	ErrMissingGPUSerial      = newEvidenceError("software attestation missing GPU serial", ErrInvalidQuote)
+	ErrMissingDriverVersion  = newEvidenceError("software attestation missing driver version", ErrInvalidQuote)
+	ErrSignatureTooShort     = newEvidenceError("provider signature too short", ErrInvalidSignature)
+	ErrPubKeyTooShort        = newEvidenceError("provider public key too short", ErrInvalidSignature)
+)
+
+// evidenceError is a specific evidence defect that also matches the
+// generic errors it refines
+type evidenceError struct {
+	msg     string
+	generic []error
+}
+
+func newEvidenceError(msg string, generic ...error) error {
+	return &evidenceError{msg: msg, generic: generic}
+}
+
+func (e *evidenceError) Error() string { return e.msg }
+
+func (e *evidenceError) Is(target error) bool {
+	for _, g := range e.generic {
+		if target == g {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that local nvtrust evidence is large enough to be a real
+// SPDM report and certificate chain. Verification runs the same checks;
+// callers can use it to reject malformed evidence before submission.
+func (ev *LocalGPUEvidence) Validate() error {
+	if len(ev.SPDMReport) < MinSPDMReportSize {
+		return ErrSPDMTooShort
+	}
+	if len(ev.CertChain) < MinCertChainSize {
+		return ErrCertChainTooShort
+	}
+	return nil
+}
+
+// Validate checks that a software attestation names its GPU and driver and
+// carries a full-size signature and public key. It does not verify the
+// signature itself.
+func (sw *SoftwareGPUAttestation) Validate() error {
+	if sw.GPUSerial == "" {
+		return ErrMissingGPUSerial
+	}
+	if sw.DriverVersion == "" {
+		return ErrMissingDriverVersion
+	}
+	if len(sw.Signature) < MinProviderSignatureSize {
+		return ErrSignatureTooShort
+	}
+	if len(sw.ProviderPubKey) < MinProviderPubKeySize {
+		return ErrPubKeyTooShort
+	}
+	return nil
+}
+
+// Validate checks that collected SPDM evidence has a full-size certificate
+// chain, signature and raw report
+func (evidence *SPDMEvidence) Validate() error {
+	if len(evidence.CertificateChain) < MinCertChainSize {
+		return ErrCertChainTooShort
+	}
+	if len(evidence.Signature) < MinSPDMSignatureSize {
+		return ErrSPDMSignatureTooShort
+	}
+	if len(evidence.RawReport) < MinSPDMReportSize {
+		return ErrSPDMTooShort
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLocalGPUEvidenceValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		ev      LocalGPUEvidence
+		wantErr error
+	}{
+		{"valid", LocalGPUEvidence{SPDMReport: make([]byte, MinSPDMReportSize), CertChain: make([]byte, MinCertChainSize)}, nil},
+		{"short SPDM", LocalGPUEvidence{SPDMReport: make([]byte, MinSPDMReportSize-1), CertChain: make([]byte, MinCertChainSize)}, ErrSPDMTooShort},
+		{"short cert chain", LocalGPUEvidence{SPDMReport: make([]byte, MinSPDMReportSize), CertChain: make([]byte, 10)}, ErrCertChainTooShort},
+	}
+	for _, tt := range tests {
+		if err := tt.ev.Validate(); err != tt.wantErr {
+			t.Errorf("%s: Validate() = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSoftwareGPUAttestationValidate(t *testing.T) {
+	valid := func() SoftwareGPUAttestation {
+		return SoftwareGPUAttestation{
+			GPUSerial:      "GPU-1",
+			DriverVersion:  "570.00",
+			Signature:      make([]byte, MinProviderSignatureSize),
+			ProviderPubKey: make([]byte, MinProviderPubKeySize),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(sw *SoftwareGPUAttestation)
+		wantErr error
+	}{
+		{"valid", func(*SoftwareGPUAttestation) {}, nil},
+		{"missing serial", func(sw *SoftwareGPUAttestation) { sw.GPUSerial = "" }, ErrMissingGPUSerial},
+		{"missing driver", func(sw *SoftwareGPUAttestation) { sw.DriverVersion = "" }, ErrMissingDriverVersion},
+		{"short signature", func(sw *SoftwareGPUAttestation) { sw.Signature = sw.Signature[:10] }, ErrSignatureTooShort},
+		{"short public key", func(sw *SoftwareGPUAttestation) { sw.ProviderPubKey = nil }, ErrPubKeyTooShort},
+	}
+	for _, tt := range tests {
+		sw := valid()
+		tt.mutate(&sw)
+		if err := sw.Validate(); err != tt.wantErr {
+			t.Errorf("%s: Validate() = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSPDMEvidenceValidate(t *testing.T) {
+	valid := SPDMEvidence{
+		CertificateChain: make([]byte, MinCertChainSize),
+		Signature:        make([]byte, MinSPDMSignatureSize),
+		RawReport:        make([]byte, MinSPDMReportSize),
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+
+	short := valid
+	short.Signature = nil
+	if err := short.Validate(); err != ErrSPDMSignatureTooShort {
+		t.Errorf("Validate() = %v, want %v", err, ErrSPDMSignatureTooShort)
+	}
+
+	nv := NewNvtrustVerifier(nil)
+	short = valid
+	short.RawReport = short.RawReport[:100]
+	if _, err := nv.VerifyGPU(&short, &GPUHardwareInfo{Model: "H100"}); err != ErrSPDMTooShort {
+		t.Errorf("VerifyGPU() = %v, want %v", err, ErrSPDMTooShort)
+	}
+}
+
+// TestEvidenceErrorsMatchGeneric keeps errors.Is checks written against the
+// generic errors working
+func TestEvidenceErrorsMatchGeneric(t *testing.T) {
+	tests := []struct {
+		err     error
+		generic error
+		want    bool
+	}{
+		{ErrSPDMTooShort, ErrInvalidQuote, true},
+		{ErrSPDMTooShort, ErrSPDMVerifyFailed, true},
+		{ErrCertChainTooShort, ErrCertChainInvalid, true},
+		{ErrMissingGPUSerial, ErrInvalidQuote, true},
+		{ErrSignatureTooShort, ErrInvalidSignature, true},
+		{ErrPubKeyTooShort, ErrInvalidQuote, false},
+	}
+	for _, tt := range tests {
+		if got := errors.Is(tt.err, tt.generic); got != tt.want {
+			t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.generic, got, tt.want)
+		}
+	}
+}
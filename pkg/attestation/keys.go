@@ -0,0 +1,100 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+var ErrInvalidKey = errors.New("invalid provider public key")
+
+// authorizedKey is a provider signing key and the end of its validity window
+type authorizedKey struct {
+	pubKey   ed25519.PublicKey
+	notAfter time.Time
+}
+
+// AuthorizeKey authorizes pubKey to sign software attestations for a
+// provider until notAfter. Providers rotate keys by authorizing the new key
+// and letting the old one expire (or revoking it). Authorizing a key that is
+// already present updates its expiry.
+func (v *Verifier) AuthorizeKey(providerID string, pubKey ed25519.PublicKey, notAfter time.Time) error {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return ErrInvalidKey
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	keys := v.authorizedKeys[providerID]
+	for i := range keys {
+		if bytes.Equal(keys[i].pubKey, pubKey) {
+			keys[i].notAfter = notAfter
+			v.resetCache()
+			return nil
+		}
+	}
+	key := authorizedKey{pubKey: append(ed25519.PublicKey(nil), pubKey...), notAfter: notAfter}
+	v.authorizedKeys[providerID] = append(keys, key)
+	return nil
+}
+
+// RevokeKey removes a provider key before its expiry
+func (v *Verifier) RevokeKey(providerID string, pubKey ed25519.PublicKey) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys := v.authorizedKeys[providerID]
+	for i := range keys {
+		if bytes.Equal(keys[i].pubKey, pubKey) {
+			v.authorizedKeys[providerID] = append(keys[:i], keys[i+1:]...)
+			break
+		}
+	}
+	if len(v.authorizedKeys[providerID]) == 0 {
+		delete(v.authorizedKeys, providerID)
+	}
+	v.resetCache()
+}
+
+var _ cc.KeyAuthorizer = (*Verifier)(nil)
+
+// IsKeyAuthorized reports whether pubKey is currently authorized to sign
+// for the provider
+func (v *Verifier) IsKeyAuthorized(providerID string, pubKey []byte) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.isKeyAuthorized(providerID, pubKey, v.now())
+}
+
+// isKeyAuthorized reports whether pubKey is authorized and unexpired for
+// the provider at time now
+func (v *Verifier) isKeyAuthorized(providerID string, pubKey []byte, now time.Time) bool {
+	for _, k := range v.authorizedKeys[providerID] {
+		if bytes.Equal(k.pubKey, pubKey) {
+			return now.Before(k.notAfter)
+		}
+	}
+	return false
+}
+
+// verifyProviderSignature checks that a software attestation is signed by
+// a key currently authorized for its provider
+func (v *Verifier) verifyProviderSignature(att *GPUAttestation, sw *SoftwareGPUAttestation) error {
+	providerID := sw.ProviderID
+	if providerID == "" {
+		providerID = att.DeviceID
+	}
+	if len(sw.ProviderPubKey) != ed25519.PublicKeySize || !v.isKeyAuthorized(providerID, sw.ProviderPubKey, v.now()) {
+		return ErrInvalidSignature
+	}
+	digest := SoftwareAttestationDigest(att)
+	if !ed25519.Verify(sw.ProviderPubKey, digest[:], sw.Signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
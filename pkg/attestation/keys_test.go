@@ -0,0 +1,169 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+// signSoftwareAttestation authorizes a fresh key for the attestation's
+// device and signs it, returning the private key
+func signSoftwareAttestation(t *testing.T, v *Verifier, att *GPUAttestation) ed25519.PrivateKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	if err := v.AuthorizeKey(att.DeviceID, pub, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("AuthorizeKey() error = %v", err)
+	}
+	resign(att, priv)
+	return priv
+}
+
+// resign signs att's software attestation with priv
+func resign(att *GPUAttestation, priv ed25519.PrivateKey) {
+	sw := att.SoftwareAttestation
+	sw.ProviderPubKey = priv.Public().(ed25519.PublicKey)
+	digest := SoftwareAttestationDigest(att)
+	sw.Signature = ed25519.Sign(priv, digest[:])
+}
+
+func TestAuthorizeKeyRejectsMalformedKey(t *testing.T) {
+	v := NewVerifier()
+	if err := v.AuthorizeKey("provider", make([]byte, 10), time.Now().Add(time.Hour)); err != ErrInvalidKey {
+		t.Errorf("AuthorizeKey() error = %v, want %v", err, ErrInvalidKey)
+	}
+}
+
+func TestSoftwareAttestationKeyAuthorization(t *testing.T) {
+	newAtt := func() *GPUAttestation {
+		return &GPUAttestation{
+			DeviceID: "GPU-001",
+			Model:    "RTX 4090",
+			Mode:     ModeSoftware,
+			SoftwareAttestation: &SoftwareGPUAttestation{
+				GPUSerial:     "GPU-SERIAL-1",
+				DriverVersion: "570.00",
+				Timestamp:     time.Now(),
+			},
+		}
+	}
+	_, unknown, _ := ed25519.GenerateKey(nil)
+
+	tests := []struct {
+		name    string
+		setup   func(v *Verifier, att *GPUAttestation)
+		wantErr error
+	}{
+		{"authorized key", func(v *Verifier, att *GPUAttestation) {
+			signSoftwareAttestation(t, v, att)
+		}, nil},
+		{"unknown key", func(v *Verifier, att *GPUAttestation) {
+			resign(att, unknown)
+		}, ErrInvalidSignature},
+		{"expired key", func(v *Verifier, att *GPUAttestation) {
+			v.AuthorizeKey(att.DeviceID, unknown.Public().(ed25519.PublicKey), time.Now().Add(-time.Minute))
+			resign(att, unknown)
+		}, ErrInvalidSignature},
+		{"key authorized for another provider", func(v *Verifier, att *GPUAttestation) {
+			v.AuthorizeKey("someone-else", unknown.Public().(ed25519.PublicKey), time.Now().Add(time.Hour))
+			resign(att, unknown)
+		}, ErrInvalidSignature},
+		{"tampered attestation", func(v *Verifier, att *GPUAttestation) {
+			signSoftwareAttestation(t, v, att)
+			att.SoftwareAttestation.DriverVersion = "999.99"
+		}, ErrInvalidSignature},
+		{"tampered model", func(v *Verifier, att *GPUAttestation) {
+			signSoftwareAttestation(t, v, att)
+			att.Model = "RTX 5090"
+		}, ErrInvalidSignature},
+		{"tampered CC flag", func(v *Verifier, att *GPUAttestation) {
+			signSoftwareAttestation(t, v, att)
+			att.CCEnabled = true
+		}, ErrInvalidSignature},
+		{"tampered device ID", func(v *Verifier, att *GPUAttestation) {
+			att.SoftwareAttestation.ProviderID = "provider-7"
+			v.AuthorizeKey("provider-7", unknown.Public().(ed25519.PublicKey), time.Now().Add(time.Hour))
+			resign(att, unknown)
+			att.DeviceID = "GPU-002"
+		}, ErrInvalidSignature},
+		{"explicit provider ID", func(v *Verifier, att *GPUAttestation) {
+			att.SoftwareAttestation.ProviderID = "provider-7"
+			v.AuthorizeKey("provider-7", unknown.Public().(ed25519.PublicKey), time.Now().Add(time.Hour))
+			resign(att, unknown)
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier()
+			att := newAtt()
+			tt.setup(v, att)
+			if _, err := v.VerifyGPUAttestation(att); err != tt.wantErr {
+				t.Errorf("VerifyGPUAttestation() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	v := NewVerifier()
+	_, oldKey, _ := ed25519.GenerateKey(nil)
+	_, newKey, _ := ed25519.GenerateKey(nil)
+	v.AuthorizeKey("GPU-001", oldKey.Public().(ed25519.PublicKey), time.Now().Add(time.Hour))
+	v.AuthorizeKey("GPU-001", newKey.Public().(ed25519.PublicKey), time.Now().Add(24*time.Hour))
+
+	att := &GPUAttestation{
+		DeviceID: "GPU-001",
+		Model:    "RTX 4090",
+		Mode:     ModeSoftware,
+		SoftwareAttestation: &SoftwareGPUAttestation{
+			GPUSerial:     "GPU-SERIAL-1",
+			DriverVersion: "570.00",
+			Timestamp:     time.Now(),
+		},
+	}
+
+	// Both keys are valid during the overlap
+	for _, key := range []ed25519.PrivateKey{oldKey, newKey} {
+		resign(att, key)
+		if _, err := v.VerifyGPUAttestation(att); err != nil {
+			t.Errorf("VerifyGPUAttestation() during overlap error = %v", err)
+		}
+	}
+
+	v.RevokeKey("GPU-001", oldKey.Public().(ed25519.PublicKey))
+	resign(att, oldKey)
+	if _, err := v.VerifyGPUAttestation(att); err != ErrInvalidSignature {
+		t.Errorf("revoked key error = %v, want %v", err, ErrInvalidSignature)
+	}
+	resign(att, newKey)
+	if _, err := v.VerifyGPUAttestation(att); err != nil {
+		t.Errorf("rotated key error = %v", err)
+	}
+}
+
+func TestVerifierAuthorizesPoolRegistration(t *testing.T) {
+	v := NewVerifier()
+	pub, _, _ := ed25519.GenerateKey(nil)
+	pool := cc.NewAIRewardPool(time.Hour)
+	pool.KeyAuthorizer = v
+	provider := &cc.AIProvider{ProviderID: "provider", StakeLUX: 1_000, SigningKey: pub}
+
+	if err := pool.RegisterProvider(provider); !errors.Is(err, cc.ErrUnauthorizedKey) {
+		t.Fatalf("RegisterProvider() before AuthorizeKey = %v, want %v", err, cc.ErrUnauthorizedKey)
+	}
+	if err := v.AuthorizeKey("provider", pub, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("AuthorizeKey() error = %v", err)
+	}
+	if err := pool.RegisterProvider(provider); err != nil {
+		t.Errorf("RegisterProvider() after AuthorizeKey = %v", err)
+	}
+}
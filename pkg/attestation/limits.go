@@ -0,0 +1,116 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Default maximum evidence sizes. Real SPDM reports and NVIDIA certificate
+// chains are a few KiB; the limits leave ample headroom while keeping a
+// hostile attestation from forcing large allocations.
+const (
+	DefaultMaxSPDMReportSize   = 64 << 10
+	DefaultMaxCertChainSize    = 64 << 10
+	DefaultMaxDriverReportSize = 64 << 10
+	DefaultMaxAttestationSize  = 256 << 10
+)
+
+var ErrEvidenceTooLarge = errors.New("attestation evidence too large")
+
+// EvidenceLimits bounds the size of attestation evidence, in bytes. Zero
+// fields take their defaults.
+type EvidenceLimits struct {
+	SPDMReport   int // LocalGPUEvidence.SPDMReport
+	CertChain    int // LocalGPUEvidence.CertChain
+	DriverReport int // LocalGPUEvidence.DriverReport
+
+	// Total bounds the variable-length fields of a whole GPU attestation
+	// or CPU quote, identity strings included
+	Total int
+}
+
+// DefaultEvidenceLimits returns the default evidence size limits
+func DefaultEvidenceLimits() EvidenceLimits {
+	return EvidenceLimits{
+		SPDMReport:   DefaultMaxSPDMReportSize,
+		CertChain:    DefaultMaxCertChainSize,
+		DriverReport: DefaultMaxDriverReportSize,
+		Total:        DefaultMaxAttestationSize,
+	}
+}
+
+// withDefaults fills zero limits with their defaults
+func (l EvidenceLimits) withDefaults() EvidenceLimits {
+	d := DefaultEvidenceLimits()
+	if l.SPDMReport <= 0 {
+		l.SPDMReport = d.SPDMReport
+	}
+	if l.CertChain <= 0 {
+		l.CertChain = d.CertChain
+	}
+	if l.DriverReport <= 0 {
+		l.DriverReport = d.DriverReport
+	}
+	if l.Total <= 0 {
+		l.Total = d.Total
+	}
+	return l
+}
+
+// SetEvidenceLimits replaces the verifier's evidence size limits
+func (v *Verifier) SetEvidenceLimits(limits EvidenceLimits) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.limits = limits.withDefaults()
+}
+
+// EvidenceLimits returns the verifier's evidence size limits
+func (v *Verifier) EvidenceLimits() EvidenceLimits {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.limits
+}
+
+// checkGPUSize rejects an attestation with an oversized evidence field or
+// total size. It only reads lengths, so it runs before anything hashes or
+// parses the evidence.
+func (v *Verifier) checkGPUSize(att *GPUAttestation) error {
+	total := len(att.DeviceID) + len(att.Model) + len(att.DriverVersion) + len(att.VBIOSVersion)
+	if ev := att.LocalEvidence; ev != nil {
+		for _, field := range []struct {
+			name  string
+			size  int
+			limit int
+		}{
+			{"spdm_report", len(ev.SPDMReport), v.limits.SPDMReport},
+			{"cert_chain", len(ev.CertChain), v.limits.CertChain},
+			{"driver_report", len(ev.DriverReport), v.limits.DriverReport},
+		} {
+			if field.size > field.limit {
+				return fmt.Errorf("%w: %s is %d bytes, limit %d", ErrEvidenceTooLarge, field.name, field.size, field.limit)
+			}
+			total += field.size
+		}
+	}
+	if sw := att.SoftwareAttestation; sw != nil {
+		total += len(sw.GPUSerial) + len(sw.PCIID) + len(sw.BoardID) + len(sw.GPUPartNum) +
+			len(sw.ComputeCaps) + len(sw.DriverVersion) + len(sw.CUDAVersion) + len(sw.VBIOSVersion) +
+			len(sw.ProviderID) + len(sw.ProviderPubKey) + len(sw.Signature)
+	}
+	return v.checkTotalSize(total)
+}
+
+// checkQuoteSize rejects a CPU quote whose fields exceed the total limit
+func (v *Verifier) checkQuoteSize(quote *AttestationQuote) error {
+	return v.checkTotalSize(len(quote.Quote) + len(quote.Measurement) + len(quote.ReportData) + len(quote.Nonce))
+}
+
+func (v *Verifier) checkTotalSize(total int) error {
+	if total > v.limits.Total {
+		return fmt.Errorf("%w: attestation is %d bytes, limit %d", ErrEvidenceTooLarge, total, v.limits.Total)
+	}
+	return nil
+}
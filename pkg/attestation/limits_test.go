@@ -0,0 +1,82 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyGPUAttestationEvidenceLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(att *GPUAttestation)
+		wantErr bool
+	}{
+		{"within limits", func(att *GPUAttestation) {}, false},
+		{"SPDM report at limit", func(att *GPUAttestation) {
+			att.LocalEvidence.SPDMReport = make([]byte, DefaultMaxSPDMReportSize)
+		}, false},
+		{"SPDM report over limit", func(att *GPUAttestation) {
+			att.LocalEvidence.SPDMReport = make([]byte, DefaultMaxSPDMReportSize+1)
+		}, true},
+		{"cert chain over limit", func(att *GPUAttestation) {
+			att.LocalEvidence.CertChain = make([]byte, DefaultMaxCertChainSize+1)
+		}, true},
+		{"driver report over limit", func(att *GPUAttestation) {
+			att.LocalEvidence.DriverReport = make([]byte, DefaultMaxDriverReportSize+1)
+		}, true},
+		{"oversized identity", func(att *GPUAttestation) {
+			att.Model = "H100" + strings.Repeat(" ", DefaultMaxAttestationSize)
+		}, true},
+		{"oversized software attestation", func(att *GPUAttestation) {
+			att.Mode = ModeSoftware
+			att.LocalEvidence = nil
+			att.SoftwareAttestation = &SoftwareGPUAttestation{Signature: make([]byte, DefaultMaxAttestationSize+1)}
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier()
+			att := newLocalAttestation("GPU-001", "H100")
+			tt.modify(att)
+			_, err := v.VerifyGPUAttestation(att)
+			if got := errors.Is(err, ErrEvidenceTooLarge); got != tt.wantErr {
+				t.Errorf("VerifyGPUAttestation() = %v, want ErrEvidenceTooLarge %v", err, tt.wantErr)
+			}
+			if tt.wantErr && v.Stats().Rejected[RejectInvalid] != 1 {
+				t.Errorf("Stats().Rejected = %v, want one invalid", v.Stats().Rejected)
+			}
+		})
+	}
+}
+
+func TestSetEvidenceLimits(t *testing.T) {
+	v := NewVerifier()
+	v.SetEvidenceLimits(EvidenceLimits{SPDMReport: 1024})
+	limits := v.EvidenceLimits()
+	if limits.SPDMReport != 1024 || limits.CertChain != DefaultMaxCertChainSize || limits.Total != DefaultMaxAttestationSize {
+		t.Errorf("EvidenceLimits() = %+v, want SPDM 1024 and other defaults", limits)
+	}
+
+	att := newLocalAttestation("GPU-001", "H100")
+	att.LocalEvidence.SPDMReport = make([]byte, 1025)
+	if _, err := v.VerifyGPUAttestation(att); !errors.Is(err, ErrEvidenceTooLarge) {
+		t.Errorf("VerifyGPUAttestation() = %v, want ErrEvidenceTooLarge", err)
+	}
+
+	trustQuoteKeys(v)
+	quote := signedQuote(t, v, TEETypeSGX, nil)
+	v.SetEvidenceLimits(EvidenceLimits{Total: len(quote.Quote) + len(quote.Nonce)})
+	signed := quote.Quote
+	quote.Quote = append(signed, 0)
+	if err := v.VerifyCPUAttestation(quote, make([]byte, 32)); !errors.Is(err, ErrEvidenceTooLarge) {
+		t.Errorf("VerifyCPUAttestation() = %v, want ErrEvidenceTooLarge", err)
+	}
+	quote.Quote = signed
+	if err := v.VerifyCPUAttestation(quote, make([]byte, 32)); err != nil {
+		t.Errorf("VerifyCPUAttestation() at the limit = %v", err)
+	}
+}
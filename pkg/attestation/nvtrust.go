@@ -11,6 +11,7 @@
 // Supported GPUs (hardware CC capable):
 //   - Datacenter: H100, H200, B100, B200, GB200
 //   - Professional: RTX PRO 6000 Blackwell
+//   - Limited CC: A100 (Ampere), scored below full CC
 //
 // NOT supported (no CC hardware - confirmed by NVIDIA):
 //   - Consumer: RTX 5090, RTX 4090, etc
@@ -24,10 +25,11 @@
 package attestation
 
 import (
-	"crypto/sha256"
-	"encoding/binary"
+	"crypto/rand"
 	"errors"
 	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
 )
 
 var (
@@ -134,8 +136,8 @@ func (nv *NvtrustVerifier) VerifyGPU(evidence *SPDMEvidence, gpuInfo *GPUHardwar
 		return nil, ErrInvalidQuote
 	}
 
-	// Step 1: Check if GPU model supports CC
-	if !IsHardwareCCCapable(gpuInfo.Model) {
+	// Step 1: Check if GPU model supports CC (full or limited)
+	if GPUCCCapability(gpuInfo.Model) == cc.GPUCCNone {
 		return nil, ErrGPUNotCCCapable
 	}
 
@@ -195,8 +197,8 @@ type GPUHardwareInfo struct {
 
 // verifyCertificateChain verifies the GPU certificate chain up to NVIDIA root
 func (nv *NvtrustVerifier) verifyCertificateChain(certChain []byte) error {
-	if len(certChain) < 256 {
-		return ErrCertChainInvalid
+	if len(certChain) < MinCertChainSize {
+		return ErrCertChainTooShort
 	}
 
 	// In production: Parse X.509 certificate chain
@@ -211,8 +213,8 @@ func (nv *NvtrustVerifier) verifyCertificateChain(certChain []byte) error {
 
 // verifySPDMSignature verifies the SPDM measurement signature
 func (nv *NvtrustVerifier) verifySPDMSignature(evidence *SPDMEvidence) error {
-	if len(evidence.Signature) < 64 {
-		return ErrSPDMVerifyFailed
+	if len(evidence.Signature) < MinSPDMSignatureSize {
+		return ErrSPDMSignatureTooShort
 	}
 
 	// In production: Extract public key from certificate
@@ -220,8 +222,8 @@ func (nv *NvtrustVerifier) verifySPDMSignature(evidence *SPDMEvidence) error {
 	// Check nonce freshness
 
 	// Verify raw report structure (SPDM 1.1 MEASUREMENT response)
-	if len(evidence.RawReport) < 256 {
-		return ErrSPDMVerifyFailed
+	if len(evidence.RawReport) < MinSPDMReportSize {
+		return ErrSPDMTooShort
 	}
 
 	return nil
@@ -260,6 +262,10 @@ func (nv *NvtrustVerifier) verifyAgainstRIM(measurementHash [48]byte, gpuInfo *G
 
 // calculateLocalTrustScore calculates trust score for local verification
 func (nv *NvtrustVerifier) calculateLocalTrustScore(gpuInfo *GPUHardwareInfo, rimVerified bool) uint8 {
+	if GPUCCCapability(gpuInfo.Model) == cc.GPUCCLimited {
+		return limitedCCTrustScore(gpuInfo.CCEnabled, rimVerified)
+	}
+
 	// Base score for local nvtrust verification: 70
 	// This is PRIMARY method - scores equivalent to NRAS
 	score := uint8(70)
@@ -316,15 +322,10 @@ func CollectGPUEvidence(deviceIndex int) (*SPDMEvidence, *GPUHardwareInfo, error
 	return nil, nil, ErrNvtrustNotAvailable
 }
 
-// GenerateAttestationNonce generates a fresh nonce for attestation
+// GenerateAttestationNonce generates a fresh random nonce for attestation
 func GenerateAttestationNonce() [32]byte {
 	var nonce [32]byte
-	// In production: Use crypto/rand
-	// For now: Use timestamp-based nonce
-	ts := time.Now().UnixNano()
-	binary.LittleEndian.PutUint64(nonce[:8], uint64(ts))
-	hash := sha256.Sum256(nonce[:])
-	copy(nonce[:], hash[:])
+	rand.Read(nonce[:]) // Never fails; see crypto/rand.Read
 	return nonce
 }
 
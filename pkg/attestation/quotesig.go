@@ -0,0 +1,96 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+	"slices"
+)
+
+// Signed regions and signature offsets of CPU TEE quotes. SGX (v3) and TDX
+// (v4) quotes carry an ECDSA P-256 signature over the header and report
+// body, followed by the attestation key that made it. SEV-SNP reports are
+// signed with the chip's P-384 VCEK over the first 0x2A0 bytes.
+const (
+	sgxSignedSize = 48 + 384
+	tdxSignedSize = 48 + 584
+	snpSignedSize = 0x2A0
+
+	quoteSigSize    = 64 // r || s, big-endian
+	quoteKeySize    = 64 // x || y, big-endian
+	snpSigFieldSize = 72 // r and s each, little-endian
+)
+
+var ErrUntrustedQuoteKey = errors.New("quote signed by an untrusted key")
+
+// RegisterQuoteKey trusts key to sign CPU TEE quotes: a quoting enclave
+// attestation key (P-256) for SGX and TDX, or a chip's VCEK (P-384) for
+// SEV-SNP. The verifier does not walk the PCK or VCEK certificate chain;
+// callers validate the key against the vendor root before registering it.
+func (v *Verifier) RegisterQuoteKey(key *ecdsa.PublicKey) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.quoteKeys = append(v.quoteKeys, key)
+}
+
+// verifyQuoteSignature checks that a CPU quote's body is signed by a
+// registered quote key
+func (v *Verifier) verifyQuoteSignature(quote *AttestationQuote) error {
+	data := quote.Quote
+	switch quote.Type {
+	case TEETypeSGX, TEETypeTDX:
+		signed := sgxSignedSize
+		if quote.Type == TEETypeTDX {
+			signed = tdxSignedSize
+		}
+		sigStart := signed + 4 // Signature data length
+		if len(data) < sigStart+quoteSigSize+quoteKeySize {
+			return ErrInvalidQuote
+		}
+		sig := data[sigStart : sigStart+quoteSigSize]
+		raw := data[sigStart+quoteSigSize : sigStart+quoteSigSize+quoteKeySize]
+		key := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(raw[:32]),
+			Y:     new(big.Int).SetBytes(raw[32:]),
+		}
+		if !slices.ContainsFunc(v.quoteKeys, func(k *ecdsa.PublicKey) bool { return key.Equal(k) }) {
+			return ErrUntrustedQuoteKey
+		}
+		digest := sha256.Sum256(data[:signed])
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(key, digest[:], r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case TEETypeSEVSNP:
+		if len(data) < snpSignedSize+2*snpSigFieldSize {
+			return ErrInvalidQuote
+		}
+		digest := sha512.Sum384(data[:snpSignedSize])
+		r := littleEndianInt(data[snpSignedSize : snpSignedSize+snpSigFieldSize])
+		s := littleEndianInt(data[snpSignedSize+snpSigFieldSize : snpSignedSize+2*snpSigFieldSize])
+		for _, key := range v.quoteKeys {
+			if key.Curve == elliptic.P384() && ecdsa.Verify(key, digest[:], r, s) {
+				return nil
+			}
+		}
+		return ErrInvalidSignature
+	default:
+		return ErrUnsupportedTEE
+	}
+}
+
+// littleEndianInt decodes a little-endian unsigned integer
+func littleEndianInt(b []byte) *big.Int {
+	be := slices.Clone(b)
+	slices.Reverse(be)
+	return new(big.Int).SetBytes(be)
+}
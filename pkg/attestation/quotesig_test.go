@@ -0,0 +1,165 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testQuoteKeys are the P-256 attestation key and P-384 VCEK that sign
+// test quotes
+var testQuoteKeys = sync.OnceValues(func() (*ecdsa.PrivateKey, *ecdsa.PrivateKey) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return p256, p384
+})
+
+// trustQuoteKeys registers the test quote keys with v
+func trustQuoteKeys(v *Verifier) {
+	p256, p384 := testQuoteKeys()
+	v.RegisterQuoteKey(&p256.PublicKey)
+	v.RegisterQuoteKey(&p384.PublicKey)
+}
+
+// signedQuote builds a quote of the given type, lets fill set its body and
+// signs it with the test quote keys. If v is not nil the quote carries a
+// nonce issued by v.
+func signedQuote(t *testing.T, v *Verifier, typ TEEType, fill func(q []byte)) *AttestationQuote {
+	t.Helper()
+	size := map[TEEType]int{
+		TEETypeSGX:    sgxSignedSize + 4 + quoteSigSize + quoteKeySize,
+		TEETypeTDX:    tdxSignedSize + 4 + quoteSigSize + quoteKeySize,
+		TEETypeSEVSNP: 1184,
+	}[typ]
+	q := make([]byte, size)
+	if fill != nil {
+		fill(q)
+	}
+	signQuote(t, typ, q)
+	quote := &AttestationQuote{Type: typ, Quote: q, Timestamp: time.Now()}
+	if v != nil {
+		nonce, err := v.NewNonce()
+		if err != nil {
+			t.Fatal(err)
+		}
+		quote.Nonce = nonce
+	}
+	return quote
+}
+
+// signQuote writes the test quote keys' signature into q
+func signQuote(t *testing.T, typ TEEType, q []byte) {
+	t.Helper()
+	p256, p384 := testQuoteKeys()
+	switch typ {
+	case TEETypeSGX, TEETypeTDX:
+		signed := sgxSignedSize
+		if typ == TEETypeTDX {
+			signed = tdxSignedSize
+		}
+		digest := sha256.Sum256(q[:signed])
+		r, s, err := ecdsa.Sign(rand.Reader, p256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := q[signed+4:]
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:64])
+		p256.X.FillBytes(sig[64:96])
+		p256.Y.FillBytes(sig[96:128])
+	case TEETypeSEVSNP:
+		digest := sha512.Sum384(q[:snpSignedSize])
+		r, s, err := ecdsa.Sign(rand.Reader, p384, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		rField := q[snpSignedSize : snpSignedSize+snpSigFieldSize]
+		sField := q[snpSignedSize+snpSigFieldSize : snpSignedSize+2*snpSigFieldSize]
+		r.FillBytes(rField)
+		s.FillBytes(sField)
+		slices.Reverse(rField)
+		slices.Reverse(sField)
+	}
+}
+
+func TestVerifyQuoteSignature(t *testing.T) {
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, typ := range []TEEType{TEETypeSGX, TEETypeTDX, TEETypeSEVSNP} {
+		t.Run(typ.String(), func(t *testing.T) {
+			v := NewVerifier()
+			quote := signedQuote(t, nil, typ, nil)
+			if err := v.verifyQuoteSignature(quote); err == nil {
+				t.Fatal("verifyQuoteSignature() with no trusted keys = nil")
+			}
+
+			trustQuoteKeys(v)
+			if err := v.verifyQuoteSignature(quote); err != nil {
+				t.Fatalf("verifyQuoteSignature() = %v", err)
+			}
+
+			quote.Quote[100] ^= 1
+			if err := v.verifyQuoteSignature(quote); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("verifyQuoteSignature() tampered = %v, want %v", err, ErrInvalidSignature)
+			}
+
+			if typ == TEETypeSEVSNP {
+				return
+			}
+			v = NewVerifier()
+			v.RegisterQuoteKey(&other.PublicKey)
+			if err := v.verifyQuoteSignature(signedQuote(t, nil, typ, nil)); !errors.Is(err, ErrUntrustedQuoteKey) {
+				t.Errorf("verifyQuoteSignature() untrusted key = %v, want %v", err, ErrUntrustedQuoteKey)
+			}
+		})
+	}
+}
+
+func TestTDXMeasurementIsMRTD(t *testing.T) {
+	mrtd := make([]byte, 48)
+	for i := range mrtd {
+		mrtd[i] = 0xD7
+	}
+	v := NewVerifier()
+	trustQuoteKeys(v)
+	quote := signedQuote(t, v, TEETypeTDX, func(q []byte) {
+		copy(q[184:232], mrtd)
+		copy(q[568:632], []byte("report data chosen by the guest"))
+	})
+
+	v.RegisterTrustedMeasurement("td-image", mrtd)
+	status, err := v.VerifyCPUDevice("td-1", quote)
+	if err != nil {
+		t.Fatalf("VerifyCPUDevice() error = %v", err)
+	}
+	if status.Measurement != "td-image" || !status.HardwareCC {
+		t.Errorf("status = %+v, want td-image with hardware CC", status)
+	}
+
+	v = NewVerifier()
+	trustQuoteKeys(v)
+	v.RegisterTrustedMeasurement("report-data", quote.Quote[568:616])
+	quote.Nonce, _ = v.NewNonce()
+	if _, err := v.VerifyCPUDevice("td-1", quote); err != ErrInvalidMeasurement {
+		t.Errorf("VerifyCPUDevice() matching report data = %v, want %v", err, ErrInvalidMeasurement)
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Attestation material carries hardware serials and raw measurements that
+// must not reach logs. GPUAttestation and AttestationQuote implement
+// fmt.Stringer and slog.LogValuer through Redact, so formatting or logging
+// them never prints raw evidence; log the Redact() copy when fields are
+// needed individually, or RedactWith to apply a RedactionPolicy.
+
+const (
+	// DefaultRedactIDPrefix is how many leading characters of a hardware
+	// identifier survive redaction by default
+	DefaultRedactIDPrefix = 4
+
+	// DefaultRedactHashBytes is how much of the SHA-256 of redacted bytes
+	// is kept by default, enough to correlate log lines without
+	// identifying the device
+	DefaultRedactHashBytes = 8
+
+	// MaxRedactHashBytes keeps the whole SHA-256
+	MaxRedactHashBytes = sha256.Size
+)
+
+// ErrInvalidRedaction is returned for a RedactionPolicy with a negative or
+// out-of-range value
+var ErrInvalidRedaction = errors.New("invalid redaction policy")
+
+// RedactionPolicy controls how much of attestation material survives
+// redaction
+type RedactionPolicy struct {
+	IDPrefix  int // Leading characters of serials and device IDs kept (0 = default)
+	HashBytes int // Bytes of the SHA-256 of raw evidence kept, up to MaxRedactHashBytes (0 = default)
+}
+
+// Validate checks that p's values are in range
+func (p RedactionPolicy) Validate() error {
+	if p.IDPrefix < 0 {
+		return fmt.Errorf("%w: ID prefix %d is negative", ErrInvalidRedaction, p.IDPrefix)
+	}
+	if p.HashBytes < 0 || p.HashBytes > MaxRedactHashBytes {
+		return fmt.Errorf("%w: hash bytes %d is outside 0-%d", ErrInvalidRedaction, p.HashBytes, MaxRedactHashBytes)
+	}
+	return nil
+}
+
+func (p RedactionPolicy) idPrefix() int {
+	if p.IDPrefix > 0 {
+		return p.IDPrefix
+	}
+	return DefaultRedactIDPrefix
+}
+
+func (p RedactionPolicy) hashBytes() int {
+	if p.HashBytes > 0 {
+		return min(p.HashBytes, MaxRedactHashBytes)
+	}
+	return DefaultRedactHashBytes
+}
+
+// RedactedBytes stands in for raw attestation bytes: their length and a
+// truncated SHA-256
+type RedactedBytes struct {
+	Len    int    `json:"len"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+func (p RedactionPolicy) bytes(b []byte) RedactedBytes {
+	if len(b) == 0 {
+		return RedactedBytes{}
+	}
+	sum := sha256.Sum256(b)
+	return RedactedBytes{Len: len(b), SHA256: hex.EncodeToString(sum[:p.hashBytes()])}
+}
+
+// String formats as "<len>B sha256:<prefix>"
+func (r RedactedBytes) String() string {
+	if r.Len == 0 {
+		return "0B"
+	}
+	return fmt.Sprintf("%dB sha256:%s", r.Len, r.SHA256)
+}
+
+// id keeps the first characters of a serial or device ID allowed by p.
+// Identifiers no longer than that are masked entirely.
+func (p RedactionPolicy) id(id string) string {
+	n := p.idPrefix()
+	if len(id) <= n {
+		return strings.Repeat("*", len(id))
+	}
+	return id[:n] + "..."
+}
+
+// RedactedQuote is a loggable AttestationQuote
+type RedactedQuote struct {
+	Type        string        `json:"type"`
+	Version     uint32        `json:"version"`
+	Quote       RedactedBytes `json:"quote"`
+	Measurement RedactedBytes `json:"measurement"`
+	ReportData  RedactedBytes `json:"report_data"`
+	Timestamp   time.Time     `json:"timestamp"`
+	Nonce       RedactedBytes `json:"nonce"`
+}
+
+// Redact returns a loggable copy of the quote with raw bytes replaced by
+// their lengths and hashes
+func (q *AttestationQuote) Redact() *RedactedQuote {
+	return q.RedactWith(RedactionPolicy{})
+}
+
+// RedactWith is Redact under policy p
+func (q *AttestationQuote) RedactWith(p RedactionPolicy) *RedactedQuote {
+	if q == nil {
+		return nil
+	}
+	return &RedactedQuote{
+		Type:        q.Type.String(),
+		Version:     q.Version,
+		Quote:       p.bytes(q.Quote),
+		Measurement: p.bytes(q.Measurement),
+		ReportData:  p.bytes(q.ReportData),
+		Timestamp:   q.Timestamp,
+		Nonce:       p.bytes(q.Nonce),
+	}
+}
+
+// String returns the redacted quote as JSON
+func (r *RedactedQuote) String() string {
+	return redactedString(r)
+}
+
+// String returns the redacted quote as JSON
+func (q *AttestationQuote) String() string {
+	return q.Redact().String()
+}
+
+// LogValue implements slog.LogValuer with the redacted quote
+func (q *AttestationQuote) LogValue() slog.Value {
+	return slog.StringValue(q.String())
+}
+
+// RedactedLocalEvidence is loggable LocalGPUEvidence
+type RedactedLocalEvidence struct {
+	SPDMReport   RedactedBytes `json:"spdm_report"`
+	CertChain    RedactedBytes `json:"cert_chain"`
+	RIMVerified  bool          `json:"rim_verified"`
+	DriverReport RedactedBytes `json:"driver_report"`
+	Nonce        RedactedBytes `json:"nonce"`
+}
+
+// RedactedSoftwareAttestation is a loggable SoftwareGPUAttestation
+type RedactedSoftwareAttestation struct {
+	GPUSerial      string        `json:"gpu_serial"`
+	PCIID          string        `json:"pci_id"`
+	BoardID        string        `json:"board_id"`
+	GPUPartNum     string        `json:"gpu_part_num"`
+	ComputeCaps    string        `json:"compute_caps"`
+	DriverVersion  string        `json:"driver_version"`
+	CUDAVersion    string        `json:"cuda_version"`
+	VBIOSVersion   string        `json:"vbios_version"`
+	BenchmarkSeed  RedactedBytes `json:"benchmark_seed"`
+	BenchmarkHash  RedactedBytes `json:"benchmark_hash"`
+	BenchmarkTime  uint64        `json:"benchmark_time_ms"`
+	ProviderID     string        `json:"provider_id,omitempty"`
+	ProviderPubKey RedactedBytes `json:"provider_pubkey"`
+	Signature      RedactedBytes `json:"signature"`
+	Timestamp      time.Time     `json:"timestamp"`
+	Nonce          RedactedBytes `json:"nonce"`
+}
+
+// RedactedGPUAttestation is a loggable GPUAttestation
+type RedactedGPUAttestation struct {
+	DeviceID      string          `json:"device_id"`
+	Model         string          `json:"model"`
+	CCEnabled     bool            `json:"cc_enabled"`
+	TEEIOEnabled  bool            `json:"tee_io_enabled"`
+	DriverVersion string          `json:"driver_version"`
+	VBIOSVersion  string          `json:"vbios_version"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Mode          AttestationMode `json:"mode"`
+
+	LocalEvidence       *RedactedLocalEvidence       `json:"local_evidence,omitempty"`
+	SoftwareAttestation *RedactedSoftwareAttestation `json:"software_attestation,omitempty"`
+}
+
+// Redact returns a loggable copy of the attestation with raw evidence
+// replaced by lengths and hashes, and serials truncated
+func (att *GPUAttestation) Redact() *RedactedGPUAttestation {
+	return att.RedactWith(RedactionPolicy{})
+}
+
+// RedactWith is Redact under policy p
+func (att *GPUAttestation) RedactWith(p RedactionPolicy) *RedactedGPUAttestation {
+	if att == nil {
+		return nil
+	}
+	r := &RedactedGPUAttestation{
+		DeviceID:      p.id(att.DeviceID),
+		Model:         att.Model,
+		CCEnabled:     att.CCEnabled,
+		TEEIOEnabled:  att.TEEIOEnabled,
+		DriverVersion: att.DriverVersion,
+		VBIOSVersion:  att.VBIOSVersion,
+		Timestamp:     att.Timestamp,
+		Mode:          att.Mode,
+	}
+	if ev := att.LocalEvidence; ev != nil {
+		r.LocalEvidence = &RedactedLocalEvidence{
+			SPDMReport:   p.bytes(ev.SPDMReport),
+			CertChain:    p.bytes(ev.CertChain),
+			RIMVerified:  ev.RIMVerified,
+			DriverReport: p.bytes(ev.DriverReport),
+			Nonce:        p.bytes(ev.Nonce[:]),
+		}
+	}
+	if sw := att.SoftwareAttestation; sw != nil {
+		r.SoftwareAttestation = &RedactedSoftwareAttestation{
+			GPUSerial:      p.id(sw.GPUSerial),
+			PCIID:          sw.PCIID,
+			BoardID:        p.id(sw.BoardID),
+			GPUPartNum:     sw.GPUPartNum,
+			ComputeCaps:    sw.ComputeCaps,
+			DriverVersion:  sw.DriverVersion,
+			CUDAVersion:    sw.CUDAVersion,
+			VBIOSVersion:   sw.VBIOSVersion,
+			BenchmarkSeed:  p.bytes(sw.BenchmarkSeed[:]),
+			BenchmarkHash:  p.bytes(sw.BenchmarkHash[:]),
+			BenchmarkTime:  sw.BenchmarkTime,
+			ProviderID:     sw.ProviderID,
+			ProviderPubKey: p.bytes(sw.ProviderPubKey),
+			Signature:      p.bytes(sw.Signature),
+			Timestamp:      sw.Timestamp,
+			Nonce:          p.bytes(sw.Nonce[:]),
+		}
+	}
+	return r
+}
+
+// String returns the redacted attestation as JSON
+func (r *RedactedGPUAttestation) String() string {
+	return redactedString(r)
+}
+
+// String returns the redacted attestation as JSON
+func (att *GPUAttestation) String() string {
+	return att.Redact().String()
+}
+
+// LogValue implements slog.LogValuer with the redacted attestation
+func (att *GPUAttestation) LogValue() slog.Value {
+	return slog.StringValue(att.String())
+}
+
+// redactedString marshals a redacted copy for display. Redacted types hold
+// only strings, numbers and times, so marshalling cannot fail.
+func redactedString(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
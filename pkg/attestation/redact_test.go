@@ -0,0 +1,152 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactID(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"abc", "***"},
+		{"abcd", "****"},
+		{"1650223001234", "1650..."},
+	}
+	for _, tt := range tests {
+		if got := (RedactionPolicy{}).id(tt.in); got != tt.want {
+			t.Errorf("id(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactBytes(t *testing.T) {
+	if got := (RedactionPolicy{}).bytes(nil); got != (RedactedBytes{}) || got.String() != "0B" {
+		t.Errorf("bytes(nil) = %+v (%s), want zero", got, got)
+	}
+	got := (RedactionPolicy{}).bytes([]byte("measurement"))
+	if got.Len != 11 || len(got.SHA256) != 2*DefaultRedactHashBytes {
+		t.Errorf("bytes() = %+v, want len 11 and %d hex chars", got, 2*DefaultRedactHashBytes)
+	}
+	if got != (RedactionPolicy{}).bytes([]byte("measurement")) {
+		t.Error("bytes() is not deterministic")
+	}
+}
+
+func TestRedactionPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     RedactionPolicy
+		wantErr    bool
+		wantID     string
+		wantHexLen int
+	}{
+		{"default", RedactionPolicy{}, false, "1650...", 2 * DefaultRedactHashBytes},
+		{"custom", RedactionPolicy{IDPrefix: 2, HashBytes: 4}, false, "16...", 8},
+		{"full hash", RedactionPolicy{HashBytes: 32}, false, "1650...", 64},
+		{"negative prefix", RedactionPolicy{IDPrefix: -1}, true, "", 0},
+		{"hash too long", RedactionPolicy{HashBytes: 33}, true, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			att := &GPUAttestation{DeviceID: "1650223001234", LocalEvidence: &LocalGPUEvidence{SPDMReport: []byte("report")}}
+			r := att.RedactWith(tt.policy)
+			if r.DeviceID != tt.wantID {
+				t.Errorf("DeviceID = %q, want %q", r.DeviceID, tt.wantID)
+			}
+			if got := len(r.LocalEvidence.SPDMReport.SHA256); got != tt.wantHexLen {
+				t.Errorf("SPDMReport hash = %d hex chars, want %d", got, tt.wantHexLen)
+			}
+		})
+	}
+}
+
+func TestGPUAttestationRedact(t *testing.T) {
+	const serial = "1650223001234"
+	const deviceID = "GPU-8f3c2a1b-0000-1111"
+	spdm := bytes.Repeat([]byte{0xab}, MinSPDMReportSize)
+	sig := bytes.Repeat([]byte{0xcd}, MinProviderSignatureSize)
+
+	att := &GPUAttestation{
+		DeviceID:      deviceID,
+		Model:         "H100",
+		DriverVersion: "550.54.15",
+		LocalEvidence: &LocalGPUEvidence{
+			SPDMReport: spdm,
+			CertChain:  bytes.Repeat([]byte{0xef}, MinCertChainSize),
+		},
+		SoftwareAttestation: &SoftwareGPUAttestation{
+			GPUSerial: serial,
+			BoardID:   "0x4100",
+			Signature: sig,
+		},
+	}
+
+	r := att.Redact()
+	if r.DeviceID != "GPU-..." || r.SoftwareAttestation.GPUSerial != "1650..." {
+		t.Errorf("Redact() ids = %q, %q, want truncated", r.DeviceID, r.SoftwareAttestation.GPUSerial)
+	}
+	if r.LocalEvidence.SPDMReport.Len != len(spdm) || r.SoftwareAttestation.Signature.Len != len(sig) {
+		t.Errorf("Redact() lengths = %d, %d, want %d, %d",
+			r.LocalEvidence.SPDMReport.Len, r.SoftwareAttestation.Signature.Len, len(spdm), len(sig))
+	}
+	if r.Model != att.Model || r.DriverVersion != att.DriverVersion {
+		t.Errorf("Redact() dropped diagnostics: %+v", r)
+	}
+
+	var logged bytes.Buffer
+	slog.New(slog.NewTextHandler(&logged, nil)).Info("attested", "attestation", att)
+
+	outputs := map[string]string{
+		"String": att.String(),
+		"%v":     fmt.Sprintf("%v", att),
+		"slog":   logged.String(),
+	}
+	secrets := []string{serial, deviceID, hex.EncodeToString(spdm[:8]), hex.EncodeToString(sig[:8])}
+	for name, out := range outputs {
+		for _, secret := range secrets {
+			if strings.Contains(out, secret) {
+				t.Errorf("%s output leaks %q: %s", name, secret, out)
+			}
+		}
+		if !strings.Contains(out, "H100") {
+			t.Errorf("%s output missing model: %s", name, out)
+		}
+	}
+
+	if (*GPUAttestation)(nil).Redact() != nil {
+		t.Error("nil Redact() != nil")
+	}
+}
+
+func TestAttestationQuoteRedact(t *testing.T) {
+	measurement := bytes.Repeat([]byte{0x42}, 48)
+	q := &AttestationQuote{
+		Type:        TEETypeSEVSNP,
+		Version:     2,
+		Quote:       bytes.Repeat([]byte{0x01}, 1184),
+		Measurement: measurement,
+	}
+
+	r := q.Redact()
+	if r.Type != TEETypeSEVSNP.String() || r.Quote.Len != 1184 || r.Measurement.Len != 48 || r.Nonce.Len != 0 {
+		t.Errorf("Redact() = %+v", r)
+	}
+	if out := fmt.Sprint(q); strings.Contains(out, hex.EncodeToString(measurement[:8])) {
+		t.Errorf("String() leaks measurement: %s", out)
+	}
+}
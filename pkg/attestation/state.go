@@ -0,0 +1,87 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DeviceStateFile is the file name used by SaveState/LoadState
+const DeviceStateFile = "attested_devices.json"
+
+// AttestedDevice pairs a device ID with its attestation status
+type AttestedDevice struct {
+	DeviceID string `json:"device_id"`
+	DeviceStatus
+}
+
+// AttestedDevices returns a snapshot of all attested devices sorted by
+// trust score (highest first), with ties broken by device ID.
+func (v *Verifier) AttestedDevices() []AttestedDevice {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	devices := make([]AttestedDevice, 0, len(v.attestedDevices))
+	for id, status := range v.attestedDevices {
+		devices = append(devices, AttestedDevice{DeviceID: id, DeviceStatus: *status})
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].TrustScore != devices[j].TrustScore {
+			return devices[i].TrustScore > devices[j].TrustScore
+		}
+		return devices[i].DeviceID < devices[j].DeviceID
+	})
+	return devices
+}
+
+// SaveState writes the attested device map to DeviceStateFile in dir.
+// The file is replaced atomically so a crash never leaves a partial state.
+func (v *Verifier) SaveState(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	v.mu.Lock()
+	data, err := json.MarshalIndent(v.attestedDevices, "", "  ")
+	v.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, DeviceStateFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadState restores the attested device map from DeviceStateFile in dir,
+// replacing any in-memory state. A missing file is not an error: a fresh
+// node simply starts with no attested devices.
+func (v *Verifier) LoadState(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, DeviceStateFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	devices := make(map[string]*DeviceStatus)
+	if err := json.Unmarshal(data, &devices); err != nil {
+		return err
+	}
+	for id, status := range devices {
+		if status == nil {
+			delete(devices, id)
+		}
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.attestedDevices = devices
+	v.resetCache()
+	return nil
+}
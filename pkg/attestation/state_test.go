@@ -0,0 +1,91 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAttestedDevicesSorted(t *testing.T) {
+	v := NewVerifier()
+	v.attestedDevices["b"] = &DeviceStatus{Attested: true, TrustScore: 50}
+	v.attestedDevices["a"] = &DeviceStatus{Attested: true, TrustScore: 50}
+	v.attestedDevices["c"] = &DeviceStatus{Attested: true, TrustScore: 95}
+
+	devices := v.AttestedDevices()
+	if len(devices) != 3 {
+		t.Fatalf("got %d devices, want 3", len(devices))
+	}
+	want := []string{"c", "a", "b"}
+	for i, id := range want {
+		if devices[i].DeviceID != id {
+			t.Errorf("devices[%d] = %s, want %s", i, devices[i].DeviceID, id)
+		}
+	}
+
+	// The listing is a snapshot and must not alias verifier state
+	devices[0].TrustScore = 1
+	if v.attestedDevices["c"].TrustScore != 95 {
+		t.Error("AttestedDevices should return copies")
+	}
+}
+
+func TestSaveLoadState(t *testing.T) {
+	dir := t.TempDir()
+	v := NewVerifier()
+	v.attestedDevices["GPU-1"] = &DeviceStatus{
+		Attested:   true,
+		TrustScore: 88,
+		LastSeen:   time.Now().Truncate(time.Second),
+		Operator:   "GPU-1",
+		Vendor:     TEETypeNVIDIA,
+		JobHistory: []string{"job-1", "job-2"},
+		Mode:       ModeLocal,
+		HardwareCC: true,
+	}
+	if err := v.SaveState(dir); err != nil {
+		t.Fatalf("SaveState: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, DeviceStateFile+".tmp")); !os.IsNotExist(err) {
+		t.Error("temporary state file should not remain after save")
+	}
+
+	restored := NewVerifier()
+	if err := restored.LoadState(dir); err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	status, ok := restored.GetDeviceStatus("GPU-1")
+	if !ok {
+		t.Fatal("device not restored")
+	}
+	if status.TrustScore != 88 || !status.HardwareCC || len(status.JobHistory) != 2 {
+		t.Errorf("restored status = %+v", status)
+	}
+	if !status.LastSeen.Equal(v.attestedDevices["GPU-1"].LastSeen) {
+		t.Errorf("LastSeen = %v, want %v", status.LastSeen, v.attestedDevices["GPU-1"].LastSeen)
+	}
+}
+
+func TestLoadStateMissingFile(t *testing.T) {
+	v := NewVerifier()
+	if err := v.LoadState(t.TempDir()); err != nil {
+		t.Errorf("LoadState on empty dir: %v", err)
+	}
+	if len(v.AttestedDevices()) != 0 {
+		t.Error("expected no devices")
+	}
+}
+
+func TestLoadStateCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, DeviceStateFile), []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewVerifier().LoadState(dir); err == nil {
+		t.Error("expected error for corrupt state file")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"sort"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+// RejectReason categorizes why a verification failed
+type RejectReason string
+
+const (
+	RejectExpired        RejectReason = "expired"              // Quote, attestation or benchmark challenge too old
+	RejectBadSignature   RejectReason = "bad_signature"        // Signature missing, malformed or not matching
+	RejectRevoked        RejectReason = "revoked"              // Signing key revoked, expired or never authorized
+	RejectMeasurement    RejectReason = "measurement_mismatch" // Measurement or RIM not trusted
+	RejectUnsupportedTEE RejectReason = "unsupported_tee"      // TEE type or GPU model without CC support
+	RejectBenchmark      RejectReason = "benchmark"            // Benchmark answer wrong or implausibly timed
+	RejectBinding        RejectReason = "binding_mismatch"     // CPU quote not bound to the GPU attestation
+	RejectInvalid        RejectReason = "invalid"              // Missing or malformed evidence
+)
+
+// VerifiedCount is the number of successful verifications for one mode
+// and tier
+type VerifiedCount struct {
+	Mode  AttestationMode `json:"mode"`
+	Tier  cc.CCTier       `json:"tier"`
+	Count uint64          `json:"count"`
+}
+
+// VerificationStats counts verification outcomes since the verifier was
+// created. Cache hits are reported by CacheStats, not here.
+type VerificationStats struct {
+	// Verified is ordered by mode, then tier
+	Verified []VerifiedCount `json:"verified"`
+
+	Rejected map[RejectReason]uint64 `json:"rejected"`
+}
+
+type verifiedKey struct {
+	mode AttestationMode
+	tier cc.CCTier
+}
+
+// Stats returns the verification outcome counters, e.g. so operators can
+// spot a rise in measurement mismatches after a driver rollout
+func (v *Verifier) Stats() VerificationStats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	stats := VerificationStats{
+		Verified: make([]VerifiedCount, 0, len(v.verified)),
+		Rejected: make(map[RejectReason]uint64, len(v.rejected)),
+	}
+	for k, n := range v.verified {
+		stats.Verified = append(stats.Verified, VerifiedCount{Mode: k.mode, Tier: k.tier, Count: n})
+	}
+	sort.Slice(stats.Verified, func(i, j int) bool {
+		a, b := stats.Verified[i], stats.Verified[j]
+		return a.Mode < b.Mode || (a.Mode == b.Mode && a.Tier < b.Tier)
+	})
+	for reason, n := range v.rejected {
+		stats.Rejected[reason] = n
+	}
+	return stats
+}
+
+// recordVerified counts a successful verification
+func (v *Verifier) recordVerified(mode AttestationMode, tier cc.CCTier) {
+	v.verified[verifiedKey{mode, tier}]++
+}
+
+// recordRejected counts a failed verification of att, which is nil for
+// CPU quotes
+func (v *Verifier) recordRejected(att *GPUAttestation, err error) {
+	v.rejected[v.rejectReason(att, err)]++
+}
+
+// rejectReason categorizes a verification error. Signature failures on a
+// software attestation whose key is not currently authorized count as
+// revoked.
+func (v *Verifier) rejectReason(att *GPUAttestation, err error) RejectReason {
+	switch {
+	case errors.Is(err, ErrQuoteExpired), errors.Is(err, ErrChallengeExpired):
+		return RejectExpired
+	case errors.Is(err, ErrInvalidSignature):
+		if att != nil && att.SoftwareAttestation != nil {
+			sw := att.SoftwareAttestation
+			providerID := sw.ProviderID
+			if providerID == "" {
+				providerID = att.DeviceID
+			}
+			if len(sw.ProviderPubKey) == ed25519.PublicKeySize && !v.isKeyAuthorized(providerID, sw.ProviderPubKey, v.now()) {
+				return RejectRevoked
+			}
+		}
+		return RejectBadSignature
+	case errors.Is(err, ErrSPDMVerifyFailed):
+		return RejectBadSignature
+	case errors.Is(err, ErrUntrustedQuoteKey), errors.Is(err, ErrUntrustedGPUKey):
+		return RejectRevoked
+	case errors.Is(err, ErrInvalidMeasurement), errors.Is(err, ErrNoTrustedMeasurement), errors.Is(err, ErrRIMVerifyFailed):
+		return RejectMeasurement
+	case errors.Is(err, ErrUnsupportedTEE), errors.Is(err, ErrGPUNotCCCapable):
+		return RejectUnsupportedTEE
+	case errors.Is(err, ErrBenchmarkMismatch), errors.Is(err, ErrBenchmarkImplausible), errors.Is(err, ErrStaleBenchmark):
+		return RejectBenchmark
+	case errors.Is(err, ErrBindingMismatch):
+		return RejectBinding
+	default:
+		return RejectInvalid
+	}
+}
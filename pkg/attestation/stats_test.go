@@ -0,0 +1,93 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package attestation
+
+import (
+	"crypto/ed25519"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+func TestVerifierStats(t *testing.T) {
+	v := NewVerifier()
+
+	newSoftware := func() *GPUAttestation {
+		return &GPUAttestation{
+			DeviceID: "GPU-SW",
+			Model:    "RTX 4090",
+			Mode:     ModeSoftware,
+			SoftwareAttestation: &SoftwareGPUAttestation{
+				GPUSerial:     "GPU-SERIAL-1",
+				DriverVersion: "570.00",
+				Timestamp:     time.Now(),
+			},
+		}
+	}
+
+	// Verified: two local Tier 1, one local Tier 2 (limited CC), one software
+	for _, model := range []string{"H100", "B200", "A100"} {
+		if _, err := v.VerifyGPUAttestation(newLocalAttestation("GPU-"+model, model)); err != nil {
+			t.Fatalf("%s: VerifyGPUAttestation() error = %v", model, err)
+		}
+	}
+	sw := newSoftware()
+	priv := signSoftwareAttestation(t, v, sw)
+	if _, err := v.VerifyGPUAttestation(sw); err != nil {
+		t.Fatalf("software: VerifyGPUAttestation() error = %v", err)
+	}
+
+	// Rejected
+	v.VerifyGPUAttestation(newLocalAttestation("GPU-4090", "RTX 4090")) // unsupported
+
+	expired := newSoftware()
+	expired.SoftwareAttestation.Timestamp = time.Now().Add(-2 * SoftwareAttestationMaxAge)
+	resign(expired, priv)
+	v.VerifyGPUAttestation(expired)
+
+	forged := newSoftware()
+	resign(forged, priv)
+	forged.SoftwareAttestation.Signature[0] ^= 0xff
+	v.VerifyGPUAttestation(forged)
+
+	v.RevokeKey(sw.DeviceID, priv.Public().(ed25519.PublicKey))
+	revoked := newSoftware()
+	resign(revoked, priv)
+	v.VerifyGPUAttestation(revoked)
+
+	trustQuoteKeys(v)
+	v.RegisterTrustedMeasurement("good", make([]byte, 32))
+	v.VerifyCPUAttestation(signedQuote(t, v, TEETypeSGX, func(q []byte) { q[112] = 1 }), nil)
+
+	v.VerifyGPUAttestation(&GPUAttestation{DeviceID: "empty", Mode: ModeLocal})
+
+	got := v.Stats()
+	wantVerified := []VerifiedCount{
+		{Mode: ModeLocal, Tier: cc.Tier1GPUNativeCC, Count: 2},
+		{Mode: ModeLocal, Tier: cc.Tier2ConfidentialVM, Count: 1},
+		{Mode: ModeSoftware, Tier: cc.Tier4Standard, Count: 1},
+	}
+	if !reflect.DeepEqual(got.Verified, wantVerified) {
+		t.Errorf("Verified = %+v, want %+v", got.Verified, wantVerified)
+	}
+	wantRejected := map[RejectReason]uint64{
+		RejectUnsupportedTEE: 1,
+		RejectExpired:        1,
+		RejectBadSignature:   1,
+		RejectRevoked:        1,
+		RejectMeasurement:    1,
+		RejectInvalid:        1,
+	}
+	if !reflect.DeepEqual(got.Rejected, wantRejected) {
+		t.Errorf("Rejected = %v, want %v", got.Rejected, wantRejected)
+	}
+
+	// The snapshot is a copy
+	got.Rejected[RejectExpired] = 100
+	if v.Stats().Rejected[RejectExpired] != 1 {
+		t.Error("modifying a Stats() snapshot changed the verifier's counters")
+	}
+}
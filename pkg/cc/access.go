@@ -0,0 +1,95 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidAccessList = errors.New("invalid provider access list")
+
+// ProviderList maps provider IDs to an operator note, e.g. why a provider
+// was blocked or when it was vetted
+type ProviderList map[string]string
+
+// Has reports whether providerID is listed
+func (l ProviderList) Has(providerID string) bool {
+	_, ok := l[providerID]
+	return ok
+}
+
+func (l ProviderList) validate() error {
+	for id := range l {
+		if id == "" {
+			return fmt.Errorf("%w: empty provider ID", ErrInvalidAccessList)
+		}
+	}
+	return nil
+}
+
+func (l ProviderList) clone() ProviderList {
+	if len(l) == 0 {
+		return nil
+	}
+	c := make(ProviderList, len(l))
+	for id, note := range l {
+		c[id] = note
+	}
+	return c
+}
+
+// SetAccessLists validates and applies copies of the pool's allowlist and
+// blocklist. A nil or empty list is unset.
+func (pool *AIRewardPool) SetAccessLists(allow, block ProviderList) error {
+	if err := allow.validate(); err != nil {
+		return err
+	}
+	if err := block.validate(); err != nil {
+		return err
+	}
+	pool.Allowlist = allow.clone()
+	pool.Blocklist = block.clone()
+	return nil
+}
+
+// BlockProvider adds providerID to the blocklist with the given reason
+func (pool *AIRewardPool) BlockProvider(providerID, reason string) error {
+	if providerID == "" {
+		return fmt.Errorf("%w: empty provider ID", ErrInvalidAccessList)
+	}
+	if pool.Blocklist == nil {
+		pool.Blocklist = make(ProviderList)
+	}
+	pool.Blocklist[providerID] = reason
+	return nil
+}
+
+// UnblockProvider removes providerID from the blocklist
+func (pool *AIRewardPool) UnblockProvider(providerID string) {
+	delete(pool.Blocklist, providerID)
+	if len(pool.Blocklist) == 0 {
+		pool.Blocklist = nil
+	}
+}
+
+// accessReason reports whether the pool's lists let providerID take part
+// in rewards. The blocklist takes precedence over the allowlist.
+func (pool *AIRewardPool) accessReason(providerID string) (bool, EligibilityReason) {
+	switch {
+	case pool.Blocklist.Has(providerID):
+		return false, EligibilityBlocked
+	case len(pool.Allowlist) > 0 && !pool.Allowlist.Has(providerID):
+		return false, EligibilityNotAllowed
+	}
+	return true, EligibilityOK
+}
+
+// Participates reports whether providerID may earn rewards under the
+// pool's allowlist and blocklist. It does not check liveness, attestation
+// or stake.
+func (pool *AIRewardPool) Participates(providerID string) bool {
+	ok, _ := pool.accessReason(providerID)
+	return ok
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAccessLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		allow   ProviderList
+		block   ProviderList
+		wantA   EligibilityReason
+		wantB   EligibilityReason
+		wantPay []string
+	}{
+		{"no lists", nil, nil, EligibilityOK, EligibilityOK, []string{"a", "b"}},
+		{"blocklist", nil, ProviderList{"b": "under investigation"}, EligibilityOK, EligibilityBlocked, []string{"a"}},
+		{"allowlist", ProviderList{"a": "vetted"}, nil, EligibilityOK, EligibilityNotAllowed, []string{"a"}},
+		{"block overrides allow", ProviderList{"a": "", "b": ""}, ProviderList{"a": ""}, EligibilityBlocked, EligibilityOK, []string{"b"}},
+		{"empty allowlist is unset", ProviderList{}, nil, EligibilityOK, EligibilityOK, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newLedgerPool(t)
+			if err := pool.SetAccessLists(tt.allow, tt.block); err != nil {
+				t.Fatalf("SetAccessLists() error = %v", err)
+			}
+			for id, want := range map[string]EligibilityReason{"a": tt.wantA, "b": tt.wantB} {
+				if ok, reason := pool.RandomMiningEligibility(pool.Providers[id], time.Minute); ok != (want == EligibilityOK) || reason != want {
+					t.Errorf("RandomMiningEligibility(%s) = %v, %v, want %v", id, ok, reason, want)
+				}
+				task := pool.CalculateTaskReward(pool.Providers[id], "task", ModelingLevelInferenceStandard, 10)
+				pool.RecordTaskReward(pool.Providers[id], task)
+				if paid := task.RewardLUX.Sign() > 0; paid != (want == EligibilityOK) {
+					t.Errorf("task reward to %s = %s, want paid %v", id, task.RewardLUX, want == EligibilityOK)
+				}
+			}
+
+			var paid []string
+			summary := pool.CalculateEpochRewards(big.NewInt(1e18), time.Minute)
+			for _, r := range summary.ProviderRewards {
+				paid = append(paid, r.ProviderID)
+			}
+			if !reflect.DeepEqual(paid, tt.wantPay) {
+				t.Errorf("participation rewards paid to %v, want %v", paid, tt.wantPay)
+			}
+			var tiers uint64
+			for _, n := range summary.TierDistribution {
+				tiers += n
+			}
+			if summary.OnlineProviders != uint64(len(tt.wantPay)) || tiers != summary.OnlineProviders {
+				t.Errorf("OnlineProviders = %d, tier total = %d, want %d", summary.OnlineProviders, tiers, len(tt.wantPay))
+			}
+			for id := range tt.block {
+				if tasks := pool.ProviderStatement(id, 0, 0).TaskCount; tasks != 0 {
+					t.Errorf("blocked provider %s recorded %d tasks", id, tasks)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessListUpdates(t *testing.T) {
+	pool := newLedgerPool(t)
+	if err := pool.SetAccessLists(ProviderList{"": ""}, nil); !errors.Is(err, ErrInvalidAccessList) {
+		t.Errorf("SetAccessLists() with empty ID error = %v, want %v", err, ErrInvalidAccessList)
+	}
+	if err := pool.BlockProvider("", "x"); !errors.Is(err, ErrInvalidAccessList) {
+		t.Errorf("BlockProvider(\"\") error = %v, want %v", err, ErrInvalidAccessList)
+	}
+
+	allow := ProviderList{"a": "vetted", "b": "vetted"}
+	if err := pool.SetAccessLists(allow, nil); err != nil {
+		t.Fatalf("SetAccessLists() error = %v", err)
+	}
+	delete(allow, "b")
+	if !pool.Participates("b") {
+		t.Error("changing the caller's list changed the pool's allowlist")
+	}
+	if err := pool.BlockProvider("a", "double signing"); err != nil {
+		t.Fatalf("BlockProvider() error = %v", err)
+	}
+
+	// Lists survive persistence and are independent in clones
+	data, err := json.Marshal(pool)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var restored AIRewardPool
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if restored.Participates("a") || !restored.Participates("b") || restored.Participates("c") {
+		t.Errorf("restored lists = allow %v, block %v", restored.Allowlist, restored.Blocklist)
+	}
+	if restored.Blocklist["a"] != "double signing" {
+		t.Errorf("restored block reason = %q, want %q", restored.Blocklist["a"], "double signing")
+	}
+
+	clone := pool.Clone()
+	clone.UnblockProvider("a")
+	if !clone.Participates("a") || pool.Participates("a") {
+		t.Error("UnblockProvider on a clone changed the original")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"fmt"
+	"time"
+)
+
+// KeyAuthorizer reports whether a provider signing key is currently
+// authorized, e.g. an attestation.Verifier
+type KeyAuthorizer interface {
+	IsKeyAuthorized(providerID string, pubKey []byte) bool
+}
+
+// ValidateProvider runs the pool's admission checks on a provider and
+// returns the first failure:
+//
+//   - ErrInvalidAttestation: empty provider ID, or an attestation issued
+//     to a different provider or not yet valid
+//   - ErrInvalidTier: an attestation without a known tier
+//   - ErrAttestationMethod: an attestation method that cannot back its
+//     tier, e.g. a Tier 1 claim backed by software attestation
+//   - ErrAttestationExpired: an attestation expired past its grace period
+//   - ErrInsufficientStake: stake below the minimum for the attested tier
+//   - ErrInsufficientVRAM: reported GPU memory below the modeling level's,
+//     or below that of any opted-in ModelingLevels
+//   - ErrInvalidModelingLevel: an undefined level in ModelingLevels
+//   - ErrInsufficientComputeCapability: an attested compute capability
+//     below pool.MinComputeCap for the tier or a served modeling level
+//   - ErrUnauthorizedKey: SigningKey not authorized by pool.KeyAuthorizer
+//
+// Providers without an attestation are admitted as Tier4. Providers that do
+// not report GPU memory skip the VRAM check, and the key check only runs
+// when the pool has a KeyAuthorizer.
+func (pool *AIRewardPool) ValidateProvider(provider *AIProvider) error {
+	if provider == nil || provider.ProviderID == "" {
+		return ErrInvalidAttestation
+	}
+
+	tier := Tier4Standard
+	if a := provider.Attestation; a != nil {
+		if err := pool.validateAttestation(provider, pool.now()); err != nil {
+			return err
+		}
+		tier = a.Tier
+	}
+
+	if provider.StakeLUX < pool.MinStake(tier) {
+		return ErrInsufficientStake
+	}
+
+	// Reject providers claiming a modeling level their GPU cannot hold
+	if vram, ok := provider.VRAMGB(); ok {
+		if required := provider.MaxModelingLevel.MinVRAMGB(); vram < required {
+			return fmt.Errorf("%w: %s requires %dGB, have %dGB",
+				ErrInsufficientVRAM, provider.MaxModelingLevel, required, vram)
+		}
+	}
+	vram, _ := provider.VRAMGB()
+	for _, level := range provider.ModelingLevels {
+		if err := CheckLevelVRAM(level, vram); err != nil {
+			return err
+		}
+	}
+
+	if err := pool.MinComputeCap.check(tier, provider, provider.ComputeCapability()); err != nil {
+		return err
+	}
+
+	if pool.KeyAuthorizer != nil && !pool.KeyAuthorizer.IsKeyAuthorized(provider.ProviderID, provider.SigningKey) {
+		return fmt.Errorf("%w: provider %s", ErrUnauthorizedKey, provider.ProviderID)
+	}
+	return nil
+}
+
+// validateAttestation checks the provider's attestation at now. An expired
+// attestation is accepted within the pool's grace period; the provider's own
+// TierGracePeriod is ignored, since registration replaces it.
+func (pool *AIRewardPool) validateAttestation(provider *AIProvider, now time.Time) error {
+	a := provider.Attestation
+	if a.Tier < Tier1GPUNativeCC || a.Tier > Tier4Standard {
+		return fmt.Errorf("%w: %d", ErrInvalidTier, a.Tier)
+	}
+	if a.ProviderID != "" && a.ProviderID != provider.ProviderID {
+		return fmt.Errorf("%w: issued to %s", ErrInvalidAttestation, a.ProviderID)
+	}
+	if err := a.checkMethod(); err != nil {
+		return err
+	}
+	if a.isValidAt(now) {
+		return nil
+	}
+	if !now.After(a.IssuedAt) {
+		return fmt.Errorf("%w: not valid until %s", ErrInvalidAttestation, a.IssuedAt.Format(time.RFC3339))
+	}
+
+	graced := *provider
+	graced.TierGracePeriod = pool.TierGracePeriod
+	if _, ok := graced.graceRemaining(now); !ok {
+		return fmt.Errorf("%w: expired at %s", ErrAttestationExpired, a.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// staticAuthorizer authorizes a single key for every provider
+type staticAuthorizer []byte
+
+func (k staticAuthorizer) IsKeyAuthorized(_ string, pubKey []byte) bool {
+	return bytes.Equal(k, pubKey)
+}
+
+func TestValidateProvider(t *testing.T) {
+	now := time.Now()
+	key := []byte("provider-key")
+	valid := func() *AIProvider {
+		return &AIProvider{
+			ProviderID: "p",
+			Attestation: &TierAttestation{
+				Tier:       Tier2ConfidentialVM,
+				Method:     MethodSEVSNP,
+				ProviderID: "p",
+				IssuedAt:   now.Add(-time.Hour),
+				ExpiresAt:  now.Add(time.Hour),
+				HardwareInfo: &HardwareInfo{
+					MemorySize: 24_000_000_000,
+				},
+			},
+			MaxModelingLevel: ModelingLevelInferenceStandard,
+			StakeLUX:         50_000,
+			SigningKey:       key,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *AIProvider)
+		grace  time.Duration
+		want   error
+	}{
+		{"valid", func(p *AIProvider) {}, 0, nil},
+		{"no attestation as Tier4", func(p *AIProvider) { p.Attestation = nil; p.StakeLUX = 1_000 }, 0, nil},
+		{"empty ID", func(p *AIProvider) { p.ProviderID = "" }, 0, ErrInvalidAttestation},
+		{"unknown tier", func(p *AIProvider) { p.Attestation.Tier = TierUnknown }, 0, ErrInvalidTier},
+		{"other provider's attestation", func(p *AIProvider) { p.Attestation.ProviderID = "q" }, 0, ErrInvalidAttestation},
+		{"not yet valid", func(p *AIProvider) { p.Attestation.IssuedAt = now.Add(time.Minute) }, 0, ErrInvalidAttestation},
+		{"expired", func(p *AIProvider) { p.Attestation.ExpiresAt = now.Add(-time.Minute) }, 0, ErrAttestationExpired},
+		{"expired within pool grace", func(p *AIProvider) { p.Attestation.ExpiresAt = now.Add(-time.Minute) }, time.Hour, nil},
+		{"expired past grace", func(p *AIProvider) { p.Attestation.ExpiresAt = now.Add(-2 * time.Hour) }, time.Hour, ErrAttestationExpired},
+		{"expired with provider-chosen grace", func(p *AIProvider) {
+			p.Attestation.ExpiresAt = now.Add(-2 * time.Hour)
+			p.TierGracePeriod = 24 * time.Hour
+		}, time.Hour, ErrAttestationExpired},
+		{"method cannot back tier", func(p *AIProvider) { p.Attestation.Method = MethodSoftware }, 0, ErrAttestationMethod},
+		{"no method", func(p *AIProvider) { p.Attestation.Method = "" }, 0, ErrAttestationMethod},
+		{"stake below attested tier", func(p *AIProvider) { p.StakeLUX = 10_000 }, 0, ErrInsufficientStake},
+		{"insufficient VRAM", func(p *AIProvider) { p.MaxModelingLevel = ModelingLevelInferenceHeavy }, 0, ErrInsufficientVRAM},
+		{"opted-in levels", func(p *AIProvider) {
+			p.ModelingLevels = []ModelingLevel{ModelingLevelInferenceLight, ModelingLevelInferenceStandard}
+		}, 0, nil},
+		{"opted-in level over VRAM", func(p *AIProvider) {
+			p.ModelingLevels = []ModelingLevel{ModelingLevelInferenceLight, ModelingLevelTraining}
+		}, 0, ErrInsufficientVRAM},
+		{"undefined opted-in level", func(p *AIProvider) { p.ModelingLevels = []ModelingLevel{9} }, 0, ErrInvalidModelingLevel},
+		{"unauthorized key", func(p *AIProvider) { p.SigningKey = []byte("other") }, 0, ErrUnauthorizedKey},
+		{"first failure wins", func(p *AIProvider) { p.StakeLUX = 0; p.SigningKey = nil }, 0, ErrInsufficientStake},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := NewAIRewardPool(time.Hour)
+			pool.TierGracePeriod = tt.grace
+			pool.KeyAuthorizer = staticAuthorizer(key)
+			p := valid()
+			tt.modify(p)
+
+			err := pool.ValidateProvider(p)
+			if !errors.Is(err, tt.want) || (err == nil) != (tt.want == nil) {
+				t.Fatalf("ValidateProvider() = %v, want %v", err, tt.want)
+			}
+			if err := pool.RegisterProvider(p); !errors.Is(err, tt.want) {
+				t.Errorf("RegisterProvider() = %v, want %v", err, tt.want)
+			}
+			if _, registered := pool.Providers[p.ProviderID]; registered != (tt.want == nil) {
+				t.Errorf("registered = %v, want %v", registered, tt.want == nil)
+			}
+		})
+	}
+}
+
+func TestValidateProviderWithoutKeyAuthorizer(t *testing.T) {
+	pool := NewAIRewardPool(time.Hour)
+	if err := pool.ValidateProvider(&AIProvider{ProviderID: "p", StakeLUX: 1_000}); err != nil {
+		t.Errorf("ValidateProvider() = %v, want nil without a KeyAuthorizer", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// ErrInvalidShares is returned when the pool's participation and task
+// shares are not fractions in [0, 1] that sum to at most 1
+var ErrInvalidShares = errors.New("invalid reward pool shares")
+
+// Nil amounts
+//
+// Reward amounts are *big.Int, which decode to nil when a JSON payload
+// omits them or sets them to null. The decoders below initialize missing
+// amounts to zero so a loaded pool or result can be used without nil
+// checks, and the reward calculations treat nil inputs as zero.
+
+// zeroIfNil sets *x to zero if it is nil
+func zeroIfNil(x **big.Int) {
+	if *x == nil {
+		*x = new(big.Int)
+	}
+}
+
+// orZero returns x, or a new zero if x is nil
+func orZero(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return x
+}
+
+// Fractional amounts
+//
+// Shares and multipliers are float64 in configuration, but multiplying a
+// wei amount by one must neither overflow nor lose precision, so they are
+// converted to exact rationals first. A float is taken as the decimal it
+// prints as (0.3 is 3/10, not its nearest binary fraction), and results
+// are rounded down so the parts of an amount never sum to more than it.
+
+// decimalRat returns f as an exact rational. Negative, NaN and infinite
+// values, which no share, weight or multiplier may take, return zero.
+func decimalRat(f float64) *big.Rat {
+	if !(f > 0) || math.IsInf(f, 1) {
+		return new(big.Rat)
+	}
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// shareRat returns share as a rational clamped to [0, 1]
+func shareRat(share float64) *big.Rat {
+	r := decimalRat(share)
+	if r.Cmp(big.NewRat(1, 1)) > 0 {
+		return big.NewRat(1, 1)
+	}
+	return r
+}
+
+// mulRat returns x*r rounded down. x and r must be non-negative.
+func mulRat(x *big.Int, r *big.Rat) *big.Int {
+	out := new(big.Int).Mul(orZero(x), r.Num())
+	return out.Quo(out, r.Denom())
+}
+
+// SetShares validates and sets the fractions of the AI pool paid as
+// participation and task rewards
+func (pool *AIRewardPool) SetShares(participation, task float64) error {
+	for _, share := range []float64{participation, task} {
+		if !(share >= 0 && share <= 1) {
+			return fmt.Errorf("%w: share %v outside [0, 1]", ErrInvalidShares, share)
+		}
+	}
+	if sum := new(big.Rat).Add(decimalRat(participation), decimalRat(task)); sum.Cmp(big.NewRat(1, 1)) > 0 {
+		return fmt.Errorf("%w: participation %v and task %v sum to more than 1", ErrInvalidShares, participation, task)
+	}
+	pool.ParticipationShare = participation
+	pool.TaskShare = task
+	return nil
+}
+
+// UnmarshalJSON decodes a pool, initializing a missing pool total, provider
+// set and earnings amounts
+func (pool *AIRewardPool) UnmarshalJSON(data []byte) error {
+	type plain AIRewardPool
+	if err := json.Unmarshal(data, (*plain)(pool)); err != nil {
+		return err
+	}
+	zeroIfNil(&pool.TotalPoolLUX)
+	if pool.Providers == nil {
+		pool.Providers = make(map[string]*AIProvider)
+	}
+	for id, p := range pool.Providers {
+		if p == nil {
+			delete(pool.Providers, id)
+		}
+	}
+	for id, records := range pool.History {
+		kept := records[:0]
+		for _, rec := range records {
+			if rec != nil {
+				kept = append(kept, rec)
+			}
+		}
+		pool.History[id] = kept
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes an epoch record, dropping null task entries
+func (rec *ProviderEpochRecord) UnmarshalJSON(data []byte) error {
+	type plain ProviderEpochRecord
+	if err := json.Unmarshal(data, (*plain)(rec)); err != nil {
+		return err
+	}
+	kept := rec.Tasks[:0]
+	for _, task := range rec.Tasks {
+		if task != nil {
+			kept = append(kept, task)
+		}
+	}
+	rec.Tasks = kept
+	return nil
+}
+
+// UnmarshalJSON decodes a participation reward, initializing a missing
+// amount to zero
+func (r *ParticipationRewardResult) UnmarshalJSON(data []byte) error {
+	type plain ParticipationRewardResult
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	zeroIfNil(&r.RewardLUX)
+	return nil
+}
+
+// UnmarshalJSON decodes a task reward, initializing a missing amount to zero
+func (r *TaskRewardResult) UnmarshalJSON(data []byte) error {
+	type plain TaskRewardResult
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	zeroIfNil(&r.RewardLUX)
+	return nil
+}
+
+// UnmarshalJSON decodes an epoch summary, initializing missing amounts to
+// zero
+func (s *EpochRewardSummary) UnmarshalJSON(data []byte) error {
+	type plain EpochRewardSummary
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+	zeroIfNil(&s.TotalBlockRewardsLUX)
+	zeroIfNil(&s.ValidatorRewardsLUX)
+	zeroIfNil(&s.AIPoolRewardsLUX)
+	zeroIfNil(&s.ParticipationRewardsLUX)
+	zeroIfNil(&s.TaskRewardsLUX)
+	return nil
+}
+
+// UnmarshalJSON decodes a provider statement, initializing missing amounts
+// to zero
+func (s *ProviderStatement) UnmarshalJSON(data []byte) error {
+	type plain ProviderStatement
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+	zeroIfNil(&s.ParticipationLUX)
+	zeroIfNil(&s.TaskLUX)
+	zeroIfNil(&s.TotalLUX)
+	return nil
+}
@@ -0,0 +1,230 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalPoolMissingAmounts(t *testing.T) {
+	now := time.Now().UTC()
+	payload := `{
+		"epoch_number": 3,
+		"epoch_duration": 3600000000000,
+		"total_pool_lux": null,
+		"participation_share": 0.3,
+		"task_share": 0.7,
+		"providers": {
+			"p": {
+				"provider_id": "p",
+				"attestation": {"tier": 2, "issued_at": "` + now.Add(-time.Hour).Format(time.RFC3339) + `", "expires_at": "` + now.Add(time.Hour).Format(time.RFC3339) + `"},
+				"max_modeling_level": 2,
+				"stake_lux": 50000,
+				"last_heartbeat": "` + now.Format(time.RFC3339) + `",
+				"reputation_score": 1
+			},
+			"gone": null
+		},
+		"history": {
+			"p": [
+				{"epoch": 2, "participation": {"provider_id": "p", "weight_share": 1}, "tasks": [{"task_id": "t1"}, null, {"task_id": "t2", "reward_lux": 5}]},
+				null
+			]
+		}
+	}`
+
+	var pool AIRewardPool
+	if err := json.Unmarshal([]byte(payload), &pool); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if pool.TotalPoolLUX == nil || pool.TotalPoolLUX.Sign() != 0 {
+		t.Fatalf("TotalPoolLUX = %v, want 0", pool.TotalPoolLUX)
+	}
+	if _, ok := pool.Providers["gone"]; ok {
+		t.Error("null provider entry was kept")
+	}
+	records := pool.History["p"]
+	if len(records) != 1 || len(records[0].Tasks) != 2 {
+		t.Fatalf("History = %+v, want one record with two tasks", records)
+	}
+	if records[0].Participation.RewardLUX == nil || records[0].Tasks[0].RewardLUX == nil {
+		t.Fatal("missing reward amounts were not initialized")
+	}
+
+	// None of these may panic on the decoded pool
+	pool.CalculateParticipationRewards(time.Hour)
+	pool.RecordTaskReward(pool.Providers["p"], pool.CalculateTaskReward(pool.Providers["p"], "t3", ModelingLevelInferenceStandard, 1))
+	pool.SimulateEpoch(nil, time.Hour)
+	summary := pool.CalculateEpochRewards(big.NewInt(1000), time.Hour)
+	if summary.AIPoolRewardsLUX.Int64() != 100 {
+		t.Errorf("AIPoolRewardsLUX = %s, want 100", summary.AIPoolRewardsLUX)
+	}
+
+	stmt := pool.ProviderStatement("p", 0, 10)
+	if stmt.TaskLUX.Int64() < 5 {
+		t.Errorf("TaskLUX = %s, want at least 5", stmt.TaskLUX)
+	}
+}
+
+func TestUnmarshalResultsMissingAmounts(t *testing.T) {
+	var summary EpochRewardSummary
+	if err := json.Unmarshal([]byte(`{"epoch_number": 1, "validator_rewards_lux": null}`), &summary); err != nil {
+		t.Fatalf("Unmarshal(summary) error = %v", err)
+	}
+	for name, amount := range map[string]*big.Int{
+		"TotalBlockRewardsLUX":    summary.TotalBlockRewardsLUX,
+		"ValidatorRewardsLUX":     summary.ValidatorRewardsLUX,
+		"AIPoolRewardsLUX":        summary.AIPoolRewardsLUX,
+		"ParticipationRewardsLUX": summary.ParticipationRewardsLUX,
+		"TaskRewardsLUX":          summary.TaskRewardsLUX,
+	} {
+		if amount == nil || amount.Sign() != 0 {
+			t.Errorf("%s = %v, want 0", name, amount)
+		}
+	}
+
+	var stmt ProviderStatement
+	if err := json.Unmarshal([]byte(`{"provider_id": "p"}`), &stmt); err != nil {
+		t.Fatalf("Unmarshal(statement) error = %v", err)
+	}
+	if stmt.TotalLUX.Cmp(new(big.Int).Add(stmt.ParticipationLUX, stmt.TaskLUX)) != 0 {
+		t.Errorf("TotalLUX = %s, want 0", stmt.TotalLUX)
+	}
+
+	var task TaskRewardResult
+	if err := json.Unmarshal([]byte(`{"task_id": "t", "reward_lux": 42}`), &task); err != nil {
+		t.Fatalf("Unmarshal(task) error = %v", err)
+	}
+	if task.RewardLUX.Int64() != 42 {
+		t.Errorf("RewardLUX = %s, want 42", task.RewardLUX)
+	}
+}
+
+func TestPoolJSONRoundTrip(t *testing.T) {
+	pool := NewAIRewardPool(time.Hour)
+	pool.TotalPoolLUX = big.NewInt(1234)
+	data, err := json.Marshal(pool)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded AIRewardPool
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if decoded.TotalPoolLUX.Cmp(pool.TotalPoolLUX) != 0 || decoded.EpochDuration != time.Hour {
+		t.Errorf("decoded = %+v, want pool total 1234 and 1h epochs", decoded)
+	}
+}
+
+func TestRewardMathExtremes(t *testing.T) {
+	now := time.Now()
+	provider := func(id string, stake uint64, rep float64) *AIProvider {
+		return &AIProvider{
+			ProviderID: id,
+			Attestation: &TierAttestation{
+				Tier:      Tier4Standard,
+				IssuedAt:  now.Add(-time.Hour),
+				ExpiresAt: now.Add(time.Hour),
+			},
+			MaxModelingLevel: ModelingLevelInferenceLight,
+			StakeLUX:         stake,
+			LastHeartbeat:    now,
+			ReputationScore:  rep,
+		}
+	}
+
+	// 10^60 wei split between a heavy provider and one whose share is far
+	// below the old 1e-9 resolution
+	pool := NewAIRewardPool(time.Hour)
+	pool.TotalPoolLUX = new(big.Int).Exp(big.NewInt(10), big.NewInt(60), nil)
+	pool.Providers["whale"] = provider("whale", 1<<40, 1)
+	pool.Providers["tiny"] = provider("tiny", 1_000, -1.99999999999)
+
+	rewards := pool.CalculateParticipationRewards(time.Minute)
+	if len(rewards) != 2 {
+		t.Fatalf("got %d rewards, want 2", len(rewards))
+	}
+	participation := mulRat(pool.TotalPoolLUX, shareRat(pool.ParticipationShare))
+	sum := new(big.Int)
+	for _, r := range rewards {
+		if r.RewardLUX.Sign() <= 0 {
+			t.Errorf("%s reward = %s, want positive", r.ProviderID, r.RewardLUX)
+		}
+		sum.Add(sum, r.RewardLUX)
+	}
+	if sum.Cmp(participation) > 0 {
+		t.Errorf("rewards sum to %s, more than the participation pool %s", sum, participation)
+	}
+
+	// A multiplier past int64 range once wrapped the reward negative
+	if err := pool.SetTaskRates(&TaskRates{TierMultipliers: map[CCTier]float64{Tier4Standard: 1e18}}); err != nil {
+		t.Fatalf("SetTaskRates() error = %v", err)
+	}
+	reward := pool.CalculateTaskReward(provider("big-mult", 1_000, 1), "t1", ModelingLevelInferenceStandard, 1<<62)
+	want := new(big.Int).Mul(big.NewInt(DefaultBaseRateWei), big.NewInt(1<<62))
+	want.Mul(want, big.NewInt(1e18))
+	if reward.RewardLUX.Cmp(want) != 0 {
+		t.Errorf("reward = %s, want %s", reward.RewardLUX, want)
+	}
+}
+
+func TestDecimalRat(t *testing.T) {
+	tests := []struct {
+		f    float64
+		want string
+	}{
+		{0.3, "3/10"},
+		{1.5, "3/2"},
+		{1e-300, "1/" + new(big.Int).Exp(big.NewInt(10), big.NewInt(300), nil).String()},
+		{0, "0/1"},
+		{-1, "0/1"},
+		{math.NaN(), "0/1"},
+		{math.Inf(1), "0/1"},
+	}
+	for _, tt := range tests {
+		if got := decimalRat(tt.f).String(); got != tt.want {
+			t.Errorf("decimalRat(%v) = %s, want %s", tt.f, got, tt.want)
+		}
+	}
+
+	if got := mulRat(big.NewInt(1e18), shareRat(2)); got.Cmp(big.NewInt(1e18)) != 0 {
+		t.Errorf("share above 1 = %s, want clamped to the whole amount", got)
+	}
+}
+
+func TestSetShares(t *testing.T) {
+	tests := []struct {
+		participation, task float64
+		wantErr             bool
+	}{
+		{0.3, 0.7, false},
+		{0, 1, false},
+		{0.25, 0.5, false},
+		{0.5, 0.6, true},
+		{-0.1, 0.5, true},
+		{1.1, 0, true},
+		{math.NaN(), 0.5, true},
+	}
+	for _, tt := range tests {
+		pool := NewAIRewardPool(time.Hour)
+		err := pool.SetShares(tt.participation, tt.task)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetShares(%v, %v) error = %v, wantErr %v", tt.participation, tt.task, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, ErrInvalidShares) {
+				t.Errorf("SetShares(%v, %v) error = %v, want %v", tt.participation, tt.task, err, ErrInvalidShares)
+			}
+			if pool.ParticipationShare != 0.30 {
+				t.Errorf("rejected shares changed ParticipationShare to %v", pool.ParticipationShare)
+			}
+		}
+	}
+}
@@ -72,22 +72,59 @@ const (
 	TEENone          CPUTEEType = "None"
 )
 
+// GPUCCLevel describes how complete a GPU's confidential computing support is
+type GPUCCLevel uint8
+
+const (
+	GPUCCNone    GPUCCLevel = iota // No GPU CC
+	GPUCCLimited                   // Constrained CC (Ampere A100)
+	GPUCCFull                      // Full CC (Hopper, Blackwell datacenter)
+)
+
+// String returns the level name
+func (l GPUCCLevel) String() string {
+	switch l {
+	case GPUCCNone:
+		return "None"
+	case GPUCCLimited:
+		return "Limited"
+	case GPUCCFull:
+		return "Full"
+	default:
+		return "Unknown"
+	}
+}
+
+// GPUCCMode is the confidential computing mode reported by nvidia-smi
+type GPUCCMode string
+
+const (
+	GPUCCModeOff      GPUCCMode = "Off"
+	GPUCCModeDevTools GPUCCMode = "DevTools" // CC enabled with debugging and profiling allowed; not production-secure
+	GPUCCModeOn       GPUCCMode = "On"
+)
+
 // HardwareCapability represents detected hardware CC capabilities
 type HardwareCapability struct {
 	// GPU capabilities
-	GPUVendor    GPUVendor `json:"gpu_vendor"`
-	GPUModel     string    `json:"gpu_model"`
-	GPUSerial    string    `json:"gpu_serial"`
-	GPUMemoryMB  uint64    `json:"gpu_memory_mb"`
-	GPUDriverVer string    `json:"gpu_driver_version"`
-	ComputeCap   string    `json:"compute_capability"` // e.g., "9.0" for Blackwell
+	GPUVendor    GPUVendor         `json:"gpu_vendor"`
+	GPUModel     string            `json:"gpu_model"`
+	GPUSerial    string            `json:"gpu_serial"`
+	GPUMemoryMB  uint64            `json:"gpu_memory_mb"` // MiB, as nvidia-smi reports it; see MemoryBytes
+	GPUDriverVer string            `json:"gpu_driver_version"`
+	ComputeCap   ComputeCapability `json:"compute_capability"` // e.g., "9.0" for Blackwell
 
 	// GPU CC capabilities
-	GPUCCSupported bool `json:"gpu_cc_supported"`  // Hardware supports CC
-	GPUCCEnabled   bool `json:"gpu_cc_enabled"`    // CC currently enabled
-	NVTrustAvail   bool `json:"nvtrust_available"` // nvtrust local verifier available
-	TEEIOSupported bool `json:"tee_io_supported"`  // TEE-IO for Blackwell
-	MIGSupported   bool `json:"mig_supported"`     // Multi-Instance GPU
+	GPUCCSupported bool      `json:"gpu_cc_supported"`      // Hardware supports CC
+	GPUCCLimited   bool      `json:"gpu_cc_limited"`        // CC support is constrained (A100)
+	GPUCCEnabled   bool      `json:"gpu_cc_enabled"`        // CC currently on in production mode
+	GPUCCMode      GPUCCMode `json:"gpu_cc_mode,omitempty"` // Reported CC mode; GPUCCEnabled is GPUCCMode == On
+	NVTrustAvail   bool      `json:"nvtrust_available"`     // nvtrust local verifier available
+	TEEIOSupported bool      `json:"tee_io_supported"`      // TEE-IO for Blackwell
+	MIGSupported   bool      `json:"mig_supported"`         // Multi-Instance GPU
+
+	Fabric *GPUFabric `json:"fabric,omitempty"` // NVLink/NVSwitch fabric; nil if no GPU has an active link
+	ECC    *GPUECC    `json:"ecc,omitempty"`    // ECC/RAS state; nil if no GPU supports ECC
 
 	// CPU TEE capabilities
 	CPUVendor    string     `json:"cpu_vendor"`
@@ -95,6 +132,9 @@ type HardwareCapability struct {
 	CPUTEEType   CPUTEEType `json:"cpu_tee_type"`
 	CPUTEEActive bool       `json:"cpu_tee_active"` // Currently running in TEE
 
+	CPUTEEHostCapable bool `json:"cpu_tee_host_capable,omitempty"` // Bare-metal host can launch confidential VMs
+	CPUTEEToolsAvail  bool `json:"cpu_tee_tools_available"`        // Guest attestation tool for CPUTEEType installed
+
 	// Device TEE capabilities (mobile/edge)
 	DeviceTEEType    string `json:"device_tee_type,omitempty"`
 	DeviceTEEEnabled bool   `json:"device_tee_enabled,omitempty"`
@@ -176,6 +216,16 @@ func detectNVIDIACapabilitiesWithDeps(cap *HardwareCapability, cmdRunner Command
 		cap.GPUSerial = strings.TrimSpace(parts[3])
 	}
 
+	// A Grace CPU-only host has no NVIDIA GPU to attest
+	if isGraceCPUOnly(cap.GPUModel) {
+		cap.GPUVendor = VendorUnknown
+		cap.GPUModel = ""
+		cap.GPUMemoryMB = 0
+		cap.GPUDriverVer = ""
+		cap.GPUSerial = ""
+		return false
+	}
+
 	// Detect CC capabilities based on GPU model
 	detectNVIDIACCCapabilitiesByModel(cap)
 
@@ -184,11 +234,18 @@ func detectNVIDIACapabilitiesWithDeps(cap *HardwareCapability, cmdRunner Command
 		cap.NVTrustAvail = checkNVTrustAvailableWithDeps(fileReader)
 	}
 
-	// Check if CC mode is currently enabled (requires nvidia-smi query)
+	// Check the current CC mode (requires nvidia-smi query)
 	if cap.GPUCCSupported {
-		cap.GPUCCEnabled = checkNVIDIACCEnabledWithDeps(cmdRunner)
+		cap.GPUCCMode = checkNVIDIACCModeWithDeps(cmdRunner)
+		cap.GPUCCEnabled = cap.GPUCCMode == GPUCCModeOn
 	}
 
+	// Multi-GPU hosts may join their GPUs with NVLink/NVSwitch
+	cap.Fabric = detectNVLinkWithDeps(cmdRunner)
+
+	// Datacenter GPUs report ECC error counts and row remapping
+	cap.ECC = detectECCWithDeps(cmdRunner)
+
 	return true
 }
 
@@ -198,56 +255,94 @@ func detectNVIDIACCCapabilitiesByModel(cap *HardwareCapability) {
 	switch {
 	// Blackwell datacenter - highest CC tier (9.0)
 	case strings.Contains(model, "B100") || strings.Contains(model, "B200") || strings.Contains(model, "GB200"):
-		cap.ComputeCap = "9.0"
+		cap.ComputeCap = ComputeCapHopper
 		cap.GPUCCSupported = true
 		cap.TEEIOSupported = true
 		cap.MIGSupported = true
 
+	// Grace Hopper Superchip - Hopper GPU, full CC (9.0)
+	case isGraceHopper(model):
+		cap.ComputeCap = ComputeCapHopper
+		cap.GPUCCSupported = true
+		cap.TEEIOSupported = false
+		cap.MIGSupported = true
+
+	// Grace CPU without a Hopper GPU - no GPU, so no GPU CC
+	case isGraceCPUOnly(model):
+		cap.ComputeCap = ""
+		cap.GPUCCSupported = false
+		cap.TEEIOSupported = false
+		cap.MIGSupported = false
+
 	// Hopper datacenter - full CC support (9.0)
 	case strings.Contains(model, "H100") || strings.Contains(model, "H200"):
-		cap.ComputeCap = "9.0"
+		cap.ComputeCap = ComputeCapHopper
 		cap.GPUCCSupported = true
 		cap.TEEIOSupported = false // TEE-IO is Blackwell only
 		cap.MIGSupported = true
 
 	// Ada professional - CC support (8.9)
 	case strings.Contains(model, "RTX 6000") && strings.Contains(model, "Ada"):
-		cap.ComputeCap = "8.9"
+		cap.ComputeCap = ComputeCapAda
 		cap.GPUCCSupported = true
 		cap.TEEIOSupported = false
 		cap.MIGSupported = false
 
 	// RTX PRO 6000 Blackwell - CC support (9.0)
 	case strings.Contains(model, "RTX PRO 6000"):
-		cap.ComputeCap = "9.0"
+		cap.ComputeCap = ComputeCapHopper
 		cap.GPUCCSupported = true
 		cap.TEEIOSupported = true
 		cap.MIGSupported = false
 
-	// Grace Hopper Superchip - full CC (9.0)
-	case strings.Contains(model, "Grace"):
-		cap.ComputeCap = "9.0"
+	// Ampere datacenter - limited CC (8.0)
+	case strings.Contains(model, "A100"):
+		cap.ComputeCap = ComputeCapAmpere
 		cap.GPUCCSupported = true
+		cap.GPUCCLimited = true
 		cap.TEEIOSupported = false
 		cap.MIGSupported = true
 
 	// Consumer Blackwell - NO CC support (confirmed by NVIDIA forums)
 	case strings.Contains(model, "5090") || strings.Contains(model, "5080"):
-		cap.ComputeCap = "9.0"
+		cap.ComputeCap = ComputeCapHopper
 		cap.GPUCCSupported = false // Explicitly disabled
 
 	// DGX Spark (GB10) - NO CC support (confirmed by NVIDIA forums)
 	case strings.Contains(model, "GB10"):
-		cap.ComputeCap = "9.0"
+		cap.ComputeCap = ComputeCapHopper
 		cap.GPUCCSupported = false // Explicitly disabled
 
 	// Consumer Ada - no CC support (8.9)
 	case strings.Contains(model, "4090") || strings.Contains(model, "4080"):
-		cap.ComputeCap = "8.9"
+		cap.ComputeCap = ComputeCapAda
 		cap.GPUCCSupported = false
 	}
 }
 
+// isGraceHopper reports whether model names a Grace Hopper superchip (GH200),
+// i.e. a Grace CPU paired with a Hopper GPU
+func isGraceHopper(model string) bool {
+	return strings.Contains(model, "GH200") || strings.Contains(model, "Grace Hopper")
+}
+
+// graceGPUMarkers identify a Grace part that carries an NVIDIA GPU
+var graceGPUMarkers = []string{"GH200", "GB200", "Hopper", "Blackwell"}
+
+// isGraceCPUOnly reports whether model names a Grace CPU with no GPU, such
+// as the Grace CPU Superchip
+func isGraceCPUOnly(model string) bool {
+	if !strings.Contains(model, "Grace") {
+		return false
+	}
+	for _, marker := range graceGPUMarkers {
+		if strings.Contains(model, marker) {
+			return false
+		}
+	}
+	return true
+}
+
 // checkNVTrustAvailable checks if nvtrust local verifier tools are available
 func checkNVTrustAvailable() bool {
 	return checkNVTrustAvailableWithDeps(defaultFileReader)
@@ -275,15 +370,32 @@ func checkNVIDIACCEnabled() bool {
 	return checkNVIDIACCEnabledWithDeps(defaultCommandRunner)
 }
 
-// checkNVIDIACCEnabledWithDeps is the testable version
+// checkNVIDIACCEnabledWithDeps is the testable version. DevTools mode does
+// not count as enabled.
 func checkNVIDIACCEnabledWithDeps(cmdRunner CommandRunner) bool {
-	// Query nvidia-smi for CC mode status
+	return checkNVIDIACCModeWithDeps(cmdRunner) == GPUCCModeOn
+}
+
+// checkNVIDIACCModeWithDeps queries nvidia-smi for the CC mode. Anything
+// other than on or devtools, including a failed query, is Off.
+func checkNVIDIACCModeWithDeps(cmdRunner CommandRunner) GPUCCMode {
 	output, err := cmdRunner.Run("nvidia-smi", "--query-gpu=conf-compute.mode", "--format=csv,noheader")
 	if err != nil {
-		return false
+		return GPUCCModeOff
+	}
+	return parseGPUCCMode(string(output))
+}
+
+// parseGPUCCMode maps an nvidia-smi conf-compute.mode value to a GPUCCMode
+func parseGPUCCMode(output string) GPUCCMode {
+	switch strings.ToLower(strings.TrimSpace(output)) {
+	case "on", "enabled", "1":
+		return GPUCCModeOn
+	case "devtools", "dev-tools", "dev tools":
+		return GPUCCModeDevTools
+	default:
+		return GPUCCModeOff
 	}
-	mode := strings.ToLower(strings.TrimSpace(string(output)))
-	return mode == "on" || mode == "enabled" || mode == "1"
 }
 
 // detectAMDCapabilities detects AMD GPU capabilities
@@ -344,22 +456,22 @@ func detectAppleSiliconCapabilitiesWithDeps(cap *HardwareCapability, cmdRunner C
 		switch {
 		case strings.Contains(brand, "M4"):
 			cap.NPUModel = "Neural Engine 18-core"
-			cap.ComputeCap = "apple-m4"
+			cap.ComputeCap = ComputeCapAppleM4
 			cap.DeviceTEEType = "SecureEnclave"
 			cap.DeviceTEEEnabled = true
 		case strings.Contains(brand, "M3"):
 			cap.NPUModel = "Neural Engine 16-core"
-			cap.ComputeCap = "apple-m3"
+			cap.ComputeCap = ComputeCapAppleM3
 			cap.DeviceTEEType = "SecureEnclave"
 			cap.DeviceTEEEnabled = true
 		case strings.Contains(brand, "M2"):
 			cap.NPUModel = "Neural Engine 16-core"
-			cap.ComputeCap = "apple-m2"
+			cap.ComputeCap = ComputeCapAppleM2
 			cap.DeviceTEEType = "SecureEnclave"
 			cap.DeviceTEEEnabled = true
 		case strings.Contains(brand, "M1"):
 			cap.NPUModel = "Neural Engine 16-core"
-			cap.ComputeCap = "apple-m1"
+			cap.ComputeCap = ComputeCapAppleM1
 			cap.DeviceTEEType = "SecureEnclave"
 			cap.DeviceTEEEnabled = true
 		}
@@ -406,33 +518,150 @@ func detectLinuxCPUTEEWithDeps(cap *HardwareCapability, fileReader FileReader) {
 		cap.CPUModel = strings.TrimSpace(match[1])
 	}
 
-	// Detect SEV-SNP (AMD)
-	if strings.Contains(cap.CPUVendor, "AMD") {
-		if _, err := fileReader.Stat("/dev/sev-guest"); err == nil {
-			cap.CPUTEEType = TEESEVSNP
-			// Check if we're running inside a SEV-SNP VM
-			cap.CPUTEEActive = checkSEVSNPActiveWithDeps(fileReader)
+	// Detect a confidential guest by its device node. Some ARM and
+	// virtualized cpuinfo layouts omit vendor_id, so the vendor only
+	// decides which nodes are probed first.
+	if tee, active, ok := probeCPUTEEGuestWithDeps(cpuTEEHint(cap.CPUVendor, cpuinfo), fileReader); ok {
+		cap.CPUTEEType = tee
+		cap.CPUTEEActive = active
+	}
+
+	// Detect a host that can launch confidential VMs without being in one.
+	// CPUTEEType stays for the TEE this process runs in.
+	cap.CPUTEEHostCapable = checkCPUTEEHostWithDeps(cap.CPUVendor, cpuinfo, fileReader)
+
+	cap.CPUTEEToolsAvail = checkCPUTEEToolsAvailableWithDeps(cap.CPUTEEType, fileReader)
+}
+
+// cpuTEETools lists, per CPU TEE, the guest tool that fetches the
+// attestation report a Tier 2 attestation is built from
+var cpuTEETools = map[CPUTEEType]struct {
+	name    string
+	install string
+}{
+	TEESEVSNP: {"snpguest", "https://github.com/virtee/snpguest"},
+	TEETDX:    {"trustauthority-cli", "https://github.com/intel/trustauthority-client-for-go"},
+}
+
+// cpuTEEToolDirs are where attestation tools are looked for
+var cpuTEEToolDirs = []string{"/usr/local/bin", "/usr/bin", "/opt/tee/bin"}
+
+// checkCPUTEEToolsAvailableWithDeps reports whether the attestation tool
+// for tee is installed. TEEs without a known tool report false.
+func checkCPUTEEToolsAvailableWithDeps(tee CPUTEEType, fileReader FileReader) bool {
+	tool, ok := cpuTEETools[tee]
+	if !ok {
+		return false
+	}
+	for _, dir := range cpuTEEToolDirs {
+		if _, err := fileReader.Stat(dir + "/" + tool.name); err == nil {
+			return true
 		}
 	}
+	return false
+}
 
-	// Detect TDX (Intel)
-	if strings.Contains(cap.CPUVendor, "Intel") {
-		if _, err := fileReader.Stat("/dev/tdx-guest"); err == nil {
-			cap.CPUTEEType = TEETDX
-			cap.CPUTEEActive = checkTDXActiveWithDeps(fileReader)
-		} else if _, err := fileReader.Stat("/dev/sgx_enclave"); err == nil {
-			cap.CPUTEEType = TEESGX
-			cap.CPUTEEActive = true
+// cpuTEEGuests lists the device node each CPU TEE exposes to software
+// running inside it, in probe order, with the CPU vendor it belongs to and
+// how to tell whether it is active (nil means whenever the node exists)
+var cpuTEEGuests = []struct {
+	vendor string
+	tee    CPUTEEType
+	device string
+	active func(FileReader) bool
+}{
+	{"AMD", TEESEVSNP, "/dev/sev-guest", checkSEVSNPActiveWithDeps},
+	{"Intel", TEETDX, "/dev/tdx-guest", checkTDXActiveWithDeps},
+	{"Intel", TEESGX, "/dev/sgx_enclave", nil},
+	{"ARM", TEECCA, "/sys/devices/platform/arm-cca", nil},
+}
+
+// cpuTEEHint returns the CPU vendor suggested by cpuinfo, "" if unknown
+func cpuTEEHint(vendor, cpuinfo string) string {
+	switch lower := strings.ToLower(cpuinfo); {
+	case strings.Contains(vendor, "AMD"):
+		return "AMD"
+	case strings.Contains(vendor, "Intel"):
+		return "Intel"
+	case strings.Contains(lower, "aarch64") || strings.Contains(lower, "arm") || strings.Contains(lower, "cpu implementer"):
+		return "ARM"
+	}
+	return ""
+}
+
+// probeCPUTEEGuestWithDeps returns the CPU TEE whose guest device node
+// exists. The nodes of the hinted vendor are probed first, then the rest,
+// so a TEE is found even when cpuinfo names no vendor or the wrong one.
+func probeCPUTEEGuestWithDeps(hint string, fileReader FileReader) (tee CPUTEEType, active, ok bool) {
+	for _, hinted := range []bool{true, false} {
+		for _, guest := range cpuTEEGuests {
+			if (guest.vendor == hint) != hinted {
+				continue
+			}
+			if _, err := fileReader.Stat(guest.device); err != nil {
+				continue
+			}
+			if guest.active == nil {
+				return guest.tee, true, true
+			}
+			return guest.tee, guest.active(fileReader), true
 		}
 	}
+	return TEENone, false, false
+}
+
+// cpuTEEHosts lists, per CPU vendor, the cpuinfo flag advertising host
+// support for a confidential VM technology and the KVM parameter that
+// enables it
+var cpuTEEHosts = []struct {
+	vendor   string
+	tee      CPUTEEType
+	flag     string
+	kvmParam string
+}{
+	{"AMD", TEESEVSNP, "sev_snp", "/sys/module/kvm_amd/parameters/sev_snp"},
+	{"Intel", TEETDX, "tdx_host_platform", "/sys/module/kvm_intel/parameters/tdx"},
+}
+
+// checkCPUTEEHostWithDeps reports whether this machine can host confidential
+// VMs. A loaded KVM module decides through its parameter; otherwise the
+// cpuinfo flag counts unless the kernel runs under a hypervisor, where the
+// flag describes the guest instead.
+func checkCPUTEEHostWithDeps(vendor, cpuinfo string, fileReader FileReader) bool {
+	flags := cpuinfoFlags(cpuinfo)
+	for _, host := range cpuTEEHosts {
+		if !strings.Contains(vendor, host.vendor) {
+			continue
+		}
+		if data, err := fileReader.ReadFile(host.kvmParam); err == nil {
+			v := strings.TrimSpace(string(data))
+			return v == "Y" || v == "1"
+		}
+		return flags[host.flag] && !flags["hypervisor"]
+	}
+	return false
+}
 
-	// Detect ARM CCA
-	if strings.Contains(strings.ToLower(cpuinfo), "aarch64") || strings.Contains(strings.ToLower(cpuinfo), "arm") {
-		if _, err := fileReader.Stat("/sys/devices/platform/arm-cca"); err == nil {
-			cap.CPUTEEType = TEECCA
-			cap.CPUTEEActive = true
+// cpuTEEHostType returns the confidential VM technology a host with this
+// CPU vendor launches
+func cpuTEEHostType(vendor string) CPUTEEType {
+	for _, host := range cpuTEEHosts {
+		if strings.Contains(vendor, host.vendor) {
+			return host.tee
+		}
+	}
+	return TEENone
+}
+
+// cpuinfoFlags returns the CPU feature flags of the first processor
+func cpuinfoFlags(cpuinfo string) map[string]bool {
+	flags := make(map[string]bool)
+	if match := regexp.MustCompile(`(?m)^flags\s*:\s*(.*)$`).FindStringSubmatch(cpuinfo); len(match) > 1 {
+		for _, f := range strings.Fields(match[1]) {
+			flags[f] = true
 		}
 	}
+	return flags
 }
 
 // checkSEVSNPActive checks if running inside a SEV-SNP VM
@@ -485,8 +714,15 @@ func detectDeviceTEECapabilities(cap *HardwareCapability) {
 // calculateMaxTier determines the maximum achievable CC tier
 func calculateMaxTier(cap *HardwareCapability) CCTier {
 	// Tier 1: GPU-native CC (NVIDIA with NVTrust)
-	if cap.GPUCCSupported && cap.GPUCCEnabled && cap.NVTrustAvail {
-		return Tier1GPUNativeCC
+	// Limited GPU CC ranks with confidential VMs rather than full GPU CC
+	// DevTools mode allows debugging the GPU, so it never counts as GPU CC
+	if cap.GPUCCEnabled && cap.GPUCCMode != GPUCCModeDevTools && cap.NVTrustAvail {
+		switch cap.GPUCCLevel() {
+		case GPUCCFull:
+			return Tier1GPUNativeCC
+		case GPUCCLimited:
+			return Tier2ConfidentialVM
+		}
 	}
 
 	// Tier 2: Confidential VM + GPU
@@ -518,12 +754,25 @@ func (c *HardwareCapability) GetSupportedTiers() []CCTier {
 	return tiers
 }
 
+// GPUCCLevel returns the GPU's confidential computing level
+func (c *HardwareCapability) GPUCCLevel() GPUCCLevel {
+	switch {
+	case !c.GPUCCSupported:
+		return GPUCCNone
+	case c.GPUCCLimited:
+		return GPUCCLimited
+	default:
+		return GPUCCFull
+	}
+}
+
 // IsGPUCCCapable returns true if the GPU supports hardware CC
 func (c *HardwareCapability) IsGPUCCCapable() bool {
 	return c.GPUCCSupported
 }
 
-// IsCPUTEECapable returns true if the CPU supports TEE
+// IsCPUTEECapable returns true if this process runs on a CPU TEE. A host
+// that can only launch confidential VMs reports CPUTEEHostCapable instead.
 func (c *HardwareCapability) IsCPUTEECapable() bool {
 	return c.CPUTEEType != TEENone
 }
@@ -535,11 +784,20 @@ func (c *HardwareCapability) IsDeviceTEECapable() bool {
 
 // RequiresSetup returns true if additional setup is needed to enable CC
 func (c *HardwareCapability) RequiresSetup() (bool, string) {
+	if c.GPUCCSupported && c.GPUCCMode == GPUCCModeDevTools {
+		return true, "GPU CC is in DevTools mode, which is not production-secure. Run: nvidia-smi -i 0 -cc 1"
+	}
 	if c.GPUCCSupported && !c.GPUCCEnabled {
 		return true, "GPU CC mode needs to be enabled. Run: nvidia-smi -i 0 -cc 1"
 	}
 	if c.GPUCCSupported && !c.NVTrustAvail {
 		return true, "nvtrust tools not found. Install from: https://github.com/NVIDIA/nvtrust"
 	}
+	if c.CPUTEEHostCapable && !c.CPUTEEActive && c.MaxTier > Tier2ConfidentialVM {
+		return true, "Host supports " + string(cpuTEEHostType(c.CPUVendor)) + " confidential VMs. Run the provider inside one to reach Tier 2"
+	}
+	if tool, ok := cpuTEETools[c.CPUTEEType]; ok && c.CPUTEEActive && !c.CPUTEEToolsAvail {
+		return true, tool.name + " not found; it is needed to produce " + string(c.CPUTEEType) + " attestations. Install from: " + tool.install
+	}
 	return false, ""
 }
@@ -6,6 +6,7 @@ package cc
 import (
 	"errors"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -245,6 +246,24 @@ func TestDetectNVIDIACapabilities_GraceHopper(t *testing.T) {
 	}
 }
 
+func TestDetectNVIDIACapabilities_GraceCPUOnly(t *testing.T) {
+	cmdRunner := NewMockCommandRunner()
+	fileReader := NewMockFileReader()
+
+	cmdRunner.SetOutput("nvidia-smi", []byte("NVIDIA Grace CPU Superchip, 0, 550.54.15, N/A\n"))
+
+	cap := &HardwareCapability{}
+	if detectNVIDIACapabilitiesWithDeps(cap, cmdRunner, fileReader) {
+		t.Fatal("Grace CPU-only host should not be detected as an NVIDIA GPU")
+	}
+	if cap.GPUCCSupported || cap.MIGSupported || cap.ComputeCap != "" {
+		t.Errorf("Grace CPU-only host claims GPU features: %+v", cap)
+	}
+	if cap.GPUVendor != VendorUnknown || cap.GPUModel != "" {
+		t.Errorf("GPU = %s %q, want no GPU", cap.GPUVendor, cap.GPUModel)
+	}
+}
+
 func TestDetectNVIDIACapabilities_RTX5090_NoCC(t *testing.T) {
 	cmdRunner := NewMockCommandRunner()
 	fileReader := NewMockFileReader()
@@ -369,6 +388,94 @@ func TestCheckNVIDIACCEnabled_Variants(t *testing.T) {
 	}
 }
 
+func TestCheckNVIDIACCMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    GPUCCMode
+		enabled bool
+	}{
+		{"on", "on\n", GPUCCModeOn, true},
+		{"ON uppercase", "ON\n", GPUCCModeOn, true},
+		{"enabled", "Enabled\n", GPUCCModeOn, true},
+		{"1", "1\n", GPUCCModeOn, true},
+		{"devtools", "devtools\n", GPUCCModeDevTools, false},
+		{"DEVTOOLS uppercase", "DEVTOOLS\n", GPUCCModeDevTools, false},
+		{"DevTools mixed", "DevTools\n", GPUCCModeDevTools, false},
+		{"dev-tools", "dev-tools\n", GPUCCModeDevTools, false},
+		{"off", "OFF\n", GPUCCModeOff, false},
+		{"0", "0\n", GPUCCModeOff, false},
+		{"unknown", "maybe\n", GPUCCModeOff, false},
+		{"empty", "", GPUCCModeOff, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmdRunner := NewMockCommandRunner()
+			cmdRunner.SetOutput("nvidia-smi", []byte(tt.output))
+
+			if got := checkNVIDIACCModeWithDeps(cmdRunner); got != tt.want {
+				t.Errorf("checkNVIDIACCModeWithDeps(%q) = %v, want %v", tt.output, got, tt.want)
+			}
+			if got := checkNVIDIACCEnabledWithDeps(cmdRunner); got != tt.enabled {
+				t.Errorf("checkNVIDIACCEnabledWithDeps(%q) = %v, want %v", tt.output, got, tt.enabled)
+			}
+		})
+	}
+
+	cmdRunner := NewMockCommandRunner()
+	cmdRunner.SetError("nvidia-smi", errors.New("no devices"))
+	if got := checkNVIDIACCModeWithDeps(cmdRunner); got != GPUCCModeOff {
+		t.Errorf("checkNVIDIACCModeWithDeps() on error = %v, want %v", got, GPUCCModeOff)
+	}
+}
+
+func TestDevToolsModeNotTier1(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      GPUCCMode
+		enabled   bool
+		cpuTEE    bool
+		want      CCTier
+		wantSetup bool
+	}{
+		{"on", GPUCCModeOn, true, false, Tier1GPUNativeCC, false},
+		{"devtools", GPUCCModeDevTools, false, false, Tier4Standard, true},
+		{"devtools in confidential VM", GPUCCModeDevTools, false, true, Tier2ConfidentialVM, true},
+		{"devtools reported as enabled", GPUCCModeDevTools, true, false, Tier4Standard, true},
+		{"off", GPUCCModeOff, false, false, Tier4Standard, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cap := &HardwareCapability{
+				GPUVendor:      VendorNVIDIA,
+				GPUModel:       "H100",
+				GPUCCSupported: true,
+				GPUCCEnabled:   tt.enabled,
+				GPUCCMode:      tt.mode,
+				NVTrustAvail:   true,
+				CPUTEEType:     TEENone,
+			}
+			if tt.cpuTEE {
+				cap.CPUTEEType = TEESEVSNP
+				cap.CPUTEEActive = true
+			}
+
+			if got := calculateMaxTier(cap); got != tt.want {
+				t.Errorf("calculateMaxTier() = %v, want %v", got, tt.want)
+			}
+			needsSetup, hint := cap.RequiresSetup()
+			if needsSetup != tt.wantSetup {
+				t.Errorf("RequiresSetup() = %v, want %v", needsSetup, tt.wantSetup)
+			}
+			if tt.mode == GPUCCModeDevTools && !strings.Contains(hint, "DevTools") {
+				t.Errorf("RequiresSetup() hint = %q, want DevTools mention", hint)
+			}
+		})
+	}
+}
+
 func TestCheckNVTrustAvailable(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -676,6 +783,49 @@ model name	: ARMv9 Processor (aarch64)
 	}
 }
 
+func TestDetectLinuxCPUTEE_NoVendorLine(t *testing.T) {
+	// ARM-style layout: no vendor_id or model name
+	const armInfo = "processor\t: 0\nBogoMIPS\t: 50.00\nFeatures\t: fp asimd evtstrm\nCPU implementer\t: 0x41\nCPU architecture: 8\n"
+	// Virtualized layout that only lists processors
+	const bareInfo = "processor\t: 0\nprocessor\t: 1\n"
+
+	tests := []struct {
+		name       string
+		cpuinfo    string
+		devices    []string
+		wantType   CPUTEEType
+		wantActive bool
+	}{
+		{"SEV-SNP without vendor", bareInfo, []string{"/dev/sev-guest"}, TEESEVSNP, true},
+		{"TDX without vendor", bareInfo, []string{"/dev/tdx-guest"}, TEETDX, true},
+		{"SEV-SNP on ARM-style cpuinfo", armInfo, []string{"/dev/sev-guest"}, TEESEVSNP, true},
+		{"CCA hinted before SEV-SNP", armInfo, []string{"/dev/sev-guest", "/sys/devices/platform/arm-cca"}, TEECCA, true},
+		{"TDX preferred over SGX", bareInfo, []string{"/dev/sgx_enclave", "/dev/tdx-guest"}, TEETDX, true},
+		{"vendor hint wins", "vendor_id\t: GenuineIntel\n", []string{"/dev/sev-guest", "/dev/sgx_enclave"}, TEESGX, true},
+		{"no device", armInfo, nil, TEENone, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileReader := NewMockFileReader()
+			fileReader.SetFile("/proc/cpuinfo", []byte(tt.cpuinfo))
+			for _, dev := range tt.devices {
+				fileReader.SetExists(dev, true)
+			}
+
+			cap := &HardwareCapability{CPUTEEType: TEENone}
+			detectLinuxCPUTEEWithDeps(cap, fileReader)
+
+			if cap.CPUTEEType != tt.wantType {
+				t.Errorf("CPUTEEType = %v, want %v", cap.CPUTEEType, tt.wantType)
+			}
+			if cap.CPUTEEActive != tt.wantActive {
+				t.Errorf("CPUTEEActive = %v, want %v", cap.CPUTEEActive, tt.wantActive)
+			}
+		})
+	}
+}
+
 func TestDetectLinuxCPUTEE_NoTEE(t *testing.T) {
 	fileReader := NewMockFileReader()
 
@@ -708,6 +858,115 @@ func TestDetectLinuxCPUTEE_NoCPUInfo(t *testing.T) {
 	}
 }
 
+func TestDetectLinuxCPUTEE_HostCapable(t *testing.T) {
+	const amd = "vendor_id\t: AuthenticAMD\nmodel name\t: AMD EPYC 9654 96-Core Processor\n"
+	const intel = "vendor_id\t: GenuineIntel\nmodel name\t: Intel(R) Xeon(R) Platinum 8580\n"
+
+	tests := []struct {
+		name        string
+		cpuinfo     string
+		files       map[string]string
+		guestDevice string
+		wantType    CPUTEEType
+		wantHost    bool
+		wantActive  bool
+	}{
+		{"AMD host via cpuinfo", amd + "flags\t: fpu sev sev_es sev_snp\n", nil, "", TEENone, true, false},
+		{"AMD host via kvm", amd, map[string]string{"/sys/module/kvm_amd/parameters/sev_snp": "Y\n"}, "", TEENone, true, false},
+		{"AMD kvm disables SNP", amd + "flags\t: sev_snp\n", map[string]string{"/sys/module/kvm_amd/parameters/sev_snp": "N\n"}, "", TEENone, false, false},
+		{"AMD guest flag under hypervisor", amd + "flags\t: sev_snp hypervisor\n", nil, "/dev/sev-guest", TEESEVSNP, false, true},
+		{"Intel TDX host via cpuinfo", intel + "flags\t: vmx tdx_host_platform\n", nil, "", TEENone, true, false},
+		{"Intel TDX host via kvm", intel, map[string]string{"/sys/module/kvm_intel/parameters/tdx": "1"}, "", TEENone, true, false},
+		{"Intel without TDX", intel + "flags\t: vmx sgx\n", nil, "", TEENone, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileReader := NewMockFileReader()
+			fileReader.SetFile("/proc/cpuinfo", []byte(tt.cpuinfo))
+			for path, content := range tt.files {
+				fileReader.SetFile(path, []byte(content))
+			}
+			if tt.guestDevice != "" {
+				fileReader.SetExists(tt.guestDevice, true)
+				fileReader.SetExists("/usr/bin/snpguest", true)
+			}
+
+			cap := &HardwareCapability{CPUTEEType: TEENone}
+			detectLinuxCPUTEEWithDeps(cap, fileReader)
+
+			if cap.CPUTEEType != tt.wantType {
+				t.Errorf("CPUTEEType = %v, want %v", cap.CPUTEEType, tt.wantType)
+			}
+			if cap.CPUTEEHostCapable != tt.wantHost {
+				t.Errorf("CPUTEEHostCapable = %v, want %v", cap.CPUTEEHostCapable, tt.wantHost)
+			}
+			if cap.CPUTEEActive != tt.wantActive {
+				t.Errorf("CPUTEEActive = %v, want %v", cap.CPUTEEActive, tt.wantActive)
+			}
+
+			// A host that isn't itself confidential stays below Tier 2
+			cap.MaxTier = calculateMaxTier(cap)
+			if tt.wantHost && cap.MaxTier != Tier4Standard {
+				t.Errorf("MaxTier = %v, want Tier4 until a CVM is running", cap.MaxTier)
+			}
+			if cap.IsCPUTEECapable() != tt.wantActive {
+				t.Errorf("IsCPUTEECapable() = %v, want %v", cap.IsCPUTEECapable(), tt.wantActive)
+			}
+			if needsSetup, hint := cap.RequiresSetup(); needsSetup != tt.wantHost {
+				t.Errorf("RequiresSetup() = %v, %q; want %v", needsSetup, hint, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestCPUTEEToolsAvailable(t *testing.T) {
+	const amd = "vendor_id\t: AuthenticAMD\n"
+	const intel = "vendor_id\t: GenuineIntel\n"
+
+	tests := []struct {
+		name      string
+		cpuinfo   string
+		device    string
+		tool      string
+		wantAvail bool
+		wantHint  string // Substring of the setup hint, "" if no setup needed
+	}{
+		{"SEV-SNP guest with snpguest", amd, "/dev/sev-guest", "/usr/local/bin/snpguest", true, ""},
+		{"SEV-SNP guest without snpguest", amd, "/dev/sev-guest", "", false, "snpguest"},
+		{"SEV-SNP guest with the wrong tool", amd, "/dev/sev-guest", "/usr/bin/trustauthority-cli", false, "snpguest"},
+		{"TDX guest with trustauthority-cli", intel, "/dev/tdx-guest", "/opt/tee/bin/trustauthority-cli", true, ""},
+		{"TDX guest without trustauthority-cli", intel, "/dev/tdx-guest", "", false, "trustauthority-cli"},
+		{"SGX has no known tool", intel, "/dev/sgx_enclave", "/usr/bin/snpguest", false, ""},
+		{"no TEE", amd, "", "/usr/bin/snpguest", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileReader := NewMockFileReader()
+			fileReader.SetFile("/proc/cpuinfo", []byte(tt.cpuinfo))
+			if tt.device != "" {
+				fileReader.SetExists(tt.device, true)
+			}
+			if tt.tool != "" {
+				fileReader.SetExists(tt.tool, true)
+			}
+
+			cap := &HardwareCapability{CPUTEEType: TEENone}
+			detectLinuxCPUTEEWithDeps(cap, fileReader)
+			cap.MaxTier = calculateMaxTier(cap)
+
+			if cap.CPUTEEToolsAvail != tt.wantAvail {
+				t.Errorf("CPUTEEToolsAvail = %v, want %v", cap.CPUTEEToolsAvail, tt.wantAvail)
+			}
+			needsSetup, hint := cap.RequiresSetup()
+			if needsSetup != (tt.wantHint != "") || !strings.Contains(hint, tt.wantHint) {
+				t.Errorf("RequiresSetup() = %v, %q; want hint mentioning %q", needsSetup, hint, tt.wantHint)
+			}
+		})
+	}
+}
+
 // =============================================================================
 // SEV-SNP Active Tests
 // =============================================================================
@@ -797,6 +1056,9 @@ func TestDetectNVIDIACCCapabilitiesByModel(t *testing.T) {
 		{"RTX 6000 Ada", "NVIDIA RTX 6000 Ada Generation", true, false, false, "8.9"},
 		{"RTX PRO 6000", "NVIDIA RTX PRO 6000", true, true, false, "9.0"},
 		{"Grace", "NVIDIA GH200 Grace Hopper", true, false, true, "9.0"},
+		{"GH200", "NVIDIA GH200 480GB", true, false, true, "9.0"},
+		{"Grace CPU only", "NVIDIA Grace CPU Superchip", false, false, false, ""},
+		{"A100", "NVIDIA A100-SXM4-80GB", true, false, true, "8.0"},
 		{"RTX 5090", "NVIDIA GeForce RTX 5090", false, false, false, "9.0"},
 		{"RTX 5080", "NVIDIA GeForce RTX 5080", false, false, false, "9.0"},
 		{"GB10", "NVIDIA GB10", false, false, false, "9.0"},
@@ -819,7 +1081,7 @@ func TestDetectNVIDIACCCapabilitiesByModel(t *testing.T) {
 			if cap.MIGSupported != tt.expectMIG {
 				t.Errorf("MIGSupported: expected %v, got %v", tt.expectMIG, cap.MIGSupported)
 			}
-			if cap.ComputeCap != tt.expectCompute {
+			if string(cap.ComputeCap) != tt.expectCompute {
 				t.Errorf("ComputeCap: expected %s, got %s", tt.expectCompute, cap.ComputeCap)
 			}
 		})
@@ -1318,6 +1580,43 @@ func TestCalculateMaxTierPriority(t *testing.T) {
 	}
 }
 
+func TestGPUCCLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		want  GPUCCLevel
+	}{
+		{"H100", "NVIDIA H100 80GB", GPUCCFull},
+		{"A100", "NVIDIA A100-SXM4-80GB", GPUCCLimited},
+		{"RTX 4090", "NVIDIA GeForce RTX 4090", GPUCCNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cap := &HardwareCapability{GPUModel: tt.model}
+			detectNVIDIACCCapabilitiesByModel(cap)
+			if got := cap.GPUCCLevel(); got != tt.want {
+				t.Errorf("GPUCCLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateMaxTierLimitedGPUCC(t *testing.T) {
+	// Limited GPU CC ranks below full GPU CC but above Standard
+	cap := &HardwareCapability{
+		GPUCCSupported: true,
+		GPUCCLimited:   true,
+		GPUCCEnabled:   true,
+		NVTrustAvail:   true,
+	}
+
+	tier := calculateMaxTier(cap)
+	if tier != Tier2ConfidentialVM {
+		t.Errorf("limited GPU CC should map to Tier2, got %v", tier)
+	}
+}
+
 func TestCalculateMaxTierTier2OverTier3(t *testing.T) {
 	// Test that Tier2 takes priority over Tier3 when both available
 	cap := &HardwareCapability{
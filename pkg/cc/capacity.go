@@ -0,0 +1,47 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrComputeClaimExceedsCapacity is returned when a provider claims more
+// compute for a task than its benchmarked throughput accounts for
+var ErrComputeClaimExceedsCapacity = errors.New("compute units exceed benchmarked capacity")
+
+// ComputeClaimTolerance is how far a compute-unit claim may exceed the
+// benchmark-derived estimate, allowing for prompt processing and load
+const ComputeClaimTolerance = 2.0
+
+// ExpectedComputeUnits estimates the compute units (GPU-seconds) needed to
+// generate tokens at the provider's benchmarked throughput. It returns
+// false when the provider has no capacity figure.
+func (p *AIProvider) ExpectedComputeUnits(tokens uint64) (uint64, bool) {
+	if p.CapacityTPS <= 0 {
+		return 0, false
+	}
+	return uint64(math.Ceil(float64(tokens) / p.CapacityTPS)), true
+}
+
+// ValidateComputeClaim sanity-checks a task's claimed compute units against
+// the tokens it produced and the provider's benchmarked capacity. Claims
+// from providers without a capacity figure are accepted.
+func (pool *AIRewardPool) ValidateComputeClaim(provider *AIProvider, tokens, computeUnits uint64) error {
+	expected, ok := provider.ExpectedComputeUnits(tokens)
+	if !ok {
+		return nil
+	}
+	limit := uint64(math.Ceil(float64(expected) * ComputeClaimTolerance))
+	if limit == 0 {
+		limit = 1
+	}
+	if computeUnits > limit {
+		return fmt.Errorf("%w: claimed %d, at most %d for %d tokens at %.1f tokens/sec",
+			ErrComputeClaimExceedsCapacity, computeUnits, limit, tokens, provider.CapacityTPS)
+	}
+	return nil
+}
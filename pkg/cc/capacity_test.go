@@ -0,0 +1,50 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateComputeClaim(t *testing.T) {
+	pool := NewAIRewardPool(time.Hour)
+
+	tests := []struct {
+		name         string
+		capacity     float64
+		tokens       uint64
+		computeUnits uint64
+		wantErr      bool
+	}{
+		{"no benchmark", 0, 100, 1_000_000, false},
+		{"within estimate", 50, 1000, 20, false},
+		{"within tolerance", 50, 1000, 40, false},
+		{"exceeds tolerance", 50, 1000, 41, true},
+		{"tiny task", 1000, 10, 1, false},
+		{"tiny task overclaimed", 1000, 10, 5, true},
+	}
+	for _, tt := range tests {
+		p := &AIProvider{ProviderID: "p", CapacityTPS: tt.capacity}
+		err := pool.ValidateComputeClaim(p, tt.tokens, tt.computeUnits)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateComputeClaim() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrComputeClaimExceedsCapacity) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, ErrComputeClaimExceedsCapacity)
+		}
+	}
+}
+
+func TestExpectedComputeUnits(t *testing.T) {
+	p := &AIProvider{}
+	if _, ok := p.ExpectedComputeUnits(100); ok {
+		t.Error("ExpectedComputeUnits() without capacity should report false")
+	}
+	p.CapacityTPS = 40
+	if got, ok := p.ExpectedComputeUnits(100); !ok || got != 3 {
+		t.Errorf("ExpectedComputeUnits(100) = %d, %v, want 3, true", got, ok)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import "fmt"
+
+// ValidateClaim checks that a capability reported by a remote provider
+// supports the MaxTier it claims. The achievable tier is recomputed with
+// the same rules as detection, and for NVIDIA GPUs the CC support flags are
+// derived from the GPU model rather than trusted as reported. Claiming a
+// better tier than the hardware allows returns ErrHardwareNotSupported.
+func (c *HardwareCapability) ValidateClaim() error {
+	if c.MaxTier < Tier1GPUNativeCC || c.MaxTier > Tier4Standard {
+		return fmt.Errorf("%w: max_tier %d", ErrInvalidTier, c.MaxTier)
+	}
+	if supported := c.SupportedMaxTier(); !supported.MeetsTierRequirement(c.MaxTier) {
+		return fmt.Errorf("%w: claimed tier %d, %s hardware supports at most tier %d",
+			ErrHardwareNotSupported, c.MaxTier, c.describe(), supported)
+	}
+	return nil
+}
+
+// SupportedMaxTier returns the best tier the reported hardware can reach,
+// ignoring the claimed MaxTier and any GPU CC support the GPU model lacks
+func (c *HardwareCapability) SupportedMaxTier() CCTier {
+	check := *c
+	check.GPUCCSupported = false
+	check.GPUCCLimited = false
+	if check.GPUVendor == VendorNVIDIA {
+		detectNVIDIACCCapabilitiesByModel(&check)
+	}
+	return calculateMaxTier(&check)
+}
+
+// describe names the reported hardware for error messages
+func (c *HardwareCapability) describe() string {
+	if c.GPUModel != "" {
+		return fmt.Sprintf("%q", c.GPUModel)
+	}
+	if c.CPUModel != "" {
+		return fmt.Sprintf("%q", c.CPUModel)
+	}
+	return "reported"
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateClaim(t *testing.T) {
+	h100CC := HardwareCapability{
+		GPUVendor: VendorNVIDIA, GPUModel: "NVIDIA H100 80GB HBM3",
+		GPUCCSupported: true, GPUCCEnabled: true, GPUCCMode: GPUCCModeOn, NVTrustAvail: true,
+	}
+	tests := []struct {
+		name string
+		cap  HardwareCapability
+		tier CCTier
+		want error
+	}{
+		{"H100 with CC claims tier 1", h100CC, Tier1GPUNativeCC, nil},
+		{"H100 with CC under-claims", h100CC, Tier4Standard, nil},
+		{"H100 without nvtrust claims tier 1", HardwareCapability{
+			GPUVendor: VendorNVIDIA, GPUModel: "H100", GPUCCSupported: true, GPUCCEnabled: true,
+		}, Tier1GPUNativeCC, ErrHardwareNotSupported},
+		{"RTX 4090 reporting CC support claims tier 1", HardwareCapability{
+			GPUVendor: VendorNVIDIA, GPUModel: "NVIDIA GeForce RTX 4090",
+			GPUCCSupported: true, GPUCCEnabled: true, NVTrustAvail: true,
+		}, Tier1GPUNativeCC, ErrHardwareNotSupported},
+		{"A100 with CC claims tier 1", HardwareCapability{
+			GPUVendor: VendorNVIDIA, GPUModel: "A100", GPUCCSupported: true, GPUCCEnabled: true, NVTrustAvail: true,
+		}, Tier1GPUNativeCC, ErrHardwareNotSupported},
+		{"A100 with CC claims tier 2", HardwareCapability{
+			GPUVendor: VendorNVIDIA, GPUModel: "A100", GPUCCSupported: true, GPUCCEnabled: true, NVTrustAvail: true,
+		}, Tier2ConfidentialVM, nil},
+		{"confidential VM claims tier 2", HardwareCapability{
+			CPUTEEType: TEESEVSNP, CPUTEEActive: true,
+		}, Tier2ConfidentialVM, nil},
+		{"inactive TEE claims tier 2", HardwareCapability{
+			CPUTEEType: TEETDX,
+		}, Tier2ConfidentialVM, ErrHardwareNotSupported},
+		{"plain hardware claims tier 4", HardwareCapability{}, Tier4Standard, nil},
+		{"unset tier", HardwareCapability{}, TierUnknown, ErrInvalidTier},
+		{"out of range tier", HardwareCapability{}, CCTier(9), ErrInvalidTier},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cap := tt.cap
+			cap.MaxTier = tt.tier
+			if err := cap.ValidateClaim(); !errors.Is(err, tt.want) {
+				t.Errorf("ValidateClaim() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidComputeCapability      = errors.New("invalid compute capability")
+	ErrInsufficientComputeCapability = errors.New("compute capability below required minimum")
+)
+
+// ComputeCapability identifies a GPU's compute generation: an NVIDIA
+// major.minor version such as "8.9", or a named Apple chip such as
+// "apple-m4". Only capabilities of the same family are ordered.
+type ComputeCapability string
+
+// Compute capabilities reported by detection
+const (
+	ComputeCapAmpere ComputeCapability = "8.0"
+	ComputeCapAda    ComputeCapability = "8.9"
+	ComputeCapHopper ComputeCapability = "9.0" // Also reported for Blackwell
+
+	ComputeCapAppleM1 ComputeCapability = "apple-m1"
+	ComputeCapAppleM2 ComputeCapability = "apple-m2"
+	ComputeCapAppleM3 ComputeCapability = "apple-m3"
+	ComputeCapAppleM4 ComputeCapability = "apple-m4"
+)
+
+// appleChipPrefix prefixes the generation of named Apple capabilities
+const appleChipPrefix = "apple-m"
+
+// ParseComputeCapability parses s and returns it in canonical form, e.g.
+// "9" as "9.0" and "Apple-M4" as "apple-m4"
+func ParseComputeCapability(s string) (ComputeCapability, error) {
+	vendor, major, minor, ok := ComputeCapability(s).parse()
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidComputeCapability, s)
+	}
+	if vendor == VendorApple {
+		return ComputeCapability(appleChipPrefix + strconv.Itoa(major)), nil
+	}
+	return ComputeCapability(fmt.Sprintf("%d.%d", major, minor)), nil
+}
+
+// parse splits c into its vendor and version. Apple chips have no minor
+// version.
+func (c ComputeCapability) parse() (vendor GPUVendor, major, minor int, ok bool) {
+	s := strings.ToLower(strings.TrimSpace(string(c)))
+	if gen, found := strings.CutPrefix(s, appleChipPrefix); found {
+		major, err := strconv.Atoi(gen)
+		if err != nil || major < 1 {
+			return "", 0, 0, false
+		}
+		return VendorApple, major, 0, true
+	}
+
+	majorStr, minorStr, hasMinor := strings.Cut(s, ".")
+	major, err := strconv.Atoi(majorStr)
+	if err != nil || major < 0 {
+		return "", 0, 0, false
+	}
+	if hasMinor {
+		if minor, err = strconv.Atoi(minorStr); err != nil || minor < 0 {
+			return "", 0, 0, false
+		}
+	}
+	return VendorNVIDIA, major, minor, true
+}
+
+// AtLeast reports whether c is min or newer. Any capability meets an empty
+// min; an unparsable capability, or one of a different family than min,
+// does not.
+func (c ComputeCapability) AtLeast(min ComputeCapability) bool {
+	if min == "" {
+		return true
+	}
+	vendor, major, minor, ok := c.parse()
+	minVendor, minMajor, minMinor, minOK := min.parse()
+	if !ok || !minOK || vendor != minVendor {
+		return false
+	}
+	return major > minMajor || (major == minMajor && minor >= minMinor)
+}
+
+// ComputeCapPolicy sets the minimum compute capability a provider must
+// report to be admitted at a tier or to serve a modeling level. Tiers and
+// levels without an entry have no minimum.
+type ComputeCapPolicy struct {
+	Tiers  map[CCTier]ComputeCapability        `json:"tiers,omitempty"`
+	Levels map[ModelingLevel]ComputeCapability `json:"levels,omitempty"`
+}
+
+// Validate checks that every entry is for a known tier or level and names
+// a parsable compute capability
+func (p *ComputeCapPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for tier, min := range p.Tiers {
+		if tier < Tier1GPUNativeCC || tier > Tier4Standard {
+			return fmt.Errorf("%w: tier %d", ErrInvalidTier, tier)
+		}
+		if _, err := ParseComputeCapability(string(min)); err != nil {
+			return fmt.Errorf("%s: %w", tier, err)
+		}
+	}
+	for level, min := range p.Levels {
+		if !level.Valid() {
+			return fmt.Errorf("%w: %d", ErrInvalidModelingLevel, level)
+		}
+		if _, err := ParseComputeCapability(string(min)); err != nil {
+			return fmt.Errorf("%s: %w", level, err)
+		}
+	}
+	return nil
+}
+
+// clone returns a copy of p with its capabilities in canonical form. p
+// must be valid.
+func (p *ComputeCapPolicy) clone() *ComputeCapPolicy {
+	if p == nil {
+		return nil
+	}
+	c := &ComputeCapPolicy{}
+	if p.Tiers != nil {
+		c.Tiers = make(map[CCTier]ComputeCapability, len(p.Tiers))
+		for tier, min := range p.Tiers {
+			c.Tiers[tier], _ = ParseComputeCapability(string(min))
+		}
+	}
+	if p.Levels != nil {
+		c.Levels = make(map[ModelingLevel]ComputeCapability, len(p.Levels))
+		for level, min := range p.Levels {
+			c.Levels[level], _ = ParseComputeCapability(string(min))
+		}
+	}
+	return c
+}
+
+// check returns ErrInsufficientComputeCapability if have is below the
+// minimum for tier or for any level the provider serves. A provider that
+// reports no capability fails any minimum that applies to it.
+func (p *ComputeCapPolicy) check(tier CCTier, provider *AIProvider, have ComputeCapability) error {
+	if p == nil {
+		return nil
+	}
+	if min, ok := p.Tiers[tier]; ok && !have.AtLeast(min) {
+		return insufficientComputeCap(tier.String(), min, have)
+	}
+	for level := ModelingLevelInferenceLight; level <= ModelingLevelSpecialized; level++ {
+		if min, ok := p.Levels[level]; ok && provider.SupportsLevel(level) && !have.AtLeast(min) {
+			return insufficientComputeCap(level.String(), min, have)
+		}
+	}
+	return nil
+}
+
+func insufficientComputeCap(what string, min, have ComputeCapability) error {
+	if have == "" {
+		have = "none reported"
+	}
+	return fmt.Errorf("%w: %s requires %s, have %s", ErrInsufficientComputeCapability, what, min, have)
+}
+
+// ComputeCapability returns the compute capability reported by the
+// provider's attestation, or "" if it has none
+func (p *AIProvider) ComputeCapability() ComputeCapability {
+	if p.Attestation == nil || p.Attestation.HardwareInfo == nil {
+		return ""
+	}
+	return p.Attestation.HardwareInfo.ComputeCapability
+}
+
+// SetComputeCapPolicy validates policy and applies a copy to the pool's
+// admission checks. Nil removes every minimum.
+func (pool *AIRewardPool) SetComputeCapPolicy(policy *ComputeCapPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	pool.MinComputeCap = policy.clone()
+	return nil
+}
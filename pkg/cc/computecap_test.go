@@ -0,0 +1,120 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestComputeCapabilityAtLeast(t *testing.T) {
+	tests := []struct {
+		have, min ComputeCapability
+		want      bool
+	}{
+		{"8.9", "9.0", false},
+		{"9.0", "8.9", true},
+		{"9.0", "9.0", true},
+		{"8.9", "8.10", false},
+		{"10.0", "9.0", true},
+		{"12.0", "9.0", true},
+		{"9", "9.0", true},
+		{"8.0", "8.9", false},
+		{"apple-m4", "apple-m2", true},
+		{"apple-m1", "apple-m3", false},
+		{"Apple-M3", "apple-m3", true},
+		{"apple-m4", "9.0", false},
+		{"9.0", "apple-m1", false},
+		{"", "8.0", false},
+		{"sm_90", "8.0", false},
+		{"", "", true},
+		{"8.0", "", true},
+	}
+	for _, tt := range tests {
+		if got := tt.have.AtLeast(tt.min); got != tt.want {
+			t.Errorf("%q.AtLeast(%q) = %v, want %v", tt.have, tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestParseComputeCapability(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ComputeCapability
+		wantErr bool
+	}{
+		{"9.0", "9.0", false},
+		{" 9 ", "9.0", false},
+		{"8.9", "8.9", false},
+		{"Apple-M4", "apple-m4", false},
+		{"apple-m0", "", true},
+		{"apple", "", true},
+		{"9.x", "", true},
+		{"-1.0", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseComputeCapability(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseComputeCapability(%q) = %q, %v, want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidComputeCapability) {
+			t.Errorf("ParseComputeCapability(%q) error = %v, want ErrInvalidComputeCapability", tt.in, err)
+		}
+	}
+}
+
+func TestComputeCapPolicy(t *testing.T) {
+	now := time.Now()
+	provider := func(tier CCTier, level ModelingLevel, cc ComputeCapability) *AIProvider {
+		return &AIProvider{
+			ProviderID: "p",
+			Attestation: &TierAttestation{
+				Tier:         tier,
+				Method:       acceptedMethod(tier),
+				IssuedAt:     now.Add(-time.Hour),
+				ExpiresAt:    now.Add(time.Hour),
+				HardwareInfo: &HardwareInfo{ComputeCapability: cc},
+			},
+			MaxModelingLevel: level,
+			StakeLUX:         100_000,
+		}
+	}
+
+	pool := NewAIRewardPool(time.Hour)
+	if err := pool.SetComputeCapPolicy(&ComputeCapPolicy{Tiers: map[CCTier]ComputeCapability{Tier1GPUNativeCC: "banana"}}); !errors.Is(err, ErrInvalidComputeCapability) {
+		t.Errorf("SetComputeCapPolicy(invalid) = %v, want ErrInvalidComputeCapability", err)
+	}
+	err := pool.SetComputeCapPolicy(&ComputeCapPolicy{
+		Tiers:  map[CCTier]ComputeCapability{Tier1GPUNativeCC: "9", Tier3DeviceTEE: "apple-m2"},
+		Levels: map[ModelingLevel]ComputeCapability{ModelingLevelInferenceHeavy: "8.9"},
+	})
+	if err != nil {
+		t.Fatalf("SetComputeCapPolicy() = %v", err)
+	}
+	if got := pool.MinComputeCap.Tiers[Tier1GPUNativeCC]; got != ComputeCapHopper {
+		t.Errorf("stored Tier1 minimum = %q, want canonical %q", got, ComputeCapHopper)
+	}
+
+	tests := []struct {
+		name     string
+		provider *AIProvider
+		want     error
+	}{
+		{"tier minimum met", provider(Tier1GPUNativeCC, ModelingLevelInferenceLight, "9.0"), nil},
+		{"tier minimum missed", provider(Tier1GPUNativeCC, ModelingLevelInferenceLight, "8.9"), ErrInsufficientComputeCapability},
+		{"not reported", provider(Tier1GPUNativeCC, ModelingLevelInferenceLight, ""), ErrInsufficientComputeCapability},
+		{"apple chip met", provider(Tier3DeviceTEE, ModelingLevelInferenceLight, ComputeCapAppleM4), nil},
+		{"apple chip missed", provider(Tier3DeviceTEE, ModelingLevelInferenceLight, ComputeCapAppleM1), ErrInsufficientComputeCapability},
+		{"no tier minimum", provider(Tier2ConfidentialVM, ModelingLevelInferenceStandard, ""), nil},
+		{"level minimum missed", provider(Tier2ConfidentialVM, ModelingLevelInferenceHeavy, ComputeCapAmpere), ErrInsufficientComputeCapability},
+		{"level minimum met", provider(Tier2ConfidentialVM, ModelingLevelInferenceHeavy, ComputeCapAda), nil},
+	}
+	for _, tt := range tests {
+		if err := pool.ValidateProvider(tt.provider); !errors.Is(err, tt.want) {
+			t.Errorf("%s: ValidateProvider() = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
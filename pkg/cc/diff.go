@@ -0,0 +1,95 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import "fmt"
+
+// CapabilityChange is a single field that differs between two capability
+// snapshots. Field is the field's JSON name; values are formatted for display.
+type CapabilityChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// String formats the change as "field: old -> new"
+func (c CapabilityChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Field, c.Old, c.New)
+}
+
+// CapabilityChanges is the result of Diff
+type CapabilityChanges []CapabilityChange
+
+// TierChanged reports whether the maximum achievable tier changed
+func (c CapabilityChanges) TierChanged() bool {
+	return c.Changed("max_tier")
+}
+
+// Changed reports whether the named field changed
+func (c CapabilityChanges) Changed(field string) bool {
+	for _, change := range c {
+		if change.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// capabilityFields lists the fields compared by Diff, in display order.
+// Keep in sync with HardwareCapability.
+var capabilityFields = []struct {
+	name string
+	get  func(c *HardwareCapability) interface{}
+}{
+	{"gpu_vendor", func(c *HardwareCapability) interface{} { return c.GPUVendor }},
+	{"gpu_model", func(c *HardwareCapability) interface{} { return c.GPUModel }},
+	{"gpu_serial", func(c *HardwareCapability) interface{} { return c.GPUSerial }},
+	{"gpu_memory_mb", func(c *HardwareCapability) interface{} { return c.GPUMemoryMB }},
+	{"gpu_driver_version", func(c *HardwareCapability) interface{} { return c.GPUDriverVer }},
+	{"compute_capability", func(c *HardwareCapability) interface{} { return c.ComputeCap }},
+	{"gpu_cc_supported", func(c *HardwareCapability) interface{} { return c.GPUCCSupported }},
+	{"gpu_cc_limited", func(c *HardwareCapability) interface{} { return c.GPUCCLimited }},
+	{"gpu_cc_enabled", func(c *HardwareCapability) interface{} { return c.GPUCCEnabled }},
+	{"gpu_cc_mode", func(c *HardwareCapability) interface{} { return c.GPUCCMode }},
+	{"nvtrust_available", func(c *HardwareCapability) interface{} { return c.NVTrustAvail }},
+	{"tee_io_supported", func(c *HardwareCapability) interface{} { return c.TEEIOSupported }},
+	{"mig_supported", func(c *HardwareCapability) interface{} { return c.MIGSupported }},
+	{"fabric", func(c *HardwareCapability) interface{} { return c.Fabric.String() }},
+	{"ecc", func(c *HardwareCapability) interface{} { return c.ECC.String() }},
+	{"cpu_vendor", func(c *HardwareCapability) interface{} { return c.CPUVendor }},
+	{"cpu_model", func(c *HardwareCapability) interface{} { return c.CPUModel }},
+	{"cpu_tee_type", func(c *HardwareCapability) interface{} { return c.CPUTEEType }},
+	{"cpu_tee_active", func(c *HardwareCapability) interface{} { return c.CPUTEEActive }},
+	{"cpu_tee_host_capable", func(c *HardwareCapability) interface{} { return c.CPUTEEHostCapable }},
+	{"cpu_tee_tools_available", func(c *HardwareCapability) interface{} { return c.CPUTEEToolsAvail }},
+	{"device_tee_type", func(c *HardwareCapability) interface{} { return c.DeviceTEEType }},
+	{"device_tee_enabled", func(c *HardwareCapability) interface{} { return c.DeviceTEEEnabled }},
+	{"npu_model", func(c *HardwareCapability) interface{} { return c.NPUModel }},
+	{"max_tier", func(c *HardwareCapability) interface{} { return c.MaxTier }},
+}
+
+// Diff returns the field-level changes from old to new, e.g. after enabling
+// GPU CC with `nvidia-smi -cc 1` and re-detecting, or across a driver
+// upgrade. A nil snapshot is treated as an empty capability.
+func Diff(old, new *HardwareCapability) CapabilityChanges {
+	if old == nil {
+		old = &HardwareCapability{}
+	}
+	if new == nil {
+		new = &HardwareCapability{}
+	}
+
+	var changes CapabilityChanges
+	for _, f := range capabilityFields {
+		o, n := f.get(old), f.get(new)
+		if o != n {
+			changes = append(changes, CapabilityChange{
+				Field: f.name,
+				Old:   fmt.Sprint(o),
+				New:   fmt.Sprint(n),
+			})
+		}
+	}
+	return changes
+}
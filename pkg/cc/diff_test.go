@@ -0,0 +1,78 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := &HardwareCapability{
+		GPUVendor:      VendorNVIDIA,
+		GPUModel:       "NVIDIA H100 80GB HBM3",
+		GPUDriverVer:   "550.54",
+		GPUCCSupported: true,
+		NVTrustAvail:   true,
+		MaxTier:        Tier4Standard,
+	}
+	after := *before
+	after.GPUCCEnabled = true
+	after.GPUDriverVer = "570.86"
+	after.MaxTier = Tier1GPUNativeCC
+
+	changes := Diff(before, &after)
+	want := []CapabilityChange{
+		{"gpu_driver_version", "550.54", "570.86"},
+		{"gpu_cc_enabled", "false", "true"},
+		{"max_tier", Tier4Standard.String(), Tier1GPUNativeCC.String()},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Diff() = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change[%d] = %v, want %v", i, changes[i], want[i])
+		}
+	}
+	if !changes.TierChanged() {
+		t.Error("TierChanged() = false, want true")
+	}
+	if changes.Changed("gpu_model") {
+		t.Error("Changed(gpu_model) = true, want false")
+	}
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	c := &HardwareCapability{GPUVendor: VendorNVIDIA, MaxTier: Tier2ConfidentialVM}
+	changes := Diff(c, c)
+	if len(changes) != 0 {
+		t.Errorf("Diff(c, c) = %v, want no changes", changes)
+	}
+	if changes.TierChanged() {
+		t.Error("TierChanged() = true for identical snapshots")
+	}
+}
+
+func TestDiffNil(t *testing.T) {
+	changes := Diff(nil, &HardwareCapability{GPUModel: "NVIDIA B200"})
+	if len(changes) != 1 || changes[0].Field != "gpu_model" || changes[0].New != "NVIDIA B200" {
+		t.Errorf("Diff(nil, c) = %v, want gpu_model change", changes)
+	}
+}
+
+func TestCapabilityChangeString(t *testing.T) {
+	c := CapabilityChange{Field: "gpu_cc_enabled", Old: "false", New: "true"}
+	if got := c.String(); got != "gpu_cc_enabled: false -> true" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestDiffCoversAllFields(t *testing.T) {
+	typ := reflect.TypeOf(HardwareCapability{})
+	if typ.NumField() != len(capabilityFields) {
+		t.Errorf("HardwareCapability has %d fields, Diff compares %d; update capabilityFields",
+			typ.NumField(), len(capabilityFields))
+	}
+}
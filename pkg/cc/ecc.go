@@ -0,0 +1,150 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// eccPenalty is the hardware score penalty for GPUs that have reported
+	// uncorrectable ECC errors or have row remappings waiting for a reset
+	eccPenalty = 10
+
+	// MaxUncorrectableECC is the most lifetime uncorrectable ECC errors a
+	// GPU may report before its provider no longer meets the trust minimum
+	MaxUncorrectableECC = 10
+)
+
+// GPUECC is the ECC and RAS state of the host's GPUs, as reported by
+// nvidia-smi. Error counts are the most reported by any one GPU.
+type GPUECC struct {
+	Enabled             bool   `json:"enabled"`              // ECC on for every GPU that supports it
+	Corrected           uint64 `json:"corrected"`            // Lifetime corrected errors
+	Uncorrected         uint64 `json:"uncorrected"`          // Lifetime uncorrectable errors
+	UncorrectedVolatile uint64 `json:"uncorrected_volatile"` // Uncorrectable errors since the driver loaded
+	RemapPending        bool   `json:"remap_pending,omitempty"`
+	RemapFailure        bool   `json:"remap_failure,omitempty"` // Row remapping ran out of spare rows
+}
+
+// Degraded reports whether any GPU has hit an uncorrectable error or is
+// waiting for a reset to remap memory rows
+func (e *GPUECC) Degraded() bool {
+	return e != nil && (e.Uncorrected > 0 || e.UncorrectedVolatile > 0 || e.RemapPending || e.RemapFailure)
+}
+
+// Failing reports whether a GPU has more than MaxUncorrectableECC
+// uncorrectable errors or can no longer remap failing rows, making its
+// results untrustworthy
+func (e *GPUECC) Failing() bool {
+	return e != nil && (e.Uncorrected > MaxUncorrectableECC || e.RemapFailure)
+}
+
+// String formats the ECC state for display, e.g. in a capability Diff
+func (e *GPUECC) String() string {
+	if e == nil {
+		return "unsupported"
+	}
+	mode := "off"
+	if e.Enabled {
+		mode = "on"
+	}
+	s := fmt.Sprintf("%s, %d corrected, %d uncorrectable", mode, e.Corrected, e.Uncorrected)
+	if e.RemapFailure {
+		s += ", remap failed"
+	} else if e.RemapPending {
+		s += ", remap pending"
+	}
+	return s
+}
+
+// detectECCWithDeps queries the ECC mode, error counts and row remapping
+// state of every GPU. It returns nil if the query fails or no GPU supports
+// ECC.
+func detectECCWithDeps(cmdRunner CommandRunner) *GPUECC {
+	output, err := cmdRunner.Run("nvidia-smi",
+		"--query-gpu=ecc.mode.current,ecc.errors.corrected.aggregate.total,ecc.errors.uncorrected.aggregate.total,ecc.errors.uncorrected.volatile.total",
+		"--format=csv,noheader,nounits")
+	if err != nil {
+		return nil
+	}
+	ecc := parseECCQuery(string(output))
+	if ecc == nil {
+		return nil
+	}
+
+	// GPUs before Ampere retire pages rather than remap rows
+	if output, err := cmdRunner.Run("nvidia-smi", "--query-remapped-rows=remapped_rows.pending,remapped_rows.failure", "--format=csv,noheader"); err == nil {
+		ecc.RemapPending, ecc.RemapFailure = parseRemappedRows(string(output))
+	}
+	return ecc
+}
+
+// parseECCQuery parses one line per GPU of
+//
+//	Enabled, 12, 0, 0
+//
+// (mode, aggregate corrected, aggregate uncorrected, volatile
+// uncorrected). GPUs without ECC report [N/A] and are skipped.
+func parseECCQuery(output string) *GPUECC {
+	var ecc *GPUECC
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) != 4 {
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		var enabled bool
+		switch fields[0] {
+		case "Enabled":
+			enabled = true
+		case "Disabled":
+		default:
+			continue // [N/A] or not an ECC query
+		}
+		if ecc == nil {
+			ecc = &GPUECC{Enabled: true}
+		}
+		ecc.Enabled = ecc.Enabled && enabled
+		ecc.Corrected = max(ecc.Corrected, eccCount(fields[1]))
+		ecc.Uncorrected = max(ecc.Uncorrected, eccCount(fields[2]))
+		ecc.UncorrectedVolatile = max(ecc.UncorrectedVolatile, eccCount(fields[3]))
+	}
+	return ecc
+}
+
+// eccCount parses an error counter, counting [N/A] as zero
+func eccCount(s string) uint64 {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// parseRemappedRows parses one "pending, failure" line per GPU and
+// reports whether any GPU has a remapping pending or has failed one
+func parseRemappedRows(output string) (pending, failure bool) {
+	for _, line := range strings.Split(output, "\n") {
+		p, f, ok := strings.Cut(line, ",")
+		if !ok {
+			continue
+		}
+		pending = pending || rasFlag(p)
+		failure = failure || rasFlag(f)
+	}
+	return pending, failure
+}
+
+func rasFlag(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "yes", "1", "true":
+		return true
+	}
+	return false
+}
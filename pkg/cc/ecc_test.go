@@ -0,0 +1,117 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseECCQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *GPUECC
+	}{
+		{
+			name:   "healthy H100s",
+			output: "Enabled, 0, 0, 0\nEnabled, 3, 0, 0\n",
+			want:   &GPUECC{Enabled: true, Corrected: 3},
+		},
+		{
+			name:   "one GPU with uncorrectable errors",
+			output: "Enabled, 120, 0, 0\nEnabled, 41, 12, 2\n",
+			want:   &GPUECC{Enabled: true, Corrected: 120, Uncorrected: 12, UncorrectedVolatile: 2},
+		},
+		{
+			name:   "ECC disabled on one GPU",
+			output: "Enabled, 0, 0, 0\nDisabled, [N/A], [N/A], [N/A]\n",
+			want:   &GPUECC{},
+		},
+		{
+			name:   "consumer GPU",
+			output: "[N/A], [N/A], [N/A], [N/A]\n",
+			want:   nil,
+		},
+		{
+			name:   "not an ECC query",
+			output: "NVIDIA H100 80GB HBM3, 81559, 550.54.15, 1652222014738\n",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseECCQuery(tt.output)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("parseECCQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRemappedRows(t *testing.T) {
+	tests := []struct {
+		output           string
+		pending, failure bool
+	}{
+		{"No, No\nNo, No\n", false, false},
+		{"No, No\nYes, No\n", true, false},
+		{"0, 1\n", false, true},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		if pending, failure := parseRemappedRows(tt.output); pending != tt.pending || failure != tt.failure {
+			t.Errorf("parseRemappedRows(%q) = %v, %v, want %v, %v", tt.output, pending, failure, tt.pending, tt.failure)
+		}
+	}
+}
+
+func TestDetectECC(t *testing.T) {
+	cmdRunner := NewMockCommandRunner()
+	cmdRunner.SetOutput("nvidia-smi", []byte("Enabled, 5, 1, 0\n"))
+	if e := detectECCWithDeps(cmdRunner); e == nil || !e.Enabled || e.Uncorrected != 1 || !e.Degraded() || e.Failing() {
+		t.Errorf("detectECCWithDeps() = %v, want enabled with 1 uncorrectable error", e)
+	}
+
+	cmdRunner.SetError("nvidia-smi", errors.New("no devices"))
+	if e := detectECCWithDeps(cmdRunner); e != nil {
+		t.Errorf("detectECCWithDeps() after failure = %v, want nil", e)
+	}
+}
+
+func TestECCScoring(t *testing.T) {
+	score := func(ecc *GPUECC) *TrustScoreResult {
+		return CalculateTrustScore(&TrustScoreInput{
+			Tier:                 Tier1GPUNativeCC,
+			GPUGeneration:        9,
+			HardwareCapabilities: &HardwareCapability{ECC: ecc},
+		})
+	}
+	healthy := score(&GPUECC{Enabled: true, Corrected: 50})
+
+	tests := []struct {
+		name     string
+		ecc      *GPUECC
+		penalty  uint8
+		eligible bool
+	}{
+		{"no ECC support", nil, 0, true},
+		{"corrected only", &GPUECC{Enabled: true, Corrected: 5000}, 0, true},
+		{"uncorrectable", &GPUECC{Enabled: true, Uncorrected: 1}, eccPenalty, true},
+		{"remap pending", &GPUECC{Enabled: true, RemapPending: true}, eccPenalty, true},
+		{"over threshold", &GPUECC{Enabled: true, Uncorrected: MaxUncorrectableECC + 1}, eccPenalty, false},
+		{"remap failure", &GPUECC{Enabled: true, RemapFailure: true}, eccPenalty, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := score(tt.ecc)
+			if penalty := healthy.HardwareScore - got.HardwareScore; penalty != tt.penalty {
+				t.Errorf("hardware score penalty = %d, want %d", penalty, tt.penalty)
+			}
+			if got.MeetsMinimum != tt.eligible {
+				t.Errorf("MeetsMinimum = %v, want %v (warnings %q)", got.MeetsMinimum, tt.eligible, got.Warnings)
+			}
+		})
+	}
+}
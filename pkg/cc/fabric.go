@@ -0,0 +1,103 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fabricBonus is the hardware score bonus for a coherent multi-GPU fabric
+// at training modeling levels
+const fabricBonus = 3
+
+// GPUFabric describes the NVLink/NVSwitch fabric joining the host's GPUs,
+// as reported by `nvidia-smi nvlink --status`
+type GPUFabric struct {
+	Links        int     `json:"links"`         // Active NVLink links per GPU (fewest on any GPU)
+	BandwidthGBs float64 `json:"bandwidth_gbs"` // Aggregate link bandwidth per GPU in GB/s (lowest on any GPU)
+	DomainSize   int     `json:"domain_size"`   // GPUs with at least one active link
+}
+
+// Coherent reports whether the fabric joins two or more GPUs, as opposed to
+// isolated GPUs that only share PCIe
+func (f *GPUFabric) Coherent() bool {
+	return f != nil && f.Links > 0 && f.DomainSize >= 2
+}
+
+// String formats the fabric for display, e.g. in a capability Diff
+func (f *GPUFabric) String() string {
+	if f == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d GPUs, %d links, %g GB/s", f.DomainSize, f.Links, f.BandwidthGBs)
+}
+
+// detectNVLinkWithDeps queries the NVLink status of every GPU. It returns
+// nil if the query fails or no GPU has an active link.
+func detectNVLinkWithDeps(cmdRunner CommandRunner) *GPUFabric {
+	output, err := cmdRunner.Run("nvidia-smi", "nvlink", "--status")
+	if err != nil {
+		return nil
+	}
+	return parseNVLinkStatus(string(output))
+}
+
+// parseNVLinkStatus parses `nvidia-smi nvlink --status` output:
+//
+//	GPU 0: NVIDIA H100 80GB HBM3 (UUID: GPU-...)
+//		 Link 0: 26.562 GB/s
+//		 Link 1: <inactive>
+//
+// GPUs without an active link are left out of the domain, so eight
+// PCIe-only GPUs report no fabric at all.
+func parseNVLinkStatus(output string) *GPUFabric {
+	type gpuLinks struct {
+		links     int
+		bandwidth float64
+	}
+	var gpus []*gpuLinks
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "GPU "):
+			gpus = append(gpus, &gpuLinks{})
+		case strings.HasPrefix(line, "Link ") && len(gpus) > 0:
+			_, speed, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			fields := strings.Fields(speed)
+			if len(fields) != 2 || fields[1] != "GB/s" {
+				continue // <inactive>
+			}
+			gbs, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil || gbs <= 0 {
+				continue
+			}
+			g := gpus[len(gpus)-1]
+			g.links++
+			g.bandwidth += gbs
+		}
+	}
+
+	var fabric *GPUFabric
+	for _, g := range gpus {
+		if g.links == 0 {
+			continue
+		}
+		if fabric == nil {
+			fabric = &GPUFabric{Links: g.links, BandwidthGBs: g.bandwidth}
+		}
+		if g.links < fabric.Links {
+			fabric.Links = g.links
+		}
+		if g.bandwidth < fabric.BandwidthGBs {
+			fabric.BandwidthGBs = g.bandwidth
+		}
+		fabric.DomainSize++
+	}
+	return fabric
+}
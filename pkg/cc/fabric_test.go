@@ -0,0 +1,134 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// nvlinkStatus builds `nvidia-smi nvlink --status` output for gpus GPUs
+// with links links each, active at speed ("" for <inactive>)
+func nvlinkStatus(model string, gpus, links int, speed string) string {
+	var b strings.Builder
+	for g := 0; g < gpus; g++ {
+		fmt.Fprintf(&b, "GPU %d: %s (UUID: GPU-%08d-0000-0000-0000-000000000000)\n", g, model, g)
+		for l := 0; l < links; l++ {
+			if speed == "" {
+				fmt.Fprintf(&b, "\t Link %d: <inactive>\n", l)
+			} else {
+				fmt.Fprintf(&b, "\t Link %d: %s GB/s\n", l, speed)
+			}
+		}
+	}
+	return b.String()
+}
+
+func TestParseNVLinkStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		want     *GPUFabric
+		coherent bool
+	}{
+		{
+			name:     "HGX H100 8-GPU",
+			output:   nvlinkStatus("NVIDIA H100 80GB HBM3", 8, 18, "26.562"),
+			want:     &GPUFabric{Links: 18, BandwidthGBs: 18 * 26.562, DomainSize: 8},
+			coherent: true,
+		},
+		{
+			name:     "GB200 NVL72 compute tray",
+			output:   nvlinkStatus("NVIDIA GB200", 4, 18, "50"),
+			want:     &GPUFabric{Links: 18, BandwidthGBs: 900, DomainSize: 4},
+			coherent: true,
+		},
+		{
+			name:   "eight PCIe GPUs",
+			output: nvlinkStatus("NVIDIA L40S", 8, 4, ""),
+			want:   nil,
+		},
+		{
+			name:   "GPUs without links listed",
+			output: nvlinkStatus("NVIDIA RTX 4090", 8, 0, ""),
+			want:   nil,
+		},
+		{
+			name:     "single GPU with an active link",
+			output:   nvlinkStatus("NVIDIA H100 80GB HBM3", 1, 2, "26.562"),
+			want:     &GPUFabric{Links: 2, BandwidthGBs: 2 * 26.562, DomainSize: 1},
+			coherent: false,
+		},
+		{
+			name: "degraded link",
+			output: "GPU 0: NVIDIA A100-SXM4-80GB (UUID: GPU-0)\n\t Link 0: 25 GB/s\n\t Link 1: 25 GB/s\n" +
+				"GPU 1: NVIDIA A100-SXM4-80GB (UUID: GPU-1)\n\t Link 0: 25 GB/s\n\t Link 1: <inactive>\n" +
+				"GPU 2: NVIDIA A100-SXM4-80GB (UUID: GPU-2)\n\t Link 0: <inactive>\n\t Link 1: <inactive>\n",
+			want:     &GPUFabric{Links: 1, BandwidthGBs: 25, DomainSize: 2},
+			coherent: true,
+		},
+		{
+			name:   "empty",
+			output: "",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseNVLinkStatus(tt.output)
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("parseNVLinkStatus() = %v, want %v", got, tt.want)
+			}
+			if got != nil {
+				if got.Links != tt.want.Links || got.DomainSize != tt.want.DomainSize ||
+					fmt.Sprintf("%.3f", got.BandwidthGBs) != fmt.Sprintf("%.3f", tt.want.BandwidthGBs) {
+					t.Errorf("parseNVLinkStatus() = %v, want %v", got, tt.want)
+				}
+			}
+			if got.Coherent() != tt.coherent {
+				t.Errorf("Coherent() = %v, want %v", got.Coherent(), tt.coherent)
+			}
+		})
+	}
+}
+
+func TestDetectNVLink(t *testing.T) {
+	cmdRunner := NewMockCommandRunner()
+	cmdRunner.SetOutput("nvidia-smi", []byte(nvlinkStatus("NVIDIA B200", 8, 18, "50")))
+	if f := detectNVLinkWithDeps(cmdRunner); !f.Coherent() || f.DomainSize != 8 {
+		t.Errorf("detectNVLinkWithDeps() = %v, want 8-GPU fabric", f)
+	}
+
+	cmdRunner.SetError("nvidia-smi", errors.New("nvlink query unsupported"))
+	if f := detectNVLinkWithDeps(cmdRunner); f != nil {
+		t.Errorf("detectNVLinkWithDeps() after failure = %v, want nil", f)
+	}
+}
+
+func TestFabricBonusAtTrainingLevels(t *testing.T) {
+	nvl := &HardwareCapability{Fabric: &GPUFabric{Links: 18, BandwidthGBs: 900, DomainSize: 4}}
+	isolated := &HardwareCapability{}
+	tests := []struct {
+		name  string
+		hw    *HardwareCapability
+		level ModelingLevel
+		bonus uint8
+	}{
+		{"fabric training", nvl, ModelingLevelTraining, fabricBonus},
+		{"fabric specialized", nvl, ModelingLevelSpecialized, fabricBonus},
+		{"fabric inference", nvl, ModelingLevelInferenceHeavy, 0},
+		{"isolated training", isolated, ModelingLevelTraining, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := calculateHardwareScore(&TrustScoreInput{Tier: Tier1GPUNativeCC, HardwareCapabilities: &HardwareCapability{}})
+			got := calculateHardwareScore(&TrustScoreInput{Tier: Tier1GPUNativeCC, HardwareCapabilities: tt.hw, ModelingLevel: tt.level})
+			if got-base != tt.bonus {
+				t.Errorf("fabric bonus = %d, want %d", got-base, tt.bonus)
+			}
+		})
+	}
+}
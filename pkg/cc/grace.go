@@ -0,0 +1,83 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import "time"
+
+// Tier grace period
+//
+// A provider whose attestation has just expired keeps its attested tier for
+// TierGracePeriod, so a slow re-attestation does not cut its reward weight
+// to Tier4 all at once. During the grace period the tier multiplier decays
+// linearly from the attested tier's multiplier down to Tier4's, reaching
+// Tier4 at the end of the period. A zero grace period drops to Tier4 as
+// soon as the attestation expires.
+
+// InTierGrace reports whether the provider's attestation has expired but is
+// still within its tier grace period
+func (p *AIProvider) InTierGrace() bool {
+	_, ok := p.graceRemaining(time.Now())
+	return ok
+}
+
+// TierMultiplier returns the reward multiplier of the provider's effective
+// tier, decayed toward Tier4 while in the tier grace period
+func (p *AIProvider) TierMultiplier() float64 {
+	return p.tierMultiplierAt(time.Now(), CCTier.RewardMultiplier)
+}
+
+// tierMultiplierAt is TierMultiplier at now with each tier's multiplier
+// given by mult
+func (p *AIProvider) tierMultiplierAt(now time.Time, mult func(CCTier) float64) float64 {
+	floor := mult(Tier4Standard)
+	if p.Attestation != nil && p.Attestation.isValidAt(now) {
+		return mult(p.Attestation.Tier)
+	}
+	remaining, ok := p.graceRemaining(now)
+	if !ok {
+		return floor
+	}
+	full := mult(p.Attestation.Tier)
+	return floor + (full-floor)*remaining
+}
+
+// effectiveTierAt returns the attested tier while the attestation is valid
+// or in grace, and Tier4 otherwise
+func (p *AIProvider) effectiveTierAt(now time.Time) CCTier {
+	if p.hasCurrentAttestation(now) {
+		return p.Attestation.Tier
+	}
+	return Tier4Standard
+}
+
+// hasCurrentAttestation reports whether the provider's attestation is
+// valid or expired within the grace period
+func (p *AIProvider) hasCurrentAttestation(now time.Time) bool {
+	if p.Attestation == nil {
+		return false
+	}
+	if p.Attestation.isValidAt(now) {
+		return true
+	}
+	_, ok := p.graceRemaining(now)
+	return ok
+}
+
+// graceRemaining returns the fraction of the grace period left at now, in
+// (0, 1]. The second return value is false when the attestation is missing,
+// still valid, was never valid, or expired more than TierGracePeriod ago.
+func (p *AIProvider) graceRemaining(now time.Time) (float64, bool) {
+	a := p.Attestation
+	if p.TierGracePeriod <= 0 || a == nil || a.Tier == TierUnknown {
+		return 0, false
+	}
+	if !a.ExpiresAt.After(a.IssuedAt) || now.Before(a.ExpiresAt) {
+		return 0, false
+	}
+	elapsed := now.Sub(a.ExpiresAt)
+	if elapsed >= p.TierGracePeriod {
+		return 0, false
+	}
+	return 1 - float64(elapsed)/float64(p.TierGracePeriod), true
+}
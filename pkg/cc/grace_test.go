@@ -0,0 +1,171 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestTierGraceBoundary(t *testing.T) {
+	expiry := time.Now().Truncate(time.Second)
+	grace := time.Hour
+	provider := &AIProvider{
+		ProviderID: "grace",
+		Attestation: &TierAttestation{
+			Tier:      Tier1GPUNativeCC,
+			Method:    MethodNVTrust,
+			IssuedAt:  expiry.Add(-6 * time.Hour),
+			ExpiresAt: expiry,
+		},
+		TierGracePeriod: grace,
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		wantTier CCTier
+		wantMult float64
+	}{
+		{"before expiry", expiry.Add(-time.Nanosecond), Tier1GPUNativeCC, 1.5},
+		{"at expiry", expiry, Tier1GPUNativeCC, 1.5},
+		{"half way", expiry.Add(grace / 2), Tier1GPUNativeCC, 1.0},
+		{"just before grace ends", expiry.Add(grace - time.Nanosecond), Tier1GPUNativeCC, 0.5},
+		{"grace ends", expiry.Add(grace), Tier4Standard, 0.5},
+		{"after grace", expiry.Add(2 * grace), Tier4Standard, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := provider.effectiveTierAt(tt.now); got != tt.wantTier {
+				t.Errorf("effectiveTierAt() = %v, want %v", got, tt.wantTier)
+			}
+			if got := provider.tierMultiplierAt(tt.now, CCTier.RewardMultiplier); math.Abs(got-tt.wantMult) > 1e-6 {
+				t.Errorf("tierMultiplierAt() = %v, want %v", got, tt.wantMult)
+			}
+		})
+	}
+}
+
+func TestTierGraceDisabled(t *testing.T) {
+	now := time.Now()
+	provider := &AIProvider{
+		ProviderID: "no-grace",
+		Attestation: &TierAttestation{
+			Tier:      Tier1GPUNativeCC,
+			Method:    MethodNVTrust,
+			IssuedAt:  now.Add(-7 * time.Hour),
+			ExpiresAt: now.Add(-time.Minute),
+		},
+	}
+	if provider.InTierGrace() {
+		t.Error("InTierGrace() = true with no grace period")
+	}
+	if got := provider.EffectiveTier(); got != Tier4Standard {
+		t.Errorf("EffectiveTier() = %v, want %v", got, Tier4Standard)
+	}
+	if got := provider.TierMultiplier(); got != 0.5 {
+		t.Errorf("TierMultiplier() = %v, want 0.5", got)
+	}
+}
+
+func TestTierGraceNeverValid(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		attestation *TierAttestation
+	}{
+		{"nil attestation", nil},
+		{"unknown tier", &TierAttestation{
+			Tier:      TierUnknown,
+			IssuedAt:  now.Add(-2 * time.Hour),
+			ExpiresAt: now.Add(-time.Minute),
+		}},
+		{"expires before issued", &TierAttestation{
+			Tier:      Tier1GPUNativeCC,
+			Method:    MethodNVTrust,
+			IssuedAt:  now.Add(-time.Minute),
+			ExpiresAt: now.Add(-2 * time.Minute),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &AIProvider{Attestation: tt.attestation, TierGracePeriod: time.Hour}
+			if provider.InTierGrace() {
+				t.Error("InTierGrace() = true, want false")
+			}
+			if got := provider.EffectiveTier(); got != Tier4Standard {
+				t.Errorf("EffectiveTier() = %v, want %v", got, Tier4Standard)
+			}
+		})
+	}
+}
+
+func TestTierGraceRewards(t *testing.T) {
+	now := time.Now()
+	pool := NewAIRewardPool(time.Hour)
+	pool.TierGracePeriod = time.Hour
+	provider := &AIProvider{
+		ProviderID:       "graced",
+		MaxModelingLevel: ModelingLevelInferenceStandard,
+		StakeLUX:         100_000,
+		LastHeartbeat:    now,
+		ReputationScore:  0.5,
+		Attestation: &TierAttestation{
+			Tier:      Tier1GPUNativeCC,
+			Method:    MethodNVTrust,
+			IssuedAt:  now.Add(-6 * time.Hour),
+			ExpiresAt: now.Add(-time.Minute),
+		},
+	}
+	if err := pool.RegisterProvider(provider); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+	if provider.TierGracePeriod != time.Hour {
+		t.Fatalf("TierGracePeriod = %v, want the pool's %v", provider.TierGracePeriod, time.Hour)
+	}
+	if !provider.InTierGrace() {
+		t.Fatal("InTierGrace() = false, want true")
+	}
+
+	if ok, reason := pool.RandomMiningEligibility(provider, time.Minute); !ok {
+		t.Errorf("RandomMiningEligibility() = %v, want eligible", reason)
+	}
+
+	pool.TotalPoolLUX = big.NewInt(1e18)
+	results := pool.CalculateParticipationRewards(time.Minute)
+	if len(results) != 1 || results[0].Tier != Tier1GPUNativeCC {
+		t.Fatalf("CalculateParticipationRewards() = %+v, want one Tier1 result", results)
+	}
+
+	// The decayed task reward sits between the Tier4 and Tier1 rewards
+	valid := &AIProvider{Attestation: &TierAttestation{
+		Tier:      Tier1GPUNativeCC,
+		Method:    MethodNVTrust,
+		IssuedAt:  now.Add(-time.Hour),
+		ExpiresAt: now.Add(time.Hour),
+	}}
+	reward := func(p *AIProvider) *big.Int {
+		return pool.Clone().CalculateTaskReward(p, "task", ModelingLevelInferenceStandard, 1000).RewardLUX
+	}
+	graced, tier1, tier4 := reward(provider), reward(valid), reward(&AIProvider{})
+	if graced.Cmp(tier4) <= 0 || graced.Cmp(tier1) >= 0 {
+		t.Errorf("CalculateTaskReward() in grace = %v, want between %v and %v", graced, tier4, tier1)
+	}
+}
+
+func TestTierGracePeriodSetByPool(t *testing.T) {
+	for _, pooled := range []time.Duration{0, time.Hour} {
+		pool := NewAIRewardPool(time.Hour)
+		pool.TierGracePeriod = pooled
+		provider := &AIProvider{ProviderID: "greedy", StakeLUX: 1_000, TierGracePeriod: 365 * 24 * time.Hour}
+		if err := pool.RegisterProvider(provider); err != nil {
+			t.Fatalf("RegisterProvider() error = %v", err)
+		}
+		if provider.TierGracePeriod != pooled {
+			t.Errorf("TierGracePeriod = %v, want the pool's %v", provider.TierGracePeriod, pooled)
+		}
+	}
+}
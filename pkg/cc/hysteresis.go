@@ -0,0 +1,78 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import "time"
+
+// TierHysteresis damps tier flapping when hardware state is borderline,
+// e.g. GPU CC mode toggling or an attestation hovering at expiry. Feed it
+// the tier observed on each detection cycle and use the tier it returns
+// for rewards and scheduling. An upgrade takes effect immediately; a
+// downgrade only once it has been observed for DowngradeCycles consecutive
+// cycles and for at least DowngradeAfter. Zero disables either condition,
+// and the zero value applies downgrades immediately.
+//
+// A TierHysteresis is not safe for concurrent use.
+type TierHysteresis struct {
+	DowngradeCycles int
+	DowngradeAfter  time.Duration
+
+	current       CCTier
+	pending       CCTier // Lower tier awaiting confirmation, TierUnknown if none
+	pendingSince  time.Time
+	pendingCycles int
+}
+
+// NewTierHysteresis returns a TierHysteresis that applies a downgrade once
+// it has persisted for cycles observations and for after
+func NewTierHysteresis(cycles int, after time.Duration) *TierHysteresis {
+	return &TierHysteresis{DowngradeCycles: cycles, DowngradeAfter: after}
+}
+
+// Observe records the tier detected at now and returns the tier in effect.
+// The first observation is taken as is. TierUnknown, e.g. from a failed
+// detection, counts as Tier4Standard.
+func (h *TierHysteresis) Observe(tier CCTier, now time.Time) CCTier {
+	if tier == TierUnknown {
+		tier = Tier4Standard
+	}
+	if h.current == TierUnknown || tier.MeetsTierRequirement(h.current) {
+		// First observation, unchanged or upgrade
+		h.current = tier
+		h.clearPending()
+		return h.current
+	}
+
+	if h.pending == TierUnknown {
+		h.pendingSince = now
+	}
+	h.pending = tier // Track the latest lower tier if it keeps falling
+	h.pendingCycles++
+	if h.pendingCycles >= h.DowngradeCycles && now.Sub(h.pendingSince) >= h.DowngradeAfter {
+		h.current = tier
+		h.clearPending()
+	}
+	return h.current
+}
+
+// Tier returns the tier in effect, TierUnknown before the first observation
+func (h *TierHysteresis) Tier() CCTier {
+	return h.current
+}
+
+// PendingDowngrade returns the lower tier awaiting confirmation, when it was
+// first observed and for how many consecutive cycles. ok is false when no
+// downgrade is pending.
+func (h *TierHysteresis) PendingDowngrade() (tier CCTier, since time.Time, cycles int, ok bool) {
+	if h.pending == TierUnknown {
+		return TierUnknown, time.Time{}, 0, false
+	}
+	return h.pending, h.pendingSince, h.pendingCycles, true
+}
+
+func (h *TierHysteresis) clearPending() {
+	h.pending = TierUnknown
+	h.pendingSince = time.Time{}
+	h.pendingCycles = 0
+}
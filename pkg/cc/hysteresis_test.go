@@ -0,0 +1,108 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTierHysteresisFlappingCCMode toggles GPU CC mode between detection
+// cycles; the tier stays at Tier1 until CC stays off long enough
+func TestTierHysteresisFlappingCCMode(t *testing.T) {
+	detect := func(ccOn bool) CCTier {
+		c := &HardwareCapability{
+			GPUVendor:      VendorNVIDIA,
+			GPUModel:       "NVIDIA H100 80GB HBM3",
+			GPUCCSupported: true,
+			NVTrustAvail:   true,
+			GPUCCEnabled:   ccOn,
+		}
+		if ccOn {
+			c.GPUCCMode = GPUCCModeOn
+		}
+		return calculateMaxTier(c)
+	}
+
+	h := NewTierHysteresis(3, 2*time.Minute)
+	start := time.Now()
+	cycle := time.Minute
+	steps := []struct {
+		ccOn bool
+		want CCTier
+	}{
+		{true, Tier1GPUNativeCC},
+		{false, Tier1GPUNativeCC}, // Flap
+		{true, Tier1GPUNativeCC},
+		{false, Tier1GPUNativeCC}, // Flap again, pending restarts
+		{false, Tier1GPUNativeCC},
+		{true, Tier1GPUNativeCC},
+		{false, Tier1GPUNativeCC}, // Sustained from here
+		{false, Tier1GPUNativeCC},
+		{false, Tier4Standard},   // Third cycle, two minutes on
+		{true, Tier1GPUNativeCC}, // Upgrades apply at once
+	}
+	for i, step := range steps {
+		if got := h.Observe(detect(step.ccOn), start.Add(time.Duration(i)*cycle)); got != step.want {
+			t.Errorf("cycle %d (cc on %v): Observe() = %v, want %v", i, step.ccOn, got, step.want)
+		}
+	}
+	if _, _, _, ok := h.PendingDowngrade(); ok {
+		t.Error("PendingDowngrade() ok after upgrade, want none")
+	}
+}
+
+func TestTierHysteresisConditions(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		cycles   int
+		after    time.Duration
+		interval time.Duration
+		applied  int // Downgrade observation on which the tier drops
+	}{
+		{"immediate", 0, 0, time.Second, 1},
+		{"cycles only", 3, 0, time.Second, 3},
+		{"duration only", 0, time.Minute, 20 * time.Second, 4},
+		{"both, duration binding", 2, time.Minute, 20 * time.Second, 4},
+		{"both, cycles binding", 5, time.Minute, time.Minute, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewTierHysteresis(tt.cycles, tt.after)
+			h.Observe(Tier1GPUNativeCC, now)
+			for i := 1; i <= 10; i++ {
+				got := h.Observe(Tier2ConfidentialVM, now.Add(time.Duration(i)*tt.interval))
+				want := Tier1GPUNativeCC
+				if i >= tt.applied {
+					want = Tier2ConfidentialVM
+				}
+				if got != want {
+					t.Fatalf("observation %d: Observe() = %v, want %v", i, got, want)
+				}
+				if i == 1 && tt.applied > 1 {
+					pending, since, cycles, ok := h.PendingDowngrade()
+					if !ok || pending != Tier2ConfidentialVM || cycles != 1 || !since.Equal(now.Add(tt.interval)) {
+						t.Errorf("PendingDowngrade() = %v, %v, %d, %v", pending, since, cycles, ok)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTierHysteresisUnknownCountsAsTier4(t *testing.T) {
+	h := NewTierHysteresis(2, 0)
+	now := time.Now()
+	h.Observe(Tier2ConfidentialVM, now)
+	if got := h.Observe(TierUnknown, now); got != Tier2ConfidentialVM {
+		t.Errorf("first failed detection: Observe() = %v, want Tier2", got)
+	}
+	if got := h.Observe(TierUnknown, now); got != Tier4Standard {
+		t.Errorf("second failed detection: Observe() = %v, want Tier4", got)
+	}
+	if h.Tier() != Tier4Standard {
+		t.Errorf("Tier() = %v, want Tier4", h.Tier())
+	}
+}
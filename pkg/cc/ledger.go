@@ -0,0 +1,171 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"math/big"
+)
+
+// DefaultHistoryEpochs is how many epochs of per-provider earnings the pool
+// retains when AIRewardPool.HistoryEpochs is unset
+const DefaultHistoryEpochs = 90
+
+// ProviderEpochRecord is a provider's earnings in a single epoch
+type ProviderEpochRecord struct {
+	// Epoch is the reward epoch the record covers
+	Epoch uint64 `json:"epoch"`
+
+	// Participation is the availability reward, nil if none was earned
+	Participation *ParticipationRewardResult `json:"participation,omitempty"`
+
+	// Tasks are the task completion rewards, one per task ID
+	Tasks []*TaskRewardResult `json:"tasks,omitempty"`
+}
+
+// ProviderStatement aggregates a provider's earnings over a range of epochs
+type ProviderStatement struct {
+	// ProviderID is the provider the statement covers
+	ProviderID string `json:"provider_id"`
+
+	// FromEpoch and ToEpoch bound the statement, inclusive
+	FromEpoch uint64 `json:"from_epoch"`
+	ToEpoch   uint64 `json:"to_epoch"`
+
+	// ParticipationLUX is the sum of availability rewards (wei)
+	ParticipationLUX *big.Int `json:"participation_lux"`
+
+	// TaskLUX is the sum of task completion rewards (wei)
+	TaskLUX *big.Int `json:"task_lux"`
+
+	// TotalLUX is ParticipationLUX + TaskLUX (wei)
+	TotalLUX *big.Int `json:"total_lux"`
+
+	// TaskCount is the number of rewarded tasks
+	TaskCount uint64 `json:"task_count"`
+
+	// ComputeUnits is the compute consumed by rewarded tasks
+	ComputeUnits uint64 `json:"compute_units"`
+
+	// ParticipationEpochs is the number of epochs with an availability reward
+	ParticipationEpochs uint64 `json:"participation_epochs"`
+
+	// AvgWeightShare is the mean weight share over ParticipationEpochs
+	AvgWeightShare float64 `json:"avg_weight_share"`
+
+	// Epochs are the underlying per-epoch records, oldest first
+	Epochs []*ProviderEpochRecord `json:"epochs"`
+}
+
+// historyEpochs returns the retention window in epochs
+func (pool *AIRewardPool) historyEpochs() uint64 {
+	if pool.HistoryEpochs > 0 {
+		return uint64(pool.HistoryEpochs)
+	}
+	return DefaultHistoryEpochs
+}
+
+// epochRecord returns the provider's record for the current epoch, creating
+// it and pruning records outside the retention window as needed
+func (pool *AIRewardPool) epochRecord(providerID string) *ProviderEpochRecord {
+	if pool.History == nil {
+		pool.History = make(map[string][]*ProviderEpochRecord)
+	}
+	records := pool.History[providerID]
+	if n := len(records); n > 0 && records[n-1].Epoch == pool.EpochNumber {
+		return records[n-1]
+	}
+
+	rec := &ProviderEpochRecord{Epoch: pool.EpochNumber}
+	records = append(records, rec)
+	keep := 0
+	for keep < len(records) && pool.EpochNumber-records[keep].Epoch >= pool.historyEpochs() {
+		keep++
+	}
+	pool.History[providerID] = records[keep:]
+	return rec
+}
+
+// recordParticipation stores the epoch's availability rewards, replacing
+// any recorded earlier in the same epoch
+func (pool *AIRewardPool) recordParticipation(results []*ParticipationRewardResult) {
+	for _, r := range results {
+		pool.epochRecord(r.ProviderID).Participation = r
+	}
+}
+
+// recordTaskReward stores a task reward in the current epoch, replacing an
+// earlier reward for the same task, and reports whether the task is new
+func (pool *AIRewardPool) recordTaskReward(r *TaskRewardResult) (added bool) {
+	rec := pool.epochRecord(r.ProviderID)
+	for i, existing := range rec.Tasks {
+		if existing.TaskID == r.TaskID {
+			rec.Tasks[i] = r
+			return false
+		}
+	}
+	rec.Tasks = append(rec.Tasks, r)
+	return true
+}
+
+// ProviderStatement aggregates a provider's retained earnings between
+// fromEpoch and toEpoch inclusive. Epochs older than the retention window
+// are no longer available.
+func (pool *AIRewardPool) ProviderStatement(providerID string, fromEpoch, toEpoch uint64) *ProviderStatement {
+	stmt := &ProviderStatement{
+		ProviderID:       providerID,
+		FromEpoch:        fromEpoch,
+		ToEpoch:          toEpoch,
+		ParticipationLUX: big.NewInt(0),
+		TaskLUX:          big.NewInt(0),
+		TotalLUX:         big.NewInt(0),
+		Epochs:           make([]*ProviderEpochRecord, 0),
+	}
+
+	var shareSum float64
+	for _, rec := range pool.History[providerID] {
+		if rec.Epoch < fromEpoch || rec.Epoch > toEpoch {
+			continue
+		}
+		stmt.Epochs = append(stmt.Epochs, rec)
+		if p := rec.Participation; p != nil {
+			if p.RewardLUX != nil {
+				stmt.ParticipationLUX.Add(stmt.ParticipationLUX, p.RewardLUX)
+			}
+			shareSum += p.WeightShare
+			stmt.ParticipationEpochs++
+		}
+		for _, task := range rec.Tasks {
+			if task.RewardLUX != nil {
+				stmt.TaskLUX.Add(stmt.TaskLUX, task.RewardLUX)
+			}
+			stmt.TaskCount++
+			stmt.ComputeUnits += task.ComputeUnits
+		}
+	}
+
+	stmt.TotalLUX.Add(stmt.ParticipationLUX, stmt.TaskLUX)
+	if stmt.ParticipationEpochs > 0 {
+		stmt.AvgWeightShare = shareSum / float64(stmt.ParticipationEpochs)
+	}
+	return stmt
+}
+
+// cloneHistory deep-copies earnings records so a cloned pool can record
+// without touching the original
+func cloneHistory(history map[string][]*ProviderEpochRecord) map[string][]*ProviderEpochRecord {
+	if history == nil {
+		return nil
+	}
+	c := make(map[string][]*ProviderEpochRecord, len(history))
+	for id, records := range history {
+		cr := make([]*ProviderEpochRecord, len(records))
+		for i, rec := range records {
+			copied := *rec
+			copied.Tasks = append([]*TaskRewardResult(nil), rec.Tasks...)
+			cr[i] = &copied
+		}
+		c[id] = cr
+	}
+	return c
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func newLedgerPool(t *testing.T) *AIRewardPool {
+	t.Helper()
+	pool := NewAIRewardPool(time.Hour)
+	now := time.Now()
+	for _, id := range []string{"a", "b"} {
+		err := pool.RegisterProvider(&AIProvider{
+			ProviderID: id,
+			Attestation: &TierAttestation{
+				Tier:      Tier2ConfidentialVM,
+				Method:    MethodSEVSNP,
+				IssuedAt:  now.Add(-time.Hour),
+				ExpiresAt: now.Add(time.Hour),
+			},
+			MaxModelingLevel: ModelingLevelInferenceStandard,
+			StakeLUX:         50_000,
+			LastHeartbeat:    now,
+			ReputationScore:  1,
+		})
+		if err != nil {
+			t.Fatalf("RegisterProvider(%s): %v", id, err)
+		}
+	}
+	return pool
+}
+
+func TestProviderStatement(t *testing.T) {
+	pool := newLedgerPool(t)
+	blockReward := big.NewInt(1e18)
+
+	for epoch := uint64(0); epoch < 3; epoch++ {
+		pool.EpochNumber = epoch
+		pool.CalculateEpochRewards(blockReward, time.Minute)
+		pool.RecordTaskReward(pool.Providers["a"], pool.CalculateTaskReward(pool.Providers["a"], "task", ModelingLevelInferenceStandard, 10))
+	}
+	// Recalculating within an epoch replaces rather than double counts
+	pool.CalculateEpochRewards(blockReward, time.Minute)
+	pool.RecordTaskReward(pool.Providers["a"], pool.CalculateTaskReward(pool.Providers["a"], "task", ModelingLevelInferenceStandard, 10))
+
+	stmt := pool.ProviderStatement("a", 1, 2)
+	if len(stmt.Epochs) != 2 || stmt.ParticipationEpochs != 2 {
+		t.Fatalf("statement covers %d epochs (%d with participation), want 2", len(stmt.Epochs), stmt.ParticipationEpochs)
+	}
+	if stmt.TaskCount != 2 || stmt.ComputeUnits != 20 {
+		t.Errorf("TaskCount = %d, ComputeUnits = %d, want 2, 20", stmt.TaskCount, stmt.ComputeUnits)
+	}
+	if stmt.AvgWeightShare != 0.5 {
+		t.Errorf("AvgWeightShare = %v, want 0.5", stmt.AvgWeightShare)
+	}
+
+	wantParticipation := new(big.Int).Mul(stmt.Epochs[0].Participation.RewardLUX, big.NewInt(2))
+	if stmt.ParticipationLUX.Cmp(wantParticipation) != 0 {
+		t.Errorf("ParticipationLUX = %s, want %s", stmt.ParticipationLUX, wantParticipation)
+	}
+	if new(big.Int).Add(stmt.ParticipationLUX, stmt.TaskLUX).Cmp(stmt.TotalLUX) != 0 {
+		t.Errorf("TotalLUX = %s, want participation + task", stmt.TotalLUX)
+	}
+
+	if b := pool.ProviderStatement("b", 0, 2); b.TaskCount != 0 || b.ParticipationEpochs != 3 {
+		t.Errorf("provider b: TaskCount = %d, ParticipationEpochs = %d, want 0, 3", b.TaskCount, b.ParticipationEpochs)
+	}
+	if none := pool.ProviderStatement("missing", 0, 10); none.TotalLUX.Sign() != 0 || len(none.Epochs) != 0 {
+		t.Errorf("unknown provider statement = %+v, want empty", none)
+	}
+}
+
+func TestProviderHistoryRetention(t *testing.T) {
+	pool := newLedgerPool(t)
+	pool.HistoryEpochs = 3
+
+	for epoch := uint64(0); epoch < 10; epoch++ {
+		pool.EpochNumber = epoch
+		pool.CalculateEpochRewards(big.NewInt(1e18), time.Minute)
+	}
+
+	records := pool.History["a"]
+	if len(records) != 3 || records[0].Epoch != 7 {
+		t.Errorf("retained %d records starting at epoch %d, want 3 starting at 7", len(records), records[0].Epoch)
+	}
+}
+
+func TestProviderHistoryPersistence(t *testing.T) {
+	pool := newLedgerPool(t)
+	pool.CalculateEpochRewards(big.NewInt(1e18), time.Minute)
+	pool.RecordTaskReward(pool.Providers["a"], pool.CalculateTaskReward(pool.Providers["a"], "task", ModelingLevelInferenceStandard, 10))
+
+	data, err := json.Marshal(pool)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var restored AIRewardPool
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	want := pool.ProviderStatement("a", 0, 0)
+	got := restored.ProviderStatement("a", 0, 0)
+	if got.TotalLUX.Cmp(want.TotalLUX) != 0 || got.TaskCount != want.TaskCount {
+		t.Errorf("restored statement = %s LUX / %d tasks, want %s / %d",
+			got.TotalLUX, got.TaskCount, want.TotalLUX, want.TaskCount)
+	}
+}
+
+func TestSimulateEpochLeavesHistory(t *testing.T) {
+	pool := newLedgerPool(t)
+	pool.CalculateEpochRewards(big.NewInt(1e18), time.Minute)
+	before := pool.ProviderStatement("a", 0, 0).TotalLUX
+
+	pool.SimulateEpoch(big.NewInt(5e18), time.Minute)
+
+	if after := pool.ProviderStatement("a", 0, 0).TotalLUX; after.Cmp(before) != 0 {
+		t.Errorf("SimulateEpoch changed recorded earnings from %s to %s", before, after)
+	}
+}
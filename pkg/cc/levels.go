@@ -0,0 +1,47 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidModelingLevel = errors.New("invalid modeling level")
+
+// Valid reports whether l is one of the defined modeling levels
+func (l ModelingLevel) Valid() bool {
+	return l >= ModelingLevelInferenceLight && l <= ModelingLevelSpecialized
+}
+
+// SupportsLevel reports whether the provider serves level. A provider that
+// opted into specific ModelingLevels serves exactly those; otherwise every
+// level up to MaxModelingLevel.
+func (p *AIProvider) SupportsLevel(level ModelingLevel) bool {
+	if !level.Valid() {
+		return false
+	}
+	if len(p.ModelingLevels) == 0 {
+		return level <= p.MaxModelingLevel
+	}
+	for _, l := range p.ModelingLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckLevelVRAM checks that level is defined and fits in vramGB decimal
+// gigabytes of GPU memory. A vramGB of 0 means unreported and skips the
+// memory check.
+func CheckLevelVRAM(level ModelingLevel, vramGB uint64) error {
+	if !level.Valid() {
+		return fmt.Errorf("%w: %d", ErrInvalidModelingLevel, level)
+	}
+	if required := level.MinVRAMGB(); vramGB > 0 && vramGB < required {
+		return fmt.Errorf("%w: %s requires %dGB, have %dGB", ErrInsufficientVRAM, level, required, vramGB)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import "testing"
+
+func TestSupportsLevel(t *testing.T) {
+	maxOnly := &AIProvider{MaxModelingLevel: ModelingLevelInferenceStandard}
+	optIn := &AIProvider{
+		MaxModelingLevel: ModelingLevelTraining,
+		ModelingLevels:   []ModelingLevel{ModelingLevelInferenceLight, ModelingLevelTraining},
+	}
+	tests := []struct {
+		provider *AIProvider
+		level    ModelingLevel
+		want     bool
+	}{
+		{maxOnly, ModelingLevelInferenceLight, true},
+		{maxOnly, ModelingLevelInferenceStandard, true},
+		{maxOnly, ModelingLevelInferenceHeavy, false},
+		{optIn, ModelingLevelInferenceLight, true},
+		{optIn, ModelingLevelInferenceStandard, false}, // Below max but not opted in
+		{optIn, ModelingLevelTraining, true},
+		{optIn, 0, false},
+	}
+	for _, tt := range tests {
+		if got := tt.provider.SupportsLevel(tt.level); got != tt.want {
+			t.Errorf("SupportsLevel(%s) with levels %v = %v, want %v", tt.level, tt.provider.ModelingLevels, got, tt.want)
+		}
+	}
+}
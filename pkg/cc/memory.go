@@ -0,0 +1,70 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import "math"
+
+// Memory units
+//
+// GPU memory appears in three units: nvidia-smi reports MiB
+// (HardwareCapability.GPUMemoryMB), attestation hardware info and tier
+// requirements use bytes (HardwareInfo.MemorySize,
+// TierRequirement.RequireMinMemory), and VRAM thresholds such as
+// ModelingLevel.MinVRAMGB use decimal gigabytes, the unit on a card's
+// label. Bytes are the canonical unit: comparisons convert to bytes, or
+// to decimal GB via the accessors below, never compare raw fields.
+// Conversions saturate at math.MaxUint64, so a hostile report cannot wrap
+// around to a small size.
+
+const (
+	// MiB is the unit of HardwareCapability.GPUMemoryMB, in bytes
+	MiB = 1 << 20
+
+	// GB is a decimal gigabyte, the unit of VRAM thresholds, in bytes
+	GB = 1_000_000_000
+)
+
+// highMemoryBytes is the GPU memory above which hardware scoring adds a
+// bonus (80GB)
+const highMemoryBytes = 80 * GB
+
+// GBToBytes converts decimal gigabytes to bytes
+func GBToBytes(gb uint64) uint64 {
+	return mulSaturating(gb, GB)
+}
+
+// mulSaturating returns n*unit, or math.MaxUint64 if it overflows
+func mulSaturating(n, unit uint64) uint64 {
+	if n > math.MaxUint64/unit {
+		return math.MaxUint64
+	}
+	return n * unit
+}
+
+// MemoryBytes returns the reported GPU memory in bytes, or 0 if unknown
+func (c *HardwareCapability) MemoryBytes() uint64 {
+	if c == nil {
+		return 0
+	}
+	return mulSaturating(c.GPUMemoryMB, MiB)
+}
+
+// MemoryGB returns the reported GPU memory in whole decimal gigabytes, so
+// an "80GB" card reporting 81559 MiB has 85GB
+func (c *HardwareCapability) MemoryGB() uint64 {
+	return c.MemoryBytes() / GB
+}
+
+// MemoryBytes returns the attested memory size in bytes, or 0 if unknown
+func (h *HardwareInfo) MemoryBytes() uint64 {
+	if h == nil {
+		return 0
+	}
+	return h.MemorySize
+}
+
+// MemoryGB returns the attested memory size in whole decimal gigabytes
+func (h *HardwareInfo) MemoryGB() uint64 {
+	return h.MemoryBytes() / GB
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMemoryUnits(t *testing.T) {
+	tests := []struct {
+		name      string
+		mib       uint64
+		wantBytes uint64
+		wantGB    uint64
+	}{
+		{"unreported", 0, 0, 0},
+		{"RTX 4090", 24564, 25_757_220_864, 25},
+		{"A100 80GB", 81920, 85_899_345_920, 85},
+		{"H100 80GB", 81559, 85_520_809_984, 85},
+		{"80000 MiB", 80000, 83_886_080_000, 83},
+		{"overflowing", math.MaxUint64 / MiB * 2, math.MaxUint64, math.MaxUint64 / GB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &HardwareCapability{GPUMemoryMB: tt.mib}
+			if got := c.MemoryBytes(); got != tt.wantBytes {
+				t.Errorf("MemoryBytes() = %d, want %d", got, tt.wantBytes)
+			}
+			if got := c.MemoryGB(); got != tt.wantGB {
+				t.Errorf("MemoryGB() = %d, want %d", got, tt.wantGB)
+			}
+
+			// The same memory attested in bytes reads back identically
+			h := &HardwareInfo{MemorySize: tt.wantBytes}
+			if h.MemoryBytes() != tt.wantBytes || h.MemoryGB() != tt.wantGB {
+				t.Errorf("HardwareInfo = %d bytes, %d GB; want %d, %d", h.MemoryBytes(), h.MemoryGB(), tt.wantBytes, tt.wantGB)
+			}
+		})
+	}
+
+	var nilCap *HardwareCapability
+	var nilInfo *HardwareInfo
+	if nilCap.MemoryBytes() != 0 || nilInfo.MemoryGB() != 0 {
+		t.Error("nil receivers should report no memory")
+	}
+	if got := GBToBytes(80); got != 80_000_000_000 {
+		t.Errorf("GBToBytes(80) = %d, want 80000000000", got)
+	}
+	if got := GBToBytes(math.MaxUint64 / 10); got != math.MaxUint64 {
+		t.Errorf("GBToBytes(overflowing) = %d, want math.MaxUint64", got)
+	}
+}
+
+// TestHighMemoryBonusThreshold pins the 80GB scoring threshold in bytes, so
+// it is not compared against a raw MiB count
+func TestHighMemoryBonusThreshold(t *testing.T) {
+	tests := []struct {
+		mib  uint64
+		want uint8
+	}{
+		{80, 5},    // 80 MiB, not 80GB
+		{40960, 5}, // A100 40GB
+		{76293, 5}, // Just under 80 * 10^9 bytes
+		{76294, 7}, // Just over
+		{81920, 7}, // A100 80GB
+	}
+	for _, tt := range tests {
+		input := TrustScoreInput{
+			Tier:                 Tier4Standard,
+			HardwareCapabilities: &HardwareCapability{GPUMemoryMB: tt.mib},
+		}
+		if got := calculateHardwareScore(&input); got != tt.want {
+			t.Errorf("calculateHardwareScore() with %d MiB = %d, want %d", tt.mib, got, tt.want)
+		}
+	}
+}
+
+func TestRequireMinMemoryBytes(t *testing.T) {
+	req := TierRequirement{MinTier: Tier4Standard, RequireMinMemory: GBToBytes(24)}
+	now := time.Now()
+	for mib, wantErr := range map[uint64]bool{24564: false, 16384: true} {
+		att := &TierAttestation{
+			Tier:         Tier4Standard,
+			IssuedAt:     now,
+			ExpiresAt:    now.Add(time.Hour),
+			HardwareInfo: &HardwareInfo{MemorySize: (&HardwareCapability{GPUMemoryMB: mib}).MemoryBytes()},
+		}
+		if err := req.IsMet(att); (err != nil) != wantErr {
+			t.Errorf("IsMet() with %d MiB = %v, want error %v", mib, err, wantErr)
+		}
+	}
+}
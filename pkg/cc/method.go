@@ -0,0 +1,60 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Attestation methods, as reported in TierAttestation.Method and
+// TrustScoreInput.AttestationMethod
+const (
+	MethodNVTrust       = "nvtrust"        // NVIDIA GPU quote, verified locally
+	MethodSEVSNP        = "sev-snp"        // AMD SEV-SNP report
+	MethodTDX           = "tdx"            // Intel TDX quote
+	MethodCCA           = "cca"            // Arm CCA realm token
+	MethodSecureEnclave = "secure-enclave" // Apple Secure Enclave
+	MethodTrustZone     = "trustzone"      // Qualcomm TrustZone/SPU
+	MethodSoftware      = "software"       // Software/stake-based
+)
+
+// ErrAttestationMethod is returned when an attestation's method cannot
+// back the tier it claims
+var ErrAttestationMethod = errors.New("attestation method not accepted for tier")
+
+// tierMethods lists the attestation methods that can back each tier.
+// Tier 4 accepts any method, so it has no entry.
+var tierMethods = map[CCTier][]string{
+	Tier1GPUNativeCC:    {MethodNVTrust},
+	Tier2ConfidentialVM: {MethodSEVSNP, MethodTDX, MethodCCA},
+	Tier3DeviceTEE:      {MethodSecureEnclave, MethodTrustZone},
+}
+
+// AttestationMethods returns the attestation methods that can back tier,
+// or nil if any method is accepted
+func (t CCTier) AttestationMethods() []string {
+	return slices.Clone(tierMethods[t])
+}
+
+// AcceptsMethod reports whether method can back an attestation of tier t
+func (t CCTier) AcceptsMethod(method string) bool {
+	methods, ok := tierMethods[t]
+	return !ok || slices.Contains(methods, method)
+}
+
+// checkMethod returns ErrAttestationMethod if the attestation's method
+// cannot back its tier, e.g. a Tier 1 claim with software attestation
+func (a *TierAttestation) checkMethod() error {
+	if !a.Tier.AcceptsMethod(a.Method) {
+		method := a.Method
+		if method == "" {
+			method = "none"
+		}
+		return fmt.Errorf("%w: %s attestation cannot back %s, want one of %v",
+			ErrAttestationMethod, method, a.Tier, tierMethods[a.Tier])
+	}
+	return nil
+}
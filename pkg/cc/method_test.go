@@ -0,0 +1,74 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTierRequirement_IsMetMethod(t *testing.T) {
+	methods := []string{
+		MethodNVTrust, MethodSEVSNP, MethodTDX, MethodCCA,
+		MethodSecureEnclave, MethodTrustZone, MethodSoftware, "",
+	}
+	accepted := map[CCTier]map[string]bool{
+		Tier1GPUNativeCC:    {MethodNVTrust: true},
+		Tier2ConfidentialVM: {MethodSEVSNP: true, MethodTDX: true, MethodCCA: true},
+		Tier3DeviceTEE:      {MethodSecureEnclave: true, MethodTrustZone: true},
+	}
+
+	now := time.Now()
+	for _, tier := range []CCTier{Tier1GPUNativeCC, Tier2ConfidentialVM, Tier3DeviceTEE, Tier4Standard} {
+		for _, method := range methods {
+			want := tier == Tier4Standard || accepted[tier][method]
+			att := &TierAttestation{
+				Tier:       tier,
+				Method:     method,
+				TrustScore: tier.MaxTrustScore(),
+				IssuedAt:   now.Add(-time.Minute),
+				ExpiresAt:  now.Add(time.Hour),
+			}
+
+			if got := tier.AcceptsMethod(method); got != want {
+				t.Errorf("%s.AcceptsMethod(%q) = %v, want %v", tier, method, got, want)
+			}
+
+			req := TierRequirement{MinTier: Tier4Standard, RequireTierMethod: true}
+			err := req.IsMet(att)
+			if want && err != nil {
+				t.Errorf("%s with %q: IsMet() = %v, want nil", tier, method, err)
+			}
+			if !want && !errors.Is(err, ErrAttestationMethod) {
+				t.Errorf("%s with %q: IsMet() = %v, want %v", tier, method, err, ErrAttestationMethod)
+			}
+
+			// Without the policy the method is not checked
+			req.RequireTierMethod = false
+			if err := req.IsMet(att); err != nil {
+				t.Errorf("%s with %q, policy off: IsMet() = %v, want nil", tier, method, err)
+			}
+		}
+	}
+}
+
+func TestAttestationMethodsCopy(t *testing.T) {
+	methods := Tier1GPUNativeCC.AttestationMethods()
+	methods[0] = MethodSoftware
+	if !Tier1GPUNativeCC.AcceptsMethod(MethodNVTrust) || Tier1GPUNativeCC.AcceptsMethod(MethodSoftware) {
+		t.Error("modifying AttestationMethods() changed the tier policy")
+	}
+	if got := Tier4Standard.AttestationMethods(); got != nil {
+		t.Errorf("Tier4Standard.AttestationMethods() = %v, want nil", got)
+	}
+}
+
+// acceptedMethod returns an attestation method that can back tier
+func acceptedMethod(tier CCTier) string {
+	if methods := tier.AttestationMethods(); len(methods) > 0 {
+		return methods[0]
+	}
+	return MethodSoftware
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+// OnboardingReport summarizes what a new provider's hardware can achieve:
+// the highest tier, a baseline trust score, the stake that tier requires
+// and any setup still needed to reach it
+type OnboardingReport struct {
+	Capability     *HardwareCapability `json:"capability"`
+	MaxTier        CCTier              `json:"max_tier"`
+	SupportedTiers []CCTier            `json:"supported_tiers"`
+
+	// Baseline trust score, as QuickTrustScore, with its breakdown
+	TrustScore     uint8             `json:"trust_score"`
+	TrustBreakdown *TrustScoreResult `json:"trust_breakdown"`
+
+	// Minimum stake for MaxTier
+	MinStakeLUX uint64 `json:"min_stake_lux"`
+
+	RequiresSetup bool   `json:"requires_setup"`
+	SetupHint     string `json:"setup_hint,omitempty"`
+}
+
+// DetectAndScore detects the host's capabilities and builds its onboarding
+// report
+func DetectAndScore() (*OnboardingReport, error) {
+	cap, err := DetectCapabilities()
+	if err != nil {
+		return nil, err
+	}
+	return NewOnboardingReport(cap), nil
+}
+
+// NewOnboardingReport builds the onboarding report for detected
+// capabilities
+func NewOnboardingReport(cap *HardwareCapability) *OnboardingReport {
+	breakdown := CalculateTrustScore(BaselineTrustInput(cap.MaxTier, cap))
+	report := &OnboardingReport{
+		Capability:     cap,
+		MaxTier:        cap.MaxTier,
+		SupportedTiers: cap.GetSupportedTiers(),
+		TrustScore:     breakdown.TotalScore,
+		TrustBreakdown: breakdown,
+		MinStakeLUX:    cap.MaxTier.MinStakeLUX(),
+	}
+	report.RequiresSetup, report.SetupHint = cap.RequiresSetup()
+	return report
+}
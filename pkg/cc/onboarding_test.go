@@ -0,0 +1,64 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewOnboardingReport(t *testing.T) {
+	tests := []struct {
+		name      string
+		cap       *HardwareCapability
+		wantTiers int
+		wantSetup bool
+	}{
+		{
+			name: "blackwell ready",
+			cap: &HardwareCapability{
+				GPUVendor: VendorNVIDIA, ComputeCap: "9.0", GPUCCSupported: true, GPUCCEnabled: true,
+				GPUCCMode: GPUCCModeOn, NVTrustAvail: true, MaxTier: Tier1GPUNativeCC,
+			},
+			wantTiers: 4,
+		},
+		{
+			name: "hopper CC off",
+			cap: &HardwareCapability{
+				GPUVendor: VendorNVIDIA, ComputeCap: "9.0", GPUCCSupported: true, MaxTier: Tier4Standard,
+			},
+			wantTiers: 1,
+			wantSetup: true,
+		},
+		{
+			name:      "consumer",
+			cap:       &HardwareCapability{GPUVendor: VendorUnknown, CPUTEEType: TEENone, MaxTier: Tier4Standard},
+			wantTiers: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewOnboardingReport(tt.cap)
+			if r.MaxTier != tt.cap.MaxTier {
+				t.Errorf("MaxTier = %v, want %v", r.MaxTier, tt.cap.MaxTier)
+			}
+			if len(r.SupportedTiers) != tt.wantTiers {
+				t.Errorf("SupportedTiers = %v, want %d tiers", r.SupportedTiers, tt.wantTiers)
+			}
+			if want := QuickTrustScore(tt.cap.MaxTier, tt.cap); r.TrustScore != want || r.TrustBreakdown.TotalScore != want {
+				t.Errorf("TrustScore = %d (breakdown %d), want %d", r.TrustScore, r.TrustBreakdown.TotalScore, want)
+			}
+			if r.MinStakeLUX != tt.cap.MaxTier.MinStakeLUX() {
+				t.Errorf("MinStakeLUX = %d, want %d", r.MinStakeLUX, tt.cap.MaxTier.MinStakeLUX())
+			}
+			if r.RequiresSetup != tt.wantSetup || (r.SetupHint != "") != tt.wantSetup {
+				t.Errorf("RequiresSetup = %v, %q, want %v", r.RequiresSetup, r.SetupHint, tt.wantSetup)
+			}
+			if _, err := json.Marshal(r); err != nil {
+				t.Errorf("json.Marshal() error = %v", err)
+			}
+		})
+	}
+}
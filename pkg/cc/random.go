@@ -0,0 +1,78 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// rewardSeedDomain separates reward seeds from other hashes of the same
+// entropy
+const rewardSeedDomain = "lux-ai-reward-seed-v1"
+
+// RewardSeed derives the seed of an epoch's random selections from the
+// epoch number and public entropy such as the epoch's block hash. Anyone
+// holding the same inputs derives the same seed, so selections can be
+// replayed and verified.
+func RewardSeed(epoch uint64, entropy []byte) int64 {
+	h := sha256.New()
+	h.Write([]byte(rewardSeedDomain))
+	binary.Write(h, binary.BigEndian, epoch)
+	h.Write(entropy)
+	return int64(binary.BigEndian.Uint64(h.Sum(nil)))
+}
+
+// NewRewardRNG returns an RNG seeded with RewardSeed(epoch, entropy)
+func NewRewardRNG(epoch uint64, entropy []byte) *rand.Rand {
+	return rand.New(rand.NewSource(RewardSeed(epoch, entropy)))
+}
+
+// SelectRandomMiners draws up to count distinct providers eligible for
+// random mining, each with probability proportional to its reward weight.
+// The draw is seeded from the pool's EpochNumber and entropy and considers
+// providers in ID order, so the same pool state and entropy always select
+// the same providers, in the same order.
+func (pool *AIRewardPool) SelectRandomMiners(entropy []byte, count int, maxHeartbeatAge time.Duration) []*AIProvider {
+	return pool.selectRandomMiners(NewRewardRNG(pool.EpochNumber, entropy), count, maxHeartbeatAge)
+}
+
+func (pool *AIRewardPool) selectRandomMiners(rng *rand.Rand, count int, maxHeartbeatAge time.Duration) []*AIProvider {
+	now := pool.now()
+	var candidates []*AIProvider
+	for _, provider := range pool.Providers {
+		if ok, _ := pool.RandomMiningEligibility(provider, maxHeartbeatAge); ok {
+			candidates = append(candidates, provider)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ProviderID < candidates[j].ProviderID
+	})
+	weights := make([]float64, len(candidates))
+	for i, provider := range candidates {
+		weights[i] = max(provider.rewardWeightAt(now), 0)
+	}
+
+	var selected []*AIProvider
+	for len(selected) < count && len(candidates) > 0 {
+		var total float64
+		for _, w := range weights {
+			total += w
+		}
+		if total <= 0 {
+			break
+		}
+		i, r := 0, rng.Float64()*total
+		for ; i < len(weights)-1 && r >= weights[i]; i++ {
+			r -= weights[i]
+		}
+		selected = append(selected, candidates[i])
+		candidates = append(candidates[:i], candidates[i+1:]...)
+		weights = append(weights[:i], weights[i+1:]...)
+	}
+	return selected
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/clock"
+)
+
+func newSelectionPool(t *testing.T) *AIRewardPool {
+	t.Helper()
+	pool := NewAIRewardPool(time.Hour)
+	pool.Clock = clock.NewMock(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	now := pool.now()
+	for i := range 12 {
+		err := pool.RegisterProvider(&AIProvider{
+			ProviderID: fmt.Sprintf("p%02d", i),
+			Attestation: &TierAttestation{
+				Tier:      Tier2ConfidentialVM,
+				Method:    MethodSEVSNP,
+				IssuedAt:  now.Add(-time.Hour),
+				ExpiresAt: now.Add(time.Hour),
+			},
+			MaxModelingLevel: ModelingLevelInferenceStandard,
+			StakeLUX:         50_000,
+			LastHeartbeat:    now,
+			ReputationScore:  1,
+		})
+		if err != nil {
+			t.Fatalf("RegisterProvider(%d): %v", i, err)
+		}
+	}
+	pool.EpochNumber = 7
+	return pool
+}
+
+func selectedIDs(providers []*AIProvider) []string {
+	ids := make([]string, len(providers))
+	for i, p := range providers {
+		ids[i] = p.ProviderID
+	}
+	return ids
+}
+
+func TestRewardSeed(t *testing.T) {
+	if RewardSeed(7, []byte("block")) != RewardSeed(7, []byte("block")) {
+		t.Error("RewardSeed() differs for identical inputs")
+	}
+	if RewardSeed(7, []byte("block")) == RewardSeed(8, []byte("block")) {
+		t.Error("RewardSeed() ignores the epoch")
+	}
+	if RewardSeed(7, []byte("block")) == RewardSeed(7, []byte("other")) {
+		t.Error("RewardSeed() ignores the entropy")
+	}
+}
+
+func TestSelectRandomMiners(t *testing.T) {
+	pool := newSelectionPool(t)
+	pool.Providers["p03"].LastHeartbeat = pool.now().Add(-time.Hour)
+	pool.BlockProvider("p05", "test")
+
+	want := selectedIDs(pool.SelectRandomMiners([]byte("block-1"), 5, time.Minute))
+	if len(want) != 5 {
+		t.Fatalf("SelectRandomMiners() = %v, want 5 providers", want)
+	}
+	for _, id := range want {
+		if id == "p03" || id == "p05" {
+			t.Errorf("SelectRandomMiners() = %v, selected ineligible %s", want, id)
+		}
+	}
+
+	// Replaying on a copy of the pool state gives the same draw
+	for range 3 {
+		if got := selectedIDs(pool.Clone().SelectRandomMiners([]byte("block-1"), 5, time.Minute)); !slices.Equal(got, want) {
+			t.Errorf("SelectRandomMiners() = %v, want %v for the same seed", got, want)
+		}
+	}
+
+	diverged := false
+	for _, entropy := range []string{"block-2", "block-3", "block-4"} {
+		if got := selectedIDs(pool.SelectRandomMiners([]byte(entropy), 5, time.Minute)); !slices.Equal(got, want) {
+			diverged = true
+		}
+	}
+	pool.EpochNumber++
+	if got := selectedIDs(pool.SelectRandomMiners([]byte("block-1"), 5, time.Minute)); !slices.Equal(got, want) {
+		diverged = true
+	}
+	if !diverged {
+		t.Errorf("SelectRandomMiners() = %v for every seed, want different seeds to diverge", want)
+	}
+
+	if got := pool.SelectRandomMiners([]byte("block-1"), 20, time.Minute); len(got) != 10 {
+		t.Errorf("SelectRandomMiners(20) = %d providers, want the 10 eligible", len(got))
+	}
+}
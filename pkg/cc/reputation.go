@@ -0,0 +1,94 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import "time"
+
+// Reputation recovery
+//
+// A provider's trust score heals only at epoch boundaries, in
+// AdvanceEpoch, and only after a clean epoch: one in which it completed at
+// least one rewarded task (RecordTaskReward) and had no failures or
+// slashes (RecordFailure, RecordSlash). The recovery rate grows with the
+// run of consecutive clean epochs, so a provider that has behaved for a
+// long time heals faster than one that just stopped failing, and any
+// dirty epoch resets the run. An idle epoch neither heals nor resets it:
+// having reported no failures is not evidence of good behavior.
+const (
+	// RecoveryRatePerCleanEpoch is the fraction of the gap to the tier's
+	// maximum trust score recovered per consecutive clean epoch
+	RecoveryRatePerCleanEpoch = 0.05
+
+	// MaxRecoveryRate caps the per-epoch recovery rate
+	MaxRecoveryRate = 0.25
+)
+
+// RecoveryRate returns the recovery rate after cleanEpochs consecutive
+// clean epochs: RecoveryRatePerCleanEpoch per epoch, capped at
+// MaxRecoveryRate, and 0 with no clean epochs
+func RecoveryRate(cleanEpochs uint64) float64 {
+	return min(MaxRecoveryRate, RecoveryRatePerCleanEpoch*float64(cleanEpochs))
+}
+
+// RecordFailure counts a failed task against the provider's current epoch
+func (p *AIProvider) RecordFailure() {
+	p.FailuresThisEpoch++
+}
+
+// RecordSlash counts a slashing event against the provider's current
+// epoch and reduces its trust score by severity (see AdjustScoreForSlashing)
+func (p *AIProvider) RecordSlash(severity float64) {
+	p.SlashesThisEpoch++
+	if p.Attestation != nil {
+		p.Attestation.TrustScore = AdjustScoreForSlashing(p.Attestation.TrustScore, severity)
+	}
+}
+
+// closeEpoch updates the provider's clean-epoch run, recovers its trust
+// score after a clean epoch, and resets the per-epoch counters. Only
+// AdvanceEpoch calls it, which limits recovery to once per epoch.
+func (p *AIProvider) closeEpoch(now time.Time) {
+	dirty := p.FailuresThisEpoch > 0 || p.SlashesThisEpoch > 0
+	idle := p.TasksThisEpoch == 0
+	p.FailuresThisEpoch = 0
+	p.SlashesThisEpoch = 0
+	p.TasksThisEpoch = 0
+
+	if dirty {
+		p.CleanEpochs = 0
+		return
+	}
+	if idle {
+		return
+	}
+	p.CleanEpochs++
+
+	// Expired attestations are re-scored on re-attestation instead
+	if !p.hasCurrentAttestation(now) {
+		return
+	}
+	att := p.Attestation
+	maxScore := att.Tier.MaxTrustScore()
+	if att.TrustScore >= maxScore {
+		return
+	}
+	recovered := RecoverScoreAfterGoodBehavior(att.TrustScore, maxScore, RecoveryRate(p.CleanEpochs))
+	if recovered == att.TrustScore {
+		// Small gaps round to no recovery; heal at least a point
+		recovered++
+	}
+	att.TrustScore = recovered
+}
+
+// AdvanceEpoch closes the current epoch and starts the next. Each
+// provider's trust score recovers toward its tier's maximum if it completed
+// tasks and the epoch was clean, per-epoch counters are reset, and
+// EpochNumber is incremented.
+func (pool *AIRewardPool) AdvanceEpoch() {
+	now := pool.now()
+	for _, provider := range pool.Providers {
+		provider.closeEpoch(now)
+	}
+	pool.EpochNumber++
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newRecoveryProvider(tier CCTier, score uint8, expiresIn time.Duration) *AIProvider {
+	now := time.Now()
+	return &AIProvider{
+		ProviderID: "p",
+		Attestation: &TierAttestation{
+			Tier:       tier,
+			TrustScore: score,
+			IssuedAt:   now.Add(-time.Hour),
+			ExpiresAt:  now.Add(expiresIn),
+		},
+	}
+}
+
+func TestRecoveryRate(t *testing.T) {
+	tests := []struct {
+		clean uint64
+		want  float64
+	}{
+		{0, 0},
+		{1, 0.05},
+		{2, 0.10},
+		{5, 0.25},
+		{100, MaxRecoveryRate},
+	}
+	for _, tt := range tests {
+		if got := RecoveryRate(tt.clean); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("RecoveryRate(%d) = %v, want %v", tt.clean, got, tt.want)
+		}
+	}
+}
+
+func TestAdvanceEpochRecovery(t *testing.T) {
+	pool := NewAIRewardPool(time.Hour)
+	provider := newRecoveryProvider(Tier2ConfidentialVM, 70, 24*time.Hour)
+	pool.Providers[provider.ProviderID] = provider
+
+	// Each step runs one epoch: working epochs complete a task, and dirty
+	// epochs record a failure or slash
+	steps := []struct {
+		name      string
+		idle      bool
+		failure   bool
+		slash     float64
+		wantScore uint8
+		wantClean uint64
+	}{
+		{"clean, gap rounds to zero", false, false, 0, 71, 1}, // 19*0.05 = 0.95 -> +1 minimum
+		{"clean, rate grows", false, false, 0, 72, 2},         // 18*0.10 = 1.8 -> +1
+		{"idle keeps run", true, false, 0, 72, 2},             // no recovery
+		{"failure resets run", false, true, 0, 72, 0},         // no recovery
+		{"slash halves score", false, false, 0.5, 36, 0},      // 72 - 36
+		{"clean after slash", false, false, 0, 38, 1},         // 53*0.05 = 2.65 -> +2
+		{"clean again", false, false, 0, 43, 2},               // 51*0.10 = 5.1 -> +5
+		{"third clean epoch", false, false, 0, 49, 3},         // 46*0.15 = 6.9 -> +6
+	}
+	for i, step := range steps {
+		if !step.idle {
+			task := fmt.Sprintf("task-%d", i)
+			pool.RecordTaskReward(provider, pool.CalculateTaskReward(provider, task, ModelingLevelInferenceLight, 1))
+		}
+		if step.failure {
+			provider.RecordFailure()
+		}
+		if step.slash > 0 {
+			provider.RecordSlash(step.slash)
+		}
+		pool.AdvanceEpoch()
+
+		if got := provider.Attestation.TrustScore; got != step.wantScore {
+			t.Errorf("%s: TrustScore = %d, want %d", step.name, got, step.wantScore)
+		}
+		if provider.CleanEpochs != step.wantClean {
+			t.Errorf("%s: CleanEpochs = %d, want %d", step.name, provider.CleanEpochs, step.wantClean)
+		}
+		if provider.FailuresThisEpoch != 0 || provider.SlashesThisEpoch != 0 || provider.TasksThisEpoch != 0 {
+			t.Errorf("%s: per-epoch counters not reset", step.name)
+		}
+		if pool.EpochNumber != uint64(i+1) {
+			t.Errorf("%s: EpochNumber = %d, want %d", step.name, pool.EpochNumber, i+1)
+		}
+	}
+}
+
+func TestAdvanceEpochCapsAtTierMax(t *testing.T) {
+	pool := NewAIRewardPool(time.Hour)
+	provider := newRecoveryProvider(Tier3DeviceTEE, 60, 24*time.Hour)
+	pool.Providers[provider.ProviderID] = provider
+
+	for i := 0; i < 50; i++ {
+		provider.TasksThisEpoch = 1
+		pool.AdvanceEpoch()
+	}
+	if got, want := provider.Attestation.TrustScore, Tier3DeviceTEE.MaxTrustScore(); got != want {
+		t.Errorf("TrustScore after 50 clean epochs = %d, want tier max %d", got, want)
+	}
+}
+
+func TestAdvanceEpochExpiredAttestation(t *testing.T) {
+	pool := NewAIRewardPool(time.Hour)
+	provider := newRecoveryProvider(Tier2ConfidentialVM, 70, -time.Minute)
+	provider.TasksThisEpoch = 3
+	pool.Providers[provider.ProviderID] = provider
+
+	pool.AdvanceEpoch()
+	if got := provider.Attestation.TrustScore; got != 70 {
+		t.Errorf("TrustScore = %d, want 70 for an expired attestation", got)
+	}
+	if provider.CleanEpochs != 1 || provider.TasksThisEpoch != 0 {
+		t.Errorf("CleanEpochs = %d, TasksThisEpoch = %d, want 1 and 0",
+			provider.CleanEpochs, provider.TasksThisEpoch)
+	}
+}
+
+func TestAdvanceEpochIdleDoesNotRecover(t *testing.T) {
+	pool := NewAIRewardPool(time.Hour)
+	provider := newRecoveryProvider(Tier2ConfidentialVM, 40, 24*time.Hour)
+	pool.Providers[provider.ProviderID] = provider
+
+	for i := 0; i < 10; i++ {
+		pool.AdvanceEpoch()
+	}
+	if got := provider.Attestation.TrustScore; got != 40 {
+		t.Errorf("TrustScore after 10 idle epochs = %d, want 40", got)
+	}
+
+	// Calculating a reward does not count the task; recording it twice
+	// counts it once
+	reward := pool.CalculateTaskReward(provider, "task", ModelingLevelInferenceLight, 1)
+	if provider.TasksThisEpoch != 0 {
+		t.Errorf("TasksThisEpoch = %d after CalculateTaskReward, want 0", provider.TasksThisEpoch)
+	}
+	pool.RecordTaskReward(provider, reward)
+	pool.RecordTaskReward(provider, reward)
+	if provider.TasksThisEpoch != 1 || provider.TotalTasksCompleted != 1 {
+		t.Errorf("TasksThisEpoch = %d, TotalTasksCompleted = %d, want 1 and 1",
+			provider.TasksThisEpoch, provider.TotalTasksCompleted)
+	}
+}
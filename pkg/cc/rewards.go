@@ -20,7 +20,10 @@ package cc
 
 import (
 	"math/big"
+	"sort"
 	"time"
+
+	"github.com/luxfi/ai/pkg/clock"
 )
 
 // AIRewardPoolShare is the percentage of block rewards allocated to AI compute
@@ -121,6 +124,10 @@ type AIProvider struct {
 	// MaxModelingLevel is the highest modeling level supported
 	MaxModelingLevel ModelingLevel `json:"max_modeling_level"`
 
+	// ModelingLevels, if set, are the only levels the provider opts into;
+	// see SupportsLevel
+	ModelingLevels []ModelingLevel `json:"modeling_levels,omitempty"`
+
 	// CurrentModelingLevel is the current active workload level
 	CurrentModelingLevel ModelingLevel `json:"current_modeling_level"`
 
@@ -130,6 +137,10 @@ type AIProvider struct {
 	// LastHeartbeat is when the provider last checked in
 	LastHeartbeat time.Time `json:"last_heartbeat"`
 
+	// Heartbeats is the provider's heartbeat history, kept by
+	// AIRewardPool.RecordHeartbeat; nil until the first one
+	Heartbeats *HeartbeatTracker `json:"heartbeats,omitempty"`
+
 	// ConsecutiveEpochs is consecutive epochs online
 	ConsecutiveEpochs uint64 `json:"consecutive_epochs"`
 
@@ -139,30 +150,89 @@ type AIProvider struct {
 	// TotalTasksCompleted is lifetime tasks completed
 	TotalTasksCompleted uint64 `json:"total_tasks_completed"`
 
+	// FailuresThisEpoch is failed tasks in current epoch
+	FailuresThisEpoch uint64 `json:"failures_this_epoch,omitempty"`
+
+	// SlashesThisEpoch is slashing events in current epoch
+	SlashesThisEpoch uint64 `json:"slashes_this_epoch,omitempty"`
+
+	// CleanEpochs is consecutive epochs without failures or slashes
+	CleanEpochs uint64 `json:"clean_epochs,omitempty"`
+
 	// ReputationScore is 0.0-1.0 historical reputation
 	ReputationScore float64 `json:"reputation_score"`
+
+	// CapacityTPS is the benchmarked throughput in tokens/sec, 0 if unknown
+	CapacityTPS float64 `json:"capacity_tps,omitempty"`
+
+	// TierGracePeriod is how long an expired attestation keeps its tier,
+	// at a decaying multiplier, before falling to Tier4 (0 = no grace).
+	// The pool sets it at registration; a provider cannot choose its own.
+	TierGracePeriod time.Duration `json:"tier_grace_period,omitempty"`
+
+	// SigningKey is the provider's ed25519 public key, checked against the
+	// pool's KeyAuthorizer at registration
+	SigningKey []byte `json:"signing_key,omitempty"`
 }
 
 // IsOnline checks if the provider is currently online
 func (p *AIProvider) IsOnline(maxHeartbeatAge time.Duration) bool {
-	return time.Since(p.LastHeartbeat) < maxHeartbeatAge
+	return p.isOnlineAt(time.Now(), maxHeartbeatAge)
 }
 
-// EffectiveTier returns the CC tier from attestation, or Tier4 if none
-func (p *AIProvider) EffectiveTier() CCTier {
-	if p.Attestation != nil && p.Attestation.IsValid() {
-		return p.Attestation.Tier
+func (p *AIProvider) isOnlineAt(now time.Time, maxHeartbeatAge time.Duration) bool {
+	return now.Sub(p.LastHeartbeat) < maxHeartbeatAge
+}
+
+// IsEligible reports whether the provider is online and holds a current
+// attestation, either valid or within its tier grace period. It is the one
+// liveness check shared by participation rewards and random mining
+// eligibility; the reason is EligibilityOK when eligible. Stake is checked
+// separately against a pool's schedule.
+func (p *AIProvider) IsEligible(maxHeartbeatAge time.Duration) (bool, EligibilityReason) {
+	return p.isEligibleAt(time.Now(), maxHeartbeatAge)
+}
+
+func (p *AIProvider) isEligibleAt(now time.Time, maxHeartbeatAge time.Duration) (bool, EligibilityReason) {
+	switch {
+	case p == nil:
+		return false, EligibilityNilProvider
+	case !p.isOnlineAt(now, maxHeartbeatAge):
+		return false, EligibilityOffline
+	case p.Attestation == nil:
+		return false, EligibilityNoAttestation
+	case !p.hasCurrentAttestation(now):
+		return false, EligibilityAttestationExpired
+	}
+	return true, EligibilityOK
+}
+
+// VRAMGB returns the GPU memory reported by the provider's attestation in
+// decimal gigabytes (an "80GB" card reports ~85GB). The second return value
+// is false when the attestation carries no hardware memory information.
+func (p *AIProvider) VRAMGB() (uint64, bool) {
+	if p.Attestation == nil || p.Attestation.HardwareInfo.MemoryBytes() == 0 {
+		return 0, false
 	}
-	return Tier4Standard
+	return p.Attestation.HardwareInfo.MemoryGB(), true
+}
+
+// EffectiveTier returns the CC tier from attestation, or Tier4 if none.
+// An expired attestation keeps its tier during the tier grace period.
+func (p *AIProvider) EffectiveTier() CCTier {
+	return p.effectiveTierAt(time.Now())
 }
 
 // RewardWeight calculates the provider's weight in the reward pool
 // Weight = TierMultiplier * ModelingMultiplier * StakeWeight * UptimeBonus * ReputationBonus
 func (p *AIProvider) RewardWeight() float64 {
-	tier := p.EffectiveTier()
+	return p.rewardWeightAt(time.Now())
+}
 
-	// Base tier multiplier (1.5x for Tier1, down to 0.5x for Tier4)
-	tierMult := tier.RewardMultiplier()
+func (p *AIProvider) rewardWeightAt(now time.Time) float64 {
+	// Base tier multiplier (1.5x for Tier1, down to 0.5x for Tier4),
+	// decaying toward Tier4 during the tier grace period
+	tierMult := p.tierMultiplierAt(now, CCTier.RewardMultiplier)
 
 	// Modeling level multiplier
 	modelMult := p.MaxModelingLevel.BaseRewardMultiplier()
@@ -217,13 +287,67 @@ type AIRewardPool struct {
 	// TotalPoolLUX is the total LUX in the AI reward pool for this epoch
 	TotalPoolLUX *big.Int `json:"total_pool_lux"`
 
-	// ParticipationShare is the % of AI pool for random availability rewards
+	// ParticipationShare is the fraction of the AI pool for random
+	// availability rewards, clamped to [0, 1]; set through SetShares to
+	// validate
 	// Default: 30% of AI pool (3% of total block rewards)
 	ParticipationShare float64 `json:"participation_share"`
 
 	// TaskShare is the % of AI pool for task completion rewards
 	// Default: 70% of AI pool (7% of total block rewards)
 	TaskShare float64 `json:"task_share"`
+
+	// StakeSchedule overrides the per-tier minimum stakes, e.g. for private
+	// or test deployments. Nil uses the LP-5610 values.
+	StakeSchedule StakeSchedule `json:"stake_schedule,omitempty"`
+
+	// History holds each provider's per-epoch earnings, oldest first
+	History map[string][]*ProviderEpochRecord `json:"history,omitempty"`
+
+	// HistoryEpochs is how many epochs of History to retain
+	// Default: DefaultHistoryEpochs
+	HistoryEpochs int `json:"history_epochs,omitempty"`
+
+	// TierGracePeriod is applied to every provider at registration,
+	// replacing any value the provider set (0 = no grace)
+	TierGracePeriod time.Duration `json:"tier_grace_period,omitempty"`
+
+	// TaskRates overrides the task reward base rate and multipliers. Nil
+	// uses the defaults; set through SetTaskRates to validate.
+	TaskRates *TaskRates `json:"task_rates,omitempty"`
+
+	// KeyAuthorizer, when set, must authorize each provider's SigningKey
+	// at registration
+	KeyAuthorizer KeyAuthorizer `json:"-"`
+
+	// Allowlist, when non-empty, restricts rewards to the listed providers
+	Allowlist ProviderList `json:"allowlist,omitempty"`
+
+	// Blocklist excludes providers from rewards regardless of stake,
+	// attestation or Allowlist, e.g. pending an investigation. Set both
+	// through SetAccessLists or BlockProvider to validate.
+	Blocklist ProviderList `json:"blocklist,omitempty"`
+
+	// MinComputeCap, when set, rejects providers whose attested compute
+	// capability is below the minimum for their tier or modeling levels.
+	// Set through SetComputeCapPolicy to validate.
+	MinComputeCap *ComputeCapPolicy `json:"min_compute_capability,omitempty"`
+
+	// HeartbeatInterval is how often providers are expected to heartbeat
+	// and UptimeWindow the rolling window their uptime is measured over.
+	// Zero uses DefaultHeartbeatInterval and DefaultUptimeWindow; set
+	// through SetUptimePolicy to validate.
+	HeartbeatInterval time.Duration `json:"heartbeat_interval,omitempty"`
+	UptimeWindow      time.Duration `json:"uptime_window,omitempty"`
+
+	// Clock supplies the time for heartbeat, attestation and grace period
+	// checks. Nil uses the system clock.
+	Clock clock.Clock `json:"-"`
+}
+
+// now returns the current time on the pool's clock
+func (pool *AIRewardPool) now() time.Time {
+	return clock.Or(pool.Clock).Now()
 }
 
 // NewAIRewardPool creates a new AI reward pool
@@ -237,20 +361,73 @@ func NewAIRewardPool(epochDuration time.Duration) *AIRewardPool {
 	}
 }
 
-// RegisterProvider adds a provider to the pool
-func (pool *AIRewardPool) RegisterProvider(provider *AIProvider) error {
-	if provider.ProviderID == "" {
-		return ErrInvalidAttestation
+// NewAIRewardPoolWithStakeSchedule creates a reward pool whose minimum
+// stakes come from schedule instead of the LP-5610 defaults
+func NewAIRewardPoolWithStakeSchedule(epochDuration time.Duration, schedule StakeSchedule) (*AIRewardPool, error) {
+	if err := schedule.Validate(); err != nil {
+		return nil, err
 	}
-	if provider.StakeLUX < Tier4Standard.MinStakeLUX() {
-		return ErrInsufficientStake
+	pool := NewAIRewardPool(epochDuration)
+	pool.StakeSchedule = make(StakeSchedule, len(schedule))
+	for tier, stake := range schedule {
+		pool.StakeSchedule[tier] = stake
 	}
+	return pool, nil
+}
+
+// MinStake returns the minimum stake for tier under the pool's schedule
+func (pool *AIRewardPool) MinStake(tier CCTier) uint64 {
+	return pool.StakeSchedule.MinStake(tier)
+}
+
+// RegisterProvider adds a provider to the pool after ValidateProvider
+// admits it. Registering counts as the provider's first heartbeat; later
+// check-ins are recorded with Heartbeat.
+func (pool *AIRewardPool) RegisterProvider(provider *AIProvider) error {
+	if err := pool.ValidateProvider(provider); err != nil {
+		return err
+	}
+	provider.TierGracePeriod = pool.TierGracePeriod
+	pool.RecordHeartbeat(provider)
 	pool.Providers[provider.ProviderID] = provider
 	return nil
 }
 
-// CalculateBlockRewardSplit splits block reward between validators and AI pool
+// ScoreDistribution returns the current trust scores of all providers with a
+// valid attestation, grouped by CC tier and sorted ascending. Providers whose
+// attestation is missing or expired have no current score and are omitted.
+func (pool *AIRewardPool) ScoreDistribution() map[CCTier][]uint8 {
+	dist := make(map[CCTier][]uint8)
+	now := pool.now()
+	for _, provider := range pool.Providers {
+		if provider.Attestation == nil || !provider.Attestation.isValidAt(now) {
+			continue
+		}
+		tier := provider.Attestation.Tier
+		dist[tier] = append(dist[tier], provider.Attestation.TrustScore)
+	}
+	for _, scores := range dist {
+		sort.Slice(scores, func(i, j int) bool { return scores[i] < scores[j] })
+	}
+	return dist
+}
+
+// ScoreHistograms buckets the current score distribution of each tier
+// using the given upper bounds (see NewScoreHistogram).
+func (pool *AIRewardPool) ScoreHistograms(bounds []uint8) map[CCTier]*ScoreHistogram {
+	dist := pool.ScoreDistribution()
+	histograms := make(map[CCTier]*ScoreHistogram, len(dist))
+	for tier, scores := range dist {
+		histograms[tier] = NewScoreHistogram(scores, bounds)
+	}
+	return histograms
+}
+
+// CalculateBlockRewardSplit splits block reward between validators and AI pool.
+// A nil block reward is treated as zero.
 func CalculateBlockRewardSplit(totalBlockReward *big.Int) (validatorReward, aiPoolReward *big.Int) {
+	totalBlockReward = orZero(totalBlockReward)
+
 	// 90% to validators
 	validatorReward = new(big.Int).Mul(totalBlockReward, big.NewInt(90))
 	validatorReward.Div(validatorReward, big.NewInt(100))
@@ -284,31 +461,40 @@ type ParticipationRewardResult struct {
 
 // CalculateParticipationRewards distributes the participation pool
 // This is the "random mining" reward - providers earn just for being online and attested
+// Results are ordered by provider ID
 func (pool *AIRewardPool) CalculateParticipationRewards(
 	maxHeartbeatAge time.Duration,
 ) []*ParticipationRewardResult {
 	// Get participation pool amount
-	participationPool := new(big.Int).Set(pool.TotalPoolLUX)
-	participationPool.Mul(participationPool, big.NewInt(int64(pool.ParticipationShare*100)))
-	participationPool.Div(participationPool, big.NewInt(100))
+	participationPool := mulRat(pool.TotalPoolLUX, shareRat(pool.ParticipationShare))
 
-	// Calculate total weight of online providers
-	var totalWeight float64
+	// Collect online providers in provider ID order, so the results and
+	// the float weight total don't depend on map iteration order
+	now := pool.now()
 	onlineProviders := make([]*AIProvider, 0)
-
 	for _, provider := range pool.Providers {
-		if !provider.IsOnline(maxHeartbeatAge) {
+		if !pool.Participates(provider.ProviderID) {
 			continue
 		}
-		if provider.Attestation == nil || !provider.Attestation.IsValid() {
-			continue
+		if ok, _ := provider.isEligibleAt(now, maxHeartbeatAge); ok {
+			onlineProviders = append(onlineProviders, provider)
 		}
-		weight := provider.RewardWeight()
+	}
+	sort.Slice(onlineProviders, func(i, j int) bool {
+		return onlineProviders[i].ProviderID < onlineProviders[j].ProviderID
+	})
+
+	// Calculate total weight of online providers. Weights are summed
+	// exactly so the shares sum to at most 1.
+	var totalWeight float64
+	exactTotal := new(big.Rat)
+	for _, provider := range onlineProviders {
+		weight := provider.rewardWeightAt(now)
 		totalWeight += weight
-		onlineProviders = append(onlineProviders, provider)
+		exactTotal.Add(exactTotal, decimalRat(weight))
 	}
 
-	if totalWeight == 0 || len(onlineProviders) == 0 {
+	if exactTotal.Sign() == 0 || len(onlineProviders) == 0 {
 		return nil
 	}
 
@@ -316,19 +502,18 @@ func (pool *AIRewardPool) CalculateParticipationRewards(
 	results := make([]*ParticipationRewardResult, 0, len(onlineProviders))
 
 	for _, provider := range onlineProviders {
-		weight := provider.RewardWeight()
+		weight := provider.rewardWeightAt(now)
 		share := weight / totalWeight
 
-		reward := new(big.Int).Set(participationPool)
-		reward.Mul(reward, big.NewInt(int64(share*1e9)))
-		reward.Div(reward, big.NewInt(1e9))
+		exactShare := new(big.Rat).Quo(decimalRat(weight), exactTotal)
+		reward := mulRat(participationPool, exactShare)
 
 		results = append(results, &ParticipationRewardResult{
 			ProviderID:    provider.ProviderID,
 			RewardLUX:     reward,
 			Weight:        weight,
 			WeightShare:   share,
-			Tier:          provider.EffectiveTier(),
+			Tier:          provider.effectiveTierAt(now),
 			ModelingLevel: provider.MaxModelingLevel,
 		})
 	}
@@ -354,29 +539,35 @@ type TaskRewardResult struct {
 	ComputeUnits uint64 `json:"compute_units"`
 }
 
-// CalculateTaskReward calculates reward for a completed task
+// CalculateTaskReward calculates the reward for a completed task without
+// recording it; see RecordTaskReward. Providers the pool's access lists
+// exclude earn nothing.
 func (pool *AIRewardPool) CalculateTaskReward(
 	provider *AIProvider,
 	taskID string,
 	modelingLevel ModelingLevel,
 	computeUnits uint64,
 ) *TaskRewardResult {
-	// Base rate per compute unit (in wei)
-	// 1 compute unit = 1 GPU-second at Tier 2 / Level 2
-	baseRateWei := big.NewInt(1e12) // 0.000001 LUX per compute unit
+	if !pool.Participates(provider.ProviderID) {
+		return &TaskRewardResult{
+			ProviderID:    provider.ProviderID,
+			TaskID:        taskID,
+			RewardLUX:     new(big.Int),
+			ModelingLevel: modelingLevel,
+			ComputeUnits:  computeUnits,
+		}
+	}
+	rates := pool.TaskRates
 
 	// Calculate reward
-	reward := new(big.Int).Mul(baseRateWei, big.NewInt(int64(computeUnits)))
-
-	// Apply tier multiplier
-	tierMult := provider.EffectiveTier().RewardMultiplier()
-	reward.Mul(reward, big.NewInt(int64(tierMult*100)))
-	reward.Div(reward, big.NewInt(100))
+	reward := new(big.Int).Mul(rates.BaseRate(), new(big.Int).SetUint64(computeUnits))
 
-	// Apply modeling level multiplier
-	levelMult := modelingLevel.BaseRewardMultiplier()
-	reward.Mul(reward, big.NewInt(int64(levelMult*100)))
-	reward.Div(reward, big.NewInt(100))
+	// Apply tier multiplier, decaying toward Tier4 during the tier grace
+	// period, and the modeling level multiplier. Negative or non-finite
+	// multipliers count as zero.
+	tierMult := provider.tierMultiplierAt(pool.now(), rates.TierMultiplier)
+	levelMult := rates.LevelMultiplier(modelingLevel)
+	reward = mulRat(reward, new(big.Rat).Mul(decimalRat(tierMult), decimalRat(levelMult)))
 
 	return &TaskRewardResult{
 		ProviderID:    provider.ProviderID,
@@ -387,6 +578,21 @@ func (pool *AIRewardPool) CalculateTaskReward(
 	}
 }
 
+// RecordTaskReward stores a reward from CalculateTaskReward in provider's
+// current epoch and counts the task toward its clean epoch (see
+// AdvanceEpoch). Recording the same task again replaces its reward without
+// counting it twice. Rewards of providers the pool's access lists exclude
+// are not recorded.
+func (pool *AIRewardPool) RecordTaskReward(provider *AIProvider, r *TaskRewardResult) {
+	if !pool.Participates(provider.ProviderID) {
+		return
+	}
+	if pool.recordTaskReward(r) {
+		provider.TasksThisEpoch++
+		provider.TotalTasksCompleted++
+	}
+}
+
 // EpochRewardSummary contains the full epoch reward distribution
 type EpochRewardSummary struct {
 	// EpochNumber is the epoch being summarized
@@ -407,7 +613,7 @@ type EpochRewardSummary struct {
 	// TaskRewardsLUX is 70% of AI pool (7% total)
 	TaskRewardsLUX *big.Int `json:"task_rewards_lux"`
 
-	// OnlineProviders is count of providers that were online
+	// OnlineProviders is count of participating providers that were online
 	OnlineProviders uint64 `json:"online_providers"`
 
 	// TotalProviders is count of all registered providers
@@ -416,7 +622,7 @@ type EpochRewardSummary struct {
 	// ProviderRewards is the per-provider reward breakdown
 	ProviderRewards []*ParticipationRewardResult `json:"provider_rewards"`
 
-	// TierDistribution shows providers by tier
+	// TierDistribution shows online participating providers by tier
 	TierDistribution map[CCTier]uint64 `json:"tier_distribution"`
 }
 
@@ -425,6 +631,7 @@ func (pool *AIRewardPool) CalculateEpochRewards(
 	totalBlockRewards *big.Int,
 	maxHeartbeatAge time.Duration,
 ) *EpochRewardSummary {
+	totalBlockRewards = orZero(totalBlockRewards)
 	validatorRewards, aiPoolRewards := CalculateBlockRewardSplit(totalBlockRewards)
 
 	// Update pool total
@@ -432,21 +639,21 @@ func (pool *AIRewardPool) CalculateEpochRewards(
 
 	// Calculate participation rewards
 	participationRewards := pool.CalculateParticipationRewards(maxHeartbeatAge)
+	pool.recordParticipation(participationRewards)
 
 	// Calculate pool splits
-	participationPool := new(big.Int).Set(aiPoolRewards)
-	participationPool.Mul(participationPool, big.NewInt(int64(pool.ParticipationShare*100)))
-	participationPool.Div(participationPool, big.NewInt(100))
+	participationPool := mulRat(aiPoolRewards, shareRat(pool.ParticipationShare))
 
 	taskPool := new(big.Int).Sub(aiPoolRewards, participationPool)
 
-	// Count tiers
+	// Count the tiers of online providers the access lists admit
 	tierDist := make(map[CCTier]uint64)
 	var onlineCount uint64
+	now := pool.now()
 	for _, provider := range pool.Providers {
-		if provider.IsOnline(maxHeartbeatAge) {
+		if pool.Participates(provider.ProviderID) && provider.isOnlineAt(now, maxHeartbeatAge) {
 			onlineCount++
-			tier := provider.EffectiveTier()
+			tier := provider.effectiveTierAt(now)
 			tierDist[tier]++
 		}
 	}
@@ -465,28 +672,124 @@ func (pool *AIRewardPool) CalculateEpochRewards(
 	}
 }
 
-// RandomMiningEligibility checks if a provider is eligible for random mining rewards
-func RandomMiningEligibility(provider *AIProvider, maxHeartbeatAge time.Duration) (bool, string) {
-	if provider == nil {
-		return false, "provider is nil"
+// SimulateEpoch previews the epoch reward distribution for a hypothetical
+// block reward without mutating the pool: the epoch number, pool total and
+// provider state are left untouched. To preview different share settings,
+// adjust them on a Clone and simulate that.
+func (pool *AIRewardPool) SimulateEpoch(totalBlockReward *big.Int, maxAge time.Duration) *EpochRewardSummary {
+	return pool.Clone().CalculateEpochRewards(totalBlockReward, maxAge)
+}
+
+// Clone returns a copy of the pool whose settings, pool total, provider set
+// and earnings history can be changed without affecting the original.
+// Provider attestations are shared, not copied.
+func (pool *AIRewardPool) Clone() *AIRewardPool {
+	c := *pool
+	if pool.TotalPoolLUX != nil {
+		c.TotalPoolLUX = new(big.Int).Set(pool.TotalPoolLUX)
+	}
+	if pool.StakeSchedule != nil {
+		c.StakeSchedule = make(StakeSchedule, len(pool.StakeSchedule))
+		for tier, stake := range pool.StakeSchedule {
+			c.StakeSchedule[tier] = stake
+		}
 	}
+	c.History = cloneHistory(pool.History)
+	c.TaskRates = pool.TaskRates.clone()
+	c.Allowlist = pool.Allowlist.clone()
+	c.Blocklist = pool.Blocklist.clone()
+	c.MinComputeCap = pool.MinComputeCap.clone()
+	c.Providers = make(map[string]*AIProvider, len(pool.Providers))
+	for id, p := range pool.Providers {
+		cp := *p
+		cp.Heartbeats = p.Heartbeats.clone()
+		c.Providers[id] = &cp
+	}
+	return &c
+}
+
+// EligibilityReason explains the outcome of a random mining eligibility check.
+// Programmatic callers should compare against the constants below; String()
+// is for display only.
+type EligibilityReason uint8
+
+const (
+	// EligibilityOK means the provider is eligible for random mining rewards
+	EligibilityOK EligibilityReason = iota
+
+	// EligibilityNilProvider means no provider was supplied
+	EligibilityNilProvider
+
+	// EligibilityOffline means the provider's last heartbeat is too old
+	EligibilityOffline
+
+	// EligibilityNoAttestation means the provider has no CC attestation
+	EligibilityNoAttestation
+
+	// EligibilityAttestationExpired means the provider's attestation has expired and
+	// is past its tier grace period
+	EligibilityAttestationExpired
 
-	if !provider.IsOnline(maxHeartbeatAge) {
-		return false, "provider offline"
+	// EligibilityInsufficientStake means the stake is below the tier minimum
+	EligibilityInsufficientStake
+
+	// EligibilityBlocked means the provider is on the pool's blocklist
+	EligibilityBlocked
+
+	// EligibilityNotAllowed means the pool has an allowlist and the
+	// provider is not on it
+	EligibilityNotAllowed
+)
+
+// String returns the human-readable description of the eligibility reason
+func (r EligibilityReason) String() string {
+	switch r {
+	case EligibilityOK:
+		return "eligible"
+	case EligibilityNilProvider:
+		return "provider is nil"
+	case EligibilityOffline:
+		return "provider offline"
+	case EligibilityNoAttestation:
+		return "no attestation"
+	case EligibilityAttestationExpired:
+		return "attestation expired"
+	case EligibilityInsufficientStake:
+		return "insufficient stake"
+	case EligibilityBlocked:
+		return "provider blocked"
+	case EligibilityNotAllowed:
+		return "provider not allowlisted"
+	default:
+		return "unknown"
 	}
+}
 
-	if provider.Attestation == nil {
-		return false, "no attestation"
+// RandomMiningEligibility checks if a provider is eligible for random mining rewards
+func RandomMiningEligibility(provider *AIProvider, maxHeartbeatAge time.Duration) (bool, EligibilityReason) {
+	return randomMiningEligibility(time.Now(), provider, maxHeartbeatAge, nil)
+}
+
+// RandomMiningEligibility checks eligibility using the pool's stake schedule
+// and access lists
+func (pool *AIRewardPool) RandomMiningEligibility(provider *AIProvider, maxHeartbeatAge time.Duration) (bool, EligibilityReason) {
+	if provider != nil {
+		if ok, reason := pool.accessReason(provider.ProviderID); !ok {
+			return false, reason
+		}
 	}
+	return randomMiningEligibility(pool.now(), provider, maxHeartbeatAge, pool.StakeSchedule)
+}
 
-	if !provider.Attestation.IsValid() {
-		return false, "attestation expired"
+func randomMiningEligibility(now time.Time, provider *AIProvider, maxHeartbeatAge time.Duration, schedule StakeSchedule) (bool, EligibilityReason) {
+	if ok, reason := provider.isEligibleAt(now, maxHeartbeatAge); !ok {
+		return false, reason
 	}
 
-	minStake := provider.EffectiveTier().MinStakeLUX()
+	minStake := schedule.MinStake(provider.effectiveTierAt(now))
 	if provider.StakeLUX < minStake {
-		return false, "insufficient stake"
+		return false, EligibilityInsufficientStake
 	}
 
-	return true, "eligible"
+	return true, EligibilityOK
 }
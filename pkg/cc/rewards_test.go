@@ -4,9 +4,12 @@
 package cc
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 	"time"
+
+	"github.com/luxfi/ai/pkg/clock"
 )
 
 func TestModelingLevelString(t *testing.T) {
@@ -90,6 +93,7 @@ func TestAIProviderRewardWeight(t *testing.T) {
 				ProviderID: "test-1",
 				Attestation: &TierAttestation{
 					Tier:      Tier1GPUNativeCC,
+					Method:    MethodNVTrust,
 					IssuedAt:  now.Add(-1 * time.Hour),
 					ExpiresAt: now.Add(5 * time.Hour),
 				},
@@ -139,6 +143,7 @@ func TestAIRewardPoolRegistration(t *testing.T) {
 		ProviderID: "test-provider",
 		Attestation: &TierAttestation{
 			Tier:      Tier2ConfidentialVM,
+			Method:    MethodSEVSNP,
 			IssuedAt:  now.Add(-1 * time.Hour),
 			ExpiresAt: now.Add(23 * time.Hour),
 		},
@@ -179,6 +184,7 @@ func TestParticipationRewards(t *testing.T) {
 			ProviderID: "tier1-provider",
 			Attestation: &TierAttestation{
 				Tier:      Tier1GPUNativeCC,
+				Method:    MethodNVTrust,
 				IssuedAt:  now.Add(-1 * time.Hour),
 				ExpiresAt: now.Add(5 * time.Hour),
 			},
@@ -191,6 +197,7 @@ func TestParticipationRewards(t *testing.T) {
 			ProviderID: "tier2-provider",
 			Attestation: &TierAttestation{
 				Tier:      Tier2ConfidentialVM,
+				Method:    MethodSEVSNP,
 				IssuedAt:  now.Add(-1 * time.Hour),
 				ExpiresAt: now.Add(23 * time.Hour),
 			},
@@ -248,6 +255,43 @@ func TestParticipationRewards(t *testing.T) {
 	}
 }
 
+func TestParticipationRewardsOrder(t *testing.T) {
+	pool := NewAIRewardPool(1 * time.Hour)
+	pool.TotalPoolLUX = new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18))
+	now := time.Now()
+	for _, id := range []string{"delta", "alpha", "echo", "charlie", "bravo"} {
+		pool.Providers[id] = &AIProvider{
+			ProviderID: id,
+			Attestation: &TierAttestation{
+				Tier:      Tier2ConfidentialVM,
+				Method:    MethodSEVSNP,
+				IssuedAt:  now.Add(-1 * time.Hour),
+				ExpiresAt: now.Add(23 * time.Hour),
+			},
+			MaxModelingLevel: ModelingLevelInferenceStandard,
+			StakeLUX:         50_000,
+			LastHeartbeat:    now,
+			ReputationScore:  0.7,
+		}
+	}
+
+	first := pool.CalculateParticipationRewards(5 * time.Minute)
+	want := []string{"alpha", "bravo", "charlie", "delta", "echo"}
+	for i, r := range first {
+		if r.ProviderID != want[i] {
+			t.Fatalf("result %d = %s, want %s", i, r.ProviderID, want[i])
+		}
+	}
+	for range 20 {
+		again := pool.CalculateParticipationRewards(5 * time.Minute)
+		for i, r := range again {
+			if r.ProviderID != first[i].ProviderID || r.RewardLUX.Cmp(first[i].RewardLUX) != 0 || r.WeightShare != first[i].WeightShare {
+				t.Fatalf("result %d = %+v, want %+v", i, r, first[i])
+			}
+		}
+	}
+}
+
 func TestTaskReward(t *testing.T) {
 	pool := NewAIRewardPool(1 * time.Hour)
 	now := time.Now()
@@ -256,6 +300,7 @@ func TestTaskReward(t *testing.T) {
 		ProviderID: "task-provider",
 		Attestation: &TierAttestation{
 			Tier:      Tier1GPUNativeCC,
+			Method:    MethodNVTrust,
 			IssuedAt:  now.Add(-1 * time.Hour),
 			ExpiresAt: now.Add(5 * time.Hour),
 		},
@@ -304,7 +349,7 @@ func TestRandomMiningEligibility(t *testing.T) {
 		name     string
 		provider *AIProvider
 		eligible bool
-		reason   string
+		reason   EligibilityReason
 	}{
 		{
 			name: "Eligible provider",
@@ -312,6 +357,7 @@ func TestRandomMiningEligibility(t *testing.T) {
 				ProviderID: "eligible",
 				Attestation: &TierAttestation{
 					Tier:      Tier2ConfidentialVM,
+					Method:    MethodSEVSNP,
 					IssuedAt:  now.Add(-1 * time.Hour),
 					ExpiresAt: now.Add(23 * time.Hour),
 				},
@@ -319,13 +365,13 @@ func TestRandomMiningEligibility(t *testing.T) {
 				LastHeartbeat: now,
 			},
 			eligible: true,
-			reason:   "eligible",
+			reason:   EligibilityOK,
 		},
 		{
 			name:     "Nil provider",
 			provider: nil,
 			eligible: false,
-			reason:   "provider is nil",
+			reason:   EligibilityNilProvider,
 		},
 		{
 			name: "Offline provider",
@@ -333,6 +379,7 @@ func TestRandomMiningEligibility(t *testing.T) {
 				ProviderID: "offline",
 				Attestation: &TierAttestation{
 					Tier:      Tier2ConfidentialVM,
+					Method:    MethodSEVSNP,
 					IssuedAt:  now.Add(-1 * time.Hour),
 					ExpiresAt: now.Add(23 * time.Hour),
 				},
@@ -340,7 +387,7 @@ func TestRandomMiningEligibility(t *testing.T) {
 				LastHeartbeat: now.Add(-10 * time.Minute), // Too old
 			},
 			eligible: false,
-			reason:   "provider offline",
+			reason:   EligibilityOffline,
 		},
 		{
 			name: "No attestation",
@@ -351,7 +398,7 @@ func TestRandomMiningEligibility(t *testing.T) {
 				LastHeartbeat: now,
 			},
 			eligible: false,
-			reason:   "no attestation",
+			reason:   EligibilityNoAttestation,
 		},
 		{
 			name: "Expired attestation",
@@ -359,6 +406,7 @@ func TestRandomMiningEligibility(t *testing.T) {
 				ProviderID: "expired",
 				Attestation: &TierAttestation{
 					Tier:      Tier2ConfidentialVM,
+					Method:    MethodSEVSNP,
 					IssuedAt:  now.Add(-25 * time.Hour),
 					ExpiresAt: now.Add(-1 * time.Hour), // Expired
 				},
@@ -366,7 +414,7 @@ func TestRandomMiningEligibility(t *testing.T) {
 				LastHeartbeat: now,
 			},
 			eligible: false,
-			reason:   "attestation expired",
+			reason:   EligibilityAttestationExpired,
 		},
 		{
 			name: "Insufficient stake",
@@ -374,6 +422,7 @@ func TestRandomMiningEligibility(t *testing.T) {
 				ProviderID: "low-stake",
 				Attestation: &TierAttestation{
 					Tier:      Tier1GPUNativeCC, // Requires 100k LUX
+					Method:    MethodNVTrust,
 					IssuedAt:  now.Add(-1 * time.Hour),
 					ExpiresAt: now.Add(5 * time.Hour),
 				},
@@ -381,7 +430,7 @@ func TestRandomMiningEligibility(t *testing.T) {
 				LastHeartbeat: now,
 			},
 			eligible: false,
-			reason:   "insufficient stake",
+			reason:   EligibilityInsufficientStake,
 		},
 	}
 
@@ -392,12 +441,35 @@ func TestRandomMiningEligibility(t *testing.T) {
 				t.Errorf("RandomMiningEligibility() eligible = %v, want %v", eligible, tt.eligible)
 			}
 			if reason != tt.reason {
-				t.Errorf("RandomMiningEligibility() reason = %s, want %s", reason, tt.reason)
+				t.Errorf("RandomMiningEligibility() reason = %v, want %v", reason, tt.reason)
 			}
 		})
 	}
 }
 
+func TestEligibilityReasonString(t *testing.T) {
+	tests := []struct {
+		reason   EligibilityReason
+		expected string
+	}{
+		{EligibilityOK, "eligible"},
+		{EligibilityNilProvider, "provider is nil"},
+		{EligibilityOffline, "provider offline"},
+		{EligibilityNoAttestation, "no attestation"},
+		{EligibilityAttestationExpired, "attestation expired"},
+		{EligibilityInsufficientStake, "insufficient stake"},
+		{EligibilityBlocked, "provider blocked"},
+		{EligibilityNotAllowed, "provider not allowlisted"},
+		{EligibilityReason(99), "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.reason.String(); got != tt.expected {
+			t.Errorf("EligibilityReason(%d).String() = %s, want %s", tt.reason, got, tt.expected)
+		}
+	}
+}
+
 func TestEpochRewardSummary(t *testing.T) {
 	pool := NewAIRewardPool(1 * time.Hour)
 	now := time.Now()
@@ -408,6 +480,7 @@ func TestEpochRewardSummary(t *testing.T) {
 			ProviderID: "t1",
 			Attestation: &TierAttestation{
 				Tier:      Tier1GPUNativeCC,
+				Method:    MethodNVTrust,
 				IssuedAt:  now.Add(-1 * time.Hour),
 				ExpiresAt: now.Add(5 * time.Hour),
 			},
@@ -420,6 +493,7 @@ func TestEpochRewardSummary(t *testing.T) {
 			ProviderID: "t2",
 			Attestation: &TierAttestation{
 				Tier:      Tier2ConfidentialVM,
+				Method:    MethodSEVSNP,
 				IssuedAt:  now.Add(-1 * time.Hour),
 				ExpiresAt: now.Add(23 * time.Hour),
 			},
@@ -465,6 +539,54 @@ func TestEpochRewardSummary(t *testing.T) {
 	}
 }
 
+func TestSimulateEpoch(t *testing.T) {
+	pool := NewAIRewardPool(1 * time.Hour)
+	pool.EpochNumber = 7
+	pool.TotalPoolLUX = big.NewInt(42)
+	now := time.Now()
+	pool.RegisterProvider(&AIProvider{
+		ProviderID: "t1",
+		Attestation: &TierAttestation{
+			Tier:      Tier1GPUNativeCC,
+			Method:    MethodNVTrust,
+			IssuedAt:  now.Add(-1 * time.Hour),
+			ExpiresAt: now.Add(5 * time.Hour),
+		},
+		MaxModelingLevel: ModelingLevelInferenceHeavy,
+		StakeLUX:         100_000,
+		LastHeartbeat:    now,
+		ReputationScore:  0.9,
+	})
+
+	totalRewards := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	simulated := pool.SimulateEpoch(totalRewards, 5*time.Minute)
+
+	if pool.TotalPoolLUX.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("SimulateEpoch mutated TotalPoolLUX = %s, want 42", pool.TotalPoolLUX)
+	}
+	if pool.EpochNumber != 7 || simulated.EpochNumber != 7 {
+		t.Errorf("EpochNumber = %d (summary %d), want 7", pool.EpochNumber, simulated.EpochNumber)
+	}
+
+	actual := pool.CalculateEpochRewards(totalRewards, 5*time.Minute)
+	if simulated.AIPoolRewardsLUX.Cmp(actual.AIPoolRewardsLUX) != 0 ||
+		simulated.ParticipationRewardsLUX.Cmp(actual.ParticipationRewardsLUX) != 0 ||
+		len(simulated.ProviderRewards) != len(actual.ProviderRewards) {
+		t.Error("SimulateEpoch should match CalculateEpochRewards")
+	}
+
+	// Hypothetical share settings on a clone leave the pool untouched
+	tuned := pool.Clone()
+	tuned.ParticipationShare = 0.5
+	preview := tuned.SimulateEpoch(totalRewards, 5*time.Minute)
+	if pool.ParticipationShare != 0.30 {
+		t.Errorf("Clone shares settings with pool: ParticipationShare = %v", pool.ParticipationShare)
+	}
+	if preview.ParticipationRewardsLUX.Cmp(actual.ParticipationRewardsLUX) <= 0 {
+		t.Error("higher participation share should preview a larger participation pool")
+	}
+}
+
 func BenchmarkParticipationRewards(b *testing.B) {
 	pool := NewAIRewardPool(1 * time.Hour)
 	now := time.Now()
@@ -476,6 +598,7 @@ func BenchmarkParticipationRewards(b *testing.B) {
 			ProviderID: string(rune('A' + i)),
 			Attestation: &TierAttestation{
 				Tier:      tier,
+				Method:    acceptedMethod(tier),
 				IssuedAt:  now.Add(-1 * time.Hour),
 				ExpiresAt: now.Add(tier.AttestationValidity()),
 			},
@@ -559,6 +682,7 @@ func TestEffectiveTierAllCases(t *testing.T) {
 				ProviderID: "t1",
 				Attestation: &TierAttestation{
 					Tier:      Tier1GPUNativeCC,
+					Method:    MethodNVTrust,
 					IssuedAt:  now.Add(-1 * time.Hour),
 					ExpiresAt: now.Add(5 * time.Hour),
 				},
@@ -571,6 +695,7 @@ func TestEffectiveTierAllCases(t *testing.T) {
 				ProviderID: "t2",
 				Attestation: &TierAttestation{
 					Tier:      Tier2ConfidentialVM,
+					Method:    MethodSEVSNP,
 					IssuedAt:  now.Add(-1 * time.Hour),
 					ExpiresAt: now.Add(23 * time.Hour),
 				},
@@ -583,6 +708,7 @@ func TestEffectiveTierAllCases(t *testing.T) {
 				ProviderID: "t3",
 				Attestation: &TierAttestation{
 					Tier:      Tier3DeviceTEE,
+					Method:    MethodSecureEnclave,
 					IssuedAt:  now.Add(-1 * time.Hour),
 					ExpiresAt: now.Add(6 * 24 * time.Hour),
 				},
@@ -615,6 +741,7 @@ func TestEffectiveTierAllCases(t *testing.T) {
 				ProviderID: "expired",
 				Attestation: &TierAttestation{
 					Tier:      Tier1GPUNativeCC,
+					Method:    MethodNVTrust,
 					IssuedAt:  now.Add(-10 * time.Hour),
 					ExpiresAt: now.Add(-1 * time.Hour), // Expired
 				},
@@ -627,6 +754,7 @@ func TestEffectiveTierAllCases(t *testing.T) {
 				ProviderID: "future",
 				Attestation: &TierAttestation{
 					Tier:      Tier1GPUNativeCC,
+					Method:    MethodNVTrust,
 					IssuedAt:  now.Add(1 * time.Hour), // Issued in future
 					ExpiresAt: now.Add(7 * time.Hour),
 				},
@@ -758,6 +886,63 @@ func TestRegisterProviderErrorCases(t *testing.T) {
 	}
 }
 
+// TestRegisterProviderVRAMCheck tests that claimed modeling levels are
+// validated against the attested GPU memory
+func TestRegisterProviderVRAMCheck(t *testing.T) {
+	now := time.Now()
+	newProvider := func(id string, level ModelingLevel, memBytes uint64) *AIProvider {
+		return &AIProvider{
+			ProviderID: id,
+			Attestation: &TierAttestation{
+				Tier:      Tier2ConfidentialVM,
+				Method:    MethodSEVSNP,
+				IssuedAt:  now.Add(-1 * time.Hour),
+				ExpiresAt: now.Add(23 * time.Hour),
+				HardwareInfo: &HardwareInfo{
+					Vendor:     "NVIDIA",
+					Model:      "RTX 4090",
+					MemorySize: memBytes,
+				},
+			},
+			MaxModelingLevel: level,
+			StakeLUX:         50_000,
+			LastHeartbeat:    now,
+		}
+	}
+
+	const mib = 1024 * 1024
+	tests := []struct {
+		name     string
+		provider *AIProvider
+		wantErr  bool
+	}{
+		{"24GB card at Heavy level", newProvider("heavy-24", ModelingLevelInferenceHeavy, 24564*mib), true},
+		{"24GB card at Standard level", newProvider("std-24", ModelingLevelInferenceStandard, 24564*mib), false},
+		{"80GB H100 at Heavy level", newProvider("heavy-80", ModelingLevelInferenceHeavy, 81559*mib), false},
+		{"8GB card at Training level", newProvider("train-8", ModelingLevelTraining, 8192*mib), true},
+		{"No memory reported", newProvider("unknown", ModelingLevelInferenceHeavy, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := NewAIRewardPool(1 * time.Hour)
+			err := pool.RegisterProvider(tt.provider)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInsufficientVRAM) {
+					t.Errorf("RegisterProvider() error = %v, want %v", err, ErrInsufficientVRAM)
+				}
+				if _, exists := pool.Providers[tt.provider.ProviderID]; exists {
+					t.Error("Rejected provider should not be registered")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("RegisterProvider() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
 // TestCalculateParticipationRewardsAllPaths tests all code paths
 func TestCalculateParticipationRewardsAllPaths(t *testing.T) {
 	now := time.Now()
@@ -779,6 +964,7 @@ func TestCalculateParticipationRewardsAllPaths(t *testing.T) {
 			ProviderID: "offline",
 			Attestation: &TierAttestation{
 				Tier:      Tier2ConfidentialVM,
+				Method:    MethodSEVSNP,
 				IssuedAt:  now.Add(-1 * time.Hour),
 				ExpiresAt: now.Add(23 * time.Hour),
 			},
@@ -813,6 +999,7 @@ func TestCalculateParticipationRewardsAllPaths(t *testing.T) {
 			ProviderID: "expired",
 			Attestation: &TierAttestation{
 				Tier:      Tier2ConfidentialVM,
+				Method:    MethodSEVSNP,
 				IssuedAt:  now.Add(-25 * time.Hour),
 				ExpiresAt: now.Add(-1 * time.Hour), // Expired
 			},
@@ -834,6 +1021,7 @@ func TestCalculateParticipationRewardsAllPaths(t *testing.T) {
 			ProviderID: "online",
 			Attestation: &TierAttestation{
 				Tier:      Tier2ConfidentialVM,
+				Method:    MethodSEVSNP,
 				IssuedAt:  now.Add(-1 * time.Hour),
 				ExpiresAt: now.Add(23 * time.Hour),
 			},
@@ -848,6 +1036,7 @@ func TestCalculateParticipationRewardsAllPaths(t *testing.T) {
 			ProviderID: "offline",
 			Attestation: &TierAttestation{
 				Tier:      Tier1GPUNativeCC,
+				Method:    MethodNVTrust,
 				IssuedAt:  now.Add(-1 * time.Hour),
 				ExpiresAt: now.Add(5 * time.Hour),
 			},
@@ -875,6 +1064,7 @@ func TestCalculateParticipationRewardsAllPaths(t *testing.T) {
 			ProviderID: "solo",
 			Attestation: &TierAttestation{
 				Tier:      Tier2ConfidentialVM,
+				Method:    MethodSEVSNP,
 				IssuedAt:  now.Add(-1 * time.Hour),
 				ExpiresAt: now.Add(23 * time.Hour),
 			},
@@ -959,6 +1149,7 @@ func TestRewardWeightEdgeCases(t *testing.T) {
 				ProviderID: "high-stake",
 				Attestation: &TierAttestation{
 					Tier:      Tier1GPUNativeCC,
+					Method:    MethodNVTrust,
 					IssuedAt:  now.Add(-1 * time.Hour),
 					ExpiresAt: now.Add(5 * time.Hour),
 				},
@@ -975,6 +1166,7 @@ func TestRewardWeightEdgeCases(t *testing.T) {
 				ProviderID: "veteran",
 				Attestation: &TierAttestation{
 					Tier:      Tier2ConfidentialVM,
+					Method:    MethodSEVSNP,
 					IssuedAt:  now.Add(-1 * time.Hour),
 					ExpiresAt: now.Add(23 * time.Hour),
 				},
@@ -991,6 +1183,7 @@ func TestRewardWeightEdgeCases(t *testing.T) {
 				ProviderID: "new-provider",
 				Attestation: &TierAttestation{
 					Tier:      Tier3DeviceTEE,
+					Method:    MethodSecureEnclave,
 					IssuedAt:  now.Add(-1 * time.Hour),
 					ExpiresAt: now.Add(6 * 24 * time.Hour),
 				},
@@ -1007,6 +1200,7 @@ func TestRewardWeightEdgeCases(t *testing.T) {
 				ProviderID: "trusted",
 				Attestation: &TierAttestation{
 					Tier:      Tier3DeviceTEE,
+					Method:    MethodSecureEnclave,
 					IssuedAt:  now.Add(-1 * time.Hour),
 					ExpiresAt: now.Add(6 * 24 * time.Hour),
 				},
@@ -1112,6 +1306,108 @@ func TestIsOnline(t *testing.T) {
 	}
 }
 
+// TestIsEligibleAgreesWithRewards checks that participation rewards and
+// random mining eligibility both follow AIProvider.IsEligible
+func TestIsEligibleAgreesWithRewards(t *testing.T) {
+	now := time.Now()
+	maxAge := 5 * time.Minute
+	attestation := func(expires time.Time) *TierAttestation {
+		return &TierAttestation{Tier: Tier2ConfidentialVM, Method: MethodSEVSNP, IssuedAt: now.Add(-48 * time.Hour), ExpiresAt: expires}
+	}
+
+	tests := []struct {
+		name     string
+		provider *AIProvider
+		reason   EligibilityReason
+	}{
+		{"eligible", &AIProvider{Attestation: attestation(now.Add(time.Hour)), LastHeartbeat: now}, EligibilityOK},
+		{"offline", &AIProvider{Attestation: attestation(now.Add(time.Hour)), LastHeartbeat: now.Add(-time.Hour)}, EligibilityOffline},
+		{"no attestation", &AIProvider{LastHeartbeat: now}, EligibilityNoAttestation},
+		{"expired", &AIProvider{Attestation: attestation(now.Add(-time.Hour)), LastHeartbeat: now}, EligibilityAttestationExpired},
+		{"in grace", &AIProvider{Attestation: attestation(now.Add(-time.Hour)), LastHeartbeat: now, TierGracePeriod: 2 * time.Hour}, EligibilityOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.provider.ProviderID = tt.name
+			tt.provider.StakeLUX = 1_000_000
+			tt.provider.MaxModelingLevel = ModelingLevelInferenceStandard
+			ok, reason := tt.provider.IsEligible(maxAge)
+			if ok != (tt.reason == EligibilityOK) || reason != tt.reason {
+				t.Errorf("IsEligible() = %v, %v, want %v", ok, reason, tt.reason)
+			}
+			if okRM, reasonRM := RandomMiningEligibility(tt.provider, maxAge); okRM != ok || reasonRM != reason {
+				t.Errorf("RandomMiningEligibility() = %v, %v, want %v, %v", okRM, reasonRM, ok, reason)
+			}
+
+			pool := NewAIRewardPool(time.Hour)
+			pool.TotalPoolLUX = big.NewInt(1_000_000)
+			pool.Providers[tt.provider.ProviderID] = tt.provider
+			if paid := len(pool.CalculateParticipationRewards(maxAge)) == 1; paid != ok {
+				t.Errorf("participation reward paid = %v, want %v", paid, ok)
+			}
+		})
+	}
+
+	var nilProvider *AIProvider
+	if ok, reason := nilProvider.IsEligible(maxAge); ok || reason != EligibilityNilProvider {
+		t.Errorf("nil IsEligible() = %v, %v, want nil provider", ok, reason)
+	}
+}
+
+// TestPoolClock checks that heartbeat, attestation expiry and grace period
+// checks follow the pool's clock rather than the system clock
+func TestPoolClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock := clock.NewMock(start)
+	maxAge := 5 * time.Minute
+
+	pool := NewAIRewardPool(time.Hour)
+	pool.Clock = mock
+	provider := &AIProvider{
+		ProviderID:       "p",
+		StakeLUX:         1_000_000,
+		MaxModelingLevel: ModelingLevelInferenceStandard,
+		LastHeartbeat:    start,
+		TierGracePeriod:  2 * time.Hour,
+		Attestation: &TierAttestation{
+			Tier:       Tier2ConfidentialVM,
+			Method:     MethodSEVSNP,
+			TrustScore: 80,
+			IssuedAt:   start.Add(-time.Hour),
+			ExpiresAt:  start.Add(time.Hour),
+		},
+	}
+	pool.Providers[provider.ProviderID] = provider
+
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		heartbeat bool
+		reason    EligibilityReason
+		scored    bool
+	}{
+		{"attested", 0, false, EligibilityOK, true},
+		{"heartbeat too old", maxAge, false, EligibilityOffline, true},
+		{"expired in grace", time.Hour, true, EligibilityOK, false},
+		{"past grace", 2 * time.Hour, true, EligibilityAttestationExpired, false},
+	}
+	for _, tt := range tests {
+		now := mock.Advance(tt.advance)
+		if tt.heartbeat {
+			provider.LastHeartbeat = now
+		}
+		if ok, reason := pool.RandomMiningEligibility(provider, maxAge); ok != (tt.reason == EligibilityOK) || reason != tt.reason {
+			t.Errorf("%s: RandomMiningEligibility() = %v, %v, want %v", tt.name, ok, reason, tt.reason)
+		}
+		if scored := len(pool.ScoreDistribution()[Tier2ConfidentialVM]) == 1; scored != tt.scored {
+			t.Errorf("%s: scored = %v, want %v", tt.name, scored, tt.scored)
+		}
+		if sim := pool.Clone(); sim.now() != now {
+			t.Errorf("%s: Clone() now = %v, want %v", tt.name, sim.now(), now)
+		}
+	}
+}
+
 // TestNewAIRewardPool verifies pool initialization
 func TestNewAIRewardPool(t *testing.T) {
 	duration := 2 * time.Hour
@@ -1136,3 +1432,48 @@ func TestNewAIRewardPool(t *testing.T) {
 		t.Errorf("TaskShare = %f, want 0.70", pool.TaskShare)
 	}
 }
+
+func TestScoreDistribution(t *testing.T) {
+	now := time.Now()
+	pool := NewAIRewardPool(1 * time.Hour)
+
+	add := func(id string, tier CCTier, score uint8, expires time.Time) {
+		pool.Providers[id] = &AIProvider{
+			ProviderID: id,
+			Attestation: &TierAttestation{
+				Tier:       tier,
+				Method:     acceptedMethod(tier),
+				TrustScore: score,
+				IssuedAt:   now.Add(-1 * time.Hour),
+				ExpiresAt:  expires,
+			},
+			StakeLUX:      100_000,
+			LastHeartbeat: now,
+		}
+	}
+	add("t1-high", Tier1GPUNativeCC, 100, now.Add(time.Hour))
+	add("t1-low", Tier1GPUNativeCC, 91, now.Add(time.Hour))
+	add("t2", Tier2ConfidentialVM, 75, now.Add(time.Hour))
+	add("t2-expired", Tier2ConfidentialVM, 80, now.Add(-time.Minute))
+	pool.Providers["unattested"] = &AIProvider{ProviderID: "unattested", StakeLUX: 1_000}
+
+	dist := pool.ScoreDistribution()
+	if got := dist[Tier1GPUNativeCC]; len(got) != 2 || got[0] != 91 || got[1] != 100 {
+		t.Errorf("Tier1 scores = %v, want [91 100]", got)
+	}
+	if got := dist[Tier2ConfidentialVM]; len(got) != 1 || got[0] != 75 {
+		t.Errorf("Tier2 scores = %v, want [75] (expired omitted)", got)
+	}
+	if _, ok := dist[Tier4Standard]; ok {
+		t.Error("Unattested provider should not contribute a score")
+	}
+
+	hist := pool.ScoreHistograms([]uint8{90, 95, 100})
+	t1 := hist[Tier1GPUNativeCC]
+	if t1 == nil || t1.Count != 2 {
+		t.Fatalf("Tier1 histogram = %+v, want 2 observations", t1)
+	}
+	if t1.Buckets[0].Count != 0 || t1.Buckets[1].Count != 1 || t1.Buckets[2].Count != 2 {
+		t.Errorf("Tier1 buckets = %+v", t1.Buckets)
+	}
+}
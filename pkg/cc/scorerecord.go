@@ -0,0 +1,102 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// scoreRecordDomain separates score record signatures from other messages
+// signed with the node key
+const scoreRecordDomain = "lux-trust-score-record-v1"
+
+var ErrInvalidScoreRecord = errors.New("invalid trust score record")
+
+// ScoreRecord is a trust score a node assigned to a provider, with the
+// inputs and weights it was computed from, signed by the node's ed25519
+// key. A provider can hand it to a third party, who checks it offline with
+// VerifyScoreRecord, e.g. to carry reputation to another network or to
+// settle a dispute about a score.
+type ScoreRecord struct {
+	ProviderID string            `json:"provider_id"`
+	Input      *TrustScoreInput  `json:"input"`
+	Weights    TrustScoreWeight  `json:"weights"`
+	Result     *TrustScoreResult `json:"result"`
+	IssuedAt   time.Time         `json:"issued_at"`
+	NodeKey    ed25519.PublicKey `json:"node_key"`
+	Signature  []byte            `json:"signature"`
+}
+
+// SignRecord packages r with the input and weights it was calculated from
+// into a ScoreRecord signed by nodeKey. issuedAt is recorded in UTC.
+func (r *TrustScoreResult) SignRecord(providerID string, input *TrustScoreInput, weights TrustScoreWeight, nodeKey ed25519.PrivateKey, issuedAt time.Time) (*ScoreRecord, error) {
+	if input == nil {
+		return nil, fmt.Errorf("%w: nil input", ErrInvalidScoreRecord)
+	}
+	if len(nodeKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: node key is %d bytes, want %d", ErrInvalidScoreRecord, len(nodeKey), ed25519.PrivateKeySize)
+	}
+	record := &ScoreRecord{
+		ProviderID: providerID,
+		Input:      input,
+		Weights:    weights,
+		Result:     r,
+		IssuedAt:   issuedAt.UTC(),
+		NodeKey:    nodeKey.Public().(ed25519.PublicKey),
+	}
+	digest, err := record.Digest()
+	if err != nil {
+		return nil, err
+	}
+	record.Signature = ed25519.Sign(nodeKey, digest[:])
+	return record, nil
+}
+
+// Digest returns the SHA-256 the node signs: a domain tag followed by the
+// JSON encoding of every field but the signature
+func (rec *ScoreRecord) Digest() ([32]byte, error) {
+	signed := *rec
+	signed.Signature = nil
+	data, err := json.Marshal(&signed)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("%w: %v", ErrInvalidScoreRecord, err)
+	}
+	return sha256.Sum256(append([]byte(scoreRecordDomain), data...)), nil
+}
+
+// VerifyScoreRecord checks that record was signed by nodePubKey and that
+// its result is what its input and weights produce, so neither the score
+// nor the reasons for it were altered after signing
+func VerifyScoreRecord(record *ScoreRecord, nodePubKey ed25519.PublicKey) error {
+	if record == nil || record.Input == nil || record.Result == nil {
+		return fmt.Errorf("%w: missing input or result", ErrInvalidScoreRecord)
+	}
+	if len(nodePubKey) != ed25519.PublicKeySize || !bytes.Equal(record.NodeKey, nodePubKey) {
+		return fmt.Errorf("%w: not issued by this node", ErrInvalidScoreRecord)
+	}
+	digest, err := record.Digest()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(nodePubKey, digest[:], record.Signature) {
+		return fmt.Errorf("%w: bad signature", ErrInvalidScoreRecord)
+	}
+
+	// Compare encodings, which is how the result was signed
+	want, err := json.Marshal(CalculateTrustScoreWithWeights(record.Input, record.Weights))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidScoreRecord, err)
+	}
+	got, _ := json.Marshal(record.Result)
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("%w: result does not match its input", ErrInvalidScoreRecord)
+	}
+	return nil
+}
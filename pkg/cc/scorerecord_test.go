@@ -0,0 +1,86 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScoreRecord(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, otherPriv, _ := ed25519.GenerateKey(nil)
+
+	input := BaselineTrustInput(Tier1GPUNativeCC, &HardwareCapability{
+		GPUVendor:    VendorNVIDIA,
+		GPUModel:     "H100",
+		GPUMemoryMB:  81559,
+		GPUCCEnabled: true,
+		Fabric:       &GPUFabric{Links: 18, BandwidthGBs: 900, DomainSize: 8},
+		MaxTier:      Tier1GPUNativeCC,
+	})
+	input.ReputationScore = 0.87
+	input.AttestationAge = 90 * time.Minute
+	weights := DefaultWeights()
+	result := CalculateTrustScoreWithWeights(input, weights)
+
+	issued := time.Date(2025, 6, 1, 12, 0, 0, 123456789, time.FixedZone("CEST", 2*60*60))
+	record, err := result.SignRecord("provider-1", input, weights, priv, issued)
+	if err != nil {
+		t.Fatalf("SignRecord() error = %v", err)
+	}
+	if !record.IssuedAt.Equal(issued) || record.IssuedAt.Location() != time.UTC {
+		t.Errorf("IssuedAt = %v, want %v in UTC", record.IssuedAt, issued)
+	}
+
+	// A third party receives the record as JSON
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	decode := func() *ScoreRecord {
+		var rec ScoreRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		return &rec
+	}
+	if err := VerifyScoreRecord(decode(), pub); err != nil {
+		t.Fatalf("VerifyScoreRecord() error = %v", err)
+	}
+
+	forged, _ := (&TrustScoreResult{TotalScore: 100, Tier: Tier1GPUNativeCC}).SignRecord("provider-1", input, weights, priv, issued)
+
+	tests := []struct {
+		name   string
+		record func() *ScoreRecord
+		key    ed25519.PublicKey
+	}{
+		{"wrong node key", decode, otherPub},
+		{"raised score", func() *ScoreRecord { r := decode(); r.Result.TotalScore = 100; return r }, pub},
+		{"altered input", func() *ScoreRecord { r := decode(); r.Input.SlashingEvents = 0; r.Input.TasksFailed = 99; return r }, pub},
+		{"other provider", func() *ScoreRecord { r := decode(); r.ProviderID = "provider-2"; return r }, pub},
+		{"backdated", func() *ScoreRecord { r := decode(); r.IssuedAt = r.IssuedAt.Add(-time.Hour); return r }, pub},
+		{"re-signed by another node", func() *ScoreRecord {
+			r, _ := result.SignRecord("provider-1", input, weights, otherPriv, issued)
+			return r
+		}, pub},
+		{"result not derived from input", func() *ScoreRecord { return forged }, pub},
+		{"nil", func() *ScoreRecord { return nil }, pub},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyScoreRecord(tt.record(), tt.key); !errors.Is(err, ErrInvalidScoreRecord) {
+				t.Errorf("VerifyScoreRecord() error = %v, want %v", err, ErrInvalidScoreRecord)
+			}
+		})
+	}
+
+	if _, err := result.SignRecord("provider-1", input, weights, priv[:10], issued); !errors.Is(err, ErrInvalidScoreRecord) {
+		t.Errorf("SignRecord() with short key error = %v, want %v", err, ErrInvalidScoreRecord)
+	}
+}
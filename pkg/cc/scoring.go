@@ -4,6 +4,8 @@
 package cc
 
 import (
+	"fmt"
+	"sort"
 	"time"
 )
 
@@ -35,10 +37,11 @@ type TrustScoreInput struct {
 	TEEIOEnabled         bool  // TEE-IO for Blackwell
 	RIMVerified          bool  // Reference Integrity Manifest verified
 	HardwareCapabilities *HardwareCapability
+	ModelingLevel        ModelingLevel // Workload scored for; fabric counts at training and above
 
 	// Attestation-based inputs
 	AttestationAge    time.Duration // Time since last attestation
-	AttestationMethod string        // "nvtrust", "sev-snp", "tdx", "software"
+	AttestationMethod string        // One of the Method* constants
 	LocalVerification bool          // True if locally verified (no cloud)
 	CertChainValid    bool          // Certificate chain validated
 
@@ -128,6 +131,12 @@ func CalculateTrustScoreWithWeights(input *TrustScoreInput, weights TrustScoreWe
 	result.MinimumRequired = minScore
 	result.MeetsMinimum = result.TotalScore >= minScore
 
+	// Failing GPU memory can corrupt results regardless of score
+	if hw := input.HardwareCapabilities; hw != nil && hw.ECC.Failing() {
+		result.MeetsMinimum = false
+		result.Warnings = append(result.Warnings, fmt.Sprintf("GPU memory failing: %s", hw.ECC))
+	}
+
 	return result
 }
 
@@ -173,12 +182,19 @@ func calculateHardwareScore(input *TrustScoreInput) uint8 {
 		if input.HardwareCapabilities.MIGSupported {
 			score += 1 // +1 for MIG support
 		}
-		if input.HardwareCapabilities.GPUMemoryMB > 80000 { // >80GB
+		if input.HardwareCapabilities.MemoryBytes() > highMemoryBytes {
 			score += 2 // +2 for high memory
 		}
+		if input.ModelingLevel >= ModelingLevelTraining && input.HardwareCapabilities.Fabric.Coherent() {
+			score += fabricBonus // +3 for NVLink fabric when training
+		}
+		if input.HardwareCapabilities.ECC.Degraded() {
+			score -= eccPenalty // -10 for uncorrectable ECC errors or pending remaps
+		}
 	}
 
-	// Cap at 100 (will be weighted to 40%)
+	// Clamp to [0, 100] (will be weighted to 40%)
+	score = max(0, score)
 	if score > 100 {
 		score = 100
 	}
@@ -207,13 +223,13 @@ func calculateAttestationScore(input *TrustScoreInput) uint8 {
 
 	// Verification method bonus
 	switch input.AttestationMethod {
-	case "nvtrust":
+	case MethodNVTrust:
 		score += 10 // Best: local GPU attestation
-	case "sev-snp", "tdx":
+	case MethodSEVSNP, MethodTDX:
 		score += 8 // Good: CPU TEE attestation
-	case "cca":
+	case MethodCCA:
 		score += 6 // ARM CCA
-	case "secure-enclave":
+	case MethodSecureEnclave:
 		score += 5 // Apple Secure Enclave
 	default:
 		score += 2 // Software attestation
@@ -323,21 +339,27 @@ func calculateUptimeScore(input *TrustScoreInput) uint8 {
 // QuickTrustScore calculates a quick trust score with minimal inputs
 // Useful for initial tier classification before full attestation
 func QuickTrustScore(tier CCTier, cap *HardwareCapability) uint8 {
+	return CalculateTrustScore(BaselineTrustInput(tier, cap)).TotalScore
+}
+
+// BaselineTrustInput builds the trust score input for a freshly onboarded
+// provider: locally verified, with neutral reputation and no uptime yet.
+// AIRewardPool.TrustInput adds the uptime of a provider in a pool.
+func BaselineTrustInput(tier CCTier, cap *HardwareCapability) *TrustScoreInput {
 	input := &TrustScoreInput{
 		Tier:                 tier,
 		HardwareCapabilities: cap,
 		AttestationAge:       0,
 		LocalVerification:    true,
-		UptimePercentage:     100.0,
 		ReputationScore:      0.5,
 	}
 
 	// Set GPU generation based on model
 	if cap != nil {
 		switch {
-		case cap.ComputeCap == "9.0": // Blackwell/Hopper
+		case cap.ComputeCap.AtLeast(ComputeCapHopper): // Blackwell/Hopper
 			input.GPUGeneration = 10
-		case cap.ComputeCap == "8.9": // Ada
+		case cap.ComputeCap.AtLeast(ComputeCapAda):
 			input.GPUGeneration = 9
 		default:
 			input.GPUGeneration = 5
@@ -345,9 +367,7 @@ func QuickTrustScore(tier CCTier, cap *HardwareCapability) uint8 {
 		input.CCFeaturesEnabled = cap.GPUCCEnabled
 		input.TEEIOEnabled = cap.TEEIOSupported
 	}
-
-	result := CalculateTrustScore(input)
-	return result.TotalScore
+	return input
 }
 
 // ValidateProviderScore checks if a provider meets minimum score requirements
@@ -368,7 +388,9 @@ func AdjustScoreForSlashing(currentScore uint8, slashingSeverity float64) uint8
 	return currentScore - reduction
 }
 
-// RecoverScoreAfterGoodBehavior increases trust score after good behavior
+// RecoverScoreAfterGoodBehavior increases trust score after good behavior.
+// It applies a single recovery step with no notion of time; providers in a
+// reward pool recover through AIRewardPool.AdvanceEpoch instead.
 func RecoverScoreAfterGoodBehavior(currentScore, maxScore uint8, recoveryRate float64) uint8 {
 	recovery := uint8(float64(maxScore-currentScore) * recoveryRate)
 	newScore := currentScore + recovery
@@ -377,3 +399,57 @@ func RecoverScoreAfterGoodBehavior(currentScore, maxScore uint8, recoveryRate fl
 	}
 	return newScore
 }
+
+// DefaultScoreBuckets are the histogram upper bounds used for trust score
+// monitoring. They align with the tier floors and ceilings (49/69/89) so
+// operators can see how close providers sit to each boundary.
+var DefaultScoreBuckets = []uint8{10, 20, 30, 40, 49, 50, 60, 69, 70, 80, 89, 90, 95, 100}
+
+// HistogramBucket is a single cumulative histogram bucket.
+// Count is the number of observations less than or equal to UpperBound.
+type HistogramBucket struct {
+	UpperBound uint8  `json:"le"`
+	Count      uint64 `json:"count"`
+}
+
+// ScoreHistogram is a Prometheus-style cumulative histogram of trust scores
+type ScoreHistogram struct {
+	// Buckets are cumulative and sorted by UpperBound; the implicit +Inf
+	// bucket equals Count.
+	Buckets []HistogramBucket `json:"buckets"`
+
+	// Count is the total number of observations
+	Count uint64 `json:"count"`
+
+	// Sum is the sum of all observed scores
+	Sum uint64 `json:"sum"`
+}
+
+// NewScoreHistogram builds a cumulative histogram of scores over the given
+// upper bounds. Bounds are sorted and de-duplicated; nil uses DefaultScoreBuckets.
+func NewScoreHistogram(scores []uint8, bounds []uint8) *ScoreHistogram {
+	if bounds == nil {
+		bounds = DefaultScoreBuckets
+	}
+	sorted := append([]uint8(nil), bounds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	h := &ScoreHistogram{Buckets: make([]HistogramBucket, 0, len(sorted))}
+	for i, b := range sorted {
+		if i > 0 && b == sorted[i-1] {
+			continue
+		}
+		h.Buckets = append(h.Buckets, HistogramBucket{UpperBound: b})
+	}
+
+	for _, score := range scores {
+		h.Count++
+		h.Sum += uint64(score)
+		for i := range h.Buckets {
+			if score <= h.Buckets[i].UpperBound {
+				h.Buckets[i].Count++
+			}
+		}
+	}
+	return h
+}
@@ -1527,3 +1527,44 @@ func TestTotalScoreExceedsMax(t *testing.T) {
 		t.Errorf("Tier3 score %d exceeds max %d", result.TotalScore, maxScore)
 	}
 }
+
+func TestNewScoreHistogram(t *testing.T) {
+	scores := []uint8{12, 45, 49, 70, 88, 90, 100}
+	h := NewScoreHistogram(scores, []uint8{49, 89, 100, 49})
+
+	if h.Count != 7 {
+		t.Errorf("Count = %d, want 7", h.Count)
+	}
+	if h.Sum != 454 {
+		t.Errorf("Sum = %d, want 454", h.Sum)
+	}
+
+	want := []HistogramBucket{
+		{UpperBound: 49, Count: 3},
+		{UpperBound: 89, Count: 5},
+		{UpperBound: 100, Count: 7},
+	}
+	if len(h.Buckets) != len(want) {
+		t.Fatalf("got %d buckets, want %d (duplicates should be dropped)", len(h.Buckets), len(want))
+	}
+	for i, b := range want {
+		if h.Buckets[i] != b {
+			t.Errorf("bucket %d = %+v, want %+v", i, h.Buckets[i], b)
+		}
+	}
+}
+
+func TestNewScoreHistogramDefaults(t *testing.T) {
+	h := NewScoreHistogram(nil, nil)
+	if len(h.Buckets) != len(DefaultScoreBuckets) {
+		t.Errorf("got %d buckets, want %d", len(h.Buckets), len(DefaultScoreBuckets))
+	}
+	if h.Count != 0 || h.Sum != 0 {
+		t.Errorf("empty histogram has Count=%d Sum=%d", h.Count, h.Sum)
+	}
+	for i := 1; i < len(h.Buckets); i++ {
+		if h.Buckets[i].UpperBound <= h.Buckets[i-1].UpperBound {
+			t.Errorf("buckets not strictly increasing at %d", i)
+		}
+	}
+}
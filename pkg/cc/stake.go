@@ -0,0 +1,54 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidStakeSchedule is returned when a stake schedule is not monotonic
+var ErrInvalidStakeSchedule = errors.New("invalid stake schedule")
+
+// stakeTiers lists the tiers covered by a stake schedule, most secure first
+var stakeTiers = []CCTier{Tier1GPUNativeCC, Tier2ConfidentialVM, Tier3DeviceTEE, Tier4Standard}
+
+// StakeSchedule maps each CC tier to its minimum stake in LUX. Tiers missing
+// from the map use the LP-5610 value from CCTier.MinStakeLUX.
+type StakeSchedule map[CCTier]uint64
+
+// DefaultStakeSchedule returns the LP-5610 minimum stakes
+func DefaultStakeSchedule() StakeSchedule {
+	s := make(StakeSchedule, len(stakeTiers))
+	for _, tier := range stakeTiers {
+		s[tier] = tier.MinStakeLUX()
+	}
+	return s
+}
+
+// MinStake returns the minimum stake for tier under this schedule
+func (s StakeSchedule) MinStake(tier CCTier) uint64 {
+	if stake, ok := s[tier]; ok {
+		return stake
+	}
+	return tier.MinStakeLUX()
+}
+
+// Validate checks that the schedule only names known tiers and that each
+// tier requires at least as much stake as the tier below it
+func (s StakeSchedule) Validate() error {
+	for tier := range s {
+		if tier < Tier1GPUNativeCC || tier > Tier4Standard {
+			return fmt.Errorf("%w: unknown tier %d", ErrInvalidStakeSchedule, tier)
+		}
+	}
+	for i := 0; i < len(stakeTiers)-1; i++ {
+		higher, lower := stakeTiers[i], stakeTiers[i+1]
+		if s.MinStake(higher) < s.MinStake(lower) {
+			return fmt.Errorf("%w: %s stake %d is below %s stake %d", ErrInvalidStakeSchedule,
+				higher, s.MinStake(higher), lower, s.MinStake(lower))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDefaultStakeSchedule(t *testing.T) {
+	s := DefaultStakeSchedule()
+	for _, tier := range []CCTier{Tier1GPUNativeCC, Tier2ConfidentialVM, Tier3DeviceTEE, Tier4Standard} {
+		if got := s.MinStake(tier); got != tier.MinStakeLUX() {
+			t.Errorf("MinStake(%s) = %d, want %d", tier, got, tier.MinStakeLUX())
+		}
+	}
+	if err := s.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestStakeScheduleValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule StakeSchedule
+		wantErr  bool
+	}{
+		{"nil uses defaults", nil, false},
+		{"testnet", StakeSchedule{Tier1GPUNativeCC: 100, Tier2ConfidentialVM: 50, Tier3DeviceTEE: 10, Tier4Standard: 1}, false},
+		{"equal stakes", StakeSchedule{Tier1GPUNativeCC: 0, Tier2ConfidentialVM: 0, Tier3DeviceTEE: 0, Tier4Standard: 0}, false},
+		{"partial override", StakeSchedule{Tier4Standard: 10}, false},
+		{"inverted", StakeSchedule{Tier1GPUNativeCC: 10, Tier2ConfidentialVM: 50, Tier3DeviceTEE: 10, Tier4Standard: 1}, true},
+		{"partial override above default", StakeSchedule{Tier4Standard: 20_000}, true},
+		{"unknown tier", StakeSchedule{TierUnknown: 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidStakeSchedule) {
+				t.Errorf("Validate() = %v, want ErrInvalidStakeSchedule", err)
+			}
+		})
+	}
+}
+
+func TestRewardPoolStakeSchedule(t *testing.T) {
+	if _, err := NewAIRewardPoolWithStakeSchedule(time.Hour, StakeSchedule{Tier4Standard: 200_000}); err == nil {
+		t.Error("NewAIRewardPoolWithStakeSchedule() accepted a non-monotonic schedule")
+	}
+
+	schedule := StakeSchedule{Tier1GPUNativeCC: 100, Tier2ConfidentialVM: 50, Tier3DeviceTEE: 10, Tier4Standard: 1}
+	pool, err := NewAIRewardPoolWithStakeSchedule(time.Hour, schedule)
+	if err != nil {
+		t.Fatalf("NewAIRewardPoolWithStakeSchedule() error = %v", err)
+	}
+	schedule[Tier4Standard] = 1_000
+	if got := pool.MinStake(Tier4Standard); got != 1 {
+		t.Errorf("MinStake(Tier4) = %d after caller mutation, want 1", got)
+	}
+
+	if err := pool.RegisterProvider(&AIProvider{ProviderID: "low", StakeLUX: 5}); err != nil {
+		t.Errorf("RegisterProvider() = %v, want nil under testnet schedule", err)
+	}
+	if err := NewAIRewardPool(time.Hour).RegisterProvider(&AIProvider{ProviderID: "low", StakeLUX: 5}); err != ErrInsufficientStake {
+		t.Errorf("RegisterProvider() = %v, want ErrInsufficientStake under default schedule", err)
+	}
+
+	now := time.Now()
+	provider := &AIProvider{
+		ProviderID: "tier2",
+		Attestation: &TierAttestation{
+			Tier:      Tier2ConfidentialVM,
+			IssuedAt:  now.Add(-time.Hour),
+			ExpiresAt: now.Add(time.Hour),
+		},
+		StakeLUX:      60,
+		LastHeartbeat: now,
+	}
+	if ok, reason := pool.RandomMiningEligibility(provider, time.Minute); !ok {
+		t.Errorf("pool.RandomMiningEligibility() = %v, want eligible", reason)
+	}
+	if ok, reason := RandomMiningEligibility(provider, time.Minute); ok || reason != EligibilityInsufficientStake {
+		t.Errorf("RandomMiningEligibility() = %v, %v, want insufficient stake", ok, reason)
+	}
+}
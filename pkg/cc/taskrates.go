@@ -0,0 +1,128 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// DefaultBaseRateWei is the task reward per compute unit in wei
+// (0.000001 LUX). 1 compute unit = 1 GPU-second at Tier 2 / Level 2.
+const DefaultBaseRateWei int64 = 1e12
+
+// ErrInvalidTaskRates is returned when task reward rates are negative or
+// name an unknown tier or modeling level
+var ErrInvalidTaskRates = errors.New("invalid task rates")
+
+// TaskRates configures task completion rewards. A nil *TaskRates, a nil
+// BaseRateWei and tiers or levels missing from the tables all use the
+// defaults: DefaultBaseRateWei, CCTier.RewardMultiplier and
+// ModelingLevel.BaseRewardMultiplier.
+type TaskRates struct {
+	// BaseRateWei is the reward per compute unit before multipliers
+	BaseRateWei *big.Int `json:"base_rate_wei,omitempty"`
+
+	// TierMultipliers overrides the reward multiplier of each listed tier
+	TierMultipliers map[CCTier]float64 `json:"tier_multipliers,omitempty"`
+
+	// LevelMultipliers overrides the multiplier of each listed modeling level
+	LevelMultipliers map[ModelingLevel]float64 `json:"level_multipliers,omitempty"`
+}
+
+// BaseRate returns the reward per compute unit in wei
+func (r *TaskRates) BaseRate() *big.Int {
+	if r == nil || r.BaseRateWei == nil {
+		return big.NewInt(DefaultBaseRateWei)
+	}
+	return new(big.Int).Set(r.BaseRateWei)
+}
+
+// TierMultiplier returns the task reward multiplier for tier
+func (r *TaskRates) TierMultiplier(tier CCTier) float64 {
+	if r != nil {
+		if mult, ok := r.TierMultipliers[tier]; ok {
+			return mult
+		}
+	}
+	return tier.RewardMultiplier()
+}
+
+// LevelMultiplier returns the task reward multiplier for level
+func (r *TaskRates) LevelMultiplier(level ModelingLevel) float64 {
+	if r != nil {
+		if mult, ok := r.LevelMultipliers[level]; ok {
+			return mult
+		}
+	}
+	return level.BaseRewardMultiplier()
+}
+
+// Validate checks that the base rate and multipliers are non-negative and
+// finite, and that the tables only name known tiers and levels
+func (r *TaskRates) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.BaseRateWei != nil && r.BaseRateWei.Sign() < 0 {
+		return fmt.Errorf("%w: negative base rate %s", ErrInvalidTaskRates, r.BaseRateWei)
+	}
+	for tier, mult := range r.TierMultipliers {
+		if tier < Tier1GPUNativeCC || tier > Tier4Standard {
+			return fmt.Errorf("%w: unknown tier %d", ErrInvalidTaskRates, tier)
+		}
+		if !validMultiplier(mult) {
+			return fmt.Errorf("%w: %s multiplier %v", ErrInvalidTaskRates, tier, mult)
+		}
+	}
+	for level, mult := range r.LevelMultipliers {
+		if level < ModelingLevelInferenceLight || level > ModelingLevelSpecialized {
+			return fmt.Errorf("%w: unknown modeling level %d", ErrInvalidTaskRates, level)
+		}
+		if !validMultiplier(mult) {
+			return fmt.Errorf("%w: %s multiplier %v", ErrInvalidTaskRates, level, mult)
+		}
+	}
+	return nil
+}
+
+func validMultiplier(mult float64) bool {
+	return mult >= 0 && !math.IsInf(mult, 1)
+}
+
+// clone returns a deep copy of r
+func (r *TaskRates) clone() *TaskRates {
+	if r == nil {
+		return nil
+	}
+	c := &TaskRates{}
+	if r.BaseRateWei != nil {
+		c.BaseRateWei = new(big.Int).Set(r.BaseRateWei)
+	}
+	if r.TierMultipliers != nil {
+		c.TierMultipliers = make(map[CCTier]float64, len(r.TierMultipliers))
+		for tier, mult := range r.TierMultipliers {
+			c.TierMultipliers[tier] = mult
+		}
+	}
+	if r.LevelMultipliers != nil {
+		c.LevelMultipliers = make(map[ModelingLevel]float64, len(r.LevelMultipliers))
+		for level, mult := range r.LevelMultipliers {
+			c.LevelMultipliers[level] = mult
+		}
+	}
+	return c
+}
+
+// SetTaskRates validates rates and applies a copy to the pool's task
+// rewards. Nil restores the defaults.
+func (pool *AIRewardPool) SetTaskRates(rates *TaskRates) error {
+	if err := rates.Validate(); err != nil {
+		return err
+	}
+	pool.TaskRates = rates.clone()
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestTaskRatesValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rates   *TaskRates
+		wantErr bool
+	}{
+		{"nil uses defaults", nil, false},
+		{"empty", &TaskRates{}, false},
+		{"zero rate", &TaskRates{BaseRateWei: big.NewInt(0)}, false},
+		{"overrides", &TaskRates{
+			BaseRateWei:      big.NewInt(5e11),
+			TierMultipliers:  map[CCTier]float64{Tier1GPUNativeCC: 2, Tier4Standard: 0},
+			LevelMultipliers: map[ModelingLevel]float64{ModelingLevelTraining: 3},
+		}, false},
+		{"negative rate", &TaskRates{BaseRateWei: big.NewInt(-1)}, true},
+		{"negative tier multiplier", &TaskRates{TierMultipliers: map[CCTier]float64{Tier2ConfidentialVM: -0.5}}, true},
+		{"NaN level multiplier", &TaskRates{LevelMultipliers: map[ModelingLevel]float64{ModelingLevelTraining: math.NaN()}}, true},
+		{"infinite multiplier", &TaskRates{TierMultipliers: map[CCTier]float64{Tier1GPUNativeCC: math.Inf(1)}}, true},
+		{"unknown tier", &TaskRates{TierMultipliers: map[CCTier]float64{TierUnknown: 1}}, true},
+		{"unknown level", &TaskRates{LevelMultipliers: map[ModelingLevel]float64{9: 1}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rates.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidTaskRates) {
+				t.Errorf("Validate() = %v, want ErrInvalidTaskRates", err)
+			}
+		})
+	}
+}
+
+func TestTaskRewardWithTaskRates(t *testing.T) {
+	now := time.Now()
+	provider := &AIProvider{
+		ProviderID: "p",
+		Attestation: &TierAttestation{
+			Tier:      Tier1GPUNativeCC,
+			IssuedAt:  now.Add(-time.Hour),
+			ExpiresAt: now.Add(time.Hour),
+		},
+	}
+
+	tests := []struct {
+		name  string
+		rates *TaskRates
+		level ModelingLevel
+		want  int64 // wei for 100 compute units
+	}{
+		// 1e12 * 100 * 1.5 * 1.0
+		{"defaults", nil, ModelingLevelInferenceStandard, 150e12},
+		{"base rate", &TaskRates{BaseRateWei: big.NewInt(2e12)}, ModelingLevelInferenceStandard, 300e12},
+		{"tier override", &TaskRates{TierMultipliers: map[CCTier]float64{Tier1GPUNativeCC: 3}}, ModelingLevelInferenceStandard, 300e12},
+		{"other tier override ignored", &TaskRates{TierMultipliers: map[CCTier]float64{Tier2ConfidentialVM: 3}}, ModelingLevelInferenceStandard, 150e12},
+		{"level override", &TaskRates{LevelMultipliers: map[ModelingLevel]float64{ModelingLevelInferenceLight: 2}}, ModelingLevelInferenceLight, 300e12},
+		{"zero rate", &TaskRates{BaseRateWei: big.NewInt(0)}, ModelingLevelInferenceStandard, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := NewAIRewardPool(time.Hour)
+			if err := pool.SetTaskRates(tt.rates); err != nil {
+				t.Fatalf("SetTaskRates() error = %v", err)
+			}
+			got := pool.CalculateTaskReward(provider, "task", tt.level, 100).RewardLUX
+			if got.Cmp(big.NewInt(tt.want)) != 0 {
+				t.Errorf("CalculateTaskReward() = %s, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTaskRates(t *testing.T) {
+	pool := NewAIRewardPool(time.Hour)
+	if err := pool.SetTaskRates(&TaskRates{BaseRateWei: big.NewInt(-5)}); !errors.Is(err, ErrInvalidTaskRates) {
+		t.Fatalf("SetTaskRates(negative) = %v, want ErrInvalidTaskRates", err)
+	}
+	if pool.TaskRates != nil {
+		t.Fatal("rejected rates were applied")
+	}
+
+	rates := &TaskRates{
+		BaseRateWei:     big.NewInt(7),
+		TierMultipliers: map[CCTier]float64{Tier3DeviceTEE: 1},
+	}
+	if err := pool.SetTaskRates(rates); err != nil {
+		t.Fatalf("SetTaskRates() error = %v", err)
+	}
+
+	// Later changes to the caller's value or a clone don't leak into the pool
+	rates.BaseRateWei.SetInt64(9)
+	rates.TierMultipliers[Tier3DeviceTEE] = 5
+	clone := pool.Clone()
+	clone.TaskRates.TierMultipliers[Tier3DeviceTEE] = 6
+
+	if got := pool.TaskRates.BaseRate().Int64(); got != 7 {
+		t.Errorf("BaseRate() = %d, want 7", got)
+	}
+	if got := pool.TaskRates.TierMultiplier(Tier3DeviceTEE); got != 1 {
+		t.Errorf("TierMultiplier(Tier3) = %v, want 1", got)
+	}
+	if got := pool.TaskRates.TierMultiplier(Tier1GPUNativeCC); got != Tier1GPUNativeCC.RewardMultiplier() {
+		t.Errorf("TierMultiplier(Tier1) = %v, want default", got)
+	}
+}
@@ -182,6 +182,8 @@ var (
 	ErrInvalidAttestation   = errors.New("invalid attestation evidence")
 	ErrInsufficientStake    = errors.New("insufficient stake for tier")
 	ErrHardwareNotSupported = errors.New("hardware does not support required CC tier")
+	ErrInsufficientVRAM     = errors.New("insufficient GPU memory for modeling level")
+	ErrUnauthorizedKey      = errors.New("provider signing key is not authorized")
 )
 
 // TierAttestation represents an attestation bound to a specific CC tier
@@ -195,6 +197,10 @@ type TierAttestation struct {
 	// HardwareID is the unique hardware identifier (GPU serial, etc.)
 	HardwareID string `json:"hardware_id"`
 
+	// Method is the attestation method that produced the evidence (nvtrust,
+	// sev-snp, tdx, cca, secure-enclave, trustzone, software)
+	Method string `json:"method,omitempty"`
+
 	// EvidenceHash is the hash of the attestation evidence (for on-chain anchoring)
 	EvidenceHash [32]byte `json:"evidence_hash"`
 
@@ -241,7 +247,7 @@ type HardwareInfo struct {
 	TEEIOEnabled bool `json:"tee_io_enabled"`
 
 	// ComputeCapability for GPUs (e.g., "9.0" for Blackwell)
-	ComputeCapability string `json:"compute_capability,omitempty"`
+	ComputeCapability ComputeCapability `json:"compute_capability,omitempty"`
 
 	// MemorySize in bytes
 	MemorySize uint64 `json:"memory_size"`
@@ -249,10 +255,13 @@ type HardwareInfo struct {
 
 // IsValid checks if the attestation is currently valid
 func (a *TierAttestation) IsValid() bool {
+	return a.isValidAt(time.Now())
+}
+
+func (a *TierAttestation) isValidAt(now time.Time) bool {
 	if a.Tier == TierUnknown {
 		return false
 	}
-	now := time.Now()
 	return now.After(a.IssuedAt) && now.Before(a.ExpiresAt)
 }
 
@@ -332,6 +341,11 @@ type TierRequirement struct {
 
 	// RequireMinMemory is the minimum GPU memory required (in bytes)
 	RequireMinMemory uint64 `json:"require_min_memory,omitempty"`
+
+	// RequireTierMethod requires the attestation's method to be one that
+	// can back its tier (see CCTier.AttestationMethods), so that e.g. a
+	// Tier 1 claim with software attestation is rejected
+	RequireTierMethod bool `json:"require_tier_method,omitempty"`
 }
 
 // DefaultTierRequirement returns default requirements for a tier
@@ -341,6 +355,7 @@ func DefaultTierRequirement(tier CCTier) *TierRequirement {
 		RequireValidAttestation: true,
 		MaxAttestationAge:       tier.AttestationValidity(),
 		MinTrustScore:           tier.BaseTrustScore(),
+		RequireTierMethod:       true,
 	}
 }
 
@@ -355,6 +370,13 @@ func (r *TierRequirement) IsMet(attestation *TierAttestation) error {
 		return err
 	}
 
+	// Check the attestation method can back the claimed tier
+	if r.RequireTierMethod {
+		if err := attestation.checkMethod(); err != nil {
+			return err
+		}
+	}
+
 	// Check attestation validity
 	if r.RequireValidAttestation && !attestation.IsValid() {
 		return ErrAttestationExpired
@@ -382,8 +404,8 @@ func (r *TierRequirement) IsMet(attestation *TierAttestation) error {
 
 	// Check memory requirement
 	if r.RequireMinMemory > 0 && attestation.HardwareInfo != nil {
-		if attestation.HardwareInfo.MemorySize < r.RequireMinMemory {
-			return fmt.Errorf("%w: requires %d bytes memory, have %d", ErrHardwareNotSupported, r.RequireMinMemory, attestation.HardwareInfo.MemorySize)
+		if have := attestation.HardwareInfo.MemoryBytes(); have < r.RequireMinMemory {
+			return fmt.Errorf("%w: requires %d bytes memory, have %d", ErrHardwareNotSupported, r.RequireMinMemory, have)
 		}
 	}
 
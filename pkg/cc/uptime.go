@@ -0,0 +1,238 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+var (
+	ErrInvalidUptimePolicy = errors.New("invalid uptime policy")
+	ErrUnknownProvider     = errors.New("provider not registered")
+)
+
+const (
+	// DefaultHeartbeatInterval is how often providers are expected to send
+	// a heartbeat
+	DefaultHeartbeatInterval = time.Minute
+
+	// DefaultUptimeWindow is the rolling window uptime is measured over
+	DefaultUptimeWindow = 24 * time.Hour
+)
+
+// HeartbeatTracker records a provider's heartbeat arrivals and derives its
+// uptime over a rolling window: heartbeats received against heartbeats
+// expected at Interval.
+type HeartbeatTracker struct {
+	// Interval is how often a heartbeat is expected
+	Interval time.Duration `json:"interval"`
+
+	// Retention is how much history is kept behind the latest arrival;
+	// uptime windows longer than this see only the retained part
+	Retention time.Duration `json:"retention"`
+
+	// Arrivals are the recorded heartbeats, oldest first, at most one per
+	// half interval
+	Arrivals []time.Time `json:"arrivals,omitempty"`
+
+	// Since is the first heartbeat ever recorded. It is kept when older
+	// arrivals are pruned so that a long absence still counts against
+	// uptime.
+	Since time.Time `json:"since"`
+
+	// Consecutive is the number of heartbeats in the current unbroken run
+	Consecutive uint64 `json:"consecutive,omitempty"`
+}
+
+// NewHeartbeatTracker returns a tracker expecting a heartbeat every
+// interval and keeping retention of history
+func NewHeartbeatTracker(interval, retention time.Duration) *HeartbeatTracker {
+	return &HeartbeatTracker{Interval: interval, Retention: retention}
+}
+
+// Record adds a heartbeat that arrived at at and reports whether it was
+// counted. A heartbeat within half an interval of one already recorded is
+// a duplicate, e.g. a retry or a clock that stepped back, and is ignored.
+// Arrivals may be recorded out of order.
+func (t *HeartbeatTracker) Record(at time.Time) bool {
+	if t.Interval <= 0 {
+		return false
+	}
+	i := sort.Search(len(t.Arrivals), func(i int) bool { return !t.Arrivals[i].Before(at) })
+	if (i > 0 && at.Sub(t.Arrivals[i-1]) < t.Interval/2) ||
+		(i < len(t.Arrivals) && t.Arrivals[i].Sub(at) < t.Interval/2) {
+		return false
+	}
+	t.Arrivals = append(t.Arrivals, time.Time{})
+	copy(t.Arrivals[i+1:], t.Arrivals[i:])
+	t.Arrivals[i] = at
+	if t.Since.IsZero() || at.Before(t.Since) {
+		t.Since = at
+	}
+
+	if i == len(t.Arrivals)-1 {
+		if i > 0 && at.Sub(t.Arrivals[i-1]) <= t.maxGap() {
+			t.Consecutive++
+		} else {
+			t.Consecutive = 1
+		}
+	}
+	t.prune()
+	return true
+}
+
+// maxGap is the longest gap between heartbeats that does not break a run;
+// half an interval of lateness is tolerated
+func (t *HeartbeatTracker) maxGap() time.Duration {
+	return t.Interval + t.Interval/2
+}
+
+// prune drops arrivals older than Retention before the latest one
+func (t *HeartbeatTracker) prune() {
+	if t.Retention <= 0 || len(t.Arrivals) == 0 {
+		return
+	}
+	cutoff := t.Arrivals[len(t.Arrivals)-1].Add(-t.Retention)
+	i := sort.Search(len(t.Arrivals), func(i int) bool { return !t.Arrivals[i].Before(cutoff) })
+	t.Arrivals = append(t.Arrivals[:0], t.Arrivals[i:]...)
+}
+
+// Uptime returns the percentage (0-100) of heartbeats expected over the
+// window ending at now that were received. Heartbeats up to half an
+// interval after now, from a provider clock running ahead, are counted. If
+// tracking began inside the window, only the part since the first
+// heartbeat is expected, so a new provider is not penalised for the time
+// before it joined.
+func (t *HeartbeatTracker) Uptime(now time.Time, window time.Duration) float64 {
+	if t == nil || t.Interval <= 0 || window <= 0 || len(t.Arrivals) == 0 {
+		return 0
+	}
+	start, end := now.Add(-window), now.Add(t.Interval/2)
+
+	var received int
+	for _, at := range t.Arrivals {
+		if !at.Before(start) && !at.After(end) {
+			received++
+		}
+	}
+	if received == 0 {
+		return 0
+	}
+
+	expected := float64(window) / float64(t.Interval)
+	if t.Since.After(start) {
+		expected = max(1, math.Ceil(float64(now.Sub(t.Since))/float64(t.Interval)))
+	}
+	return min(100, 100*float64(received)/expected)
+}
+
+// ConsecutiveAt returns the length of the current run of heartbeats, or 0
+// if the last one is more than an interval and a half before now
+func (t *HeartbeatTracker) ConsecutiveAt(now time.Time) uint64 {
+	if t == nil || len(t.Arrivals) == 0 || now.Sub(t.Arrivals[len(t.Arrivals)-1]) > t.maxGap() {
+		return 0
+	}
+	return t.Consecutive
+}
+
+func (t *HeartbeatTracker) clone() *HeartbeatTracker {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.Arrivals = append([]time.Time(nil), t.Arrivals...)
+	return &c
+}
+
+// SetUptimePolicy sets how often providers are expected to heartbeat and
+// the window their uptime is measured over, applying both to existing
+// trackers. Zero restores a default.
+func (pool *AIRewardPool) SetUptimePolicy(interval, window time.Duration) error {
+	if interval < 0 || window < 0 {
+		return fmt.Errorf("%w: negative duration", ErrInvalidUptimePolicy)
+	}
+	c := AIRewardPool{HeartbeatInterval: interval, UptimeWindow: window}
+	if interval, window := c.uptimePolicy(); window < interval {
+		return fmt.Errorf("%w: window %s shorter than heartbeat interval %s", ErrInvalidUptimePolicy, window, interval)
+	}
+	pool.HeartbeatInterval = interval
+	pool.UptimeWindow = window
+	interval, window = pool.uptimePolicy()
+	for _, p := range pool.Providers {
+		if p.Heartbeats != nil {
+			p.Heartbeats.Interval = interval
+			p.Heartbeats.Retention = window
+			p.Heartbeats.prune()
+		}
+	}
+	return nil
+}
+
+// uptimePolicy returns the pool's heartbeat interval and uptime window
+func (pool *AIRewardPool) uptimePolicy() (interval, window time.Duration) {
+	interval, window = pool.HeartbeatInterval, pool.UptimeWindow
+	if interval == 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	if window == 0 {
+		window = DefaultUptimeWindow
+	}
+	return interval, window
+}
+
+// RecordHeartbeat marks provider as having checked in now, on the pool's
+// clock, and records the arrival in its heartbeat tracker
+func (pool *AIRewardPool) RecordHeartbeat(provider *AIProvider) {
+	now := pool.now()
+	if provider.Heartbeats == nil {
+		provider.Heartbeats = NewHeartbeatTracker(pool.uptimePolicy())
+	}
+	provider.Heartbeats.Record(now)
+	if now.After(provider.LastHeartbeat) {
+		provider.LastHeartbeat = now
+	}
+}
+
+// Heartbeat records a check-in from the registered provider providerID
+func (pool *AIRewardPool) Heartbeat(providerID string) error {
+	provider, ok := pool.Providers[providerID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownProvider, providerID)
+	}
+	pool.RecordHeartbeat(provider)
+	return nil
+}
+
+// UptimePercentage returns provider's uptime over the pool's uptime
+// window, derived from its recorded heartbeats
+func (pool *AIRewardPool) UptimePercentage(provider *AIProvider) float64 {
+	_, window := pool.uptimePolicy()
+	return provider.Heartbeats.Uptime(pool.now(), window)
+}
+
+// UptimeInput fills the uptime inputs of a trust score from provider's
+// heartbeat history, replacing any client-asserted values
+func (pool *AIRewardPool) UptimeInput(input *TrustScoreInput, provider *AIProvider) {
+	now := pool.now()
+	input.UptimePercentage = pool.UptimePercentage(provider)
+	input.LastSeenDelta = now.Sub(provider.LastHeartbeat)
+	input.ConsecutiveHeartbeats = provider.Heartbeats.ConsecutiveAt(now)
+}
+
+// TrustInput builds provider's trust score input: the baseline for its
+// attested tier, Tier4 if unattested, on hardware cap, with uptime from its
+// recorded heartbeats
+func (pool *AIRewardPool) TrustInput(provider *AIProvider, cap *HardwareCapability) *TrustScoreInput {
+	tier := Tier4Standard
+	if provider.Attestation != nil {
+		tier = provider.Attestation.Tier
+	}
+	input := BaselineTrustInput(tier, cap)
+	pool.UptimeInput(input, provider)
+	return input
+}
@@ -0,0 +1,184 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cc
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/luxfi/ai/pkg/clock"
+)
+
+func TestHeartbeatTrackerUptime(t *testing.T) {
+	base := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	every := func(from, to time.Duration) []time.Duration {
+		var offsets []time.Duration
+		for d := from; d < to; d += time.Minute {
+			offsets = append(offsets, d)
+		}
+		return offsets
+	}
+
+	tests := []struct {
+		name    string
+		beats   []time.Duration // Offsets from base
+		now     time.Duration
+		window  time.Duration
+		want    float64
+		counted int
+	}{
+		{"every interval", every(0, 120*time.Minute), 120 * time.Minute, time.Hour, 100, 120},
+		{"half missing", every(0, 90*time.Minute), 120 * time.Minute, time.Hour, 50, 90},
+		{"before window", every(0, 60*time.Minute), 120 * time.Minute, time.Hour, 0, 60},
+		{"window start included", every(0, 61*time.Minute), 120 * time.Minute, time.Hour, 100.0 / 60, 61},
+		{"mid-interval now", every(0, 120*time.Minute), 119*time.Minute + 30*time.Second, time.Hour, 100, 120},
+		{"joined inside window", every(90*time.Minute, 120*time.Minute), 120 * time.Minute, time.Hour, 100, 30},
+		{"joined then left", every(90*time.Minute, 100*time.Minute), 120 * time.Minute, time.Hour, 100 * 10.0 / 30, 10},
+		{"duplicates ignored", []time.Duration{0, 10 * time.Second, 20 * time.Second, time.Minute}, 3 * time.Minute, time.Hour, 100 * 2.0 / 3, 2},
+		{"out of order", []time.Duration{2 * time.Minute, 0, time.Minute}, 2 * time.Minute, time.Hour, 100, 3},
+		{"clock ahead within tolerance", []time.Duration{0, time.Minute, 2*time.Minute + 20*time.Second}, 2 * time.Minute, time.Hour, 100, 3},
+		{"clock far ahead", []time.Duration{0, time.Minute, 5 * time.Minute}, 3 * time.Minute, time.Hour, 100 * 2.0 / 3, 3},
+		{"no heartbeats", nil, time.Hour, time.Hour, 0, 0},
+	}
+	for _, tt := range tests {
+		tracker := NewHeartbeatTracker(time.Minute, 0)
+		counted := 0
+		for _, d := range tt.beats {
+			if tracker.Record(base.Add(d)) {
+				counted++
+			}
+		}
+		if counted != tt.counted {
+			t.Errorf("%s: recorded %d heartbeats, want %d", tt.name, counted, tt.counted)
+		}
+		if got := tracker.Uptime(base.Add(tt.now), tt.window); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: Uptime() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHeartbeatTrackerRetention(t *testing.T) {
+	base := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewHeartbeatTracker(time.Minute, time.Hour)
+	for d := time.Duration(0); d < 2*time.Hour; d += time.Minute {
+		tracker.Record(base.Add(d))
+	}
+	if n := len(tracker.Arrivals); n != 61 {
+		t.Errorf("retained %d arrivals, want 61", n)
+	}
+	if tracker.ConsecutiveAt(base.Add(2*time.Hour)) != 120 {
+		t.Errorf("ConsecutiveAt() = %d, want 120", tracker.ConsecutiveAt(base.Add(2*time.Hour)))
+	}
+
+	// A long absence is not forgotten once its arrivals are pruned
+	tracker.Record(base.Add(10 * time.Hour))
+	if got := tracker.Uptime(base.Add(10*time.Hour), time.Hour); math.Abs(got-100.0/60) > 1e-9 {
+		t.Errorf("Uptime() after absence = %v, want %v", got, 100.0/60)
+	}
+	if got := tracker.ConsecutiveAt(base.Add(10 * time.Hour)); got != 1 {
+		t.Errorf("ConsecutiveAt() after absence = %d, want 1", got)
+	}
+	if got := tracker.ConsecutiveAt(base.Add(11 * time.Hour)); got != 0 {
+		t.Errorf("ConsecutiveAt() when stale = %d, want 0", got)
+	}
+}
+
+func TestPoolUptime(t *testing.T) {
+	mock := clock.NewMock(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	pool := NewAIRewardPool(time.Hour)
+	pool.Clock = mock
+	if err := pool.SetUptimePolicy(time.Hour, time.Minute); !errors.Is(err, ErrInvalidUptimePolicy) {
+		t.Errorf("SetUptimePolicy(window < interval) = %v, want ErrInvalidUptimePolicy", err)
+	}
+	if err := pool.SetUptimePolicy(30*time.Second, time.Hour); err != nil {
+		t.Fatalf("SetUptimePolicy() = %v", err)
+	}
+
+	provider := &AIProvider{ProviderID: "p"}
+	for range 60 {
+		pool.RecordHeartbeat(provider)
+		mock.Advance(time.Minute)
+	}
+	if !provider.LastHeartbeat.Equal(mock.Now().Add(-time.Minute)) {
+		t.Errorf("LastHeartbeat = %v, want the last recorded heartbeat", provider.LastHeartbeat)
+	}
+	// One heartbeat a minute against one expected every 30s
+	if got := pool.UptimePercentage(provider); math.Abs(got-50) > 1 {
+		t.Errorf("UptimePercentage() = %v, want about 50", got)
+	}
+
+	pool.Providers["p"] = provider
+	clone := pool.Clone()
+	pool.RecordHeartbeat(provider)
+	if len(clone.Providers["p"].Heartbeats.Arrivals) == len(provider.Heartbeats.Arrivals) {
+		t.Error("Clone() shares heartbeat history with the original")
+	}
+
+	if err := pool.SetUptimePolicy(time.Minute, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	// Heartbeats a minute apart broke every run at the 30s interval
+	input := &TrustScoreInput{UptimePercentage: 100}
+	pool.UptimeInput(input, provider)
+	if input.UptimePercentage != 100 || input.LastSeenDelta != 0 || input.ConsecutiveHeartbeats != 1 {
+		t.Errorf("UptimeInput() = %v%%, %v, %d", input.UptimePercentage, input.LastSeenDelta, input.ConsecutiveHeartbeats)
+	}
+
+	absent := &AIProvider{ProviderID: "q"}
+	pool.UptimeInput(input, absent)
+	if input.UptimePercentage != 0 || input.ConsecutiveHeartbeats != 0 {
+		t.Errorf("UptimeInput() without heartbeats = %v%%, %d, want 0", input.UptimePercentage, input.ConsecutiveHeartbeats)
+	}
+}
+
+func TestPoolHeartbeatTrustInput(t *testing.T) {
+	mock := clock.NewMock(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	pool := NewAIRewardPool(time.Hour)
+	pool.Clock = mock
+	if err := pool.SetUptimePolicy(time.Minute, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	provider := &AIProvider{
+		ProviderID:       "p",
+		MaxModelingLevel: ModelingLevelInferenceStandard,
+		StakeLUX:         100_000,
+		Attestation: &TierAttestation{
+			Tier:      Tier1GPUNativeCC,
+			Method:    MethodNVTrust,
+			IssuedAt:  mock.Now().Add(-time.Hour),
+			ExpiresAt: mock.Now().Add(24 * time.Hour),
+		},
+	}
+	if err := pool.RegisterProvider(provider); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+	if !provider.LastHeartbeat.Equal(mock.Now()) {
+		t.Errorf("LastHeartbeat = %v, want registration time %v", provider.LastHeartbeat, mock.Now())
+	}
+
+	// Present for the first half hour, then silent for the second
+	for range 29 {
+		mock.Advance(time.Minute)
+		if err := pool.Heartbeat("p"); err != nil {
+			t.Fatalf("Heartbeat() error = %v", err)
+		}
+	}
+	mock.Advance(31 * time.Minute)
+	if err := pool.Heartbeat("missing"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("Heartbeat(unregistered) = %v, want ErrUnknownProvider", err)
+	}
+
+	input := pool.TrustInput(provider, nil)
+	if input.Tier != Tier1GPUNativeCC || math.Abs(input.UptimePercentage-50) > 1e-9 {
+		t.Errorf("TrustInput() tier %v, uptime %v%%; want tier 1 at 50%%", input.Tier, input.UptimePercentage)
+	}
+	if input.LastSeenDelta != 31*time.Minute || input.ConsecutiveHeartbeats != 0 {
+		t.Errorf("TrustInput() last seen %v ago, %d consecutive; want 31m, 0", input.LastSeenDelta, input.ConsecutiveHeartbeats)
+	}
+	if got := pool.TrustInput(&AIProvider{ProviderID: "new"}, nil); got.Tier != Tier4Standard || got.UptimePercentage != 0 {
+		t.Errorf("TrustInput(unattested) tier %v, uptime %v%%; want Tier4 with no uptime", got.Tier, got.UptimePercentage)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package clock abstracts the current time so that attestation validity,
+// heartbeat staleness, score freshness and epoch timing can be tested by
+// advancing a mock clock instead of waiting on, or allowing for, real time.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is the system clock
+type Real struct{}
+
+// Now returns time.Now()
+func (Real) Now() time.Time { return time.Now() }
+
+// Or returns c, or the system clock if c is nil
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real{}
+	}
+	return c
+}
+
+// Mock is a Clock that only moves when told to. It is safe for concurrent
+// use.
+type Mock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewMock returns a Mock set to now
+func NewMock(now time.Time) *Mock {
+	return &Mock{now: now}
+}
+
+// Now returns the mock's current time
+func (m *Mock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Advance moves the mock forward by d, or back if d is negative, and
+// returns the new time
+func (m *Mock) Advance(d time.Duration) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+	return m.now
+}
+
+// Set moves the mock to t
+func (m *Mock) Set(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = t
+}
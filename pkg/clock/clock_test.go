@@ -0,0 +1,37 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMock(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMock(start)
+	if got := m.Now(); !got.Equal(start) {
+		t.Fatalf("Now() = %v, want %v", got, start)
+	}
+	if got := m.Advance(90 * time.Second); !got.Equal(start.Add(90 * time.Second)) {
+		t.Errorf("Advance() = %v, want %v", got, start.Add(90*time.Second))
+	}
+	if got := m.Now(); !got.Equal(start.Add(90 * time.Second)) {
+		t.Errorf("Now() after Advance = %v, want %v", got, start.Add(90*time.Second))
+	}
+	m.Set(start)
+	if got := m.Now(); !got.Equal(start) {
+		t.Errorf("Now() after Set = %v, want %v", got, start)
+	}
+}
+
+func TestOr(t *testing.T) {
+	if _, ok := Or(nil).(Real); !ok {
+		t.Error("Or(nil) is not the real clock")
+	}
+	m := NewMock(time.Time{})
+	if Or(m) != Clock(m) {
+		t.Error("Or(m) did not return m")
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/luxfi/ai/internal/backoff"
+	"github.com/luxfi/ai/pkg/attestation"
+	"github.com/luxfi/ai/pkg/cc"
+)
+
+// ErrNoAttester is returned by Attest when no Attester has been installed.
+var ErrNoAttester = errors.New("no attester configured")
+
+const (
+	// attestationRefreshFraction is how far into an attestation's validity
+	// window the miner re-attests, leaving the rest as margin for retries.
+	attestationRefreshFraction = 0.75
+
+	// attestationRetryBase is the delay before retrying a failed
+	// attestation; it doubles on each consecutive failure.
+	attestationRetryBase = 5 * time.Second
+
+	// attestationRetryMax caps the delay between attestation retries.
+	attestationRetryMax = 5 * time.Minute
+)
+
+// Attestation is the outcome of one round of the attestation
+// challenge/verify flow.
+type Attestation struct {
+	Tier       cc.CCTier `json:"tier"`
+	TrustScore uint8     `json:"trust_score"`
+	IssuedAt   time.Time `json:"issued_at"`
+
+	// ExpiresAt is when the attestation stops counting towards Tier. Zero
+	// means IssuedAt plus the tier's AttestationValidity.
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Evidence is what the node verifies. The node derives its own tier,
+	// trust score and expiry from it, so without evidence the attestation
+	// stays local to the miner.
+	Evidence *attestation.GPUAttestation `json:"evidence,omitempty"`
+}
+
+// refreshAt returns when the attestation should be renewed.
+func (a *Attestation) refreshAt() time.Time {
+	validity := a.ExpiresAt.Sub(a.IssuedAt)
+	return a.IssuedAt.Add(time.Duration(float64(validity) * attestationRefreshFraction))
+}
+
+// Attester runs the attestation challenge/verify flow, e.g. answering a
+// verifier's benchmark challenge and having the resulting evidence
+// verified, and returns the verified attestation.
+type Attester func(ctx context.Context) (*Attestation, error)
+
+// AttestationStatus reports the miner's attestation in its local status.
+type AttestationStatus struct {
+	Tier       cc.CCTier     `json:"tier"`
+	TrustScore uint8         `json:"trust_score"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	ExpiresIn  time.Duration `json:"expires_in"` // Negative once expired
+	Failures   int           `json:"failures,omitempty"`
+	LastError  string        `json:"last_error,omitempty"`
+}
+
+// SetAttester installs the attestation flow. When set before Start, the
+// miner attests on startup and re-attests at 75% of each attestation's
+// validity window, retrying failures with backoff, so the attestation is
+// renewed before it expires. Passing nil removes it.
+func (m *Miner) SetAttester(a Attester) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attester = a
+}
+
+// Attest runs the attestation flow once, keeps the result as the miner's
+// current attestation and reports it to the node when registered. A
+// failure leaves the previous attestation in place.
+func (m *Miner) Attest(ctx context.Context) (*Attestation, error) {
+	m.mu.RLock()
+	attester := m.attester
+	m.mu.RUnlock()
+	if attester == nil {
+		return nil, ErrNoAttester
+	}
+
+	att, err := attester(ctx)
+	if err == nil && att == nil {
+		err = errors.New("attester returned no attestation")
+	}
+	if err != nil {
+		m.mu.Lock()
+		m.attestFailures++
+		m.attestErr = err.Error()
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	if att.IssuedAt.IsZero() {
+		att.IssuedAt = time.Now()
+	}
+	if att.ExpiresAt.IsZero() {
+		att.ExpiresAt = att.IssuedAt.Add(att.Tier.AttestationValidity())
+	}
+
+	m.mu.Lock()
+	m.attestation = att
+	m.attestFailures = 0
+	m.attestErr = ""
+	m.mu.Unlock()
+
+	return att, m.reportAttestation(ctx, att)
+}
+
+// Attestation returns the miner's current attestation, or nil if it has
+// not attested.
+func (m *Miner) Attestation() *Attestation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.attestation == nil {
+		return nil
+	}
+	att := *m.attestation
+	return &att
+}
+
+// AttestationExpiresIn returns the time left before the current
+// attestation expires. It is negative once expired and zero if the miner
+// has not attested.
+func (m *Miner) AttestationExpiresIn() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.attestation == nil {
+		return 0
+	}
+	return time.Until(m.attestation.ExpiresAt)
+}
+
+// attestationStatusLocked summarizes the attestation for Status, or
+// returns nil if the miner has never attempted one. Caller holds m.mu.
+func (m *Miner) attestationStatusLocked(now time.Time) *AttestationStatus {
+	if m.attestation == nil && m.attestFailures == 0 {
+		return nil
+	}
+	status := &AttestationStatus{
+		Failures:  m.attestFailures,
+		LastError: m.attestErr,
+	}
+	if att := m.attestation; att != nil {
+		status.Tier = att.Tier
+		status.TrustScore = att.TrustScore
+		status.ExpiresAt = att.ExpiresAt
+		status.ExpiresIn = att.ExpiresAt.Sub(now)
+	}
+	return status
+}
+
+// reportAttestation sends the attestation's evidence to the node for
+// verification. It is a no-op for miners that have not registered and for
+// attestations without evidence.
+func (m *Miner) reportAttestation(ctx context.Context, att *Attestation) error {
+	m.mu.RLock()
+	token := m.nodeToken
+	m.mu.RUnlock()
+	if token == "" || att.Evidence == nil {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"id":       m.ID(),
+		"evidence": att.Evidence,
+	})
+	if err != nil {
+		return err
+	}
+	return m.postNode(ctx, "/api/miners/attestation", token, body, nil)
+}
+
+// AttestationChallenge asks the node for a benchmark challenge to answer in
+// the next software attestation's evidence, as an Attester does before
+// building it. The evidence's device ID must be the miner's ID. The miner
+// must be registered.
+func (m *Miner) AttestationChallenge(ctx context.Context) (*attestation.BenchmarkChallenge, error) {
+	m.mu.RLock()
+	token := m.nodeToken
+	m.mu.RUnlock()
+	if token == "" {
+		return nil, ErrNotRegistered
+	}
+
+	body, err := json.Marshal(map[string]string{"id": m.ID()})
+	if err != nil {
+		return nil, err
+	}
+	var ch attestation.BenchmarkChallenge
+	if err := m.postNode(ctx, "/api/miners/attestation/challenge", token, body, &ch); err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
+
+// refreshAttestation attests on startup and then again at
+// attestationRefreshFraction of each attestation's validity, until the
+// miner stops. Failures are retried with jittered exponential backoff.
+func (m *Miner) refreshAttestation(ctx context.Context) {
+	retry := backoff.Backoff{
+		Base:   attestationRetryBase,
+		Max:    attestationRetryMax,
+		Jitter: nodeRetryJitter,
+	}
+
+	var wait time.Duration
+	for failures := 0; ; {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-m.stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		att, err := m.Attest(ctx)
+		if err != nil {
+			m.logf(LogError, "", "attestation: %v", err)
+			// Also retry an attestation the node failed to receive, but
+			// never later than its own refresh point
+			wait = retry.Delay(failures)
+			failures++
+			if att != nil {
+				wait = min(wait, time.Until(att.refreshAt()))
+			}
+			continue
+		}
+		failures = 0
+		wait = time.Until(att.refreshAt())
+	}
+}
@@ -11,7 +11,10 @@
 // does in runInference, runChat, and runEmbedding.
 package backend
 
-import "context"
+import (
+	"context"
+	"strings"
+)
 
 // Message is a single chat turn. Shape matches OpenAI chat messages and the
 // miner's internal message type.
@@ -20,19 +23,63 @@ type Message struct {
 	Content string `json:"content"`
 }
 
+// Response format types accepted in ResponseFormat.Type.
+const (
+	ResponseFormatText       = "text"
+	ResponseFormatJSONObject = "json_object"
+)
+
+// ResponseFormat constrains the shape of a chat reply. Shape matches the
+// OpenAI response_format parameter.
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
+// Sampling holds optional sampling parameters, matching the OpenAI chat
+// parameters of the same names. Nil fields leave the engine's defaults.
+type Sampling struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	// Seed requests deterministic sampling on engines that support it.
+	Seed *int64 `json:"seed,omitempty"`
+}
+
 // ChatRequest is a multi-turn chat prompt.
 type ChatRequest struct {
 	Model     string    `json:"model"`
 	Messages  []Message `json:"messages"`
 	MaxTokens int       `json:"max_tokens,omitempty"`
+	Sampling
+	// ResponseFormat, when set, asks the engine to constrain its output.
+	// Backends without Capabilities.JSONMode may ignore it, so callers
+	// should validate the reply themselves.
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	// Stop lists sequences that end generation. The reply excludes the
+	// matched sequence.
+	Stop []string `json:"stop,omitempty"`
 }
 
+// Finish reasons reported in ChatResponse.FinishReason. Natural ends and
+// stop sequences both report stop, as OpenAI clients expect; only length
+// tells a client the reply was truncated.
+const (
+	// FinishReasonStop means generation ended naturally or on a stop
+	// sequence.
+	FinishReasonStop = "stop"
+	// FinishReasonLength means generation was cut off by MaxTokens.
+	FinishReasonLength = "length"
+)
+
 // ChatResponse is the assistant's reply.
 type ChatResponse struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
 	Model   string `json:"model"`
 	Tokens  int    `json:"tokens,omitempty"`
+	// FinishReason is why generation ended; empty means unknown.
+	FinishReason string `json:"finish_reason,omitempty"`
 }
 
 // InferenceRequest is a single-prompt completion request.
@@ -70,6 +117,9 @@ type Capabilities struct {
 	// EmbeddingDims, when non-zero, declares a fixed output dimensionality for
 	// embeddings; 0 means the backend decides per-request.
 	EmbeddingDims int `json:"embedding_dims,omitempty"`
+	// JSONMode reports that the engine enforces a json_object
+	// ResponseFormat rather than ignoring it.
+	JSONMode bool `json:"json_mode,omitempty"`
 }
 
 // InferenceBackend is the pluggable compute layer for the miner.
@@ -93,3 +143,71 @@ type InferenceBackend interface {
 	// Embed produces an embedding vector for the given text.
 	Embed(ctx context.Context, req EmbedRequest) (EmbedResponse, error)
 }
+
+// ModelLoader is implemented by backends that load model files into memory
+// themselves (as opposed to remote engines that manage their own models).
+// The miner calls it when model files appear in or disappear from its model
+// directory. It is optional; backends that don't implement it serve
+// whatever models their engine has.
+type ModelLoader interface {
+	// LoadModel makes the model at path available under id.
+	LoadModel(ctx context.Context, id, path string) error
+
+	// UnloadModel releases a model previously loaded under id. The miner
+	// only calls it once no task is using the model.
+	UnloadModel(id string) error
+}
+
+// ModelLister is implemented by backends that can enumerate the models
+// their engine serves (e.g. ollama's /api/tags). The miner advertises them
+// to the node alongside the files in its model directory. It is optional
+// so existing backends keep compiling; see ListModels.
+type ModelLister interface {
+	// Models returns the IDs of the models the engine can serve now.
+	Models(ctx context.Context) ([]string, error)
+}
+
+// ListModels returns b's models when it implements ModelLister and nil
+// otherwise.
+func ListModels(ctx context.Context, b InferenceBackend) ([]string, error) {
+	lister, ok := b.(ModelLister)
+	if !ok {
+		return nil, nil
+	}
+	return lister.Models(ctx)
+}
+
+// TruncateAtStop cuts content at the earliest occurrence of any stop
+// sequence and reports whether one was found. Callers use it to enforce
+// ChatRequest.Stop on engines that ignore it.
+func TruncateAtStop(content string, stop []string) (string, bool) {
+	cut := -1
+	for _, s := range stop {
+		if s == "" {
+			continue
+		}
+		if i := strings.Index(content, s); i >= 0 && (cut < 0 || i < cut) {
+			cut = i
+		}
+	}
+	if cut < 0 {
+		return content, false
+	}
+	return content[:cut], true
+}
+
+// ResolveFinishReason maps an engine's finish reason onto the OpenAI
+// values. stopped reports that a stop sequence was enforced after the
+// engine returned, which ends the reply before any length limit. Engine
+// spellings of a length cutoff ("length", "max_tokens") report length;
+// anything else, including no reason at all, is a natural stop.
+func ResolveFinishReason(reason string, stopped bool) string {
+	if stopped {
+		return FinishReasonStop
+	}
+	switch strings.ToLower(reason) {
+	case FinishReasonLength, "max_tokens":
+		return FinishReasonLength
+	}
+	return FinishReasonStop
+}
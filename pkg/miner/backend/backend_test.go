@@ -70,3 +70,44 @@ func TestRequestResponseRoundTrip(t *testing.T) {
 		t.Errorf("Embed: model not preserved, got %q", emb.Model)
 	}
 }
+
+func TestTruncateAtStop(t *testing.T) {
+	tests := []struct {
+		content     string
+		stop        []string
+		want        string
+		wantStopped bool
+	}{
+		{"hello world", nil, "hello world", false},
+		{"hello world", []string{"xyz"}, "hello world", false},
+		{"hello world", []string{"world", "o"}, "hell", true},
+		{"hello world", []string{""}, "hello world", false},
+		{"END", []string{"END"}, "", true},
+	}
+	for _, tt := range tests {
+		got, stopped := backend.TruncateAtStop(tt.content, tt.stop)
+		if got != tt.want || stopped != tt.wantStopped {
+			t.Errorf("TruncateAtStop(%q, %q) = (%q, %v), want (%q, %v)", tt.content, tt.stop, got, stopped, tt.want, tt.wantStopped)
+		}
+	}
+}
+
+func TestResolveFinishReason(t *testing.T) {
+	tests := []struct {
+		reason  string
+		stopped bool
+		want    string
+	}{
+		{"", false, backend.FinishReasonStop},
+		{"stop", false, backend.FinishReasonStop},
+		{"eos", false, backend.FinishReasonStop},
+		{"length", false, backend.FinishReasonLength},
+		{"MAX_TOKENS", false, backend.FinishReasonLength},
+		{"length", true, backend.FinishReasonStop},
+	}
+	for _, tt := range tests {
+		if got := backend.ResolveFinishReason(tt.reason, tt.stopped); got != tt.want {
+			t.Errorf("ResolveFinishReason(%q, %v) = %q, want %q", tt.reason, tt.stopped, got, tt.want)
+		}
+	}
+}
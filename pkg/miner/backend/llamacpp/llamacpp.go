@@ -0,0 +1,297 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package llamacpp provides an InferenceBackend that talks to a llama.cpp
+// server (`llama-server -m model.gguf`). Chat and embeddings use the
+// server's OpenAI-style `/v1` routes; single-prompt inference uses the
+// native `/completion` endpoint, which reports whether generation stopped
+// on the token limit. A llama.cpp server hosts one model, so request model
+// names are informational only.
+//
+// This adapter uses only the Go standard library — no SDK dependency.
+package llamacpp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luxfi/ai/pkg/miner/backend"
+)
+
+const (
+	// DefaultBaseURL is where llama-server listens by default.
+	DefaultBaseURL = "http://localhost:8080"
+
+	// DefaultTimeout is the per-request HTTP timeout used when the caller
+	// does not supply an HTTPClient.
+	DefaultTimeout = 5 * time.Minute
+)
+
+// Config configures a llama.cpp backend.
+type Config struct {
+	// BaseURL is the server root, e.g. "http://localhost:8080" (without
+	// "/v1"). Trailing slash is tolerated. Defaults to DefaultBaseURL.
+	BaseURL string
+	// APIKey is sent as "Authorization: Bearer <key>" for servers started
+	// with --api-key. Empty sends no header.
+	APIKey string
+	// HTTPClient is optional. When nil, a client with DefaultTimeout is
+	// used.
+	HTTPClient *http.Client
+}
+
+// Backend is the llama.cpp InferenceBackend.
+type Backend struct {
+	cfg    Config
+	client *http.Client
+}
+
+// New returns a backend configured against cfg. If cfg.BaseURL is empty,
+// DefaultBaseURL is used.
+func New(cfg Config) *Backend {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	c := cfg.HTTPClient
+	if c == nil {
+		c = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &Backend{cfg: cfg, client: c}
+}
+
+// StatusError reports a non-2xx HTTP response from llama-server. A 503
+// means the server is still loading its model.
+type StatusError struct {
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+	// APIMessage is the `error.message` field of the JSON body, if any.
+	APIMessage string
+	// RawBody is the verbatim response body.
+	RawBody string
+}
+
+func (e *StatusError) Error() string {
+	if e.APIMessage != "" {
+		return fmt.Sprintf("llamacpp: %s (status %d)", e.APIMessage, e.StatusCode)
+	}
+	return fmt.Sprintf("llamacpp: status %d: %s", e.StatusCode, strings.TrimSpace(e.RawBody))
+}
+
+// Name implements backend.InferenceBackend.
+func (*Backend) Name() string { return "llamacpp" }
+
+// Capabilities implements backend.InferenceBackend. Embedding requires the
+// server to be started with --embeddings; otherwise Embed returns a
+// StatusError.
+func (*Backend) Capabilities() backend.Capabilities {
+	return backend.Capabilities{
+		Chat:      true,
+		Inference: true,
+		Embedding: true,
+		// response_format is compiled to a GBNF grammar.
+		JSONMode: true,
+	}
+}
+
+// --- chat ---
+
+type chatRequest struct {
+	Model          string                  `json:"model,omitempty"`
+	Messages       []backend.Message       `json:"messages"`
+	MaxTokens      int                     `json:"max_tokens,omitempty"`
+	ResponseFormat *backend.ResponseFormat `json:"response_format,omitempty"`
+	Stop           []string                `json:"stop,omitempty"`
+	backend.Sampling
+}
+
+type chatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      backend.Message `json:"message"`
+		FinishReason string          `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Chat implements backend.InferenceBackend via /v1/chat/completions.
+func (b *Backend) Chat(ctx context.Context, req backend.ChatRequest) (backend.ChatResponse, error) {
+	payload := chatRequest{
+		Model:          req.Model,
+		Messages:       req.Messages,
+		MaxTokens:      req.MaxTokens,
+		ResponseFormat: req.ResponseFormat,
+		Stop:           req.Stop,
+		Sampling:       req.Sampling,
+	}
+
+	var resp chatResponse
+	if err := b.do(ctx, http.MethodPost, "/v1/chat/completions", payload, &resp); err != nil {
+		return backend.ChatResponse{}, err
+	}
+	if len(resp.Choices) == 0 {
+		return backend.ChatResponse{}, errors.New("llamacpp: chat response has no choices")
+	}
+	c := resp.Choices[0]
+	return backend.ChatResponse{
+		Role:         c.Message.Role,
+		Content:      c.Message.Content,
+		Model:        responseModel(resp.Model, req.Model),
+		Tokens:       resp.Usage.CompletionTokens,
+		FinishReason: c.FinishReason,
+	}, nil
+}
+
+// --- completion ---
+
+type completionRequest struct {
+	Prompt   string `json:"prompt"`
+	NPredict int    `json:"n_predict,omitempty"`
+	Stream   bool   `json:"stream"`
+}
+
+type completionResponse struct {
+	Content         string `json:"content"`
+	Model           string `json:"model"`
+	TokensPredicted int    `json:"tokens_predicted"`
+}
+
+// Inference implements backend.InferenceBackend via the native /completion
+// endpoint.
+func (b *Backend) Inference(ctx context.Context, req backend.InferenceRequest) (backend.InferenceResponse, error) {
+	payload := completionRequest{Prompt: req.Prompt, NPredict: req.MaxTokens}
+
+	var resp completionResponse
+	if err := b.do(ctx, http.MethodPost, "/completion", payload, &resp); err != nil {
+		return backend.InferenceResponse{}, err
+	}
+	return backend.InferenceResponse{
+		Text:   resp.Content,
+		Tokens: resp.TokensPredicted,
+		Model:  responseModel(resp.Model, req.Model),
+	}, nil
+}
+
+// --- embeddings ---
+
+type embeddingRequest struct {
+	Model string `json:"model,omitempty"`
+	Input string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Model string `json:"model"`
+	Data  []struct {
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed implements backend.InferenceBackend via /v1/embeddings.
+func (b *Backend) Embed(ctx context.Context, req backend.EmbedRequest) (backend.EmbedResponse, error) {
+	var resp embeddingResponse
+	if err := b.do(ctx, http.MethodPost, "/v1/embeddings", embeddingRequest{Model: req.Model, Input: req.Text}, &resp); err != nil {
+		return backend.EmbedResponse{}, err
+	}
+	if len(resp.Data) == 0 {
+		return backend.EmbedResponse{}, errors.New("llamacpp: embedding response has no data")
+	}
+	return backend.EmbedResponse{
+		Embedding: resp.Data[0].Embedding,
+		Model:     responseModel(resp.Model, req.Model),
+	}, nil
+}
+
+// --- models ---
+
+type modelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// Models implements backend.ModelLister, returning the model (or alias)
+// the server was started with.
+func (b *Backend) Models(ctx context.Context) ([]string, error) {
+	var resp modelsResponse
+	if err := b.do(ctx, http.MethodGet, "/v1/models", nil, &resp); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(resp.Data))
+	for _, m := range resp.Data {
+		ids = append(ids, m.ID)
+	}
+	return ids, nil
+}
+
+// responseModel prefers the model the server reports, falling back to the
+// one requested.
+func responseModel(reported, requested string) string {
+	if reported != "" {
+		return reported
+	}
+	return requested
+}
+
+// --- HTTP plumbing ---
+
+func (b *Backend) do(ctx context.Context, method, path string, payload any, out any) error {
+	var reader io.Reader
+	if payload != nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("llamacpp: encode request: %w", err)
+		}
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, b.cfg.BaseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("llamacpp: build request: %w", err)
+	}
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if b.cfg.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+b.cfg.APIKey)
+	}
+
+	resp, err := b.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("llamacpp: http: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("llamacpp: read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// llama-server returns {"error": {"code": ..., "message": "..."}}
+		var errEnv struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		statusErr := &StatusError{StatusCode: resp.StatusCode, RawBody: string(respBody)}
+		if jerr := json.Unmarshal(respBody, &errEnv); jerr == nil {
+			statusErr.APIMessage = errEnv.Error.Message
+		}
+		return statusErr
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("llamacpp: decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package llamacpp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/ai/pkg/miner/backend"
+)
+
+// newServer serves reply on path and records the last request body and
+// Authorization header
+func newServer(t *testing.T, path, reply string, gotBody, gotAuth *string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("request path: got %q want %q", r.URL.Path, path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if gotBody != nil {
+			*gotBody = string(body)
+		}
+		if gotAuth != nil {
+			*gotAuth = r.Header.Get("Authorization")
+		}
+		_, _ = w.Write([]byte(reply))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestName(t *testing.T) {
+	if got := New(Config{}).Name(); got != "llamacpp" {
+		t.Errorf("Name: got %q want %q", got, "llamacpp")
+	}
+}
+
+func TestChat(t *testing.T) {
+	var gotBody, gotAuth string
+	srv := newServer(t, "/v1/chat/completions", `{
+		"model": "qwen.gguf",
+		"choices": [{"message": {"role": "assistant", "content": "hi"}, "finish_reason": "length"}],
+		"usage": {"completion_tokens": 4}
+	}`, &gotBody, &gotAuth)
+
+	b := New(Config{BaseURL: srv.URL + "/", APIKey: "secret"})
+	resp, err := b.Chat(context.Background(), backend.ChatRequest{
+		Model:     "zen-mini-0.5b",
+		Messages:  []backend.Message{{Role: "user", Content: "hello"}},
+		MaxTokens: 4,
+		Stop:      []string{"END"},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("auth header: got %q want %q", gotAuth, "Bearer secret")
+	}
+	if !strings.Contains(gotBody, `"stop":["END"]`) || !strings.Contains(gotBody, `"max_tokens":4`) {
+		t.Errorf("request body missing parameters: %s", gotBody)
+	}
+	if resp.Content != "hi" || resp.Tokens != 4 || resp.Model != "qwen.gguf" || resp.FinishReason != backend.FinishReasonLength {
+		t.Errorf("Chat response: %+v", resp)
+	}
+}
+
+func TestInference(t *testing.T) {
+	var gotBody string
+	srv := newServer(t, "/completion", `{"content":" world","tokens_predicted":2}`, &gotBody, nil)
+
+	resp, err := New(Config{BaseURL: srv.URL}).Inference(context.Background(), backend.InferenceRequest{
+		Model:     "m",
+		Prompt:    "hello",
+		MaxTokens: 2,
+	})
+	if err != nil {
+		t.Fatalf("Inference: %v", err)
+	}
+	if !strings.Contains(gotBody, `"n_predict":2`) || !strings.Contains(gotBody, `"stream":false`) {
+		t.Errorf("request body missing parameters: %s", gotBody)
+	}
+	if resp.Text != " world" || resp.Tokens != 2 || resp.Model != "m" {
+		t.Errorf("Inference response: %+v", resp)
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	srv := newServer(t, "/v1/embeddings", `{"data":[{"embedding":[1,2,3]}]}`, nil, nil)
+
+	resp, err := New(Config{BaseURL: srv.URL}).Embed(context.Background(), backend.EmbedRequest{Text: "hi"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if !reflect.DeepEqual(resp.Embedding, []float64{1, 2, 3}) {
+		t.Errorf("Embedding: got %v", resp.Embedding)
+	}
+}
+
+func TestModels(t *testing.T) {
+	srv := newServer(t, "/v1/models", `{"object":"list","data":[{"id":"qwen.gguf"}]}`, nil, nil)
+
+	got, err := New(Config{BaseURL: srv.URL}).Models(context.Background())
+	if err != nil {
+		t.Fatalf("Models: %v", err)
+	}
+	if want := []string{"qwen.gguf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Models: got %v want %v", got, want)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":{"code":503,"message":"Loading model","type":"unavailable_error"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := New(Config{BaseURL: srv.URL}).Embed(context.Background(), backend.EmbedRequest{Text: "hi"})
+	var se *StatusError
+	if !errors.As(err, &se) {
+		t.Fatalf("error should be *StatusError, got %T: %v", err, err)
+	}
+	if se.StatusCode != http.StatusServiceUnavailable || se.APIMessage != "Loading model" {
+		t.Errorf("StatusError: %+v", se)
+	}
+	if got, want := se.Error(), "llamacpp: Loading model (status 503)"; got != want {
+		t.Errorf("Error(): got %q want %q", got, want)
+	}
+}
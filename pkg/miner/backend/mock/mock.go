@@ -0,0 +1,121 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package mock provides a scriptable InferenceBackend for tests. Unlike
+// noop, which always returns the same placeholder, a mock Backend returns
+// whatever responses and errors the test configures and records every
+// request it receives.
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/luxfi/ai/pkg/miner/backend"
+)
+
+// Backend is a scriptable backend.InferenceBackend and backend.ModelLister.
+// Set the exported fields before use; they must not be changed while the
+// backend is serving requests.
+type Backend struct {
+	// ChatResponse, InferenceResponse and EmbedResponse are returned by
+	// the corresponding methods. An empty Model is filled in from the
+	// request.
+	ChatResponse      backend.ChatResponse
+	InferenceResponse backend.InferenceResponse
+	EmbedResponse     backend.EmbedResponse
+
+	// ModelIDs is returned by Models.
+	ModelIDs []string
+
+	// Err, when non-nil, is returned by every method instead of a
+	// response.
+	Err error
+
+	mu         sync.Mutex
+	chats      []backend.ChatRequest
+	inferences []backend.InferenceRequest
+	embeddings []backend.EmbedRequest
+}
+
+// Name implements backend.InferenceBackend.
+func (*Backend) Name() string { return "mock" }
+
+// Capabilities implements backend.InferenceBackend.
+func (*Backend) Capabilities() backend.Capabilities {
+	return backend.Capabilities{Chat: true, Inference: true, Embedding: true}
+}
+
+// Chat implements backend.InferenceBackend.
+func (b *Backend) Chat(_ context.Context, req backend.ChatRequest) (backend.ChatResponse, error) {
+	b.mu.Lock()
+	b.chats = append(b.chats, req)
+	b.mu.Unlock()
+	if b.Err != nil {
+		return backend.ChatResponse{}, b.Err
+	}
+	resp := b.ChatResponse
+	if resp.Model == "" {
+		resp.Model = req.Model
+	}
+	return resp, nil
+}
+
+// Inference implements backend.InferenceBackend.
+func (b *Backend) Inference(_ context.Context, req backend.InferenceRequest) (backend.InferenceResponse, error) {
+	b.mu.Lock()
+	b.inferences = append(b.inferences, req)
+	b.mu.Unlock()
+	if b.Err != nil {
+		return backend.InferenceResponse{}, b.Err
+	}
+	resp := b.InferenceResponse
+	if resp.Model == "" {
+		resp.Model = req.Model
+	}
+	return resp, nil
+}
+
+// Embed implements backend.InferenceBackend.
+func (b *Backend) Embed(_ context.Context, req backend.EmbedRequest) (backend.EmbedResponse, error) {
+	b.mu.Lock()
+	b.embeddings = append(b.embeddings, req)
+	b.mu.Unlock()
+	if b.Err != nil {
+		return backend.EmbedResponse{}, b.Err
+	}
+	resp := b.EmbedResponse
+	if resp.Model == "" {
+		resp.Model = req.Model
+	}
+	return resp, nil
+}
+
+// Models implements backend.ModelLister.
+func (b *Backend) Models(context.Context) ([]string, error) {
+	if b.Err != nil {
+		return nil, b.Err
+	}
+	return append([]string(nil), b.ModelIDs...), nil
+}
+
+// ChatRequests returns the chat requests received so far.
+func (b *Backend) ChatRequests() []backend.ChatRequest {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]backend.ChatRequest(nil), b.chats...)
+}
+
+// InferenceRequests returns the inference requests received so far.
+func (b *Backend) InferenceRequests() []backend.InferenceRequest {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]backend.InferenceRequest(nil), b.inferences...)
+}
+
+// EmbedRequests returns the embedding requests received so far.
+func (b *Backend) EmbedRequests() []backend.EmbedRequest {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]backend.EmbedRequest(nil), b.embeddings...)
+}
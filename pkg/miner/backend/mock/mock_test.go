@@ -0,0 +1,45 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package mock
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/luxfi/ai/pkg/miner/backend"
+)
+
+func TestScriptedResponses(t *testing.T) {
+	ctx := context.Background()
+	b := &Backend{
+		ChatResponse: backend.ChatResponse{Role: "assistant", Content: "scripted"},
+		ModelIDs:     []string{"a", "b"},
+	}
+
+	resp, err := b.Chat(ctx, backend.ChatRequest{Model: "m", Stop: []string{"x"}})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.Content != "scripted" || resp.Model != "m" {
+		t.Errorf("Chat response: %+v", resp)
+	}
+	if reqs := b.ChatRequests(); len(reqs) != 1 || !reflect.DeepEqual(reqs[0].Stop, []string{"x"}) {
+		t.Errorf("ChatRequests: %+v", reqs)
+	}
+
+	models, err := backend.ListModels(ctx, b)
+	if err != nil || !reflect.DeepEqual(models, []string{"a", "b"}) {
+		t.Errorf("ListModels: got %v, %v", models, err)
+	}
+
+	b.Err = errors.New("boom")
+	if _, err := b.Embed(ctx, backend.EmbedRequest{}); !errors.Is(err, b.Err) {
+		t.Errorf("Embed error: got %v want %v", err, b.Err)
+	}
+	if len(b.EmbedRequests()) != 1 {
+		t.Errorf("EmbedRequests should record failed calls")
+	}
+}
@@ -55,9 +55,10 @@ func (b *Backend) Capabilities() backend.Capabilities {
 // the previous inline stub in miner.runChat.
 func (b *Backend) Chat(_ context.Context, req backend.ChatRequest) (backend.ChatResponse, error) {
 	return backend.ChatResponse{
-		Role:    "assistant",
-		Content: "I'm an AI assistant running on the Lux network.",
-		Model:   req.Model,
+		Role:         "assistant",
+		Content:      "I'm an AI assistant running on the Lux network.",
+		Model:        req.Model,
+		FinishReason: backend.FinishReasonStop,
 	}, nil
 }
 
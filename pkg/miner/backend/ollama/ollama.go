@@ -0,0 +1,322 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package ollama provides an InferenceBackend that talks to a local ollama
+// server over its native HTTP API (`/api/chat`, `/api/generate`,
+// `/api/embed`, `/api/tags`). Unlike the OpenAI-compatible endpoint, the
+// native API reports which models are pulled, so the miner can advertise
+// them to the node without extra configuration.
+//
+// This adapter uses only the Go standard library — no SDK dependency.
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luxfi/ai/pkg/miner/backend"
+)
+
+const (
+	// DefaultBaseURL is where `ollama serve` listens by default.
+	DefaultBaseURL = "http://localhost:11434"
+
+	// DefaultTimeout is the per-request HTTP timeout used when the caller
+	// does not supply an HTTPClient. Generous because ollama loads models
+	// on first use.
+	DefaultTimeout = 5 * time.Minute
+)
+
+// Config configures an ollama backend.
+type Config struct {
+	// BaseURL is the server root, e.g. "http://localhost:11434". Trailing
+	// slash is tolerated. Defaults to DefaultBaseURL.
+	BaseURL string
+	// Model is the default model name (e.g. "llama3.1") for requests whose
+	// own Model field is empty.
+	Model string
+	// EmbeddingModel overrides Model for embedding requests (e.g.
+	// "nomic-embed-text").
+	EmbeddingModel string
+	// HTTPClient is optional. When nil, a client with DefaultTimeout is
+	// used.
+	HTTPClient *http.Client
+}
+
+// Backend is the ollama InferenceBackend.
+type Backend struct {
+	cfg    Config
+	client *http.Client
+}
+
+// New returns a backend configured against cfg. If cfg.BaseURL is empty,
+// DefaultBaseURL is used.
+func New(cfg Config) *Backend {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	c := cfg.HTTPClient
+	if c == nil {
+		c = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &Backend{cfg: cfg, client: c}
+}
+
+// StatusError reports a non-2xx HTTP response from the ollama server.
+// Callers can `errors.As` against it to react to specific status codes
+// (e.g. 404 when a model has not been pulled).
+type StatusError struct {
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+	// APIMessage is the `error` field ollama returns in the JSON body, if
+	// any.
+	APIMessage string
+	// RawBody is the verbatim response body.
+	RawBody string
+}
+
+func (e *StatusError) Error() string {
+	if e.APIMessage != "" {
+		return fmt.Sprintf("ollama: %s (status %d)", e.APIMessage, e.StatusCode)
+	}
+	return fmt.Sprintf("ollama: status %d: %s", e.StatusCode, strings.TrimSpace(e.RawBody))
+}
+
+// Name implements backend.InferenceBackend.
+func (*Backend) Name() string { return "ollama" }
+
+// Capabilities implements backend.InferenceBackend.
+func (*Backend) Capabilities() backend.Capabilities {
+	return backend.Capabilities{
+		Chat:      true,
+		Inference: true,
+		Embedding: true,
+		// format "json" is enforced with grammar-constrained sampling.
+		JSONMode: true,
+	}
+}
+
+// --- chat ---
+
+type options struct {
+	NumPredict int      `json:"num_predict,omitempty"`
+	Stop       []string `json:"stop,omitempty"`
+	backend.Sampling
+}
+
+type chatRequest struct {
+	Model    string            `json:"model"`
+	Messages []backend.Message `json:"messages"`
+	Stream   bool              `json:"stream"`
+	Format   string            `json:"format,omitempty"`
+	Options  *options          `json:"options,omitempty"`
+}
+
+type chatResponse struct {
+	Model      string          `json:"model"`
+	Message    backend.Message `json:"message"`
+	DoneReason string          `json:"done_reason"`
+	EvalCount  int             `json:"eval_count"`
+}
+
+// Chat implements backend.InferenceBackend.
+func (b *Backend) Chat(ctx context.Context, req backend.ChatRequest) (backend.ChatResponse, error) {
+	payload := chatRequest{
+		Model:    b.model(req.Model),
+		Messages: req.Messages,
+		Options:  newOptions(req.MaxTokens, req.Stop, req.Sampling),
+	}
+	if req.ResponseFormat != nil && req.ResponseFormat.Type == backend.ResponseFormatJSONObject {
+		payload.Format = "json"
+	}
+
+	var resp chatResponse
+	if err := b.do(ctx, http.MethodPost, "/api/chat", payload, &resp); err != nil {
+		return backend.ChatResponse{}, err
+	}
+	role := resp.Message.Role
+	if role == "" {
+		role = "assistant"
+	}
+	return backend.ChatResponse{
+		Role:         role,
+		Content:      resp.Message.Content,
+		Model:        resp.Model,
+		Tokens:       resp.EvalCount,
+		FinishReason: finishReason(resp.DoneReason),
+	}, nil
+}
+
+// --- completion ---
+
+type generateRequest struct {
+	Model   string   `json:"model"`
+	Prompt  string   `json:"prompt"`
+	Stream  bool     `json:"stream"`
+	Options *options `json:"options,omitempty"`
+}
+
+type generateResponse struct {
+	Model     string `json:"model"`
+	Response  string `json:"response"`
+	EvalCount int    `json:"eval_count"`
+}
+
+// Inference implements backend.InferenceBackend via /api/generate.
+func (b *Backend) Inference(ctx context.Context, req backend.InferenceRequest) (backend.InferenceResponse, error) {
+	payload := generateRequest{
+		Model:   b.model(req.Model),
+		Prompt:  req.Prompt,
+		Options: newOptions(req.MaxTokens, nil, backend.Sampling{}),
+	}
+
+	var resp generateResponse
+	if err := b.do(ctx, http.MethodPost, "/api/generate", payload, &resp); err != nil {
+		return backend.InferenceResponse{}, err
+	}
+	return backend.InferenceResponse{
+		Text:   resp.Response,
+		Tokens: resp.EvalCount,
+		Model:  resp.Model,
+	}, nil
+}
+
+// --- embeddings ---
+
+type embedRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type embedResponse struct {
+	Model      string      `json:"model"`
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+// Embed implements backend.InferenceBackend via /api/embed.
+func (b *Backend) Embed(ctx context.Context, req backend.EmbedRequest) (backend.EmbedResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = b.cfg.EmbeddingModel
+	}
+	model = b.model(model)
+
+	var resp embedResponse
+	if err := b.do(ctx, http.MethodPost, "/api/embed", embedRequest{Model: model, Input: req.Text}, &resp); err != nil {
+		return backend.EmbedResponse{}, err
+	}
+	if len(resp.Embeddings) == 0 {
+		return backend.EmbedResponse{}, errors.New("ollama: embedding response has no data")
+	}
+	return backend.EmbedResponse{
+		Embedding: resp.Embeddings[0],
+		Model:     resp.Model,
+	}, nil
+}
+
+// --- models ---
+
+type tagsResponse struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+// Models implements backend.ModelLister, returning the models pulled into
+// the ollama server.
+func (b *Backend) Models(ctx context.Context) ([]string, error) {
+	var resp tagsResponse
+	if err := b.do(ctx, http.MethodGet, "/api/tags", nil, &resp); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(resp.Models))
+	for _, m := range resp.Models {
+		ids = append(ids, m.Name)
+	}
+	return ids, nil
+}
+
+// --- helpers ---
+
+// model returns id, or the configured default when id is empty.
+func (b *Backend) model(id string) string {
+	if id == "" {
+		return b.cfg.Model
+	}
+	return id
+}
+
+// newOptions returns generation options, or nil when none are set so the
+// server's model defaults apply.
+func newOptions(maxTokens int, stop []string, sampling backend.Sampling) *options {
+	if maxTokens <= 0 && len(stop) == 0 && sampling == (backend.Sampling{}) {
+		return nil
+	}
+	return &options{NumPredict: maxTokens, Stop: stop, Sampling: sampling}
+}
+
+// finishReason maps ollama's done_reason onto the OpenAI values.
+func finishReason(doneReason string) string {
+	if doneReason == "length" {
+		return backend.FinishReasonLength
+	}
+	return backend.FinishReasonStop
+}
+
+// --- HTTP plumbing ---
+
+func (b *Backend) do(ctx context.Context, method, path string, payload any, out any) error {
+	var reader io.Reader
+	if payload != nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("ollama: encode request: %w", err)
+		}
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, b.cfg.BaseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("ollama: build request: %w", err)
+	}
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := b.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("ollama: http: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("ollama: read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// ollama returns {"error": "..."}
+		var errEnv struct {
+			Error string `json:"error"`
+		}
+		statusErr := &StatusError{StatusCode: resp.StatusCode, RawBody: string(respBody)}
+		if jerr := json.Unmarshal(respBody, &errEnv); jerr == nil {
+			statusErr.APIMessage = errEnv.Error
+		}
+		return statusErr
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("ollama: decode response: %w", err)
+	}
+	return nil
+}
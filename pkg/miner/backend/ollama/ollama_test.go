@@ -0,0 +1,158 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/luxfi/ai/pkg/miner/backend"
+)
+
+// newServer serves reply on path and records the last request body
+func newServer(t *testing.T, path, reply string, gotBody *string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("request path: got %q want %q", r.URL.Path, path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if gotBody != nil {
+			*gotBody = string(body)
+		}
+		_, _ = w.Write([]byte(reply))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestName(t *testing.T) {
+	if got := New(Config{}).Name(); got != "ollama" {
+		t.Errorf("Name: got %q want %q", got, "ollama")
+	}
+}
+
+func TestChat(t *testing.T) {
+	var gotBody string
+	srv := newServer(t, "/api/chat", `{
+		"model": "llama3.1",
+		"message": {"role": "assistant", "content": "{\"ok\":true}"},
+		"done": true,
+		"done_reason": "length",
+		"eval_count": 7
+	}`, &gotBody)
+
+	temperature, seed := 0.2, int64(7)
+	b := New(Config{BaseURL: srv.URL + "/", Model: "llama3.1"})
+	resp, err := b.Chat(context.Background(), backend.ChatRequest{
+		Messages:       []backend.Message{{Role: "user", Content: "hello"}},
+		MaxTokens:      16,
+		Stop:           []string{"END"},
+		ResponseFormat: &backend.ResponseFormat{Type: backend.ResponseFormatJSONObject},
+		Sampling:       backend.Sampling{Temperature: &temperature, Seed: &seed},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	for _, want := range []string{`"model":"llama3.1"`, `"stream":false`, `"format":"json"`, `"num_predict":16`, `"stop":["END"]`, `"temperature":0.2`, `"seed":7`} {
+		if !strings.Contains(gotBody, want) {
+			t.Errorf("request body missing %s: %s", want, gotBody)
+		}
+	}
+	if resp.Content != `{"ok":true}` || resp.Tokens != 7 || resp.Model != "llama3.1" {
+		t.Errorf("Chat response: %+v", resp)
+	}
+	if resp.FinishReason != backend.FinishReasonLength {
+		t.Errorf("FinishReason: got %q want %q", resp.FinishReason, backend.FinishReasonLength)
+	}
+}
+
+func TestChatOmitsUnsetOptions(t *testing.T) {
+	var gotBody string
+	srv := newServer(t, "/api/chat", `{"message":{"content":"hi"},"done_reason":"stop"}`, &gotBody)
+
+	resp, err := New(Config{BaseURL: srv.URL}).Chat(context.Background(), backend.ChatRequest{Model: "m"})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if strings.Contains(gotBody, "options") || strings.Contains(gotBody, "format") {
+		t.Errorf("unset options sent: %s", gotBody)
+	}
+	if resp.Role != "assistant" || resp.FinishReason != backend.FinishReasonStop {
+		t.Errorf("Chat response: %+v", resp)
+	}
+}
+
+func TestInference(t *testing.T) {
+	var gotBody string
+	srv := newServer(t, "/api/generate", `{"model":"m","response":"done","eval_count":3}`, &gotBody)
+
+	resp, err := New(Config{BaseURL: srv.URL}).Inference(context.Background(), backend.InferenceRequest{Model: "m", Prompt: "p"})
+	if err != nil {
+		t.Fatalf("Inference: %v", err)
+	}
+	if !strings.Contains(gotBody, `"prompt":"p"`) {
+		t.Errorf("request body missing prompt: %s", gotBody)
+	}
+	if resp.Text != "done" || resp.Tokens != 3 {
+		t.Errorf("Inference response: %+v", resp)
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	var gotBody string
+	srv := newServer(t, "/api/embed", `{"model":"nomic-embed-text","embeddings":[[0.1,0.2]]}`, &gotBody)
+
+	b := New(Config{BaseURL: srv.URL, Model: "llama3.1", EmbeddingModel: "nomic-embed-text"})
+	resp, err := b.Embed(context.Background(), backend.EmbedRequest{Text: "hi"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if !strings.Contains(gotBody, `"model":"nomic-embed-text"`) {
+		t.Errorf("request body should use EmbeddingModel: %s", gotBody)
+	}
+	if !reflect.DeepEqual(resp.Embedding, []float64{0.1, 0.2}) {
+		t.Errorf("Embedding: got %v", resp.Embedding)
+	}
+
+	empty := newServer(t, "/api/embed", `{"embeddings":[]}`, nil)
+	if _, err := New(Config{BaseURL: empty.URL}).Embed(context.Background(), backend.EmbedRequest{}); err == nil {
+		t.Error("Embed with no data should fail")
+	}
+}
+
+func TestModels(t *testing.T) {
+	srv := newServer(t, "/api/tags", `{"models":[{"name":"llama3.1:latest"},{"name":"nomic-embed-text:latest"}]}`, nil)
+
+	got, err := New(Config{BaseURL: srv.URL}).Models(context.Background())
+	if err != nil {
+		t.Fatalf("Models: %v", err)
+	}
+	if want := []string{"llama3.1:latest", "nomic-embed-text:latest"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Models: got %v want %v", got, want)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"model \"missing\" not found, try pulling it first"}`))
+	}))
+	defer srv.Close()
+
+	_, err := New(Config{BaseURL: srv.URL}).Chat(context.Background(), backend.ChatRequest{Model: "missing"})
+	var se *StatusError
+	if !errors.As(err, &se) {
+		t.Fatalf("error should be *StatusError, got %T: %v", err, err)
+	}
+	if se.StatusCode != http.StatusNotFound || !strings.Contains(se.APIMessage, "not found") {
+		t.Errorf("StatusError: %+v", se)
+	}
+}
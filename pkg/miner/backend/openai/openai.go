@@ -114,6 +114,9 @@ func (*Backend) Capabilities() backend.Capabilities {
 		Inference: true,
 		Embedding: true,
 		// EmbeddingDims deliberately 0 — varies per model.
+		// response_format is part of the dialect; llama.cpp, vllm and
+		// ollama enforce it with grammar-constrained sampling.
+		JSONMode: true,
 	}
 }
 
@@ -125,9 +128,12 @@ type chatMessage struct {
 }
 
 type chatCompletionRequest struct {
-	Model     string        `json:"model"`
-	Messages  []chatMessage `json:"messages"`
-	MaxTokens int           `json:"max_tokens,omitempty"`
+	Model          string                  `json:"model"`
+	Messages       []chatMessage           `json:"messages"`
+	MaxTokens      int                     `json:"max_tokens,omitempty"`
+	ResponseFormat *backend.ResponseFormat `json:"response_format,omitempty"`
+	Stop           []string                `json:"stop,omitempty"`
+	backend.Sampling
 }
 
 type chatCompletionChoice struct {
@@ -162,9 +168,12 @@ func (b *Backend) Chat(ctx context.Context, req backend.ChatRequest) (backend.Ch
 	}
 
 	payload := chatCompletionRequest{
-		Model:     model,
-		Messages:  msgs,
-		MaxTokens: req.MaxTokens,
+		Model:          model,
+		Messages:       msgs,
+		MaxTokens:      req.MaxTokens,
+		ResponseFormat: req.ResponseFormat,
+		Stop:           req.Stop,
+		Sampling:       req.Sampling,
 	}
 
 	var resp chatCompletionResponse
@@ -177,10 +186,11 @@ func (b *Backend) Chat(ctx context.Context, req backend.ChatRequest) (backend.Ch
 	}
 	c := resp.Choices[0].Message
 	return backend.ChatResponse{
-		Role:    c.Role,
-		Content: c.Content,
-		Model:   resp.Model,
-		Tokens:  resp.Usage.CompletionTokens,
+		Role:         c.Role,
+		Content:      c.Content,
+		Model:        resp.Model,
+		Tokens:       resp.Usage.CompletionTokens,
+		FinishReason: resp.Choices[0].FinishReason,
 	}, nil
 }
 
@@ -316,20 +326,53 @@ func (b *Backend) Embed(ctx context.Context, req backend.EmbedRequest) (backend.
 	}, nil
 }
 
+// --- models ---
+
+type modelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// Models implements backend.ModelLister via GET /models. Against the public
+// OpenAI API this lists every model the key can use.
+func (b *Backend) Models(ctx context.Context) ([]string, error) {
+	var resp modelsResponse
+	if err := b.do(ctx, http.MethodGet, "/models", nil, &resp); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(resp.Data))
+	for _, m := range resp.Data {
+		ids = append(ids, m.ID)
+	}
+	return ids, nil
+}
+
 // --- HTTP plumbing ---
 
 func (b *Backend) post(ctx context.Context, path string, payload any, out any) error {
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("openai: encode request: %w", err)
+	return b.do(ctx, http.MethodPost, path, payload, out)
+}
+
+// do sends a request with a JSON payload, or none when payload is nil.
+func (b *Backend) do(ctx context.Context, method, path string, payload any, out any) error {
+	var reader io.Reader
+	if payload != nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("openai: encode request: %w", err)
+		}
+		reader = bytes.NewReader(body)
 	}
 
 	url := b.cfg.BaseURL + path
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, reader)
 	if err != nil {
 		return fmt.Errorf("openai: build request: %w", err)
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
 	httpReq.Header.Set("Accept", "application/json")
 	if b.cfg.APIKey != "" {
 		httpReq.Header.Set("Authorization", "Bearer "+b.cfg.APIKey)
@@ -75,6 +75,80 @@ func TestChatHappyPath(t *testing.T) {
 	}
 }
 
+func TestChatPassesResponseFormat(t *testing.T) {
+	var gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{}"}}]}`))
+	}))
+	defer srv.Close()
+
+	b := New(Config{BaseURL: srv.URL})
+	_, err := b.Chat(context.Background(), backend.ChatRequest{
+		Messages:       []backend.Message{{Role: "user", Content: "hello"}},
+		ResponseFormat: &backend.ResponseFormat{Type: backend.ResponseFormatJSONObject},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if !strings.Contains(gotBody, `"response_format":{"type":"json_object"}`) {
+		t.Errorf("request body missing response_format: %s", gotBody)
+	}
+
+	if _, err := b.Chat(context.Background(), backend.ChatRequest{}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if strings.Contains(gotBody, "response_format") {
+		t.Errorf("response_format sent when unset: %s", gotBody)
+	}
+}
+
+func TestChatPassesStop(t *testing.T) {
+	var gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"one"},"finish_reason":"length"}]}`))
+	}))
+	defer srv.Close()
+
+	b := New(Config{BaseURL: srv.URL})
+	resp, err := b.Chat(context.Background(), backend.ChatRequest{
+		Messages: []backend.Message{{Role: "user", Content: "hello"}},
+		Stop:     []string{"END", "###"},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if !strings.Contains(gotBody, `"stop":["END","###"]`) {
+		t.Errorf("request body missing stop: %s", gotBody)
+	}
+	if resp.FinishReason != backend.FinishReasonLength {
+		t.Errorf("FinishReason = %q, want %q", resp.FinishReason, backend.FinishReasonLength)
+	}
+}
+
+func TestModels(t *testing.T) {
+	var gotMethod, gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"gpt-a"},{"id":"gpt-b"}]}`))
+	}))
+	defer srv.Close()
+
+	got, err := New(Config{BaseURL: srv.URL}).Models(context.Background())
+	if err != nil {
+		t.Fatalf("Models: %v", err)
+	}
+	if gotMethod != http.MethodGet || gotPath != "/models" {
+		t.Errorf("request: got %s %s want GET /models", gotMethod, gotPath)
+	}
+	if len(got) != 2 || got[0] != "gpt-a" || got[1] != "gpt-b" {
+		t.Errorf("Models: got %v", got)
+	}
+}
+
 func TestChatDefaultsModelFromConfig(t *testing.T) {
 	var sawModel string
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -206,7 +280,7 @@ func TestNoAuthHeaderWhenAPIKeyEmpty(t *testing.T) {
 
 func TestCapabilities(t *testing.T) {
 	caps := New(Config{}).Capabilities()
-	if !caps.Chat || !caps.Inference || !caps.Embedding {
+	if !caps.Chat || !caps.Inference || !caps.Embedding || !caps.JSONMode {
 		t.Errorf("capabilities: %+v", caps)
 	}
 }
@@ -391,3 +465,31 @@ func TestStatusErrorFormatIsStable(t *testing.T) {
 		}
 	})
 }
+
+func TestChatPassesSampling(t *testing.T) {
+	var gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer srv.Close()
+
+	topP, penalty, seed := 0.9, -0.5, int64(0)
+	b := New(Config{BaseURL: srv.URL})
+	_, err := b.Chat(context.Background(), backend.ChatRequest{
+		Messages: []backend.Message{{Role: "user", Content: "hello"}},
+		Sampling: backend.Sampling{TopP: &topP, PresencePenalty: &penalty, Seed: &seed},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	for _, want := range []string{`"top_p":0.9`, `"presence_penalty":-0.5`, `"seed":0`} {
+		if !strings.Contains(gotBody, want) {
+			t.Errorf("request body missing %s: %s", want, gotBody)
+		}
+	}
+	if strings.Contains(gotBody, "frequency_penalty") || strings.Contains(gotBody, "temperature") {
+		t.Errorf("unset sampling parameters sent: %s", gotBody)
+	}
+}
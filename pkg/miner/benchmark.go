@@ -0,0 +1,106 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/luxfi/ai/pkg/miner/backend"
+)
+
+// ErrBenchmarkFailed is returned when the startup benchmark produces no
+// output to measure.
+var ErrBenchmarkFailed = errors.New("benchmark produced no tokens")
+
+const (
+	// DefaultBenchmarkModel is the reference model benchmarked when
+	// Config.BenchmarkModel is unset.
+	DefaultBenchmarkModel = "zen-mini-0.5b"
+
+	// DefaultBenchmarkTimeout bounds the startup benchmark when
+	// Config.BenchmarkTimeout is unset.
+	DefaultBenchmarkTimeout = 30 * time.Second
+
+	// benchmarkRuns is how many reference completions are timed.
+	benchmarkRuns = 3
+
+	// benchmarkMaxTokens is the completion length requested per run.
+	benchmarkMaxTokens = 128
+
+	// benchmarkPrompt is the reference prompt; it asks for a long answer so
+	// decode time dominates.
+	benchmarkPrompt = "Explain, step by step and in detail, how a transformer language model generates text."
+)
+
+// Benchmark measures the backend's throughput in tokens per second by
+// timing a few completions of a reference prompt against
+// Config.BenchmarkModel. The result is kept as the miner's capacity and
+// reported to the node on Register. Start runs it unless
+// Config.SkipBenchmark is set.
+func (m *Miner) Benchmark(ctx context.Context) (float64, error) {
+	model := m.config.BenchmarkModel
+	if model == "" {
+		model = DefaultBenchmarkModel
+	}
+	b := m.Backend()
+
+	var tokens int
+	start := time.Now()
+	for i := 0; i < benchmarkRuns; i++ {
+		resp, err := b.Chat(ctx, backend.ChatRequest{
+			Model:     model,
+			Messages:  []backend.Message{{Role: "user", Content: benchmarkPrompt}},
+			MaxTokens: benchmarkMaxTokens,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if resp.Tokens > 0 {
+			tokens += resp.Tokens
+		} else {
+			tokens += estimateTokens(resp.Content)
+		}
+	}
+	elapsed := time.Since(start)
+	if tokens == 0 {
+		return 0, ErrBenchmarkFailed
+	}
+	if elapsed <= 0 {
+		elapsed = time.Microsecond
+	}
+
+	capacity := float64(tokens) / elapsed.Seconds()
+	m.mu.Lock()
+	m.capacity = capacity
+	m.mu.Unlock()
+	return capacity, nil
+}
+
+// Capacity returns the benchmarked throughput in tokens per second, or zero
+// if no benchmark has completed.
+func (m *Miner) Capacity() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.capacity
+}
+
+// runStartupBenchmark benchmarks the backend during Start. A failed
+// benchmark leaves capacity unknown rather than failing startup.
+func (m *Miner) runStartupBenchmark(ctx context.Context) {
+	timeout := m.config.BenchmarkTimeout
+	if timeout <= 0 {
+		timeout = DefaultBenchmarkTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	m.Benchmark(ctx)
+}
+
+// estimateTokens approximates a token count from text length for backends
+// that don't report usage (~4 characters per token).
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
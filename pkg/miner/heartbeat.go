@@ -0,0 +1,94 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/luxfi/ai/internal/minersig"
+)
+
+// ErrNoSigningKey is returned by Heartbeat when Config.SigningKey is unset.
+var ErrNoSigningKey = errors.New("no heartbeat signing key configured")
+
+const (
+	// DefaultHeartbeatInterval is how often heartbeats are sent when
+	// Config.HeartbeatInterval is unset.
+	DefaultHeartbeatInterval = 30 * time.Second
+
+	// heartbeatNonceSize is the length of each heartbeat's random nonce.
+	heartbeatNonceSize = 16
+)
+
+// Heartbeat sends the node a liveness report signed with
+// Config.SigningKey over the miner ID, the current time and a fresh nonce.
+// The miner must be registered. Tasks the node reports cancelled in its
+// reply are stopped.
+func (m *Miner) Heartbeat(ctx context.Context) error {
+	key := m.config.SigningKey
+	if key == nil {
+		return ErrNoSigningKey
+	}
+	m.mu.RLock()
+	token := m.nodeToken
+	m.mu.RUnlock()
+	if token == "" {
+		return ErrNotRegistered
+	}
+
+	nonce := make([]byte, heartbeatNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	now := time.Now()
+	digest := minersig.HeartbeatDigest(m.ID(), now, nonce)
+	body, err := json.Marshal(map[string]interface{}{
+		"id":        m.ID(),
+		"timestamp": now,
+		"nonce":     nonce,
+		"signature": ed25519.Sign(key, digest[:]),
+	})
+	if err != nil {
+		return err
+	}
+	var reply struct {
+		Cancelled []string `json:"cancelled"`
+	}
+	if err := m.postNode(ctx, "/api/miners/heartbeat", token, body, &reply); err != nil {
+		return err
+	}
+	for _, id := range reply.Cancelled {
+		m.cancelTask(id)
+	}
+	return nil
+}
+
+// sendHeartbeats sends a heartbeat every HeartbeatInterval while the miner
+// is registered, until it stops.
+func (m *Miner) sendHeartbeats(ctx context.Context) {
+	interval := m.config.HeartbeatInterval
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			if err := m.Heartbeat(ctx); err != nil {
+				m.logf(LogError, "", "heartbeat: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLogBufferSize is how many recent log entries the miner keeps
+	// when Config.LogBufferSize is zero.
+	DefaultLogBufferSize = 1000
+
+	// maxLogStreams caps concurrent /logs streams so a misbehaving client
+	// cannot tie up the miner's API.
+	maxLogStreams = 4
+
+	// logKeepAlive is how often an idle /logs stream sends a comment so
+	// proxies don't close it.
+	logKeepAlive = 15 * time.Second
+)
+
+// Log levels.
+const (
+	LogInfo  = "info"
+	LogError = "error"
+)
+
+// LogEntry is one line of the miner's activity log.
+type LogEntry struct {
+	Seq     uint64    `json:"seq"`
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Task    string    `json:"task,omitempty"`
+	Message string    `json:"message"`
+}
+
+// logRing keeps the most recent log entries and wakes streams when one is
+// added. It has its own lock so the miner can log while holding m.mu.
+type logRing struct {
+	mu      sync.Mutex
+	entries []LogEntry // Oldest first, at most size
+	size    int
+	seq     uint64        // Seq of the newest entry
+	wake    chan struct{} // Closed and replaced on every append
+	streams int           // Open /logs streams
+}
+
+func newLogRing(size int) *logRing {
+	if size <= 0 {
+		size = DefaultLogBufferSize
+	}
+	return &logRing{size: size, wake: make(chan struct{})}
+}
+
+func (l *logRing) add(level, task, msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	if len(l.entries) == l.size {
+		copy(l.entries, l.entries[1:])
+		l.entries = l.entries[:l.size-1]
+	}
+	l.entries = append(l.entries, LogEntry{
+		Seq:     l.seq,
+		Time:    time.Now(),
+		Level:   level,
+		Task:    task,
+		Message: msg,
+	})
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// since returns the buffered entries newer than seq and a channel closed
+// when another is added.
+func (l *logRing) since(seq uint64) ([]LogEntry, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i := len(l.entries)
+	for i > 0 && l.entries[i-1].Seq > seq {
+		i--
+	}
+	return append([]LogEntry(nil), l.entries[i:]...), l.wake
+}
+
+// tail returns the last n buffered entries, or all of them if n <= 0.
+func (l *logRing) tail(n int) []LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n <= 0 || n > len(l.entries) {
+		n = len(l.entries)
+	}
+	return append([]LogEntry(nil), l.entries[len(l.entries)-n:]...)
+}
+
+// openStream reserves one of the maxLogStreams stream slots.
+func (l *logRing) openStream() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.streams >= maxLogStreams {
+		return false
+	}
+	l.streams++
+	return true
+}
+
+func (l *logRing) closeStream() {
+	l.mu.Lock()
+	l.streams--
+	l.mu.Unlock()
+}
+
+// logf records a log entry, attributed to task when it is non-empty.
+func (m *Miner) logf(level, task, format string, args ...interface{}) {
+	m.logs.add(level, task, fmt.Sprintf(format, args...))
+}
+
+// Logs returns the last n entries of the miner's activity log, oldest
+// first, or every buffered entry if n <= 0.
+func (m *Miner) Logs(n int) []LogEntry {
+	return m.logs.tail(n)
+}
+
+// handleLogs streams the miner's activity log as server-sent events, one
+// JSON LogEntry per event with its Seq as the event ID. The stream starts
+// with the buffered entries after ?since= or the Last-Event-ID header, or
+// the last ?tail= entries, and then follows new entries until the client
+// disconnects or the miner stops. Requests must carry Config.LogToken as a
+// bearer token; the endpoint is disabled when it is unset.
+func (m *Miner) handleLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if m.config.LogToken == "" {
+		http.Error(w, "log streaming disabled", http.StatusForbidden)
+		return
+	}
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(m.config.LogToken)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var seq uint64
+	q := r.URL.Query()
+	switch {
+	case q.Get("since") != "" || r.Header.Get("Last-Event-ID") != "":
+		s := q.Get("since")
+		if s == "" {
+			s = r.Header.Get("Last-Event-ID")
+		}
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid since: "+s, http.StatusBadRequest)
+			return
+		}
+		seq = n
+	case q.Get("tail") != "":
+		n, err := strconv.Atoi(q.Get("tail"))
+		if err != nil || n < 0 {
+			http.Error(w, "invalid tail: "+q.Get("tail"), http.StatusBadRequest)
+			return
+		}
+		if n > 0 {
+			if entries := m.logs.tail(n); len(entries) > 0 {
+				seq = entries[0].Seq - 1
+			}
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	if !m.logs.openStream() {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, "too many log streams", http.StatusTooManyRequests)
+		return
+	}
+	defer m.logs.closeStream()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(logKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		entries, wake := m.logs.since(seq)
+		for _, e := range entries {
+			data, _ := json.Marshal(e)
+			fmt.Fprintf(w, "id: %d\ndata: %s\n\n", e.Seq, data)
+			seq = e.Seq
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-m.stopCh:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-wake:
+		}
+	}
+}
@@ -5,17 +5,23 @@ package miner
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"sync"
 	"time"
 
+	"github.com/luxfi/ai/internal/minersig"
+	"github.com/luxfi/ai/pkg/cc"
 	"github.com/luxfi/ai/pkg/miner/backend"
+	"github.com/luxfi/ai/pkg/miner/backend/llamacpp"
 	"github.com/luxfi/ai/pkg/miner/backend/noop"
+	"github.com/luxfi/ai/pkg/miner/backend/ollama"
 	"github.com/luxfi/ai/pkg/miner/backend/openai"
 )
 
@@ -48,6 +54,12 @@ type Task struct {
 	CreatedAt time.Time       `json:"created_at"`
 	StartedAt *time.Time      `json:"started_at,omitempty"`
 	EndedAt   *time.Time      `json:"ended_at,omitempty"`
+	Error     string          `json:"error,omitempty"` // Why a failed task failed
+
+	// Signature by Config.SigningKey over minersig.ResultDigest, set on a
+	// completed result when submitting it
+	Signature []byte     `json:"signature,omitempty"`
+	SignedAt  *time.Time `json:"signed_at,omitempty"`
 }
 
 // Stats tracks miner statistics
@@ -63,19 +75,56 @@ type Stats struct {
 
 // Config holds miner configuration
 type Config struct {
+	// MinerID is the identity used when registering with the node. Empty
+	// means the wallet address is used.
+	MinerID string `json:"miner_id,omitempty"`
+
 	WalletAddress string `json:"wallet_address"`
-	NodeURL       string `json:"node_url"`
+	NodeURL       string `json:"node_url"` // lux-ai node API root, e.g. http://localhost:9090
 	GPUEnabled    bool   `json:"gpu_enabled"`
 	MaxTasks      int    `json:"max_tasks"`
 	CacheSize     int64  `json:"cache_size"` // in bytes
 	ModelDir      string `json:"model_dir"`
 	APIPort       int    `json:"api_port"`
 
+	// Endpoint is the URL at which the node can reach this miner's API,
+	// sent at registration. Empty means http://localhost:<APIPort>.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// GPUMemoryMB is the GPU memory in MiB, as nvidia-smi reports it, sent
+	// at registration so the node only routes models that fit. Zero leaves
+	// it unreported.
+	GPUMemoryMB uint64 `json:"gpu_memory_mb,omitempty"`
+
+	// Region is the locality reported at registration, e.g. "us-east", so
+	// the node can prefer nearby miners for requests hinting that region.
+	Region string `json:"region,omitempty"`
+
+	// ModelingLevels, if set, are the only modeling levels the node assigns
+	// this miner, each with its concurrency limit (0 = no limit). The node
+	// rejects levels whose minimum VRAM exceeds GPUMemoryMB.
+	ModelingLevels map[cc.ModelingLevel]int `json:"modeling_levels,omitempty"`
+
+	// Capability is the detected hardware capability reported at
+	// registration. The node rejects the registration if its MaxTier is
+	// better than the hardware supports. Nil leaves it unreported.
+	Capability *cc.HardwareCapability `json:"capability,omitempty"`
+
+	// SigningKey signs the miner's heartbeats and completed results. Its
+	// public key is sent at registration, after which the node only accepts
+	// heartbeats and results it signed. Nil disables heartbeats.
+	SigningKey ed25519.PrivateKey `json:"-"`
+
+	// HeartbeatInterval is how often a registered miner with a SigningKey
+	// sends a heartbeat. Zero means DefaultHeartbeatInterval.
+	HeartbeatInterval time.Duration `json:"heartbeat_interval,omitempty"`
+
 	// Backend selects the inference-engine adapter used by the miner.
 	// Supported values: "noop" (default, deterministic mock), "openai"
 	// (OpenAI-compatible HTTP — works for the public OpenAI API and for
 	// local engines like llama.cpp server, vllm, ollama, and LocalAI that
-	// expose the same dialect).
+	// expose the same dialect), "ollama" (native ollama API) and
+	// "llamacpp" (llama.cpp server).
 	//
 	// When the value is empty the miner falls back to the noop backend, so
 	// existing callers see no behaviour change.
@@ -86,8 +135,9 @@ type Config struct {
 	// for ollama). Only used when Backend == "openai".
 	OpenAIBase string `json:"openai_base,omitempty"`
 
-	// OpenAIAPIKey is the bearer token sent with OpenAI requests. Empty is
-	// fine for local engines that don't authenticate.
+	// OpenAIAPIKey is the bearer token sent with OpenAI requests, and to
+	// llama.cpp servers started with --api-key. Empty is fine for local
+	// engines that don't authenticate.
 	OpenAIAPIKey string `json:"openai_api_key,omitempty"`
 
 	// OpenAIModel is the default model name passed to the OpenAI backend
@@ -96,12 +146,59 @@ type Config struct {
 
 	// OpenAIEmbeddingModel overrides OpenAIModel for embedding tasks.
 	OpenAIEmbeddingModel string `json:"openai_embedding_model,omitempty"`
+
+	// BackendURL is the server root for the "ollama" and "llamacpp"
+	// backends. Empty uses the adapter's default local address.
+	BackendURL string `json:"backend_url,omitempty"`
+
+	// BackendModel is the default model for the "ollama" backend when the
+	// caller doesn't set Task.Model. llama.cpp serves a single model and
+	// ignores it.
+	BackendModel string `json:"backend_model,omitempty"`
+
+	// NodeTimeout bounds each HTTP call to NodeURL so a hung node cannot
+	// wedge the miner. Zero means DefaultNodeTimeout.
+	NodeTimeout time.Duration `json:"node_timeout,omitempty"`
+
+	// NodeRetries is how many times a failed node call is retried. GETs
+	// retry timeouts and 5xx; POSTs only retry when the node cannot have
+	// acted on them (refused connections, 429 and 503). Zero means
+	// DefaultNodeRetries; negative disables retries.
+	NodeRetries int `json:"node_retries,omitempty"`
+
+	// WatchModels rescans ModelDir while running so models can be added or
+	// removed without a restart. See RefreshModels.
+	WatchModels bool `json:"watch_models,omitempty"`
+
+	// ModelScanInterval is how often ModelDir is rescanned when
+	// WatchModels is set. Zero means DefaultModelScanInterval.
+	ModelScanInterval time.Duration `json:"model_scan_interval,omitempty"`
+
+	// SkipBenchmark skips the throughput benchmark Start otherwise runs,
+	// for fast startup. The node then has no capacity figure for the miner.
+	SkipBenchmark bool `json:"skip_benchmark,omitempty"`
+
+	// BenchmarkModel is the reference model benchmarked at startup. Empty
+	// means DefaultBenchmarkModel.
+	BenchmarkModel string `json:"benchmark_model,omitempty"`
+
+	// BenchmarkTimeout bounds the startup benchmark. Zero means
+	// DefaultBenchmarkTimeout.
+	BenchmarkTimeout time.Duration `json:"benchmark_timeout,omitempty"`
+
+	// LogToken is the bearer token operators use to stream the miner's
+	// activity log from GET /logs. Empty disables the endpoint.
+	LogToken string `json:"log_token,omitempty"`
+
+	// LogBufferSize is how many recent log entries are kept for /logs.
+	// Zero means DefaultLogBufferSize.
+	LogBufferSize int `json:"log_buffer_size,omitempty"`
 }
 
 // DefaultConfig returns default configuration
 func DefaultConfig() Config {
 	return Config{
-		NodeURL:    "http://localhost:9650",
+		NodeURL:    "http://localhost:9090",
 		GPUEnabled: true,
 		MaxTasks:   10,
 		CacheSize:  10 * 1024 * 1024 * 1024, // 10GB
@@ -128,6 +225,34 @@ type Miner struct {
 	// keeps GetStats zero-cost on systems without GPU telemetry wired.
 	gpuStatsProvider GPUStatsProvider
 
+	// Bearer token issued by the node on Register; empty when unregistered.
+	nodeToken string
+
+	// Cancel functions of the tasks being processed, keyed by ID, so a
+	// task the node cancels stops early; see cancelTask.
+	taskCancels map[string]context.CancelFunc
+
+	// Client for all calls to NodeURL; deadlines are applied per call.
+	httpClient *http.Client
+
+	// Model files discovered in ModelDir, keyed by ID. refreshMu serializes
+	// RefreshModels so concurrent scans don't load a model twice.
+	models    map[string]*localModel
+	refreshMu sync.Mutex
+
+	// Benchmarked throughput in tokens/sec; zero until Benchmark succeeds.
+	capacity float64
+
+	// Attestation flow and its latest result; see SetAttester. The failure
+	// count and error describe attempts since the last success.
+	attester       Attester
+	attestation    *Attestation
+	attestFailures int
+	attestErr      string
+
+	// Recent task events and errors, streamed by /logs.
+	logs *logRing
+
 	// Channels
 	taskCh   chan *Task
 	resultCh chan *Task
@@ -142,12 +267,16 @@ type Miner struct {
 // callers see no behaviour change.
 func New(config Config) *Miner {
 	return &Miner{
-		config:   config,
-		tasks:    make(map[string]*Task),
-		backend:  newBackend(config),
-		taskCh:   make(chan *Task, config.MaxTasks),
-		resultCh: make(chan *Task, config.MaxTasks),
-		stopCh:   make(chan struct{}),
+		config:      config,
+		tasks:       make(map[string]*Task),
+		backend:     newBackend(config),
+		httpClient:  &http.Client{},
+		taskCancels: make(map[string]context.CancelFunc),
+		models:      make(map[string]*localModel),
+		logs:        newLogRing(config.LogBufferSize),
+		taskCh:      make(chan *Task, config.MaxTasks),
+		resultCh:    make(chan *Task, config.MaxTasks),
+		stopCh:      make(chan struct{}),
 	}
 }
 
@@ -162,6 +291,16 @@ func newBackend(cfg Config) backend.InferenceBackend {
 			Model:          cfg.OpenAIModel,
 			EmbeddingModel: cfg.OpenAIEmbeddingModel,
 		})
+	case "ollama":
+		return ollama.New(ollama.Config{
+			BaseURL: cfg.BackendURL,
+			Model:   cfg.BackendModel,
+		})
+	case "llamacpp", "llama.cpp":
+		return llamacpp.New(llamacpp.Config{
+			BaseURL: cfg.BackendURL,
+			APIKey:  cfg.OpenAIAPIKey,
+		})
 	case "", "noop":
 		return noop.New()
 	default:
@@ -206,6 +345,10 @@ func (m *Miner) Start(ctx context.Context) error {
 	m.startTime = time.Now()
 	m.mu.Unlock()
 
+	if !m.config.SkipBenchmark {
+		m.runStartupBenchmark(ctx)
+	}
+
 	// Start task worker
 	go m.taskWorker(ctx)
 
@@ -218,6 +361,21 @@ func (m *Miner) Start(ctx context.Context) error {
 	// Main mining loop
 	go m.miningLoop(ctx)
 
+	if m.config.WatchModels {
+		go m.watchModels(ctx)
+	}
+
+	if m.config.SigningKey != nil {
+		go m.sendHeartbeats(ctx)
+	}
+
+	m.mu.RLock()
+	attests := m.attester != nil
+	m.mu.RUnlock()
+	if attests {
+		go m.refreshAttestation(ctx)
+	}
+
 	return nil
 }
 
@@ -233,6 +391,12 @@ func (m *Miner) Stop() error {
 
 	close(m.stopCh)
 
+	// Best-effort: tell the node we're leaving so our tasks are reassigned
+	// promptly instead of waiting for the miner to be swept as stale.
+	deregCtx, deregCancel := context.WithTimeout(context.Background(), deregisterTimeout)
+	defer deregCancel()
+	m.Deregister(deregCtx)
+
 	if m.server != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -324,11 +488,14 @@ func (m *Miner) GetTask(id string) (*Task, error) {
 	return task, nil
 }
 
-// miningLoop polls for new tasks from the network
+// miningLoop registers with the node and polls it for new tasks. A miner
+// that is not registered, because the node was unreachable or has since
+// deregistered it, registers again before polling.
 func (m *Miner) miningLoop(ctx context.Context) {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
+	m.ensureRegistered(ctx)
 	for {
 		select {
 		case <-ctx.Done():
@@ -336,44 +503,57 @@ func (m *Miner) miningLoop(ctx context.Context) {
 		case <-m.stopCh:
 			return
 		case <-ticker.C:
-			m.pollForTasks(ctx)
+			if m.ensureRegistered(ctx) {
+				m.pollForTasks(ctx)
+			}
 		}
 	}
 }
 
-// pollForTasks checks the node for available tasks
-func (m *Miner) pollForTasks(ctx context.Context) {
-	// In production, this would query the AIVM for pending tasks
-	// For now, we just log that we're polling
+// ensureRegistered registers with the node unless the miner already holds
+// a node token, and reports whether it does afterwards. Failures are
+// logged by Register and retried on the next poll.
+func (m *Miner) ensureRegistered(ctx context.Context) bool {
 	m.mu.RLock()
-	running := m.running
+	registered := m.nodeToken != ""
 	m.mu.RUnlock()
-
-	if !running {
-		return
+	if registered {
+		return true
 	}
+	return m.Register(ctx, m.endpoint()) == nil
+}
 
-	// Query node for tasks
-	url := fmt.Sprintf("%s/ext/bc/A/ai/pendingTasks", m.config.NodeURL)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return
+// endpoint returns the URL advertised to the node for this miner's API
+func (m *Miner) endpoint() string {
+	if m.config.Endpoint != "" {
+		return m.config.Endpoint
 	}
+	return fmt.Sprintf("http://localhost:%d", m.config.APIPort)
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
+// pollForTasks claims the tasks the node has for this miner and queues
+// them
+func (m *Miner) pollForTasks(ctx context.Context) {
+	m.mu.RLock()
+	running := m.running
+	token := m.nodeToken
+	m.mu.RUnlock()
+
+	if !running {
 		return
 	}
-	defer resp.Body.Close()
 
-	// Parse and submit tasks
 	var tasks []*Task
-	if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+	path := "/api/tasks/pending?miner=" + url.QueryEscape(m.ID())
+	if err := m.nodeRequest(ctx, "GET", path, token, nil, &tasks); err != nil {
+		m.logf(LogError, "", "polling for tasks: %v", err)
 		return
 	}
 
 	for _, task := range tasks {
-		m.SubmitTask(task)
+		if err := m.SubmitTask(task); err != nil {
+			m.logf(LogError, task.ID, "queueing task: %v", err)
+		}
 	}
 }
 
@@ -391,41 +571,68 @@ func (m *Miner) taskWorker(ctx context.Context) {
 	}
 }
 
-// processTask executes an AI task
+// processTask executes an AI task. A task cancelled by the node, before or
+// while it runs, is dropped without submitting a result.
 func (m *Miner) processTask(ctx context.Context, task *Task) {
 	m.mu.Lock()
+	if task.Status == "cancelled" {
+		m.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	m.taskCancels[task.ID] = cancel
 	now := time.Now()
 	task.StartedAt = &now
 	task.Status = "processing"
 	m.mu.Unlock()
-
-	// Process based on task type
-	var err error
-	switch task.Type {
-	case TaskInference:
-		err = m.runInference(ctx, task)
-	case TaskChat:
-		err = m.runChat(ctx, task)
-	case TaskEmbedding:
-		err = m.runEmbedding(ctx, task)
-	default:
-		err = ErrInvalidTask
+	m.logf(LogInfo, task.ID, "started %s task on model %q", task.Type, task.Model)
+
+	// Process based on task type, keeping a local model loaded until done
+	release, err := m.acquireModel(task.Model)
+	if err == nil {
+		switch task.Type {
+		case TaskInference:
+			err = m.runInference(ctx, task)
+		case TaskChat:
+			err = m.runChat(ctx, task)
+		case TaskEmbedding:
+			err = m.runEmbedding(ctx, task)
+		default:
+			err = ErrInvalidTask
+		}
+		release()
 	}
 
 	m.mu.Lock()
+	delete(m.taskCancels, task.ID)
 	endTime := time.Now()
 	task.EndedAt = &endTime
 
-	if err != nil {
+	cancelled := task.Status == "cancelled"
+	switch {
+	case cancelled:
+	case err != nil:
 		task.Status = "failed"
+		task.Error = err.Error()
 		m.stats.TasksFailed++
-	} else {
+	default:
 		task.Status = "completed"
 		m.stats.TasksCompleted++
 		m.stats.TotalRewards += task.Reward
 	}
 	m.mu.Unlock()
 
+	if cancelled {
+		m.logf(LogInfo, task.ID, "cancelled by the node after %s", endTime.Sub(now))
+		return
+	}
+	if err != nil {
+		m.logf(LogError, task.ID, "failed after %s: %v", endTime.Sub(now), err)
+	} else {
+		m.logf(LogInfo, task.ID, "completed in %s", endTime.Sub(now))
+	}
+
 	m.resultCh <- task
 }
 
@@ -474,7 +681,10 @@ func (m *Miner) runChat(ctx context.Context, task *Task) error {
 			Role    string `json:"role"`
 			Content string `json:"content"`
 		} `json:"messages"`
-		MaxTokens int `json:"max_tokens"`
+		MaxTokens      int                     `json:"max_tokens"`
+		ResponseFormat *backend.ResponseFormat `json:"response_format"`
+		Stop           []string                `json:"stop"`
+		backend.Sampling
 	}
 	if err := json.Unmarshal(task.Input, &input); err != nil {
 		return err
@@ -486,18 +696,26 @@ func (m *Miner) runChat(ctx context.Context, task *Task) error {
 	}
 
 	resp, err := m.Backend().Chat(ctx, backend.ChatRequest{
-		Model:     task.Model,
-		Messages:  msgs,
-		MaxTokens: input.MaxTokens,
+		Model:          task.Model,
+		Messages:       msgs,
+		MaxTokens:      input.MaxTokens,
+		ResponseFormat: input.ResponseFormat,
+		Stop:           input.Stop,
+		Sampling:       input.Sampling,
 	})
 	if err != nil {
 		return err
 	}
 
+	// Enforce stop sequences for engines that don't
+	content, stopped := backend.TruncateAtStop(resp.Content, input.Stop)
+	finishReason := backend.ResolveFinishReason(resp.FinishReason, stopped)
+
 	output := map[string]interface{}{
-		"role":    resp.Role,
-		"content": resp.Content,
-		"model":   resp.Model,
+		"role":          resp.Role,
+		"content":       content,
+		"model":         resp.Model,
+		"finish_reason": finishReason,
 	}
 
 	outputBytes, err := json.Marshal(output)
@@ -554,29 +772,47 @@ func (m *Miner) resultHandler(ctx context.Context) {
 	}
 }
 
-// submitResult sends completed task back to the network
+// submitResult sends a finished task back to the node with the token from
+// registration. A completed result is signed with Config.SigningKey, which
+// the node requires once the miner has registered its public key.
 func (m *Miner) submitResult(ctx context.Context, task *Task) {
-	// In production, this would submit the result to the AIVM
-	url := fmt.Sprintf("%s/ext/bc/A/ai/submitResult", m.config.NodeURL)
-
+	m.mu.Lock()
+	if key := m.config.SigningKey; key != nil && task.Status == "completed" {
+		now := time.Now()
+		digest := minersig.ResultDigest(task.ID, task.Output, now)
+		task.Signature = ed25519.Sign(key, digest[:])
+		task.SignedAt = &now
+	}
 	body, err := json.Marshal(task)
+	token := m.nodeToken
+	m.mu.Unlock()
 	if err != nil {
 		return
 	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
-	if err != nil {
-		return
+	err = m.nodeRequest(ctx, "POST", "/api/tasks/submit", token, body, nil)
+	var statusErr *nodeStatusError
+	switch {
+	case errors.As(err, &statusErr) && statusErr.status == http.StatusConflict:
+		m.cancelTask(task.ID)
+		m.logf(LogInfo, task.ID, "node refused the result: %v", err)
+	case err != nil:
+		m.logf(LogError, task.ID, "submitting result: %v", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
+// cancelTask marks a task the node has cancelled, stopping it if it is
+// running so its result is not submitted. Finished tasks are only marked.
+func (m *Miner) cancelTask(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, ok := m.tasks[id]
+	if !ok {
 		return
 	}
-	defer resp.Body.Close()
-
-	_ = body // Would be sent in request body
+	task.Status = "cancelled"
+	if cancel := m.taskCancels[id]; cancel != nil {
+		cancel()
+	}
 }
 
 // startAPI starts the local API server
@@ -587,6 +823,7 @@ func (m *Miner) startAPI() {
 	mux.HandleFunc("/task", m.handleTask)
 	mux.HandleFunc("/chat", m.handleChat)
 	mux.HandleFunc("/health", m.handleHealth)
+	mux.HandleFunc("/logs", m.handleLogs)
 
 	m.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", m.config.APIPort),
@@ -697,6 +934,7 @@ func (m *Miner) handleChat(w http.ResponseWriter, r *http.Request) {
 func (m *Miner) handleHealth(w http.ResponseWriter, r *http.Request) {
 	m.mu.RLock()
 	running := m.running
+	attestation := m.attestationStatusLocked(time.Now())
 	m.mu.RUnlock()
 
 	status := "healthy"
@@ -704,18 +942,23 @@ func (m *Miner) handleHealth(w http.ResponseWriter, r *http.Request) {
 		status = "stopped"
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	resp := map[string]interface{}{
 		"status":  status,
 		"running": running,
-	})
+	}
+	if attestation != nil {
+		resp["attestation"] = attestation
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
 }
 
 // MinerStatus represents the current status of the miner
 type MinerStatus struct {
-	Wallet  string `json:"wallet"`
-	Running bool   `json:"running"`
-	Stats   Stats  `json:"stats"`
+	Wallet      string             `json:"wallet"`
+	Running     bool               `json:"running"`
+	Stats       Stats              `json:"stats"`
+	Attestation *AttestationStatus `json:"attestation,omitempty"`
 }
 
 // Status returns the current miner status
@@ -724,9 +967,10 @@ func (m *Miner) Status() MinerStatus {
 	defer m.mu.RUnlock()
 
 	return MinerStatus{
-		Wallet:  m.config.WalletAddress,
-		Running: m.running,
-		Stats:   m.stats,
+		Wallet:      m.config.WalletAddress,
+		Running:     m.running,
+		Stats:       m.stats,
+		Attestation: m.attestationStatusLocked(time.Now()),
 	}
 }
 
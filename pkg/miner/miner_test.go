@@ -1,14 +1,30 @@
 package miner
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/luxfi/ai/internal/minersig"
+	"github.com/luxfi/ai/pkg/attestation"
+	"github.com/luxfi/ai/pkg/cc"
 	"github.com/luxfi/ai/pkg/miner/backend"
+	"github.com/luxfi/ai/pkg/miner/backend/mock"
 	"github.com/luxfi/ai/pkg/miner/backend/noop"
 )
 
@@ -60,8 +76,8 @@ func TestMinerStatus(t *testing.T) {
 func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
 
-	if cfg.NodeURL != "http://localhost:9650" {
-		t.Errorf("expected default node URL http://localhost:9650, got %s", cfg.NodeURL)
+	if cfg.NodeURL != "http://localhost:9090" {
+		t.Errorf("expected default node URL http://localhost:9090, got %s", cfg.NodeURL)
 	}
 	if !cfg.GPUEnabled {
 		t.Error("expected GPU to be enabled by default")
@@ -261,11 +277,60 @@ func TestDefaultBackendIsNoop(t *testing.T) {
 
 // TestBackendSelectionViaConfig confirms Config.Backend wires through.
 func TestBackendSelectionViaConfig(t *testing.T) {
-	cfg := DefaultConfig()
-	cfg.Backend = "openai"
-	m := New(cfg)
-	if name := m.Backend().Name(); name != "openai" {
-		t.Errorf("configured backend: got %q want %q", name, "openai")
+	tests := []struct {
+		backend string
+		want    string
+	}{
+		{"openai", "openai"},
+		{"ollama", "ollama"},
+		{"llamacpp", "llamacpp"},
+		{"llama.cpp", "llamacpp"},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.Backend = tt.backend
+		m := New(cfg)
+		if name := m.Backend().Name(); name != tt.want {
+			t.Errorf("configured backend %q: got %q want %q", tt.backend, name, tt.want)
+		}
+	}
+}
+
+// TestRegisterAdvertisesBackendModels checks that models the engine reports
+// are advertised alongside the files in ModelDir.
+func TestRegisterAdvertisesBackendModels(t *testing.T) {
+	var got []string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Models []string `json:"models"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		got = req.Models
+		json.NewEncoder(w).Encode(map[string]string{"token": "tok"})
+	}))
+	defer node.Close()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "local.gguf"), []byte("x"), 0o600)
+
+	mb := &mock.Backend{ModelIDs: []string{"llama3.1", "local"}}
+	m := New(Config{NodeURL: node.URL, ModelDir: dir, MaxTasks: 1}).WithBackend(mb)
+	if err := m.RefreshModels(context.Background()); err != nil {
+		t.Fatalf("RefreshModels() error = %v", err)
+	}
+	if err := m.Register(context.Background(), "http://miner:8888"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if want := []string{"llama3.1", "local"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("advertised models = %v, want %v", got, want)
+	}
+
+	mb.Err = errors.New("engine down")
+	if err := m.Register(context.Background(), "http://miner:8888"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if want := []string{"local"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("advertised models with failing backend = %v, want %v", got, want)
 	}
 }
 
@@ -308,6 +373,69 @@ func TestRunChatUsesBackend(t *testing.T) {
 	}
 }
 
+// TestRunChatStop checks that stop sequences reach the backend, are enforced
+// on backends that ignore them and set finish_reason accordingly.
+func TestRunChatStop(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		finishReason string
+		stop         []string
+		wantContent  string
+		wantReason   string
+	}{
+		{"no stop", "one two", "", nil, "one two", "stop"},
+		{"length passed through", "one two", "length", nil, "one two", "length"},
+		{"engine length spelling", "one two", "max_tokens", nil, "one two", "length"},
+		{"engine stop spelling", "one two", "eos", nil, "one two", "stop"},
+		{"truncated", "one two", "length", []string{" t"}, "one", "stop"},
+	}
+	for _, tt := range tests {
+		rb := &recordingBackend{chatContent: tt.content, finishReason: tt.finishReason}
+		m := New(DefaultConfig()).WithBackend(rb)
+
+		input, _ := json.Marshal(map[string]any{
+			"messages": []map[string]string{{"role": "user", "content": "hi"}},
+			"stop":     tt.stop,
+		})
+		task := &Task{Type: TaskChat, Model: "m", Input: input}
+		if err := m.runChat(context.Background(), task); err != nil {
+			t.Fatalf("%s: runChat: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(rb.lastStop, tt.stop) {
+			t.Errorf("%s: backend stop = %q, want %q", tt.name, rb.lastStop, tt.stop)
+		}
+		var out struct {
+			Content      string `json:"content"`
+			FinishReason string `json:"finish_reason"`
+		}
+		if err := json.Unmarshal(task.Output, &out); err != nil {
+			t.Fatalf("%s: decode output: %v", tt.name, err)
+		}
+		if out.Content != tt.wantContent || out.FinishReason != tt.wantReason {
+			t.Errorf("%s: output = (%q, %q), want (%q, %q)", tt.name, out.Content, out.FinishReason, tt.wantContent, tt.wantReason)
+		}
+	}
+}
+
+func TestRunChatSampling(t *testing.T) {
+	rb := &recordingBackend{chatContent: "ok"}
+	m := New(DefaultConfig()).WithBackend(rb)
+
+	input := []byte(`{"messages":[{"role":"user","content":"hi"}],"temperature":0.7,"top_p":0.5,"frequency_penalty":1,"seed":42}`)
+	if err := m.runChat(context.Background(), &Task{Type: TaskChat, Model: "m", Input: input}); err != nil {
+		t.Fatalf("runChat: %v", err)
+	}
+	s := rb.lastSampling
+	if s.Temperature == nil || *s.Temperature != 0.7 || s.TopP == nil || *s.TopP != 0.5 ||
+		s.FrequencyPenalty == nil || *s.FrequencyPenalty != 1 || s.Seed == nil || *s.Seed != 42 {
+		t.Errorf("backend sampling = %+v, want the task's parameters", s)
+	}
+	if s.PresencePenalty != nil {
+		t.Errorf("PresencePenalty = %v, want unset", *s.PresencePenalty)
+	}
+}
+
 // TestRunEmbeddingUsesBackend mirrors TestRunChatUsesBackend for embeddings.
 func TestRunEmbeddingUsesBackend(t *testing.T) {
 	m := New(DefaultConfig()).WithBackend(&recordingBackend{
@@ -354,8 +482,11 @@ func TestGPUStatsProviderPopulatesStats(t *testing.T) {
 
 // recordingBackend is a test double implementing backend.InferenceBackend.
 type recordingBackend struct {
-	chatContent string
-	embedding   []float64
+	chatContent  string
+	finishReason string
+	embedding    []float64
+	lastStop     []string
+	lastSampling backend.Sampling
 }
 
 func (*recordingBackend) Name() string { return "recording" }
@@ -363,7 +494,9 @@ func (*recordingBackend) Capabilities() backend.Capabilities {
 	return backend.Capabilities{Chat: true, Inference: true, Embedding: true}
 }
 func (r *recordingBackend) Chat(_ context.Context, req backend.ChatRequest) (backend.ChatResponse, error) {
-	return backend.ChatResponse{Role: "assistant", Content: r.chatContent, Model: req.Model}, nil
+	r.lastStop = req.Stop
+	r.lastSampling = req.Sampling
+	return backend.ChatResponse{Role: "assistant", Content: r.chatContent, Model: req.Model, FinishReason: r.finishReason}, nil
 }
 func (r *recordingBackend) Inference(_ context.Context, req backend.InferenceRequest) (backend.InferenceResponse, error) {
 	return backend.InferenceResponse{Text: r.chatContent, Model: req.Model}, nil
@@ -371,3 +504,810 @@ func (r *recordingBackend) Inference(_ context.Context, req backend.InferenceReq
 func (r *recordingBackend) Embed(_ context.Context, req backend.EmbedRequest) (backend.EmbedResponse, error) {
 	return backend.EmbedResponse{Embedding: r.embedding, Model: req.Model}, nil
 }
+
+func TestRegisterAndDeregisterOnStop(t *testing.T) {
+	var gotAuth, gotID string
+	deregistered := false
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/miners/register":
+			json.NewEncoder(w).Encode(map[string]string{"status": "registered", "token": "tok-123"})
+		case "/api/miners/deregister":
+			var req struct {
+				ID string `json:"id"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			gotID = req.ID
+			gotAuth = r.Header.Get("Authorization")
+			deregistered = true
+			json.NewEncoder(w).Encode(map[string]string{"status": "deregistered"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer node.Close()
+
+	m := New(Config{NodeURL: node.URL, WalletAddress: "0xminer", MaxTasks: 1})
+	if err := m.Deregister(context.Background()); err != ErrNotRegistered {
+		t.Errorf("Deregister() before Register = %v, want %v", err, ErrNotRegistered)
+	}
+	if err := m.Register(context.Background(), "http://miner:8888"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	m.running = true // Stop without binding the API port
+	if err := m.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if !deregistered {
+		t.Fatal("Stop() should deregister from the node")
+	}
+	if gotAuth != "Bearer tok-123" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer tok-123")
+	}
+	if gotID != "0xminer" {
+		t.Errorf("deregistered ID = %q, want %q", gotID, "0xminer")
+	}
+	if err := m.Deregister(context.Background()); err != ErrNotRegistered {
+		t.Errorf("second Deregister() = %v, want %v", err, ErrNotRegistered)
+	}
+}
+
+func TestEnsureRegistered(t *testing.T) {
+	var registrations int32
+	var endpoint string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/miners/register" {
+			http.NotFound(w, r)
+			return
+		}
+		// The node turns the first registration away
+		if atomic.AddInt32(&registrations, 1) == 1 {
+			http.Error(w, "not ready", http.StatusBadRequest)
+			return
+		}
+		var req struct {
+			Endpoint string `json:"endpoint"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		endpoint = req.Endpoint
+		json.NewEncoder(w).Encode(map[string]string{"status": "registered", "token": "tok-123"})
+	}))
+	defer node.Close()
+
+	m := New(Config{NodeURL: node.URL, WalletAddress: "0xminer", MaxTasks: 1, APIPort: 8899})
+	if m.ensureRegistered(context.Background()) {
+		t.Fatal("ensureRegistered() = true after the node refused")
+	}
+	if !m.ensureRegistered(context.Background()) {
+		t.Fatal("ensureRegistered() = false after the node accepted")
+	}
+	if !m.ensureRegistered(context.Background()) || atomic.LoadInt32(&registrations) != 2 {
+		t.Errorf("registrations = %d, want 2: a registered miner does not register again", registrations)
+	}
+	if endpoint != "http://localhost:8899" {
+		t.Errorf("endpoint = %q, want the API port on localhost", endpoint)
+	}
+}
+
+func TestNodeCallDeadline(t *testing.T) {
+	release := make(chan struct{})
+	var calls int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+	}))
+	defer node.Close()
+	defer close(release)
+
+	m := New(Config{NodeURL: node.URL, WalletAddress: "0xminer", MaxTasks: 1, NodeTimeout: 50 * time.Millisecond, NodeRetries: 1})
+
+	// A timed-out GET is retried; a timed-out POST may have been applied
+	for _, tt := range []struct {
+		method    string
+		wantCalls int32
+	}{
+		{"GET", 2},
+		{"POST", 1},
+	} {
+		atomic.StoreInt32(&calls, 0)
+		start := time.Now()
+		err := m.nodeRequest(context.Background(), tt.method, "/api/tasks", "", nil, nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("%s error = %v, want deadline exceeded", tt.method, err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("%s took %v against a hung node", tt.method, elapsed)
+		}
+		if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+			t.Errorf("%s: node saw %d calls, want %d", tt.method, got, tt.wantCalls)
+		}
+	}
+}
+
+func TestNodeCallRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		failures  int
+		status    int
+		wantErr   bool
+		wantCalls int32
+	}{
+		{"succeeds first time", "POST", 0, 0, false, 1},
+		{"retries 503", "POST", 2, http.StatusServiceUnavailable, false, 3},
+		{"retries 429", "POST", 1, http.StatusTooManyRequests, false, 2},
+		{"does not retry POST 502", "POST", 1, http.StatusBadGateway, true, 1},
+		{"retries GET 502", "GET", 1, http.StatusBadGateway, false, 2},
+		{"gives up after budget", "GET", 3, http.StatusBadGateway, true, 3},
+		{"does not retry 400", "GET", 1, http.StatusBadRequest, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(atomic.AddInt32(&calls, 1)) <= tt.failures {
+					w.WriteHeader(tt.status)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]string{"token": "tok"})
+			}))
+			defer node.Close()
+
+			m := New(Config{NodeURL: node.URL, WalletAddress: "0xminer", MaxTasks: 1})
+			err := m.nodeRequest(context.Background(), tt.method, "/api/miners/register", "", []byte(`{}`), nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%s error = %v, wantErr %v", tt.method, err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("node saw %d calls, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSubmitResultSendsBody(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	type submission struct {
+		task  Task
+		token string
+	}
+	got := make(chan submission, 1)
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tasks/submit" {
+			http.NotFound(w, r)
+			return
+		}
+		var task Task
+		json.NewDecoder(r.Body).Decode(&task)
+		got <- submission{task, r.Header.Get("Authorization")}
+	}))
+	defer node.Close()
+
+	tests := []struct {
+		name   string
+		key    ed25519.PrivateKey
+		status string
+		signed bool
+	}{
+		{"unkeyed", nil, "completed", false},
+		{"signed", priv, "completed", true},
+		{"failure unsigned", priv, "failed", false},
+	}
+	for _, tt := range tests {
+		m := New(Config{NodeURL: node.URL, MaxTasks: 1, SigningKey: tt.key})
+		m.nodeToken = "tok"
+		m.submitResult(context.Background(), &Task{ID: "task-1", Status: tt.status, Output: json.RawMessage(`{"content": "hi"}`)})
+
+		select {
+		case sub := <-got:
+			if sub.task.ID != "task-1" || sub.token != "Bearer tok" {
+				t.Errorf("%s: submitted %q with %q, want task-1 with the node token", tt.name, sub.task.ID, sub.token)
+			}
+			if signed := len(sub.task.Signature) > 0 && sub.task.SignedAt != nil; signed != tt.signed {
+				t.Fatalf("%s: signed = %v, want %v", tt.name, signed, tt.signed)
+			}
+			if !tt.signed {
+				continue
+			}
+			digest := minersig.ResultDigest("task-1", sub.task.Output, *sub.task.SignedAt)
+			if !ed25519.Verify(pub, digest[:], sub.task.Signature) {
+				t.Errorf("%s: signature does not verify over the result digest", tt.name)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: submitResult did not reach the node", tt.name)
+		}
+	}
+}
+
+func TestPollForTasksClaimsAssigned(t *testing.T) {
+	var query, auth string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tasks/pending" {
+			http.NotFound(w, r)
+			return
+		}
+		query, auth = r.URL.RawQuery, r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode([]*Task{{ID: "task-1", Type: TaskChat}})
+	}))
+	defer node.Close()
+
+	m := New(Config{NodeURL: node.URL, WalletAddress: "0xminer", MaxTasks: 1})
+	m.running = true
+	m.nodeToken = "tok"
+	m.pollForTasks(context.Background())
+
+	if query != "miner=0xminer" || auth != "Bearer tok" {
+		t.Errorf("polled with query %q and %q, want miner=0xminer with the node token", query, auth)
+	}
+	select {
+	case task := <-m.taskCh:
+		if task.ID != "task-1" {
+			t.Errorf("queued task %q, want task-1", task.ID)
+		}
+	default:
+		t.Error("claimed task was not queued")
+	}
+}
+
+// blockingBackend blocks chats until their context is cancelled.
+type blockingBackend struct {
+	recordingBackend
+	started chan struct{}
+}
+
+func (b *blockingBackend) Chat(ctx context.Context, _ backend.ChatRequest) (backend.ChatResponse, error) {
+	close(b.started)
+	<-ctx.Done()
+	return backend.ChatResponse{}, ctx.Err()
+}
+
+func TestNodeCancelStopsTask(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	var submits int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/miners/heartbeat":
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "cancelled": []string{"task-1"}})
+		case "/api/tasks/submit":
+			atomic.AddInt32(&submits, 1)
+			http.Error(w, "task cancelled", http.StatusConflict)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer node.Close()
+
+	b := &blockingBackend{started: make(chan struct{})}
+	m := New(Config{NodeURL: node.URL, WalletAddress: "0xminer", MaxTasks: 1, SigningKey: priv}).WithBackend(b)
+	m.running = true
+	m.nodeToken = "tok"
+	if err := m.SubmitTask(&Task{ID: "task-1", Type: TaskChat, Input: json.RawMessage(`{"messages":[]}`)}); err != nil {
+		t.Fatalf("SubmitTask() error = %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.processTask(context.Background(), <-m.taskCh)
+		close(done)
+	}()
+	<-b.started
+	if err := m.Heartbeat(context.Background()); err != nil {
+		t.Fatalf("Heartbeat() error = %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("task kept running after the node cancelled it")
+	}
+
+	task, _ := m.GetTask("task-1")
+	if task.Status != "cancelled" {
+		t.Errorf("task status = %q, want cancelled", task.Status)
+	}
+	if stats := m.GetStats(); stats.TasksFailed != 0 || stats.TasksCompleted != 0 {
+		t.Errorf("stats = %+v, want the cancelled task uncounted", stats)
+	}
+	select {
+	case <-m.resultCh:
+		t.Error("cancelled task was queued for submission")
+	default:
+	}
+
+	// A result the node refuses with 409 marks the task cancelled
+	m.tasks["task-2"] = &Task{ID: "task-2", Status: "completed"}
+	m.submitResult(context.Background(), m.tasks["task-2"])
+	if m.tasks["task-2"].Status != "cancelled" || atomic.LoadInt32(&submits) != 1 {
+		t.Errorf("after 409: status = %q, %d submits; want cancelled after 1", m.tasks["task-2"].Status, submits)
+	}
+}
+
+// loaderBackend records ModelLoader calls.
+type loaderBackend struct {
+	recordingBackend
+	mu       sync.Mutex
+	loaded   []string
+	unloaded []string
+}
+
+func (l *loaderBackend) LoadModel(_ context.Context, id, _ string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.loaded = append(l.loaded, id)
+	return nil
+}
+
+func (l *loaderBackend) UnloadModel(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.unloaded = append(l.unloaded, id)
+	return nil
+}
+
+func TestRefreshModels(t *testing.T) {
+	var mu sync.Mutex
+	var advertised [][]string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/miners/register":
+			json.NewEncoder(w).Encode(map[string]string{"token": "tok"})
+		case "/api/miners/models":
+			var req struct {
+				Models []string `json:"models"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			advertised = append(advertised, req.Models)
+			mu.Unlock()
+		}
+	}))
+	defer node.Close()
+
+	dir := t.TempDir()
+	lb := &loaderBackend{}
+	m := New(Config{NodeURL: node.URL, WalletAddress: "0xminer", MaxTasks: 1, ModelDir: dir}).WithBackend(lb)
+	if err := m.Register(context.Background(), "http://miner:8888"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	for _, name := range []string{"zen-mini.gguf", "qwen3-8b.safetensors", "README.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.RefreshModels(context.Background()); err != nil {
+		t.Fatalf("RefreshModels() error = %v", err)
+	}
+	if got := strings.Join(m.Models(), ","); got != "qwen3-8b,zen-mini" {
+		t.Errorf("Models() = %q, want %q", got, "qwen3-8b,zen-mini")
+	}
+	if len(lb.loaded) != 2 {
+		t.Errorf("loaded %v, want both model files", lb.loaded)
+	}
+
+	// A model serving a task is unadvertised on removal but not unloaded
+	// until the task finishes
+	release, err := m.acquireModel("zen-mini")
+	if err != nil {
+		t.Fatalf("acquireModel() error = %v", err)
+	}
+	os.Remove(filepath.Join(dir, "zen-mini.gguf"))
+	if err := m.RefreshModels(context.Background()); err != nil {
+		t.Fatalf("RefreshModels() error = %v", err)
+	}
+	if got := strings.Join(m.Models(), ","); got != "qwen3-8b" {
+		t.Errorf("Models() after removal = %q, want %q", got, "qwen3-8b")
+	}
+	if len(lb.unloaded) != 0 {
+		t.Errorf("unloaded %v while a task was in flight", lb.unloaded)
+	}
+	if _, err := m.acquireModel("zen-mini"); err != ErrModelUnavailable {
+		t.Errorf("acquireModel() on draining model = %v, want %v", err, ErrModelUnavailable)
+	}
+	release()
+	if len(lb.unloaded) != 1 || lb.unloaded[0] != "zen-mini" {
+		t.Errorf("unloaded %v after task finished, want [zen-mini]", lb.unloaded)
+	}
+
+	// Unchanged directory: nothing new to advertise
+	if err := m.RefreshModels(context.Background()); err != nil {
+		t.Fatalf("RefreshModels() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(advertised) != 2 {
+		t.Fatalf("advertised %d times, want 2", len(advertised))
+	}
+	if got := strings.Join(advertised[1], ","); got != "qwen3-8b" {
+		t.Errorf("last advertised = %q, want %q", got, "qwen3-8b")
+	}
+}
+
+func TestBenchmarkCapacity(t *testing.T) {
+	var gotCapacity float64
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			CapacityTPS float64 `json:"capacity_tps"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		gotCapacity = req.CapacityTPS
+		json.NewEncoder(w).Encode(map[string]string{"token": "tok"})
+	}))
+	defer node.Close()
+
+	m := New(Config{NodeURL: node.URL, MaxTasks: 1}).WithBackend(&recordingBackend{chatContent: "benchmark output"})
+	if got := m.Capacity(); got != 0 {
+		t.Errorf("Capacity() before Benchmark = %v, want 0", got)
+	}
+	if err := m.Register(context.Background(), "http://miner:8888"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if gotCapacity != 0 {
+		t.Errorf("capacity_tps sent before Benchmark = %v, want omitted", gotCapacity)
+	}
+
+	capacity, err := m.Benchmark(context.Background())
+	if err != nil {
+		t.Fatalf("Benchmark() error = %v", err)
+	}
+	if capacity <= 0 || m.Capacity() != capacity {
+		t.Fatalf("Benchmark() = %v, Capacity() = %v", capacity, m.Capacity())
+	}
+	if err := m.Register(context.Background(), "http://miner:8888"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if gotCapacity != capacity {
+		t.Errorf("capacity_tps = %v, want %v", gotCapacity, capacity)
+	}
+
+	empty := New(DefaultConfig()).WithBackend(&recordingBackend{})
+	if _, err := empty.Benchmark(context.Background()); err != ErrBenchmarkFailed {
+		t.Errorf("Benchmark() with no output = %v, want %v", err, ErrBenchmarkFailed)
+	}
+}
+
+func TestRegisterReportsGPUMemory(t *testing.T) {
+	var got map[string]interface{}
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(map[string]string{"token": "tok"})
+	}))
+	defer node.Close()
+
+	m := New(Config{NodeURL: node.URL, MaxTasks: 1, GPUMemoryMB: 81559}).WithBackend(&recordingBackend{})
+	if err := m.Register(context.Background(), "http://miner:8888"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if got["gpu_memory_mb"] != float64(81559) {
+		t.Errorf("gpu_memory_mb = %v, want 81559", got["gpu_memory_mb"])
+	}
+}
+
+func TestAttestReportsToNode(t *testing.T) {
+	var got map[string]interface{}
+	var gotAuth string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/miners/register":
+			json.NewEncoder(w).Encode(map[string]string{"token": "tok-123"})
+		case "/api/miners/attestation":
+			gotAuth = r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&got)
+			json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+		case "/api/miners/attestation/challenge":
+			json.NewEncoder(w).Encode(attestation.BenchmarkChallenge{DeviceID: "0xminer", Iterations: 4})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer node.Close()
+
+	m := New(Config{NodeURL: node.URL, WalletAddress: "0xminer", MaxTasks: 1})
+	if _, err := m.AttestationChallenge(context.Background()); err != ErrNotRegistered {
+		t.Errorf("AttestationChallenge() before Register = %v, want %v", err, ErrNotRegistered)
+	}
+	if _, err := m.Attest(context.Background()); err != ErrNoAttester {
+		t.Errorf("Attest() without attester = %v, want %v", err, ErrNoAttester)
+	}
+	if m.Status().Attestation != nil {
+		t.Error("Status().Attestation should be nil before attesting")
+	}
+
+	issued := time.Now()
+	evidence := &attestation.GPUAttestation{DeviceID: "0xminer", Model: "A100", Mode: attestation.ModeLocal}
+	m.SetAttester(func(context.Context) (*Attestation, error) {
+		return &Attestation{Tier: cc.Tier2ConfidentialVM, TrustScore: 85, IssuedAt: issued, Evidence: evidence}, nil
+	})
+	att, err := m.Attest(context.Background())
+	if err != nil {
+		t.Fatalf("Attest() error = %v", err)
+	}
+	if want := issued.Add(24 * time.Hour); !att.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt = %v, want tier validity %v", att.ExpiresAt, want)
+	}
+	if got != nil {
+		t.Error("unregistered miner should not report its attestation")
+	}
+
+	// Registering reports the attestation made before it
+	if err := m.Register(context.Background(), "http://miner:8888"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if gotAuth != "Bearer tok-123" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer tok-123")
+	}
+	if ch, err := m.AttestationChallenge(context.Background()); err != nil || ch.DeviceID != "0xminer" || ch.Iterations != 4 {
+		t.Errorf("AttestationChallenge() = %+v, %v", ch, err)
+	}
+	reported, _ := got["evidence"].(map[string]interface{})
+	if got["id"] != "0xminer" || reported["device_id"] != "0xminer" || reported["model"] != "A100" {
+		t.Errorf("reported attestation = %v, want the evidence", got)
+	}
+	if _, ok := got["tier"]; ok {
+		t.Errorf("reported attestation = %v, want no self-assessed tier", got)
+	}
+
+	status := m.Status().Attestation
+	if status == nil {
+		t.Fatal("Status().Attestation = nil after attesting")
+	}
+	if status.ExpiresIn <= 23*time.Hour || status.ExpiresIn > 24*time.Hour {
+		t.Errorf("ExpiresIn = %v, want just under 24h", status.ExpiresIn)
+	}
+}
+
+func TestAttestationRefresh(t *testing.T) {
+	m := New(Config{WalletAddress: "0xminer", MaxTasks: 1})
+
+	var calls atomic.Int32
+	m.SetAttester(func(context.Context) (*Attestation, error) {
+		calls.Add(1)
+		now := time.Now()
+		return &Attestation{Tier: cc.Tier1GPUNativeCC, IssuedAt: now, ExpiresAt: now.Add(40 * time.Millisecond)}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.refreshAttestation(ctx)
+
+	// Each attestation is renewed at 75% of its 40ms validity
+	deadline := time.Now().Add(2 * time.Second)
+	for calls.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := calls.Load(); n < 3 {
+		t.Fatalf("attested %d times, want at least 3", n)
+	}
+	if left := m.AttestationExpiresIn(); left <= 0 {
+		t.Errorf("AttestationExpiresIn() = %v, want the attestation refreshed before expiry", left)
+	}
+}
+
+func TestAttestFailureKeepsPrevious(t *testing.T) {
+	m := New(Config{WalletAddress: "0xminer", MaxTasks: 1})
+	fail := false
+	m.SetAttester(func(context.Context) (*Attestation, error) {
+		if fail {
+			return nil, errors.New("challenge rejected")
+		}
+		return &Attestation{Tier: cc.Tier3DeviceTEE, TrustScore: 60}, nil
+	})
+	if _, err := m.Attest(context.Background()); err != nil {
+		t.Fatalf("Attest() error = %v", err)
+	}
+
+	fail = true
+	if _, err := m.Attest(context.Background()); err == nil {
+		t.Fatal("Attest() should return the attester's error")
+	}
+	status := m.Status().Attestation
+	if status.Tier != cc.Tier3DeviceTEE || status.Failures != 1 || status.LastError != "challenge rejected" {
+		t.Errorf("Status().Attestation = %+v, want previous tier kept with 1 failure", status)
+	}
+	if status.ExpiresIn <= 0 {
+		t.Errorf("ExpiresIn = %v, want previous attestation still valid", status.ExpiresIn)
+	}
+}
+
+func TestHeartbeatSigned(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	var registeredKey []byte
+	var registration struct {
+		ID        string    `json:"id"`
+		PublicKey []byte    `json:"public_key"`
+		Timestamp time.Time `json:"timestamp"`
+		Signature []byte    `json:"signature"`
+	}
+	var registerAuth string
+	var hb struct {
+		ID        string    `json:"id"`
+		Timestamp time.Time `json:"timestamp"`
+		Nonce     []byte    `json:"nonce"`
+		Signature []byte    `json:"signature"`
+	}
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/miners/register":
+			json.NewDecoder(r.Body).Decode(&registration)
+			registeredKey = registration.PublicKey
+			registerAuth = r.Header.Get("Authorization")
+			json.NewEncoder(w).Encode(map[string]string{"token": "tok-123"})
+		case "/api/miners/heartbeat":
+			json.NewDecoder(r.Body).Decode(&hb)
+			json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer node.Close()
+
+	if err := New(Config{NodeURL: node.URL}).Heartbeat(context.Background()); err != ErrNoSigningKey {
+		t.Errorf("Heartbeat() without key = %v, want %v", err, ErrNoSigningKey)
+	}
+	m := New(Config{NodeURL: node.URL, WalletAddress: "0xminer", MaxTasks: 1, SigningKey: priv})
+	if err := m.Heartbeat(context.Background()); err != ErrNotRegistered {
+		t.Errorf("Heartbeat() before Register = %v, want %v", err, ErrNotRegistered)
+	}
+	if err := m.Register(context.Background(), "http://miner:8888"); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if !bytes.Equal(registeredKey, pub) {
+		t.Fatalf("registered public key = %x, want %x", registeredKey, pub)
+	}
+	regDigest := minersig.RegistrationDigest(registration.ID, registration.Timestamp, registration.PublicKey)
+	if !ed25519.Verify(pub, regDigest[:], registration.Signature) {
+		t.Error("registration signature does not verify against the miner key")
+	}
+	if err := m.Register(context.Background(), "http://miner:8888"); err != nil {
+		t.Fatalf("re-Register() error = %v", err)
+	}
+	if registerAuth != "Bearer tok-123" {
+		t.Errorf("re-registration Authorization = %q, want the node token", registerAuth)
+	}
+
+	if err := m.Heartbeat(context.Background()); err != nil {
+		t.Fatalf("Heartbeat() error = %v", err)
+	}
+	if hb.ID != "0xminer" || len(hb.Nonce) != heartbeatNonceSize {
+		t.Errorf("heartbeat id = %q, nonce = %d bytes", hb.ID, len(hb.Nonce))
+	}
+	digest := minersig.HeartbeatDigest(hb.ID, hb.Timestamp, hb.Nonce)
+	if !ed25519.Verify(registeredKey, digest[:], hb.Signature) {
+		t.Error("heartbeat signature does not verify against the registered key")
+	}
+}
+
+func TestLogRingBounded(t *testing.T) {
+	m := New(Config{LogBufferSize: 3})
+	for i := 0; i < 5; i++ {
+		m.logf(LogInfo, "", "event %d", i)
+	}
+
+	logs := m.Logs(0)
+	if len(logs) != 3 || logs[0].Message != "event 2" || logs[2].Seq != 5 {
+		t.Fatalf("Logs(0) = %+v, want events 2-4", logs)
+	}
+	if tail := m.Logs(1); len(tail) != 1 || tail[0].Message != "event 4" {
+		t.Errorf("Logs(1) = %+v, want event 4", tail)
+	}
+}
+
+func TestLogsHandler(t *testing.T) {
+	m := New(Config{MaxTasks: 1, LogToken: "secret"})
+	srv := httptest.NewServer(http.HandlerFunc(m.handleLogs))
+	defer srv.Close()
+
+	get := func(token, query string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest("GET", srv.URL+query, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", query, err)
+		}
+		return resp
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		resp := get(token, "")
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, resp.StatusCode)
+		}
+	}
+
+	m.logf(LogInfo, "t1", "started")
+	m.logf(LogError, "t1", "failed")
+
+	resp := get("secret", "?tail=1")
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	events := make(chan LogEntry)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var e LogEntry
+			json.Unmarshal([]byte(data), &e)
+			events <- e
+		}
+		close(events)
+	}()
+	next := func() LogEntry {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for log event")
+			return LogEntry{}
+		}
+	}
+
+	if e := next(); e.Seq != 2 || e.Level != LogError || e.Task != "t1" {
+		t.Errorf("first event = %+v, want the buffered failure", e)
+	}
+	m.logf(LogInfo, "t2", "completed")
+	if e := next(); e.Seq != 3 || e.Message != "completed" {
+		t.Errorf("second event = %+v, want the new entry", e)
+	}
+}
+
+func TestLogsHandlerLimits(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(Config{}).handleLogs(rec, httptest.NewRequest("GET", "/logs", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("without LogToken: status = %d, want 403", rec.Code)
+	}
+
+	m := New(Config{LogToken: "secret"})
+	for i := 0; i < maxLogStreams; i++ {
+		if !m.logs.openStream() {
+			t.Fatalf("stream %d refused, want %d allowed", i, maxLogStreams)
+		}
+	}
+	req := httptest.NewRequest("GET", "/logs", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	m.handleLogs(rec, req)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("over the stream limit: status = %d, want 429 with Retry-After", rec.Code)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	refused := fmt.Errorf("/api/tasks/submit: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+	reset := fmt.Errorf("/api/tasks/submit: %w", &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset")})
+	tests := []struct {
+		name string
+		err  error
+		get  bool
+		post bool
+	}{
+		{"refused connection", refused, true, true},
+		{"reset after sending", reset, true, false},
+		{"timeout", context.DeadlineExceeded, true, false},
+		{"429", &nodeStatusError{status: http.StatusTooManyRequests}, true, true},
+		{"503", &nodeStatusError{status: http.StatusServiceUnavailable}, true, true},
+		{"500", &nodeStatusError{status: http.StatusInternalServerError}, true, false},
+		{"409", &nodeStatusError{status: http.StatusConflict}, false, false},
+	}
+	for _, tt := range tests {
+		if got := isTransient("GET", tt.err); got != tt.get {
+			t.Errorf("isTransient(GET, %s) = %v, want %v", tt.name, got, tt.get)
+		}
+		if got := isTransient("POST", tt.err); got != tt.post {
+			t.Errorf("isTransient(POST, %s) = %v, want %v", tt.name, got, tt.post)
+		}
+	}
+}
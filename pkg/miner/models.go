@@ -0,0 +1,251 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/luxfi/ai/pkg/miner/backend"
+)
+
+// ErrModelUnavailable is returned for tasks naming a local model whose file
+// has been removed from ModelDir.
+var ErrModelUnavailable = errors.New("model is being unloaded")
+
+// DefaultModelScanInterval is how often ModelDir is rescanned when
+// Config.WatchModels is set and Config.ModelScanInterval is unset.
+const DefaultModelScanInterval = 30 * time.Second
+
+// ModelFileExtensions lists the file extensions recognised as models in
+// ModelDir. A model's ID is its file name without the extension.
+var ModelFileExtensions = []string{".gguf", ".safetensors", ".onnx", ".bin"}
+
+// localModel is a model file discovered in ModelDir.
+type localModel struct {
+	path     string
+	inFlight int  // Tasks currently using the model
+	draining bool // File removed; unload once inFlight drops to zero
+}
+
+// Models returns the IDs of the local models the miner advertises, sorted.
+// Models whose files were removed are no longer advertised even while
+// in-flight tasks finish with them.
+func (m *Miner) Models() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]string, 0, len(m.models))
+	for id, lm := range m.models {
+		if !lm.draining {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// RefreshModels rescans ModelDir, loads newly added model files, unloads
+// removed ones and, if the miner is registered, advertises the updated
+// model list to the node. A removed model still serving tasks is unloaded
+// when its last task finishes.
+func (m *Miner) RefreshModels(ctx context.Context) error {
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
+
+	found, err := scanModelDir(m.config.ModelDir)
+	if err != nil {
+		return err
+	}
+	loader, _ := m.Backend().(backend.ModelLoader)
+
+	m.mu.Lock()
+	var added []string
+	for id := range found {
+		if lm, ok := m.models[id]; !ok || lm.draining {
+			added = append(added, id)
+		}
+	}
+	var unload []string
+	changed := false
+	for id, lm := range m.models {
+		if _, ok := found[id]; ok || lm.draining {
+			continue
+		}
+		changed = true
+		lm.draining = true
+		if lm.inFlight == 0 {
+			delete(m.models, id)
+			unload = append(unload, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, id := range unload {
+		if loader != nil {
+			loader.UnloadModel(id)
+		}
+	}
+
+	sort.Strings(added)
+	for _, id := range added {
+		m.mu.Lock()
+		lm, ok := m.models[id]
+		if ok {
+			// Re-added while draining: the engine still has it loaded
+			lm.draining = false
+			lm.path = found[id]
+		}
+		m.mu.Unlock()
+
+		if !ok {
+			if loader != nil {
+				if err := loader.LoadModel(ctx, id, found[id]); err != nil {
+					continue
+				}
+			}
+			m.mu.Lock()
+			m.models[id] = &localModel{path: found[id]}
+			m.mu.Unlock()
+		}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return m.advertiseModels(ctx)
+}
+
+// advertisedModels returns the local models plus any the backend's engine
+// reports serving (see backend.ModelLister), sorted and deduplicated. A
+// backend that fails to list its models contributes none.
+func (m *Miner) advertisedModels(ctx context.Context) []string {
+	ids := m.Models()
+	served, err := backend.ListModels(ctx, m.Backend())
+	if err != nil || len(served) == 0 {
+		return ids
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	for _, id := range served {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// advertiseModels sends the current model list to the node. It is a no-op
+// for miners that have not registered.
+func (m *Miner) advertiseModels(ctx context.Context) error {
+	m.mu.RLock()
+	token := m.nodeToken
+	m.mu.RUnlock()
+	if token == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"id":     m.ID(),
+		"models": m.advertisedModels(ctx),
+	})
+	if err != nil {
+		return err
+	}
+	return m.postNode(ctx, "/api/miners/models", token, body, nil)
+}
+
+// acquireModel pins a local model for the duration of a task. The returned
+// release function must be called when the task finishes. Models that are
+// not local (e.g. served by a remote engine) are not tracked.
+func (m *Miner) acquireModel(id string) (func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lm, ok := m.models[id]
+	if !ok {
+		return func() {}, nil
+	}
+	if lm.draining {
+		return nil, ErrModelUnavailable
+	}
+	lm.inFlight++
+
+	return func() {
+		m.mu.Lock()
+		lm.inFlight--
+		unload := lm.draining && lm.inFlight == 0 && m.models[id] == lm
+		if unload {
+			delete(m.models, id)
+		}
+		m.mu.Unlock()
+
+		if unload {
+			if loader, ok := m.Backend().(backend.ModelLoader); ok {
+				loader.UnloadModel(id)
+			}
+		}
+	}, nil
+}
+
+// watchModels rescans ModelDir until the miner stops.
+func (m *Miner) watchModels(ctx context.Context) {
+	interval := m.config.ModelScanInterval
+	if interval <= 0 {
+		interval = DefaultModelScanInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m.RefreshModels(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.RefreshModels(ctx)
+		}
+	}
+}
+
+// scanModelDir maps model IDs to file paths for the model files in dir. A
+// missing directory has no models.
+func scanModelDir(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]string)
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		ext := filepath.Ext(e.Name())
+		for _, known := range ModelFileExtensions {
+			if strings.EqualFold(ext, known) {
+				found[strings.TrimSuffix(e.Name(), ext)] = filepath.Join(dir, e.Name())
+				break
+			}
+		}
+	}
+	return found, nil
+}
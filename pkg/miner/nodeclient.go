@@ -0,0 +1,159 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/luxfi/ai/internal/backoff"
+)
+
+const (
+	// DefaultNodeTimeout bounds each HTTP call to the node when
+	// Config.NodeTimeout is unset.
+	DefaultNodeTimeout = 10 * time.Second
+
+	// DefaultNodeRetries is how many times a transient node failure is
+	// retried when Config.NodeRetries is unset.
+	DefaultNodeRetries = 2
+
+	// nodeRetryBackoff is the delay before the first retry; it doubles on
+	// each subsequent attempt.
+	nodeRetryBackoff = 200 * time.Millisecond
+
+	// nodeRetryJitter spreads retry delays by up to 20% so miners that lost
+	// the node at the same moment don't retry in lockstep.
+	nodeRetryJitter = 0.2
+)
+
+// nodeStatusError is returned when the node answers with a non-200 status.
+type nodeStatusError struct {
+	path   string
+	status int
+	text   string
+}
+
+func (e *nodeStatusError) Error() string {
+	return fmt.Sprintf("%s: node returned %s", e.path, e.text)
+}
+
+// nodeTimeout returns the per-call deadline for node requests.
+func (m *Miner) nodeTimeout() time.Duration {
+	if m.config.NodeTimeout > 0 {
+		return m.config.NodeTimeout
+	}
+	return DefaultNodeTimeout
+}
+
+// nodeRetries returns how many times a transient failure is retried.
+// A negative Config.NodeRetries disables retries.
+func (m *Miner) nodeRetries() int {
+	switch {
+	case m.config.NodeRetries > 0:
+		return m.config.NodeRetries
+	case m.config.NodeRetries < 0:
+		return 0
+	default:
+		return DefaultNodeRetries
+	}
+}
+
+// nodeRequest sends a request to the node and decodes the JSON reply into
+// out when out is non-nil. Each attempt gets its own deadline; failures
+// worth retrying (see isTransient) are retried with jittered exponential
+// backoff until the retry budget or ctx runs out.
+func (m *Miner) nodeRequest(ctx context.Context, method, path, token string, body []byte, out interface{}) error {
+	policy := backoff.Backoff{
+		Base:    nodeRetryBackoff,
+		Jitter:  nodeRetryJitter,
+		Retries: m.nodeRetries(),
+	}
+	return policy.Retry(ctx, func(ctx context.Context) error {
+		err := m.nodeAttempt(ctx, method, path, token, body, out)
+		if err != nil && !isTransient(method, err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	})
+}
+
+// nodeAttempt makes a single node request bounded by nodeTimeout.
+func (m *Miner) nodeAttempt(ctx context.Context, method, path, token string, body []byte, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, m.nodeTimeout())
+	defer cancel()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.config.NodeURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &nodeStatusError{path: path, status: resp.StatusCode, text: resp.Status}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// isTransient reports whether a failed node call is worth retrying. Any
+// timeout, connection error, 429 or 5xx is retried for idempotent methods.
+// Other calls (registration, heartbeats, submissions) may already have been
+// applied when the reply is lost, so they are only retried when the node
+// refused them outright: a failed dial, 429 or 503.
+func isTransient(method string, err error) bool {
+	var statusErr *nodeStatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.status == http.StatusTooManyRequests || statusErr.status == http.StatusServiceUnavailable {
+			return true
+		}
+		return idempotent(method) && statusErr.status >= 500
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if !idempotent(method) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// idempotent reports whether repeating a request with method has the same
+// effect on the node as sending it once
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2019-2025, Lux Industries Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/luxfi/ai/internal/minersig"
+)
+
+// ErrNotRegistered is returned by Deregister when the miner never
+// registered with the node (or already deregistered).
+var ErrNotRegistered = errors.New("miner not registered with node")
+
+// deregisterTimeout bounds the best-effort deregistration made from Stop.
+const deregisterTimeout = 5 * time.Second
+
+// ID returns the identifier the miner registers under: Config.MinerID when
+// set, otherwise the wallet address.
+func (m *Miner) ID() string {
+	if m.config.MinerID != "" {
+		return m.config.MinerID
+	}
+	return m.config.WalletAddress
+}
+
+// Register announces the miner to the node's /api/miners/register endpoint.
+// endpoint is the URL at which the node can reach this miner's API. The
+// advertised models include those the backend reports serving, and the
+// benchmarked capacity, GPU memory, region, modeling levels, hardware
+// capability and heartbeat public key are included when known. A miner
+// already known to the node proves its identity with the token from its
+// last registration or, with a signing key, a signature over
+// minersig.RegistrationDigest. The token returned by the node is kept for
+// authenticated calls such as Deregister, and the current attestation, if
+// any, is then reported.
+func (m *Miner) Register(ctx context.Context, endpoint string) error {
+	info := map[string]interface{}{
+		"id":             m.ID(),
+		"wallet_address": m.config.WalletAddress,
+		"endpoint":       endpoint,
+		"gpu_enabled":    m.config.GPUEnabled,
+		"models":         m.advertisedModels(ctx),
+	}
+	if capacity := m.Capacity(); capacity > 0 {
+		info["capacity_tps"] = capacity
+	}
+	if m.config.GPUMemoryMB > 0 {
+		info["gpu_memory_mb"] = m.config.GPUMemoryMB
+	}
+	if m.config.Region != "" {
+		info["region"] = m.config.Region
+	}
+	if len(m.config.ModelingLevels) > 0 {
+		info["modeling_levels"] = m.config.ModelingLevels
+	}
+	if m.config.Capability != nil {
+		info["capability"] = m.config.Capability
+	}
+	if key := m.config.SigningKey; key != nil {
+		pub := key.Public().(ed25519.PublicKey)
+		now := time.Now()
+		digest := minersig.RegistrationDigest(m.ID(), now, pub)
+		info["public_key"] = pub
+		info["timestamp"] = now
+		info["signature"] = ed25519.Sign(key, digest[:])
+	}
+	body, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	token := m.nodeToken
+	m.mu.RUnlock()
+
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := m.postNode(ctx, "/api/miners/register", token, body, &resp); err != nil {
+		m.logf(LogError, "", "registering with node: %v", err)
+		return err
+	}
+	m.logf(LogInfo, "", "registered with node as %s", m.ID())
+
+	m.mu.Lock()
+	m.nodeToken = resp.Token
+	m.mu.Unlock()
+
+	// An attestation made before registering has not reached the node yet
+	if att := m.Attestation(); att != nil {
+		m.reportAttestation(ctx, att)
+	}
+	return nil
+}
+
+// Deregister tells the node this miner is leaving so it stops routing work
+// here and reassigns any tasks still assigned to it. Stop calls this
+// automatically for registered miners.
+func (m *Miner) Deregister(ctx context.Context) error {
+	m.mu.RLock()
+	token := m.nodeToken
+	m.mu.RUnlock()
+	if token == "" {
+		return ErrNotRegistered
+	}
+
+	body, err := json.Marshal(map[string]string{"id": m.ID()})
+	if err != nil {
+		return err
+	}
+	if err := m.postNode(ctx, "/api/miners/deregister", token, body, nil); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.nodeToken = ""
+	m.mu.Unlock()
+	return nil
+}
+
+// postNode POSTs a JSON body to the node and decodes the reply into out
+// when out is non-nil.
+func (m *Miner) postNode(ctx context.Context, path, token string, body []byte, out interface{}) error {
+	return m.nodeRequest(ctx, "POST", path, token, body, out)
+}